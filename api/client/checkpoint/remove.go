@@ -0,0 +1,26 @@
+// +build experimental
+
+package checkpoint
+
+import (
+	"github.com/docker/docker/api/client"
+	"github.com/docker/docker/cli"
+	"github.com/spf13/cobra"
+	"golang.org/x/net/context"
+)
+
+func newRemoveCommand(dockerCli *client.DockerCli) *cobra.Command {
+	return &cobra.Command{
+		Use:     "rm CONTAINER CHECKPOINT",
+		Short:   "Remove a checkpoint",
+		Aliases: []string{"remove"},
+		Args:    cli.ExactArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runRemove(dockerCli, args[0], args[1])
+		},
+	}
+}
+
+func runRemove(dockerCli *client.DockerCli, container string, checkpoint string) error {
+	return dockerCli.Client().CheckpointDelete(context.Background(), container, checkpoint)
+}
@@ -1,19 +1,28 @@
 package container
 
 import (
+	"bytes"
 	"fmt"
 	"io"
+	"io/ioutil"
 	"os"
 	"path/filepath"
 	"strings"
+	"time"
 
 	"golang.org/x/net/context"
 
+	"github.com/Sirupsen/logrus"
 	"github.com/docker/docker/api/client"
 	"github.com/docker/docker/cli"
+	"github.com/docker/docker/errors"
 	"github.com/docker/docker/pkg/archive"
+	"github.com/docker/docker/pkg/progress"
+	"github.com/docker/docker/pkg/streamformatter"
 	"github.com/docker/docker/pkg/system"
+	apiclient "github.com/docker/engine-api/client"
 	"github.com/docker/engine-api/types"
+	"github.com/docker/go-units"
 	"github.com/spf13/cobra"
 )
 
@@ -21,6 +30,8 @@ type copyOptions struct {
 	source      string
 	destination string
 	followLink  bool
+	copyUIDGID  bool
+	chunkSize   string
 }
 
 type copyDirection int
@@ -33,8 +44,18 @@ const (
 
 type cpConfig struct {
 	followLink bool
+	copyUIDGID bool
+	chunkSize  int64
 }
 
+// chunkRetries is how many times a chunk of an upload in chunks is sent
+// again when the connection to the daemon fails.
+const chunkRetries = 5
+
+// chunkRetryDelay is how long to wait before sending a chunk again for the
+// first time, and then longer for each retry.
+var chunkRetryDelay = time.Second
+
 // NewCopyCommand creates a new `docker cp` command
 func NewCopyCommand(dockerCli *client.DockerCli) *cobra.Command {
 	var opts copyOptions
@@ -67,6 +88,8 @@ func NewCopyCommand(dockerCli *client.DockerCli) *cobra.Command {
 	flags := cmd.Flags()
 
 	flags.BoolVarP(&opts.followLink, "follow-link", "L", false, "Always follow symbol link in SRC_PATH")
+	flags.BoolVarP(&opts.copyUIDGID, "archive", "a", false, "Archive mode (copy all uid/gid information, extended attributes and SELinux labels)")
+	flags.StringVar(&opts.chunkSize, "chunk-size", "", "Upload to the container in chunks of this size, resuming on connection failures")
 
 	return cmd
 }
@@ -85,6 +108,20 @@ func runCopy(dockerCli *client.DockerCli, opts copyOptions) error {
 
 	cpParam := &cpConfig{
 		followLink: opts.followLink,
+		copyUIDGID: opts.copyUIDGID,
+	}
+	if opts.chunkSize != "" {
+		chunkSize, err := units.RAMInBytes(opts.chunkSize)
+		if err != nil {
+			return err
+		}
+		if chunkSize <= 0 {
+			return fmt.Errorf("invalid chunk size: %s", opts.chunkSize)
+		}
+		if direction != toContainer {
+			return fmt.Errorf("--chunk-size is only supported when copying to a container")
+		}
+		cpParam.chunkSize = chunkSize
 	}
 
 	ctx := context.Background()
@@ -236,7 +273,12 @@ func copyToContainer(ctx context.Context, dockerCli *client.DockerCli, srcPath,
 			return err
 		}
 
-		srcArchive, err := archive.TarResource(srcInfo)
+		var srcArchive archive.Archive
+		if cpParam.copyUIDGID {
+			srcArchive, err = archive.TarResourceWithXattrs(srcInfo)
+		} else {
+			srcArchive, err = archive.TarResource(srcInfo)
+		}
 		if err != nil {
 			return err
 		}
@@ -266,11 +308,99 @@ func copyToContainer(ctx context.Context, dockerCli *client.DockerCli, srcPath,
 
 	options := types.CopyToContainerOptions{
 		AllowOverwriteDirWithFile: false,
+		CopyUIDGID:                cpParam.copyUIDGID,
 	}
 
+	if cpParam.chunkSize > 0 {
+		return uploadInChunks(ctx, dockerCli, dstContainer, resolvedDstPath, content, options, cpParam.chunkSize)
+	}
 	return dockerCli.Client().CopyToContainer(ctx, dstContainer, resolvedDstPath, content, options)
 }
 
+// uploadInChunks uploads the archive content in chunks of chunkSize bytes,
+// and extracts it to path in the container. A chunk interrupted by a
+// connection failure is sent again, instead of the whole archive.
+func uploadInChunks(ctx context.Context, dockerCli *client.DockerCli, container, path string, content io.Reader, options types.CopyToContainerOptions, chunkSize int64) (err error) {
+	cli := dockerCli.Client()
+	upload, err := cli.ContainerArchiveUploadCreate(ctx, container, path, options)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		// The archive cannot be sent again from where it stopped by
+		// another command, so the upload is not kept until it expires.
+		if err != nil {
+			if err := cli.ContainerArchiveUploadRemove(ctx, container, upload.ID); err != nil {
+				logrus.Debugf("Error removing archive upload %s: %v", upload.ID, err)
+			}
+		}
+	}()
+
+	progressOutput := streamformatter.NewStreamFormatter().NewProgressOutput(dockerCli.Err(), true)
+	content = progress.NewProgressReader(ioutil.NopCloser(content), progressOutput, 0, "", "Copying to container")
+
+	chunk := make([]byte, chunkSize)
+	var offset int64
+	for {
+		n, err := io.ReadFull(content, chunk)
+		if err == io.EOF {
+			break
+		}
+		if err != nil && err != io.ErrUnexpectedEOF {
+			return err
+		}
+		if offset, err = uploadChunk(ctx, cli, container, upload.ID, offset, chunk[:n]); err != nil {
+			return err
+		}
+		if n < len(chunk) {
+			break
+		}
+	}
+
+	return cli.ContainerArchiveUploadExtract(ctx, container, upload.ID)
+}
+
+// uploadChunk sends the chunk of the upload id which starts at offset, and
+// returns the offset of the next chunk. The chunk is sent again while the
+// connection to the daemon fails.
+func uploadChunk(ctx context.Context, cli apiclient.APIClient, container, id string, offset int64, chunk []byte) (int64, error) {
+	var err error
+	for i := 0; i <= chunkRetries; i++ {
+		if i > 0 {
+			time.Sleep(time.Duration(i) * chunkRetryDelay)
+
+			// The daemon may have received the chunk, and only the
+			// response to it was lost.
+			upload, inspectErr := cli.ContainerArchiveUploadInspect(ctx, container, id)
+			if inspectErr != nil {
+				if apiclient.ErrorCode(inspectErr) != "" {
+					return 0, inspectErr
+				}
+				err = inspectErr
+				continue
+			}
+			switch upload.Offset {
+			case offset:
+			case offset + int64(len(chunk)):
+				return upload.Offset, nil
+			default:
+				return 0, fmt.Errorf("archive upload %s is at offset %d instead of %d", id, upload.Offset, offset)
+			}
+		}
+
+		var upload types.ArchiveUpload
+		upload, err = cli.ContainerArchiveUploadWrite(ctx, container, id, offset, bytes.NewReader(chunk))
+		if err == nil {
+			return upload.Offset, nil
+		}
+		if code := apiclient.ErrorCode(err); code != "" && code != errors.CodeUploadBusy {
+			// The daemon refused the chunk, sending it again won't help.
+			return 0, err
+		}
+	}
+	return 0, err
+}
+
 // We use `:` as a delimiter between CONTAINER and PATH, but `:` could also be
 // in a valid LOCALPATH, like `file:name.txt`. We can resolve this ambiguity by
 // requiring a LOCALPATH with a `:` to be made explicit with a relative or
@@ -0,0 +1,68 @@
+package container
+
+import (
+	"errors"
+	"io"
+	"io/ioutil"
+	"testing"
+
+	"golang.org/x/net/context"
+
+	apiclient "github.com/docker/engine-api/client"
+	"github.com/docker/engine-api/types"
+)
+
+// fakeUploadClient is a client whose connection to the daemon fails on the
+// first write of each chunk, before or after the daemon receives it.
+type fakeUploadClient struct {
+	apiclient.APIClient
+	offset      int64
+	writes      int
+	lostReplies bool
+}
+
+func (f *fakeUploadClient) ContainerArchiveUploadWrite(ctx context.Context, container, uploadID string, offset int64, content io.Reader) (types.ArchiveUpload, error) {
+	f.writes++
+	if f.writes%2 == 1 && !f.lostReplies {
+		return types.ArchiveUpload{}, errors.New("connection reset by peer")
+	}
+	n, err := io.Copy(ioutil.Discard, content)
+	if err != nil {
+		return types.ArchiveUpload{}, err
+	}
+	f.offset += n
+	if f.writes%2 == 1 {
+		return types.ArchiveUpload{}, errors.New("connection reset by peer")
+	}
+	return types.ArchiveUpload{ID: uploadID, Offset: f.offset}, nil
+}
+
+func (f *fakeUploadClient) ContainerArchiveUploadInspect(ctx context.Context, container, uploadID string) (types.ArchiveUpload, error) {
+	return types.ArchiveUpload{ID: uploadID, Offset: f.offset}, nil
+}
+
+func TestUploadChunkRetries(t *testing.T) {
+	chunkRetryDelay = 0
+	chunk := []byte("hello")
+
+	// The chunk was not received, so it is sent again
+	cli := &fakeUploadClient{}
+	offset, err := uploadChunk(context.Background(), cli, "container", "upload", 0, chunk)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if offset != 5 || cli.writes != 2 {
+		t.Fatalf("Expected the chunk to be sent twice up to offset 5, got %d writes up to offset %d", cli.writes, offset)
+	}
+
+	// The chunk was received and only the reply was lost, so it is not
+	// sent again
+	cli = &fakeUploadClient{lostReplies: true}
+	offset, err = uploadChunk(context.Background(), cli, "container", "upload", 0, chunk)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if offset != 5 || cli.writes != 1 {
+		t.Fatalf("Expected the chunk to be sent once up to offset 5, got %d writes up to offset %d", cli.writes, offset)
+	}
+}
@@ -4,6 +4,8 @@ import (
 	"fmt"
 	"io"
 	"os"
+	"sort"
+	"strings"
 
 	"golang.org/x/net/context"
 
@@ -23,7 +25,9 @@ import (
 )
 
 type createOptions struct {
-	name string
+	name        string
+	verifyLocal bool
+	fromSpec    string
 }
 
 // NewCreateCommand creats a new cobra.Command for `docker create`
@@ -34,8 +38,16 @@ func NewCreateCommand(dockerCli *client.DockerCli) *cobra.Command {
 	cmd := &cobra.Command{
 		Use:   "create [OPTIONS] IMAGE [COMMAND] [ARG...]",
 		Short: "Create a new container",
-		Args:  cli.RequiresMinArgs(1),
+		Args: func(cmd *cobra.Command, args []string) error {
+			if opts.fromSpec != "" {
+				return cli.NoArgs(cmd, args)
+			}
+			return cli.RequiresMinArgs(1)(cmd, args)
+		},
 		RunE: func(cmd *cobra.Command, args []string) error {
+			if opts.fromSpec != "" {
+				return runCreateFromSpec(dockerCli, cmd.Flags(), &opts)
+			}
 			copts.Image = args[0]
 			if len(args) > 1 {
 				copts.Args = args[1:]
@@ -49,6 +61,8 @@ func NewCreateCommand(dockerCli *client.DockerCli) *cobra.Command {
 	flags.SetInterspersed(false)
 
 	flags.StringVar(&opts.name, "name", "", "Assign a name to the container")
+	flags.BoolVar(&opts.verifyLocal, "verify-local", false, "Verify the local content of an image referred to by digest")
+	flags.StringVar(&opts.fromSpec, "from-spec", "", "Create the container from a spec exported by `docker export-spec`")
 
 	// Add an explicit help that doesn't have a `-h` to prevent the conflict
 	// with hostname
@@ -65,14 +79,116 @@ func runCreate(dockerCli *client.DockerCli, flags *pflag.FlagSet, opts *createOp
 		reportError(dockerCli.Err(), "create", err.Error(), true)
 		return cli.StatusError{StatusCode: 125}
 	}
-	response, err := createContainer(context.Background(), dockerCli, config, hostConfig, networkingConfig, hostConfig.ContainerIDFile, opts.name)
+	response, err := createContainer(context.Background(), dockerCli, config, hostConfig, networkingConfig, hostConfig.ContainerIDFile, opts.name, types.ContainerCreateOptions{VerifyLocal: opts.verifyLocal})
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(dockerCli.Out(), "%s\n", response.ID)
+	return nil
+}
+
+// specFlags are the flags which can be given with --from-spec.
+var specFlags = map[string]bool{
+	"from-spec":             true,
+	"name":                  true,
+	"verify-local":          true,
+	"disable-content-trust": true,
+}
+
+// runCreateFromSpec creates the container of a spec exported by
+// `docker export-spec`: it creates the named volumes of the spec which do
+// not exist, creates the container with the image of the spec and connects
+// it to the networks of the spec. The container is removed if its image
+// is not the image of the spec.
+func runCreateFromSpec(dockerCli *client.DockerCli, flags *pflag.FlagSet, opts *createOptions) error {
+	var conflicts []string
+	flags.Visit(func(f *pflag.Flag) {
+		if !specFlags[f.Name] {
+			conflicts = append(conflicts, "--"+f.Name)
+		}
+	})
+	if len(conflicts) > 0 {
+		return fmt.Errorf("Conflicting options: --from-spec and %s", strings.Join(conflicts, ", "))
+	}
+
+	spec, err := readContainerSpec(opts.fromSpec)
+	if err != nil {
+		return err
+	}
+
+	ctx := context.Background()
+	clnt := dockerCli.Client()
+
+	for _, v := range spec.Volumes {
+		if _, err := clnt.VolumeCreate(ctx, v); err != nil {
+			return err
+		}
+	}
+
+	// the daemon connects a new container to the network of its network
+	// mode only, the other networks are connected once it is created
+	var endpoints map[string]*networktypes.EndpointSettings
+	if spec.NetworkingConfig != nil {
+		endpoints = spec.NetworkingConfig.EndpointsConfig
+	}
+	networkingConfig := &networktypes.NetworkingConfig{EndpointsConfig: make(map[string]*networktypes.EndpointSettings)}
+	mode := string(spec.HostConfig.NetworkMode)
+	if ep, ok := endpoints[mode]; ok {
+		networkingConfig.EndpointsConfig[mode] = ep
+	}
+
+	name := opts.name
+	if name == "" {
+		name = spec.Name
+	}
+	response, err := createContainer(ctx, dockerCli, spec.Config, spec.HostConfig, networkingConfig, "", name, types.ContainerCreateOptions{VerifyLocal: opts.verifyLocal})
 	if err != nil {
 		return err
 	}
+
+	if err := connectSpecNetworks(ctx, dockerCli, spec, response.ID); err != nil {
+		clnt.ContainerRemove(ctx, response.ID, types.ContainerRemoveOptions{Force: true})
+		return err
+	}
+
 	fmt.Fprintf(dockerCli.Out(), "%s\n", response.ID)
 	return nil
 }
 
+// connectSpecNetworks checks the image of the container created from the
+// spec and connects it to the user-defined networks of the spec other than
+// the network of its network mode.
+func connectSpecNetworks(ctx context.Context, dockerCli *client.DockerCli, spec *types.ContainerSpec, id string) error {
+	clnt := dockerCli.Client()
+
+	if spec.ImageID != "" {
+		c, err := clnt.ContainerInspect(ctx, id)
+		if err != nil {
+			return err
+		}
+		if c.Image != spec.ImageID {
+			return fmt.Errorf("the image %s is %s, the spec was exported with %s", spec.Config.Image, c.Image, spec.ImageID)
+		}
+	}
+
+	if spec.NetworkingConfig == nil {
+		return nil
+	}
+	var networks []string
+	for n := range spec.NetworkingConfig.EndpointsConfig {
+		if n != string(spec.HostConfig.NetworkMode) && container.NetworkMode(n).IsUserDefined() {
+			networks = append(networks, n)
+		}
+	}
+	sort.Strings(networks)
+	for _, n := range networks {
+		if err := clnt.NetworkConnect(ctx, n, id, spec.NetworkingConfig.EndpointsConfig[n]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 func pullImage(ctx context.Context, dockerCli *client.DockerCli, image string, out io.Writer) error {
 	ref, err := reference.ParseNamed(image)
 	if err != nil {
@@ -148,7 +264,7 @@ func newCIDFile(path string) (*cidFile, error) {
 	return &cidFile{path: path, file: f}, nil
 }
 
-func createContainer(ctx context.Context, dockerCli *client.DockerCli, config *container.Config, hostConfig *container.HostConfig, networkingConfig *networktypes.NetworkingConfig, cidfile, name string) (*types.ContainerCreateResponse, error) {
+func createContainer(ctx context.Context, dockerCli *client.DockerCli, config *container.Config, hostConfig *container.HostConfig, networkingConfig *networktypes.NetworkingConfig, cidfile, name string, options types.ContainerCreateOptions) (*types.ContainerCreateResponse, error) {
 	stderr := dockerCli.Err()
 
 	var containerIDFile *cidFile
@@ -179,7 +295,7 @@ func createContainer(ctx context.Context, dockerCli *client.DockerCli, config *c
 	}
 
 	//create the container
-	response, err := dockerCli.Client().ContainerCreate(ctx, config, hostConfig, networkingConfig, name)
+	response, err := dockerCli.Client().ContainerCreate(ctx, config, hostConfig, networkingConfig, name, options)
 
 	//if image not found try to pull it
 	if err != nil {
@@ -197,7 +313,7 @@ func createContainer(ctx context.Context, dockerCli *client.DockerCli, config *c
 			}
 			// Retry
 			var retryErr error
-			response, retryErr = dockerCli.Client().ContainerCreate(ctx, config, hostConfig, networkingConfig, name)
+			response, retryErr = dockerCli.Client().ContainerCreate(ctx, config, hostConfig, networkingConfig, name, options)
 			if retryErr != nil {
 				return nil, retryErr
 			}
@@ -0,0 +1,92 @@
+package container
+
+import (
+	"encoding/json"
+	"fmt"
+	"text/tabwriter"
+	"time"
+
+	"golang.org/x/net/context"
+
+	"github.com/docker/docker/api/client"
+	"github.com/docker/docker/cli"
+	"github.com/docker/docker/pkg/stringid"
+	"github.com/docker/engine-api/types"
+	"github.com/docker/go-units"
+	"github.com/spf13/cobra"
+)
+
+type execStatsOptions struct {
+	container string
+	noTrunc   bool
+}
+
+// NewExecStatsCommand creates a new cobra.Command for `docker exec-stats`
+func NewExecStatsCommand(dockerCli *client.DockerCli) *cobra.Command {
+	var opts execStatsOptions
+
+	cmd := &cobra.Command{
+		Use:   "exec-stats [OPTIONS] CONTAINER",
+		Short: "Display the resource usage of the exec sessions of a container",
+		Args:  cli.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			opts.container = args[0]
+			return runExecStats(dockerCli, &opts)
+		},
+	}
+
+	flags := cmd.Flags()
+	flags.BoolVar(&opts.noTrunc, "no-trunc", false, "Don't truncate output")
+	return cmd
+}
+
+func runExecStats(dockerCli *client.DockerCli, opts *execStatsOptions) error {
+	ctx := context.Background()
+
+	c, err := dockerCli.Client().ContainerInspect(ctx, opts.container)
+	if err != nil {
+		return err
+	}
+
+	w := tabwriter.NewWriter(dockerCli.Out(), 20, 1, 3, ' ', 0)
+	fmt.Fprintln(w, "EXEC ID\tRUNNING\tCPU TIME\tMEM USAGE\tMEM MAX USAGE\tPIDS")
+	for _, id := range c.ExecIDs {
+		stats, err := execStats(ctx, dockerCli, id)
+		if err != nil {
+			// the exec was removed, or its resources are not accounted
+			continue
+		}
+		inspect, err := dockerCli.Client().ContainerExecInspect(ctx, id)
+		if err != nil {
+			continue
+		}
+		if !opts.noTrunc {
+			id = stringid.TruncateID(id)
+		}
+		fmt.Fprintf(w, "%s\t%t\t%s\t%s\t%s\t%d\n",
+			id,
+			inspect.Running,
+			time.Duration(stats.CPUUsage).String(),
+			units.BytesSize(float64(stats.MemoryUsage)),
+			units.BytesSize(float64(stats.MemoryMaxUsage)),
+			stats.Pids)
+	}
+	w.Flush()
+	return nil
+}
+
+// execStats returns the resources used so far by the processes of the exec
+// id.
+func execStats(ctx context.Context, dockerCli *client.DockerCli, id string) (*types.ExecStats, error) {
+	body, err := dockerCli.Client().ContainerExecStats(ctx, id, false)
+	if err != nil {
+		return nil, err
+	}
+	defer body.Close()
+
+	var stats types.ExecStats
+	if err := json.NewDecoder(body).Decode(&stats); err != nil {
+		return nil, err
+	}
+	return &stats, nil
+}
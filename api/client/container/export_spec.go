@@ -0,0 +1,83 @@
+package container
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+
+	"golang.org/x/net/context"
+
+	"github.com/docker/docker/api/client"
+	"github.com/docker/docker/cli"
+	"github.com/docker/engine-api/types"
+	"github.com/spf13/cobra"
+)
+
+type exportSpecOptions struct {
+	container string
+	output    string
+}
+
+// NewExportSpecCommand creates a new `docker export-spec` command
+func NewExportSpecCommand(dockerCli *client.DockerCli) *cobra.Command {
+	var opts exportSpecOptions
+
+	cmd := &cobra.Command{
+		Use:   "export-spec [OPTIONS] CONTAINER",
+		Short: "Export the spec of a container to create it again",
+		Args:  cli.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			opts.container = args[0]
+			return runExportSpec(dockerCli, opts)
+		},
+	}
+
+	flags := cmd.Flags()
+
+	flags.StringVarP(&opts.output, "output", "o", "", "Write to a file, instead of STDOUT")
+
+	return cmd
+}
+
+func runExportSpec(dockerCli *client.DockerCli, opts exportSpecOptions) error {
+	spec, err := dockerCli.Client().ContainerSpec(context.Background(), opts.container)
+	if err != nil {
+		return err
+	}
+
+	data, err := json.MarshalIndent(spec, "", "    ")
+	if err != nil {
+		return err
+	}
+	data = append(data, '\n')
+
+	if opts.output == "" {
+		_, err := dockerCli.Out().Write(data)
+		return err
+	}
+
+	return ioutil.WriteFile(opts.output, data, 0644)
+}
+
+// readContainerSpec reads the spec of a container exported by
+// `docker export-spec`, refusing the specs of other versions.
+func readContainerSpec(path string) (*types.ContainerSpec, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var spec types.ContainerSpec
+	if err := json.NewDecoder(f).Decode(&spec); err != nil {
+		return nil, fmt.Errorf("invalid container spec %s: %v", path, err)
+	}
+	if spec.Version != types.ContainerSpecVersion {
+		return nil, fmt.Errorf("unsupported version %q of the container spec %s, expected version %q", spec.Version, path, types.ContainerSpecVersion)
+	}
+	if spec.Config == nil || spec.Config.Image == "" || spec.HostConfig == nil {
+		return nil, fmt.Errorf("invalid container spec %s: no image or host configuration", path)
+	}
+	return &spec, nil
+}
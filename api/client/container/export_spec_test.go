@@ -0,0 +1,47 @@
+package container
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestReadContainerSpec(t *testing.T) {
+	dir, err := ioutil.TempDir("", "docker-spec-")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	for _, tc := range []struct {
+		spec     string
+		expected string
+	}{
+		{`{"Version":"1","Config":{"Image":"busybox@sha256:0123"},"HostConfig":{}}`, ""},
+		{`{"Version":"2","Config":{"Image":"busybox"},"HostConfig":{}}`, "unsupported version"},
+		{`{"Config":{"Image":"busybox"},"HostConfig":{}}`, "unsupported version"},
+		{`{"Version":"1","Config":{},"HostConfig":{}}`, "no image"},
+		{`{"Version":"1","Config":{"Image":"busybox"}}`, "no image or host configuration"},
+		{`{"Version":`, "invalid container spec"},
+	} {
+		pth := filepath.Join(dir, "spec.json")
+		if err := ioutil.WriteFile(pth, []byte(tc.spec), 0600); err != nil {
+			t.Fatal(err)
+		}
+		spec, err := readContainerSpec(pth)
+		if tc.expected == "" {
+			if err != nil {
+				t.Fatalf("unexpected error for %s: %v", tc.spec, err)
+			}
+			if spec.Config.Image != "busybox@sha256:0123" {
+				t.Fatalf("expected the image busybox@sha256:0123, got %s", spec.Config.Image)
+			}
+			continue
+		}
+		if err == nil || !strings.Contains(err.Error(), tc.expected) {
+			t.Fatalf("expected an error with %q for %s, got %v", tc.expected, tc.spec, err)
+		}
+	}
+}
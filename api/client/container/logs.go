@@ -3,6 +3,7 @@ package container
 import (
 	"fmt"
 	"io"
+	"strings"
 
 	"golang.org/x/net/context"
 
@@ -18,9 +19,22 @@ var validDrivers = map[string]bool{
 	"journald":  true,
 }
 
+// supportsLogs tells whether the logs of the log driver can be read: it
+// is one of the validDrivers, or a composite driver, like
+// "syslog+json-file", including one.
+func supportsLogs(driver string) bool {
+	for _, name := range strings.Split(driver, "+") {
+		if validDrivers[name] {
+			return true
+		}
+	}
+	return false
+}
+
 type logsOptions struct {
 	follow     bool
 	since      string
+	until      string
 	timestamps bool
 	details    bool
 	tail       string
@@ -45,7 +59,8 @@ func NewLogsCommand(dockerCli *client.DockerCli) *cobra.Command {
 
 	flags := cmd.Flags()
 	flags.BoolVarP(&opts.follow, "follow", "f", false, "Follow log output")
-	flags.StringVar(&opts.since, "since", "", "Show logs since timestamp")
+	flags.StringVar(&opts.since, "since", "", "Show logs since timestamp, or after a journal cursor")
+	flags.StringVar(&opts.until, "until", "", "Show logs before timestamp")
 	flags.BoolVarP(&opts.timestamps, "timestamps", "t", false, "Show timestamps")
 	flags.BoolVar(&opts.details, "details", false, "Show extra details provided to logs")
 	flags.StringVar(&opts.tail, "tail", "all", "Number of lines to show from the end of the logs")
@@ -60,7 +75,7 @@ func runLogs(dockerCli *client.DockerCli, opts *logsOptions) error {
 		return err
 	}
 
-	if !validDrivers[c.HostConfig.LogConfig.Type] {
+	if !supportsLogs(c.HostConfig.LogConfig.Type) {
 		return fmt.Errorf("\"logs\" command is supported only for \"json-file\" and \"journald\" logging drivers (got: %s)", c.HostConfig.LogConfig.Type)
 	}
 
@@ -68,6 +83,7 @@ func runLogs(dockerCli *client.DockerCli, opts *logsOptions) error {
 		ShowStdout: true,
 		ShowStderr: true,
 		Since:      opts.since,
+		Until:      opts.until,
 		Timestamps: opts.timestamps,
 		Follow:     opts.follow,
 		Tail:       opts.tail,
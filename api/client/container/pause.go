@@ -2,17 +2,22 @@ package container
 
 import (
 	"fmt"
+	"sort"
 	"strings"
 
 	"golang.org/x/net/context"
 
 	"github.com/docker/docker/api/client"
 	"github.com/docker/docker/cli"
+	"github.com/docker/engine-api/types"
+	"github.com/docker/engine-api/types/filters"
 	"github.com/spf13/cobra"
 )
 
 type pauseOptions struct {
 	containers []string
+	group      string
+	all        bool
 }
 
 // NewPauseCommand creats a new cobra.Command for `docker pause`
@@ -20,9 +25,14 @@ func NewPauseCommand(dockerCli *client.DockerCli) *cobra.Command {
 	var opts pauseOptions
 
 	cmd := &cobra.Command{
-		Use:   "pause CONTAINER [CONTAINER...]",
+		Use:   "pause [OPTIONS] CONTAINER [CONTAINER...]",
 		Short: "Pause all processes within one or more containers",
-		Args:  cli.RequiresMinArgs(1),
+		Args: func(cmd *cobra.Command, args []string) error {
+			if opts.group != "" || opts.all {
+				return cli.NoArgs(cmd, args)
+			}
+			return cli.RequiresMinArgs(1)(cmd, args)
+		},
 		RunE: func(cmd *cobra.Command, args []string) error {
 			opts.containers = args
 			return runPause(dockerCli, &opts)
@@ -30,12 +40,40 @@ func NewPauseCommand(dockerCli *client.DockerCli) *cobra.Command {
 	}
 	cmd.SetFlagErrorFunc(flagErrorFunc)
 
+	flags := cmd.Flags()
+	flags.StringVar(&opts.group, "group", "", "Pause all the running containers with the given label, all of them or none")
+	flags.BoolVar(&opts.all, "all", false, "Pause all the running containers, independently of each other")
+
 	return cmd
 }
 
 func runPause(dockerCli *client.DockerCli, opts *pauseOptions) error {
 	ctx := context.Background()
 
+	if opts.all {
+		if opts.group != "" {
+			return fmt.Errorf("Conflicting options: --all and --group")
+		}
+		report, err := dockerCli.Client().ContainerPauseAll(ctx, filters.NewArgs())
+		if err != nil {
+			return err
+		}
+		return printPauseAllReport(dockerCli, "pausing", report)
+	}
+
+	if opts.group != "" {
+		filter := filters.NewArgs()
+		filter.Add("label", opts.group)
+		report, err := dockerCli.Client().ContainerPauseGroup(ctx, filter)
+		if err != nil {
+			return err
+		}
+		for _, id := range report.Containers {
+			fmt.Fprintf(dockerCli.Out(), "%s\n", id)
+		}
+		return nil
+	}
+
 	var errs []string
 	for _, container := range opts.containers {
 		if err := dockerCli.Client().ContainerPause(ctx, container); err != nil {
@@ -49,3 +87,20 @@ func runPause(dockerCli *client.DockerCli, opts *pauseOptions) error {
 	}
 	return nil
 }
+
+// printPauseAllReport prints the IDs of the containers paused or unpaused by
+// --all, and returns the errors of the others.
+func printPauseAllReport(dockerCli *client.DockerCli, action string, report types.PauseGroupReport) error {
+	for _, id := range report.Containers {
+		fmt.Fprintf(dockerCli.Out(), "%s\n", id)
+	}
+	if len(report.Errors) == 0 {
+		return nil
+	}
+	var errs []string
+	for id, err := range report.Errors {
+		errs = append(errs, fmt.Sprintf("Error %s container %s: %s", action, id, err))
+	}
+	sort.Strings(errs)
+	return fmt.Errorf("%s", strings.Join(errs, "\n"))
+}
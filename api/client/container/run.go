@@ -25,11 +25,12 @@ import (
 )
 
 type runOptions struct {
-	autoRemove bool
-	detach     bool
-	sigProxy   bool
-	name       string
-	detachKeys string
+	autoRemove  bool
+	detach      bool
+	sigProxy    bool
+	name        string
+	detachKeys  string
+	verifyLocal bool
 }
 
 // NewRunCommand create a new `docker run` command
@@ -60,6 +61,7 @@ func NewRunCommand(dockerCli *client.DockerCli) *cobra.Command {
 	flags.BoolVar(&opts.sigProxy, "sig-proxy", true, "Proxy received signals to the process")
 	flags.StringVar(&opts.name, "name", "", "Assign a name to the container")
 	flags.StringVar(&opts.detachKeys, "detach-keys", "", "Override the key sequence for detaching a container")
+	flags.BoolVar(&opts.verifyLocal, "verify-local", false, "Verify the local content of an image referred to by digest")
 
 	// Add an explicit help that doesn't have a `-h` to prevent the conflict
 	// with hostname
@@ -151,7 +153,7 @@ func runRun(dockerCli *client.DockerCli, flags *pflag.FlagSet, opts *runOptions,
 
 	ctx, cancelFun := context.WithCancel(context.Background())
 
-	createResponse, err := createContainer(ctx, dockerCli, config, hostConfig, networkingConfig, hostConfig.ContainerIDFile, opts.name)
+	createResponse, err := createContainer(ctx, dockerCli, config, hostConfig, networkingConfig, hostConfig.ContainerIDFile, opts.name, types.ContainerCreateOptions{VerifyLocal: opts.verifyLocal})
 	if err != nil {
 		reportError(stderr, cmdPath, err.Error(), true)
 		return runStartContainerErr(err)
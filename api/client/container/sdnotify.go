@@ -0,0 +1,31 @@
+package container
+
+import (
+	"fmt"
+	"strings"
+
+	systemdDaemon "github.com/coreos/go-systemd/daemon"
+	"github.com/docker/engine-api/types"
+)
+
+// sdNotify sends the given assignments to systemd, for the units whose main
+// process is `docker start --attach --sd-notify`. It does nothing outside
+// such units.
+func sdNotify(assignments ...string) {
+	systemdDaemon.SdNotify(strings.Join(assignments, "\n"))
+}
+
+// exitStatus describes how the container of the given state exited, for
+// the status of its unit.
+func exitStatus(state *types.ContainerState) string {
+	switch {
+	case state.OOMKilled:
+		return fmt.Sprintf("Container killed by the OOM killer (exit code %d)", state.ExitCode)
+	case state.ExitCode > 128 && state.ExitCode <= 128+64:
+		return fmt.Sprintf("Container killed by signal %d (exit code %d)", state.ExitCode-128, state.ExitCode)
+	case state.Error != "":
+		return fmt.Sprintf("Container failed: %s (exit code %d)", state.Error, state.ExitCode)
+	default:
+		return fmt.Sprintf("Container exited with code %d", state.ExitCode)
+	}
+}
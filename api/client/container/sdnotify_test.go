@@ -0,0 +1,24 @@
+package container
+
+import (
+	"testing"
+
+	"github.com/docker/engine-api/types"
+)
+
+func TestExitStatus(t *testing.T) {
+	for _, tc := range []struct {
+		state    types.ContainerState
+		expected string
+	}{
+		{types.ContainerState{ExitCode: 0}, "Container exited with code 0"},
+		{types.ContainerState{ExitCode: 2}, "Container exited with code 2"},
+		{types.ContainerState{ExitCode: 143}, "Container killed by signal 15 (exit code 143)"},
+		{types.ContainerState{ExitCode: 137, OOMKilled: true}, "Container killed by the OOM killer (exit code 137)"},
+		{types.ContainerState{ExitCode: 127, Error: "executable file not found"}, "Container failed: executable file not found (exit code 127)"},
+	} {
+		if status := exitStatus(&tc.state); status != tc.expected {
+			t.Fatalf("expected %q, got %q", tc.expected, status)
+		}
+	}
+}
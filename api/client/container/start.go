@@ -12,6 +12,7 @@ import (
 	"github.com/docker/docker/cli"
 	"github.com/docker/docker/pkg/promise"
 	"github.com/docker/docker/pkg/signal"
+	"github.com/docker/docker/utils"
 	"github.com/docker/engine-api/types"
 	"github.com/spf13/cobra"
 )
@@ -20,6 +21,8 @@ type startOptions struct {
 	attach     bool
 	openStdin  bool
 	detachKeys string
+	sdNotify   bool
+	checkpoint string
 
 	containers []string
 }
@@ -43,12 +46,23 @@ func NewStartCommand(dockerCli *client.DockerCli) *cobra.Command {
 	flags.BoolVarP(&opts.attach, "attach", "a", false, "Attach STDOUT/STDERR and forward signals")
 	flags.BoolVarP(&opts.openStdin, "interactive", "i", false, "Attach container's STDIN")
 	flags.StringVar(&opts.detachKeys, "detach-keys", "", "Override the key sequence for detaching a container")
+	flags.BoolVar(&opts.sdNotify, "sd-notify", false, "Notify systemd of the start and exit status of the attached container")
+	if utils.ExperimentalBuild() {
+		flags.StringVar(&opts.checkpoint, "checkpoint", "", "Restore from this checkpoint")
+	}
 	return cmd
 }
 
 func runStart(dockerCli *client.DockerCli, opts *startOptions) error {
 	ctx, cancelFun := context.WithCancel(context.Background())
 
+	if opts.sdNotify && !opts.attach {
+		return fmt.Errorf("--sd-notify requires --attach")
+	}
+	if opts.checkpoint != "" && len(opts.containers) > 1 {
+		return fmt.Errorf("You cannot restore multiple containers at once.")
+	}
+
 	if opts.attach || opts.openStdin {
 		// We're going to attach to a container.
 		// 1. Ensure we only have one container.
@@ -104,11 +118,14 @@ func runStart(dockerCli *client.DockerCli, opts *startOptions) error {
 		})
 
 		// 3. Start the container.
-		if err := dockerCli.Client().ContainerStart(ctx, c.ID, types.ContainerStartOptions{}); err != nil {
+		if err := dockerCli.Client().ContainerStart(ctx, c.ID, types.ContainerStartOptions{CheckpointID: opts.checkpoint}); err != nil {
 			cancelFun()
 			<-cErr
 			return err
 		}
+		if opts.sdNotify {
+			sdNotify("READY=1", "STATUS=Container "+strings.TrimPrefix(c.Name, "/")+" running")
+		}
 
 		// 4. Wait for attachment to break.
 		if c.Config.Tty && dockerCli.IsTerminalOut() {
@@ -119,6 +136,9 @@ func runStart(dockerCli *client.DockerCli, opts *startOptions) error {
 		if attchErr := <-cErr; attchErr != nil {
 			return attchErr
 		}
+		if opts.sdNotify {
+			return notifyExitStatus(dockerCli, ctx, c.ID)
+		}
 		_, status, err := getExitCode(dockerCli, ctx, c.ID)
 		if err != nil {
 			return err
@@ -129,16 +149,30 @@ func runStart(dockerCli *client.DockerCli, opts *startOptions) error {
 	} else {
 		// We're not going to attach to anything.
 		// Start as many containers as we want.
-		return startContainersWithoutAttachments(dockerCli, ctx, opts.containers)
+		return startContainersWithoutAttachments(dockerCli, ctx, opts.containers, opts.checkpoint)
 	}
 
 	return nil
 }
 
-func startContainersWithoutAttachments(dockerCli *client.DockerCli, ctx context.Context, containers []string) error {
+// notifyExitStatus notifies systemd of how the container exited, and
+// returns its exit code as status.
+func notifyExitStatus(dockerCli *client.DockerCli, ctx context.Context, containerID string) error {
+	c, err := dockerCli.Client().ContainerInspect(ctx, containerID)
+	if err != nil {
+		return err
+	}
+	sdNotify("STOPPING=1", "STATUS="+exitStatus(c.State))
+	if c.State.ExitCode != 0 {
+		return cli.StatusError{StatusCode: c.State.ExitCode}
+	}
+	return nil
+}
+
+func startContainersWithoutAttachments(dockerCli *client.DockerCli, ctx context.Context, containers []string, checkpoint string) error {
 	var failedContainers []string
 	for _, container := range containers {
-		if err := dockerCli.Client().ContainerStart(ctx, container, types.ContainerStartOptions{}); err != nil {
+		if err := dockerCli.Client().ContainerStart(ctx, container, types.ContainerStartOptions{CheckpointID: checkpoint}); err != nil {
 			fmt.Fprintf(dockerCli.Err(), "%s\n", err)
 			failedContainers = append(failedContainers, container)
 		} else {
@@ -27,6 +27,7 @@ type containerStats struct {
 	BlockRead        float64
 	BlockWrite       float64
 	PidsCurrent      uint64
+	PidsLimit        uint64
 	mu               sync.Mutex
 	err              error
 }
@@ -122,6 +123,7 @@ func (s *containerStats) Collect(ctx context.Context, cli client.APIClient, stre
 			s.BlockRead = float64(blkRead)
 			s.BlockWrite = float64(blkWrite)
 			s.PidsCurrent = v.PidsStats.Current
+			s.PidsLimit = v.PidsStats.Limit
 			s.mu.Unlock()
 			u <- nil
 			if !streamStats {
@@ -144,6 +146,7 @@ func (s *containerStats) Collect(ctx context.Context, cli client.APIClient, stre
 			s.BlockRead = 0
 			s.BlockWrite = 0
 			s.PidsCurrent = 0
+			s.PidsLimit = 0
 			s.err = errors.New("timeout waiting for stats")
 			s.mu.Unlock()
 			// if this is the first stat you get, release WaitGroup
@@ -175,7 +178,7 @@ func (s *containerStats) Display(w io.Writer) error {
 	s.mu.Lock()
 	defer s.mu.Unlock()
 	// NOTE: if you change this format, you must also change the err format below!
-	format := "%s\t%.2f%%\t%s / %s\t%.2f%%\t%s / %s\t%s / %s\t%d\n"
+	format := "%s\t%.2f%%\t%s / %s\t%.2f%%\t%s / %s\t%s / %s\t%s\n"
 	if s.err != nil {
 		format = "%s\t%s\t%s / %s\t%s\t%s / %s\t%s / %s\t%s\n"
 		errStr := "--"
@@ -192,10 +195,19 @@ func (s *containerStats) Display(w io.Writer) error {
 		s.MemoryPercentage,
 		units.HumanSize(s.NetworkRx), units.HumanSize(s.NetworkTx),
 		units.HumanSize(s.BlockRead), units.HumanSize(s.BlockWrite),
-		s.PidsCurrent)
+		s.pids())
 	return nil
 }
 
+// pids returns the number of pids of the container, and the limit of the
+// pids cgroup when there is one.
+func (s *containerStats) pids() string {
+	if s.PidsLimit == 0 {
+		return fmt.Sprintf("%d", s.PidsCurrent)
+	}
+	return fmt.Sprintf("%d / %d", s.PidsCurrent, s.PidsLimit)
+}
+
 func calculateCPUPercent(previousCPU, previousSystem uint64, v *types.StatsJSON) float64 {
 	var (
 		cpuPercent = 0.0
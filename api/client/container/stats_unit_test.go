@@ -29,6 +29,17 @@ func TestDisplay(t *testing.T) {
 	if got != want {
 		t.Fatalf("c.Display() = %q, want %q", got, want)
 	}
+
+	c.PidsLimit = 100
+	b.Reset()
+	if err := c.Display(&b); err != nil {
+		t.Fatalf("c.Display() gave error: %s", err)
+	}
+	got = b.String()
+	want = "app\t30.00%\t100 MiB / 2 GiB\t4.88%\t104.9 MB / 838.9 MB\t104.9 MB / 838.9 MB\t1 / 100\n"
+	if got != want {
+		t.Fatalf("c.Display() = %q, want %q", got, want)
+	}
 }
 
 func TestCalculBlockIO(t *testing.T) {
@@ -8,11 +8,14 @@ import (
 
 	"github.com/docker/docker/api/client"
 	"github.com/docker/docker/cli"
+	"github.com/docker/engine-api/types/filters"
 	"github.com/spf13/cobra"
 )
 
 type unpauseOptions struct {
 	containers []string
+	group      string
+	all        bool
 }
 
 // NewUnpauseCommand creats a new cobra.Command for `docker unpause`
@@ -20,9 +23,14 @@ func NewUnpauseCommand(dockerCli *client.DockerCli) *cobra.Command {
 	var opts unpauseOptions
 
 	cmd := &cobra.Command{
-		Use:   "unpause CONTAINER [CONTAINER...]",
+		Use:   "unpause [OPTIONS] CONTAINER [CONTAINER...]",
 		Short: "Unpause all processes within one or more containers",
-		Args:  cli.RequiresMinArgs(1),
+		Args: func(cmd *cobra.Command, args []string) error {
+			if opts.group != "" || opts.all {
+				return cli.NoArgs(cmd, args)
+			}
+			return cli.RequiresMinArgs(1)(cmd, args)
+		},
 		RunE: func(cmd *cobra.Command, args []string) error {
 			opts.containers = args
 			return runUnpause(dockerCli, &opts)
@@ -30,12 +38,40 @@ func NewUnpauseCommand(dockerCli *client.DockerCli) *cobra.Command {
 	}
 	cmd.SetFlagErrorFunc(flagErrorFunc)
 
+	flags := cmd.Flags()
+	flags.StringVar(&opts.group, "group", "", "Unpause all the paused containers with the given label, all of them or none")
+	flags.BoolVar(&opts.all, "all", false, "Unpause all the paused containers, independently of each other")
+
 	return cmd
 }
 
 func runUnpause(dockerCli *client.DockerCli, opts *unpauseOptions) error {
 	ctx := context.Background()
 
+	if opts.all {
+		if opts.group != "" {
+			return fmt.Errorf("Conflicting options: --all and --group")
+		}
+		report, err := dockerCli.Client().ContainerUnpauseAll(ctx, filters.NewArgs())
+		if err != nil {
+			return err
+		}
+		return printPauseAllReport(dockerCli, "unpausing", report)
+	}
+
+	if opts.group != "" {
+		filter := filters.NewArgs()
+		filter.Add("label", opts.group)
+		report, err := dockerCli.Client().ContainerUnpauseGroup(ctx, filter)
+		if err != nil {
+			return err
+		}
+		for _, id := range report.Containers {
+			fmt.Fprintf(dockerCli.Out(), "%s\n", id)
+		}
+		return nil
+	}
+
 	var errs []string
 	for _, container := range opts.containers {
 		if err := dockerCli.Client().ContainerUnpause(ctx, container); err != nil {
@@ -3,16 +3,20 @@ package container
 import (
 	"fmt"
 	"strings"
+	"time"
 
 	"golang.org/x/net/context"
 
 	"github.com/docker/docker/api/client"
 	"github.com/docker/docker/cli"
+	containertypes "github.com/docker/engine-api/types/container"
 	"github.com/spf13/cobra"
 )
 
 type waitOptions struct {
 	containers []string
+	condition  string
+	timeout    time.Duration
 }
 
 // NewWaitCommand creates a new cobra.Command for `docker wait`
@@ -20,7 +24,7 @@ func NewWaitCommand(dockerCli *client.DockerCli) *cobra.Command {
 	var opts waitOptions
 
 	cmd := &cobra.Command{
-		Use:   "wait CONTAINER [CONTAINER...]",
+		Use:   "wait [OPTIONS] CONTAINER [CONTAINER...]",
 		Short: "Block until a container stops, then print its exit code",
 		Args:  cli.RequiresMinArgs(1),
 		RunE: func(cmd *cobra.Command, args []string) error {
@@ -30,15 +34,24 @@ func NewWaitCommand(dockerCli *client.DockerCli) *cobra.Command {
 	}
 	cmd.SetFlagErrorFunc(flagErrorFunc)
 
+	flags := cmd.Flags()
+	flags.StringVar(&opts.condition, "condition", string(containertypes.WaitConditionNotRunning), "Condition to wait for (not-running, next-exit or removed)")
+	flags.DurationVar(&opts.timeout, "timeout", 0, "Maximum time to wait for each container, 0 waits forever")
+
 	return cmd
 }
 
 func runWait(dockerCli *client.DockerCli, opts *waitOptions) error {
 	ctx := context.Background()
 
+	timeout := opts.timeout
+	if timeout <= 0 {
+		timeout = -1
+	}
+
 	var errs []string
 	for _, container := range opts.containers {
-		status, err := dockerCli.Client().ContainerWait(ctx, container)
+		status, err := dockerCli.Client().ContainerWaitCondition(ctx, container, containertypes.WaitCondition(opts.condition), timeout)
 		if err != nil {
 			errs = append(errs, err.Error())
 		} else {
@@ -8,8 +8,10 @@ import (
 
 	"github.com/Sirupsen/logrus"
 	Cli "github.com/docker/docker/cli"
+	"github.com/docker/docker/opts"
 	flag "github.com/docker/docker/pkg/mflag"
 	"github.com/docker/docker/pkg/promise"
+	runconfigopts "github.com/docker/docker/runconfig/opts"
 	"github.com/docker/engine-api/types"
 )
 
@@ -130,8 +132,12 @@ func ParseExec(cmd *flag.FlagSet, args []string) (*types.ExecConfig, error) {
 		flDetach     = cmd.Bool([]string{"d", "-detach"}, false, "Detached mode: run command in the background")
 		flUser       = cmd.String([]string{"u", "-user"}, "", "Username or UID (format: <name|uid>[:<group|gid>])")
 		flPrivileged = cmd.Bool([]string{"-privileged"}, false, "Give extended privileges to the command")
+		flEnv        = opts.NewListOpts(runconfigopts.ValidateEnv)
+		flEnvFile    = opts.NewListOpts(nil)
 		execCmd      []string
 	)
+	cmd.Var(&flEnv, []string{"e", "-env"}, "Set environment variables")
+	cmd.Var(&flEnvFile, []string{"-env-file"}, "Read in a file of environment variables")
 	cmd.Require(flag.Min, 2)
 	if err := cmd.ParseFlags(args, true); err != nil {
 		return nil, err
@@ -139,10 +145,16 @@ func ParseExec(cmd *flag.FlagSet, args []string) (*types.ExecConfig, error) {
 	parsedArgs := cmd.Args()
 	execCmd = parsedArgs[1:]
 
+	env, err := runconfigopts.ReadKVStrings(flEnvFile.GetAll(), flEnv.GetAll())
+	if err != nil {
+		return nil, err
+	}
+
 	execConfig := &types.ExecConfig{
 		User:       *flUser,
 		Privileged: *flPrivileged,
 		Tty:        *flTty,
+		Env:        env,
 		Cmd:        execCmd,
 		Detach:     *flDetach,
 	}
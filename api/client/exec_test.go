@@ -3,6 +3,8 @@ package client
 import (
 	"fmt"
 	"io/ioutil"
+	"os"
+	"reflect"
 	"testing"
 
 	flag "github.com/docker/docker/pkg/mflag"
@@ -44,6 +46,14 @@ func TestParseExec(t *testing.T) {
 			Tty:          true,
 			Cmd:          []string{"command"},
 		},
+		&arguments{
+			[]string{"-e", "LANG=C", "--env", "DEBUG", "container", "command"},
+		}: {
+			AttachStdout: true,
+			AttachStderr: true,
+			Env:          []string{"LANG=C", "DEBUG"},
+			Cmd:          []string{"command"},
+		},
 		&arguments{
 			[]string{"-d", "container", "command"},
 		}: {
@@ -88,6 +98,38 @@ func TestParseExec(t *testing.T) {
 	}
 }
 
+func TestParseExecEnvFile(t *testing.T) {
+	f, err := ioutil.TempFile("", "docker-exec-env-")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.Remove(f.Name())
+	if _, err := f.WriteString("# debugging\nLANG=C\nPATH=/usr/bin\n"); err != nil {
+		t.Fatal(err)
+	}
+	f.Close()
+
+	cmd := flag.NewFlagSet("exec", flag.ContinueOnError)
+	cmd.ShortUsage = func() {}
+	cmd.SetOutput(ioutil.Discard)
+	execConfig, err := ParseExec(cmd, []string{"--env-file", f.Name(), "-e", "PATH=/opt/bin", "container", "command"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// The variables of -e come last, so that they override the ones of the file.
+	expected := []string{"LANG=C", "PATH=/usr/bin", "PATH=/opt/bin"}
+	if !reflect.DeepEqual(execConfig.Env, expected) {
+		t.Fatalf("Expected the environment %v, got %v", expected, execConfig.Env)
+	}
+
+	cmd = flag.NewFlagSet("exec", flag.ContinueOnError)
+	cmd.ShortUsage = func() {}
+	cmd.SetOutput(ioutil.Discard)
+	if _, err := ParseExec(cmd, []string{"--env-file", f.Name() + ".missing", "container", "command"}); err == nil {
+		t.Fatal("Expected an error for a missing env file")
+	}
+}
+
 func compareExecConfig(config1 *types.ExecConfig, config2 *types.ExecConfig) bool {
 	if config1.AttachStderr != config2.AttachStderr {
 		return false
@@ -118,5 +160,13 @@ func compareExecConfig(config1 *types.ExecConfig, config2 *types.ExecConfig) boo
 			return false
 		}
 	}
+	if len(config1.Env) != len(config2.Env) {
+		return false
+	}
+	for index, value := range config1.Env {
+		if value != config2.Env[index] {
+			return false
+		}
+	}
 	return true
 }
@@ -34,27 +34,28 @@ import (
 )
 
 type buildOptions struct {
-	context        string
-	dockerfileName string
-	tags           opts.ListOpts
-	labels         []string
-	buildArgs      opts.ListOpts
-	ulimits        *runconfigopts.UlimitOpt
-	memory         string
-	memorySwap     string
-	shmSize        string
-	cpuShares      int64
-	cpuPeriod      int64
-	cpuQuota       int64
-	cpuSetCpus     string
-	cpuSetMems     string
-	cgroupParent   string
-	isolation      string
-	quiet          bool
-	noCache        bool
-	rm             bool
-	forceRm        bool
-	pull           bool
+	context         string
+	dockerfileName  string
+	tags            opts.ListOpts
+	labels          []string
+	buildArgs       opts.ListOpts
+	ulimits         *runconfigopts.UlimitOpt
+	memory          string
+	memorySwap      string
+	shmSize         string
+	cpuShares       int64
+	cpuPeriod       int64
+	cpuQuota        int64
+	cpuSetCpus      string
+	cpuSetMems      string
+	cgroupParent    string
+	isolation       string
+	quiet           bool
+	noCache         bool
+	rm              bool
+	forceRm         bool
+	pull            bool
+	ignoreFreeSpace bool
 }
 
 // NewBuildCommand creates a new `docker build` command
@@ -98,6 +99,7 @@ func NewBuildCommand(dockerCli *client.DockerCli) *cobra.Command {
 	flags.BoolVar(&options.forceRm, "force-rm", false, "Always remove intermediate containers")
 	flags.BoolVarP(&options.quiet, "quiet", "q", false, "Suppress the build output and print image ID on success")
 	flags.BoolVar(&options.pull, "pull", false, "Always attempt to pull a newer version of the image")
+	flags.BoolVar(&options.ignoreFreeSpace, "ignore-free-space", false, "Build even if the daemon would be left with less than its minimum free space")
 
 	client.AddTrustedFlags(flags, true)
 
@@ -244,27 +246,28 @@ func runBuild(dockerCli *client.DockerCli, options buildOptions) error {
 	}
 
 	buildOptions := types.ImageBuildOptions{
-		Memory:         memory,
-		MemorySwap:     memorySwap,
-		Tags:           options.tags.GetAll(),
-		SuppressOutput: options.quiet,
-		NoCache:        options.noCache,
-		Remove:         options.rm,
-		ForceRemove:    options.forceRm,
-		PullParent:     options.pull,
-		Isolation:      container.Isolation(options.isolation),
-		CPUSetCPUs:     options.cpuSetCpus,
-		CPUSetMems:     options.cpuSetMems,
-		CPUShares:      options.cpuShares,
-		CPUQuota:       options.cpuQuota,
-		CPUPeriod:      options.cpuPeriod,
-		CgroupParent:   options.cgroupParent,
-		Dockerfile:     relDockerfile,
-		ShmSize:        shmSize,
-		Ulimits:        options.ulimits.GetList(),
-		BuildArgs:      runconfigopts.ConvertKVStringsToMap(options.buildArgs.GetAll()),
-		AuthConfigs:    dockerCli.RetrieveAuthConfigs(),
-		Labels:         runconfigopts.ConvertKVStringsToMap(options.labels),
+		Memory:          memory,
+		MemorySwap:      memorySwap,
+		Tags:            options.tags.GetAll(),
+		SuppressOutput:  options.quiet,
+		NoCache:         options.noCache,
+		Remove:          options.rm,
+		ForceRemove:     options.forceRm,
+		PullParent:      options.pull,
+		IgnoreFreeSpace: options.ignoreFreeSpace,
+		Isolation:       container.Isolation(options.isolation),
+		CPUSetCPUs:      options.cpuSetCpus,
+		CPUSetMems:      options.cpuSetMems,
+		CPUShares:       options.cpuShares,
+		CPUQuota:        options.cpuQuota,
+		CPUPeriod:       options.cpuPeriod,
+		CgroupParent:    options.cgroupParent,
+		Dockerfile:      relDockerfile,
+		ShmSize:         shmSize,
+		Ulimits:         options.ulimits.GetList(),
+		BuildArgs:       runconfigopts.ConvertKVStringsToMap(options.buildArgs.GetAll()),
+		AuthConfigs:     dockerCli.RetrieveAuthConfigs(),
+		Labels:          runconfigopts.ConvertKVStringsToMap(options.labels),
 	}
 
 	response, err := dockerCli.Client().ImageBuild(ctx, body, buildOptions)
@@ -14,8 +14,10 @@ import (
 )
 
 type pullOptions struct {
-	remote string
-	all    bool
+	remote          string
+	all             bool
+	platform        string
+	ignoreFreeSpace bool
 }
 
 // NewPullCommand creates a new `docker pull` command
@@ -35,6 +37,8 @@ func NewPullCommand(dockerCli *client.DockerCli) *cobra.Command {
 	flags := cmd.Flags()
 
 	flags.BoolVarP(&opts.all, "all-tags", "a", false, "Download all tagged images in the repository")
+	flags.StringVar(&opts.platform, "platform", "", "Pull the image for a platform (os/arch[/variant]) from a manifest list")
+	flags.BoolVar(&opts.ignoreFreeSpace, "ignore-free-space", false, "Pull even if the daemon would be left with less than its minimum free space")
 	client.AddTrustedFlags(flags, true)
 
 	return cmd
@@ -77,9 +81,9 @@ func runPull(dockerCli *client.DockerCli, opts pullOptions) error {
 
 	if client.IsTrusted() && !registryRef.HasDigest() {
 		// Check if tag is digest
-		return dockerCli.TrustedPull(ctx, repoInfo, registryRef, authConfig, requestPrivilege)
+		return dockerCli.TrustedPull(ctx, repoInfo, registryRef, authConfig, requestPrivilege, opts.platform, opts.ignoreFreeSpace)
 	}
 
-	return dockerCli.ImagePullPrivileged(ctx, authConfig, distributionRef.String(), requestPrivilege, opts.all)
+	return dockerCli.ImagePullPrivileged(ctx, authConfig, distributionRef.String(), requestPrivilege, opts.all, opts.platform, opts.ignoreFreeSpace)
 
 }
@@ -0,0 +1,94 @@
+package manifest
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+
+	"golang.org/x/net/context"
+
+	"github.com/docker/docker/api/client"
+	"github.com/docker/docker/api/client/inspect"
+	"github.com/docker/docker/cli"
+	"github.com/docker/docker/reference"
+	"github.com/docker/docker/registry"
+	"github.com/docker/engine-api/types"
+	"github.com/spf13/cobra"
+)
+
+type inspectOptions struct {
+	ref     string
+	format  string
+	verbose bool
+}
+
+func newInspectCommand(dockerCli *client.DockerCli) *cobra.Command {
+	var opts inspectOptions
+
+	cmd := &cobra.Command{
+		Use:   "inspect [OPTIONS] NAME[:TAG|@DIGEST]",
+		Short: "Display the manifest or manifest list of an image in its registry",
+		Args:  cli.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			opts.ref = args[0]
+			return runInspect(dockerCli, opts)
+		},
+	}
+
+	flags := cmd.Flags()
+	flags.StringVarP(&opts.format, "format", "f", "", "Format the output using the given go template")
+	flags.BoolVarP(&opts.verbose, "verbose", "v", false, "Show the digest, media type and size of the manifest along with it")
+
+	return cmd
+}
+
+func runInspect(dockerCli *client.DockerCli, opts inspectOptions) error {
+	ctx := context.Background()
+
+	distributionRef, err := reference.ParseNamed(opts.ref)
+	if err != nil {
+		return err
+	}
+	repoInfo, err := registry.ParseRepositoryInfo(distributionRef)
+	if err != nil {
+		return err
+	}
+
+	authConfig := dockerCli.ResolveAuthConfig(ctx, repoInfo.Index)
+	encodedAuth, err := client.EncodeAuthToBase64(authConfig)
+	if err != nil {
+		return err
+	}
+	options := types.ImageManifestOptions{
+		RegistryAuth:  encodedAuth,
+		PrivilegeFunc: dockerCli.RegistryAuthenticationPrivilegedFunc(repoInfo.Index, "manifest inspect"),
+	}
+
+	getRefFunc := func(ref string) (interface{}, []byte, error) {
+		manifest, err := dockerCli.Client().ImageManifest(ctx, ref, options)
+		return manifest, nil, err
+	}
+	if opts.format != "" {
+		return inspect.Inspect(dockerCli.Out(), []string{opts.ref}, opts.format, getRefFunc)
+	}
+
+	manifest, err := dockerCli.Client().ImageManifest(ctx, opts.ref, options)
+	if err != nil {
+		return err
+	}
+
+	var out []byte
+	if opts.verbose {
+		if out, err = json.MarshalIndent(manifest, "", "    "); err != nil {
+			return err
+		}
+	} else {
+		var buf bytes.Buffer
+		if err := json.Indent(&buf, manifest.Manifest, "", "    "); err != nil {
+			return err
+		}
+		out = buf.Bytes()
+	}
+	fmt.Fprintln(dockerCli.Out(), string(out))
+	return nil
+}
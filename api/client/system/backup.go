@@ -0,0 +1,53 @@
+package system
+
+import (
+	"errors"
+	"io"
+
+	"golang.org/x/net/context"
+
+	"github.com/docker/docker/api/client"
+	"github.com/docker/docker/cli"
+	"github.com/spf13/cobra"
+)
+
+type backupOptions struct {
+	output string
+}
+
+// NewBackupCommand creates a new cobra.Command for `docker backup`
+func NewBackupCommand(dockerCli *client.DockerCli) *cobra.Command {
+	var opts backupOptions
+
+	cmd := &cobra.Command{
+		Use:   "backup [OPTIONS]",
+		Short: "Save the metadata of the daemon to a tar archive (streamed to STDOUT by default)",
+		Args:  cli.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runBackup(dockerCli, opts)
+		},
+	}
+
+	flags := cmd.Flags()
+	flags.StringVarP(&opts.output, "output", "o", "", "Write to a file, instead of STDOUT")
+	return cmd
+}
+
+func runBackup(dockerCli *client.DockerCli, opts backupOptions) error {
+	if opts.output == "" && dockerCli.IsTerminalOut() {
+		return errors.New("Cowardly refusing to save to a terminal. Use the -o flag or redirect.")
+	}
+
+	responseBody, err := dockerCli.Client().DaemonBackup(context.Background())
+	if err != nil {
+		return err
+	}
+	defer responseBody.Close()
+
+	if opts.output == "" {
+		_, err := io.Copy(dockerCli.Out(), responseBody)
+		return err
+	}
+
+	return client.CopyToFile(opts.output, responseBody)
+}
@@ -0,0 +1,158 @@
+package system
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"golang.org/x/net/context"
+
+	"github.com/docker/docker/api/client"
+	"github.com/docker/docker/cli"
+	"github.com/docker/docker/pkg/stringid"
+	"github.com/docker/docker/pkg/stringutils"
+	"github.com/docker/engine-api/types"
+	"github.com/docker/go-units"
+	"github.com/spf13/cobra"
+)
+
+type diskUsageOptions struct {
+	verbose bool
+}
+
+func newDiskUsageCommand(dockerCli *client.DockerCli) *cobra.Command {
+	var opts diskUsageOptions
+
+	cmd := &cobra.Command{
+		Use:   "df [OPTIONS]",
+		Short: "Show docker disk usage",
+		Args:  cli.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runDiskUsage(dockerCli, &opts)
+		},
+	}
+
+	flags := cmd.Flags()
+	flags.BoolVarP(&opts.verbose, "verbose", "v", false, "Show detailed information on space usage")
+
+	return cmd
+}
+
+func runDiskUsage(dockerCli *client.DockerCli, opts *diskUsageOptions) error {
+	du, err := dockerCli.Client().DiskUsage(context.Background())
+	if err != nil {
+		return err
+	}
+
+	w := tabwriter.NewWriter(dockerCli.Out(), 20, 1, 3, ' ', 0)
+	if opts.verbose {
+		printVerboseDiskUsage(w, du)
+	} else {
+		printDiskUsage(w, du)
+	}
+	return w.Flush()
+}
+
+// printDiskUsage prints the number of images, containers and volumes, how
+// many are in use, their size, and the space removing the unused ones
+// would reclaim.
+func printDiskUsage(w io.Writer, du types.DiskUsage) {
+	fmt.Fprintln(w, "TYPE\tTOTAL\tACTIVE\tSIZE\tRECLAIMABLE")
+
+	var activeImages int
+	var usedLayers int64
+	for _, img := range du.Images {
+		if img.Containers > 0 {
+			activeImages++
+			usedLayers += img.Size - img.SharedSize
+		}
+	}
+	fmt.Fprintf(w, "Images\t%d\t%d\t%s\t%s\n", len(du.Images), activeImages,
+		units.HumanSize(float64(du.LayersSize)), reclaimable(du.LayersSize-usedLayers, du.LayersSize))
+
+	var activeContainers int
+	var containersSize, stoppedSize int64
+	for _, c := range du.Containers {
+		containersSize += c.SizeRw
+		if c.State == "running" || c.State == "paused" || c.State == "restarting" {
+			activeContainers++
+		} else {
+			stoppedSize += c.SizeRw
+		}
+	}
+	fmt.Fprintf(w, "Containers\t%d\t%d\t%s\t%s\n", len(du.Containers), activeContainers,
+		units.HumanSize(float64(containersSize)), reclaimable(stoppedSize, containersSize))
+
+	var activeVolumes int
+	var volumesSize, unusedSize int64
+	for _, v := range du.Volumes {
+		if v.Size < 0 {
+			continue
+		}
+		volumesSize += v.Size
+		if v.RefCount > 0 {
+			activeVolumes++
+		} else {
+			unusedSize += v.Size
+		}
+	}
+	fmt.Fprintf(w, "Local Volumes\t%d\t%d\t%s\t%s\n", len(du.Volumes), activeVolumes,
+		units.HumanSize(float64(volumesSize)), reclaimable(unusedSize, volumesSize))
+}
+
+// printVerboseDiskUsage prints the space used by each image, container and
+// volume.
+func printVerboseDiskUsage(w io.Writer, du types.DiskUsage) {
+	fmt.Fprintf(w, "Images space usage:\n\n")
+	fmt.Fprintln(w, "REPOSITORY\tTAG\tIMAGE ID\tCREATED\tSIZE\tSHARED SIZE\tUNIQUE SIZE\tCONTAINERS")
+	for _, img := range du.Images {
+		repo, tag := "<none>", "<none>"
+		if len(img.RepoTags) > 0 {
+			if i := strings.LastIndex(img.RepoTags[0], ":"); i >= 0 {
+				repo, tag = img.RepoTags[0][:i], img.RepoTags[0][i+1:]
+			}
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s ago\t%s\t%s\t%s\t%d\n", repo, tag, stringid.TruncateID(img.ID),
+			units.HumanDuration(time.Now().UTC().Sub(time.Unix(img.Created, 0))),
+			units.HumanSize(float64(img.Size)), units.HumanSize(float64(img.SharedSize)),
+			units.HumanSize(float64(img.Size-img.SharedSize)), img.Containers)
+	}
+
+	fmt.Fprintf(w, "\nContainers space usage:\n\n")
+	fmt.Fprintln(w, "CONTAINER ID\tIMAGE\tCOMMAND\tSIZE\tCREATED\tSTATUS\tNAMES")
+	for _, c := range du.Containers {
+		var names []string
+		for _, name := range c.Names {
+			names = append(names, strings.TrimPrefix(name, "/"))
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s ago\t%s\t%s\n", stringid.TruncateID(c.ID), c.Image,
+			strconv.Quote(stringutils.Truncate(c.Command, 20)), units.HumanSize(float64(c.SizeRw)),
+			units.HumanDuration(time.Now().UTC().Sub(time.Unix(c.Created, 0))), c.Status,
+			strings.Join(names, ","))
+	}
+
+	fmt.Fprintf(w, "\nVolumes space usage:\n\n")
+	fmt.Fprintln(w, "VOLUME NAME\tDRIVER\tLINKS\tSIZE")
+	for _, v := range du.Volumes {
+		size := "N/A"
+		if v.Size >= 0 {
+			size = units.HumanSize(float64(v.Size))
+		}
+		fmt.Fprintf(w, "%s\t%s\t%d\t%s\n", v.Name, v.Driver, v.RefCount, size)
+	}
+}
+
+// reclaimable formats the space which can be reclaimed out of a total,
+// with its percentage.
+func reclaimable(size, total int64) string {
+	if size < 0 {
+		size = 0
+	}
+	if total <= 0 {
+		return units.HumanSize(float64(size))
+	}
+	return fmt.Sprintf("%s (%d%%)", units.HumanSize(float64(size)), size*100/total)
+}
@@ -0,0 +1,64 @@
+package system
+
+import (
+	"fmt"
+	"strings"
+	"text/tabwriter"
+
+	"golang.org/x/net/context"
+
+	"github.com/docker/docker/api/client"
+	"github.com/docker/docker/cli"
+	"github.com/spf13/cobra"
+)
+
+type drainOptions struct {
+	cancel bool
+}
+
+// NewDrainCommand creates a new cobra.Command for `docker drain`
+func NewDrainCommand(dockerCli *client.DockerCli) *cobra.Command {
+	var opts drainOptions
+
+	cmd := &cobra.Command{
+		Use:   "drain [OPTIONS]",
+		Short: "Stop the containers of the daemon and refuse new ones",
+		Args:  cli.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runDrain(dockerCli, &opts)
+		},
+	}
+
+	flags := cmd.Flags()
+	flags.BoolVar(&opts.cancel, "cancel", false, "Create and start containers again")
+	return cmd
+}
+
+func runDrain(dockerCli *client.DockerCli, opts *drainOptions) error {
+	ctx := context.Background()
+
+	if opts.cancel {
+		return dockerCli.Client().DaemonDrainCancel(ctx)
+	}
+
+	report, err := dockerCli.Client().DaemonDrain(ctx)
+	if err != nil {
+		return err
+	}
+
+	var failed []string
+	w := tabwriter.NewWriter(dockerCli.Out(), 20, 1, 3, ' ', 0)
+	fmt.Fprintln(w, "CONTAINER ID\tNAME\tERROR")
+	for _, c := range report.Containers {
+		if c.Error != "" {
+			failed = append(failed, c.Name)
+		}
+		fmt.Fprintf(w, "%.12s\t%s\t%s\n", c.ID, c.Name, c.Error)
+	}
+	w.Flush()
+
+	if len(failed) > 0 {
+		return fmt.Errorf("Error: failed to stop containers: %s", strings.Join(failed, ", "))
+	}
+	return nil
+}
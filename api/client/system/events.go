@@ -5,6 +5,7 @@ import (
 	"io"
 	"sort"
 	"strings"
+	"text/template"
 	"time"
 
 	"golang.org/x/net/context"
@@ -12,6 +13,7 @@ import (
 	"github.com/docker/docker/api/client"
 	"github.com/docker/docker/cli"
 	"github.com/docker/docker/pkg/jsonlog"
+	"github.com/docker/docker/utils/templates"
 	"github.com/docker/engine-api/types"
 	eventtypes "github.com/docker/engine-api/types/events"
 	"github.com/docker/engine-api/types/filters"
@@ -22,6 +24,7 @@ type eventsOptions struct {
 	since  string
 	until  string
 	filter []string
+	format string
 }
 
 // NewEventsCommand creats a new cobra.Command for `docker events`
@@ -41,11 +44,22 @@ func NewEventsCommand(dockerCli *client.DockerCli) *cobra.Command {
 	flags.StringVar(&opts.since, "since", "", "Show all events created since timestamp")
 	flags.StringVar(&opts.until, "until", "", "Stream events until this timestamp")
 	flags.StringSliceVarP(&opts.filter, "filter", "f", []string{}, "Filter output based on conditions provided")
+	flags.StringVar(&opts.format, "format", "", "Format the output using the given go template")
 
 	return cmd
 }
 
 func runEvents(dockerCli *client.DockerCli, opts *eventsOptions) error {
+	var tmpl *template.Template
+	if opts.format != "" {
+		var err error
+		tmpl, err = templates.Parse(opts.format)
+		if err != nil {
+			return cli.StatusError{StatusCode: 64,
+				Status: "Template parsing error: " + err.Error()}
+		}
+	}
+
 	eventFilterArgs := filters.NewArgs()
 
 	// Consolidate all filter flags, and sanity check them early.
@@ -70,16 +84,24 @@ func runEvents(dockerCli *client.DockerCli, opts *eventsOptions) error {
 	}
 	defer responseBody.Close()
 
-	return streamEvents(responseBody, dockerCli.Out())
+	return streamEvents(responseBody, dockerCli.Out(), tmpl)
 }
 
-// streamEvents decodes prints the incoming events in the provided output.
-func streamEvents(input io.Reader, output io.Writer) error {
+// streamEvents decodes prints the incoming events in the provided output,
+// formatted with tmpl if it is not nil.
+func streamEvents(input io.Reader, output io.Writer, tmpl *template.Template) error {
 	return DecodeEvents(input, func(event eventtypes.Message, err error) error {
 		if err != nil {
 			return err
 		}
-		printOutput(event, output)
+		if tmpl == nil {
+			printOutput(event, output)
+			return nil
+		}
+		if err := tmpl.Execute(output, event); err != nil {
+			return err
+		}
+		fmt.Fprint(output, "\n")
 		return nil
 	})
 }
@@ -102,6 +102,12 @@ func runInfo(dockerCli *client.DockerCli) error {
 	ioutils.FprintfIfNotEmpty(dockerCli.Out(), " %s", strings.Join(info.SecurityOptions, " "))
 	fmt.Fprintf(dockerCli.Out(), "\n")
 
+	if info.PullVerification != nil {
+		fmt.Fprintf(dockerCli.Out(), "Pull Verification:")
+		ioutils.FprintfIfNotEmpty(dockerCli.Out(), " %s", strings.Join(info.PullVerification, " "))
+		fmt.Fprintf(dockerCli.Out(), "\n")
+	}
+
 	ioutils.FprintfIfNotEmpty(dockerCli.Out(), "Kernel Version: %s\n", info.KernelVersion)
 	ioutils.FprintfIfNotEmpty(dockerCli.Out(), "Operating System: %s\n", info.OperatingSystem)
 	ioutils.FprintfIfNotEmpty(dockerCli.Out(), "OSType: %s\n", info.OSType)
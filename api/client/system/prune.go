@@ -0,0 +1,178 @@
+package system
+
+import (
+	"bufio"
+	"fmt"
+	"strings"
+
+	"golang.org/x/net/context"
+
+	"github.com/docker/docker/api/client"
+	"github.com/docker/docker/cli"
+	"github.com/docker/engine-api/types/filters"
+	"github.com/docker/go-units"
+	"github.com/spf13/cobra"
+)
+
+// pruneTypes are the types of objects `docker prune` removes, in the order
+// they are removed, so that the images and volumes of the containers
+// removed are removed as well.
+var pruneTypes = []string{"containers", "images", "volumes"}
+
+type pruneOptions struct {
+	types  []string
+	all    bool
+	force  bool
+	filter []string
+}
+
+// NewPruneCommand creates a new cobra.Command for `docker prune`
+func NewPruneCommand(dockerCli *client.DockerCli) *cobra.Command {
+	var opts pruneOptions
+
+	cmd := &cobra.Command{
+		Use:   "prune [OPTIONS] [containers|images|volumes...]",
+		Short: "Remove stopped containers, dangling images and unused volumes",
+		Args:  cli.RequiresMaxArgs(len(pruneTypes)),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			opts.types = args
+			return runPrune(dockerCli, &opts)
+		},
+	}
+
+	flags := cmd.Flags()
+	flags.BoolVarP(&opts.all, "all", "a", false, "Remove all the images without containers, not only the dangling ones")
+	flags.BoolVarP(&opts.force, "force", "f", false, "Do not prompt for confirmation")
+	flags.StringSliceVar(&opts.filter, "filter", []string{}, "Provide filter values (i.e. 'until=24h' or 'label=key=value')")
+
+	return cmd
+}
+
+func runPrune(dockerCli *client.DockerCli, opts *pruneOptions) error {
+	selected := map[string]bool{}
+	for _, t := range opts.types {
+		if !isPruneType(t) {
+			return fmt.Errorf("Invalid type %q: must be one of %s", t, strings.Join(pruneTypes, ", "))
+		}
+		selected[t] = true
+	}
+	// Volumes hold data, so they are only removed when asked for
+	if len(selected) == 0 {
+		selected["containers"] = true
+		selected["images"] = true
+	}
+
+	pruneFilters, err := parsePruneFilters(opts.filter)
+	if err != nil {
+		return err
+	}
+
+	if !opts.force && !confirmPrune(dockerCli, selected, opts.all) {
+		return nil
+	}
+
+	ctx := context.Background()
+	var spaceReclaimed uint64
+	if selected["containers"] {
+		report, err := dockerCli.Client().ContainersPrune(ctx, pruneFilters)
+		if err != nil {
+			return err
+		}
+		printPruned(dockerCli, "Deleted Containers:", report.ContainersDeleted)
+		spaceReclaimed += report.SpaceReclaimed
+	}
+	if selected["images"] {
+		// The filters are parsed again not to add dangling to the others
+		imageFilters, err := parsePruneFilters(opts.filter)
+		if err != nil {
+			return err
+		}
+		if opts.all {
+			imageFilters.Add("dangling", "false")
+		}
+		report, err := dockerCli.Client().ImagesPrune(ctx, imageFilters)
+		if err != nil {
+			return err
+		}
+		var deleted []string
+		for _, record := range report.ImagesDeleted {
+			if record.Untagged != "" {
+				deleted = append(deleted, "untagged: "+record.Untagged)
+			} else {
+				deleted = append(deleted, "deleted: "+record.Deleted)
+			}
+		}
+		printPruned(dockerCli, "Deleted Images:", deleted)
+		spaceReclaimed += report.SpaceReclaimed
+	}
+	if selected["volumes"] {
+		report, err := dockerCli.Client().VolumesPrune(ctx, pruneFilters)
+		if err != nil {
+			return err
+		}
+		printPruned(dockerCli, "Deleted Volumes:", report.VolumesDeleted)
+		spaceReclaimed += report.SpaceReclaimed
+	}
+
+	fmt.Fprintf(dockerCli.Out(), "Total reclaimed space: %s\n", units.HumanSize(float64(spaceReclaimed)))
+	return nil
+}
+
+func parsePruneFilters(filter []string) (filters.Args, error) {
+	pruneFilters := filters.NewArgs()
+	for _, f := range filter {
+		var err error
+		pruneFilters, err = filters.ParseFlag(f, pruneFilters)
+		if err != nil {
+			return pruneFilters, err
+		}
+	}
+	return pruneFilters, nil
+}
+
+func isPruneType(t string) bool {
+	for _, pruneType := range pruneTypes {
+		if t == pruneType {
+			return true
+		}
+	}
+	return false
+}
+
+// confirmPrune describes what is about to be removed and asks the user to
+// confirm.
+func confirmPrune(dockerCli *client.DockerCli, selected map[string]bool, all bool) bool {
+	fmt.Fprintln(dockerCli.Out(), "WARNING! This will remove:")
+	if selected["containers"] {
+		fmt.Fprintln(dockerCli.Out(), "  - all stopped containers")
+	}
+	if selected["images"] {
+		if all {
+			fmt.Fprintln(dockerCli.Out(), "  - all images without at least one container associated to them")
+		} else {
+			fmt.Fprintln(dockerCli.Out(), "  - all dangling images")
+		}
+	}
+	if selected["volumes"] {
+		fmt.Fprintln(dockerCli.Out(), "  - all volumes not used by at least one container")
+	}
+	fmt.Fprint(dockerCli.Out(), "Are you sure you want to continue? [y/N] ")
+
+	reader := bufio.NewReader(dockerCli.In())
+	line, _, err := reader.ReadLine()
+	if err != nil {
+		return false
+	}
+	return strings.ToLower(strings.TrimSpace(string(line))) == "y"
+}
+
+func printPruned(dockerCli *client.DockerCli, title string, pruned []string) {
+	if len(pruned) == 0 {
+		return
+	}
+	fmt.Fprintln(dockerCli.Out(), title)
+	for _, p := range pruned {
+		fmt.Fprintln(dockerCli.Out(), p)
+	}
+	fmt.Fprintln(dockerCli.Out())
+}
@@ -93,7 +93,10 @@ func (cli *DockerCli) certificateDirectory(server string) (string, error) {
 	return filepath.Join(cliconfig.ConfigDir(), "tls", u.Host), nil
 }
 
-func trustServer(index *registrytypes.IndexInfo) (string, error) {
+// trustServer returns the trust server of the registry of index: the one of
+// DOCKER_CONTENT_TRUST_SERVER if it is set, else the one the daemon sets for
+// the registry, if any, else the default one.
+func trustServer(index *registrytypes.IndexInfo, daemonServer string) (string, error) {
 	if s := os.Getenv("DOCKER_CONTENT_TRUST_SERVER"); s != "" {
 		urlObj, err := url.Parse(s)
 		if err != nil || urlObj.Scheme != "https" {
@@ -102,12 +105,29 @@ func trustServer(index *registrytypes.IndexInfo) (string, error) {
 
 		return s, nil
 	}
+	if daemonServer != "" {
+		return daemonServer, nil
+	}
 	if index.Official {
 		return registry.NotaryServer, nil
 	}
 	return "https://" + index.Name, nil
 }
 
+// daemonTrustServer returns the trust server the daemon sets for the
+// registry of index with --registry-trust-server, if any.
+func (cli *DockerCli) daemonTrustServer(ctx context.Context, index *registrytypes.IndexInfo) string {
+	info, err := cli.client.Info(ctx)
+	if err != nil {
+		logrus.Debugf("Error getting the trust servers of the daemon: %v", err)
+		return ""
+	}
+	if info.RegistryConfig == nil {
+		return ""
+	}
+	return info.RegistryConfig.TrustServers[index.Name]
+}
+
 type simpleCredentialStore struct {
 	auth types.AuthConfig
 }
@@ -126,8 +146,8 @@ func (scs simpleCredentialStore) SetRefreshToken(*url.URL, string, string) {
 // getNotaryRepository returns a NotaryRepository which stores all the
 // information needed to operate on a notary repository.
 // It creates an HTTP transport providing authentication support.
-func (cli *DockerCli) getNotaryRepository(repoInfo *registry.RepositoryInfo, authConfig types.AuthConfig, actions ...string) (*client.NotaryRepository, error) {
-	server, err := trustServer(repoInfo.Index)
+func (cli *DockerCli) getNotaryRepository(ctx context.Context, repoInfo *registry.RepositoryInfo, authConfig types.AuthConfig, actions ...string) (*client.NotaryRepository, error) {
+	server, err := trustServer(repoInfo.Index, cli.daemonTrustServer(ctx, repoInfo.Index))
 	if err != nil {
 		return nil, err
 	}
@@ -247,7 +267,7 @@ func (cli *DockerCli) TrustedReference(ctx context.Context, ref reference.NamedT
 	// Resolve the Auth config relevant for this server
 	authConfig := cli.ResolveAuthConfig(ctx, repoInfo.Index)
 
-	notaryRepo, err := cli.getNotaryRepository(repoInfo, authConfig, "pull")
+	notaryRepo, err := cli.getNotaryRepository(ctx, repoInfo, authConfig, "pull")
 	if err != nil {
 		fmt.Fprintf(cli.out, "Error establishing connection to trust repository: %s\n", err)
 		return nil, err
@@ -309,10 +329,10 @@ func notaryError(repoName string, err error) error {
 }
 
 // TrustedPull handles content trust pulling of an image
-func (cli *DockerCli) TrustedPull(ctx context.Context, repoInfo *registry.RepositoryInfo, ref registry.Reference, authConfig types.AuthConfig, requestPrivilege types.RequestPrivilegeFunc) error {
+func (cli *DockerCli) TrustedPull(ctx context.Context, repoInfo *registry.RepositoryInfo, ref registry.Reference, authConfig types.AuthConfig, requestPrivilege types.RequestPrivilegeFunc, platform string, ignoreFreeSpace bool) error {
 	var refs []target
 
-	notaryRepo, err := cli.getNotaryRepository(repoInfo, authConfig, "pull")
+	notaryRepo, err := cli.getNotaryRepository(ctx, repoInfo, authConfig, "pull")
 	if err != nil {
 		fmt.Fprintf(cli.out, "Error establishing connection to trust repository: %s\n", err)
 		return err
@@ -371,7 +391,7 @@ func (cli *DockerCli) TrustedPull(ctx context.Context, repoInfo *registry.Reposi
 		if err != nil {
 			return err
 		}
-		if err := cli.ImagePullPrivileged(ctx, authConfig, ref.String(), requestPrivilege, false); err != nil {
+		if err := cli.ImagePullPrivileged(ctx, authConfig, ref.String(), requestPrivilege, false, platform, ignoreFreeSpace); err != nil {
 			return err
 		}
 
@@ -462,7 +482,7 @@ func (cli *DockerCli) TrustedPush(ctx context.Context, repoInfo *registry.Reposi
 
 	fmt.Fprintln(cli.out, "Signing and pushing trust metadata")
 
-	repo, err := cli.getNotaryRepository(repoInfo, authConfig, "push", "pull")
+	repo, err := cli.getNotaryRepository(ctx, repoInfo, authConfig, "push", "pull")
 	if err != nil {
 		fmt.Fprintf(cli.out, "Error establishing connection to notary repository: %s\n", err)
 		return err
@@ -562,16 +582,18 @@ func (cli *DockerCli) addTargetToAllSignableRoles(repo *client.NotaryRepository,
 }
 
 // ImagePullPrivileged pulls the image and displays it to the output
-func (cli *DockerCli) ImagePullPrivileged(ctx context.Context, authConfig types.AuthConfig, ref string, requestPrivilege types.RequestPrivilegeFunc, all bool) error {
+func (cli *DockerCli) ImagePullPrivileged(ctx context.Context, authConfig types.AuthConfig, ref string, requestPrivilege types.RequestPrivilegeFunc, all bool, platform string, ignoreFreeSpace bool) error {
 
 	encodedAuth, err := EncodeAuthToBase64(authConfig)
 	if err != nil {
 		return err
 	}
 	options := types.ImagePullOptions{
-		RegistryAuth:  encodedAuth,
-		PrivilegeFunc: requestPrivilege,
-		All:           all,
+		RegistryAuth:    encodedAuth,
+		PrivilegeFunc:   requestPrivilege,
+		All:             all,
+		Platform:        platform,
+		IgnoreFreeSpace: ignoreFreeSpace,
 	}
 
 	responseBody, err := cli.client.ImagePull(ctx, ref, options)
@@ -0,0 +1,100 @@
+package server
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"regexp"
+
+	"github.com/docker/docker/api/server/httputils"
+	"github.com/docker/go-units"
+)
+
+// streamingRoutes are the routes whose request body is a stream, like an
+// archive, instead of a JSON document. Their request bodies are only
+// limited if a limit is set for the route.
+var streamingRoutes = map[string]bool{
+	"/build":                     true,
+	"/containers/{name}/archive": true,
+	"/containers/{name}/archive/uploads/{id}": true,
+	"/images/create": true,
+	"/images/load":   true,
+}
+
+// pathVariableRegexp matches the variables of route paths with a pattern,
+// like "{name:.*}".
+var pathVariableRegexp = regexp.MustCompile(`\{([^:}]+):[^}]*\}`)
+
+// routeKey returns the path of a route as it is used for body size limits,
+// with the patterns of the path variables removed, like
+// "/containers/{name}/update".
+func routeKey(path string) string {
+	return pathVariableRegexp.ReplaceAllString(path, "{$1}")
+}
+
+// bodySizeLimit returns the maximum size of the request bodies of the route
+// with path, or 0 if they are not limited.
+func (s *Server) bodySizeLimit(path string) int64 {
+	key := routeKey(path)
+	if limit, ok := s.cfg.BodySizeLimits[key]; ok {
+		return limit
+	}
+	if streamingRoutes[key] {
+		return 0
+	}
+	return s.cfg.MaxBodySize
+}
+
+type requestBodyTooLargeError struct {
+	limit int64
+}
+
+func (e requestBodyTooLargeError) Error() string {
+	return fmt.Sprintf("request body too large: the limit is %s", units.BytesSize(float64(e.limit)))
+}
+
+func (requestBodyTooLargeError) HTTPErrorStatusCode() int {
+	return http.StatusRequestEntityTooLarge
+}
+
+// limitRequestBody returns a handler failing the requests whose body is
+// larger than limit. Requests announcing a larger body fail before it is
+// read, the others fail as soon as the limit is reached while decoding the
+// body.
+func limitRequestBody(handler http.HandlerFunc, limit int64) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.ContentLength > limit {
+			httputils.MakeErrorHandler(requestBodyTooLargeError{limit})(w, r)
+			return
+		}
+		if r.Body != nil {
+			r.Body = &limitedBody{ReadCloser: r.Body, remaining: limit, limit: limit}
+		}
+		handler(w, r)
+	}
+}
+
+// limitedBody is a request body returning a requestBodyTooLargeError once
+// more than limit bytes are read.
+type limitedBody struct {
+	io.ReadCloser
+	remaining int64
+	limit     int64
+}
+
+func (b *limitedBody) Read(p []byte) (int, error) {
+	if b.remaining < 0 {
+		return 0, requestBodyTooLargeError{b.limit}
+	}
+	// Read one byte more than remains, to tell a body of exactly limit
+	// bytes from a larger one.
+	if int64(len(p)) > b.remaining+1 {
+		p = p[:b.remaining+1]
+	}
+	n, err := b.ReadCloser.Read(p)
+	b.remaining -= int64(n)
+	if b.remaining < 0 {
+		return n + int(b.remaining), requestBodyTooLargeError{b.limit}
+	}
+	return n, err
+}
@@ -0,0 +1,76 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/docker/docker/api/server/router"
+	"golang.org/x/net/context"
+)
+
+type bodyLimitTestRouter struct {
+	routes []router.Route
+}
+
+func (r bodyLimitTestRouter) Routes() []router.Route {
+	return r.routes
+}
+
+func TestBodySizeLimits(t *testing.T) {
+	decode := func(ctx context.Context, w http.ResponseWriter, r *http.Request, vars map[string]string) error {
+		var v map[string]interface{}
+		if err := json.NewDecoder(r.Body).Decode(&v); err != nil {
+			return err
+		}
+		w.WriteHeader(http.StatusNoContent)
+		return nil
+	}
+
+	srv := &Server{cfg: &Config{
+		MaxBodySize:    64,
+		BodySizeLimits: map[string]int64{"/containers/{name}/update": 128},
+	}}
+	srv.InitRouter(false, bodyLimitTestRouter{routes: []router.Route{
+		router.NewPostRoute("/containers/create", decode),
+		router.NewPostRoute("/containers/{name:.*}/update", decode),
+		router.NewPostRoute("/build", decode),
+		router.NewPostRoute("/containers/{name:.*}/archive/uploads/{id:.*}", decode),
+	}})
+
+	body := func(size int) string {
+		return `{"Env": "` + strings.Repeat("x", size-len(`{"Env": ""}`)) + `"}`
+	}
+	for _, tc := range []struct {
+		path    string
+		size    int
+		chunked bool
+		status  int
+	}{
+		{"/containers/create", 64, false, http.StatusNoContent},
+		{"/containers/create", 65, false, http.StatusRequestEntityTooLarge},
+		{"/containers/create", 64, true, http.StatusNoContent},
+		{"/containers/create", 65, true, http.StatusRequestEntityTooLarge},
+		{"/v1.25/containers/create", 65, false, http.StatusRequestEntityTooLarge},
+		{"/containers/foo/update", 128, true, http.StatusNoContent},
+		{"/containers/foo/update", 129, true, http.StatusRequestEntityTooLarge},
+		{"/build", 4096, true, http.StatusNoContent},
+		{"/containers/foo/archive/uploads/bar", 4096, false, http.StatusNoContent},
+	} {
+		req, err := http.NewRequest("POST", tc.path, strings.NewReader(body(tc.size)))
+		if err != nil {
+			t.Fatal(err)
+		}
+		req.Header.Set("Content-Type", "application/json")
+		if tc.chunked {
+			req.ContentLength = -1
+		}
+		resp := httptest.NewRecorder()
+		srv.routerSwapper.ServeHTTP(resp, req)
+		if resp.Code != tc.status {
+			t.Fatalf("expected status %d for a body of %d bytes to %s, got %d: %s", tc.status, tc.size, tc.path, resp.Code, resp.Body)
+		}
+	}
+}
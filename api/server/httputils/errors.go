@@ -5,6 +5,7 @@ import (
 	"strings"
 
 	"github.com/Sirupsen/logrus"
+	"github.com/docker/docker/errors"
 	"github.com/docker/engine-api/types"
 	"github.com/docker/engine-api/types/versions"
 	"github.com/gorilla/mux"
@@ -28,6 +29,20 @@ type inputValidationError interface {
 	IsValidationError() bool
 }
 
+// codedError is an interface that errors with
+// a machine-readable code implement to tell
+// the api layer which code to return.
+type codedError interface {
+	ErrorCode() string
+}
+
+// detailedError is an interface that errors with
+// details implement to tell the api layer
+// which details to return.
+type detailedError interface {
+	ErrorDetails() map[string]string
+}
+
 // GetHTTPErrorStatusCode retrieve status code from error message
 func GetHTTPErrorStatusCode(err error) int {
 	if err == nil {
@@ -75,6 +90,15 @@ func GetHTTPErrorStatusCode(err error) int {
 	return statusCode
 }
 
+// GetErrorCode returns the machine-readable code of an error,
+// or the generic code of its status code.
+func GetErrorCode(err error, statusCode int) string {
+	if e, ok := err.(codedError); ok && e.ErrorCode() != "" {
+		return e.ErrorCode()
+	}
+	return errors.CodeForStatus(statusCode)
+}
+
 // MakeErrorHandler makes an HTTP handler that decodes a Docker error and
 // returns it in the response.
 func MakeErrorHandler(err error) http.HandlerFunc {
@@ -85,6 +109,12 @@ func MakeErrorHandler(err error) http.HandlerFunc {
 			response := &types.ErrorResponse{
 				Message: err.Error(),
 			}
+			if vars["version"] == "" || versions.GreaterThanOrEqualTo(vars["version"], "1.25") {
+				response.Code = GetErrorCode(err, statusCode)
+				if e, ok := err.(detailedError); ok {
+					response.Details = e.ErrorDetails()
+				}
+			}
 			WriteJSON(w, statusCode, response)
 		} else {
 			http.Error(w, grpc.ErrorDesc(err), statusCode)
@@ -0,0 +1,66 @@
+package httputils
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/docker/docker/errors"
+	"github.com/docker/engine-api/types"
+	"github.com/gorilla/mux"
+)
+
+func TestMakeErrorHandler(t *testing.T) {
+	serve := func(err error, path string) *httptest.ResponseRecorder {
+		router := mux.NewRouter()
+		router.Path("/v{version:[0-9.]+}/test").Handler(MakeErrorHandler(err))
+		router.Path("/test").Handler(MakeErrorHandler(err))
+		r, _ := http.NewRequest("GET", path, nil)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, r)
+		return w
+	}
+	decode := func(w *httptest.ResponseRecorder) types.ErrorResponse {
+		var response types.ErrorResponse
+		if err := json.NewDecoder(w.Body).Decode(&response); err != nil {
+			t.Fatal(err)
+		}
+		return response
+	}
+
+	notFound := errors.NewErrorWithCode(fmt.Errorf("No such container: web"), http.StatusNotFound, errors.CodeNoSuchContainer, map[string]string{"container": "web"})
+	for _, path := range []string{"/test", "/v1.25/test"} {
+		w := serve(notFound, path)
+		expected := types.ErrorResponse{
+			Message: "No such container: web",
+			Code:    errors.CodeNoSuchContainer,
+			Details: map[string]string{"container": "web"},
+		}
+		if response := decode(w); w.Code != http.StatusNotFound || !reflect.DeepEqual(response, expected) {
+			t.Fatalf("expected %v for %s, got %d %v", expected, path, w.Code, response)
+		}
+	}
+
+	w := serve(errors.NewRequestConflictError(fmt.Errorf("in use")), "/v1.25/test")
+	if response := decode(w); w.Code != http.StatusConflict || response.Code != errors.CodeConflict {
+		t.Fatalf("expected the generic code of a conflict, got %d %v", w.Code, response)
+	}
+	w = serve(fmt.Errorf("failed"), "/v1.25/test")
+	if response := decode(w); w.Code != http.StatusInternalServerError || response.Code != errors.CodeInternal {
+		t.Fatalf("expected the generic code of an internal error, got %d %v", w.Code, response)
+	}
+
+	w = serve(notFound, "/v1.24/test")
+	if response := decode(w); response.Code != "" || response.Details != nil || response.Message != "No such container: web" {
+		t.Fatalf("expected only a message for API version 1.24, got %v", response)
+	}
+
+	w = serve(notFound, "/v1.23/test")
+	if body := strings.TrimSpace(w.Body.String()); w.Code != http.StatusNotFound || body != "No such container: web" {
+		t.Fatalf("expected a plain text error for API version 1.23, got %d %q", w.Code, body)
+	}
+}
@@ -0,0 +1,89 @@
+package httputils
+
+import (
+	"fmt"
+	"net/http"
+	"reflect"
+	"strings"
+
+	"github.com/docker/docker/errors"
+)
+
+// FieldsValue returns the JSON names of the fields selected with the
+// comma-separated "fields" form value of a request, or nil if none is.
+func FieldsValue(r *http.Request) []string {
+	fields := strings.TrimSpace(r.Form.Get("fields"))
+	if fields == "" {
+		return nil
+	}
+	var names []string
+	for _, name := range strings.Split(fields, ",") {
+		if name = strings.TrimSpace(name); name != "" {
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+// SelectFields returns the elements of list, a slice of structs or of
+// pointers to structs, as maps of their fields named in fields by their JSON
+// names, so that only these fields are marshaled. It returns list itself if
+// fields is empty. A field unknown to the structs is a bad request.
+func SelectFields(list interface{}, fields []string) (interface{}, error) {
+	if len(fields) == 0 {
+		return list, nil
+	}
+
+	v := reflect.ValueOf(list)
+	if v.Kind() != reflect.Slice {
+		return nil, fmt.Errorf("cannot select the fields of a %s", v.Kind())
+	}
+	t := v.Type().Elem()
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("cannot select the fields of a slice of %s", t.Kind())
+	}
+
+	indexes := make(map[string]int)
+	for i := 0; i < t.NumField(); i++ {
+		if name := jsonFieldName(t.Field(i)); name != "" {
+			indexes[name] = i
+		}
+	}
+	for _, field := range fields {
+		if _, ok := indexes[field]; !ok {
+			return nil, errors.NewBadRequestError(fmt.Errorf("Unknown field %q", field))
+		}
+	}
+
+	selected := make([]map[string]interface{}, 0, v.Len())
+	for i := 0; i < v.Len(); i++ {
+		elem := reflect.Indirect(v.Index(i))
+		m := make(map[string]interface{}, len(fields))
+		for _, field := range fields {
+			if elem.IsValid() {
+				m[field] = elem.Field(indexes[field]).Interface()
+			}
+		}
+		selected = append(selected, m)
+	}
+	return selected, nil
+}
+
+// jsonFieldName returns the name of an exported struct field in JSON, or an
+// empty string if the field isn't marshaled.
+func jsonFieldName(f reflect.StructField) string {
+	if f.PkgPath != "" {
+		return ""
+	}
+	name := strings.Split(f.Tag.Get("json"), ",")[0]
+	if name == "-" {
+		return ""
+	}
+	if name == "" {
+		name = f.Name
+	}
+	return name
+}
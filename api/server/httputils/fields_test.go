@@ -0,0 +1,50 @@
+package httputils
+
+import (
+	"net/http"
+	"reflect"
+	"testing"
+
+	"github.com/docker/engine-api/types"
+)
+
+func TestFieldsValue(t *testing.T) {
+	r, _ := http.NewRequest("GET", "/containers/json?fields=Id,%20Names,,", nil)
+	r.ParseForm()
+	if fields, expected := FieldsValue(r), []string{"Id", "Names"}; !reflect.DeepEqual(fields, expected) {
+		t.Fatalf("expected %v, got %v", expected, fields)
+	}
+
+	r, _ = http.NewRequest("GET", "/containers/json", nil)
+	r.ParseForm()
+	if fields := FieldsValue(r); fields != nil {
+		t.Fatalf("expected no fields, got %v", fields)
+	}
+}
+
+func TestSelectFields(t *testing.T) {
+	containers := []*types.Container{
+		{ID: "a", Names: []string{"/web"}, State: "running"},
+		{ID: "b", Names: []string{"/db"}, State: "exited"},
+	}
+
+	if selected, err := SelectFields(containers, nil); err != nil || !reflect.DeepEqual(selected, containers) {
+		t.Fatalf("expected the list itself without fields, got %v, %v", selected, err)
+	}
+
+	selected, err := SelectFields(containers, []string{"Id", "State"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := []map[string]interface{}{
+		{"Id": "a", "State": "running"},
+		{"Id": "b", "State": "exited"},
+	}
+	if !reflect.DeepEqual(selected, expected) {
+		t.Fatalf("expected %v, got %v", expected, selected)
+	}
+
+	if _, err := SelectFields(containers, []string{"ID"}); err == nil || GetHTTPErrorStatusCode(err) != http.StatusBadRequest {
+		t.Fatalf("expected a bad request for an unknown field, got %v", err)
+	}
+}
@@ -4,7 +4,9 @@ import (
 	"encoding/json"
 	"fmt"
 	"io"
+	"mime"
 	"net/http"
+	"strconv"
 	"strings"
 
 	"golang.org/x/net/context"
@@ -65,6 +67,33 @@ func CheckForJSON(r *http.Request) error {
 	return fmt.Errorf("Content-Type specified (%s) must be 'application/json'", ct)
 }
 
+// AcceptsMsgpack returns true if the Accept header of r prefers msgpack
+// documents, which streaming endpoints send instead of JSON documents. The
+// clients have to ask for them explicitly: msgpack is preferred if it is
+// accepted with a quality not lower than application/json.
+func AcceptsMsgpack(r *http.Request) bool {
+	var msgpackQ, jsonQ float64
+	for _, accepted := range strings.Split(r.Header.Get("Accept"), ",") {
+		mediaType, params, err := mime.ParseMediaType(strings.TrimSpace(accepted))
+		if err != nil {
+			continue
+		}
+		q := 1.0
+		if v, ok := params["q"]; ok {
+			if q, err = strconv.ParseFloat(v, 64); err != nil {
+				continue
+			}
+		}
+		switch mediaType {
+		case MsgpackMediaType:
+			msgpackQ = q
+		case "application/json":
+			jsonQ = q
+		}
+	}
+	return msgpackQ > 0 && msgpackQ >= jsonQ
+}
+
 // ParseForm ensures the request form is parsed even with invalid content types.
 // If we don't do this, POST method without Content-type (even with empty body) will fail.
 func ParseForm(r *http.Request) error {
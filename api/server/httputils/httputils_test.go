@@ -0,0 +1,29 @@
+package httputils
+
+import (
+	"net/http"
+	"testing"
+)
+
+func TestAcceptsMsgpack(t *testing.T) {
+	cases := map[string]bool{
+		"":                          false,
+		"*/*":                       false,
+		"application/json":          false,
+		"application/x-msgpack":     true,
+		"application/x-msgpack;q=0": false,
+		"application/json, application/x-msgpack":           true,
+		"application/json, application/x-msgpack;q=0.5":     false,
+		"application/json;q=0.5, application/x-msgpack":     true,
+		"text/plain, application/x-msgpack;q=0.9":           true,
+		"application/x-msgpack;q=invalid, application/json": false,
+	}
+
+	for accept, expected := range cases {
+		r, _ := http.NewRequest("GET", "/events", nil)
+		r.Header.Set("Accept", accept)
+		if actual := AcceptsMsgpack(r); actual != expected {
+			t.Fatalf("expected AcceptsMsgpack to be %v for %q, got %v", expected, accept, actual)
+		}
+	}
+}
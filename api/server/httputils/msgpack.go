@@ -0,0 +1,86 @@
+package httputils
+
+import (
+	"encoding/binary"
+	"io"
+	"reflect"
+	"time"
+
+	"github.com/ugorji/go/codec"
+)
+
+// MsgpackMediaType is the media type of the msgpack documents sent instead
+// of JSON documents to the clients accepting them.
+const MsgpackMediaType = "application/x-msgpack"
+
+// msgpackTimestampType is the extension type of the msgpack timestamps, -1.
+const msgpackTimestampType = 0xff
+
+// msgpackHandle encodes the documents like their JSON counterpart: the
+// fields of the structs are named after their json tags, and the keys of
+// the maps are sorted. Byte slices are encoded as binary data, and times as
+// msgpack timestamps.
+var msgpackHandle = newMsgpackHandle()
+
+func newMsgpackHandle() *codec.MsgpackHandle {
+	h := &codec.MsgpackHandle{WriteExt: true}
+	h.TypeInfos = codec.NewTypeInfos([]string{"json"})
+	h.Canonical = true
+	if err := h.SetBytesExt(reflect.TypeOf(time.Time{}), msgpackTimestampType, msgpackTimestampExt{}); err != nil {
+		panic(err)
+	}
+	return h
+}
+
+// NewMsgpackEncoder returns an encoder writing msgpack documents to w. The
+// documents are self-delimiting, so a stream is the concatenation of its
+// documents.
+func NewMsgpackEncoder(w io.Writer) *codec.Encoder {
+	return codec.NewEncoder(w, msgpackHandle)
+}
+
+// msgpackTimestampExt encodes times as msgpack timestamps, in the smallest
+// of the 32, 64 and 96 bits formats holding them.
+type msgpackTimestampExt struct{}
+
+func (msgpackTimestampExt) WriteExt(v interface{}) []byte {
+	var t time.Time
+	switch v := v.(type) {
+	case time.Time:
+		t = v
+	case *time.Time:
+		t = *v
+	}
+	sec, nsec := t.Unix(), uint64(t.Nanosecond())
+	if sec>>34 == 0 {
+		data := nsec<<34 | uint64(sec)
+		if data&0xffffffff00000000 == 0 {
+			b := make([]byte, 4)
+			binary.BigEndian.PutUint32(b, uint32(data))
+			return b
+		}
+		b := make([]byte, 8)
+		binary.BigEndian.PutUint64(b, data)
+		return b
+	}
+	b := make([]byte, 12)
+	binary.BigEndian.PutUint32(b, uint32(nsec))
+	binary.BigEndian.PutUint64(b[4:], uint64(sec))
+	return b
+}
+
+func (msgpackTimestampExt) ReadExt(dst interface{}, src []byte) {
+	var sec, nsec int64
+	switch len(src) {
+	case 4:
+		sec = int64(binary.BigEndian.Uint32(src))
+	case 8:
+		data := binary.BigEndian.Uint64(src)
+		sec, nsec = int64(data&0x00000003ffffffff), int64(data>>34)
+	case 12:
+		nsec, sec = int64(binary.BigEndian.Uint32(src)), int64(binary.BigEndian.Uint64(src[4:]))
+	default:
+		panic("msgpack: invalid timestamp")
+	}
+	*dst.(*time.Time) = time.Unix(sec, nsec).UTC()
+}
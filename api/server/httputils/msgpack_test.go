@@ -0,0 +1,58 @@
+package httputils
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/ugorji/go/codec"
+)
+
+type testMsgpackDocument struct {
+	Name    string    `json:"name"`
+	Skipped string    `json:"-"`
+	Empty   string    `json:"empty,omitempty"`
+	Data    []byte    `json:"data"`
+	Time    time.Time `json:"time"`
+}
+
+func TestMsgpackEncoder(t *testing.T) {
+	var buf bytes.Buffer
+	enc := NewMsgpackEncoder(&buf)
+	doc := testMsgpackDocument{Name: "web", Skipped: "x", Data: []byte("ab"), Time: time.Unix(1, 0)}
+	if err := enc.Encode(doc); err != nil {
+		t.Fatal(err)
+	}
+
+	// the fields are named after their json tags and sorted, the bytes are
+	// binary data and the time a 32 bits timestamp
+	expected := []byte{0x83,
+		0xa4, 'd', 'a', 't', 'a', 0xc4, 0x02, 'a', 'b',
+		0xa4, 'n', 'a', 'm', 'e', 0xa3, 'w', 'e', 'b',
+		0xa4, 't', 'i', 'm', 'e', 0xd6, 0xff, 0, 0, 0, 1,
+	}
+	if !bytes.Equal(buf.Bytes(), expected) {
+		t.Fatalf("expected % x, got % x", expected, buf.Bytes())
+	}
+}
+
+func TestMsgpackTimestamps(t *testing.T) {
+	for _, tm := range []time.Time{
+		time.Unix(1, 0),
+		time.Unix(1476628800, 123456789),
+		time.Unix(1<<35, 1),
+		time.Unix(-1, 0),
+	} {
+		var buf bytes.Buffer
+		if err := NewMsgpackEncoder(&buf).Encode(tm); err != nil {
+			t.Fatal(err)
+		}
+		var decoded time.Time
+		if err := codec.NewDecoder(&buf, msgpackHandle).Decode(&decoded); err != nil {
+			t.Fatal(err)
+		}
+		if !decoded.Equal(tm) {
+			t.Fatalf("expected %v, got %v", tm, decoded)
+		}
+	}
+}
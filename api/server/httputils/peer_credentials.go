@@ -0,0 +1,28 @@
+package httputils
+
+import "fmt"
+
+// PeerCredentials are the credentials of the process at the other end of a
+// unix socket connection. The server reports them as the remote address of
+// the requests of these connections when it looks them up.
+type PeerCredentials struct {
+	Pid int
+	UID int
+	GID int
+}
+
+// String returns the credentials in the form "pid=1,uid=0,gid=0", which is
+// used as the remote address of the requests.
+func (c PeerCredentials) String() string {
+	return fmt.Sprintf("pid=%d,uid=%d,gid=%d", c.Pid, c.UID, c.GID)
+}
+
+// ParsePeerCredentials returns the credentials in remoteAddr, the remote
+// address of a request, and false if the address does not hold any.
+func ParsePeerCredentials(remoteAddr string) (PeerCredentials, bool) {
+	var c PeerCredentials
+	if _, err := fmt.Sscanf(remoteAddr, "pid=%d,uid=%d,gid=%d", &c.Pid, &c.UID, &c.GID); err != nil {
+		return PeerCredentials{}, false
+	}
+	return c, true
+}
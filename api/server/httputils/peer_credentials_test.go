@@ -0,0 +1,17 @@
+package httputils
+
+import "testing"
+
+func TestParsePeerCredentials(t *testing.T) {
+	c := PeerCredentials{Pid: 1234, UID: 1000, GID: 100}
+	parsed, ok := ParsePeerCredentials(c.String())
+	if !ok || parsed != c {
+		t.Fatalf("expected %v, got %v (%v)", c, parsed, ok)
+	}
+
+	for _, addr := range []string{"", "@", "127.0.0.1:4243", "pid=1,uid=0"} {
+		if _, ok := ParsePeerCredentials(addr); ok {
+			t.Fatalf("expected no credentials in %q", addr)
+		}
+	}
+}
@@ -0,0 +1,189 @@
+package middleware
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/Sirupsen/logrus"
+	"github.com/docker/docker/api/server/httputils"
+	"github.com/docker/docker/pkg/ioutils"
+	"github.com/docker/engine-api/types/strslice"
+	"golang.org/x/net/context"
+)
+
+// maxAuditBodySize is the size of the largest request body whose fields
+// are audited.
+const maxAuditBodySize = 1048576 // 1MB
+
+// auditBodyEndpoints are the endpoints whose request body has fields that
+// are audited: the creation of containers and of exec instances.
+var auditBodyEndpoints = regexp.MustCompile(`^(/v[0-9.]+)?/containers/(create|[^/]+/exec)$`)
+
+// AuditMiddleware is a middleware that logs the requests changing the
+// state of the daemon, with their result and the identity of their caller,
+// to the journal.
+type AuditMiddleware struct{}
+
+// NewAuditMiddleware creates a new AuditMiddleware.
+func NewAuditMiddleware() AuditMiddleware {
+	return AuditMiddleware{}
+}
+
+// WrapHandler returns a new handler function wrapping the previous one in the request chain.
+func (a AuditMiddleware) WrapHandler(handler func(ctx context.Context, w http.ResponseWriter, r *http.Request, vars map[string]string) error) func(ctx context.Context, w http.ResponseWriter, r *http.Request, vars map[string]string) error {
+	return func(ctx context.Context, w http.ResponseWriter, r *http.Request, vars map[string]string) error {
+		switch r.Method {
+		case "GET", "HEAD", "OPTIONS":
+			return handler(ctx, w, r, vars)
+		}
+
+		bodyFields := auditBody(r)
+		err := handler(ctx, w, r, vars)
+		fields := auditFields(r, err)
+		for k, v := range bodyFields {
+			fields[k] = v
+		}
+		sendAudit(auditMessage(fields), fields)
+		return err
+	}
+}
+
+// auditFields returns the fields of the audit log entry of the request r,
+// which returned err. Their names follow the conventions of the journal.
+func auditFields(r *http.Request, err error) map[string]string {
+	fields := map[string]string{
+		"DOCKER_API_METHOD":   r.Method,
+		"DOCKER_API_ENDPOINT": r.URL.Path,
+		"DOCKER_API_RESULT":   "success",
+	}
+	if args := auditArgs(r.URL.Query()); args != "" {
+		fields["DOCKER_API_ARGS"] = args
+	}
+	if err != nil {
+		fields["DOCKER_API_RESULT"] = "failure"
+		fields["DOCKER_API_STATUS"] = strconv.Itoa(httputils.GetHTTPErrorStatusCode(err))
+		fields["DOCKER_API_ERROR"] = err.Error()
+	}
+
+	if r.TLS != nil && len(r.TLS.PeerCertificates) > 0 {
+		fields["DOCKER_USER"] = r.TLS.PeerCertificates[0].Subject.CommonName
+		fields["DOCKER_USER_AUTHN"] = "TLS"
+	}
+	if creds, ok := httputils.ParsePeerCredentials(r.RemoteAddr); ok {
+		fields["DOCKER_CALLER_PID"] = strconv.Itoa(creds.Pid)
+		fields["DOCKER_CALLER_UID"] = strconv.Itoa(creds.UID)
+		fields["DOCKER_CALLER_GID"] = strconv.Itoa(creds.GID)
+		if loginUID, ok := getLoginUID(creds.Pid); ok {
+			fields["DOCKER_CALLER_LOGINUID"] = loginUID
+		}
+	} else if r.RemoteAddr != "" && r.RemoteAddr != "@" {
+		fields["DOCKER_CALLER_ADDR"] = r.RemoteAddr
+	}
+	return fields
+}
+
+// auditBody returns the fields of the body of r to audit, like the image and
+// the command of the containers created, and leaves the body to be read by
+// the handler. Bodies larger than maxAuditBodySize are not audited.
+func auditBody(r *http.Request) map[string]string {
+	if !auditBodyEndpoints.MatchString(r.URL.Path) || r.Body == nil || httputils.CheckForJSON(r) != nil {
+		return nil
+	}
+
+	body := r.Body
+	bufReader := bufio.NewReaderSize(body, maxAuditBodySize)
+	r.Body = ioutils.NewReadCloserWrapper(bufReader, func() error { return body.Close() })
+	data, err := bufReader.Peek(maxAuditBodySize)
+	if err != io.EOF {
+		if err == nil {
+			logrus.Warnf("Request body of %s is larger than %d, not auditing it", r.URL.Path, maxAuditBodySize)
+		}
+		return nil
+	}
+
+	// The body of exec instances has the same fields as the configuration
+	// of containers, except for the host configuration.
+	var config struct {
+		Image      string
+		Cmd        strslice.StrSlice
+		Entrypoint strslice.StrSlice
+		User       string
+		Privileged bool
+		HostConfig *struct {
+			Privileged bool
+		}
+	}
+	if err := json.Unmarshal(data, &config); err != nil {
+		return nil
+	}
+
+	fields := make(map[string]string)
+	if config.Image != "" {
+		fields["DOCKER_API_IMAGE"] = config.Image
+	}
+	for name, value := range map[string]strslice.StrSlice{"DOCKER_API_CMD": config.Cmd, "DOCKER_API_ENTRYPOINT": config.Entrypoint} {
+		if len(value) > 0 {
+			encoded, _ := json.Marshal([]string(value))
+			fields[name] = string(encoded)
+		}
+	}
+	if config.User != "" {
+		fields["DOCKER_API_USER"] = config.User
+	}
+	if config.Privileged || (config.HostConfig != nil && config.HostConfig.Privileged) {
+		fields["DOCKER_API_PRIVILEGED"] = "true"
+	}
+	return fields
+}
+
+// auditArgs summarizes the query parameters of a request, with the values
+// of the secret ones masked.
+func auditArgs(query url.Values) string {
+	for k, values := range query {
+		for _, m := range []string{"password", "secret"} {
+			if strings.Contains(strings.ToLower(k), m) {
+				for i := range values {
+					values[i] = "*****"
+				}
+			}
+		}
+	}
+	encoded := query.Encode()
+	if args, err := url.QueryUnescape(encoded); err == nil {
+		return args
+	}
+	return encoded
+}
+
+// auditMessage returns the human readable message of an audit log entry.
+func auditMessage(fields map[string]string) string {
+	caller := "unknown caller"
+	switch {
+	case fields["DOCKER_USER"] != "":
+		caller = "user " + fields["DOCKER_USER"]
+	case fields["DOCKER_CALLER_LOGINUID"] != "":
+		caller = fmt.Sprintf("pid %s, uid %s, loginuid %s", fields["DOCKER_CALLER_PID"], fields["DOCKER_CALLER_UID"], fields["DOCKER_CALLER_LOGINUID"])
+	case fields["DOCKER_CALLER_PID"] != "":
+		caller = fmt.Sprintf("pid %s, uid %s", fields["DOCKER_CALLER_PID"], fields["DOCKER_CALLER_UID"])
+	case fields["DOCKER_CALLER_ADDR"] != "":
+		caller = fields["DOCKER_CALLER_ADDR"]
+	}
+	return fmt.Sprintf("%s %s by %s: %s", fields["DOCKER_API_METHOD"], fields["DOCKER_API_ENDPOINT"], caller, fields["DOCKER_API_RESULT"])
+}
+
+// logAudit logs an audit log entry with the logger of the daemon, when the
+// journal is not available.
+func logAudit(message string, fields map[string]string) {
+	logFields := logrus.Fields{}
+	for k, v := range fields {
+		logFields[k] = v
+	}
+	logrus.WithFields(logFields).Info(message)
+}
@@ -0,0 +1,41 @@
+package middleware
+
+import (
+	"fmt"
+	"io/ioutil"
+	"strings"
+
+	"github.com/Sirupsen/logrus"
+	"github.com/coreos/go-systemd/journal"
+)
+
+// unsetLoginUID is the login UID of the processes started outside of a
+// login session, like the ones of services.
+const unsetLoginUID = "4294967295"
+
+// sendAudit sends an audit log entry to the journal, or logs it with the
+// logger of the daemon if the journal is not available.
+func sendAudit(message string, fields map[string]string) {
+	if journal.Enabled() {
+		err := journal.Send(message, journal.PriInfo, fields)
+		if err == nil {
+			return
+		}
+		logrus.Debugf("Error sending an audit log entry to the journal: %v", err)
+	}
+	logAudit(message, fields)
+}
+
+// getLoginUID returns the UID the user of the process with the given pid
+// logged in with, which is kept across su and sudo.
+func getLoginUID(pid int) (string, bool) {
+	b, err := ioutil.ReadFile(fmt.Sprintf("/proc/%d/loginuid", pid))
+	if err != nil {
+		return "", false
+	}
+	loginUID := strings.TrimSpace(string(b))
+	if loginUID == "" || loginUID == unsetLoginUID {
+		return "", false
+	}
+	return loginUID, true
+}
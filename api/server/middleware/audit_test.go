@@ -0,0 +1,105 @@
+package middleware
+
+import (
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	derrors "github.com/docker/docker/errors"
+	"golang.org/x/net/context"
+)
+
+func TestAuditMiddlewareSkipsReads(t *testing.T) {
+	called := false
+	handler := func(ctx context.Context, w http.ResponseWriter, r *http.Request, vars map[string]string) error {
+		called = true
+		return nil
+	}
+	h := NewAuditMiddleware().WrapHandler(handler)
+
+	req, _ := http.NewRequest("GET", "/containers/json", nil)
+	if err := h(context.Background(), httptest.NewRecorder(), req, nil); err != nil {
+		t.Fatal(err)
+	}
+	if !called {
+		t.Fatal("expected the handler to be called")
+	}
+}
+
+func TestAuditFields(t *testing.T) {
+	req, _ := http.NewRequest("POST", "/v1.25/containers/create?name=web&password=hunter2", nil)
+	req.RemoteAddr = "pid=1234,uid=1000,gid=1000"
+	fields := auditFields(req, nil)
+
+	expected := map[string]string{
+		"DOCKER_API_METHOD":   "POST",
+		"DOCKER_API_ENDPOINT": "/v1.25/containers/create",
+		"DOCKER_API_RESULT":   "success",
+		"DOCKER_API_ARGS":     "name=web&password=*****",
+		"DOCKER_CALLER_PID":   "1234",
+		"DOCKER_CALLER_UID":   "1000",
+		"DOCKER_CALLER_GID":   "1000",
+	}
+	for k, v := range expected {
+		if fields[k] != v {
+			t.Fatalf("expected %s=%q, got %q", k, v, fields[k])
+		}
+	}
+	if _, ok := fields["DOCKER_API_ERROR"]; ok {
+		t.Fatalf("expected no error in %v", fields)
+	}
+
+	req, _ = http.NewRequest("POST", "/containers/web/start", nil)
+	req.RemoteAddr = "10.0.0.1:51234"
+	fields = auditFields(req, derrors.NewRequestNotFoundError(errors.New("No such container: web")))
+	if fields["DOCKER_API_RESULT"] != "failure" || fields["DOCKER_API_STATUS"] != "404" || fields["DOCKER_API_ERROR"] != "No such container: web" {
+		t.Fatalf("expected a not found failure, got %v", fields)
+	}
+	if fields["DOCKER_CALLER_ADDR"] != "10.0.0.1:51234" {
+		t.Fatalf("expected the remote address as caller, got %v", fields)
+	}
+	if msg := auditMessage(fields); msg != "POST /containers/web/start by 10.0.0.1:51234: failure" {
+		t.Fatalf("unexpected message %q", msg)
+	}
+}
+
+func TestAuditBody(t *testing.T) {
+	body := `{"Image":"busybox","Cmd":["sh","-c","id"],"User":"root","HostConfig":{"Privileged":true}}`
+	req, _ := http.NewRequest("POST", "/v1.25/containers/create?name=web", strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	fields := auditBody(req)
+
+	expected := map[string]string{
+		"DOCKER_API_IMAGE":      "busybox",
+		"DOCKER_API_CMD":        `["sh","-c","id"]`,
+		"DOCKER_API_USER":       "root",
+		"DOCKER_API_PRIVILEGED": "true",
+	}
+	if len(fields) != len(expected) {
+		t.Fatalf("expected %v, got %v", expected, fields)
+	}
+	for k, v := range expected {
+		if fields[k] != v {
+			t.Fatalf("expected %s=%q, got %q", k, v, fields[k])
+		}
+	}
+	// the handler still reads the whole body
+	if data, err := ioutil.ReadAll(req.Body); err != nil || string(data) != body {
+		t.Fatalf("expected the body to be left to the handler, got %q, %v", data, err)
+	}
+
+	req, _ = http.NewRequest("POST", "/containers/web/exec", strings.NewReader(`{"Cmd":"top","Privileged":false}`))
+	req.Header.Set("Content-Type", "application/json")
+	if fields := auditBody(req); len(fields) != 1 || fields["DOCKER_API_CMD"] != `["top"]` {
+		t.Fatalf("unexpected exec fields %v", fields)
+	}
+
+	req, _ = http.NewRequest("POST", "/containers/web/start", strings.NewReader(`{"Image":"busybox"}`))
+	req.Header.Set("Content-Type", "application/json")
+	if fields := auditBody(req); fields != nil {
+		t.Fatalf("expected only the body of create and exec requests to be audited, got %v", fields)
+	}
+}
@@ -0,0 +1,14 @@
+// +build !linux
+
+package middleware
+
+// sendAudit logs an audit log entry with the logger of the daemon, since
+// the journal is only available on Linux.
+func sendAudit(message string, fields map[string]string) {
+	logAudit(message, fields)
+}
+
+// getLoginUID returns no login UID, since they are only available on Linux.
+func getLoginUID(pid int) (string, bool) {
+	return "", false
+}
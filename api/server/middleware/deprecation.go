@@ -0,0 +1,72 @@
+package middleware
+
+import (
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/Sirupsen/logrus"
+	"github.com/docker/engine-api/types/versions"
+	"golang.org/x/net/context"
+)
+
+// maxReportedClients is the maximum number of clients of deprecated API
+// versions that are logged, so that clients sending arbitrary user agents
+// cannot grow the set of reported clients without bound.
+const maxReportedClients = 1000
+
+// DeprecationMiddleware is a middleware that signals the clients using
+// deprecated API versions that they must be upgraded, with the Deprecation
+// and Sunset headers of the responses.
+type DeprecationMiddleware struct {
+	deprecatedVersion string
+	sunset            time.Time
+
+	mu       sync.Mutex
+	reported map[string]struct{}
+}
+
+// NewDeprecationMiddleware creates a new DeprecationMiddleware marking the
+// API versions lower than deprecatedVersion as deprecated. The responses
+// to such requests announce sunset as the date the versions stop being
+// served, unless sunset is zero.
+func NewDeprecationMiddleware(deprecatedVersion string, sunset time.Time) *DeprecationMiddleware {
+	return &DeprecationMiddleware{
+		deprecatedVersion: deprecatedVersion,
+		sunset:            sunset,
+		reported:          make(map[string]struct{}),
+	}
+}
+
+// WrapHandler returns a new handler function wrapping the previous one in the request chain.
+func (m *DeprecationMiddleware) WrapHandler(handler func(ctx context.Context, w http.ResponseWriter, r *http.Request, vars map[string]string) error) func(ctx context.Context, w http.ResponseWriter, r *http.Request, vars map[string]string) error {
+	return func(ctx context.Context, w http.ResponseWriter, r *http.Request, vars map[string]string) error {
+		apiVersion := vars["version"]
+		if apiVersion != "" && versions.LessThan(apiVersion, m.deprecatedVersion) {
+			w.Header().Set("Deprecation", "true")
+			if !m.sunset.IsZero() {
+				w.Header().Set("Sunset", m.sunset.UTC().Format(http.TimeFormat))
+			}
+			m.report(apiVersion, r.UserAgent())
+		}
+		return handler(ctx, w, r, vars)
+	}
+}
+
+// report logs the first request of each client using a deprecated API
+// version, so that operators know which clients must be upgraded.
+func (m *DeprecationMiddleware) report(apiVersion, userAgent string) {
+	key := apiVersion + " " + userAgent
+	m.mu.Lock()
+	_, reported := m.reported[key]
+	if !reported && len(m.reported) < maxReportedClients {
+		m.reported[key] = struct{}{}
+	} else {
+		reported = true
+	}
+	m.mu.Unlock()
+
+	if !reported {
+		logrus.Warnf("Client using deprecated API version %s (User-Agent: %q), API versions lower than %s are deprecated", apiVersion, userAgent, m.deprecatedVersion)
+	}
+}
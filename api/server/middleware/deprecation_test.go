@@ -0,0 +1,72 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"golang.org/x/net/context"
+)
+
+func TestDeprecationMiddleware(t *testing.T) {
+	handler := func(ctx context.Context, w http.ResponseWriter, r *http.Request, vars map[string]string) error {
+		return nil
+	}
+
+	sunset := time.Date(2017, time.March, 1, 0, 0, 0, 0, time.UTC)
+	m := NewDeprecationMiddleware("1.21", sunset)
+	h := m.WrapHandler(handler)
+
+	for _, tc := range []struct {
+		version    string
+		deprecated bool
+	}{
+		{"", false},
+		{"1.19", true},
+		{"1.20", true},
+		{"1.21", false},
+		{"1.24", false},
+	} {
+		req, _ := http.NewRequest("GET", "/containers/json", nil)
+		resp := httptest.NewRecorder()
+		if err := h(context.Background(), resp, req, map[string]string{"version": tc.version}); err != nil {
+			t.Fatal(err)
+		}
+
+		deprecation, sunsetHeader := resp.Header().Get("Deprecation"), resp.Header().Get("Sunset")
+		if !tc.deprecated {
+			if deprecation != "" || sunsetHeader != "" {
+				t.Fatalf("expected no deprecation headers for version %q, got %q and %q", tc.version, deprecation, sunsetHeader)
+			}
+			continue
+		}
+		if deprecation != "true" {
+			t.Fatalf("expected Deprecation header for version %q, got %q", tc.version, deprecation)
+		}
+		if sunsetHeader != "Wed, 01 Mar 2017 00:00:00 GMT" {
+			t.Fatalf("expected Sunset header for version %q, got %q", tc.version, sunsetHeader)
+		}
+	}
+}
+
+func TestDeprecationMiddlewareWithoutSunset(t *testing.T) {
+	handler := func(ctx context.Context, w http.ResponseWriter, r *http.Request, vars map[string]string) error {
+		return nil
+	}
+
+	m := NewDeprecationMiddleware("1.21", time.Time{})
+	h := m.WrapHandler(handler)
+
+	req, _ := http.NewRequest("GET", "/containers/json", nil)
+	resp := httptest.NewRecorder()
+	if err := h(context.Background(), resp, req, map[string]string{"version": "1.20"}); err != nil {
+		t.Fatal(err)
+	}
+	if resp.Header().Get("Deprecation") != "true" {
+		t.Fatalf("expected Deprecation header, got %q", resp.Header().Get("Deprecation"))
+	}
+	if _, ok := resp.Header()["Sunset"]; ok {
+		t.Fatalf("expected no Sunset header, got %q", resp.Header().Get("Sunset"))
+	}
+}
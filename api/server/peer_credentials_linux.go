@@ -0,0 +1,85 @@
+package server
+
+import (
+	"net"
+	"syscall"
+
+	"github.com/Sirupsen/logrus"
+	"github.com/docker/docker/api/server/httputils"
+)
+
+// peerCredentialsListener is a unix socket listener whose connections
+// report the credentials of the process at their other end as their remote
+// address, which becomes the remote address of their requests.
+type peerCredentialsListener struct {
+	net.Listener
+}
+
+// withPeerCredentials returns l so that the requests of its connections have
+// the credentials of their client as remote address, if it is a unix socket
+// listener.
+func withPeerCredentials(l net.Listener) net.Listener {
+	if l.Addr().Network() != "unix" {
+		return l
+	}
+	return peerCredentialsListener{l}
+}
+
+func (l peerCredentialsListener) Accept() (net.Conn, error) {
+	c, err := l.Listener.Accept()
+	if err != nil {
+		return nil, err
+	}
+	uc, ok := c.(*net.UnixConn)
+	if !ok {
+		return c, nil
+	}
+	creds, err := getPeerCredentials(uc)
+	if err != nil {
+		logrus.Debugf("Error getting the credentials of a client of %s: %v", l.Addr(), err)
+		return c, nil
+	}
+	return &peerCredentialsConn{UnixConn: uc, addr: peerCredentialsAddr(creds)}, nil
+}
+
+// peerCredentialsConn embeds the connection so that its other methods, like
+// CloseWrite for the hijacked connections, are kept.
+type peerCredentialsConn struct {
+	*net.UnixConn
+	addr net.Addr
+}
+
+func (c *peerCredentialsConn) RemoteAddr() net.Addr {
+	return c.addr
+}
+
+type peerCredentialsAddr httputils.PeerCredentials
+
+func (a peerCredentialsAddr) Network() string {
+	return "unix"
+}
+
+func (a peerCredentialsAddr) String() string {
+	return httputils.PeerCredentials(a).String()
+}
+
+// getPeerCredentials returns the SO_PEERCRED credentials of c. The socket
+// is duplicated to read them, which puts it in blocking mode, so it is put
+// back in non-blocking mode for the runtime poller to keep handling it.
+func getPeerCredentials(c *net.UnixConn) (httputils.PeerCredentials, error) {
+	f, err := c.File()
+	if err != nil {
+		return httputils.PeerCredentials{}, err
+	}
+	defer f.Close()
+	fd := int(f.Fd())
+
+	ucred, err := syscall.GetsockoptUcred(fd, syscall.SOL_SOCKET, syscall.SO_PEERCRED)
+	if nbErr := syscall.SetNonblock(fd, true); nbErr != nil && err == nil {
+		err = nbErr
+	}
+	if err != nil {
+		return httputils.PeerCredentials{}, err
+	}
+	return httputils.PeerCredentials{Pid: int(ucred.Pid), UID: int(ucred.Uid), GID: int(ucred.Gid)}, nil
+}
@@ -0,0 +1,62 @@
+package server
+
+import (
+	"io/ioutil"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/docker/docker/api/server/httputils"
+)
+
+func TestPeerCredentialsListener(t *testing.T) {
+	tmp, err := ioutil.TempDir("", "docker-peer-credentials-test-")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(tmp)
+
+	l, err := net.Listen("unix", filepath.Join(tmp, "docker.sock"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	l = withPeerCredentials(l)
+	defer l.Close()
+
+	client, err := net.Dial("unix", l.Addr().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer client.Close()
+	c, err := l.Accept()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer c.Close()
+
+	creds, ok := httputils.ParsePeerCredentials(c.RemoteAddr().String())
+	if !ok || creds.Pid != os.Getpid() || creds.UID != os.Getuid() {
+		t.Fatalf("expected the credentials of the test as remote address, got %q", c.RemoteAddr())
+	}
+
+	// The connection is still handled by the runtime poller, so that its
+	// deadlines work
+	c.SetReadDeadline(time.Now().Add(10 * time.Millisecond))
+	done := make(chan error)
+	go func() {
+		_, err := c.Read(make([]byte, 1))
+		done <- err
+	}()
+	select {
+	case err := <-done:
+		if nerr, ok := err.(net.Error); !ok || !nerr.Timeout() {
+			t.Fatalf("expected the read to time out, got %v", err)
+		}
+	case <-time.After(5 * time.Second):
+		// Unblock the read
+		client.Close()
+		t.Fatal("expected the read deadline to be honored")
+	}
+}
@@ -17,4 +17,7 @@ type Backend interface {
 	//
 	// TODO: make this return a reference instead of string
 	BuildFromContext(ctx context.Context, src io.ReadCloser, remote string, buildOptions *types.ImageBuildOptions, pg backend.ProgressWriter) (string, error)
+	// CheckFreeSpace returns an error if a build, which needs about
+	// projected bytes, would leave too little free space.
+	CheckFreeSpace(action string, projected int64) error
 }
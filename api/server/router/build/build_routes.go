@@ -41,6 +41,7 @@ func newImageBuildOptions(ctx context.Context, r *http.Request) (*types.ImageBui
 	options.Dockerfile = r.FormValue("dockerfile")
 	options.SuppressOutput = httputils.BoolValue(r, "q")
 	options.NoCache = httputils.BoolValue(r, "nocache")
+	options.IgnoreFreeSpace = httputils.BoolValue(r, "ignorefreespace")
 	options.ForceRemove = httputils.BoolValue(r, "forcerm")
 	options.MemorySwap = httputils.Int64ValueOrZero(r, "memswap")
 	options.Memory = httputils.Int64ValueOrZero(r, "memory")
@@ -151,6 +152,17 @@ func (br *buildRouter) postBuild(ctx context.Context, w http.ResponseWriter, r *
 	}
 	buildOptions.AuthConfigs = authConfigs
 
+	if !buildOptions.IgnoreFreeSpace {
+		// the size of the context is unknown when it is streamed
+		projected := r.ContentLength
+		if projected < 0 {
+			projected = 0
+		}
+		if err := br.backend.CheckFreeSpace("build", projected); err != nil {
+			return errf(err)
+		}
+	}
+
 	remoteURL := r.FormValue("remote")
 
 	// Currently, only used if context is from a remote url.
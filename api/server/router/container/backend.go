@@ -10,6 +10,7 @@ import (
 	"github.com/docker/docker/pkg/archive"
 	"github.com/docker/engine-api/types"
 	"github.com/docker/engine-api/types/container"
+	"github.com/docker/engine-api/types/filters"
 )
 
 // execBackend includes functions to implement to provide exec functionality.
@@ -18,15 +19,21 @@ type execBackend interface {
 	ContainerExecInspect(id string) (*backend.ExecInspect, error)
 	ContainerExecResize(name string, height, width int) error
 	ContainerExecStart(ctx context.Context, name string, stdin io.ReadCloser, stdout io.Writer, stderr io.Writer) error
+	ContainerExecStats(ctx context.Context, id string, config *backend.ExecStatsConfig) error
 	ExecExists(name string) (bool, error)
 }
 
 // copyBackend includes functions to implement to provide container copy functionality.
 type copyBackend interface {
 	ContainerArchivePath(name string, path string) (content io.ReadCloser, stat *types.ContainerPathStat, err error)
+	ContainerArchiveUploadCreate(name, path string, noOverwriteDirNonDir, copyUIDGID bool) (*types.ArchiveUpload, error)
+	ContainerArchiveUploadExtract(name, id string) error
+	ContainerArchiveUploadInspect(name, id string) (*types.ArchiveUpload, error)
+	ContainerArchiveUploadRemove(name, id string) error
+	ContainerArchiveUploadWrite(name, id string, offset int64, content io.Reader) (*types.ArchiveUpload, error)
 	ContainerCopy(name string, res string) (io.ReadCloser, error)
 	ContainerExport(name string, out io.Writer) error
-	ContainerExtractToDir(name, path string, noOverwriteDirNonDir bool, content io.Reader) error
+	ContainerExtractToDir(name, path string, noOverwriteDirNonDir, copyUIDGID bool, content io.Reader) error
 	ContainerStatPath(name string, path string) (stat *types.ContainerPathStat, err error)
 }
 
@@ -35,15 +42,21 @@ type stateBackend interface {
 	ContainerCreate(config types.ContainerCreateConfig, validateHostname bool) (types.ContainerCreateResponse, error)
 	ContainerKill(name string, sig uint64) error
 	ContainerPause(name string) error
+	ContainerPauseAll(filter filters.Args) (*types.PauseGroupReport, error)
+	ContainerPauseGroup(filter filters.Args) (*types.PauseGroupReport, error)
 	ContainerRename(oldName, newName string) error
 	ContainerResize(name string, height, width int) error
 	ContainerRestart(name string, seconds int) error
 	ContainerRm(name string, config *types.ContainerRmConfig) error
-	ContainerStart(name string, hostConfig *container.HostConfig, validateHostname bool) error
+	ContainersPrune(pruneFilters filters.Args) (*types.ContainersPruneReport, error)
+	ContainerStart(name string, hostConfig *container.HostConfig, validateHostname bool, checkpoint string) error
 	ContainerStop(name string, seconds int) error
 	ContainerUnpause(name string) error
+	ContainerUnpauseAll(filter filters.Args) (*types.PauseGroupReport, error)
+	ContainerUnpauseGroup(filter filters.Args) (*types.PauseGroupReport, error)
 	ContainerUpdate(name string, hostConfig *container.HostConfig, validateHostname bool) ([]string, error)
 	ContainerWait(name string, timeout time.Duration) (int, error)
+	ContainerWaitCondition(ctx context.Context, name string, condition container.WaitCondition, timeout time.Duration) (int, error)
 }
 
 // monitorBackend includes functions to implement to provide containers monitoring functionality.
@@ -51,6 +64,7 @@ type monitorBackend interface {
 	ContainerChanges(name string) ([]archive.Change, error)
 	ContainerInspect(name string, size bool, version string) (interface{}, error)
 	ContainerLogs(ctx context.Context, name string, config *backend.ContainerLogsConfig, started chan struct{}) error
+	ContainerSpec(name string) (*types.ContainerSpec, error)
 	ContainerStats(ctx context.Context, name string, config *backend.ContainerStatsConfig) error
 	ContainerTop(name string, psArgs string) (*types.ContainerProcessList, error)
 
@@ -13,8 +13,10 @@ import (
 	"github.com/Sirupsen/logrus"
 	"github.com/docker/docker/api/server/httputils"
 	"github.com/docker/docker/api/types/backend"
+	"github.com/docker/docker/errors"
 	"github.com/docker/docker/pkg/ioutils"
 	"github.com/docker/docker/pkg/signal"
+	"github.com/docker/docker/utils"
 	"github.com/docker/engine-api/types"
 	"github.com/docker/engine-api/types/container"
 	"github.com/docker/engine-api/types/filters"
@@ -48,12 +50,33 @@ func (s *containerRouter) getContainersJSON(ctx context.Context, w http.Response
 		config.Limit = limit
 	}
 
+	// The offset and the fields were added in API 1.25, and are ignored in
+	// the requests of older clients.
+	version := httputils.VersionFromContext(ctx)
+	newAPI := versions.GreaterThanOrEqualTo(version, "1.25")
+
+	if tmpOffset := r.Form.Get("offset"); tmpOffset != "" && newAPI {
+		offset, err := strconv.Atoi(tmpOffset)
+		if err != nil {
+			return err
+		}
+		config.Offset = offset
+	}
+
 	containers, err := s.backend.Containers(config)
 	if err != nil {
 		return err
 	}
 
-	return httputils.WriteJSON(w, http.StatusOK, containers)
+	var fields []string
+	if newAPI {
+		fields = httputils.FieldsValue(r)
+	}
+	selected, err := httputils.SelectFields(containers, fields)
+	if err != nil {
+		return err
+	}
+	return httputils.WriteJSON(w, http.StatusOK, selected)
 }
 
 func (s *containerRouter) getContainersStats(ctx context.Context, w http.ResponseWriter, r *http.Request, vars map[string]string) error {
@@ -62,7 +85,10 @@ func (s *containerRouter) getContainersStats(ctx context.Context, w http.Respons
 	}
 
 	stream := httputils.BoolValueOrDefault(r, "stream", true)
-	if !stream {
+	useMsgpack := httputils.AcceptsMsgpack(r)
+	if useMsgpack {
+		w.Header().Set("Content-Type", httputils.MsgpackMediaType)
+	} else if !stream {
 		w.Header().Set("Content-Type", "application/json")
 	}
 
@@ -70,11 +96,16 @@ func (s *containerRouter) getContainersStats(ctx context.Context, w http.Respons
 		Stream:    stream,
 		OutStream: w,
 		Version:   string(httputils.VersionFromContext(ctx)),
+		Msgpack:   useMsgpack,
 	}
 
 	return s.backend.ContainerStats(ctx, vars["name"], config)
 }
 
+// logsCursorTrailer is the trailer of the logs of a container holding the
+// cursor of the last message read, for the log drivers with cursors.
+const logsCursorTrailer = "X-Docker-Logs-Cursor"
+
 func (s *containerRouter) getContainersLogs(ctx context.Context, w http.ResponseWriter, r *http.Request, vars map[string]string) error {
 	if err := httputils.ParseForm(r); err != nil {
 		return err
@@ -96,18 +127,37 @@ func (s *containerRouter) getContainersLogs(ctx context.Context, w http.Response
 			Follow:     httputils.BoolValue(r, "follow"),
 			Timestamps: httputils.BoolValue(r, "timestamps"),
 			Since:      r.Form.Get("since"),
+			Until:      r.Form.Get("until"),
 			Tail:       r.Form.Get("tail"),
 			ShowStdout: stdout,
 			ShowStderr: stderr,
 			Details:    httputils.BoolValue(r, "details"),
+			Cursor:     r.Form.Get("cursor"),
 		},
 		OutStream: w,
+		Msgpack:   httputils.AcceptsMsgpack(r),
+	}
+	if logsConfig.Msgpack {
+		w.Header().Set("Content-Type", httputils.MsgpackMediaType)
 	}
+	// The cursor of the last message read is only known once the logs are
+	// sent, so it is sent in a trailer, for the clients to resume from it.
+	w.Header().Set("Trailer", logsCursorTrailer)
+	defer func() {
+		if logsConfig.LastCursor != "" {
+			w.Header().Set(logsCursorTrailer, logsConfig.LastCursor)
+		}
+	}()
 
 	chStarted := make(chan struct{})
 	if err := s.backend.ContainerLogs(ctx, containerName, logsConfig, chStarted); err != nil {
 		select {
 		case <-chStarted:
+			if logsConfig.Msgpack {
+				// the error would corrupt the stream of documents
+				logrus.Errorf("Error running logs job: %v", err)
+				return nil
+			}
 			// The client may be expecting all of the data we're sending to
 			// be multiplexed, so send it through OutStream, which will
 			// have been set up to handle that if needed.
@@ -132,6 +182,10 @@ func (s *containerRouter) postContainersStart(ctx context.Context, w http.Respon
 	// including r.TransferEncoding
 	// allow a nil body for backwards compatibility
 
+	if err := httputils.ParseForm(r); err != nil {
+		return err
+	}
+
 	version := httputils.VersionFromContext(ctx)
 	var hostConfig *container.HostConfig
 	// A non-nil json object is at least 7 characters.
@@ -152,7 +206,11 @@ func (s *containerRouter) postContainersStart(ctx context.Context, w http.Respon
 	}
 
 	validateHostname := versions.GreaterThanOrEqualTo(version, "1.24")
-	if err := s.backend.ContainerStart(vars["name"], hostConfig, validateHostname); err != nil {
+	checkpoint := r.Form.Get("checkpoint")
+	if checkpoint != "" && !utils.ExperimentalBuild() {
+		return errors.NewBadRequestError(fmt.Errorf("Starting a container from a checkpoint is only supported in experimental builds"))
+	}
+	if err := s.backend.ContainerStart(vars["name"], hostConfig, validateHostname, checkpoint); err != nil {
 		return err
 	}
 	w.WriteHeader(http.StatusNoContent)
@@ -243,6 +301,66 @@ func (s *containerRouter) postContainersPause(ctx context.Context, w http.Respon
 	return nil
 }
 
+func (s *containerRouter) postContainersPauseGroup(ctx context.Context, w http.ResponseWriter, r *http.Request, vars map[string]string) error {
+	if err := httputils.ParseForm(r); err != nil {
+		return err
+	}
+	filter, err := filters.FromParam(r.Form.Get("filters"))
+	if err != nil {
+		return err
+	}
+
+	var report *types.PauseGroupReport
+	if httputils.BoolValue(r, "all") {
+		report, err = s.backend.ContainerPauseAll(filter)
+	} else {
+		report, err = s.backend.ContainerPauseGroup(filter)
+	}
+	if err != nil {
+		return err
+	}
+
+	return httputils.WriteJSON(w, http.StatusOK, report)
+}
+
+func (s *containerRouter) postContainersUnpauseGroup(ctx context.Context, w http.ResponseWriter, r *http.Request, vars map[string]string) error {
+	if err := httputils.ParseForm(r); err != nil {
+		return err
+	}
+	filter, err := filters.FromParam(r.Form.Get("filters"))
+	if err != nil {
+		return err
+	}
+
+	var report *types.PauseGroupReport
+	if httputils.BoolValue(r, "all") {
+		report, err = s.backend.ContainerUnpauseAll(filter)
+	} else {
+		report, err = s.backend.ContainerUnpauseGroup(filter)
+	}
+	if err != nil {
+		return err
+	}
+
+	return httputils.WriteJSON(w, http.StatusOK, report)
+}
+
+func (s *containerRouter) postContainersPrune(ctx context.Context, w http.ResponseWriter, r *http.Request, vars map[string]string) error {
+	if err := httputils.ParseForm(r); err != nil {
+		return err
+	}
+	pruneFilters, err := filters.FromParam(r.Form.Get("filters"))
+	if err != nil {
+		return err
+	}
+
+	report, err := s.backend.ContainersPrune(pruneFilters)
+	if err != nil {
+		return err
+	}
+	return httputils.WriteJSON(w, http.StatusOK, report)
+}
+
 func (s *containerRouter) postContainersUnpause(ctx context.Context, w http.ResponseWriter, r *http.Request, vars map[string]string) error {
 	if err := httputils.ParseForm(r); err != nil {
 		return err
@@ -258,7 +376,21 @@ func (s *containerRouter) postContainersUnpause(ctx context.Context, w http.Resp
 }
 
 func (s *containerRouter) postContainersWait(ctx context.Context, w http.ResponseWriter, r *http.Request, vars map[string]string) error {
-	status, err := s.backend.ContainerWait(vars["name"], -1*time.Second)
+	if err := httputils.ParseForm(r); err != nil {
+		return err
+	}
+
+	timeout := -1 * time.Second
+	if t := r.Form.Get("timeout"); t != "" {
+		seconds, err := strconv.Atoi(t)
+		if err != nil {
+			return errors.NewBadRequestError(fmt.Errorf("Invalid timeout %q: %v", t, err))
+		}
+		timeout = time.Duration(seconds) * time.Second
+	}
+
+	condition := container.WaitCondition(r.Form.Get("condition"))
+	status, err := s.backend.ContainerWaitCondition(ctx, vars["name"], condition, timeout)
 	if err != nil {
 		return err
 	}
@@ -277,6 +409,15 @@ func (s *containerRouter) getContainersChanges(ctx context.Context, w http.Respo
 	return httputils.WriteJSON(w, http.StatusOK, changes)
 }
 
+func (s *containerRouter) getContainersSpec(ctx context.Context, w http.ResponseWriter, r *http.Request, vars map[string]string) error {
+	spec, err := s.backend.ContainerSpec(vars["name"])
+	if err != nil {
+		return err
+	}
+
+	return httputils.WriteJSON(w, http.StatusOK, spec)
+}
+
 func (s *containerRouter) getContainersTop(ctx context.Context, w http.ResponseWriter, r *http.Request, vars map[string]string) error {
 	if err := httputils.ParseForm(r); err != nil {
 		return err
@@ -361,6 +502,7 @@ func (s *containerRouter) postContainersCreate(ctx context.Context, w http.Respo
 		HostConfig:       hostConfig,
 		NetworkingConfig: networkingConfig,
 		AdjustCPUShares:  adjustCPUShares,
+		VerifyLocal:      httputils.BoolValue(r, "verifylocal"),
 	}, validateHostname)
 	if err != nil {
 		return err
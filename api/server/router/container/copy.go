@@ -7,9 +7,11 @@ import (
 	"io"
 	"net/http"
 	"os"
+	"strconv"
 	"strings"
 
 	"github.com/docker/docker/api/server/httputils"
+	"github.com/docker/docker/errors"
 	"github.com/docker/engine-api/types"
 	"github.com/docker/engine-api/types/versions"
 	"golang.org/x/net/context"
@@ -115,5 +117,62 @@ func (s *containerRouter) putContainersArchive(ctx context.Context, w http.Respo
 	}
 
 	noOverwriteDirNonDir := httputils.BoolValue(r, "noOverwriteDirNonDir")
-	return s.backend.ContainerExtractToDir(v.Name, v.Path, noOverwriteDirNonDir, r.Body)
+	copyUIDGID := httputils.BoolValue(r, "copyUIDGID")
+	return s.backend.ContainerExtractToDir(v.Name, v.Path, noOverwriteDirNonDir, copyUIDGID, r.Body)
+}
+
+func (s *containerRouter) postContainersArchiveUploads(ctx context.Context, w http.ResponseWriter, r *http.Request, vars map[string]string) error {
+	v, err := httputils.ArchiveFormValues(r, vars)
+	if err != nil {
+		return err
+	}
+
+	noOverwriteDirNonDir := httputils.BoolValue(r, "noOverwriteDirNonDir")
+	copyUIDGID := httputils.BoolValue(r, "copyUIDGID")
+	upload, err := s.backend.ContainerArchiveUploadCreate(v.Name, v.Path, noOverwriteDirNonDir, copyUIDGID)
+	if err != nil {
+		return err
+	}
+
+	return httputils.WriteJSON(w, http.StatusCreated, upload)
+}
+
+func (s *containerRouter) getContainersArchiveUpload(ctx context.Context, w http.ResponseWriter, r *http.Request, vars map[string]string) error {
+	upload, err := s.backend.ContainerArchiveUploadInspect(vars["name"], vars["id"])
+	if err != nil {
+		return err
+	}
+
+	return httputils.WriteJSON(w, http.StatusOK, upload)
+}
+
+func (s *containerRouter) putContainersArchiveUpload(ctx context.Context, w http.ResponseWriter, r *http.Request, vars map[string]string) error {
+	if err := httputils.ParseForm(r); err != nil {
+		return err
+	}
+
+	offset, err := strconv.ParseInt(r.Form.Get("offset"), 10, 64)
+	if err != nil || offset < 0 {
+		return errors.NewBadRequestError(fmt.Errorf("bad parameter: 'offset' must be the number of bytes already uploaded"))
+	}
+
+	upload, err := s.backend.ContainerArchiveUploadWrite(vars["name"], vars["id"], offset, r.Body)
+	if err != nil {
+		return err
+	}
+
+	return httputils.WriteJSON(w, http.StatusOK, upload)
+}
+
+func (s *containerRouter) postContainersArchiveUploadExtract(ctx context.Context, w http.ResponseWriter, r *http.Request, vars map[string]string) error {
+	return s.backend.ContainerArchiveUploadExtract(vars["name"], vars["id"])
+}
+
+func (s *containerRouter) deleteContainersArchiveUpload(ctx context.Context, w http.ResponseWriter, r *http.Request, vars map[string]string) error {
+	if err := s.backend.ContainerArchiveUploadRemove(vars["name"], vars["id"]); err != nil {
+		return err
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+	return nil
 }
@@ -5,6 +5,7 @@ import (
 
 	"github.com/docker/docker/api/types/backend"
 	"github.com/docker/engine-api/types"
+	"github.com/docker/engine-api/types/filters"
 	"github.com/docker/engine-api/types/registry"
 	"golang.org/x/net/context"
 )
@@ -25,9 +26,14 @@ type containerBackend interface {
 type imageBackend interface {
 	ImageDelete(imageRef string, force, prune bool) ([]types.ImageDelete, error)
 	ImageHistory(imageName string) ([]*types.ImageHistory, error)
-	Images(filterArgs string, filter string, all bool) ([]*types.Image, error)
+	ImageContainers(imageName string) ([]*types.Container, error)
+	ImagesPrune(pruneFilters filters.Args) (*types.ImagesPruneReport, error)
+	Images(filterArgs string, filter string, all bool, limit, offset int) ([]*types.Image, error)
 	LookupImage(name string) (*types.ImageInspect, error)
 	TagImage(imageName, repository, tag string) error
+	ImagePins() []types.ImagePin
+	PinImage(refOrName, digest string) error
+	UnpinImage(refOrName string) error
 }
 
 type importExportBackend interface {
@@ -37,7 +43,10 @@ type importExportBackend interface {
 }
 
 type registryBackend interface {
-	PullImage(ctx context.Context, image, tag string, metaHeaders map[string][]string, authConfig *types.AuthConfig, outStream io.Writer) error
+	PullImage(ctx context.Context, image, tag, platform string, ignoreFreeSpace bool, metaHeaders map[string][]string, authConfig *types.AuthConfig, outStream io.Writer) error
+	LookupRemoteImage(ctx context.Context, name string, refresh bool, metaHeaders map[string][]string, authConfig *types.AuthConfig) (*types.ImageInspect, error)
+	LookupImageManifest(ctx context.Context, name string, metaHeaders map[string][]string, authConfig *types.AuthConfig) (*types.ImageManifest, error)
+	RemoteImageHistory(ctx context.Context, name string, metaHeaders map[string][]string, authConfig *types.AuthConfig) ([]*types.ImageHistory, error)
 	PushImage(ctx context.Context, image, tag string, metaHeaders map[string][]string, authConfig *types.AuthConfig, outStream io.Writer) error
 	SearchRegistryForImages(ctx context.Context, filtersArgs string, term string, limit int, authConfig *types.AuthConfig, metaHeaders map[string][]string) (*registry.SearchResults, error)
 }
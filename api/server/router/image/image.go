@@ -33,17 +33,25 @@ func (r *imageRouter) initRoutes() {
 		// GET
 		router.NewGetRoute("/images/json", r.getImagesJSON),
 		router.NewGetRoute("/images/search", r.getImagesSearch),
+		router.NewGetRoute("/images/pins", r.getPins),
 		router.NewGetRoute("/images/get", r.getImagesGet),
 		router.NewGetRoute("/images/{name:.*}/get", r.getImagesGet),
 		router.NewGetRoute("/images/{name:.*}/history", r.getImagesHistory),
 		router.NewGetRoute("/images/{name:.*}/json", r.getImagesByName),
+		router.NewGetRoute("/images/{name:.*}/containers", r.getImagesContainers),
+		router.Cancellable(router.NewGetRoute("/images/{name:.*}/manifest", r.getImagesManifest)),
 		// POST
 		router.NewPostRoute("/commit", r.postCommit),
 		router.NewPostRoute("/images/load", r.postImagesLoad),
+		router.NewPostRoute("/images/prune", r.postImagesPrune),
+		router.NewPostRoute("/images/pins", r.postPins),
 		router.Cancellable(router.NewPostRoute("/images/create", r.postImagesCreate)),
 		router.Cancellable(router.NewPostRoute("/images/{name:.*}/push", r.postImagesPush)),
 		router.NewPostRoute("/images/{name:.*}/tag", r.postImagesTag),
 		// DELETE
+		// The tag to unpin is a query parameter, so that only the image
+		// named "pins" has to be removed with an explicit tag or its ID.
+		router.NewDeleteRoute("/images/pins", r.deletePins),
 		router.NewDeleteRoute("/images/{name:.*}", r.deleteImages),
 	}
 }
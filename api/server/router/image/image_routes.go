@@ -16,6 +16,7 @@ import (
 	"github.com/docker/docker/registry"
 	"github.com/docker/engine-api/types"
 	"github.com/docker/engine-api/types/container"
+	"github.com/docker/engine-api/types/filters"
 	"github.com/docker/engine-api/types/versions"
 	"golang.org/x/net/context"
 )
@@ -75,12 +76,13 @@ func (s *imageRouter) postImagesCreate(ctx context.Context, w http.ResponseWrite
 	}
 
 	var (
-		image   = r.Form.Get("fromImage")
-		repo    = r.Form.Get("repo")
-		tag     = r.Form.Get("tag")
-		message = r.Form.Get("message")
-		err     error
-		output  = ioutils.NewWriteFlusher(w)
+		image    = r.Form.Get("fromImage")
+		repo     = r.Form.Get("repo")
+		tag      = r.Form.Get("tag")
+		platform = r.Form.Get("platform")
+		message  = r.Form.Get("message")
+		err      error
+		output   = ioutils.NewWriteFlusher(w)
 	)
 	defer output.Close()
 
@@ -105,7 +107,7 @@ func (s *imageRouter) postImagesCreate(ctx context.Context, w http.ResponseWrite
 			}
 		}
 
-		err = s.backend.PullImage(ctx, image, tag, metaHeaders, authConfig, output)
+		err = s.backend.PullImage(ctx, image, tag, platform, httputils.BoolValue(r, "ignorefreespace"), metaHeaders, authConfig, output)
 	} else { //import
 		src := r.Form.Get("fromSrc")
 		// 'err' MUST NOT be defined within this block, we need any error
@@ -236,8 +238,37 @@ func (s *imageRouter) deleteImages(ctx context.Context, w http.ResponseWriter, r
 	return httputils.WriteJSON(w, http.StatusOK, list)
 }
 
+func (s *imageRouter) postImagesPrune(ctx context.Context, w http.ResponseWriter, r *http.Request, vars map[string]string) error {
+	if err := httputils.ParseForm(r); err != nil {
+		return err
+	}
+	pruneFilters, err := filters.FromParam(r.Form.Get("filters"))
+	if err != nil {
+		return err
+	}
+
+	report, err := s.backend.ImagesPrune(pruneFilters)
+	if err != nil {
+		return err
+	}
+	return httputils.WriteJSON(w, http.StatusOK, report)
+}
+
 func (s *imageRouter) getImagesByName(ctx context.Context, w http.ResponseWriter, r *http.Request, vars map[string]string) error {
-	imageInspect, err := s.backend.LookupImage(vars["name"])
+	if err := httputils.ParseForm(r); err != nil {
+		return err
+	}
+
+	var (
+		imageInspect *types.ImageInspect
+		err          error
+	)
+	if httputils.BoolValue(r, "remote") {
+		metaHeaders, authConfig := registryRequestConfig(r)
+		imageInspect, err = s.backend.LookupRemoteImage(ctx, vars["name"], httputils.BoolValue(r, "refresh"), metaHeaders, authConfig)
+	} else {
+		imageInspect, err = s.backend.LookupImage(vars["name"])
+	}
 	if err != nil {
 		return err
 	}
@@ -245,23 +276,90 @@ func (s *imageRouter) getImagesByName(ctx context.Context, w http.ResponseWriter
 	return httputils.WriteJSON(w, http.StatusOK, imageInspect)
 }
 
+func (s *imageRouter) getImagesManifest(ctx context.Context, w http.ResponseWriter, r *http.Request, vars map[string]string) error {
+	metaHeaders, authConfig := registryRequestConfig(r)
+	manifest, err := s.backend.LookupImageManifest(ctx, vars["name"], metaHeaders, authConfig)
+	if err != nil {
+		return err
+	}
+
+	return httputils.WriteJSON(w, http.StatusOK, manifest)
+}
+
+// registryRequestConfig returns the X-Meta- headers of r, and the
+// credentials of its X-Registry-Auth header, for the requests sent to
+// registries on behalf of r.
+func registryRequestConfig(r *http.Request) (map[string][]string, *types.AuthConfig) {
+	metaHeaders := map[string][]string{}
+	for k, v := range r.Header {
+		if strings.HasPrefix(k, "X-Meta-") {
+			metaHeaders[k] = v
+		}
+	}
+
+	authEncoded := r.Header.Get("X-Registry-Auth")
+	authConfig := &types.AuthConfig{}
+	if authEncoded != "" {
+		authJSON := base64.NewDecoder(base64.URLEncoding, strings.NewReader(authEncoded))
+		if err := json.NewDecoder(authJSON).Decode(authConfig); err != nil {
+			// as for a pull, it is not an error if no auth was given
+			authConfig = &types.AuthConfig{}
+		}
+	}
+	return metaHeaders, authConfig
+}
+
 func (s *imageRouter) getImagesJSON(ctx context.Context, w http.ResponseWriter, r *http.Request, vars map[string]string) error {
 	if err := httputils.ParseForm(r); err != nil {
 		return err
 	}
 
+	// The limit, the offset and the fields were added in API 1.25, and are
+	// ignored in the requests of older clients.
+	var (
+		limit, offset int64
+		fields        []string
+	)
+	if version := httputils.VersionFromContext(ctx); versions.GreaterThanOrEqualTo(version, "1.25") {
+		var err error
+		if limit, err = httputils.Int64ValueOrDefault(r, "limit", 0); err != nil {
+			return err
+		}
+		if offset, err = httputils.Int64ValueOrDefault(r, "offset", 0); err != nil {
+			return err
+		}
+		fields = httputils.FieldsValue(r)
+	}
+
 	// FIXME: The filter parameter could just be a match filter
-	images, err := s.backend.Images(r.Form.Get("filters"), r.Form.Get("filter"), httputils.BoolValue(r, "all"))
+	images, err := s.backend.Images(r.Form.Get("filters"), r.Form.Get("filter"), httputils.BoolValue(r, "all"), int(limit), int(offset))
 	if err != nil {
 		return err
 	}
 
-	return httputils.WriteJSON(w, http.StatusOK, images)
+	selected, err := httputils.SelectFields(images, fields)
+	if err != nil {
+		return err
+	}
+	return httputils.WriteJSON(w, http.StatusOK, selected)
 }
 
 func (s *imageRouter) getImagesHistory(ctx context.Context, w http.ResponseWriter, r *http.Request, vars map[string]string) error {
+	if err := httputils.ParseForm(r); err != nil {
+		return err
+	}
+
+	var (
+		history []*types.ImageHistory
+		err     error
+	)
 	name := vars["name"]
-	history, err := s.backend.ImageHistory(name)
+	if httputils.BoolValue(r, "remote") {
+		metaHeaders, authConfig := registryRequestConfig(r)
+		history, err = s.backend.RemoteImageHistory(ctx, name, metaHeaders, authConfig)
+	} else {
+		history, err = s.backend.ImageHistory(name)
+	}
 	if err != nil {
 		return err
 	}
@@ -269,6 +367,18 @@ func (s *imageRouter) getImagesHistory(ctx context.Context, w http.ResponseWrite
 	return httputils.WriteJSON(w, http.StatusOK, history)
 }
 
+func (s *imageRouter) getImagesContainers(ctx context.Context, w http.ResponseWriter, r *http.Request, vars map[string]string) error {
+	if err := httputils.ParseForm(r); err != nil {
+		return err
+	}
+
+	containers, err := s.backend.ImageContainers(vars["name"])
+	if err != nil {
+		return err
+	}
+	return httputils.WriteJSON(w, http.StatusOK, containers)
+}
+
 func (s *imageRouter) postImagesTag(ctx context.Context, w http.ResponseWriter, r *http.Request, vars map[string]string) error {
 	if err := httputils.ParseForm(r); err != nil {
 		return err
@@ -280,6 +390,45 @@ func (s *imageRouter) postImagesTag(ctx context.Context, w http.ResponseWriter,
 	return nil
 }
 
+func (s *imageRouter) getPins(ctx context.Context, w http.ResponseWriter, r *http.Request, vars map[string]string) error {
+	return httputils.WriteJSON(w, http.StatusOK, s.backend.ImagePins())
+}
+
+func (s *imageRouter) postPins(ctx context.Context, w http.ResponseWriter, r *http.Request, vars map[string]string) error {
+	if err := httputils.CheckForJSON(r); err != nil {
+		return err
+	}
+
+	var pin types.ImagePin
+	if err := json.NewDecoder(r.Body).Decode(&pin); err != nil {
+		return err
+	}
+	if strings.TrimSpace(pin.Reference) == "" {
+		return fmt.Errorf("image reference cannot be blank")
+	}
+	if err := s.backend.PinImage(pin.Reference, pin.Digest); err != nil {
+		return err
+	}
+	w.WriteHeader(http.StatusCreated)
+	return nil
+}
+
+func (s *imageRouter) deletePins(ctx context.Context, w http.ResponseWriter, r *http.Request, vars map[string]string) error {
+	if err := httputils.ParseForm(r); err != nil {
+		return err
+	}
+
+	ref := r.Form.Get("reference")
+	if strings.TrimSpace(ref) == "" {
+		return fmt.Errorf("image reference cannot be blank")
+	}
+	if err := s.backend.UnpinImage(ref); err != nil {
+		return err
+	}
+	w.WriteHeader(http.StatusNoContent)
+	return nil
+}
+
 func (s *imageRouter) getImagesSearch(ctx context.Context, w http.ResponseWriter, r *http.Request, vars map[string]string) error {
 	if err := httputils.ParseForm(r); err != nil {
 		return err
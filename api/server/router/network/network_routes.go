@@ -66,6 +66,18 @@ func (n *networkRouter) getNetwork(ctx context.Context, w http.ResponseWriter, r
 	return httputils.WriteJSON(w, http.StatusOK, n.buildNetworkResource(nw))
 }
 
+func (n *networkRouter) getNetworkContainers(ctx context.Context, w http.ResponseWriter, r *http.Request, vars map[string]string) error {
+	if err := httputils.ParseForm(r); err != nil {
+		return err
+	}
+
+	containers, err := n.backend.NetworkContainers(vars["id"], httputils.BoolValue(r, "include-stopped"))
+	if err != nil {
+		return err
+	}
+	return httputils.WriteJSON(w, http.StatusOK, containers)
+}
+
 func (n *networkRouter) postNetworkCreate(ctx context.Context, w http.ResponseWriter, r *http.Request, vars map[string]string) error {
 	var create types.NetworkCreateRequest
 
@@ -1,8 +1,10 @@
 package system
 
 import (
+	"io"
 	"time"
 
+	"github.com/docker/docker/api/types/backend"
 	"github.com/docker/engine-api/types"
 	"github.com/docker/engine-api/types/events"
 	"github.com/docker/engine-api/types/filters"
@@ -13,8 +15,13 @@ import (
 // system specific functionality.
 type Backend interface {
 	SystemInfo() (*types.Info, error)
+	SystemDiskUsage() (*types.DiskUsage, error)
 	SystemVersion() types.Version
 	SubscribeToEvents(since, until time.Time, ef filters.Args) ([]events.Message, chan interface{})
 	UnsubscribeFromEvents(chan interface{})
 	AuthenticateToRegistry(ctx context.Context, authConfig *types.AuthConfig) (string, string, error)
+	BootstrapDrift() ([]backend.ContainerDrift, error)
+	Drain() (*types.DrainReport, error)
+	CancelDrain()
+	Backup(w io.Writer) error
 }
@@ -25,8 +25,13 @@ func NewRouter(b Backend, c *cluster.Cluster) router.Router {
 		router.NewGetRoute("/_ping", pingHandler),
 		router.Cancellable(router.NewGetRoute("/events", r.getEvents)),
 		router.NewGetRoute("/info", r.getInfo),
+		router.NewGetRoute("/system/df", r.getDiskUsage),
 		router.NewGetRoute("/version", r.getVersion),
+		router.NewGetRoute("/bootstrap/drift", r.getBootstrapDrift),
+		router.NewGetRoute("/backup", r.getBackup),
 		router.NewPostRoute("/auth", r.postAuth),
+		router.NewPostRoute("/drain", r.postDrain),
+		router.NewDeleteRoute("/drain", r.deleteDrain),
 	}
 
 	return r
@@ -40,6 +40,15 @@ func (s *systemRouter) getInfo(ctx context.Context, w http.ResponseWriter, r *ht
 	return httputils.WriteJSON(w, http.StatusOK, info)
 }
 
+func (s *systemRouter) getDiskUsage(ctx context.Context, w http.ResponseWriter, r *http.Request, vars map[string]string) error {
+	du, err := s.backend.SystemDiskUsage()
+	if err != nil {
+		return err
+	}
+
+	return httputils.WriteJSON(w, http.StatusOK, du)
+}
+
 func (s *systemRouter) getVersion(ctx context.Context, w http.ResponseWriter, r *http.Request, vars map[string]string) error {
 	info := s.backend.SystemVersion()
 	info.APIVersion = api.DefaultVersion
@@ -47,16 +56,56 @@ func (s *systemRouter) getVersion(ctx context.Context, w http.ResponseWriter, r
 	return httputils.WriteJSON(w, http.StatusOK, info)
 }
 
+func (s *systemRouter) getBootstrapDrift(ctx context.Context, w http.ResponseWriter, r *http.Request, vars map[string]string) error {
+	drifts, err := s.backend.BootstrapDrift()
+	if err != nil {
+		return err
+	}
+
+	return httputils.WriteJSON(w, http.StatusOK, drifts)
+}
+
+func (s *systemRouter) postDrain(ctx context.Context, w http.ResponseWriter, r *http.Request, vars map[string]string) error {
+	report, err := s.backend.Drain()
+	if err != nil {
+		return err
+	}
+
+	return httputils.WriteJSON(w, http.StatusOK, report)
+}
+
+func (s *systemRouter) deleteDrain(ctx context.Context, w http.ResponseWriter, r *http.Request, vars map[string]string) error {
+	s.backend.CancelDrain()
+	w.WriteHeader(http.StatusNoContent)
+	return nil
+}
+
+func (s *systemRouter) getBackup(ctx context.Context, w http.ResponseWriter, r *http.Request, vars map[string]string) error {
+	w.Header().Set("Content-Type", "application/x-tar")
+
+	output := ioutils.NewWriteFlusher(w)
+	defer output.Close()
+	if err := s.backend.Backup(output); err != nil {
+		if !output.Flushed() {
+			return err
+		}
+		// The archive is left truncated, which its readers detect.
+		logrus.Errorf("Error writing the backup of the daemon: %v", err)
+	}
+	return nil
+}
+
 func (s *systemRouter) getEvents(ctx context.Context, w http.ResponseWriter, r *http.Request, vars map[string]string) error {
 	if err := httputils.ParseForm(r); err != nil {
 		return err
 	}
 
-	since, err := eventTime(r.Form.Get("since"))
+	reference := time.Now()
+	since, err := eventTime(r.Form.Get("since"), reference)
 	if err != nil {
 		return err
 	}
-	until, err := eventTime(r.Form.Get("until"))
+	until, err := eventTime(r.Form.Get("until"), reference)
 	if err != nil {
 		return err
 	}
@@ -85,12 +134,22 @@ func (s *systemRouter) getEvents(ctx context.Context, w http.ResponseWriter, r *
 		return err
 	}
 
-	w.Header().Set("Content-Type", "application/json")
+	useMsgpack := httputils.AcceptsMsgpack(r)
+	if useMsgpack {
+		w.Header().Set("Content-Type", httputils.MsgpackMediaType)
+	} else {
+		w.Header().Set("Content-Type", "application/json")
+	}
 	output := ioutils.NewWriteFlusher(w)
 	defer output.Close()
 	output.Flush()
 
-	enc := json.NewEncoder(output)
+	var enc interface {
+		Encode(v interface{}) error
+	} = json.NewEncoder(output)
+	if useMsgpack {
+		enc = httputils.NewMsgpackEncoder(output)
+	}
 
 	buffered, l := s.backend.SubscribeToEvents(since, until, ef)
 	defer s.backend.UnsubscribeFromEvents(l)
@@ -142,13 +201,15 @@ func (s *systemRouter) postAuth(ctx context.Context, w http.ResponseWriter, r *h
 	})
 }
 
-func eventTime(formTime string) (time.Time, error) {
-	t, tNano, err := timetypes.ParseTimestamps(formTime, -1)
-	if err != nil {
-		return time.Time{}, err
-	}
-	if t == -1 {
+// eventTime parses the since or until of the events. The relative times are
+// computed from reference.
+func eventTime(formTime string, reference time.Time) (time.Time, error) {
+	if formTime == "" {
 		return time.Time{}, nil
 	}
-	return time.Unix(t, tNano), nil
+	t, err := timetypes.ParseTime(formTime, reference)
+	if err != nil {
+		return time.Time{}, errors.NewBadRequestError(err)
+	}
+	return t, nil
 }
@@ -3,13 +3,16 @@ package volume
 import (
 	// TODO return types need to be refactored into pkg
 	"github.com/docker/engine-api/types"
+	"github.com/docker/engine-api/types/filters"
 )
 
 // Backend is the methods that need to be implemented to provide
 // volume specific functionality
 type Backend interface {
-	Volumes(filter string) ([]*types.Volume, []string, error)
+	Volumes(filter string, size bool) ([]*types.Volume, []string, error)
 	VolumeInspect(name string) (*types.Volume, error)
+	VolumeContainers(name string) ([]*types.Container, error)
 	VolumeCreate(name, driverName string, opts, labels map[string]string) (*types.Volume, error)
 	VolumeRm(name string) error
+	VolumesPrune(pruneFilters filters.Args) (*types.VolumesPruneReport, error)
 }
@@ -6,6 +6,8 @@ import (
 	"net"
 	"net/http"
 	"strings"
+	"sync"
+	"time"
 
 	"github.com/Sirupsen/logrus"
 	"github.com/docker/docker/api/server/httputils"
@@ -28,12 +30,27 @@ type Config struct {
 	Version     string
 	SocketGroup string
 	TLSConfig   *tls.Config
+
+	// MaxBodySize is the maximum size of the request bodies, except for
+	// the routes taking streams. 0 means no limit.
+	MaxBodySize int64
+	// BodySizeLimits overrides MaxBodySize for some routes, by path, like
+	// "/containers/{name}/update". 0 means no limit.
+	BodySizeLimits map[string]int64
+	// PeerCredentials makes the requests received on unix sockets have
+	// the credentials of their client as remote address, in the form
+	// of httputils.PeerCredentials.
+	PeerCredentials bool
 }
 
 // Server contains instance details for the server
 type Server struct {
 	cfg           *Config
+	mu            sync.Mutex
 	servers       []*HTTPServer
+	serving       bool
+	errors        chan error
+	closed        chan struct{}
 	routers       []router.Router
 	routerSwapper *routerSwapper
 	middlewares   []middleware.Middleware
@@ -43,7 +60,9 @@ type Server struct {
 // It allocates resources which will be needed for ServeAPI(ports, unix-sockets).
 func New(cfg *Config) *Server {
 	return &Server{
-		cfg: cfg,
+		cfg:    cfg,
+		errors: make(chan error, 1),
+		closed: make(chan struct{}),
 	}
 }
 
@@ -54,59 +73,128 @@ func (s *Server) UseMiddleware(m middleware.Middleware) {
 }
 
 // Accept sets a listener the server accepts connections into.
+// Listeners accepted once the server is serving the API are served
+// right away.
 func (s *Server) Accept(addr string, listeners ...net.Listener) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
 	for _, listener := range listeners {
-		httpServer := &HTTPServer{
-			srv: &http.Server{
-				Addr: addr,
-			},
-			l: listener,
+		if s.cfg.PeerCredentials {
+			listener = withPeerCredentials(listener)
 		}
+		httpServer := newHTTPServer(addr, listener)
 		s.servers = append(s.servers, httpServer)
+		if s.serving {
+			s.serve(httpServer)
+		}
+	}
+}
+
+// RemoveListeners stops accepting connections on the listeners of addr.
+// The connections of these listeners are closed in the background once
+// their requests in progress complete, or after timeout. It returns false
+// if the server has no listener on addr.
+func (s *Server) RemoveListeners(addr string, timeout time.Duration) bool {
+	s.mu.Lock()
+	var removed, servers []*HTTPServer
+	for _, srv := range s.servers {
+		if srv.srv.Addr == addr {
+			removed = append(removed, srv)
+		} else {
+			servers = append(servers, srv)
+		}
+	}
+	s.servers = servers
+	s.mu.Unlock()
+
+	for _, srv := range removed {
+		logrus.Infof("API stop listening on %s", srv.l.Addr())
+		if err := srv.Close(); err != nil {
+			logrus.Error(err)
+		}
+		go srv.drain(timeout)
 	}
+	return len(removed) > 0
 }
 
 // Close closes servers and thus stop receiving requests
 func (s *Server) Close() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
 	for _, srv := range s.servers {
 		if err := srv.Close(); err != nil {
 			logrus.Error(err)
 		}
 	}
+	select {
+	case <-s.closed:
+	default:
+		close(s.closed)
+	}
 }
 
 // serveAPI loops through all initialized servers and spawns goroutine
 // with Server method for each. It sets createMux() as Handler also.
+// It returns when a server fails, or once the server is closed.
 func (s *Server) serveAPI() error {
-	var chErrors = make(chan error, len(s.servers))
+	s.mu.Lock()
+	s.serving = true
 	for _, srv := range s.servers {
-		srv.srv.Handler = s.routerSwapper
-		go func(srv *HTTPServer) {
-			var err error
-			logrus.Infof("API listen on %s", srv.l.Addr())
-			if err = srv.Serve(); err != nil && strings.Contains(err.Error(), "use of closed network connection") {
-				err = nil
-			}
-			chErrors <- err
-		}(srv)
+		s.serve(srv)
 	}
+	s.mu.Unlock()
 
-	for i := 0; i < len(s.servers); i++ {
-		err := <-chErrors
-		if err != nil {
-			return err
-		}
+	select {
+	case err := <-s.errors:
+		return err
+	case <-s.closed:
+		return nil
 	}
+}
 
-	return nil
+// serve spawns a goroutine serving the requests of srv. The listeners
+// being closed, by Close or RemoveListeners, is not an error.
+func (s *Server) serve(srv *HTTPServer) {
+	srv.srv.Handler = s.routerSwapper
+	go func() {
+		logrus.Infof("API listen on %s", srv.l.Addr())
+		if err := srv.Serve(); err != nil && !strings.Contains(err.Error(), "use of closed network connection") {
+			select {
+			case s.errors <- err:
+			default:
+			}
+		}
+	}()
 }
 
+// drainPollInterval is how often drain checks whether the connections of a
+// removed listener are closed.
+const drainPollInterval = 100 * time.Millisecond
+
 // HTTPServer contains an instance of http server and the listener.
 // srv *http.Server, contains configuration to create a http server and a mux router with all api end points.
 // l   net.Listener, is a TCP or Socket listener that dispatches incoming request to the router.
 type HTTPServer struct {
 	srv *http.Server
 	l   net.Listener
+
+	mu       sync.Mutex
+	conns    map[net.Conn]http.ConnState
+	draining bool
+}
+
+func newHTTPServer(addr string, l net.Listener) *HTTPServer {
+	s := &HTTPServer{
+		srv: &http.Server{
+			Addr: addr,
+		},
+		l:     l,
+		conns: make(map[net.Conn]http.ConnState),
+	}
+	s.srv.ConnState = s.trackConn
+	return s
 }
 
 // Serve starts listening for inbound requests.
@@ -119,6 +207,63 @@ func (s *HTTPServer) Close() error {
 	return s.l.Close()
 }
 
+// trackConn keeps track of the connections which are not hijacked, so that
+// drain knows when they are closed. Idle connections are closed right away
+// while draining.
+func (s *HTTPServer) trackConn(c net.Conn, state http.ConnState) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	switch state {
+	case http.StateClosed, http.StateHijacked:
+		delete(s.conns, c)
+		return
+	case http.StateIdle:
+		if s.draining {
+			c.Close()
+		}
+	}
+	s.conns[c] = state
+}
+
+// drain closes the connections of the server once their requests in
+// progress complete, and closes the connections left after timeout.
+// Hijacked connections, like the ones of attach, are left open until their
+// clients close them.
+func (s *HTTPServer) drain(timeout time.Duration) {
+	s.srv.SetKeepAlivesEnabled(false)
+
+	s.mu.Lock()
+	s.draining = true
+	for c, state := range s.conns {
+		if state == http.StateIdle {
+			c.Close()
+		}
+	}
+	s.mu.Unlock()
+
+	deadline := time.Now().Add(timeout)
+	for {
+		s.mu.Lock()
+		n := len(s.conns)
+		s.mu.Unlock()
+		if n == 0 {
+			return
+		}
+		if time.Now().After(deadline) {
+			break
+		}
+		time.Sleep(drainPollInterval)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	logrus.Warnf("Closing %d connection(s) to %s with requests still in progress", len(s.conns), s.l.Addr())
+	for c := range s.conns {
+		c.Close()
+	}
+}
+
 func (s *Server) makeHTTPHandler(handler httputils.APIFunc) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		// Define the context that we'll pass around to share info
@@ -167,6 +312,9 @@ func (s *Server) createMux() *mux.Router {
 	for _, apiRouter := range s.routers {
 		for _, r := range apiRouter.Routes() {
 			f := s.makeHTTPHandler(r.Handler())
+			if limit := s.bodySizeLimit(r.Path()); limit > 0 {
+				f = limitRequestBody(f, limit)
+			}
 
 			logrus.Debugf("Registering %s, %s", r.Method(), r.Path())
 			m.Path(versionMatcher + r.Path()).Methods(r.Method()).Handler(f)
@@ -1,14 +1,18 @@
 package server
 
 import (
+	"io/ioutil"
+	"net"
 	"net/http"
 	"net/http/httptest"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/docker/docker/api"
 	"github.com/docker/docker/api/server/httputils"
 	"github.com/docker/docker/api/server/middleware"
+	"github.com/docker/docker/api/server/router"
 
 	"golang.org/x/net/context"
 )
@@ -44,3 +48,88 @@ func TestMiddlewares(t *testing.T) {
 		t.Fatal(err)
 	}
 }
+
+func TestRemoveListeners(t *testing.T) {
+	started := make(chan struct{})
+	release := make(chan struct{})
+	slow := func(ctx context.Context, w http.ResponseWriter, r *http.Request, vars map[string]string) error {
+		close(started)
+		<-release
+		w.Write([]byte("done"))
+		return nil
+	}
+	ping := func(ctx context.Context, w http.ResponseWriter, r *http.Request, vars map[string]string) error {
+		w.Write([]byte("OK"))
+		return nil
+	}
+
+	srv := New(&Config{})
+	srv.InitRouter(false, bodyLimitTestRouter{routes: []router.Route{
+		router.NewGetRoute("/slow", slow),
+		router.NewGetRoute("/_ping", ping),
+	}})
+
+	listen := func(addr string) string {
+		l, err := net.Listen("tcp", "127.0.0.1:0")
+		if err != nil {
+			t.Fatal(err)
+		}
+		srv.Accept(addr, l)
+		return "http://" + l.Addr().String()
+	}
+	removedURL := listen("removed")
+	served := make(chan error)
+	go func() {
+		served <- srv.serveAPI()
+	}()
+	// Listeners accepted while serving are served right away.
+	keptURL := listen("kept")
+
+	get := func(url string) (string, error) {
+		resp, err := http.Get(url)
+		if err != nil {
+			return "", err
+		}
+		defer resp.Body.Close()
+		body, err := ioutil.ReadAll(resp.Body)
+		return string(body), err
+	}
+
+	inProgress := make(chan string)
+	go func() {
+		body, err := get(removedURL + "/slow")
+		if err != nil {
+			body = err.Error()
+		}
+		inProgress <- body
+	}()
+	<-started
+
+	if !srv.RemoveListeners("removed", 10*time.Second) {
+		t.Fatal("expected the listener to be removed")
+	}
+	if srv.RemoveListeners("removed", 10*time.Second) {
+		t.Fatal("expected no listener to be left to remove")
+	}
+	if _, err := get(removedURL + "/_ping"); err == nil {
+		t.Fatal("expected the removed listener to refuse new connections")
+	}
+	if body, err := get(keptURL + "/_ping"); err != nil || body != "OK" {
+		t.Fatalf("expected the kept listener to serve requests, got %q: %v", body, err)
+	}
+
+	close(release)
+	if body := <-inProgress; body != "done" {
+		t.Fatalf("expected the request in progress to complete, got %q", body)
+	}
+
+	srv.Close()
+	select {
+	case err := <-served:
+		if err != nil {
+			t.Fatal(err)
+		}
+	case <-time.After(10 * time.Second):
+		t.Fatal("expected serveAPI to return once the server is closed")
+	}
+}
@@ -5,6 +5,7 @@ package backend
 
 import (
 	"io"
+	"time"
 
 	"github.com/docker/docker/pkg/streamformatter"
 	"github.com/docker/engine-api/types"
@@ -32,6 +33,37 @@ type ContainerAttachConfig struct {
 type ContainerLogsConfig struct {
 	types.ContainerLogsOptions
 	OutStream io.Writer
+	// Msgpack sends the messages as msgpack LogMessage documents instead
+	// of the raw output of the container.
+	Msgpack bool
+	// LastCursor is set by ContainerLogs to the cursor of the last message
+	// read, or to the cursor the logs were read after, for the log drivers
+	// with cursors.
+	LastCursor string
+}
+
+// LogMessage is a log message of a container, as sent to the clients
+// asking for msgpack documents.
+type LogMessage struct {
+	Stream string            `json:"stream"`
+	Time   time.Time         `json:"time"`
+	Line   []byte            `json:"line"`
+	Attrs  map[string]string `json:"attrs,omitempty"`
+	Cursor string            `json:"cursor,omitempty"`
+}
+
+// ContainerDrift is how a container declared by the bootstrap spec of the
+// daemon drifted from its declaration.
+type ContainerDrift struct {
+	// Name is the name of the declared container.
+	Name string
+	// ID is the ID of the container, if it exists.
+	ID string `json:",omitempty"`
+	// Drift is "missing" for a container which does not exist, "config"
+	// for a container whose declaration changed since it was created,
+	// and "image" for a container whose image is not the image of its
+	// declaration anymore. It is empty if the container did not drift.
+	Drift string `json:",omitempty"`
 }
 
 // ContainerStatsConfig holds information for configuring the runtime
@@ -40,6 +72,8 @@ type ContainerStatsConfig struct {
 	Stream    bool
 	OutStream io.Writer
 	Version   string
+	// Msgpack sends the stats as msgpack documents instead of JSON.
+	Msgpack bool
 }
 
 // ExecInspect holds information about a running process started
@@ -55,6 +89,24 @@ type ExecInspect struct {
 	CanRemove     bool
 	ContainerID   string
 	DetachKeys    []byte
+	Stats         *ExecStats
+}
+
+// ExecStatsConfig holds information for configuring the runtime
+// behavior of a backend.ContainerExecStats() call.
+type ExecStatsConfig struct {
+	Stream    bool
+	OutStream io.Writer
+}
+
+// ExecStats holds the resources used by the processes started with
+// docker exec, apart from the other processes of the container.
+type ExecStats struct {
+	Read           time.Time `json:"read"`
+	CPUUsage       uint64    `json:"cpu_usage"`
+	MemoryUsage    uint64    `json:"memory_usage"`
+	MemoryMaxUsage uint64    `json:"memory_max_usage"`
+	Pids           int       `json:"pids"`
 }
 
 // ExecProcessConfig holds information about the exec process
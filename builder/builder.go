@@ -111,8 +111,10 @@ type Backend interface {
 	GetImageOnBuild(name string) (Image, error)
 	// TagImage tags an image with newTag
 	TagImageWithReference(image.ID, reference.Named) error
-	// PullOnBuild tells Docker to pull image referenced by `name`.
-	PullOnBuild(ctx context.Context, name string, authConfigs map[string]types.AuthConfig, output io.Writer) (Image, error)
+	// PullOnBuild tells Docker to pull image referenced by `name`. The
+	// pull is refused if it would leave too little free space, unless
+	// ignoreFreeSpace is set.
+	PullOnBuild(ctx context.Context, name string, authConfigs map[string]types.AuthConfig, ignoreFreeSpace bool, output io.Writer) (Image, error)
 	// ContainerAttachRaw attaches to container.
 	ContainerAttachRaw(cID string, stdin io.ReadCloser, stdout, stderr io.Writer, stream bool) error
 	// ContainerCreate creates a new Docker container and returns potential warnings
@@ -124,7 +126,7 @@ type Backend interface {
 	// ContainerKill stops the container execution abruptly.
 	ContainerKill(containerID string, sig uint64) error
 	// ContainerStart starts a new container
-	ContainerStart(containerID string, hostConfig *container.HostConfig, validateHostname bool) error
+	ContainerStart(containerID string, hostConfig *container.HostConfig, validateHostname bool, checkpoint string) error
 	// ContainerWait stops processing until the given container is stopped.
 	ContainerWait(containerID string, timeout time.Duration) (int, error)
 	// ContainerUpdateCmdOnBuild updates container.Path and container.Args
@@ -138,6 +140,10 @@ type Backend interface {
 	//ContainerCopy(name string, res string) (io.ReadCloser, error)
 	// TODO: use copyBackend api
 	CopyOnBuild(containerID string, destPath string, src FileInfo, decompress bool) error
+
+	// CheckFreeSpace returns an error if doing action, which needs about
+	// projected bytes, would leave too little free space.
+	CheckFreeSpace(action string, projected int64) error
 }
 
 // Image represents a Docker image used by the builder.
@@ -107,6 +107,12 @@ func (bm *BuildManager) BuildFromContext(ctx context.Context, src io.ReadCloser,
 	return b.build(pg.StdoutFormatter, pg.StderrFormatter, pg.Output)
 }
 
+// CheckFreeSpace returns an error if doing action, which needs about
+// projected bytes, would leave too little free space.
+func (bm *BuildManager) CheckFreeSpace(action string, projected int64) error {
+	return bm.backend.CheckFreeSpace(action, projected)
+}
+
 // NewBuilder creates a new Dockerfile builder from an optional dockerfile and a Config.
 // If dockerfile is nil, the Dockerfile specified by Config.DockerfileName,
 // will be read from the Context passed to Build().
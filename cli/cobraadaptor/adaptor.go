@@ -2,8 +2,10 @@ package cobraadaptor
 
 import (
 	"github.com/docker/docker/api/client"
+	"github.com/docker/docker/api/client/checkpoint"
 	"github.com/docker/docker/api/client/container"
 	"github.com/docker/docker/api/client/image"
+	"github.com/docker/docker/api/client/manifest"
 	"github.com/docker/docker/api/client/network"
 	"github.com/docker/docker/api/client/node"
 	"github.com/docker/docker/api/client/plugin"
@@ -51,7 +53,9 @@ func NewCobraAdaptor(clientFlags *cliflags.ClientFlags) CobraAdaptor {
 		container.NewCopyCommand(dockerCli),
 		container.NewCreateCommand(dockerCli),
 		container.NewDiffCommand(dockerCli),
+		container.NewExecStatsCommand(dockerCli),
 		container.NewExportCommand(dockerCli),
+		container.NewExportSpecCommand(dockerCli),
 		container.NewKillCommand(dockerCli),
 		container.NewLogsCommand(dockerCli),
 		container.NewPauseCommand(dockerCli),
@@ -79,14 +83,20 @@ func NewCobraAdaptor(clientFlags *cliflags.ClientFlags) CobraAdaptor {
 		image.NewSearchCommand(dockerCli),
 		image.NewImportCommand(dockerCli),
 		image.NewTagCommand(dockerCli),
+		manifest.NewManifestCommand(dockerCli),
 		network.NewNetworkCommand(dockerCli),
+		system.NewBackupCommand(dockerCli),
+		system.NewDrainCommand(dockerCli),
 		system.NewEventsCommand(dockerCli),
+		system.NewPruneCommand(dockerCli),
+		system.NewSystemCommand(dockerCli),
 		registry.NewLoginCommand(dockerCli),
 		registry.NewLogoutCommand(dockerCli),
 		system.NewVersionCommand(dockerCli),
 		volume.NewVolumeCommand(dockerCli),
 		system.NewInfoCommand(dockerCli),
 	)
+	checkpoint.NewCheckpointCommand(rootCmd, dockerCli)
 	plugin.NewPluginCommand(rootCmd, dockerCli)
 
 	rootCmd.PersistentFlags().BoolP("help", "h", false, "Print usage")
@@ -15,7 +15,6 @@ import (
 	"github.com/docker/docker/api"
 	apiserver "github.com/docker/docker/api/server"
 	"github.com/docker/docker/api/server/middleware"
-	"github.com/docker/docker/api/server/router"
 	"github.com/docker/docker/api/server/router/build"
 	"github.com/docker/docker/api/server/router/container"
 	"github.com/docker/docker/api/server/router/image"
@@ -42,11 +41,16 @@ import (
 	"github.com/docker/docker/registry"
 	"github.com/docker/docker/runconfig"
 	"github.com/docker/docker/utils"
+	"github.com/docker/engine-api/types"
 	"github.com/docker/go-connections/tlsconfig"
 )
 
 const (
 	daemonConfigFileFlag = "-config-file"
+
+	// apiListenerDrainTimeout is how long the requests in progress on the
+	// API listeners removed by a configuration reload have to complete.
+	apiListenerDrainTimeout = 30 * time.Second
 )
 
 // DaemonCli represents the daemon CLI.
@@ -57,6 +61,15 @@ type DaemonCli struct {
 
 	api *apiserver.Server
 	d   *daemon.Daemon
+
+	// apiHosts are the hosts the API listens on, and apiTLS and
+	// apiTLSConfig the TLS settings of their listeners. apiTLSListeners
+	// are the TCP listeners of each host, whose TLS config is replaced
+	// when the settings are reloaded.
+	apiHosts        []string
+	apiTLS          apiTLSSettings
+	apiTLSConfig    *tls.Config
+	apiTLSListeners map[string][]*tlsListener
 }
 
 func presentInHelp(usage string) string { return usage }
@@ -179,31 +192,31 @@ func (cli *DaemonCli) start() (err error) {
 		}()
 	}
 
+	maxBodySize, bodySizeLimits, err := cli.Config.APIRequestBodyLimits()
+	if err != nil {
+		return err
+	}
+
 	serverConfig := &apiserver.Config{
-		Logging:     true,
-		SocketGroup: cli.Config.SocketGroup,
-		Version:     dockerversion.Version,
-		EnableCors:  cli.Config.EnableCors,
-		CorsHeaders: cli.Config.CorsHeaders,
-	}
-
-	if cli.Config.TLS {
-		tlsOptions := tlsconfig.Options{
-			CAFile:   cli.Config.CommonTLSOptions.CAFile,
-			CertFile: cli.Config.CommonTLSOptions.CertFile,
-			KeyFile:  cli.Config.CommonTLSOptions.KeyFile,
-		}
+		Logging:         true,
+		SocketGroup:     cli.Config.SocketGroup,
+		Version:         dockerversion.Version,
+		EnableCors:      cli.Config.EnableCors,
+		CorsHeaders:     cli.Config.CorsHeaders,
+		MaxBodySize:     maxBodySize,
+		BodySizeLimits:  bodySizeLimits,
+		PeerCredentials: cli.Config.APIAudit,
+	}
 
-		if cli.Config.TLSVerify {
-			// server requires and verifies client's certificate
-			tlsOptions.ClientAuth = tls.RequireAndVerifyClientCert
-		}
-		tlsConfig, err := tlsconfig.Server(tlsOptions)
-		if err != nil {
-			return err
-		}
-		serverConfig.TLSConfig = tlsConfig
+	cli.apiTLS = apiTLSSettings{
+		tls:              cli.Config.TLS,
+		tlsVerify:        cli.Config.TLSVerify,
+		CommonTLSOptions: cli.Config.CommonTLSOptions,
 	}
+	if serverConfig.TLSConfig, err = cli.apiTLS.serverConfig(); err != nil {
+		return err
+	}
+	cli.apiTLSConfig = serverConfig.TLSConfig
 
 	if len(cli.Config.Hosts) == 0 {
 		cli.Config.Hosts = make([]string, 1)
@@ -212,39 +225,25 @@ func (cli *DaemonCli) start() (err error) {
 	api := apiserver.New(serverConfig)
 	cli.api = api
 
+	if err := cli.initMiddlewares(api, serverConfig); err != nil {
+		return fmt.Errorf("Error creating middlewares: %v", err)
+	}
+
+	cli.apiTLSListeners = make(map[string][]*tlsListener)
 	for i := 0; i < len(cli.Config.Hosts); i++ {
 		var err error
 		if cli.Config.Hosts[i], err = opts.ParseHost(cli.Config.TLS, cli.Config.Hosts[i]); err != nil {
 			return fmt.Errorf("error parsing -H %s : %v", cli.Config.Hosts[i], err)
 		}
-
-		protoAddr := cli.Config.Hosts[i]
-		protoAddrParts := strings.SplitN(protoAddr, "://", 2)
-		if len(protoAddrParts) != 2 {
-			return fmt.Errorf("bad format %s, expected PROTO://ADDR", protoAddr)
-		}
-
-		proto := protoAddrParts[0]
-		addr := protoAddrParts[1]
-
-		// It's a bad idea to bind to TCP without tlsverify.
-		if proto == "tcp" && (serverConfig.TLSConfig == nil || serverConfig.TLSConfig.ClientAuth != tls.RequireAndVerifyClientCert) {
-			logrus.Warn("[!] DON'T BIND ON ANY IP ADDRESS WITHOUT setting -tlsverify IF YOU DON'T KNOW WHAT YOU'RE DOING [!]")
-		}
-		ls, err := listeners.Init(proto, addr, serverConfig.SocketGroup, serverConfig.TLSConfig)
+	}
+	for _, protoAddr := range activatedAllLast(cli.Config.Hosts) {
+		tlsListeners, err := listenAPIHost(api, protoAddr, serverConfig.SocketGroup, serverConfig.TLSConfig)
 		if err != nil {
 			return err
 		}
-		ls = wrapListeners(proto, ls)
-		// If we're binding to a TCP port, make sure that a container doesn't try to use it.
-		if proto == "tcp" {
-			if err := allocateDaemonPort(addr); err != nil {
-				return err
-			}
-		}
-		logrus.Debugf("Listener created for HTTP on %s (%s)", protoAddrParts[0], protoAddrParts[1])
-		api.Accept(protoAddrParts[1], ls...)
+		cli.apiTLSListeners[protoAddr] = tlsListeners
 	}
+	cli.apiHosts = append([]string(nil), cli.Config.Hosts...)
 
 	if err := migrateKey(); err != nil {
 		return err
@@ -290,7 +289,6 @@ func (cli *DaemonCli) start() (err error) {
 		"graphdriver": d.GraphDriverName(),
 	}).Info("Docker daemon")
 
-	cli.initMiddlewares(api, serverConfig)
 	initRouter(api, d, c)
 
 	cli.d = d
@@ -302,14 +300,18 @@ func (cli *DaemonCli) start() (err error) {
 	serveAPIWait := make(chan error)
 	go api.Wait(serveAPIWait)
 
-	// after the daemon is done setting up we can notify systemd api
-	notifySystem()
+	// after the daemon is done setting up we can notify systemd api, the
+	// watchdog pings stop while the containers can't be listed
+	notifySystem(func() error {
+		_, err := d.Containers(&types.ContainerListOptions{All: true})
+		return err
+	})
 
 	// Daemon is fully initialized and handling API traffic
 	// Wait for serve API to complete
 	errAPI := <-serveAPIWait
 	c.Cleanup()
-	shutdownDaemon(d, 15)
+	shutdownDaemon(d)
 	containerdRemote.Cleanup()
 	if errAPI != nil {
 		return fmt.Errorf("Shutting down due to ServeAPI error: %v", errAPI)
@@ -336,6 +338,14 @@ func (cli *DaemonCli) reloadConfig() {
 			}
 
 		}
+		// debug mode takes precedence over the log level, as it does when
+		// the daemon starts
+		if config.IsValueSet("log-level") && !utils.IsDebugEnabled() {
+			cliflags.SetDaemonLogLevel(config.LogLevel)
+		}
+		if err := cli.reloadAPIListeners(config); err != nil {
+			logrus.Errorf("Error reconfiguring the API listeners: %v", err)
+		}
 	}
 
 	if err := daemon.ReloadConfiguration(*cli.configFile, flag.CommandLine, reload); err != nil {
@@ -343,6 +353,231 @@ func (cli *DaemonCli) reloadConfig() {
 	}
 }
 
+// apiTLSSettings are the TLS settings of the API listeners.
+type apiTLSSettings struct {
+	tls       bool
+	tlsVerify bool
+	daemon.CommonTLSOptions
+}
+
+// serverConfig returns the TLS configuration of the API listeners, or nil
+// if TLS is disabled.
+func (s apiTLSSettings) serverConfig() (*tls.Config, error) {
+	if !s.tls {
+		return nil, nil
+	}
+	tlsOptions := tlsconfig.Options{
+		CAFile:   s.CAFile,
+		CertFile: s.CertFile,
+		KeyFile:  s.KeyFile,
+	}
+
+	if s.tlsVerify {
+		// server requires and verifies client's certificate
+		tlsOptions.ClientAuth = tls.RequireAndVerifyClientCert
+	}
+	return tlsconfig.Server(tlsOptions)
+}
+
+// listenAPIHost creates the listeners of protoAddr, a host parsed by
+// opts.ParseHost, and adds them to the API server. The TCP listeners, bound
+// by the daemon or activated by systemd, are served with TLS when tlsConfig
+// is set, and returned so that their config can be replaced on reload.
+func listenAPIHost(api *apiserver.Server, protoAddr, socketGroup string, tlsConfig *tls.Config) ([]*tlsListener, error) {
+	protoAddrParts := strings.SplitN(protoAddr, "://", 2)
+	if len(protoAddrParts) != 2 {
+		return nil, fmt.Errorf("bad format %s, expected PROTO://ADDR", protoAddr)
+	}
+
+	proto := protoAddrParts[0]
+	addr := protoAddrParts[1]
+
+	// It's a bad idea to bind to TCP without tlsverify.
+	if proto == "tcp" && (tlsConfig == nil || tlsConfig.ClientAuth != tls.RequireAndVerifyClientCert) {
+		logrus.Warn("[!] DON'T BIND ON ANY IP ADDRESS WITHOUT setting -tlsverify IF YOU DON'T KNOW WHAT YOU'RE DOING [!]")
+	}
+	ls, err := listeners.Init(proto, addr, socketGroup, nil)
+	if err != nil {
+		return nil, err
+	}
+	var tlsListeners []*tlsListener
+	for i, l := range ls {
+		if l.Addr().Network() != "tcp" {
+			continue
+		}
+		tl := newTLSListener(l, tlsConfig)
+		tlsListeners = append(tlsListeners, tl)
+		ls[i] = tl
+	}
+	ls = wrapListeners(proto, ls)
+	// If we're binding to a TCP port, make sure that a container doesn't try to use it.
+	if proto == "tcp" {
+		if err := allocateDaemonPort(addr); err != nil {
+			for _, l := range ls {
+				l.Close()
+			}
+			return nil, err
+		}
+	}
+	// The TCP sockets activated by systemd are already bound, reserve their
+	// ports as well.
+	if proto == "fd" {
+		for _, l := range ls {
+			if l.Addr().Network() != "tcp" {
+				continue
+			}
+			if tlsConfig == nil || tlsConfig.ClientAuth != tls.RequireAndVerifyClientCert {
+				logrus.Warnf("[!] DON'T SERVE THE SOCKET ACTIVATED ON %s WITHOUT setting -tlsverify IF YOU DON'T KNOW WHAT YOU'RE DOING [!]", l.Addr())
+			}
+			if err := allocateDaemonPort(l.Addr().String()); err != nil {
+				logrus.Warnf("Error reserving the port of the socket activated on %s: %v", l.Addr(), err)
+			}
+		}
+	}
+	logrus.Debugf("Listener created for HTTP on %s (%s)", proto, addr)
+	api.Accept(addr, ls...)
+	return tlsListeners, nil
+}
+
+// removeAPIHost removes the listeners of protoAddr from the API server.
+// Their requests in progress get apiListenerDrainTimeout to complete.
+func removeAPIHost(api *apiserver.Server, protoAddr string) {
+	protoAddrParts := strings.SplitN(protoAddr, "://", 2)
+	if len(protoAddrParts) != 2 {
+		return
+	}
+	api.RemoveListeners(protoAddrParts[1], apiListenerDrainTimeout)
+	if protoAddrParts[0] == "tcp" {
+		if err := releaseDaemonPort(protoAddrParts[1]); err != nil {
+			logrus.Warnf("Error releasing daemon listening port of %s: %v", protoAddr, err)
+		}
+	}
+}
+
+// reloadAPIListeners applies the hosts and TLS settings of config to the API
+// listeners, see applyAPIListeners.
+func (cli *DaemonCli) reloadAPIListeners(config *daemon.Config) error {
+	tlsSettings := cli.apiTLS
+	if config.IsValueSet("tls") {
+		tlsSettings.tls = config.TLS
+	}
+	// Like on start, specifying tlsverify at all turns on TLS.
+	if config.IsValueSet(cliflags.TLSVerifyKey) {
+		tlsSettings.tls = true
+		tlsSettings.tlsVerify = config.TLSVerify
+	}
+	if config.IsValueSet("tlscacert") {
+		tlsSettings.CAFile = config.CAFile
+	}
+	if config.IsValueSet("tlscert") {
+		tlsSettings.CertFile = config.CertFile
+	}
+	if config.IsValueSet("tlskey") {
+		tlsSettings.KeyFile = config.KeyFile
+	}
+	if !config.IsValueSet("hosts") && tlsSettings == cli.apiTLS {
+		return nil
+	}
+
+	hosts := cli.apiHosts
+	if config.IsValueSet("hosts") {
+		hosts = config.Hosts
+		if len(hosts) == 0 {
+			hosts = make([]string, 1)
+		}
+	}
+	return cli.applyAPIListeners(hosts, tlsSettings)
+}
+
+// applyAPIListeners makes the API listen on hosts with tlsSettings. The
+// listeners of the added hosts are bound before the ones of the removed
+// hosts are closed, so that switching the daemon from a host to another
+// does not interrupt the API. The listeners of the kept hosts are not bound
+// again: their TCP listeners, including the ones activated by systemd, are
+// served with the new TLS config, for the connections they accept next.
+func (cli *DaemonCli) applyAPIListeners(hosts []string, tlsSettings apiTLSSettings) error {
+	wanted := make([]string, 0, len(hosts))
+	for _, host := range hosts {
+		protoAddr, err := opts.ParseHost(tlsSettings.tls, host)
+		if err != nil {
+			return fmt.Errorf("error parsing -H %s : %v", host, err)
+		}
+		wanted = append(wanted, protoAddr)
+	}
+
+	tlsConfig := cli.apiTLSConfig
+	tlsChanged := tlsSettings != cli.apiTLS
+	if tlsChanged {
+		var err error
+		if tlsConfig, err = tlsSettings.serverConfig(); err != nil {
+			return err
+		}
+	}
+
+	var active []string
+	tlsListeners := make(map[string][]*tlsListener)
+	for _, protoAddr := range activatedAllLast(wanted) {
+		if containsString(active, protoAddr) {
+			continue
+		}
+		if containsString(cli.apiHosts, protoAddr) {
+			active = append(active, protoAddr)
+			tlsListeners[protoAddr] = cli.apiTLSListeners[protoAddr]
+			if tlsChanged {
+				for _, l := range tlsListeners[protoAddr] {
+					if tlsConfig == nil || tlsConfig.ClientAuth != tls.RequireAndVerifyClientCert {
+						logrus.Warnf("[!] DON'T SERVE %s WITHOUT setting -tlsverify IF YOU DON'T KNOW WHAT YOU'RE DOING [!]", l.Addr())
+					}
+					l.setConfig(tlsConfig)
+				}
+			}
+			continue
+		}
+		ls, err := listenAPIHost(cli.api, protoAddr, cli.Config.SocketGroup, tlsConfig)
+		if err != nil {
+			logrus.Errorf("Error listening on %s: %v", protoAddr, err)
+			continue
+		}
+		active = append(active, protoAddr)
+		tlsListeners[protoAddr] = ls
+	}
+	for _, protoAddr := range cli.apiHosts {
+		if !containsString(active, protoAddr) {
+			removeAPIHost(cli.api, protoAddr)
+		}
+	}
+
+	cli.apiHosts = active
+	cli.apiTLS = tlsSettings
+	cli.apiTLSConfig = tlsConfig
+	cli.apiTLSListeners = tlsListeners
+	return nil
+}
+
+// activatedAllLast returns hosts with the fd:// hosts serving all the
+// socket activated files moved last, so that they serve the files which no
+// fd://N host serves whatever the order the hosts were given in.
+func activatedAllLast(hosts []string) []string {
+	var ordered, all []string
+	for _, protoAddr := range hosts {
+		if protoAddr == "fd://" || protoAddr == "fd://*" {
+			all = append(all, protoAddr)
+			continue
+		}
+		ordered = append(ordered, protoAddr)
+	}
+	return append(ordered, all...)
+}
+
+func containsString(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
+
 func (cli *DaemonCli) stop() {
 	cli.api.Close()
 }
@@ -350,16 +585,22 @@ func (cli *DaemonCli) stop() {
 // shutdownDaemon just wraps daemon.Shutdown() to handle a timeout in case
 // d.Shutdown() is waiting too long to kill container or worst it's
 // blocked there
-func shutdownDaemon(d *daemon.Daemon, timeout time.Duration) {
+func shutdownDaemon(d *daemon.Daemon) {
+	shutdownTimeout := d.ShutdownTimeout()
 	ch := make(chan struct{})
 	go func() {
 		d.Shutdown()
 		close(ch)
 	}()
+	if shutdownTimeout < 0 {
+		<-ch
+		logrus.Debug("Clean shutdown succeeded")
+		return
+	}
 	select {
 	case <-ch:
 		logrus.Debug("Clean shutdown succeeded")
-	case <-time.After(timeout * time.Second):
+	case <-time.After(time.Duration(shutdownTimeout) * time.Second):
 		logrus.Error("Force shutdown daemon")
 	}
 }
@@ -381,7 +622,7 @@ func loadDaemonCliConfig(config *daemon.Config, flags *flag.FlagSet, commonConfi
 	if configFile != "" {
 		c, err := daemon.MergeDaemonConfigurations(config, flags, configFile)
 		if err != nil {
-			if flags.IsSet(daemonConfigFileFlag) || !os.IsNotExist(err) {
+			if flags.IsSet(daemonConfigFileFlag) || config.ConfigProfile != "" || !os.IsNotExist(err) {
 				return nil, fmt.Errorf("unable to configure the Docker daemon with file %s: %v\n", configFile, err)
 			}
 		}
@@ -411,28 +652,43 @@ func loadDaemonCliConfig(config *daemon.Config, flags *flag.FlagSet, commonConfi
 func initRouter(s *apiserver.Server, d *daemon.Daemon, c *cluster.Cluster) {
 	decoder := runconfig.ContainerDecoder{}
 
-	routers := []router.Router{
+	// the experimental routes are matched first, as the routes of the
+	// checkpoints of the containers are under the routes of the containers
+	routers := addExperimentalRouters(nil, d)
+	routers = append(routers,
 		container.NewRouter(d, decoder),
 		image.NewRouter(d, decoder),
 		systemrouter.NewRouter(d, c),
 		volume.NewRouter(d),
 		build.NewRouter(dockerfile.NewBuildManager(d)),
 		swarmrouter.NewRouter(c),
-	}
+	)
 	if d.NetworkControllerEnabled() {
 		routers = append(routers, network.NewRouter(d, c))
 	}
-	routers = addExperimentalRouters(routers)
 
 	s.InitRouter(utils.IsDebugEnabled(), routers...)
 }
 
-func (cli *DaemonCli) initMiddlewares(s *apiserver.Server, cfg *apiserver.Config) {
+func (cli *DaemonCli) initMiddlewares(s *apiserver.Server, cfg *apiserver.Config) error {
 	v := cfg.Version
 
-	vm := middleware.NewVersionMiddleware(v, api.DefaultVersion, api.MinVersion)
+	minVersion := api.MinVersion
+	if cli.Config.APIMinVersion != "" {
+		minVersion = cli.Config.APIMinVersion
+	}
+	vm := middleware.NewVersionMiddleware(v, api.DefaultVersion, minVersion)
 	s.UseMiddleware(vm)
 
+	if cli.Config.APIDeprecatedVersion != "" {
+		sunset, err := cli.Config.APISunset()
+		if err != nil {
+			return err
+		}
+		dm := middleware.NewDeprecationMiddleware(cli.Config.APIDeprecatedVersion, sunset)
+		s.UseMiddleware(dm)
+	}
+
 	if cfg.EnableCors {
 		c := middleware.NewCORSMiddleware(cfg.CorsHeaders)
 		s.UseMiddleware(c)
@@ -446,4 +702,11 @@ func (cli *DaemonCli) initMiddlewares(s *apiserver.Server, cfg *apiserver.Config
 		handleAuthorization := authorization.NewMiddleware(authZPlugins)
 		s.UseMiddleware(handleAuthorization)
 	}
+
+	// The audit middleware is the last one, so that the requests refused
+	// by the other ones are logged too.
+	if cli.Config.APIAudit {
+		s.UseMiddleware(middleware.NewAuditMiddleware())
+	}
+	return nil
 }
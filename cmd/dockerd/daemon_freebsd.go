@@ -1,5 +1,5 @@
 package main
 
 // notifySystem sends a message to the host when the server is ready to be used
-func notifySystem() {
+func notifySystem(healthy func() error) {
 }
@@ -2,10 +2,61 @@
 
 package main
 
-import systemdDaemon "github.com/coreos/go-systemd/daemon"
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"time"
 
-// notifySystem sends a message to the host when the server is ready to be used
-func notifySystem() {
+	"github.com/Sirupsen/logrus"
+	systemdDaemon "github.com/coreos/go-systemd/daemon"
+)
+
+// notifySystem sends a message to the host when the server is ready to be used,
+// and then answers the pings of the systemd watchdog as long as healthy
+// returns without error.
+func notifySystem(healthy func() error) {
 	// Tell the init daemon we are accepting requests
 	go systemdDaemon.SdNotify("READY=1")
+
+	interval, err := watchdogInterval()
+	if err != nil {
+		logrus.Warnf("Not answering the systemd watchdog: %v", err)
+		return
+	}
+	if interval > 0 {
+		logrus.Infof("Answering the systemd watchdog every %s", interval/2)
+		go watchdog(interval, healthy)
+	}
+}
+
+// watchdogInterval returns the interval of the systemd watchdog of the
+// daemon, 0 if it is not enabled.
+func watchdogInterval() (time.Duration, error) {
+	usec := os.Getenv("WATCHDOG_USEC")
+	if usec == "" {
+		return 0, nil
+	}
+	if pid := os.Getenv("WATCHDOG_PID"); pid != "" && pid != strconv.Itoa(os.Getpid()) {
+		return 0, nil
+	}
+	n, err := strconv.ParseInt(usec, 10, 64)
+	if err != nil || n <= 0 {
+		return 0, fmt.Errorf("invalid WATCHDOG_USEC %q", usec)
+	}
+	return time.Duration(n) * time.Microsecond, nil
+}
+
+// watchdog pings the systemd watchdog twice per interval. It stops pinging
+// while the daemon is hung in healthy, so that systemd restarts it.
+func watchdog(interval time.Duration, healthy func() error) {
+	ticker := time.NewTicker(interval / 2)
+	defer ticker.Stop()
+	for range ticker.C {
+		if err := healthy(); err != nil {
+			logrus.Warnf("Not pinging the systemd watchdog: %v", err)
+			continue
+		}
+		systemdDaemon.SdNotify("WATCHDOG=1")
+	}
 }
@@ -0,0 +1,39 @@
+// +build linux
+
+package main
+
+import (
+	"os"
+	"strconv"
+	"testing"
+	"time"
+)
+
+func TestWatchdogInterval(t *testing.T) {
+	defer os.Unsetenv("WATCHDOG_USEC")
+	defer os.Unsetenv("WATCHDOG_PID")
+
+	for _, tc := range []struct {
+		usec     string
+		pid      string
+		interval time.Duration
+		err      bool
+	}{
+		{"", "", 0, false},
+		{"30000000", "", 30 * time.Second, false},
+		{"30000000", strconv.Itoa(os.Getpid()), 30 * time.Second, false},
+		{"30000000", strconv.Itoa(os.Getpid() + 1), 0, false},
+		{"0", "", 0, true},
+		{"30s", "", 0, true},
+	} {
+		os.Setenv("WATCHDOG_USEC", tc.usec)
+		os.Setenv("WATCHDOG_PID", tc.pid)
+		interval, err := watchdogInterval()
+		if tc.err != (err != nil) {
+			t.Fatalf("unexpected error for WATCHDOG_USEC=%q WATCHDOG_PID=%q: %v", tc.usec, tc.pid, err)
+		}
+		if interval != tc.interval {
+			t.Fatalf("expected the interval %s for WATCHDOG_USEC=%q WATCHDOG_PID=%q, got %s", tc.interval, tc.usec, tc.pid, interval)
+		}
+	}
+}
@@ -47,7 +47,7 @@ func (cli *DaemonCli) setupConfigReloadTrap() {
 }
 
 // notifySystem sends a message to the host when the server is ready to be used
-func notifySystem() {
+func notifySystem(healthy func() error) {
 }
 
 func (cli *DaemonCli) getPlatformRemoteOptions() []libcontainerd.RemoteOption {
@@ -65,6 +65,10 @@ func allocateDaemonPort(addr string) error {
 	return nil
 }
 
+func releaseDaemonPort(addr string) error {
+	return nil
+}
+
 // notifyShutdown is called after the daemon shuts down but before the process exits.
 func notifyShutdown(err error) {
 }
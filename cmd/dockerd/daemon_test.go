@@ -292,3 +292,12 @@ func TestLoadDaemonConfigWithRegistryOptions(t *testing.T) {
 		t.Fatal("expected disable-legacy-registry to be true, got false")
 	}
 }
+
+func TestActivatedAllLast(t *testing.T) {
+	hosts := []string{"fd://", "unix:///var/run/docker.sock", "fd://*", "fd://4", "tcp://0.0.0.0:2376"}
+	expected := []string{"unix:///var/run/docker.sock", "fd://4", "tcp://0.0.0.0:2376", "fd://", "fd://*"}
+	ordered := activatedAllLast(hosts)
+	if strings.Join(ordered, ",") != strings.Join(expected, ",") {
+		t.Fatalf("expected %v, got %v", expected, ordered)
+	}
+}
@@ -88,32 +88,58 @@ func (cli *DaemonCli) getLibcontainerdRoot() string {
 // allocateDaemonPort ensures that there are no containers
 // that try to use any port allocated for the docker server.
 func allocateDaemonPort(addr string) error {
-	host, port, err := net.SplitHostPort(addr)
+	hostIPs, intPort, err := daemonPortIPs(addr)
 	if err != nil {
 		return err
 	}
 
-	intPort, err := strconv.Atoi(port)
-	if err != nil {
-		return err
+	pa := portallocator.Get()
+	for _, hostIP := range hostIPs {
+		if _, err := pa.RequestPort(hostIP, "tcp", intPort); err != nil {
+			return fmt.Errorf("failed to allocate daemon listening port %d (err: %v)", intPort, err)
+		}
 	}
+	return nil
+}
 
-	var hostIPs []net.IP
-	if parsedIP := net.ParseIP(host); parsedIP != nil {
-		hostIPs = append(hostIPs, parsedIP)
-	} else if hostIPs, err = net.LookupIP(host); err != nil {
-		return fmt.Errorf("failed to lookup %s address in host specification", host)
+// releaseDaemonPort releases the port allocated by allocateDaemonPort once
+// the docker server stops listening on it.
+func releaseDaemonPort(addr string) error {
+	hostIPs, intPort, err := daemonPortIPs(addr)
+	if err != nil {
+		return err
 	}
 
 	pa := portallocator.Get()
 	for _, hostIP := range hostIPs {
-		if _, err := pa.RequestPort(hostIP, "tcp", intPort); err != nil {
-			return fmt.Errorf("failed to allocate daemon listening port %d (err: %v)", intPort, err)
+		if err := pa.ReleasePort(hostIP, "tcp", intPort); err != nil {
+			return fmt.Errorf("failed to release daemon listening port %d (err: %v)", intPort, err)
 		}
 	}
 	return nil
 }
 
+// daemonPortIPs returns the IP addresses and the port of addr.
+func daemonPortIPs(addr string) ([]net.IP, int, error) {
+	host, port, err := net.SplitHostPort(addr)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	intPort, err := strconv.Atoi(port)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	var hostIPs []net.IP
+	if parsedIP := net.ParseIP(host); parsedIP != nil {
+		hostIPs = append(hostIPs, parsedIP)
+	} else if hostIPs, err = net.LookupIP(host); err != nil {
+		return nil, 0, fmt.Errorf("failed to lookup %s address in host specification", host)
+	}
+	return hostIPs, intPort, nil
+}
+
 // notifyShutdown is called after the daemon shuts down but before the process exits.
 func notifyShutdown(err error) {
 }
@@ -4,8 +4,12 @@ package main
 
 import (
 	"io/ioutil"
+	"net"
+	"os"
+	"path/filepath"
 	"testing"
 
+	apiserver "github.com/docker/docker/api/server"
 	cliflags "github.com/docker/docker/cli/flags"
 	"github.com/docker/docker/daemon"
 	"github.com/docker/docker/opts"
@@ -210,3 +214,56 @@ func TestLoadDaemonConfigWithTrueDefaultValuesLeaveDefaults(t *testing.T) {
 		t.Fatal("expected userland proxy to be enabled, got disabled")
 	}
 }
+
+func TestApplyAPIListeners(t *testing.T) {
+	tmp, err := ioutil.TempDir("", "docker-api-listeners-test-")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(tmp)
+
+	cli := &DaemonCli{
+		Config:          &daemon.Config{},
+		api:             apiserver.New(&apiserver.Config{}),
+		apiTLSListeners: make(map[string][]*tlsListener),
+	}
+	defer cli.api.Close()
+
+	listening := func(name string) bool {
+		c, err := net.Dial("unix", filepath.Join(tmp, name))
+		if err != nil {
+			return false
+		}
+		c.Close()
+		return true
+	}
+	host := func(name string) string {
+		return "unix://" + filepath.Join(tmp, name)
+	}
+
+	if err := cli.applyAPIListeners([]string{host("a.sock")}, cli.apiTLS); err != nil {
+		t.Fatal(err)
+	}
+	if !listening("a.sock") {
+		t.Fatal("Expected the API to listen on a.sock")
+	}
+
+	// Switching to another host closes the listener of the removed one
+	if err := cli.applyAPIListeners([]string{host("b.sock")}, cli.apiTLS); err != nil {
+		t.Fatal(err)
+	}
+	if !listening("b.sock") || listening("a.sock") {
+		t.Fatalf("Expected the API to listen on b.sock only, got %v", cli.apiHosts)
+	}
+
+	// A host which can't be bound is skipped, and the other hosts are kept
+	if err := cli.applyAPIListeners([]string{host("b.sock"), host("missing/c.sock")}, cli.apiTLS); err != nil {
+		t.Fatal(err)
+	}
+	if !listening("b.sock") {
+		t.Fatal("Expected the API to keep listening on b.sock")
+	}
+	if len(cli.apiHosts) != 1 || cli.apiHosts[0] != host("b.sock") {
+		t.Fatalf("Expected the API to listen on b.sock only, got %v", cli.apiHosts)
+	}
+}
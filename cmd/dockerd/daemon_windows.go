@@ -29,7 +29,7 @@ func getDaemonConfDir() string {
 }
 
 // notifySystem sends a message to the host when the server is ready to be used
-func notifySystem() {
+func notifySystem(healthy func() error) {
 	if service != nil {
 		err := service.started()
 		if err != nil {
@@ -77,6 +77,10 @@ func allocateDaemonPort(addr string) error {
 	return nil
 }
 
+func releaseDaemonPort(addr string) error {
+	return nil
+}
+
 func wrapListeners(proto string, ls []net.Listener) []net.Listener {
 	return ls
 }
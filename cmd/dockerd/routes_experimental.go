@@ -4,10 +4,12 @@ package main
 
 import (
 	"github.com/docker/docker/api/server/router"
+	checkpointrouter "github.com/docker/docker/api/server/router/checkpoint"
 	pluginrouter "github.com/docker/docker/api/server/router/plugin"
+	"github.com/docker/docker/daemon"
 	"github.com/docker/docker/plugin"
 )
 
-func addExperimentalRouters(routers []router.Router) []router.Router {
-	return append(routers, pluginrouter.NewRouter(plugin.GetManager()))
+func addExperimentalRouters(routers []router.Router, d *daemon.Daemon) []router.Router {
+	return append(routers, checkpointrouter.NewRouter(d), pluginrouter.NewRouter(plugin.GetManager()))
 }
@@ -0,0 +1,49 @@
+package main
+
+import (
+	"crypto/tls"
+	"net"
+	"sync"
+)
+
+// tlsListener serves the connections of a TCP listener with TLS when its
+// config is set. The config can be replaced while the listener is served,
+// so that the TLS settings of the API are reloaded without binding the
+// listener again, which is not possible for the sockets activated by
+// systemd.
+type tlsListener struct {
+	net.Listener
+	mu     sync.Mutex
+	config *tls.Config
+}
+
+func newTLSListener(l net.Listener, config *tls.Config) *tlsListener {
+	tl := &tlsListener{Listener: l}
+	tl.setConfig(config)
+	return tl
+}
+
+// setConfig sets the TLS config of the connections accepted next. A nil
+// config serves them without TLS.
+func (l *tlsListener) setConfig(config *tls.Config) {
+	if config != nil {
+		config.NextProtos = []string{"http/1.1"}
+	}
+	l.mu.Lock()
+	l.config = config
+	l.mu.Unlock()
+}
+
+func (l *tlsListener) Accept() (net.Conn, error) {
+	c, err := l.Listener.Accept()
+	if err != nil {
+		return nil, err
+	}
+	l.mu.Lock()
+	config := l.config
+	l.mu.Unlock()
+	if config == nil {
+		return c, nil
+	}
+	return tls.Server(c, config), nil
+}
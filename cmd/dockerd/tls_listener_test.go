@@ -0,0 +1,42 @@
+package main
+
+import (
+	"crypto/tls"
+	"net"
+	"testing"
+)
+
+func TestTLSListenerSetConfig(t *testing.T) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	tl := newTLSListener(l, nil)
+	defer tl.Close()
+
+	accept := func() net.Conn {
+		c, err := net.Dial("tcp", l.Addr().String())
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer c.Close()
+		conn, err := tl.Accept()
+		if err != nil {
+			t.Fatal(err)
+		}
+		conn.Close()
+		return conn
+	}
+
+	if _, ok := accept().(*tls.Conn); ok {
+		t.Fatal("Expected a connection without TLS")
+	}
+	tl.setConfig(&tls.Config{})
+	if _, ok := accept().(*tls.Conn); !ok {
+		t.Fatal("Expected a TLS connection once the config is set")
+	}
+	tl.setConfig(nil)
+	if _, ok := accept().(*tls.Conn); ok {
+		t.Fatal("Expected a connection without TLS once the config is removed")
+	}
+}
@@ -20,6 +20,7 @@ import (
 	"github.com/docker/docker/daemon/logger"
 	"github.com/docker/docker/daemon/logger/jsonfilelog"
 	"github.com/docker/docker/daemon/network"
+	"github.com/docker/docker/daemon/trace"
 	"github.com/docker/docker/image"
 	"github.com/docker/docker/layer"
 	"github.com/docker/docker/pkg/idtools"
@@ -43,6 +44,10 @@ import (
 
 const configFileName = "config.v2.json"
 
+// DefaultStopTimeout is the number of seconds the containers without a stop
+// timeout have to exit when the daemon stops them.
+const DefaultStopTimeout = 10
+
 var (
 	errInvalidEndpoint = fmt.Errorf("invalid endpoint while building port map info")
 	errInvalidNetwork  = fmt.Errorf("invalid network settings while building port map info")
@@ -87,6 +92,7 @@ type CommonContainer struct {
 	// logDriver for closing
 	LogDriver      logger.Logger  `json:"-"`
 	LogCopier      *logger.Copier `json:"-"`
+	StartTrace     *trace.Trace   `json:"-"` // phases of the start in progress, if any
 	restartManager restartmanager.RestartManager
 	attachContext  *attachContext
 }
@@ -306,33 +312,48 @@ func (container *Container) ConfigPath() (string, error) {
 	return container.GetRootResourcePath(configFileName)
 }
 
+// CheckpointDir returns the directory the checkpoints of the container are
+// stored in.
+func (container *Container) CheckpointDir() string {
+	return filepath.Join(container.Root, "checkpoints")
+}
+
 // StartLogger starts a new logger driver for the container.
-func (container *Container) StartLogger(cfg containertypes.LogConfig) (logger.Logger, error) {
+func (container *Container) StartLogger(cfg containertypes.LogConfig, ctx logger.Context) (logger.Logger, error) {
 	c, err := logger.GetLogDriver(cfg.Type)
 	if err != nil {
 		return nil, fmt.Errorf("Failed to get logging factory: %v", err)
 	}
-	ctx := logger.Context{
-		Config:              cfg.Config,
-		ContainerID:         container.ID,
-		ContainerName:       container.Name,
-		ContainerEntrypoint: container.Path,
-		ContainerArgs:       container.Args,
-		ContainerImageID:    container.ImageID.String(),
-		ContainerImageName:  container.Config.Image,
-		ContainerCreated:    container.Created,
-		ContainerEnv:        container.Config.Env,
-		ContainerLabels:     container.Config.Labels,
-		DaemonName:          "docker",
-	}
+	// ctx holds what the daemon knows about the container, the rest is
+	// filled in from the container itself.
+	ctx.Config = cfg.Config
+	ctx.ContainerID = container.ID
+	ctx.ContainerName = container.Name
+	ctx.ContainerEntrypoint = container.Path
+	ctx.ContainerArgs = container.Args
+	ctx.ContainerImageID = container.ImageID.String()
+	ctx.ContainerImageName = container.Config.Image
+	ctx.ContainerCreated = container.Created
+	ctx.ContainerEnv = container.Config.Env
+	ctx.ContainerLabels = container.Config.Labels
+	ctx.StateDir = container.Root
+	ctx.DaemonName = "docker"
 
 	// Set logging file for "json-logger"
-	if cfg.Type == jsonfilelog.Name {
-		ctx.LogPath, err = container.GetRootResourcePath(fmt.Sprintf("%s-json.log", container.ID))
-		if err != nil {
-			return nil, err
+	for _, name := range logger.DriverNames(cfg.Type) {
+		if name == jsonfilelog.Name {
+			ctx.LogPath, err = container.GetRootResourcePath(fmt.Sprintf("%s-json.log", container.ID))
+			if err != nil {
+				return nil, err
+			}
 		}
 	}
+
+	// Location for drivers that spool undelivered messages to disk
+	ctx.SpoolPath, err = container.GetRootResourcePath(fmt.Sprintf("%s-%s.spool", container.ID, cfg.Type))
+	if err != nil {
+		return nil, err
+	}
 	return c(ctx)
 }
 
@@ -573,6 +594,15 @@ func (container *Container) StopSignal() int {
 	return int(stopSignal)
 }
 
+// StopTimeout returns the number of seconds the container has to exit after
+// its stop signal when the daemon stops it, before being killed.
+func (container *Container) StopTimeout() int {
+	if container.Config.StopTimeout != nil {
+		return *container.Config.StopTimeout
+	}
+	return DefaultStopTimeout
+}
+
 // InitDNSHostConfig ensures that the dns fields are never nil.
 // New containers don't ever have those fields nil,
 // but pre created containers can still have those nil values.
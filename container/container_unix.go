@@ -207,6 +207,12 @@ func (container *Container) ShmResourcePath() (string, error) {
 	return container.GetRootResourcePath("shm")
 }
 
+// DefaultMountsResourcePath returns the path of the directory holding the
+// copies of the default mounts of the daemon
+func (container *Container) DefaultMountsResourcePath() (string, error) {
+	return container.GetRootResourcePath("defaultmounts")
+}
+
 // HasMountFor checks if path is a mountpoint
 func (container *Container) HasMountFor(path string) bool {
 	_, exists := container.MountPoints[path]
@@ -7,9 +7,14 @@ import (
 
 	"golang.org/x/net/context"
 
+	"github.com/docker/engine-api/types"
+	containertypes "github.com/docker/engine-api/types/container"
 	"github.com/docker/go-units"
 )
 
+// NoHealthcheck is the health status of the containers without health check.
+const NoHealthcheck = "none"
+
 // State holds the current container state, and has methods to get and
 // set the state. Container has an embed, which allows all of the
 // functions defined against State to run against Container.
@@ -29,13 +34,15 @@ type State struct {
 	StartedAt         time.Time
 	FinishedAt        time.Time
 	waitChan          chan struct{}
+	waitRemove        chan struct{}
 	Health            *Health
 }
 
 // NewState creates a default state object with a fresh channel for state changes.
 func NewState() *State {
 	return &State{
-		waitChan: make(chan struct{}),
+		waitChan:   make(chan struct{}),
+		waitRemove: make(chan struct{}),
 	}
 }
 
@@ -110,6 +117,24 @@ func IsValidStateString(s string) bool {
 	return true
 }
 
+// HealthString returns the health status of the container, or NoHealthcheck
+// when it has no health check.
+func (s *State) HealthString() string {
+	if s.Health == nil {
+		return NoHealthcheck
+	}
+	return s.Health.Status
+}
+
+// IsValidHealthString checks if the provided string is a valid container
+// health status or not.
+func IsValidHealthString(s string) bool {
+	return s == types.Starting ||
+		s == types.Healthy ||
+		s == types.Unhealthy ||
+		s == NoHealthcheck
+}
+
 func wait(waitChan <-chan struct{}, timeout time.Duration) error {
 	if timeout < 0 {
 		<-waitChan
@@ -172,6 +197,38 @@ func (s *State) WaitWithContext(ctx context.Context) error {
 	}
 }
 
+// WaitCondition waits until the container meets condition, and returns its
+// exit code. The not-running condition is met at once if the container is
+// not running, and otherwise at its next exit or restart, like next-exit.
+// The removal of the container meets all the conditions. Canceling ctx
+// stops the wait.
+func (s *State) WaitCondition(ctx context.Context, condition containertypes.WaitCondition) (int, error) {
+	s.Lock()
+	if condition == containertypes.WaitConditionNotRunning && !s.Running {
+		defer s.Unlock()
+		return s.exitCode, nil
+	}
+	// A nil channel never fires, so only the removal is waited for with
+	// the removed condition.
+	var waitChan chan struct{}
+	if condition != containertypes.WaitConditionRemoved {
+		waitChan = s.waitChan
+	}
+	waitRemove := s.waitRemove
+	s.Unlock()
+
+	select {
+	case <-waitChan:
+	case <-waitRemove:
+	case <-ctx.Done():
+		return -1, ctx.Err()
+	}
+
+	s.Lock()
+	defer s.Unlock()
+	return s.exitCode, nil
+}
+
 // IsRunning returns whether the running flag is set. Used by Container to check whether a container is running.
 func (s *State) IsRunning() bool {
 	s.Lock()
@@ -297,6 +354,20 @@ func (s *State) ResetRemovalInProgress() {
 	s.Unlock()
 }
 
+// SetRemoved fires the waiters for the removal of the container.
+func (s *State) SetRemoved() {
+	s.Lock()
+	defer s.Unlock()
+	if s.waitRemove == nil {
+		return
+	}
+	select {
+	case <-s.waitRemove:
+	default:
+		close(s.waitRemove)
+	}
+}
+
 // SetDead sets the container state to "dead"
 func (s *State) SetDead() {
 	s.Lock()
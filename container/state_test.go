@@ -4,6 +4,9 @@ import (
 	"sync/atomic"
 	"testing"
 	"time"
+
+	containertypes "github.com/docker/engine-api/types/container"
+	"golang.org/x/net/context"
 )
 
 func TestStateRunStop(t *testing.T) {
@@ -85,3 +88,80 @@ func TestStateTimeoutWait(t *testing.T) {
 	}
 
 }
+
+func TestStateWaitCondition(t *testing.T) {
+	s := NewState()
+	s.SetStoppedLocking(&ExitStatus{ExitCode: 2})
+
+	// A stopped container is not running already.
+	if exitCode, err := s.WaitCondition(context.Background(), containertypes.WaitConditionNotRunning); err != nil || exitCode != 2 {
+		t.Fatalf("expected the exit code 2, got %d, %v", exitCode, err)
+	}
+
+	// The next exit and the removal are waited for.
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	if _, err := s.WaitCondition(ctx, containertypes.WaitConditionNextExit); err != context.DeadlineExceeded {
+		t.Fatalf("expected the wait for the next exit to time out, got %v", err)
+	}
+
+	waitC := func(condition containertypes.WaitCondition) chan int {
+		c := make(chan int, 1)
+		go func() {
+			exitCode, _ := s.WaitCondition(context.Background(), condition)
+			c <- exitCode
+		}()
+		return c
+	}
+	nextExit, removed := waitC(containertypes.WaitConditionNextExit), waitC(containertypes.WaitConditionRemoved)
+	time.Sleep(10 * time.Millisecond)
+
+	s.Lock()
+	s.SetRunning(100, false)
+	s.Unlock()
+	s.SetStoppedLocking(&ExitStatus{ExitCode: 3})
+	select {
+	case exitCode := <-nextExit:
+		if exitCode != 3 {
+			t.Fatalf("expected the exit code 3, got %d", exitCode)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("the wait for the next exit did not return")
+	}
+	select {
+	case <-removed:
+		t.Fatal("the wait for the removal returned before the removal")
+	case <-time.After(10 * time.Millisecond):
+	}
+
+	s.SetRemoved()
+	s.SetRemoved()
+	select {
+	case exitCode := <-removed:
+		if exitCode != 3 {
+			t.Fatalf("expected the exit code 3, got %d", exitCode)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("the wait for the removal did not return")
+	}
+}
+
+func TestStateHealthString(t *testing.T) {
+	s := NewState()
+	if h := s.HealthString(); h != NoHealthcheck {
+		t.Fatalf("expected the health status %s without health check, got %s", NoHealthcheck, h)
+	}
+	if !IsValidHealthString(s.HealthString()) {
+		t.Fatalf("expected %s to be a valid health status", s.HealthString())
+	}
+
+	s.Health = &Health{}
+	s.Health.Status = "healthy"
+	if h := s.HealthString(); h != "healthy" {
+		t.Fatalf("expected the health status healthy, got %s", h)
+	}
+
+	if IsValidHealthString("sick") {
+		t.Fatal("expected sick not to be a valid health status")
+	}
+}
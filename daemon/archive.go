@@ -65,14 +65,16 @@ func (daemon *Daemon) ContainerArchivePath(name string, path string) (content io
 // path must be of a directory in the container. If it is not, the error will
 // be ErrExtractPointNotDirectory. If noOverwriteDirNonDir is true then it will
 // be an error if unpacking the given content would cause an existing directory
-// to be replaced with a non-directory and vice versa.
-func (daemon *Daemon) ContainerExtractToDir(name, path string, noOverwriteDirNonDir bool, content io.Reader) error {
+// to be replaced with a non-directory and vice versa. If copyUIDGID is true
+// then the files keep the ownership they have in the archive instead of
+// being owned by the root user of the container.
+func (daemon *Daemon) ContainerExtractToDir(name, path string, noOverwriteDirNonDir, copyUIDGID bool, content io.Reader) error {
 	container, err := daemon.GetContainer(name)
 	if err != nil {
 		return err
 	}
 
-	return daemon.containerExtractToDir(container, path, noOverwriteDirNonDir, content)
+	return daemon.containerExtractToDir(container, path, noOverwriteDirNonDir, copyUIDGID, content)
 }
 
 // containerStatPath stats the filesystem resource at the specified path in this
@@ -173,8 +175,9 @@ func (daemon *Daemon) containerArchivePath(container *container.Container, path
 // container. If it is not, the error will be ErrExtractPointNotDirectory. If
 // noOverwriteDirNonDir is true then it will be an error if unpacking the
 // given content would cause an existing directory to be replaced with a non-
-// directory and vice versa.
-func (daemon *Daemon) containerExtractToDir(container *container.Container, path string, noOverwriteDirNonDir bool, content io.Reader) (err error) {
+// directory and vice versa. If copyUIDGID is true then the files keep the
+// ownership they have in the archive.
+func (daemon *Daemon) containerExtractToDir(container *container.Container, path string, noOverwriteDirNonDir, copyUIDGID bool, content io.Reader) (err error) {
 	container.Lock()
 	defer container.Unlock()
 
@@ -257,12 +260,18 @@ func (daemon *Daemon) containerExtractToDir(container *container.Container, path
 		return ErrRootFSReadOnly
 	}
 
-	uid, gid := daemon.GetRemappedUIDGID()
 	options := &archive.TarOptions{
 		NoOverwriteDirNonDir: noOverwriteDirNonDir,
-		ChownOpts: &archive.TarChownOptions{
+	}
+	if copyUIDGID {
+		// The IDs in the archive are the ones of the container, which
+		// are mapped to the ones of the host.
+		options.UIDMaps, options.GIDMaps = daemon.GetUIDGIDMaps()
+	} else {
+		uid, gid := daemon.GetRemappedUIDGID()
+		options.ChownOpts = &archive.TarChownOptions{
 			UID: uid, GID: gid, // TODO: should all ownership be set to root (either real or remapped)?
-		},
+		}
 	}
 	if err := chrootarchive.Untar(content, resolvedPath, options); err != nil {
 		return err
@@ -0,0 +1,395 @@
+package daemon
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/Sirupsen/logrus"
+	"github.com/docker/docker/container"
+	"github.com/docker/docker/pkg/stringid"
+	"github.com/docker/engine-api/types"
+)
+
+// archiveUploadTimeout is how long an archive upload is kept while none of
+// its chunks is received.
+const archiveUploadTimeout = 24 * time.Hour
+
+// archiveUpload is an archive uploaded in chunks to be extracted in a
+// container. The chunks are written to a file until the archive is
+// extracted, so that an interrupted upload resumes from its offset instead
+// of restarting from scratch. The upload is not locked while a chunk is
+// received: it is marked as writing, and offset is only moved once the
+// chunk is complete.
+type archiveUpload struct {
+	// updated is the time the last chunk was received, in nanoseconds,
+	// accessed atomically. It comes first to be 64-bit aligned.
+	updated int64
+
+	sync.Mutex
+	uploads              *archiveUploads
+	id                   string
+	containerID          string
+	path                 string
+	noOverwriteDirNonDir bool
+	copyUIDGID           bool
+	file                 string
+	offset               int64
+	writing              bool
+	removed              bool
+}
+
+// archiveUploadLimits are the limits of the archive uploads: the maximum
+// size of each upload and of all of them, and the maximum number of uploads
+// in progress.
+type archiveUploadLimits struct {
+	maxSize      int64
+	maxTotalSize int64
+	maxUploads   int
+}
+
+// archiveUploads holds the archive uploads in progress. An upload stays
+// locked while the store is locked to remove it, so the store must not be
+// locked when an upload gets locked.
+type archiveUploads struct {
+	sync.Mutex
+	root    string
+	limits  archiveUploadLimits
+	uploads map[string]*archiveUpload
+	// size is the size of the files of the uploads
+	size int64
+}
+
+// newArchiveUploads returns a store of archive uploads keeping their files
+// in root. The uploads don't survive a restart of the daemon, so root is
+// emptied.
+func newArchiveUploads(root string, limits archiveUploadLimits) (*archiveUploads, error) {
+	if err := os.RemoveAll(root); err != nil {
+		return nil, err
+	}
+	if err := os.MkdirAll(root, 0700); err != nil {
+		return nil, err
+	}
+	return &archiveUploads{
+		root:    root,
+		limits:  limits,
+		uploads: make(map[string]*archiveUpload),
+	}, nil
+}
+
+// create starts the upload of an archive to extract to path in the
+// container. The uploads which have expired are removed first, and the
+// upload fails if the maximum number of uploads are in progress.
+func (s *archiveUploads) create(containerID, path string, noOverwriteDirNonDir, copyUIDGID bool) (*archiveUpload, error) {
+	expired := time.Now().Add(-archiveUploadTimeout).UnixNano()
+	s.removeWhere(func(u *archiveUpload) bool {
+		return atomic.LoadInt64(&u.updated) < expired
+	})
+
+	u := &archiveUpload{
+		uploads:              s,
+		id:                   stringid.GenerateRandomID(),
+		containerID:          containerID,
+		path:                 path,
+		noOverwriteDirNonDir: noOverwriteDirNonDir,
+		copyUIDGID:           copyUIDGID,
+		updated:              time.Now().UnixNano(),
+	}
+	u.file = filepath.Join(s.root, u.id)
+	f, err := os.OpenFile(u.file, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0600)
+	if err != nil {
+		return nil, err
+	}
+	f.Close()
+
+	s.Lock()
+	defer s.Unlock()
+	if len(s.uploads) >= s.limits.maxUploads {
+		os.Remove(u.file)
+		return nil, errTooManyArchiveUploads(s.limits.maxUploads)
+	}
+	s.uploads[u.id] = u
+	return u, nil
+}
+
+// get returns the upload id to the container.
+func (s *archiveUploads) get(containerID, id string) (*archiveUpload, error) {
+	s.Lock()
+	u, exists := s.uploads[id]
+	s.Unlock()
+	if !exists || u.containerID != containerID {
+		return nil, errArchiveUploadNotFound(id)
+	}
+	return u, nil
+}
+
+// remove removes the upload u, which must be locked.
+func (s *archiveUploads) remove(u *archiveUpload) {
+	u.removed = true
+	s.Lock()
+	delete(s.uploads, u.id)
+	s.size -= u.offset
+	s.Unlock()
+	if err := os.Remove(u.file); err != nil && !os.IsNotExist(err) {
+		logrus.Warnf("Error removing archive upload %s: %v", u.id, err)
+	}
+}
+
+// reserve accounts for size more bytes in the files of the uploads, failing
+// if they would be larger than the limit of all the uploads.
+func (s *archiveUploads) reserve(size int64) error {
+	s.Lock()
+	defer s.Unlock()
+	if s.size+size > s.limits.maxTotalSize {
+		return errArchiveUploadsFull(s.limits.maxTotalSize)
+	}
+	s.size += size
+	return nil
+}
+
+// release accounts for size less bytes in the files of the uploads.
+func (s *archiveUploads) release(size int64) {
+	s.Lock()
+	s.size -= size
+	s.Unlock()
+}
+
+// removeContainer removes the uploads to the container.
+func (s *archiveUploads) removeContainer(containerID string) {
+	s.removeWhere(func(u *archiveUpload) bool {
+		return u.containerID == containerID
+	})
+}
+
+// removeWhere removes the uploads for which f returns true.
+func (s *archiveUploads) removeWhere(f func(*archiveUpload) bool) {
+	var removed []*archiveUpload
+	s.Lock()
+	for _, u := range s.uploads {
+		if f(u) {
+			removed = append(removed, u)
+		}
+	}
+	s.Unlock()
+
+	for _, u := range removed {
+		u.Lock()
+		if !u.removed {
+			s.remove(u)
+		}
+		u.Unlock()
+	}
+}
+
+// write writes the chunk content of the archive, which must start at the
+// offset the upload stopped at. The chunk is either entirely kept or not
+// at all, so that it can be sent again when it is interrupted. Writing
+// fails once the upload, or all the uploads, would be larger than their
+// limit, and while another chunk is written.
+func (u *archiveUpload) write(offset int64, content io.Reader) error {
+	u.Lock()
+	if u.removed {
+		u.Unlock()
+		return errArchiveUploadNotFound(u.id)
+	}
+	if u.writing {
+		u.Unlock()
+		return errArchiveUploadBusy(u.id)
+	}
+	if offset != u.offset {
+		u.Unlock()
+		return errArchiveUploadOffset(u.id, u.offset)
+	}
+	u.writing = true
+	u.Unlock()
+	atomic.StoreInt64(&u.updated, time.Now().UnixNano())
+
+	w := &archiveUploadWriter{u: u, offset: offset}
+	err := w.writeFile(content)
+
+	u.Lock()
+	defer u.Unlock()
+	u.writing = false
+	if err == nil && u.removed {
+		err = errArchiveUploadNotFound(u.id)
+	}
+	if err != nil {
+		u.uploads.release(w.written)
+		return err
+	}
+	u.offset += w.written
+	atomic.StoreInt64(&u.updated, time.Now().UnixNano())
+	return nil
+}
+
+// archiveUploadWriter writes a chunk of the upload u, starting at offset,
+// to its file, and accounts for the bytes written in the size of the
+// uploads.
+type archiveUploadWriter struct {
+	f       *os.File
+	u       *archiveUpload
+	offset  int64
+	written int64
+}
+
+// writeFile writes content to the file of the upload. The bytes written are
+// truncated if it fails, and their size is left for the caller to release.
+func (w *archiveUploadWriter) writeFile(content io.Reader) error {
+	f, err := os.OpenFile(w.u.file, os.O_WRONLY, 0600)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	if _, err := f.Seek(w.offset, os.SEEK_SET); err != nil {
+		return err
+	}
+	w.f = f
+	if _, err := io.Copy(w, content); err != nil {
+		// The bytes past the offset are overwritten by the next chunk,
+		// and never extracted.
+		f.Truncate(w.offset)
+		return err
+	}
+	return nil
+}
+
+func (w *archiveUploadWriter) Write(p []byte) (int, error) {
+	size := int64(len(p))
+	if limit := w.u.uploads.limits.maxSize; w.offset+w.written+size > limit {
+		return 0, errArchiveUploadTooLarge(w.u.id, limit)
+	}
+	if err := w.u.uploads.reserve(size); err != nil {
+		return 0, err
+	}
+	n, err := w.f.Write(p)
+	w.written += int64(n)
+	if n < len(p) {
+		w.u.uploads.release(size - int64(n))
+	}
+	return n, err
+}
+
+// toAPIType returns the state of the upload u, which must be locked.
+func (u *archiveUpload) toAPIType() *types.ArchiveUpload {
+	return &types.ArchiveUpload{
+		ID:     u.id,
+		Path:   u.path,
+		Offset: u.offset,
+	}
+}
+
+// getArchiveUpload returns the container identified by name and its upload
+// id.
+func (daemon *Daemon) getArchiveUpload(name, id string) (*container.Container, *archiveUpload, error) {
+	container, err := daemon.GetContainer(name)
+	if err != nil {
+		return nil, nil, err
+	}
+	u, err := daemon.archiveUploads.get(container.ID, id)
+	if err != nil {
+		return nil, nil, err
+	}
+	return container, u, nil
+}
+
+// ContainerArchiveUploadCreate starts the upload in chunks of an archive to
+// extract to the specified location in the filesystem of the container
+// identified by the given name. See ContainerExtractToDir for
+// noOverwriteDirNonDir and copyUIDGID.
+func (daemon *Daemon) ContainerArchiveUploadCreate(name, path string, noOverwriteDirNonDir, copyUIDGID bool) (*types.ArchiveUpload, error) {
+	container, err := daemon.GetContainer(name)
+	if err != nil {
+		return nil, err
+	}
+
+	u, err := daemon.archiveUploads.create(container.ID, path, noOverwriteDirNonDir, copyUIDGID)
+	if err != nil {
+		return nil, err
+	}
+
+	u.Lock()
+	defer u.Unlock()
+	return u.toAPIType(), nil
+}
+
+// ContainerArchiveUploadInspect returns the state of the archive upload id
+// to the container identified by the given name.
+func (daemon *Daemon) ContainerArchiveUploadInspect(name, id string) (*types.ArchiveUpload, error) {
+	_, u, err := daemon.getArchiveUpload(name, id)
+	if err != nil {
+		return nil, err
+	}
+
+	u.Lock()
+	defer u.Unlock()
+	if u.removed {
+		return nil, errArchiveUploadNotFound(id)
+	}
+	return u.toAPIType(), nil
+}
+
+// ContainerArchiveUploadWrite writes a chunk of the archive upload id, which
+// starts at offset in the archive.
+func (daemon *Daemon) ContainerArchiveUploadWrite(name, id string, offset int64, content io.Reader) (*types.ArchiveUpload, error) {
+	_, u, err := daemon.getArchiveUpload(name, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := u.write(offset, content); err != nil {
+		return nil, err
+	}
+	return daemon.ContainerArchiveUploadInspect(name, id)
+}
+
+// ContainerArchiveUploadExtract extracts the archive upload id in the
+// container identified by the given name, and removes the upload. The
+// upload is kept if the extraction fails, and can't be extracted while a
+// chunk is written.
+func (daemon *Daemon) ContainerArchiveUploadExtract(name, id string) error {
+	container, u, err := daemon.getArchiveUpload(name, id)
+	if err != nil {
+		return err
+	}
+
+	u.Lock()
+	defer u.Unlock()
+	if u.removed {
+		return errArchiveUploadNotFound(id)
+	}
+	if u.writing {
+		return errArchiveUploadBusy(id)
+	}
+
+	f, err := os.Open(u.file)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	if err := daemon.containerExtractToDir(container, u.path, u.noOverwriteDirNonDir, u.copyUIDGID, io.LimitReader(f, u.offset)); err != nil {
+		return err
+	}
+
+	daemon.archiveUploads.remove(u)
+	return nil
+}
+
+// ContainerArchiveUploadRemove aborts the archive upload id to the container
+// identified by the given name.
+func (daemon *Daemon) ContainerArchiveUploadRemove(name, id string) error {
+	_, u, err := daemon.getArchiveUpload(name, id)
+	if err != nil {
+		return err
+	}
+
+	u.Lock()
+	defer u.Unlock()
+	if u.removed {
+		return errArchiveUploadNotFound(id)
+	}
+	daemon.archiveUploads.remove(u)
+	return nil
+}
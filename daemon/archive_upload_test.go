@@ -0,0 +1,204 @@
+package daemon
+
+import (
+	"errors"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// failingReader returns the content of a reader followed by an error, like
+// the body of an interrupted request.
+type failingReader struct {
+	r io.Reader
+}
+
+func (f failingReader) Read(p []byte) (int, error) {
+	n, err := f.r.Read(p)
+	if err == io.EOF {
+		return n, errors.New("unexpected EOF")
+	}
+	return n, err
+}
+
+var testArchiveUploadLimits = archiveUploadLimits{maxSize: 1024, maxTotalSize: 1024, maxUploads: 16}
+
+func TestArchiveUploadWrite(t *testing.T) {
+	tmp, err := ioutil.TempDir("", "docker-archive-upload-test-")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(tmp)
+
+	s, err := newArchiveUploads(filepath.Join(tmp, "uploads"), testArchiveUploadLimits)
+	if err != nil {
+		t.Fatal(err)
+	}
+	u, err := s.create("container", "/data", true, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := u.write(0, strings.NewReader("hello ")); err != nil {
+		t.Fatal(err)
+	}
+	if err := u.write(0, strings.NewReader("hello ")); err == nil || !strings.Contains(err.Error(), "offset 6") {
+		t.Fatalf("Expected an offset mismatch error, got %v", err)
+	}
+
+	// An interrupted chunk is not kept, and is sent again
+	if err := u.write(6, failingReader{strings.NewReader("wor")}); err == nil {
+		t.Fatal("Expected the interrupted chunk to fail")
+	}
+	if u.offset != 6 {
+		t.Fatalf("Expected the upload to stay at offset 6, got %d", u.offset)
+	}
+	if err := u.write(6, strings.NewReader("world")); err != nil {
+		t.Fatal(err)
+	}
+
+	content, err := ioutil.ReadFile(u.file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(content) != "hello world" {
+		t.Fatalf("Expected the upload to hold %q, got %q", "hello world", content)
+	}
+}
+
+func TestArchiveUploadRemove(t *testing.T) {
+	tmp, err := ioutil.TempDir("", "docker-archive-upload-test-")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(tmp)
+
+	s, err := newArchiveUploads(filepath.Join(tmp, "uploads"), testArchiveUploadLimits)
+	if err != nil {
+		t.Fatal(err)
+	}
+	u, err := s.create("container", "/data", true, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.get("other", u.id); err == nil {
+		t.Fatal("Expected the upload not to be found for another container")
+	}
+	if _, err := s.get("container", u.id); err != nil {
+		t.Fatal(err)
+	}
+
+	s.removeContainer("container")
+	if _, err := s.get("container", u.id); err == nil {
+		t.Fatal("Expected the upload to be removed with its container")
+	}
+	if _, err := os.Stat(u.file); !os.IsNotExist(err) {
+		t.Fatalf("Expected the file of the upload to be removed, got %v", err)
+	}
+	if err := u.write(0, strings.NewReader("hello")); err == nil {
+		t.Fatal("Expected writing a removed upload to fail")
+	}
+}
+
+func TestArchiveUploadLimits(t *testing.T) {
+	tmp, err := ioutil.TempDir("", "docker-archive-upload-test-")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(tmp)
+
+	s, err := newArchiveUploads(filepath.Join(tmp, "uploads"), archiveUploadLimits{maxSize: 8, maxTotalSize: 12, maxUploads: 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+	u1, err := s.create("container", "/data", true, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	u2, err := s.create("container", "/data", true, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.create("container", "/data", true, false); err == nil || !strings.Contains(err.Error(), "Too many archive uploads") {
+		t.Fatalf("Expected too many uploads error, got %v", err)
+	}
+
+	if err := u1.write(0, strings.NewReader("123456789")); err == nil || !strings.Contains(err.Error(), "larger than the limit") {
+		t.Fatalf("Expected the upload to be too large, got %v", err)
+	}
+	if err := u1.write(0, strings.NewReader("12345678")); err != nil {
+		t.Fatal(err)
+	}
+	if err := u2.write(0, strings.NewReader("12345")); err == nil || !strings.Contains(err.Error(), "use the limit") {
+		t.Fatalf("Expected the uploads to be full, got %v", err)
+	}
+	if u2.offset != 0 || s.size != 8 {
+		t.Fatalf("Expected the failed chunk not to be kept, got offset %d and size %d", u2.offset, s.size)
+	}
+	if err := u2.write(0, strings.NewReader("1234")); err != nil {
+		t.Fatal(err)
+	}
+
+	// Removing an upload frees its space and its slot
+	u1.Lock()
+	s.remove(u1)
+	u1.Unlock()
+	if s.size != 4 {
+		t.Fatalf("Expected the uploads to use 4 bytes, got %d", s.size)
+	}
+	if _, err := s.create("container", "/data", true, false); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestArchiveUploadStalledWrite(t *testing.T) {
+	tmp, err := ioutil.TempDir("", "docker-archive-upload-test-")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(tmp)
+
+	s, err := newArchiveUploads(filepath.Join(tmp, "uploads"), testArchiveUploadLimits)
+	if err != nil {
+		t.Fatal(err)
+	}
+	u, err := s.create("container", "/data", true, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// A chunk which stalls doesn't keep the upload locked
+	r, w := io.Pipe()
+	done := make(chan error)
+	go func() {
+		done <- u.write(0, r)
+	}()
+	if _, err := w.Write([]byte("hello")); err != nil {
+		t.Fatal(err)
+	}
+
+	u.Lock()
+	offset := u.offset
+	u.Unlock()
+	if offset != 0 {
+		t.Fatalf("Expected the upload to stay at offset 0 while the chunk is written, got %d", offset)
+	}
+	if err := u.write(0, strings.NewReader("hello")); err == nil || !strings.Contains(err.Error(), "is being written") {
+		t.Fatalf("Expected a busy error, got %v", err)
+	}
+
+	// Removing the upload fails the chunk, and frees its space
+	u.Lock()
+	s.remove(u)
+	u.Unlock()
+	w.Close()
+	if err := <-done; err == nil {
+		t.Fatal("Expected the chunk of a removed upload to fail")
+	}
+	if s.size != 0 {
+		t.Fatalf("Expected the uploads to use no space, got %d", s.size)
+	}
+}
@@ -0,0 +1,176 @@
+package daemon
+
+import (
+	"archive/tar"
+	"bytes"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/docker/docker/container"
+)
+
+// Backup writes to w a tar archive of the metadata of the daemon, relative
+// to its root directory: the references, the configurations, the layer
+// metadata and the distribution metadata of the images, the configurations
+// of the containers and the metadata of the volumes. The content of the
+// layers and of the volumes is left out. A file of pinned references set
+// outside of the root directory is in the archive as
+// pinned-references.json.
+//
+// The daemon keeps running meanwhile. Its stores replace their files
+// atomically, so each file of the archive is consistent. The references
+// are read before the images, and the images before the layers, so that
+// the images and the layers they point at are in the archive unless
+// removed meanwhile.
+func (daemon *Daemon) Backup(w io.Writer) error {
+	b := &backupWriter{tw: tar.NewWriter(w), root: daemon.root, time: time.Now()}
+
+	imageDir := filepath.Join("image", daemon.GraphDriverName())
+	if err := b.addFile(filepath.Join(imageDir, "repositories.json")); err != nil {
+		return err
+	}
+	pinsPath := pinnedReferencesPath(daemon.configStore, filepath.Join(daemon.root, imageDir))
+	if err := b.addFileAs(b.backupName(pinsPath, "pinned-references.json"), pinsPath); err != nil {
+		return err
+	}
+	for _, dir := range []string{"imagedb", "layerdb", "distribution"} {
+		if err := b.addTree(filepath.Join(imageDir, dir)); err != nil {
+			return err
+		}
+	}
+
+	for _, c := range daemon.List() {
+		if err := backupContainer(b, c); err != nil {
+			return err
+		}
+	}
+
+	if daemon.volumes != nil {
+		var db bytes.Buffer
+		if err := daemon.volumes.BackupMetadata(&db); err != nil {
+			return err
+		}
+		if db.Len() > 0 {
+			if err := b.add(filepath.Join("volumes", "metadata.db"), db.Bytes(), 0600); err != nil {
+				return err
+			}
+		}
+	}
+
+	return b.tw.Close()
+}
+
+// backupContainer adds the configuration and the host configuration of the
+// container to the backup, read together under the lock of the container.
+func backupContainer(b *backupWriter, c *container.Container) error {
+	c.Lock()
+	defer c.Unlock()
+
+	for _, path := range []func() (string, error){c.ConfigPath, c.HostConfigPath} {
+		pth, err := path()
+		if err != nil {
+			return err
+		}
+		data, err := ioutil.ReadFile(pth)
+		if os.IsNotExist(err) {
+			// the container is being created, or was removed
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		if err := b.add(filepath.Join("containers", c.ID, filepath.Base(pth)), data, 0600); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// backupWriter writes the files of a backup, named relative to the root
+// directory of the daemon.
+type backupWriter struct {
+	tw   *tar.Writer
+	root string
+	time time.Time
+}
+
+// add adds a file with the given content to the backup.
+func (b *backupWriter) add(name string, data []byte, mode int64) error {
+	hdr := &tar.Header{
+		Name:     filepath.ToSlash(name),
+		Mode:     mode,
+		Size:     int64(len(data)),
+		ModTime:  b.time,
+		Typeflag: tar.TypeReg,
+	}
+	if err := b.tw.WriteHeader(hdr); err != nil {
+		return err
+	}
+	_, err := b.tw.Write(data)
+	return err
+}
+
+// backupName returns the name in the backup of the file at pth: its path
+// relative to the root, or name if it is outside of the root.
+func (b *backupWriter) backupName(pth, name string) string {
+	rel, err := filepath.Rel(b.root, pth)
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return name
+	}
+	return rel
+}
+
+// addFile adds the file at name under the root to the backup, if it exists.
+func (b *backupWriter) addFile(name string) error {
+	return b.addFileAs(name, filepath.Join(b.root, name))
+}
+
+// addFileAs adds the file at pth to the backup as name, if it exists.
+func (b *backupWriter) addFileAs(name, pth string) error {
+	fi, err := os.Stat(pth)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	data, err := ioutil.ReadFile(pth)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	return b.add(name, data, int64(fi.Mode().Perm()))
+}
+
+// addTree adds the regular files under the directory at name under the
+// root to the backup. The files removed while it walks the directory, and
+// the temporary files of the atomic writes in progress, are left out.
+func (b *backupWriter) addTree(name string) error {
+	root := filepath.Join(b.root, name)
+	err := filepath.Walk(root, func(pth string, fi os.FileInfo, err error) error {
+		if err != nil {
+			if os.IsNotExist(err) {
+				return nil
+			}
+			return err
+		}
+		if !fi.Mode().IsRegular() || strings.HasPrefix(fi.Name(), ".tmp-") {
+			return nil
+		}
+		rel, err := filepath.Rel(b.root, pth)
+		if err != nil {
+			return err
+		}
+		return b.addFile(rel)
+	})
+	if os.IsNotExist(err) {
+		return nil
+	}
+	return err
+}
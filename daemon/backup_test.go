@@ -0,0 +1,121 @@
+package daemon
+
+import (
+	"archive/tar"
+	"bytes"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/docker/docker/container"
+)
+
+func TestBackupWriter(t *testing.T) {
+	root, err := ioutil.TempDir("", "docker-backup-")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(root)
+
+	files := map[string]string{
+		"image/vfs/repositories.json":                             `{"Repositories":{}}`,
+		"image/vfs/imagedb/content/sha256/0123":                   `{"config":{}}`,
+		"image/vfs/imagedb/content/sha256/.tmp-0456":              `{"conf`,
+		"image/vfs/imagedb/metadata/sha256/0123/lastUpdated":      "2016-09-20",
+		"image/vfs/layerdb/sha256/4567/cache-id":                  "89ab",
+		"image/vfs/distribution/v2metadata-by-diffid/sha256/4567": `[]`,
+		"containers/c1/config.v2.json":                            `{"ID":"c1"}`,
+		"containers/c1/hostconfig.json":                           `{}`,
+		"containers/c1/c1-json.log":                               "log line\n",
+	}
+	for name, content := range files {
+		pth := filepath.Join(root, name)
+		if err := os.MkdirAll(filepath.Dir(pth), 0700); err != nil {
+			t.Fatal(err)
+		}
+		if err := ioutil.WriteFile(pth, []byte(content), 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// a file of pinned references outside of the root
+	pinsDir, err := ioutil.TempDir("", "docker-backup-pins-")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(pinsDir)
+	pinsPath := filepath.Join(pinsDir, "pins.json")
+	if err := ioutil.WriteFile(pinsPath, []byte(`{}`), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	var buf bytes.Buffer
+	b := &backupWriter{tw: tar.NewWriter(&buf), root: root, time: time.Now()}
+	if err := b.addFile("image/vfs/repositories.json"); err != nil {
+		t.Fatal(err)
+	}
+	if name := b.backupName(filepath.Join(root, "image/vfs/pinned-references.json"), "pinned-references.json"); name != "image/vfs/pinned-references.json" {
+		t.Fatalf("expected the pins under the root to keep their path, got %s", name)
+	}
+	if err := b.addFileAs(b.backupName(pinsPath, "pinned-references.json"), pinsPath); err != nil {
+		t.Fatal(err)
+	}
+	for _, dir := range []string{"imagedb", "layerdb", "distribution"} {
+		if err := b.addTree(filepath.Join("image/vfs", dir)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := b.addTree("image/vfs/missing"); err != nil {
+		t.Fatal(err)
+	}
+	c := container.NewBaseContainer("c1", filepath.Join(root, "containers", "c1"))
+	if err := backupContainer(b, c); err != nil {
+		t.Fatal(err)
+	}
+	if err := b.tw.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	got := make(map[string]string)
+	tr := tar.NewReader(&buf)
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		content, err := ioutil.ReadAll(tr)
+		if err != nil {
+			t.Fatal(err)
+		}
+		got[hdr.Name] = string(content)
+	}
+
+	// the logs and the temporary files are left out
+	files["pinned-references.json"] = `{}`
+	var expected []string
+	for name := range files {
+		if name != "containers/c1/c1-json.log" && name != "image/vfs/imagedb/content/sha256/.tmp-0456" {
+			expected = append(expected, name)
+			if got[name] != files[name] {
+				t.Fatalf("expected %q in %s, got %q", files[name], name, got[name])
+			}
+		}
+	}
+	var names []string
+	for name := range got {
+		names = append(names, name)
+	}
+	sort.Strings(expected)
+	sort.Strings(names)
+	if !reflect.DeepEqual(names, expected) {
+		t.Fatalf("expected the files %v, got %v", expected, names)
+	}
+}
@@ -0,0 +1,471 @@
+package daemon
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strconv"
+	"time"
+
+	"github.com/Sirupsen/logrus"
+	"github.com/docker/distribution/digest"
+	"github.com/docker/docker/api/types/backend"
+	"github.com/docker/docker/cliconfig"
+	"github.com/docker/docker/cliconfig/credentials"
+	"github.com/docker/docker/container"
+	"github.com/docker/docker/daemon/bootstrap"
+	"github.com/docker/docker/distribution"
+	"github.com/docker/docker/errors"
+	"github.com/docker/docker/pkg/ioutils"
+	"github.com/docker/docker/reference"
+	"github.com/docker/docker/registry"
+	"github.com/docker/engine-api/types"
+	"golang.org/x/net/context"
+)
+
+// bootstrapStateFile is the file of the daemon root recording that a
+// bootstrap spec was applied.
+const bootstrapStateFile = "bootstrap.json"
+
+// The statuses of the bootstrap events.
+const (
+	bootstrapStatusStarted   = "started"
+	bootstrapStatusCompleted = "completed"
+	bootstrapStatusFailed    = "failed"
+	bootstrapStatusCreated   = "created"
+	bootstrapStatusExists    = "exists"
+	bootstrapStatusRecreated = "recreated"
+	bootstrapStatusAdopted   = "adopted"
+)
+
+// The drifts of the containers declared by a bootstrap spec.
+const (
+	driftMissing = "missing"
+	driftConfig  = "config"
+	driftImage   = "image"
+)
+
+// bootstrapStopTimeout is how long the containers recreated by the
+// reconciliation have to stop before being killed, in seconds.
+const bootstrapStopTimeout = 10
+
+// bootstrapState is the content of the bootstrap state file.
+type bootstrapState struct {
+	// SpecDigest is the digest of the spec applied.
+	SpecDigest digest.Digest
+	// Completed is when the spec was applied.
+	Completed time.Time
+}
+
+// loadBootstrapSpec returns the bootstrap spec of the file at path, or nil
+// if a spec was already applied on a previous boot.
+func (daemon *Daemon) loadBootstrapSpec(path string) (*bootstrap.Spec, error) {
+	spec, err := bootstrap.Load(path)
+	if err != nil {
+		return nil, err
+	}
+
+	b, err := ioutil.ReadFile(filepath.Join(daemon.root, bootstrapStateFile))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return spec, nil
+		}
+		return nil, err
+	}
+	var state bootstrapState
+	if err := json.Unmarshal(b, &state); err != nil {
+		return nil, fmt.Errorf("error reading the bootstrap state: %v", err)
+	}
+	if state.SpecDigest != spec.Digest {
+		logrus.Warnf("Bootstrap spec %s changed since it was applied on %s, the changes are not applied", path, state.Completed.Format(time.RFC3339))
+	} else {
+		logrus.Debugf("Bootstrap spec %s was applied on %s", path, state.Completed.Format(time.RFC3339))
+	}
+	return nil, nil
+}
+
+// bootstrap applies spec: it pulls its images, and creates its networks,
+// volumes and containers, unless they exist. The progress is reported with
+// bootstrap events. The state file is only written once all the items are
+// created, so that the items which failed are retried on the next boot.
+func (daemon *Daemon) bootstrap(spec *bootstrap.Spec) {
+	logrus.Infof("Applying bootstrap spec %s", spec.Digest)
+	daemon.logBootstrapEvent(map[string]string{"status": bootstrapStatusStarted, "digest": spec.Digest.String()})
+
+	failures := 0
+	report := func(kind, name string, created bool, err error) {
+		attributes := map[string]string{"type": kind, "item": name}
+		switch {
+		case err != nil:
+			logrus.Errorf("Bootstrap of %s %s failed: %v", kind, name, err)
+			failures++
+			attributes["status"] = bootstrapStatusFailed
+			attributes["error"] = err.Error()
+		case created:
+			attributes["status"] = bootstrapStatusCreated
+		default:
+			attributes["status"] = bootstrapStatusExists
+		}
+		daemon.logBootstrapEvent(attributes)
+	}
+
+	for _, img := range spec.Images {
+		created, err := daemon.bootstrapImage(spec, img, false)
+		report("image", img.Name, created, err)
+	}
+	for _, n := range spec.Networks {
+		created, err := daemon.bootstrapNetwork(n)
+		report("network", n.Name, created, err)
+	}
+	for _, v := range spec.Volumes {
+		created, err := daemon.bootstrapVolume(v)
+		report("volume", v.Name, created, err)
+	}
+	for _, c := range spec.Containers {
+		created, err := daemon.bootstrapContainer(c)
+		report("container", c.Name, created, err)
+	}
+
+	if failures > 0 {
+		logrus.Errorf("Bootstrap spec %s failed for %d items, it is applied again on the next boot", spec.Digest, failures)
+		daemon.logBootstrapEvent(map[string]string{"status": bootstrapStatusFailed, "digest": spec.Digest.String(), "failures": strconv.Itoa(failures)})
+		return
+	}
+
+	b, err := json.Marshal(bootstrapState{SpecDigest: spec.Digest, Completed: time.Now().UTC()})
+	if err == nil {
+		err = ioutils.AtomicWriteFile(filepath.Join(daemon.root, bootstrapStateFile), b, 0600)
+	}
+	if err != nil {
+		logrus.Errorf("Error recording the bootstrap state: %v", err)
+	}
+	logrus.Infof("Bootstrap spec %s applied", spec.Digest)
+	daemon.logBootstrapEvent(map[string]string{"status": bootstrapStatusCompleted, "digest": spec.Digest.String()})
+}
+
+func (daemon *Daemon) logBootstrapEvent(attributes map[string]string) {
+	daemon.LogDaemonEventWithAttributes("bootstrap", attributes)
+}
+
+// bootstrapImage pulls img of spec unless it exists, or, with refresh,
+// unless it has a digest. An image with a digest is pulled by digest, and
+// tagged with its name. It reports whether the name points to a new image.
+func (daemon *Daemon) bootstrapImage(spec *bootstrap.Spec, img bootstrap.Image, refresh bool) (bool, error) {
+	name, err := daemon.resolveShortName(img.Name)
+	if err != nil {
+		return false, err
+	}
+	ref, err := reference.ParseNamed(name)
+	if err != nil {
+		return false, err
+	}
+	ref = reference.WithDefaultTag(ref)
+
+	pullRef := ref
+	if img.Digest != "" {
+		if pullRef, err = reference.WithDigest(ref, img.Digest); err != nil {
+			return false, err
+		}
+	}
+
+	before, _ := daemon.GetImageID(ref.String())
+	if _, err := daemon.GetImage(pullRef.String()); err != nil || (refresh && img.Digest == "") {
+		authConfig, err := daemon.bootstrapAuthConfig(spec.AuthConfigDir, ref)
+		if err != nil {
+			return false, err
+		}
+		if err := daemon.pullImageWithReference(context.Background(), pullRef, distribution.Platform{}, false, nil, authConfig, ioutil.Discard); err != nil {
+			return false, err
+		}
+	}
+
+	if tagged, isTagged := ref.(reference.NamedTagged); isTagged && img.Digest != "" {
+		id, err := daemon.GetImageID(pullRef.String())
+		if err != nil {
+			return false, err
+		}
+		if id != before {
+			if err := daemon.TagImageWithReference(id, tagged); err != nil {
+				return false, err
+			}
+		}
+	}
+
+	after, err := daemon.GetImageID(ref.String())
+	if err != nil {
+		return false, err
+	}
+	return after != before, nil
+}
+
+// bootstrapAuthConfig returns the credentials to pull ref with, from the
+// client configuration in configDir, or, if it is not set, from the client
+// configuration of the user running the daemon. The credentials helper the
+// configuration names, if any, is used like by the client.
+func (daemon *Daemon) bootstrapAuthConfig(configDir string, ref reference.Named) (*types.AuthConfig, error) {
+	if configDir == "" {
+		configDir = cliconfig.ConfigDir()
+	}
+	configFile, err := cliconfig.Load(configDir)
+	if err != nil {
+		return nil, err
+	}
+	repoInfo, err := daemon.RegistryService.ResolveRepository(ref)
+	if err != nil {
+		return nil, err
+	}
+
+	var store credentials.Store
+	if configFile.CredentialsStore != "" {
+		store = credentials.NewNativeStore(configFile)
+	} else {
+		store = credentials.NewFileStore(configFile)
+	}
+	authConfig, err := store.Get(registry.GetAuthConfigKey(repoInfo.Index))
+	if err != nil {
+		return nil, fmt.Errorf("error reading the credentials of %s from %s: %v", repoInfo.Index.Name, configDir, err)
+	}
+	return &authConfig, nil
+}
+
+func (daemon *Daemon) bootstrapNetwork(create types.NetworkCreateRequest) (bool, error) {
+	if _, err := daemon.GetNetworkByName(create.Name); err == nil {
+		return false, nil
+	}
+	if _, err := daemon.CreateNetwork(create); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+func (daemon *Daemon) bootstrapVolume(create types.VolumeCreateRequest) (bool, error) {
+	if _, err := daemon.volumes.Get(create.Name); err == nil {
+		return false, nil
+	}
+	if _, err := daemon.VolumeCreate(create.Name, create.Driver, create.DriverOpts, create.Labels); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+func (daemon *Daemon) bootstrapContainer(c bootstrap.Container) (bool, error) {
+	if container, err := daemon.GetContainer(c.Name); err == nil {
+		// the container may have been created on a boot the bootstrap
+		// failed, without being started
+		if c.Start && !container.IsRunning() {
+			return false, daemon.ContainerStart(c.Name, nil, false, "")
+		}
+		return false, nil
+	}
+	return true, daemon.createBootstrapContainer(c)
+}
+
+// createBootstrapContainer creates the container c, with the hash of its
+// declaration as label, and starts it if it is declared so.
+func (daemon *Daemon) createBootstrapContainer(c bootstrap.Container) error {
+	config := *c.Config
+	config.Labels = make(map[string]string, len(c.Config.Labels)+1)
+	for k, v := range c.Config.Labels {
+		config.Labels[k] = v
+	}
+	config.Labels[bootstrap.HashLabel] = c.Hash()
+
+	if _, err := daemon.ContainerCreate(types.ContainerCreateConfig{
+		Name:             c.Name,
+		Config:           &config,
+		HostConfig:       c.HostConfig,
+		NetworkingConfig: c.NetworkingConfig,
+	}, false); err != nil {
+		return err
+	}
+	if c.Start {
+		return daemon.ContainerStart(c.Name, nil, false, "")
+	}
+	return nil
+}
+
+// BootstrapDrift returns how the containers declared by the bootstrap spec
+// of the daemon drifted from their declaration.
+func (daemon *Daemon) BootstrapDrift() ([]backend.ContainerDrift, error) {
+	path := daemon.configStore.BootstrapSpec
+	if path == "" {
+		return nil, errors.NewRequestNotFoundError(fmt.Errorf("no bootstrap spec is configured"))
+	}
+	spec, err := bootstrap.Load(path)
+	if err != nil {
+		return nil, err
+	}
+
+	drifts := make([]backend.ContainerDrift, 0, len(spec.Containers))
+	for _, c := range spec.Containers {
+		drifts = append(drifts, daemon.containerDrift(c))
+	}
+	return drifts, nil
+}
+
+// containerDrift returns how the container c drifted from its declaration.
+// A container without the hash of a declaration, created before the
+// reconciliation or by hand, is adopted as is, so its configuration does
+// not drift.
+func (daemon *Daemon) containerDrift(c bootstrap.Container) backend.ContainerDrift {
+	drift := backend.ContainerDrift{Name: c.Name}
+	container, err := daemon.GetContainer(c.Name)
+	if err != nil {
+		drift.Drift = driftMissing
+		return drift
+	}
+	drift.ID = container.ID
+
+	if hash, ok := container.Config.Labels[bootstrap.HashLabel]; ok && hash != c.Hash() {
+		drift.Drift = driftConfig
+	} else if id, err := daemon.GetImageID(c.Config.Image); err != nil || id != container.ImageID {
+		drift.Drift = driftImage
+	}
+	return drift
+}
+
+// adoptBootstrapContainer records the hash of the declaration c in the
+// container, unless it has one, so that the changes of the declaration
+// made afterwards are found. It reports whether the container was adopted.
+func (daemon *Daemon) adoptBootstrapContainer(container *container.Container, c bootstrap.Container) (bool, error) {
+	container.Lock()
+	defer container.Unlock()
+	if _, ok := container.Config.Labels[bootstrap.HashLabel]; ok {
+		return false, nil
+	}
+	if container.Config.Labels == nil {
+		container.Config.Labels = make(map[string]string)
+	}
+	container.Config.Labels[bootstrap.HashLabel] = c.Hash()
+	return true, container.ToDisk()
+}
+
+// checkBootstrapImage checks the image of the declaration c before its
+// container is recreated: the image must be allowed by the image policy of
+// the daemon, and its local content must match its digests. The container
+// is kept as is otherwise, instead of being removed for a container which
+// cannot be created or runs an image that cannot be trusted.
+func (daemon *Daemon) checkBootstrapImage(c bootstrap.Container) error {
+	img, err := daemon.GetImage(c.Config.Image)
+	if err != nil {
+		return err
+	}
+	if err := daemon.verifyImagePolicy(c.Config.Image, img.ID()); err != nil {
+		return err
+	}
+	if _, ok := daemon.verifiedImages.get(img.ID(), ""); ok {
+		return nil
+	}
+	if err := daemon.verifyImageLayers(img); err != nil {
+		return fmt.Errorf("the local content of image %s does not match its digests: %v", c.Config.Image, err)
+	}
+	daemon.verifiedImages.add(img.ID(), "", time.Now().UTC())
+	return nil
+}
+
+// runBootstrap applies spec, if any, then reconciles the spec of the file
+// at path with the containers every interval, if it is set, until the
+// daemon shuts down.
+func (daemon *Daemon) runBootstrap(path string, spec *bootstrap.Spec, interval time.Duration) {
+	if spec != nil {
+		daemon.bootstrap(spec)
+	}
+	if interval == 0 {
+		return
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for range ticker.C {
+		if daemon.IsShuttingDown() {
+			return
+		}
+		if daemon.IsDraining() {
+			continue
+		}
+		daemon.reconcileBootstrap(path)
+	}
+}
+
+// reconcileBootstrap reads the spec of the file at path, refreshes its
+// images, creates its missing items, and recreates the containers which
+// drifted from their declaration. Only the changes and the failures are
+// reported with bootstrap events.
+func (daemon *Daemon) reconcileBootstrap(path string) {
+	spec, err := bootstrap.Load(path)
+	if err != nil {
+		logrus.Errorf("Error loading bootstrap spec to reconcile: %v", err)
+		daemon.logBootstrapEvent(map[string]string{"status": bootstrapStatusFailed, "error": err.Error()})
+		return
+	}
+
+	report := func(kind, name, status string, err error) {
+		attributes := map[string]string{"type": kind, "item": name, "status": status}
+		if err != nil {
+			logrus.Errorf("Reconciliation of %s %s failed: %v", kind, name, err)
+			attributes["status"] = bootstrapStatusFailed
+			attributes["error"] = err.Error()
+		} else if status == bootstrapStatusExists {
+			return
+		}
+		daemon.logBootstrapEvent(attributes)
+	}
+	status := func(created bool) string {
+		if created {
+			return bootstrapStatusCreated
+		}
+		return bootstrapStatusExists
+	}
+
+	for _, img := range spec.Images {
+		created, err := daemon.bootstrapImage(spec, img, true)
+		report("image", img.Name, status(created), err)
+	}
+	for _, n := range spec.Networks {
+		created, err := daemon.bootstrapNetwork(n)
+		report("network", n.Name, status(created), err)
+	}
+	for _, v := range spec.Volumes {
+		created, err := daemon.bootstrapVolume(v)
+		report("volume", v.Name, status(created), err)
+	}
+	for _, c := range spec.Containers {
+		if container, err := daemon.GetContainer(c.Name); err == nil {
+			if adopted, err := daemon.adoptBootstrapContainer(container, c); adopted || err != nil {
+				report("container", c.Name, bootstrapStatusAdopted, err)
+			}
+		}
+
+		drift := daemon.containerDrift(c)
+		switch drift.Drift {
+		case "":
+		case driftMissing:
+			report("container", c.Name, bootstrapStatusCreated, daemon.createBootstrapContainer(c))
+		default:
+			if err := daemon.checkBootstrapImage(c); err != nil {
+				report("container", c.Name, bootstrapStatusRecreated, fmt.Errorf("not recreated after its %s drifted: %v", drift.Drift, err))
+				continue
+			}
+			logrus.Infof("Recreating container %s, whose %s drifted from the bootstrap spec", c.Name, drift.Drift)
+			report("container", c.Name, bootstrapStatusRecreated, daemon.recreateBootstrapContainer(drift.ID, c))
+		}
+	}
+}
+
+// recreateBootstrapContainer replaces the container of the given ID with a
+// new container created from its declaration c.
+func (daemon *Daemon) recreateBootstrapContainer(id string, c bootstrap.Container) error {
+	container, err := daemon.GetContainer(id)
+	if err != nil {
+		return err
+	}
+	if err := daemon.containerStop(container, bootstrapStopTimeout); err != nil {
+		return err
+	}
+	if err := daemon.ContainerRm(id, &types.ContainerRmConfig{ForceRemove: true}); err != nil {
+		return err
+	}
+	return daemon.createBootstrapContainer(c)
+}
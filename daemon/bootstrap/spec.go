@@ -0,0 +1,138 @@
+// Package bootstrap defines the bootstrap specs of the daemon: the images,
+// networks, volumes and containers a daemon creates on the first boot of a
+// host, instead of scripts racing the startup of the daemon to create them
+// through the API.
+package bootstrap
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+
+	"github.com/docker/distribution/digest"
+	"github.com/docker/engine-api/types"
+	"github.com/docker/engine-api/types/container"
+	"github.com/docker/engine-api/types/network"
+)
+
+// HashLabel is the label of the containers created from a spec holding the
+// hash of their declaration, to find the containers whose declaration
+// changed since they were created.
+const HashLabel = "com.docker.bootstrap.hash"
+
+// Spec is a bootstrap spec. Its networks, volumes and containers are
+// described like the bodies of the API requests creating them.
+type Spec struct {
+	Images     []Image
+	Networks   []types.NetworkCreateRequest
+	Volumes    []types.VolumeCreateRequest
+	Containers []Container
+	// AuthConfigDir is the directory of a client configuration, like
+	// ~/.docker, holding the credentials the images are pulled with. The
+	// client configuration of the user running the daemon is used if it is
+	// not set.
+	AuthConfigDir string
+
+	// Digest is the digest of the file of the spec.
+	Digest digest.Digest `json:"-"`
+}
+
+// Image is an image to pull.
+type Image struct {
+	// Name is the name of the image, with its tag if any.
+	Name string
+	// Digest is the expected digest of the manifest of the image, if any.
+	// The image is then pulled by digest, and tagged with Name.
+	Digest digest.Digest
+}
+
+// Container is a container to create.
+type Container struct {
+	Name             string
+	Config           *container.Config
+	HostConfig       *container.HostConfig
+	NetworkingConfig *network.NetworkingConfig
+	// Start tells to start the container once it is created.
+	Start bool
+}
+
+// Hash returns the hash of the declaration of the container.
+func (c *Container) Hash() string {
+	// the declaration was decoded from JSON, so it can be encoded back
+	b, _ := json.Marshal(c)
+	return digest.FromBytes(b).Hex()
+}
+
+// Load reads the bootstrap spec of the JSON file at path.
+func Load(path string) (*Spec, error) {
+	b, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var spec Spec
+	if err := json.NewDecoder(bytes.NewReader(b)).Decode(&spec); err != nil {
+		return nil, fmt.Errorf("error reading bootstrap spec %s: %v", path, err)
+	}
+	if err := spec.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid bootstrap spec %s: %v", path, err)
+	}
+	spec.Digest = digest.FromBytes(b)
+	return &spec, nil
+}
+
+// Validate checks that the items of the spec are named, once each, and
+// that the digests of the images and the configurations of the containers
+// are valid.
+func (spec *Spec) Validate() error {
+	images := make(map[string]bool)
+	for _, img := range spec.Images {
+		if err := checkName("image", img.Name, images); err != nil {
+			return err
+		}
+		if img.Digest != "" {
+			if err := img.Digest.Validate(); err != nil {
+				return fmt.Errorf("invalid digest of image %s: %v", img.Name, err)
+			}
+		}
+	}
+
+	networks := make(map[string]bool)
+	for _, n := range spec.Networks {
+		if err := checkName("network", n.Name, networks); err != nil {
+			return err
+		}
+	}
+
+	volumes := make(map[string]bool)
+	for _, v := range spec.Volumes {
+		if err := checkName("volume", v.Name, volumes); err != nil {
+			return err
+		}
+	}
+
+	containers := make(map[string]bool)
+	for _, c := range spec.Containers {
+		if err := checkName("container", c.Name, containers); err != nil {
+			return err
+		}
+		if c.Config == nil || c.Config.Image == "" {
+			return fmt.Errorf("container %s has no image", c.Name)
+		}
+	}
+	return nil
+}
+
+// checkName checks that the item of the given kind has a name which is not
+// in names, and adds it.
+func checkName(kind, name string, names map[string]bool) error {
+	if name == "" {
+		return fmt.Errorf("%s without a name", kind)
+	}
+	if names[name] {
+		return fmt.Errorf("duplicate %s %s", kind, name)
+	}
+	names[name] = true
+	return nil
+}
@@ -0,0 +1,121 @@
+package bootstrap
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/docker/distribution/digest"
+)
+
+func writeSpec(t *testing.T, dir, content string) string {
+	path := filepath.Join(dir, "bootstrap.json")
+	if err := ioutil.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestLoad(t *testing.T) {
+	dir, err := ioutil.TempDir("", "bootstrap-")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	content := `{
+		"Images": [{"Name": "registry.example.com/app:1.0", "Digest": "sha256:5f70bf18a086007016e948b04aed3b82103a36bea41755b6cddfaf10ace3c6ef"}],
+		"Networks": [{"Name": "app", "Driver": "bridge", "Labels": {"site": "a"}}],
+		"Volumes": [{"Name": "data", "DriverOpts": {"o": "size=1G"}}],
+		"Containers": [{
+			"Name": "app",
+			"Config": {"Image": "registry.example.com/app:1.0", "Cmd": ["serve"]},
+			"HostConfig": {"NetworkMode": "app", "Binds": ["data:/data"], "RestartPolicy": {"Name": "always"}},
+			"Start": true
+		}]
+	}`
+	spec, err := Load(writeSpec(t, dir, content))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(spec.Images) != 1 || spec.Images[0].Digest.Algorithm() != digest.SHA256 {
+		t.Fatalf("unexpected images: %v", spec.Images)
+	}
+	if len(spec.Networks) != 1 || spec.Networks[0].Driver != "bridge" || spec.Networks[0].Labels["site"] != "a" {
+		t.Fatalf("unexpected networks: %v", spec.Networks)
+	}
+	if len(spec.Volumes) != 1 || spec.Volumes[0].DriverOpts["o"] != "size=1G" {
+		t.Fatalf("unexpected volumes: %v", spec.Volumes)
+	}
+	if len(spec.Containers) != 1 {
+		t.Fatalf("unexpected containers: %v", spec.Containers)
+	}
+	c := spec.Containers[0]
+	if !c.Start || c.Config.Image != "registry.example.com/app:1.0" || c.HostConfig == nil || c.HostConfig.RestartPolicy.Name != "always" {
+		t.Fatalf("unexpected container: %+v", c)
+	}
+	if spec.Digest != digest.FromBytes([]byte(content)) {
+		t.Fatalf("expected the digest of the spec file, got %s", spec.Digest)
+	}
+}
+
+func TestLoadInvalid(t *testing.T) {
+	dir, err := ioutil.TempDir("", "bootstrap-")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	for _, tc := range []struct {
+		content  string
+		expected string
+	}{
+		{`{"Images": [`, "error reading bootstrap spec"},
+		{`{"Images": [{"Digest": "sha256:5f70bf18a086007016e948b04aed3b82103a36bea41755b6cddfaf10ace3c6ef"}]}`, "image without a name"},
+		{`{"Images": [{"Name": "busybox", "Digest": "sha256:abc"}]}`, "invalid digest of image busybox"},
+		{`{"Networks": [{"Name": "app"}, {"Name": "app"}]}`, "duplicate network app"},
+		{`{"Volumes": [{"Driver": "local"}]}`, "volume without a name"},
+		{`{"Containers": [{"Name": "app", "Config": {"Cmd": ["serve"]}}]}`, "container app has no image"},
+		{`{"Containers": [{"Name": "app"}]}`, "container app has no image"},
+	} {
+		if _, err := Load(writeSpec(t, dir, tc.content)); err == nil || !strings.Contains(err.Error(), tc.expected) {
+			t.Fatalf("expected an error containing %q for %s, got %v", tc.expected, tc.content, err)
+		}
+	}
+
+	if _, err := Load(filepath.Join(dir, "missing.json")); !os.IsNotExist(err) {
+		t.Fatalf("expected a missing spec to fail with a not-exist error, got %v", err)
+	}
+}
+
+func TestContainerHash(t *testing.T) {
+	dir, err := ioutil.TempDir("", "bootstrap-")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	spec, err := Load(writeSpec(t, dir, `{"Containers": [
+		{"Name": "a", "Config": {"Image": "busybox", "Env": ["A=1"]}},
+		{"Name": "a2", "Config": {"Image": "busybox", "Env": ["A=1"]}},
+		{"Name": "b", "Config": {"Image": "busybox", "Env": ["A=2"]}}
+	]}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	c := spec.Containers
+	if c[0].Hash() == c[1].Hash() || c[0].Hash() == c[2].Hash() {
+		t.Fatal("expected different declarations to have different hashes")
+	}
+
+	again, err := Load(writeSpec(t, dir, `{"Containers": [{"Config": {"Env": ["A=1"], "Image": "busybox"}, "Name": "a"}]}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if again.Containers[0].Hash() != c[0].Hash() {
+		t.Fatal("expected the hash not to depend on the layout of the spec")
+	}
+}
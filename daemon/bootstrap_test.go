@@ -0,0 +1,172 @@
+package daemon
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/docker/docker/cliconfig"
+	"github.com/docker/docker/container"
+	"github.com/docker/docker/daemon/bootstrap"
+	"github.com/docker/docker/pkg/registrar"
+	"github.com/docker/docker/pkg/truncindex"
+	"github.com/docker/docker/reference"
+	"github.com/docker/docker/registry"
+	containertypes "github.com/docker/engine-api/types/container"
+)
+
+func TestBootstrapOnFirstBoot(t *testing.T) {
+	root, err := ioutil.TempDir("", "docker-bootstrap-")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(root)
+
+	specPath := filepath.Join(root, "spec.json")
+	if err := ioutil.WriteFile(specPath, []byte(`{"Images": []}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	daemon := &Daemon{root: root}
+	spec, err := daemon.loadBootstrapSpec(specPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if spec == nil {
+		t.Fatal("expected the spec to be applied on the first boot")
+	}
+
+	daemon.bootstrap(spec)
+	if _, err := os.Stat(filepath.Join(root, bootstrapStateFile)); err != nil {
+		t.Fatalf("expected the bootstrap state to be recorded: %v", err)
+	}
+
+	// the spec is not applied again, even if it changed
+	if err := ioutil.WriteFile(specPath, []byte(`{"Volumes": [{"Name": "data"}]}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if spec, err := daemon.loadBootstrapSpec(specPath); err != nil || spec != nil {
+		t.Fatalf("expected the spec not to be applied on the next boots, got %v, %v", spec, err)
+	}
+
+	// the spec is still checked
+	if err := ioutil.WriteFile(specPath, []byte(`{"Volumes": [{}]}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := daemon.loadBootstrapSpec(specPath); err == nil {
+		t.Fatal("expected an invalid spec to fail")
+	}
+}
+
+func TestBootstrapContainerDrift(t *testing.T) {
+	declared := bootstrap.Container{Name: "app", Config: &containertypes.Config{Image: "busybox", Cmd: []string{"serve"}}}
+	c := &container.Container{
+		CommonContainer: container.CommonContainer{
+			ID:     "5a4ff6a163ad4533d22d69a2b8960bf7fafdcba06e72d2febdba229008b0bf57",
+			Name:   "/app",
+			Config: &containertypes.Config{Image: "busybox", Labels: map[string]string{bootstrap.HashLabel: "0123"}},
+		},
+	}
+
+	store := container.NewMemoryStore()
+	store.Add(c.ID, c)
+	daemon := &Daemon{
+		containers:  store,
+		idIndex:     truncindex.NewTruncIndex([]string{c.ID}),
+		nameIndex:   registrar.NewRegistrar(),
+		configStore: &Config{},
+	}
+	daemon.reserveName(c.ID, c.Name)
+
+	if drift := daemon.containerDrift(bootstrap.Container{Name: "db", Config: declared.Config}); drift.Drift != driftMissing || drift.ID != "" {
+		t.Fatalf("expected an undeclared container to be missing, got %+v", drift)
+	}
+	if drift := daemon.containerDrift(declared); drift.Drift != driftConfig || drift.ID != c.ID {
+		t.Fatalf("expected a container with another declaration to drift in config, got %+v", drift)
+	}
+
+	if _, err := daemon.BootstrapDrift(); err == nil {
+		t.Fatal("expected the drift to fail without a bootstrap spec")
+	}
+}
+
+func TestAdoptBootstrapContainer(t *testing.T) {
+	root, err := ioutil.TempDir("", "docker-bootstrap-")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(root)
+
+	declared := bootstrap.Container{Name: "app", Config: &containertypes.Config{Image: "busybox", Cmd: []string{"serve"}}}
+	c := container.NewBaseContainer("5a4ff6a163ad4533d22d69a2b8960bf7fafdcba06e72d2febdba229008b0bf57", root)
+	c.Name = "/app"
+	c.Config = &containertypes.Config{Image: "busybox"}
+	c.HostConfig = &containertypes.HostConfig{}
+	store := container.NewMemoryStore()
+	store.Add(c.ID, c)
+	daemon := &Daemon{
+		containers:  store,
+		idIndex:     truncindex.NewTruncIndex([]string{c.ID}),
+		nameIndex:   registrar.NewRegistrar(),
+		configStore: &Config{},
+	}
+	daemon.reserveName(c.ID, c.Name)
+
+	// a container created before the reconciliation is adopted instead
+	// of drifting
+	if adopted, err := daemon.adoptBootstrapContainer(c, declared); err != nil || !adopted {
+		t.Fatalf("expected the container to be adopted, got %v, %v", adopted, err)
+	}
+	if c.Config.Labels[bootstrap.HashLabel] != declared.Hash() {
+		t.Fatalf("expected the hash of the declaration to be recorded, got %v", c.Config.Labels)
+	}
+	if _, err := os.Stat(filepath.Join(root, "config.v2.json")); err != nil {
+		t.Fatalf("expected the adopted container to be saved: %v", err)
+	}
+	if adopted, err := daemon.adoptBootstrapContainer(c, declared); err != nil || adopted {
+		t.Fatalf("expected the container to be adopted once, got %v, %v", adopted, err)
+	}
+
+	// the changes of the declaration made afterwards are drifts
+	declared.Config = &containertypes.Config{Image: "busybox", Cmd: []string{"serve", "--debug"}}
+	if drift := daemon.containerDrift(declared); drift.Drift != driftConfig {
+		t.Fatalf("expected the adopted container to drift in config, got %+v", drift)
+	}
+}
+
+func TestBootstrapAuthConfig(t *testing.T) {
+	dir, err := ioutil.TempDir("", "docker-bootstrap-auth-")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	config := `{"auths": {"registry.example.com": {"auth": "dXNlcjpwYXNz"}}}`
+	if err := ioutil.WriteFile(filepath.Join(dir, cliconfig.ConfigFileName), []byte(config), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	d := &Daemon{RegistryService: registry.NewService(registry.ServiceOptions{})}
+	ref, err := reference.ParseNamed("registry.example.com/app:1.0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	authConfig, err := d.bootstrapAuthConfig(dir, ref)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if authConfig.Username != "user" || authConfig.Password != "pass" {
+		t.Fatalf("expected the credentials of %s, got %+v", dir, authConfig)
+	}
+
+	// without a directory, the configuration of the daemon user is used
+	defer cliconfig.SetConfigDir(cliconfig.ConfigDir())
+	cliconfig.SetConfigDir(dir)
+	authConfig, err = d.bootstrapAuthConfig("", ref)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if authConfig.Username != "user" {
+		t.Fatalf("expected the credentials of the daemon user, got %+v", authConfig)
+	}
+}
@@ -0,0 +1,122 @@
+package daemon
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/docker/docker/errors"
+	"github.com/docker/docker/utils"
+	"github.com/docker/engine-api/types"
+)
+
+// CheckpointCreate checkpoints the process of a running container with
+// CRIU, so that it can be started again from the checkpoint, on this host or,
+// along with the container, on another one. The container keeps running
+// unless config.Exit is set.
+func (daemon *Daemon) CheckpointCreate(name string, config types.CheckpointCreateOptions) error {
+	container, err := daemon.GetContainer(name)
+	if err != nil {
+		return err
+	}
+
+	if !container.IsRunning() {
+		return errors.NewRequestConflictError(fmt.Errorf("Container %s not running", name))
+	}
+	if err := validateCheckpointName(config.CheckpointID); err != nil {
+		return err
+	}
+	if _, err := os.Stat(filepath.Join(container.CheckpointDir(), config.CheckpointID)); err == nil {
+		return errors.NewRequestConflictError(fmt.Errorf("Checkpoint %s of container %s already exists", config.CheckpointID, name))
+	}
+	if err := os.MkdirAll(container.CheckpointDir(), 0700); err != nil {
+		return err
+	}
+
+	if err := daemon.containerd.CreateCheckpoint(container.ID, config.CheckpointID, container.CheckpointDir(), config.Exit); err != nil {
+		return fmt.Errorf("Cannot checkpoint container %s: %s", name, err)
+	}
+
+	daemon.LogContainerEventWithAttributes(container, "checkpoint", map[string]string{"checkpoint": config.CheckpointID})
+	return nil
+}
+
+// CheckpointDelete deletes the checkpoint of a container.
+func (daemon *Daemon) CheckpointDelete(name string, checkpoint string) error {
+	container, err := daemon.GetContainer(name)
+	if err != nil {
+		return err
+	}
+	if err := checkpointExists(container.CheckpointDir(), name, checkpoint); err != nil {
+		return err
+	}
+	return os.RemoveAll(filepath.Join(container.CheckpointDir(), checkpoint))
+}
+
+// CheckpointList lists the checkpoints of a container.
+func (daemon *Daemon) CheckpointList(name string) ([]types.Checkpoint, error) {
+	container, err := daemon.GetContainer(name)
+	if err != nil {
+		return nil, err
+	}
+
+	out := []types.Checkpoint{}
+	dirs, err := readCheckpointDir(container.CheckpointDir())
+	if err != nil {
+		return nil, err
+	}
+	for _, d := range dirs {
+		out = append(out, types.Checkpoint{Name: d})
+	}
+	return out, nil
+}
+
+// readCheckpointDir returns the names of the checkpoints in dir, sorted by
+// name.
+func readCheckpointDir(dir string) ([]string, error) {
+	f, err := os.Open(dir)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	names, err := f.Readdirnames(-1)
+	if err != nil {
+		return nil, err
+	}
+	var checkpoints []string
+	for _, n := range names {
+		if fi, err := os.Stat(filepath.Join(dir, n)); err == nil && fi.IsDir() {
+			checkpoints = append(checkpoints, n)
+		}
+	}
+	sort.Strings(checkpoints)
+	return checkpoints, nil
+}
+
+// checkpointExists returns an error if the container has no checkpoint
+// with that name.
+func checkpointExists(dir, name, checkpoint string) error {
+	if err := validateCheckpointName(checkpoint); err != nil {
+		return err
+	}
+	fi, err := os.Stat(filepath.Join(dir, checkpoint))
+	if err == nil && fi.IsDir() {
+		return nil
+	}
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return errors.NewRequestNotFoundError(fmt.Errorf("No such checkpoint %s for container %s", checkpoint, name))
+}
+
+func validateCheckpointName(checkpoint string) error {
+	if !utils.RestrictedNamePattern.MatchString(checkpoint) {
+		return errors.NewBadRequestError(fmt.Errorf("Invalid checkpoint name (%s), only %s are allowed", checkpoint, utils.RestrictedNameChars))
+	}
+	return nil
+}
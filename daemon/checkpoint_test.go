@@ -0,0 +1,52 @@
+package daemon
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestReadCheckpointDir(t *testing.T) {
+	tmp, err := ioutil.TempDir("", "docker-checkpoints-")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(tmp)
+
+	dir := filepath.Join(tmp, "checkpoints")
+	checkpoints, err := readCheckpointDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(checkpoints) != 0 {
+		t.Fatalf("expected no checkpoints, got %v", checkpoints)
+	}
+
+	for _, name := range []string{"cp2", "cp1"} {
+		if err := os.MkdirAll(filepath.Join(dir, name), 0700); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := ioutil.WriteFile(filepath.Join(dir, "file"), nil, 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	checkpoints, err = readCheckpointDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expected := []string{"cp1", "cp2"}; !reflect.DeepEqual(checkpoints, expected) {
+		t.Fatalf("expected the checkpoints %v, got %v", expected, checkpoints)
+	}
+
+	if err := checkpointExists(dir, "web", "cp1"); err != nil {
+		t.Fatal(err)
+	}
+	for _, name := range []string{"cp3", "file", "../cp1", ""} {
+		if err := checkpointExists(dir, "web", name); err == nil {
+			t.Fatalf("expected an error for the checkpoint %q", name)
+		}
+	}
+}
@@ -6,14 +6,25 @@ import (
 	"fmt"
 	"io"
 	"io/ioutil"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
 	"strings"
 	"sync"
+	"time"
 
 	"github.com/Sirupsen/logrus"
+	"github.com/docker/docker/api"
+	"github.com/docker/docker/daemon/logger"
 	"github.com/docker/docker/opts"
 	"github.com/docker/docker/pkg/discovery"
 	flag "github.com/docker/docker/pkg/mflag"
 	"github.com/docker/docker/registry"
+	runconfigopts "github.com/docker/docker/runconfig/opts"
+	"github.com/docker/engine-api/types/versions"
+	"github.com/docker/go-units"
 	"github.com/imdario/mergo"
 )
 
@@ -26,6 +37,18 @@ const (
 	// maximum number of uploads that
 	// may take place at a time for each push.
 	defaultMaxConcurrentUploads = 5
+	// defaultAPIMaxBodySize is the default maximum size of the remote API
+	// request bodies.
+	defaultAPIMaxBodySize = 10 * 1024 * 1024
+	// defaultArchiveUploadsMaxSize is the default maximum size of all the
+	// archives uploaded in chunks.
+	defaultArchiveUploadsMaxSize = 10 * 1024 * 1024 * 1024
+	// defaultMaxArchiveUploads is the default maximum number of archive
+	// uploads in progress.
+	defaultMaxArchiveUploads = 16
+	// defaultShutdownTimeout is the default number of seconds the containers
+	// without a stop timeout have to exit when the daemon shuts down.
+	defaultShutdownTimeout = 15
 	// stockRuntimeName is the reserved name/alias used to represent the
 	// OCI runtime being shipped with the docker daemon package.
 	stockRuntimeName = "runc"
@@ -36,14 +59,31 @@ const (
 	disableNetworkBridge = "none"
 )
 
+const (
+	// configProfilesKey is the key of the configuration file holding its
+	// profiles, the named sets of options merged over it when selected.
+	configProfilesKey = "profiles"
+	// configProfileFlag is the flag selecting the configuration profile.
+	configProfileFlag = "config-profile"
+	// kernelCmdlineProfileKey is the key of the kernel command line
+	// selecting the configuration profile when the flag is not set.
+	kernelCmdlineProfileKey = "docker.config-profile"
+)
+
+// kernelCmdline is the file holding the kernel command line.
+var kernelCmdline = "/proc/cmdline"
+
 // flatOptions contains configuration keys
 // that MUST NOT be parsed as deep structures.
 // Use this to differentiate these options
 // with others like the ones in CommonTLSOptions.
 var flatOptions = map[string]bool{
-	"cluster-store-opts": true,
-	"log-opts":           true,
-	"runtimes":           true,
+	"cluster-store-opts":     true,
+	"log-driver-opts":        true,
+	"log-opts":               true,
+	"registry-certs-dirs":    true,
+	"registry-trust-servers": true,
+	"runtimes":               true,
 }
 
 // LogConfig represents the default log configuration.
@@ -52,6 +92,49 @@ var flatOptions = map[string]bool{
 type LogConfig struct {
 	Type   string            `json:"log-driver,omitempty"`
 	Config map[string]string `json:"log-opts,omitempty"`
+	// DriverConfigs holds the default options of each log driver, which
+	// apply to the containers using the driver unless they override them.
+	DriverConfigs map[string]map[string]string `json:"log-driver-opts,omitempty"`
+	// Redact holds the "name=regexp" rules masking sensitive data in
+	// container logs before they reach the log driver.
+	Redact []string `json:"log-redact,omitempty"`
+	// MaxAge, MaxSize and MaxFiles set the retention policy of container
+	// logs: the maximum age of the entries kept, as a duration, and the
+	// maximum size and number of files of the logs kept for each container.
+	// The log quotas of containers may not exceed them.
+	MaxAge   string `json:"log-max-age,omitempty"`
+	MaxSize  string `json:"log-max-size,omitempty"`
+	MaxFiles int    `json:"log-max-files,omitempty"`
+}
+
+// retention parses the log retention policy.
+func (config *LogConfig) retention() (logger.Retention, error) {
+	var r logger.Retention
+	if config.MaxAge != "" {
+		maxAge, err := time.ParseDuration(config.MaxAge)
+		if err != nil {
+			return r, fmt.Errorf("invalid log-max-age %q: %v", config.MaxAge, err)
+		}
+		if maxAge < 0 {
+			return r, fmt.Errorf("invalid log-max-age %q: must not be negative", config.MaxAge)
+		}
+		r.MaxAge = maxAge
+	}
+	if config.MaxSize != "" {
+		maxSize, err := units.RAMInBytes(config.MaxSize)
+		if err != nil {
+			return r, fmt.Errorf("invalid log-max-size %q: %v", config.MaxSize, err)
+		}
+		if maxSize < 0 {
+			return r, fmt.Errorf("invalid log-max-size %q: must not be negative", config.MaxSize)
+		}
+		r.MaxSize = maxSize
+	}
+	if config.MaxFiles < 0 {
+		return r, fmt.Errorf("invalid log-max-files %d: must not be negative", config.MaxFiles)
+	}
+	r.MaxFiles = config.MaxFiles
+	return r, nil
 }
 
 // commonBridgeConfig stores all the platform-common bridge driver specific
@@ -96,6 +179,112 @@ type CommonConfig struct {
 	EnableCors           bool                `json:"api-enable-cors,omitempty"`
 	LiveRestore          bool                `json:"live-restore,omitempty"`
 
+	// PinnedReferences is the path to the file holding the digests image
+	// tags are pinned to. Defaults to a file in the image store.
+	PinnedReferences string `json:"pinned-references,omitempty"`
+
+	// ConfigProfile is the name of the profile of the configuration file
+	// merged over it. It can only be set with the command line flag.
+	ConfigProfile string `json:"-"`
+
+	// ShortNameAliases is the path to the file of aliases resolving short
+	// image names to names with a registry hostname.
+	ShortNameAliases string `json:"short-name-aliases,omitempty"`
+
+	// BootstrapSpec is the path to the bootstrap spec of the images,
+	// networks, volumes and containers to create on the first boot.
+	BootstrapSpec string `json:"bootstrap-spec,omitempty"`
+
+	// BootstrapReconcileInterval is how often the containers declared by
+	// the bootstrap spec are reconciled with their declaration, as a
+	// duration. Empty or "0" disables the reconciliation.
+	BootstrapReconcileInterval string `json:"bootstrap-reconcile-interval,omitempty"`
+
+	// RegistryCacheAddr is the 'host:port' address on which the images of
+	// the daemon are served through a read-only registry API, for other
+	// daemons to use as a registry mirror. The API is served over TLS with
+	// the certificate and key of RegistryCacheTLSCert and
+	// RegistryCacheTLSKey, to the clients with a certificate signed by the
+	// CA of RegistryCacheTLSCACert.
+	RegistryCacheAddr      string `json:"registry-cache-addr,omitempty"`
+	RegistryCacheTLSCACert string `json:"registry-cache-tlscacert,omitempty"`
+	RegistryCacheTLSCert   string `json:"registry-cache-tlscert,omitempty"`
+	RegistryCacheTLSKey    string `json:"registry-cache-tlskey,omitempty"`
+	// RegistryCachePull makes the registry cache pull the tags requested
+	// but missing from the daemon.
+	RegistryCachePull bool `json:"registry-cache-pull,omitempty"`
+
+	// APIMinVersion is the lowest API version served, for operators to
+	// refuse older clients. Defaults to the lowest supported version.
+	APIMinVersion string `json:"api-min-version,omitempty"`
+
+	// APIDeprecatedVersion is the API version below which the responses
+	// announce the API versions as deprecated.
+	APIDeprecatedVersion string `json:"api-deprecated-version,omitempty"`
+
+	// APISunsetDate is the date, in the format YYYY-MM-DD, announced to the
+	// clients of deprecated API versions as the date the versions stop
+	// being served.
+	APISunsetDate string `json:"api-sunset-date,omitempty"`
+
+	// APIMaxBodySize is the maximum size of the remote API request bodies,
+	// except for the requests sending streams like build contexts.
+	APIMaxBodySize string `json:"api-max-body-size,omitempty"`
+
+	// APIBodySizeLimits holds "path=size" limits of the request bodies of
+	// some remote API endpoints, overriding APIMaxBodySize.
+	APIBodySizeLimits []string `json:"api-body-size-limits,omitempty"`
+
+	// APIAudit makes the daemon log the remote API requests changing its
+	// state to the journal, with the identity of their caller.
+	APIAudit bool `json:"api-audit,omitempty"`
+
+	// RemoteInspectTTL is how long the results of remote image inspects
+	// are cached, as a duration. Defaults to a minute, "0" disables the
+	// cache.
+	RemoteInspectTTL string `json:"remote-inspect-ttl,omitempty"`
+
+	// MinIDPrefixLength is the minimum length of the ID prefixes
+	// referring to containers, images and networks. Full IDs and names
+	// are always accepted.
+	MinIDPrefixLength int `json:"min-id-prefix-length,omitempty"`
+
+	// EventsRetention is the number of events kept in the root directory,
+	// which are replayed to the events requests after the daemon restarts.
+	// Events are only kept in memory when it is 0.
+	EventsRetention int `json:"events-retention,omitempty"`
+
+	// ShutdownTimeout is the number of seconds the containers without a
+	// stop timeout of their own have to exit when the daemon shuts down,
+	// before being killed. A negative timeout waits for them to exit.
+	ShutdownTimeout int `json:"shutdown-timeout,omitempty"`
+
+	// MinFreeSpace is the free space to keep on the filesystem of the root
+	// directory when pulling and building images, as a size like "2GB" or
+	// as a percentage of the filesystem like "10%". Empty disables the
+	// check.
+	MinFreeSpace string `json:"min-free-space,omitempty"`
+
+	// ArchiveUploadMaxSize is the maximum size of each archive uploaded in
+	// chunks, and ArchiveUploadsMaxSize the maximum size of all of them, as
+	// sizes like "2GB". MaxArchiveUploads is the maximum number of archive
+	// uploads in progress.
+	ArchiveUploadMaxSize  string `json:"archive-upload-max-size,omitempty"`
+	ArchiveUploadsMaxSize string `json:"archive-uploads-max-size,omitempty"`
+	MaxArchiveUploads     int    `json:"max-archive-uploads,omitempty"`
+
+	// AllowedImages and BlockedImages hold the patterns of the repository
+	// names of the images containers may be created and started from. If
+	// AllowedImages is set, an image must have a reference matching one of
+	// its patterns, and no reference matching a pattern of BlockedImages.
+	AllowedImages []string `json:"allowed-images,omitempty"`
+	BlockedImages []string `json:"blocked-images,omitempty"`
+
+	// SharedLayerStore is the root directory, read-only, of a daemon with
+	// the same storage driver whose layers are used besides the layers of
+	// the daemon, like a directory on NFS shared by several hosts.
+	SharedLayerStore string `json:"shared-layer-store,omitempty"`
+
 	// ClusterStore is the storage backend used for the cluster information. It is used by both
 	// multihost networking (to store networks and endpoints information) and by the node discovery
 	// mechanism.
@@ -151,6 +340,27 @@ func (config *Config) InstallCommonFlags(cmd *flag.FlagSet, usageFn func(string)
 	cmd.StringVar(&config.Root, []string{"g", "-graph"}, defaultGraph, usageFn("Root of the Docker runtime"))
 	cmd.BoolVar(&config.AutoRestart, []string{"#r", "#-restart"}, true, usageFn("--restart on the daemon has been deprecated in favor of --restart policies on docker run"))
 	cmd.StringVar(&config.GraphDriver, []string{"s", "-storage-driver"}, "", usageFn("Storage driver to use"))
+	cmd.StringVar(&config.SharedLayerStore, []string{"-shared-layer-store"}, "", usageFn("Root directory of a read-only layer store shared by several daemons"))
+	cmd.StringVar(&config.PinnedReferences, []string{"-pinned-references"}, "", usageFn("Path to the file of image tags pinned to a digest"))
+	cmd.StringVar(&config.ConfigProfile, []string{"-" + configProfileFlag}, "", usageFn("Profile of the daemon configuration file to use"))
+	cmd.StringVar(&config.ShortNameAliases, []string{"-short-name-aliases"}, "", usageFn("Path to the file of aliases for short image names"))
+	cmd.StringVar(&config.BootstrapSpec, []string{"-bootstrap-spec"}, "", usageFn("Path to the spec of the images, networks, volumes and containers to create on first boot"))
+	cmd.StringVar(&config.BootstrapReconcileInterval, []string{"-bootstrap-reconcile-interval"}, "", usageFn("How often to reconcile the containers with the bootstrap spec"))
+	cmd.StringVar(&config.RegistryCacheAddr, []string{"-registry-cache-addr"}, "", usageFn("Address to serve images on as a registry mirror"))
+	cmd.StringVar(&config.RegistryCacheTLSCACert, []string{"-registry-cache-tlscacert"}, "", usageFn("Trust only registry cache clients with certificates signed by this CA"))
+	cmd.StringVar(&config.RegistryCacheTLSCert, []string{"-registry-cache-tlscert"}, "", usageFn("Path to the TLS certificate of the registry cache"))
+	cmd.StringVar(&config.RegistryCacheTLSKey, []string{"-registry-cache-tlskey"}, "", usageFn("Path to the TLS key of the registry cache"))
+	cmd.BoolVar(&config.RegistryCachePull, []string{"-registry-cache-pull"}, false, usageFn("Pull the images requested but missing from the registry cache"))
+	cmd.StringVar(&config.RemoteInspectTTL, []string{"-remote-inspect-ttl"}, "", usageFn("How long to cache the results of remote image inspects"))
+	cmd.IntVar(&config.MinIDPrefixLength, []string{"-min-id-prefix-length"}, 0, usageFn("Minimum length of the ID prefixes of containers, images and networks"))
+	cmd.IntVar(&config.EventsRetention, []string{"-events-retention"}, 0, usageFn("Number of events to keep on disk and replay after a restart"))
+	cmd.IntVar(&config.ShutdownTimeout, []string{"-shutdown-timeout"}, defaultShutdownTimeout, usageFn("Seconds the containers have to stop when the daemon shuts down"))
+	cmd.StringVar(&config.MinFreeSpace, []string{"-min-free-space"}, "", usageFn("Free space to keep on the filesystem of the root directory when pulling and building, as a size or a percentage"))
+	cmd.StringVar(&config.ArchiveUploadMaxSize, []string{"-archive-upload-max-size"}, "", usageFn("Maximum size of each archive uploaded to a container in chunks"))
+	cmd.StringVar(&config.ArchiveUploadsMaxSize, []string{"-archive-uploads-max-size"}, "", usageFn("Maximum size of all the archives uploaded to containers in chunks"))
+	cmd.IntVar(&config.MaxArchiveUploads, []string{"-max-archive-uploads"}, defaultMaxArchiveUploads, usageFn("Maximum number of archive uploads to containers in progress"))
+	cmd.Var(opts.NewNamedListOptsRef("allowed-images", &config.AllowedImages, validateImagePattern), []string{"-allow-image"}, usageFn("Only run the images whose repository name matches a pattern"))
+	cmd.Var(opts.NewNamedListOptsRef("blocked-images", &config.BlockedImages, validateImagePattern), []string{"-block-image"}, usageFn("Do not run the images whose repository name matches a pattern"))
 	cmd.IntVar(&config.Mtu, []string{"#mtu", "-mtu"}, 0, usageFn("Set the containers network MTU"))
 	cmd.BoolVar(&config.RawLogs, []string{"-raw-logs"}, false, usageFn("Full timestamps without ANSI coloring"))
 	// FIXME: why the inconsistency between "hosts" and "sockets"?
@@ -160,10 +370,21 @@ func (config *Config) InstallCommonFlags(cmd *flag.FlagSet, usageFn func(string)
 	cmd.Var(opts.NewNamedListOptsRef("labels", &config.Labels, opts.ValidateLabel), []string{"-label"}, usageFn("Set key=value labels to the daemon"))
 	cmd.StringVar(&config.LogConfig.Type, []string{"-log-driver"}, "json-file", usageFn("Default driver for container logs"))
 	cmd.Var(opts.NewNamedMapOpts("log-opts", config.LogConfig.Config, nil), []string{"-log-opt"}, usageFn("Set log driver options"))
+	cmd.Var(runconfigopts.NewNamedLogDriverOpts("log-driver-opts", &config.LogConfig.DriverConfigs), []string{"-log-driver-opt"}, usageFn("Set the default options of a log driver (driver:key=value)"))
+	cmd.StringVar(&config.LogConfig.MaxAge, []string{"-log-max-age"}, "", usageFn("Maximum age of the log entries kept for each container"))
+	cmd.StringVar(&config.LogConfig.MaxSize, []string{"-log-max-size"}, "", usageFn("Maximum size of the logs kept for each container"))
+	cmd.IntVar(&config.LogConfig.MaxFiles, []string{"-log-max-files"}, 0, usageFn("Maximum number of log files kept for each container"))
+	cmd.Var(opts.NewNamedListOptsRef("log-redact", &config.LogConfig.Redact, logger.ValidateRedactRule), []string{"-log-redact"}, usageFn("Redact container log text matching a name=regexp rule"))
 	cmd.StringVar(&config.ClusterAdvertise, []string{"-cluster-advertise"}, "", usageFn("Address or interface name to advertise"))
 	cmd.StringVar(&config.ClusterStore, []string{"-cluster-store"}, "", usageFn("Set the cluster store"))
 	cmd.Var(opts.NewNamedMapOpts("cluster-store-opts", config.ClusterOpts, nil), []string{"-cluster-store-opt"}, usageFn("Set cluster store options"))
 	cmd.StringVar(&config.CorsHeaders, []string{"-api-cors-header"}, "", usageFn("Set CORS headers in the remote API"))
+	cmd.StringVar(&config.APIMinVersion, []string{"-api-min-version"}, "", usageFn("Refuse remote API versions lower than this version"))
+	cmd.StringVar(&config.APIDeprecatedVersion, []string{"-api-deprecated-version"}, "", usageFn("Mark remote API versions lower than this version as deprecated"))
+	cmd.StringVar(&config.APISunsetDate, []string{"-api-sunset-date"}, "", usageFn("Announce the date deprecated remote API versions stop being served"))
+	cmd.StringVar(&config.APIMaxBodySize, []string{"-api-max-body-size"}, "", usageFn("Maximum size of the remote API request bodies"))
+	cmd.BoolVar(&config.APIAudit, []string{"-api-audit"}, false, usageFn("Log the remote API requests changing the state of the daemon to the journal"))
+	cmd.Var(opts.NewNamedListOptsRef("api-body-size-limits", &config.APIBodySizeLimits, validateAPIBodySizeLimit), []string{"-api-body-size-limit"}, usageFn("Set the maximum request body size of a remote API endpoint with a path=size limit"))
 	cmd.IntVar(&maxConcurrentDownloads, []string{"-max-concurrent-downloads"}, defaultMaxConcurrentDownloads, usageFn("Set the max concurrent downloads for each pull"))
 	cmd.IntVar(&maxConcurrentUploads, []string{"-max-concurrent-uploads"}, defaultMaxConcurrentUploads, usageFn("Set the max concurrent uploads for each push"))
 
@@ -250,7 +471,7 @@ func MergeDaemonConfigurations(flagsConfig *Config, flags *flag.FlagSet, configF
 // It compares that configuration with the one provided by the flags,
 // and returns an error if there are conflicts.
 func getConflictFreeConfiguration(configFile string, flags *flag.FlagSet) (*Config, error) {
-	b, err := ioutil.ReadFile(configFile)
+	b, err := readConfigurationFiles(configFile, selectedConfigProfile(flags))
 	if err != nil {
 		return nil, err
 	}
@@ -308,6 +529,124 @@ func getConflictFreeConfiguration(configFile string, flags *flag.FlagSet) (*Conf
 	return &config, err
 }
 
+// configDropInDir returns the drop-in directory of configFile, whose files
+// are merged over it, like /etc/docker/daemon.d for /etc/docker/daemon.json.
+func configDropInDir(configFile string) string {
+	return strings.TrimSuffix(configFile, filepath.Ext(configFile)) + ".d"
+}
+
+// readConfigurationFiles returns the JSON configuration of configFile, with
+// the configuration of the *.json files of its drop-in directory merged over
+// it in the lexical order of their names. The objects of the files are
+// merged key by key, and the other values of a file replace the values of
+// the files before it. configFile may be missing if there are drop-in files.
+//
+// The profiles of the configuration are removed from it, and the options of
+// the given profile, if any, are merged over it the same way.
+func readConfigurationFiles(configFile, profile string) ([]byte, error) {
+	dropIns, err := filepath.Glob(filepath.Join(configDropInDir(configFile), "*.json"))
+	if err != nil {
+		return nil, err
+	}
+	b, err := ioutil.ReadFile(configFile)
+	if err != nil && (!os.IsNotExist(err) || len(dropIns) == 0) {
+		return nil, err
+	}
+
+	config := make(map[string]interface{})
+	if len(b) > 0 {
+		if err := decodeConfigurationObject(b, &config); err != nil {
+			return nil, fmt.Errorf("error reading %s: %v", configFile, err)
+		}
+	}
+	sort.Strings(dropIns)
+	for _, dropIn := range dropIns {
+		b, err := ioutil.ReadFile(dropIn)
+		if err != nil {
+			return nil, err
+		}
+		var dropInConfig map[string]interface{}
+		if err := decodeConfigurationObject(b, &dropInConfig); err != nil {
+			return nil, fmt.Errorf("error reading %s: %v", dropIn, err)
+		}
+		logrus.Debugf("Merging daemon configuration from %s", dropIn)
+		mergeConfigurationObjects(config, dropInConfig)
+	}
+	if err := applyConfigurationProfile(config, profile); err != nil {
+		return nil, err
+	}
+	return json.Marshal(config)
+}
+
+// applyConfigurationProfile removes the profiles from config, and merges
+// the options of the given profile over it.
+func applyConfigurationProfile(config map[string]interface{}, profile string) error {
+	if _, ok := config[configProfileFlag]; ok {
+		return fmt.Errorf("the %s option can only be set with the --%s flag or the %s kernel command line key", configProfileFlag, configProfileFlag, kernelCmdlineProfileKey)
+	}
+
+	profiles, ok := config[configProfilesKey].(map[string]interface{})
+	if _, isSet := config[configProfilesKey]; isSet && !ok {
+		return fmt.Errorf("%s must be an object of configuration profiles", configProfilesKey)
+	}
+	delete(config, configProfilesKey)
+	if profile == "" {
+		return nil
+	}
+
+	profileConfig, ok := profiles[profile].(map[string]interface{})
+	if !ok {
+		return fmt.Errorf("unknown configuration profile %s", profile)
+	}
+	if _, ok := profileConfig[configProfilesKey]; ok {
+		return fmt.Errorf("configuration profile %s cannot define %s", profile, configProfilesKey)
+	}
+	logrus.Infof("Using daemon configuration profile %s", profile)
+	mergeConfigurationObjects(config, profileConfig)
+	return nil
+}
+
+// selectedConfigProfile returns the configuration profile selected with
+// the --config-profile flag, or else with the docker.config-profile key of
+// the kernel command line.
+func selectedConfigProfile(flags *flag.FlagSet) string {
+	if flags != nil && flags.IsSet("-"+configProfileFlag) {
+		return flags.Lookup("-" + configProfileFlag).Value.String()
+	}
+
+	b, err := ioutil.ReadFile(kernelCmdline)
+	if err != nil {
+		return ""
+	}
+	for _, param := range strings.Fields(string(b)) {
+		if strings.HasPrefix(param, kernelCmdlineProfileKey+"=") {
+			return strings.TrimPrefix(param, kernelCmdlineProfileKey+"=")
+		}
+	}
+	return ""
+}
+
+// decodeConfigurationObject decodes the JSON object of a configuration
+// file, keeping its numbers as they are written.
+func decodeConfigurationObject(b []byte, config *map[string]interface{}) error {
+	dec := json.NewDecoder(bytes.NewReader(b))
+	dec.UseNumber()
+	return dec.Decode(config)
+}
+
+// mergeConfigurationObjects merges the values of src over the ones of dst.
+func mergeConfigurationObjects(dst, src map[string]interface{}) {
+	for k, v := range src {
+		srcObject, srcIsObject := v.(map[string]interface{})
+		dstObject, dstIsObject := dst[k].(map[string]interface{})
+		if srcIsObject && dstIsObject {
+			mergeConfigurationObjects(dstObject, srcObject)
+			continue
+		}
+		dst[k] = v
+	}
+}
+
 // configValuesSet returns the configuration values explicitly set in the file.
 func configValuesSet(config map[string]interface{}) map[string]interface{} {
 	flatten := make(map[string]interface{})
@@ -395,6 +734,13 @@ func findConfigurationConflicts(config map[string]interface{}, flags *flag.FlagS
 // such as config.DNS, config.Labels, config.DNSSearch,
 // as well as config.MaxConcurrentDownloads, config.MaxConcurrentUploads.
 func ValidateConfiguration(config *Config) error {
+	// validate the log level
+	if config.LogLevel != "" {
+		if _, err := logrus.ParseLevel(config.LogLevel); err != nil {
+			return fmt.Errorf("invalid log level %s", config.LogLevel)
+		}
+	}
+
 	// validate DNS
 	for _, dns := range config.DNS {
 		if _, err := opts.ValidateIPAddress(dns); err != nil {
@@ -416,6 +762,84 @@ func ValidateConfiguration(config *Config) error {
 		}
 	}
 
+	// validate the log retention policy
+	if _, err := config.LogConfig.retention(); err != nil {
+		return err
+	}
+
+	// validate the API version policy
+	if _, err := config.APISunset(); err != nil {
+		return err
+	}
+
+	// validate the API body size limits
+	if _, _, err := config.APIRequestBodyLimits(); err != nil {
+		return err
+	}
+
+	// validate the registry certificates directories
+	for host, dir := range config.CertsDirs {
+		if _, err := registry.ValidateCertsDir(host + "=" + dir); err != nil {
+			return err
+		}
+	}
+
+	// validate the registry trust servers
+	for host, server := range config.TrustServers {
+		if _, err := registry.ValidateTrustServer(host + "=" + server); err != nil {
+			return err
+		}
+	}
+
+	// validate the remote inspect cache TTL
+	if _, err := config.remoteInspectTTL(); err != nil {
+		return err
+	}
+
+	// validate the minimum ID prefix length
+	if config.MinIDPrefixLength < 0 || config.MinIDPrefixLength > 64 {
+		return fmt.Errorf("invalid min-id-prefix-length %d: must be between 0 and 64", config.MinIDPrefixLength)
+	}
+
+	// validate the number of events to keep
+	if config.EventsRetention < 0 {
+		return fmt.Errorf("invalid events-retention %d: must not be negative", config.EventsRetention)
+	}
+
+	// validate the free space to keep
+	if _, err := config.minFreeSpace(); err != nil {
+		return err
+	}
+
+	// validate the archive upload limits
+	if _, err := config.archiveUploadLimits(); err != nil {
+		return err
+	}
+
+	// validate the image policy
+	for _, pattern := range append(config.AllowedImages, config.BlockedImages...) {
+		if _, err := validateImagePattern(pattern); err != nil {
+			return err
+		}
+	}
+
+	// validate the shared layer store
+	if config.SharedLayerStore != "" {
+		if !filepath.IsAbs(config.SharedLayerStore) {
+			return fmt.Errorf("invalid shared-layer-store %s: must be an absolute path", config.SharedLayerStore)
+		}
+		if config.Root != "" && filepath.Clean(config.SharedLayerStore) == filepath.Clean(config.Root) {
+			return fmt.Errorf("invalid shared-layer-store %s: must not be the root directory of the daemon", config.SharedLayerStore)
+		}
+	}
+
+	// validate the bootstrap reconciliation interval
+	if interval, err := config.bootstrapReconcileInterval(); err != nil {
+		return err
+	} else if interval > 0 && config.BootstrapSpec == "" {
+		return fmt.Errorf("bootstrap-reconcile-interval requires a bootstrap-spec")
+	}
+
 	// validate MaxConcurrentDownloads
 	if config.IsValueSet("max-concurrent-downloads") && config.MaxConcurrentDownloads != nil && *config.MaxConcurrentDownloads < 0 {
 		return fmt.Errorf("invalid max concurrent downloads: %d", *config.MaxConcurrentDownloads)
@@ -442,3 +866,178 @@ func ValidateConfiguration(config *Config) error {
 
 	return nil
 }
+
+// remoteInspectTTL returns how long the results of remote image inspects
+// are cached.
+func (config *Config) remoteInspectTTL() (time.Duration, error) {
+	if config.RemoteInspectTTL == "" {
+		return defaultRemoteInspectTTL, nil
+	}
+	ttl, err := time.ParseDuration(config.RemoteInspectTTL)
+	if err != nil {
+		return 0, fmt.Errorf("invalid remote-inspect-ttl %q: %v", config.RemoteInspectTTL, err)
+	}
+	if ttl < 0 {
+		return 0, fmt.Errorf("invalid remote-inspect-ttl %q: must not be negative", config.RemoteInspectTTL)
+	}
+	return ttl, nil
+}
+
+// minFreeSpace returns the free space to keep on the filesystem of the root
+// directory, nil if there is none.
+func (config *Config) minFreeSpace() (*freeSpaceThreshold, error) {
+	if config.MinFreeSpace == "" {
+		return nil, nil
+	}
+	if strings.HasSuffix(config.MinFreeSpace, "%") {
+		percent, err := strconv.ParseFloat(strings.TrimSuffix(config.MinFreeSpace, "%"), 64)
+		if err != nil || percent <= 0 || percent >= 100 {
+			return nil, fmt.Errorf("invalid min-free-space %q: must be a size or a percentage between 0 and 100", config.MinFreeSpace)
+		}
+		return &freeSpaceThreshold{percent: percent}, nil
+	}
+	size, err := units.RAMInBytes(config.MinFreeSpace)
+	if err != nil || size <= 0 {
+		return nil, fmt.Errorf("invalid min-free-space %q: must be a size or a percentage between 0 and 100", config.MinFreeSpace)
+	}
+	return &freeSpaceThreshold{size: size}, nil
+}
+
+// archiveUploadLimits returns the limits of the archives uploaded to
+// containers in chunks.
+func (config *Config) archiveUploadLimits() (archiveUploadLimits, error) {
+	limits := archiveUploadLimits{
+		maxTotalSize: defaultArchiveUploadsMaxSize,
+		maxUploads:   config.MaxArchiveUploads,
+	}
+	if config.ArchiveUploadsMaxSize != "" {
+		size, err := units.RAMInBytes(config.ArchiveUploadsMaxSize)
+		if err != nil || size <= 0 {
+			return limits, fmt.Errorf("invalid archive-uploads-max-size %q: must be a positive size", config.ArchiveUploadsMaxSize)
+		}
+		limits.maxTotalSize = size
+	}
+	limits.maxSize = limits.maxTotalSize
+	if config.ArchiveUploadMaxSize != "" {
+		size, err := units.RAMInBytes(config.ArchiveUploadMaxSize)
+		if err != nil || size <= 0 {
+			return limits, fmt.Errorf("invalid archive-upload-max-size %q: must be a positive size", config.ArchiveUploadMaxSize)
+		}
+		if size > limits.maxTotalSize {
+			return limits, fmt.Errorf("invalid archive-upload-max-size %q: must not be larger than archive-uploads-max-size", config.ArchiveUploadMaxSize)
+		}
+		limits.maxSize = size
+	}
+	if limits.maxUploads == 0 {
+		limits.maxUploads = defaultMaxArchiveUploads
+	}
+	if limits.maxUploads < 0 {
+		return limits, fmt.Errorf("invalid max-archive-uploads %d: must not be negative", config.MaxArchiveUploads)
+	}
+	return limits, nil
+}
+
+// bootstrapReconcileInterval returns how often the containers declared by
+// the bootstrap spec are reconciled, 0 if they are not.
+func (config *Config) bootstrapReconcileInterval() (time.Duration, error) {
+	if config.BootstrapReconcileInterval == "" {
+		return 0, nil
+	}
+	interval, err := time.ParseDuration(config.BootstrapReconcileInterval)
+	if err != nil {
+		return 0, fmt.Errorf("invalid bootstrap-reconcile-interval %q: %v", config.BootstrapReconcileInterval, err)
+	}
+	if interval < 0 {
+		return 0, fmt.Errorf("invalid bootstrap-reconcile-interval %q: must not be negative", config.BootstrapReconcileInterval)
+	}
+	return interval, nil
+}
+
+// APIRequestBodyLimits returns the maximum size of the remote API request
+// bodies, and the maximum sizes set for some endpoints by path. A size of
+// 0 means no limit.
+func (config *Config) APIRequestBodyLimits() (int64, map[string]int64, error) {
+	maxBodySize := int64(defaultAPIMaxBodySize)
+	if config.APIMaxBodySize != "" {
+		size, err := units.RAMInBytes(config.APIMaxBodySize)
+		if err != nil {
+			return 0, nil, fmt.Errorf("invalid api-max-body-size %q: %v", config.APIMaxBodySize, err)
+		}
+		if size < 0 {
+			return 0, nil, fmt.Errorf("invalid api-max-body-size %q: must not be negative", config.APIMaxBodySize)
+		}
+		maxBodySize = size
+	}
+
+	limits := make(map[string]int64)
+	for _, val := range config.APIBodySizeLimits {
+		path, size, err := parseAPIBodySizeLimit(val)
+		if err != nil {
+			return 0, nil, err
+		}
+		limits[path] = size
+	}
+	return maxBodySize, limits, nil
+}
+
+// validateAPIBodySizeLimit validates a "path=size" limit of the request
+// bodies of a remote API endpoint.
+func validateAPIBodySizeLimit(val string) (string, error) {
+	if _, _, err := parseAPIBodySizeLimit(val); err != nil {
+		return "", err
+	}
+	return val, nil
+}
+
+func parseAPIBodySizeLimit(val string) (string, int64, error) {
+	parts := strings.SplitN(val, "=", 2)
+	if len(parts) != 2 || !strings.HasPrefix(parts[0], "/") {
+		return "", 0, fmt.Errorf("invalid api-body-size-limit %q: expected path=size, like /containers/create=1MB", val)
+	}
+	size, err := units.RAMInBytes(parts[1])
+	if err != nil {
+		return "", 0, fmt.Errorf("invalid api-body-size-limit %q: %v", val, err)
+	}
+	if size < 0 {
+		return "", 0, fmt.Errorf("invalid api-body-size-limit %q: size must not be negative", val)
+	}
+	return parts[0], size, nil
+}
+
+// apiVersionRegexp matches the API versions accepted by the API version
+// policy options.
+var apiVersionRegexp = regexp.MustCompile(`^[0-9]+\.[0-9]+$`)
+
+// APISunset validates the API version policy of config and returns the
+// sunset date of the deprecated API versions, which is zero if no date is
+// set.
+func (config *Config) APISunset() (time.Time, error) {
+	for _, v := range []struct {
+		flag, version string
+	}{
+		{"api-min-version", config.APIMinVersion},
+		{"api-deprecated-version", config.APIDeprecatedVersion},
+	} {
+		if v.version == "" {
+			continue
+		}
+		if !apiVersionRegexp.MatchString(v.version) {
+			return time.Time{}, fmt.Errorf("invalid %s %q: must be an API version like %s", v.flag, v.version, api.DefaultVersion)
+		}
+		if versions.LessThan(v.version, api.MinVersion) || versions.GreaterThan(v.version, api.DefaultVersion) {
+			return time.Time{}, fmt.Errorf("invalid %s %s: must be between %s and %s", v.flag, v.version, api.MinVersion, api.DefaultVersion)
+		}
+	}
+
+	if config.APISunsetDate == "" {
+		return time.Time{}, nil
+	}
+	if config.APIDeprecatedVersion == "" {
+		return time.Time{}, fmt.Errorf("api-sunset-date requires api-deprecated-version")
+	}
+	sunset, err := time.Parse("2006-01-02", config.APISunsetDate)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid api-sunset-date %q: must be a date in the format YYYY-MM-DD", config.APISunsetDate)
+	}
+	return sunset, nil
+}
@@ -3,6 +3,8 @@ package daemon
 import (
 	"io/ioutil"
 	"os"
+	"path/filepath"
+	"reflect"
 	"strings"
 	"testing"
 
@@ -41,7 +43,7 @@ func TestDaemonConfigurationMerge(t *testing.T) {
 		t.Fatalf("expected %v, got %v\n", true, cc.AutoRestart)
 	}
 	if cc.LogConfig.Type != "syslog" {
-		t.Fatalf("expected syslog config, got %q\n", cc.LogConfig)
+		t.Fatalf("expected syslog config, got %v\n", cc.LogConfig)
 	}
 }
 
@@ -125,6 +127,41 @@ func TestFindConfigurationConflictsWithNamedOptions(t *testing.T) {
 	}
 }
 
+func TestDaemonConfigurationLogDriverOpts(t *testing.T) {
+	f, err := ioutil.TempFile("", "docker-config-")
+	if err != nil {
+		t.Fatal(err)
+	}
+	configFile := f.Name()
+	defer os.Remove(configFile)
+	f.Write([]byte(`{"log-driver-opts": {"json-file": {"max-size": "10m"}, "journald": {"tag": "{{.Name}}"}}}`))
+	f.Close()
+
+	flags := mflag.NewFlagSet("test", mflag.ContinueOnError)
+	c := &Config{}
+	c.InstallCommonFlags(flags, func(s string) string { return s })
+
+	cc, err := MergeDaemonConfigurations(c, flags, configFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := map[string]map[string]string{
+		"json-file": {"max-size": "10m"},
+		"journald":  {"tag": "{{.Name}}"},
+	}
+	if !reflect.DeepEqual(cc.LogConfig.DriverConfigs, expected) {
+		t.Fatalf("expected %v, got %v", expected, cc.LogConfig.DriverConfigs)
+	}
+
+	// the options cannot be set both with the flag and in the file
+	if err := flags.Set("-log-driver-opt", "syslog:tag=web"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := MergeDaemonConfigurations(c, flags, configFile); err == nil || !strings.Contains(err.Error(), "log-driver-opts") {
+		t.Fatalf("expected a log-driver-opts conflict, got %v", err)
+	}
+}
+
 func TestDaemonConfigurationMergeConflicts(t *testing.T) {
 	f, err := ioutil.TempFile("", "docker-config-")
 	if err != nil {
@@ -276,3 +313,249 @@ func TestValidateConfiguration(t *testing.T) {
 		t.Fatal("expected error, got nil")
 	}
 }
+
+func TestValidateAPIVersionPolicy(t *testing.T) {
+	for _, c := range []*Config{
+		{CommonConfig: CommonConfig{APIMinVersion: "1.19"}},
+		{CommonConfig: CommonConfig{APIDeprecatedVersion: "1.21"}},
+		{CommonConfig: CommonConfig{APIMinVersion: "1.19", APIDeprecatedVersion: "1.21", APISunsetDate: "2017-03-01"}},
+	} {
+		if err := ValidateConfiguration(c); err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+	}
+
+	for _, c := range []*Config{
+		{CommonConfig: CommonConfig{APIMinVersion: "1.2"}},
+		{CommonConfig: CommonConfig{APIMinVersion: "v1.19"}},
+		{CommonConfig: CommonConfig{APIDeprecatedVersion: "99.0"}},
+		{CommonConfig: CommonConfig{APISunsetDate: "2017-03-01"}},
+		{CommonConfig: CommonConfig{APIDeprecatedVersion: "1.21", APISunsetDate: "March 1st"}},
+	} {
+		if err := ValidateConfiguration(c); err == nil {
+			t.Fatalf("expected error for %s, got nil", []string{c.APIMinVersion, c.APIDeprecatedVersion, c.APISunsetDate})
+		}
+	}
+}
+
+func TestValidateBootstrapReconcileInterval(t *testing.T) {
+	for _, c := range []*Config{
+		{CommonConfig: CommonConfig{BootstrapReconcileInterval: "0"}},
+		{CommonConfig: CommonConfig{BootstrapSpec: "/etc/docker/bootstrap.json", BootstrapReconcileInterval: "5m"}},
+	} {
+		if err := ValidateConfiguration(c); err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+	}
+
+	for _, c := range []*Config{
+		{CommonConfig: CommonConfig{BootstrapSpec: "/etc/docker/bootstrap.json", BootstrapReconcileInterval: "5"}},
+		{CommonConfig: CommonConfig{BootstrapSpec: "/etc/docker/bootstrap.json", BootstrapReconcileInterval: "-5m"}},
+		{CommonConfig: CommonConfig{BootstrapReconcileInterval: "5m"}},
+	} {
+		if err := ValidateConfiguration(c); err == nil {
+			t.Fatalf("expected error for %q, got nil", c.BootstrapReconcileInterval)
+		}
+	}
+}
+
+func TestValidateMinIDPrefixLength(t *testing.T) {
+	for _, length := range []int{0, 12, 64} {
+		if err := ValidateConfiguration(&Config{CommonConfig: CommonConfig{MinIDPrefixLength: length}}); err != nil {
+			t.Fatalf("expected no error for %d, got %v", length, err)
+		}
+	}
+	for _, length := range []int{-1, 65} {
+		if err := ValidateConfiguration(&Config{CommonConfig: CommonConfig{MinIDPrefixLength: length}}); err == nil {
+			t.Fatalf("expected error for %d, got nil", length)
+		}
+	}
+}
+
+func TestValidateEventsRetention(t *testing.T) {
+	if err := ValidateConfiguration(&Config{CommonConfig: CommonConfig{EventsRetention: 1000}}); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if err := ValidateConfiguration(&Config{CommonConfig: CommonConfig{EventsRetention: -1}}); err == nil {
+		t.Fatal("expected an error for a negative retention")
+	}
+}
+
+func TestValidateSharedLayerStore(t *testing.T) {
+	c := &Config{CommonConfig: CommonConfig{Root: "/var/lib/docker", SharedLayerStore: "/mnt/nfs/docker"}}
+	if err := ValidateConfiguration(c); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	for _, shared := range []string{"mnt/nfs/docker", "/var/lib/docker/"} {
+		c := &Config{CommonConfig: CommonConfig{Root: "/var/lib/docker", SharedLayerStore: shared}}
+		if err := ValidateConfiguration(c); err == nil {
+			t.Fatalf("expected error for %q, got nil", shared)
+		}
+	}
+}
+
+func TestAPIRequestBodyLimits(t *testing.T) {
+	c := &Config{}
+	maxBodySize, limits, err := c.APIRequestBodyLimits()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if maxBodySize != defaultAPIMaxBodySize || len(limits) != 0 {
+		t.Fatalf("expected the default limits, got %d and %v", maxBodySize, limits)
+	}
+
+	c.APIMaxBodySize = "1MB"
+	c.APIBodySizeLimits = []string{"/commit=32MB", "/containers/{name}/update=0"}
+	maxBodySize, limits, err = c.APIRequestBodyLimits()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if maxBodySize != 1024*1024 || limits["/commit"] != 32*1024*1024 || limits["/containers/{name}/update"] != 0 || len(limits) != 2 {
+		t.Fatalf("unexpected limits %d and %v", maxBodySize, limits)
+	}
+
+	for _, val := range []string{"commit=1MB", "/commit", "/commit=big", "/commit=-1"} {
+		if _, err := validateAPIBodySizeLimit(val); err == nil {
+			t.Fatalf("expected %q to be rejected", val)
+		}
+	}
+}
+
+func TestDaemonConfigurationDropIns(t *testing.T) {
+	dir, err := ioutil.TempDir("", "docker-config-")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	configFile := filepath.Join(dir, "daemon.json")
+	dropInDir := filepath.Join(dir, "daemon.d")
+	if err := os.Mkdir(dropInDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	for name, content := range map[string]string{
+		"daemon.json":            `{"debug": true, "max-concurrent-downloads": 5, "log-opts": {"max-size": "10m", "max-file": "3"}, "labels": ["site=a"]}`,
+		"daemon.d/20-admin.json": `{"log-opts": {"max-size": "20m"}, "labels": ["admin=b"]}`,
+		"daemon.d/10-site.json":  `{"log-opts": {"max-size": "1m", "tag": "site"}, "max-concurrent-downloads": 1000000}`,
+		"daemon.d/30-ignored":    `{"debug": false}`,
+	} {
+		if err := ioutil.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	cc, err := MergeDaemonConfigurations(&Config{}, nil, configFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !cc.Debug {
+		t.Fatal("expected the files without the .json extension to be ignored")
+	}
+	if cc.MaxConcurrentDownloads == nil || *cc.MaxConcurrentDownloads != 1000000 {
+		t.Fatalf("expected the drop-in files to override the configuration file, got %v", cc.MaxConcurrentDownloads)
+	}
+	expectedOpts := map[string]string{"max-size": "20m", "max-file": "3", "tag": "site"}
+	if !reflect.DeepEqual(cc.LogConfig.Config, expectedOpts) {
+		t.Fatalf("expected the objects to be merged in the order of the files, got %v", cc.LogConfig.Config)
+	}
+	if len(cc.Labels) != 1 || cc.Labels[0] != "admin=b" {
+		t.Fatalf("expected the lists of the drop-in files to replace the ones before them, got %v", cc.Labels)
+	}
+
+	// the drop-in files are enough without the configuration file
+	if err := os.Remove(configFile); err != nil {
+		t.Fatal(err)
+	}
+	cc, err = MergeDaemonConfigurations(&Config{}, nil, configFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cc.Debug || cc.LogConfig.Config["max-size"] != "20m" {
+		t.Fatalf("expected the configuration of the drop-in files, got %+v", cc.CommonConfig.LogConfig)
+	}
+
+	if err := ioutil.WriteFile(filepath.Join(dropInDir, "40-broken.json"), []byte(`{"debug": tru`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := MergeDaemonConfigurations(&Config{}, nil, configFile); err == nil || !strings.Contains(err.Error(), "40-broken.json") {
+		t.Fatalf("expected an error naming the broken file, got %v", err)
+	}
+}
+
+func TestDaemonConfigurationProfiles(t *testing.T) {
+	dir, err := ioutil.TempDir("", "docker-config-")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	configFile := filepath.Join(dir, "daemon.json")
+	content := `{"debug": true, "log-opts": {"max-size": "10m"}, "profiles": {
+		"disconnected": {"debug": false, "log-opts": {"tag": "offline"}, "registry-mirrors": ["https://mirror.example.com"]},
+		"production": {"labels": ["env=production"]}
+	}}`
+	if err := ioutil.WriteFile(configFile, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	defer func(cmdline string) { kernelCmdline = cmdline }(kernelCmdline)
+	kernelCmdline = filepath.Join(dir, "cmdline")
+
+	// without a selected profile, the profiles are ignored
+	cc, err := MergeDaemonConfigurations(&Config{}, nil, configFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !cc.Debug || len(cc.Mirrors) != 0 || len(cc.Labels) != 0 {
+		t.Fatalf("expected the configuration without the profiles, got debug %v, mirrors %v, labels %v", cc.Debug, cc.Mirrors, cc.Labels)
+	}
+
+	// selected with the kernel command line
+	if err := ioutil.WriteFile(kernelCmdline, []byte("ro root=/dev/vda1 docker.config-profile=disconnected quiet\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	cc, err = MergeDaemonConfigurations(&Config{}, nil, configFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cc.Debug || len(cc.Mirrors) != 1 {
+		t.Fatalf("expected the options of the disconnected profile, got debug %v, mirrors %v", cc.Debug, cc.Mirrors)
+	}
+	expectedOpts := map[string]string{"max-size": "10m", "tag": "offline"}
+	if !reflect.DeepEqual(cc.LogConfig.Config, expectedOpts) {
+		t.Fatalf("expected the objects of the profile to be merged, got %v", cc.LogConfig.Config)
+	}
+
+	// the flag takes precedence over the kernel command line
+	flags := mflag.NewFlagSet("test", mflag.ContinueOnError)
+	flagsConfig := &Config{}
+	flagsConfig.InstallCommonFlags(flags, func(s string) string { return s })
+	flags.Bool([]string{"D", "-debug"}, false, "")
+	if err := flags.Set("-config-profile", "production"); err != nil {
+		t.Fatal(err)
+	}
+	cc, err = MergeDaemonConfigurations(flagsConfig, flags, configFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !cc.Debug || len(cc.Labels) != 1 || cc.Labels[0] != "env=production" {
+		t.Fatalf("expected the options of the production profile, got debug %v, labels %v", cc.Debug, cc.Labels)
+	}
+	if cc.ConfigProfile != "production" {
+		t.Fatalf("expected the production profile to be reported, got %q", cc.ConfigProfile)
+	}
+
+	if err := flags.Set("-config-profile", "unknown"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := MergeDaemonConfigurations(flagsConfig, flags, configFile); err == nil || !strings.Contains(err.Error(), "unknown configuration profile unknown") {
+		t.Fatalf("expected an error for the unknown profile, got %v", err)
+	}
+
+	if err := ioutil.WriteFile(configFile, []byte(`{"config-profile": "production"}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := MergeDaemonConfigurations(&Config{}, nil, configFile); err == nil || !strings.Contains(err.Error(), "config-profile") {
+		t.Fatalf("expected an error for the profile selected in the configuration file, got %v", err)
+	}
+}
@@ -14,9 +14,14 @@ import (
 )
 
 var (
-	defaultPidFile  = "/var/run/docker.pid"
-	defaultGraph    = "/var/lib/docker"
-	defaultExecRoot = "/var/run/docker"
+	defaultPidFile    = "/var/run/docker.pid"
+	defaultGraph      = "/var/lib/docker"
+	defaultExecRoot   = "/var/run/docker"
+	defaultHooksDir   = "/usr/share/containers/oci/hooks.d"
+	defaultMountsFile = "/usr/share/containers/mounts.conf"
+	// defaultInitBinary is the name of the init binary looked up in the
+	// PATH when --init-path is not set.
+	defaultInitBinary = "docker-init"
 )
 
 // Config defines the configuration of a docker daemon.
@@ -28,8 +33,12 @@ type Config struct {
 	// Fields below here are platform specific.
 	CgroupParent         string                   `json:"cgroup-parent,omitempty"`
 	ContainerdAddr       string                   `json:"containerd,omitempty"`
+	DefaultMountsFile    string                   `json:"default-mounts-file,omitempty"`
 	EnableSelinuxSupport bool                     `json:"selinux-enabled,omitempty"`
 	ExecRoot             string                   `json:"exec-root,omitempty"`
+	HooksDir             string                   `json:"hooks-dir,omitempty"`
+	Init                 bool                     `json:"init,omitempty"`
+	InitPath             string                   `json:"init-path,omitempty"`
 	RemappedRoot         string                   `json:"userns-remap,omitempty"`
 	Ulimits              map[string]*units.Ulimit `json:"default-ulimits,omitempty"`
 	Runtimes             map[string]types.Runtime `json:"runtimes,omitempty"`
@@ -74,6 +83,8 @@ func (config *Config) InstallFlags(cmd *flag.FlagSet, usageFn func(string) strin
 	cmd.BoolVar(&config.bridgeConfig.EnableIPMasq, []string{"-ip-masq"}, true, usageFn("Enable IP masquerading"))
 	cmd.BoolVar(&config.bridgeConfig.EnableIPv6, []string{"-ipv6"}, false, usageFn("Enable IPv6 networking"))
 	cmd.StringVar(&config.ExecRoot, []string{"-exec-root"}, defaultExecRoot, usageFn("Root directory for execution state files"))
+	cmd.StringVar(&config.DefaultMountsFile, []string{"-default-mounts-file"}, defaultMountsFile, usageFn("File of the host directories made available in every container"))
+	cmd.StringVar(&config.HooksDir, []string{"-hooks-dir"}, defaultHooksDir, usageFn("Directory of the JSON definitions of the OCI hooks of the containers"))
 	cmd.StringVar(&config.bridgeConfig.IP, []string{"#bip", "-bip"}, "", usageFn("Specify network bridge IP"))
 	cmd.StringVar(&config.bridgeConfig.Iface, []string{"b", "-bridge"}, "", usageFn("Attach containers to a network bridge"))
 	cmd.StringVar(&config.bridgeConfig.FixedCIDR, []string{"-fixed-cidr"}, "", usageFn("IPv4 subnet for fixed IPs"))
@@ -91,6 +102,8 @@ func (config *Config) InstallFlags(cmd *flag.FlagSet, usageFn func(string) strin
 	config.Runtimes = make(map[string]types.Runtime)
 	cmd.Var(runconfigopts.NewNamedRuntimeOpt("runtimes", &config.Runtimes, stockRuntimeName), []string{"-add-runtime"}, usageFn("Register an additional OCI compatible runtime"))
 	cmd.StringVar(&config.DefaultRuntime, []string{"-default-runtime"}, stockRuntimeName, usageFn("Default OCI runtime to be used"))
+	cmd.BoolVar(&config.Init, []string{"-init"}, false, usageFn("Run an init in the containers to forward signals and reap processes"))
+	cmd.StringVar(&config.InitPath, []string{"-init-path"}, "", usageFn("Path to the docker-init binary"))
 	cmd.IntVar(&config.OOMScoreAdjust, []string{"-oom-score-adjust"}, -500, usageFn("Set the oom_score_adj for the daemon"))
 
 	config.attachExperimentalFlags(cmd, usageFn)
@@ -45,11 +45,16 @@ func (daemon *Daemon) GetContainer(prefixOrName string) (*container.Container, e
 	if indexError != nil {
 		// When truncindex defines an error type, use that instead
 		if indexError == truncindex.ErrNotExist {
-			err := fmt.Errorf("No such container: %s", prefixOrName)
-			return nil, errors.NewRequestNotFoundError(err)
+			return nil, errNoSuchContainer(prefixOrName)
+		}
+		if ambiguous, ok := indexError.(truncindex.ErrAmbiguousPrefix); ok {
+			return nil, errAmbiguousPrefix("container", prefixOrName, ambiguous.Candidates)
 		}
 		return nil, indexError
 	}
+	if err := daemon.checkIDPrefix("container", prefixOrName, containerID); err != nil {
+		return nil, err
+	}
 	return daemon.containers.Get(containerID), nil
 }
 
@@ -238,6 +243,14 @@ func (daemon *Daemon) verifyContainerSettings(hostConfig *containertypes.HostCon
 		return nil, nil
 	}
 
+	if err := daemon.verifyLogQuota(hostConfig.LogConfig); err != nil {
+		return nil, err
+	}
+
+	if err := daemon.verifyLogDriverOpts(hostConfig.LogConfig); err != nil {
+		return nil, err
+	}
+
 	for port := range hostConfig.PortBindings {
 		_, portStr := nat.SplitProtoPort(string(port))
 		if _, err := nat.ParsePort(portStr); err != nil {
@@ -0,0 +1,112 @@
+package daemon
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/docker/distribution/digest"
+	"github.com/docker/docker/container"
+	"github.com/docker/docker/pkg/stringid"
+	"github.com/docker/docker/reference"
+	"github.com/docker/engine-api/types"
+	"github.com/docker/engine-api/types/network"
+)
+
+// ContainerSpec returns the spec of the container: its configuration, its
+// image by digest if it was pulled from a registry, its endpoints in its
+// networks and its named volumes, without the values the daemon generated
+// for this container, like its hostname or its addresses, so that creating
+// a container from the spec creates the same container again.
+func (daemon *Daemon) ContainerSpec(name string) (*types.ContainerSpec, error) {
+	c, err := daemon.GetContainer(name)
+	if err != nil {
+		return nil, err
+	}
+
+	c.Lock()
+	defer c.Unlock()
+
+	config := *c.Config
+	if config.Hostname == stringid.TruncateID(c.ID) {
+		config.Hostname = ""
+	}
+	config.Image = daemon.specImage(c)
+	hostConfig := *c.HostConfig
+	// the file of the ID is a setting of the client on the host it ran on
+	hostConfig.ContainerIDFile = ""
+
+	spec := &types.ContainerSpec{
+		Version:    types.ContainerSpecVersion,
+		Name:       strings.TrimPrefix(c.Name, "/"),
+		Image:      config.Image,
+		ImageID:    c.ImageID.String(),
+		Config:     &config,
+		HostConfig: &hostConfig,
+	}
+
+	if c.NetworkSettings != nil && len(c.NetworkSettings.Networks) > 0 {
+		spec.NetworkingConfig = &network.NetworkingConfig{EndpointsConfig: make(map[string]*network.EndpointSettings)}
+		for n, ep := range c.NetworkSettings.Networks {
+			spec.NetworkingConfig.EndpointsConfig[n] = specEndpoint(c, ep)
+		}
+	}
+
+	volumes := make(map[string]types.VolumeCreateRequest)
+	for _, m := range c.MountPoints {
+		if !m.Named || m.Volume == nil {
+			continue
+		}
+		v := volumeToAPIType(m.Volume)
+		volumes[v.Name] = types.VolumeCreateRequest{Name: v.Name, Driver: v.Driver, Labels: v.Labels}
+	}
+	var names []string
+	for n := range volumes {
+		names = append(names, n)
+	}
+	sort.Strings(names)
+	for _, n := range names {
+		spec.Volumes = append(spec.Volumes, volumes[n])
+	}
+
+	return spec, nil
+}
+
+// specImage returns the reference by digest of the image of the container
+// if its image was pulled from a registry, and else the image it was
+// created from.
+func (daemon *Daemon) specImage(c *container.Container) string {
+	_, dgst := daemon.imageOrigin(c)
+	if dgst == "" {
+		return c.Config.Image
+	}
+	_, named, err := reference.ParseIDOrReference(c.Config.Image)
+	if err != nil || named == nil {
+		return c.Config.Image
+	}
+	canonical, err := reference.WithDigest(named, digest.Digest(dgst))
+	if err != nil {
+		return c.Config.Image
+	}
+	return canonical.String()
+}
+
+// specEndpoint returns the configuration of an endpoint of the container,
+// without its operational data and the alias of the container ID the daemon
+// adds in user-defined networks.
+func specEndpoint(c *container.Container, ep *network.EndpointSettings) *network.EndpointSettings {
+	if ep == nil {
+		return &network.EndpointSettings{}
+	}
+	shortID := stringid.TruncateID(c.ID)
+	var aliases []string
+	for _, alias := range ep.Aliases {
+		if alias != shortID {
+			aliases = append(aliases, alias)
+		}
+	}
+	return &network.EndpointSettings{
+		IPAMConfig: ep.IPAMConfig,
+		Links:      ep.Links,
+		Aliases:    aliases,
+	}
+}
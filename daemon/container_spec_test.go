@@ -0,0 +1,36 @@
+package daemon
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/docker/docker/container"
+	"github.com/docker/engine-api/types/network"
+)
+
+func TestSpecEndpoint(t *testing.T) {
+	c := container.NewBaseContainer("0123456789abcdef", "")
+	ep := &network.EndpointSettings{
+		IPAMConfig: &network.EndpointIPAMConfig{IPv4Address: "10.0.0.10"},
+		Links:      []string{"db:db"},
+		Aliases:    []string{"web", "0123456789ab"},
+		NetworkID:  "net1",
+		EndpointID: "ep1",
+		Gateway:    "10.0.0.1",
+		IPAddress:  "10.0.0.10",
+		MacAddress: "02:42:0a:00:00:0a",
+	}
+
+	expected := &network.EndpointSettings{
+		IPAMConfig: &network.EndpointIPAMConfig{IPv4Address: "10.0.0.10"},
+		Links:      []string{"db:db"},
+		Aliases:    []string{"web"},
+	}
+	if spec := specEndpoint(c, ep); !reflect.DeepEqual(spec, expected) {
+		t.Fatalf("expected %+v, got %+v", expected, spec)
+	}
+
+	if spec := specEndpoint(c, nil); !reflect.DeepEqual(spec, &network.EndpointSettings{}) {
+		t.Fatalf("expected an empty endpoint, got %+v", spec)
+	}
+}
@@ -34,6 +34,10 @@ func (daemon *Daemon) containerCreate(params types.ContainerCreateConfig, manage
 		return types.ContainerCreateResponse{}, fmt.Errorf("Config cannot be empty in order to create a container")
 	}
 
+	if daemon.IsDraining() {
+		return types.ContainerCreateResponse{}, errDraining
+	}
+
 	warnings, err := daemon.verifyContainerSettings(params.HostConfig, params.Config, false, validateHostname)
 	if err != nil {
 		return types.ContainerCreateResponse{Warnings: warnings}, err
@@ -52,12 +56,20 @@ func (daemon *Daemon) containerCreate(params types.ContainerCreateConfig, manage
 		return types.ContainerCreateResponse{Warnings: warnings}, err
 	}
 
+	var verification *types.ImageVerification
+	if params.VerifyLocal {
+		verification, err = daemon.verifyLocalImage(params.Config.Image)
+		if err != nil {
+			return types.ContainerCreateResponse{Warnings: warnings}, daemon.imageNotExistToErrcode(err)
+		}
+	}
+
 	container, err := daemon.create(params, managed)
 	if err != nil {
 		return types.ContainerCreateResponse{Warnings: warnings}, daemon.imageNotExistToErrcode(err)
 	}
 
-	return types.ContainerCreateResponse{ID: container.ID, Warnings: warnings}, nil
+	return types.ContainerCreateResponse{ID: container.ID, Warnings: warnings, Verification: verification}, nil
 }
 
 // Create creates a new container from the given configuration with a given name.
@@ -75,6 +87,9 @@ func (daemon *Daemon) create(params types.ContainerCreateConfig, managed bool) (
 			return nil, err
 		}
 		imgID = img.ID()
+		if err := daemon.verifyImagePolicy(params.Config.Image, imgID); err != nil {
+			return nil, err
+		}
 	}
 
 	if err := daemon.mergeAndVerifyConfig(params.Config, img); err != nil {
@@ -149,6 +164,7 @@ func (daemon *Daemon) create(params types.ContainerCreateConfig, managed bool) (
 	if err := daemon.Register(container); err != nil {
 		return nil, err
 	}
+	daemon.imageUsed(imgID)
 	daemon.LogContainerEvent(container, "create")
 	return container, nil
 }
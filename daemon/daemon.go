@@ -26,6 +26,8 @@ import (
 	"github.com/docker/docker/container"
 	"github.com/docker/docker/daemon/events"
 	"github.com/docker/docker/daemon/exec"
+	"github.com/docker/docker/daemon/logger"
+	"github.com/docker/docker/daemon/trace"
 	"github.com/docker/engine-api/types"
 	containertypes "github.com/docker/engine-api/types/container"
 	"github.com/docker/libnetwork/cluster"
@@ -65,15 +67,31 @@ var (
 	DefaultRuntimeBinary = "docker-runc"
 
 	errSystemNotSupported = fmt.Errorf("The Docker daemon is not supported on this platform.")
+
+	// shutdownParallelism is the maximum number of containers stopped at
+	// once when the daemon shuts down.
+	shutdownParallelism = 32 * runtime.NumCPU()
 )
 
+// shutdownGracePeriod is the number of seconds added to the stop timeouts of
+// the containers for the daemon to kill them and write their logs before it
+// exits.
+const shutdownGracePeriod = 5
+
 // Daemon holds information about the Docker daemon.
 type Daemon struct {
 	ID                        string
 	repository                string
 	containers                container.Store
 	execCommands              *exec.Store
+	archiveUploads            *archiveUploads
 	referenceStore            reference.Store
+	pinStore                  reference.PinStore
+	aliasStore                reference.AliasStore
+	registryCache             net.Listener
+	remoteInspectCache        *remoteInspectCache
+	verifiedImages            *verifiedImages
+	imageUsage                *imageUsage
 	downloadManager           *xfer.LayerDownloadManager
 	uploadManager             *xfer.LayerUploadManager
 	distributionMetadataStore dmetadata.Store
@@ -82,6 +100,8 @@ type Daemon struct {
 	configStore               *Config
 	statsCollector            *statsCollector
 	defaultLogConfig          containertypes.LogConfig
+	logDriverOpts             map[string]map[string]string
+	logRedactor               *logger.Redactor
 	RegistryService           registry.Service
 	EventsService             *events.Events
 	netController             libnetwork.NetworkController
@@ -90,6 +110,7 @@ type Daemon struct {
 	root                      string
 	seccompEnabled            bool
 	shutdown                  bool
+	draining                  int32 // set atomically while the daemon drains
 	uidMaps                   []idtools.IDMap
 	gidMaps                   []idtools.IDMap
 	layerStore                layer.Store
@@ -274,7 +295,7 @@ func (daemon *Daemon) restore() error {
 
 			// Make sure networks are available before starting
 			daemon.waitForNetworks(c)
-			if err := daemon.containerStart(c); err != nil {
+			if err := daemon.containerStart(c, "", trace.New()); err != nil {
 				logrus.Errorf("Failed to start container %s: %s", c.ID, err)
 			}
 			close(chNotify)
@@ -492,6 +513,9 @@ func NewDaemon(config *Config, registryService registry.Service, containerdRemot
 		GraphDriverOptions:        config.GraphOptions,
 		UIDMaps:                   uidMaps,
 		GIDMaps:                   gidMaps,
+
+		SharedStorePath:                 config.SharedLayerStore,
+		SharedMetadataStorePathTemplate: filepath.Join(config.SharedLayerStore, "image", "%s", "layerdb"),
 	})
 	if err != nil {
 		return nil, err
@@ -543,12 +567,35 @@ func NewDaemon(config *Config, registryService registry.Service, containerdRemot
 	}
 
 	eventsService := events.New()
+	if config.EventsRetention > 0 {
+		eventsService, err = events.NewPersistent(filepath.Join(config.Root, "events.log"), config.EventsRetention)
+		if err != nil {
+			return nil, fmt.Errorf("Couldn't load the events log: %v", err)
+		}
+	}
 
 	referenceStore, err := reference.NewReferenceStore(filepath.Join(imageRoot, "repositories.json"))
 	if err != nil {
 		return nil, fmt.Errorf("Couldn't create Tag store repositories: %s", err)
 	}
 
+	pinStore, err := reference.NewPinStore(pinnedReferencesPath(config, imageRoot))
+	if err != nil {
+		return nil, fmt.Errorf("Couldn't load pinned references: %s", err)
+	}
+
+	var aliasStore reference.AliasStore
+	if config.ShortNameAliases != "" {
+		if aliasStore, err = reference.NewAliasStore(config.ShortNameAliases); err != nil {
+			return nil, fmt.Errorf("Couldn't load short-name aliases: %s", err)
+		}
+	}
+
+	remoteInspectTTL, err := config.remoteInspectTTL()
+	if err != nil {
+		return nil, err
+	}
+
 	migrationStart := time.Now()
 	if err := v1.Migrate(config.Root, graphDriver, d.layerStore, d.imageStore, referenceStore, distributionMetadataStore); err != nil {
 		logrus.Errorf("Graph migration failed: %q. Your old graph data was found to be too inconsistent for upgrading to content-addressable storage. Some of the old data was probably not upgraded. We recommend starting over with a clean storage directory if possible.", err)
@@ -572,7 +619,20 @@ func NewDaemon(config *Config, registryService registry.Service, containerdRemot
 	d.repository = daemonRepo
 	d.containers = container.NewMemoryStore()
 	d.execCommands = exec.NewStore()
+	archiveUploadLimits, err := config.archiveUploadLimits()
+	if err != nil {
+		return nil, err
+	}
+	d.archiveUploads, err = newArchiveUploads(filepath.Join(config.Root, "archive-uploads"), archiveUploadLimits)
+	if err != nil {
+		return nil, err
+	}
 	d.referenceStore = referenceStore
+	d.pinStore = pinStore
+	d.aliasStore = aliasStore
+	d.remoteInspectCache = newRemoteInspectCache(remoteInspectTTL)
+	d.verifiedImages = newVerifiedImages(verifiedImageTTL)
+	d.imageUsage = newImageUsage()
 	d.distributionMetadataStore = distributionMetadataStore
 	d.trustKey = trustKey
 	d.idIndex = truncindex.NewTruncIndex([]string{})
@@ -581,6 +641,11 @@ func NewDaemon(config *Config, registryService registry.Service, containerdRemot
 		Type:   config.LogConfig.Type,
 		Config: config.LogConfig.Config,
 	}
+	d.logDriverOpts = config.LogConfig.DriverConfigs
+	d.logRedactor, err = logger.NewRedactor(config.LogConfig.Redact)
+	if err != nil {
+		return nil, err
+	}
 	d.RegistryService = registryService
 	d.EventsService = eventsService
 	d.volumes = volStore
@@ -594,6 +659,7 @@ func NewDaemon(config *Config, registryService registry.Service, containerdRemot
 	d.containerdRemote = containerdRemote
 
 	go d.execCommandGC()
+	go d.flushImageUsage()
 
 	d.containerd, err = containerdRemote.Client(d)
 	if err != nil {
@@ -604,6 +670,26 @@ func NewDaemon(config *Config, registryService registry.Service, containerdRemot
 		return nil, err
 	}
 
+	if config.RegistryCacheAddr != "" {
+		if err := d.startRegistryCache(config); err != nil {
+			return nil, err
+		}
+	}
+
+	if config.BootstrapSpec != "" {
+		spec, err := d.loadBootstrapSpec(config.BootstrapSpec)
+		if err != nil {
+			return nil, fmt.Errorf("Couldn't load bootstrap spec: %v", err)
+		}
+		interval, err := config.bootstrapReconcileInterval()
+		if err != nil {
+			return nil, err
+		}
+		if spec != nil || interval > 0 {
+			go d.runBootstrap(config.BootstrapSpec, spec, interval)
+		}
+	}
+
 	return d, nil
 }
 
@@ -637,8 +723,8 @@ func (daemon *Daemon) shutdownContainer(c *container.Container) error {
 			return err
 		}
 	}
-	// If container failed to exit in 10 seconds of SIGTERM, then using the force
-	if err := daemon.containerStop(c, 10); err != nil {
+	// If container failed to exit in its stop timeout of SIGTERM, then using the force
+	if err := daemon.containerStop(c, daemon.shutdownStopTimeout(c)); err != nil {
 		return fmt.Errorf("Failed to stop container %s with error: %v", c.ID, err)
 	}
 
@@ -646,9 +732,84 @@ func (daemon *Daemon) shutdownContainer(c *container.Container) error {
 	return nil
 }
 
+// shutdownContainers calls shutdown for the running containers concurrently,
+// at most shutdownParallelism at a time, and waits for all the calls to
+// return. The loggers of the containers are closed, and the journald ones
+// write their queued messages, before the containers are marked as stopped.
+func (daemon *Daemon) shutdownContainers(shutdown func(*container.Container)) {
+	var wg sync.WaitGroup
+	slots := make(chan struct{}, shutdownParallelism)
+	for _, c := range daemon.List() {
+		if !c.IsRunning() {
+			continue
+		}
+		wg.Add(1)
+		slots <- struct{}{}
+		go func(c *container.Container) {
+			defer func() {
+				<-slots
+				wg.Done()
+			}()
+			shutdown(c)
+		}(c)
+	}
+	wg.Wait()
+}
+
+// shutdownStopTimeout returns the number of seconds the container has to
+// exit when the daemon shuts down: its own stop timeout, or the shutdown
+// timeout of the daemon.
+func (daemon *Daemon) shutdownStopTimeout(c *container.Container) int {
+	if c.Config.StopTimeout != nil {
+		return *c.Config.StopTimeout
+	}
+	return daemon.configStore.ShutdownTimeout
+}
+
+// ShutdownTimeout returns the number of seconds to wait for Shutdown before
+// forcing the daemon to exit: the longest stop timeout of the running
+// containers, plus a grace period for them to be killed and their logs to be
+// written. It is negative when the shutdown has to wait for the containers
+// to exit, however long they take.
+func (daemon *Daemon) ShutdownTimeout() int {
+	timeout := daemon.configStore.ShutdownTimeout
+	if timeout < 0 {
+		return -1
+	}
+	for _, c := range daemon.List() {
+		if !c.IsRunning() {
+			continue
+		}
+		t := daemon.shutdownStopTimeout(c)
+		if t < 0 {
+			return -1
+		}
+		if t > timeout {
+			timeout = t
+		}
+	}
+	return timeout + shutdownGracePeriod
+}
+
+// pinnedReferencesPath returns the path to the file of the pinned
+// references: the path set in the configuration, or a file of the image
+// root directory.
+func pinnedReferencesPath(config *Config, imageRoot string) string {
+	if config.PinnedReferences != "" {
+		return config.PinnedReferences
+	}
+	return filepath.Join(imageRoot, "pinned-references.json")
+}
+
 // Shutdown stops the daemon.
 func (daemon *Daemon) Shutdown() error {
 	daemon.shutdown = true
+	if daemon.registryCache != nil {
+		daemon.registryCache.Close()
+	}
+	if daemon.imageUsage != nil {
+		daemon.imageUsage.flush(daemon.imageStore)
+	}
 	// Keep mounts and networking running on daemon shutdown if
 	// we are to keep containers running and restore them.
 	if daemon.configStore.LiveRestore {
@@ -660,10 +821,7 @@ func (daemon *Daemon) Shutdown() error {
 
 	if daemon.containers != nil {
 		logrus.Debug("starting clean shutdown of all containers...")
-		daemon.containers.ApplyAll(func(c *container.Container) {
-			if !c.IsRunning() {
-				return
-			}
+		daemon.shutdownContainers(func(c *container.Container) {
 			logrus.Debugf("stopping %s", c.ID)
 			if err := daemon.shutdownContainer(c); err != nil {
 				logrus.Errorf("Stop container error: %v", err)
@@ -689,6 +847,12 @@ func (daemon *Daemon) Shutdown() error {
 
 	pluginShutdown()
 
+	if daemon.EventsService != nil {
+		if err := daemon.EventsService.Close(); err != nil {
+			logrus.Errorf("Error closing the events log: %v", err)
+		}
+	}
+
 	if err := daemon.cleanupMounts(); err != nil {
 		return err
 	}
@@ -842,10 +1006,13 @@ func (daemon *Daemon) initDiscovery(config *Config) error {
 // These are the settings that Reload changes:
 // - Daemon labels.
 // - Daemon debug log level.
+// - Daemon log level.
+// - Registry mirrors and insecure registries.
 // - Daemon max concurrent downloads
 // - Daemon max concurrent uploads
 // - Cluster discovery (reconfigure and restart).
 // - Daemon live restore
+// - Daemon remote inspect cache TTL
 func (daemon *Daemon) Reload(config *Config) error {
 	var err error
 	// used to hold reloaded changes
@@ -867,6 +1034,9 @@ func (daemon *Daemon) Reload(config *Config) error {
 	if err = daemon.reloadClusterDiscovery(config); err != nil {
 		return err
 	}
+	if err = daemon.reloadRegistries(config); err != nil {
+		return err
+	}
 
 	if config.IsValueSet("labels") {
 		daemon.configStore.Labels = config.Labels
@@ -874,6 +1044,47 @@ func (daemon *Daemon) Reload(config *Config) error {
 	if config.IsValueSet("debug") {
 		daemon.configStore.Debug = config.Debug
 	}
+	if config.IsValueSet("log-level") {
+		daemon.configStore.LogLevel = config.LogLevel
+	}
+	if config.IsValueSet("log-max-age") {
+		daemon.configStore.LogConfig.MaxAge = config.LogConfig.MaxAge
+	}
+	if config.IsValueSet("log-max-size") {
+		daemon.configStore.LogConfig.MaxSize = config.LogConfig.MaxSize
+	}
+	if config.IsValueSet("log-max-files") {
+		daemon.configStore.LogConfig.MaxFiles = config.LogConfig.MaxFiles
+	}
+	if config.IsValueSet("log-redact") {
+		if err = daemon.logRedactor.SetRules(config.LogConfig.Redact); err != nil {
+			return err
+		}
+		daemon.configStore.LogConfig.Redact = config.LogConfig.Redact
+	}
+	if config.IsValueSet("remote-inspect-ttl") {
+		var ttl time.Duration
+		if ttl, err = config.remoteInspectTTL(); err != nil {
+			return err
+		}
+		daemon.remoteInspectCache.setTTL(ttl)
+		daemon.configStore.RemoteInspectTTL = config.RemoteInspectTTL
+	}
+	if config.IsValueSet("min-id-prefix-length") {
+		daemon.configStore.MinIDPrefixLength = config.MinIDPrefixLength
+	}
+	if config.IsValueSet("shutdown-timeout") {
+		daemon.configStore.ShutdownTimeout = config.ShutdownTimeout
+	}
+	if config.IsValueSet("min-free-space") {
+		daemon.configStore.MinFreeSpace = config.MinFreeSpace
+	}
+	if config.IsValueSet("allowed-images") {
+		daemon.configStore.AllowedImages = config.AllowedImages
+	}
+	if config.IsValueSet("blocked-images") {
+		daemon.configStore.BlockedImages = config.BlockedImages
+	}
 	if config.IsValueSet("live-restore") {
 		daemon.configStore.LiveRestore = config.LiveRestore
 		if err := daemon.containerdRemote.UpdateOptions(libcontainerd.WithLiveRestore(config.LiveRestore)); err != nil {
@@ -910,6 +1121,7 @@ func (daemon *Daemon) Reload(config *Config) error {
 
 	// We emit daemon reload event here with updatable configurations
 	attributes["debug"] = fmt.Sprintf("%t", daemon.configStore.Debug)
+	attributes["log-level"] = daemon.configStore.LogLevel
 	attributes["cluster-store"] = daemon.configStore.ClusterStore
 	if daemon.configStore.ClusterOpts != nil {
 		opts, _ := json.Marshal(daemon.configStore.ClusterOpts)
@@ -924,12 +1136,88 @@ func (daemon *Daemon) Reload(config *Config) error {
 	} else {
 		attributes["labels"] = "[]"
 	}
+	if daemon.configStore.LogConfig.Redact != nil {
+		rules, _ := json.Marshal(daemon.configStore.LogConfig.Redact)
+		attributes["log-redact"] = string(rules)
+	} else {
+		attributes["log-redact"] = "[]"
+	}
+	attributes["log-max-age"] = daemon.configStore.LogConfig.MaxAge
+	attributes["log-max-size"] = daemon.configStore.LogConfig.MaxSize
+	attributes["log-max-files"] = fmt.Sprintf("%d", daemon.configStore.LogConfig.MaxFiles)
+	attributes["remote-inspect-ttl"] = daemon.configStore.RemoteInspectTTL
+	attributes["min-id-prefix-length"] = fmt.Sprintf("%d", daemon.configStore.MinIDPrefixLength)
+	attributes["shutdown-timeout"] = fmt.Sprintf("%d", daemon.configStore.ShutdownTimeout)
+	attributes["min-free-space"] = daemon.configStore.MinFreeSpace
+	if daemon.configStore.AllowedImages != nil {
+		patterns, _ := json.Marshal(daemon.configStore.AllowedImages)
+		attributes["allowed-images"] = string(patterns)
+	} else {
+		attributes["allowed-images"] = "[]"
+	}
+	if daemon.configStore.BlockedImages != nil {
+		patterns, _ := json.Marshal(daemon.configStore.BlockedImages)
+		attributes["blocked-images"] = string(patterns)
+	} else {
+		attributes["blocked-images"] = "[]"
+	}
+	if daemon.configStore.Mirrors != nil {
+		mirrors, _ := json.Marshal(daemon.configStore.Mirrors)
+		attributes["registry-mirrors"] = string(mirrors)
+	} else {
+		attributes["registry-mirrors"] = "[]"
+	}
+	if daemon.configStore.InsecureRegistries != nil {
+		registries, _ := json.Marshal(daemon.configStore.InsecureRegistries)
+		attributes["insecure-registries"] = string(registries)
+	} else {
+		attributes["insecure-registries"] = "[]"
+	}
 	attributes["max-concurrent-downloads"] = fmt.Sprintf("%d", *daemon.configStore.MaxConcurrentDownloads)
 	attributes["max-concurrent-uploads"] = fmt.Sprintf("%d", *daemon.configStore.MaxConcurrentUploads)
 
 	return nil
 }
 
+// reloadRegistries validates the registry mirrors and insecure registries set
+// in the configuration file, and applies them to the registry service. The
+// other registry options, which are not reloadable, are kept.
+func (daemon *Daemon) reloadRegistries(config *Config) error {
+	mirrorsSet := config.IsValueSet("registry-mirrors")
+	insecureSet := config.IsValueSet("insecure-registries")
+	if !mirrorsSet && !insecureSet {
+		return nil
+	}
+
+	options := daemon.configStore.ServiceOptions
+	if mirrorsSet {
+		options.Mirrors = make([]string, 0, len(config.Mirrors))
+		for _, m := range config.Mirrors {
+			mirror, err := registry.ValidateMirror(m)
+			if err != nil {
+				return err
+			}
+			options.Mirrors = append(options.Mirrors, mirror)
+		}
+	}
+	if insecureSet {
+		options.InsecureRegistries = make([]string, 0, len(config.InsecureRegistries))
+		for _, r := range config.InsecureRegistries {
+			index, err := registry.ValidateIndexName(r)
+			if err != nil {
+				return err
+			}
+			options.InsecureRegistries = append(options.InsecureRegistries, index)
+		}
+	}
+
+	daemon.configStore.ServiceOptions = options
+	if daemon.RegistryService != nil {
+		daemon.RegistryService.Reload(options)
+	}
+	return nil
+}
+
 func (daemon *Daemon) reloadClusterDiscovery(config *Config) error {
 	var err error
 	newAdvertise := daemon.configStore.ClusterAdvertise
@@ -1,10 +1,13 @@
 package daemon
 
 import (
+	"fmt"
 	"io/ioutil"
 	"os"
 	"path/filepath"
 	"reflect"
+	"strings"
+	"sync"
 	"testing"
 	"time"
 
@@ -13,6 +16,7 @@ import (
 	_ "github.com/docker/docker/pkg/discovery/memory"
 	"github.com/docker/docker/pkg/registrar"
 	"github.com/docker/docker/pkg/truncindex"
+	"github.com/docker/docker/registry"
 	"github.com/docker/docker/volume"
 	volumedrivers "github.com/docker/docker/volume/drivers"
 	"github.com/docker/docker/volume/local"
@@ -110,11 +114,24 @@ func TestGetContainer(t *testing.T) {
 
 	if _, err := daemon.GetContainer("3cdbd1"); err == nil {
 		t.Fatal("Should return an error when provided a prefix that partially matches multiple container ID's")
+	} else if !strings.Contains(err.Error(), c2.ID+", "+c3.ID) {
+		t.Fatalf("Should list the containers matching an ambiguous prefix, got %v", err)
 	}
 
 	if _, err := daemon.GetContainer("nothing"); err == nil {
 		t.Fatal("Should return an error when provided a prefix that is neither a name or a partial match to an ID")
 	}
+
+	daemon.configStore = &Config{CommonConfig: CommonConfig{MinIDPrefixLength: 12}}
+	if _, err := daemon.GetContainer("75fb0b8009"); err == nil {
+		t.Fatal("Should return an error when provided a prefix shorter than the minimum length")
+	}
+	if container, _ := daemon.GetContainer("75fb0b800922"); container != c4 {
+		t.Fatal("Should match a partial ID of the minimum length")
+	}
+	if container, _ := daemon.GetContainer("3cdbd1aa"); container != c3 {
+		t.Fatal("Should match a full name shorter than the minimum length")
+	}
 }
 
 func initDaemonWithVolumeStore(tmp string) (*Daemon, error) {
@@ -364,6 +381,127 @@ func TestDaemonReloadNotAffectOthers(t *testing.T) {
 	}
 }
 
+func TestShutdownTimeout(t *testing.T) {
+	stopTimeout := 60
+	daemon := &Daemon{
+		containers:  container.NewMemoryStore(),
+		configStore: &Config{CommonConfig: CommonConfig{ShutdownTimeout: 15}},
+	}
+	for _, tc := range []struct {
+		id      string
+		running bool
+		timeout *int
+	}{
+		{"running", true, nil},
+		{"stopped", false, &stopTimeout},
+	} {
+		c := container.NewBaseContainer(tc.id, "")
+		c.Config = &containertypes.Config{StopTimeout: tc.timeout}
+		c.Running = tc.running
+		daemon.containers.Add(c.ID, c)
+	}
+
+	if timeout := daemon.ShutdownTimeout(); timeout != 15+shutdownGracePeriod {
+		t.Fatalf("expected the shutdown timeout of the daemon, got %d", timeout)
+	}
+
+	c := container.NewBaseContainer("slow", "")
+	c.Config = &containertypes.Config{StopTimeout: &stopTimeout}
+	c.Running = true
+	daemon.containers.Add(c.ID, c)
+	if timeout := daemon.ShutdownTimeout(); timeout != 60+shutdownGracePeriod {
+		t.Fatalf("expected the stop timeout of the slowest container, got %d", timeout)
+	}
+
+	daemon.configStore.ShutdownTimeout = -1
+	if timeout := daemon.ShutdownTimeout(); timeout != -1 {
+		t.Fatalf("expected to wait for the containers, got %d", timeout)
+	}
+}
+
+func TestShutdownContainers(t *testing.T) {
+	defer func(parallelism int) {
+		shutdownParallelism = parallelism
+	}(shutdownParallelism)
+	shutdownParallelism = 2
+
+	daemon := &Daemon{containers: container.NewMemoryStore()}
+	for i := 0; i < 10; i++ {
+		c := container.NewBaseContainer(fmt.Sprintf("container-%d", i), "")
+		c.Running = i != 0
+		daemon.containers.Add(c.ID, c)
+	}
+
+	var (
+		mu               sync.Mutex
+		running, maxSeen int
+		stopped          = make(map[string]bool)
+	)
+	daemon.shutdownContainers(func(c *container.Container) {
+		mu.Lock()
+		running++
+		if running > maxSeen {
+			maxSeen = running
+		}
+		stopped[c.ID] = true
+		mu.Unlock()
+
+		time.Sleep(10 * time.Millisecond)
+
+		mu.Lock()
+		running--
+		mu.Unlock()
+	})
+
+	if len(stopped) != 9 || stopped["container-0"] {
+		t.Fatalf("expected the 9 running containers to be stopped, got %v", stopped)
+	}
+	if maxSeen > 2 {
+		t.Fatalf("expected at most 2 containers to be stopped at once, got %d", maxSeen)
+	}
+}
+
+func TestDaemonReloadRegistries(t *testing.T) {
+	daemon := &Daemon{RegistryService: registry.NewService(registry.ServiceOptions{V2Only: true})}
+	daemon.configStore = &Config{
+		CommonConfig: CommonConfig{
+			ServiceOptions: registry.ServiceOptions{V2Only: true},
+		},
+	}
+
+	newConfig := &Config{
+		CommonConfig: CommonConfig{
+			ServiceOptions: registry.ServiceOptions{
+				Mirrors:            []string{"https://mirror.example.com"},
+				InsecureRegistries: []string{"registry.example.com:5000"},
+			},
+			valuesSet: map[string]interface{}{
+				"registry-mirrors":    []string{"https://mirror.example.com"},
+				"insecure-registries": []string{"registry.example.com:5000"},
+			},
+		},
+	}
+	if err := daemon.Reload(newConfig); err != nil {
+		t.Fatal(err)
+	}
+
+	serviceConfig := daemon.RegistryService.ServiceConfig()
+	if !reflect.DeepEqual(serviceConfig.Mirrors, []string{"https://mirror.example.com/"}) {
+		t.Fatalf("expected the mirror https://mirror.example.com/, got %v", serviceConfig.Mirrors)
+	}
+	if index, ok := serviceConfig.IndexConfigs["registry.example.com:5000"]; !ok || index.Secure {
+		t.Fatalf("expected registry.example.com:5000 to be insecure, got %v", serviceConfig.IndexConfigs)
+	}
+	if !daemon.configStore.V2Only {
+		t.Fatal("expected the options which are not reloadable to be kept")
+	}
+
+	newConfig.Mirrors = []string{"ftp://mirror.example.com"}
+	if err := daemon.Reload(newConfig); err == nil {
+		t.Fatal("expected an error for an invalid mirror")
+	}
+}
+
 func TestDaemonDiscoveryReload(t *testing.T) {
 	daemon := &Daemon{}
 	daemon.configStore = &Config{
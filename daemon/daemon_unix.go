@@ -11,6 +11,7 @@ import (
 	"path/filepath"
 	"runtime"
 	"runtime/debug"
+	"sort"
 	"strconv"
 	"strings"
 	"syscall"
@@ -33,6 +34,7 @@ import (
 	nwconfig "github.com/docker/libnetwork/config"
 	"github.com/docker/libnetwork/drivers/bridge"
 	"github.com/docker/libnetwork/netlabel"
+	"github.com/docker/go-units"
 	"github.com/docker/libnetwork/netutils"
 	"github.com/docker/libnetwork/options"
 	lntypes "github.com/docker/libnetwork/types"
@@ -263,6 +265,31 @@ func (daemon *Daemon) adaptContainerSettings(hostConfig *containertypes.HostConf
 	return nil
 }
 
+// mergeUlimits returns the ulimits of the container followed by copies of
+// the default ulimits of the daemon which the container doesn't override.
+// The defaults are merged each time the container starts and is inspected,
+// so that containers get the current defaults without recording them.
+func (daemon *Daemon) mergeUlimits(hostConfig *containertypes.HostConfig) []*units.Ulimit {
+	ulimits := append([]*units.Ulimit{}, hostConfig.Ulimits...)
+	set := make(map[string]bool)
+	for _, ul := range hostConfig.Ulimits {
+		set[ul.Name] = true
+	}
+
+	var names []string
+	for name := range daemon.configStore.Ulimits {
+		if !set[name] {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		ul := *daemon.configStore.Ulimits[name]
+		ulimits = append(ulimits, &ul)
+	}
+	return ulimits
+}
+
 func verifyContainerResources(resources *containertypes.Resources, sysInfo *sysinfo.SysInfo, update bool) ([]string, error) {
 	warnings := []string{}
 
@@ -591,6 +618,12 @@ func checkSystem() error {
 	if os.Geteuid() != 0 {
 		return fmt.Errorf("The Docker daemon needs to be run as root")
 	}
+	// This is only a check: runc applies the resources of the containers,
+	// and containerd collects their stats, with the cgroup v1 controllers
+	// only, so the containers would fail to start
+	if sysinfo.New(true).CgroupUnified {
+		return fmt.Errorf("The cgroup v2 unified hierarchy is not supported, boot with systemd.unified_cgroup_hierarchy=0 to mount the cgroup v1 controllers")
+	}
 	return checkKernel()
 }
 
@@ -1087,6 +1120,15 @@ func (daemon *Daemon) stats(c *container.Container) (*types.StatsJSON, error) {
 			IoTimeRecursive:         copyBlkioEntry(cgs.BlkioStats.IoTimeRecursive),
 			SectorsRecursive:        copyBlkioEntry(cgs.BlkioStats.SectorsRecursive),
 		}
+		// containerd does not report the stats of the throttling policy
+		if pid := c.GetPID(); pid > 0 {
+			serviceBytes, serviced, err := blkioThrottleStats(pid)
+			if err != nil {
+				logrus.Debugf("Error getting the blkio throttling stats of container %s: %v", c.ID, err)
+			}
+			s.BlkioStats.ThrottleIoServiceBytes = serviceBytes
+			s.BlkioStats.ThrottleIoServiced = serviced
+		}
 		cpu := cgs.CpuStats
 		s.CPUStats = types.CPUStats{
 			CPUUsage: types.CPUUsage{
@@ -1108,6 +1150,14 @@ func (daemon *Daemon) stats(c *container.Container) (*types.StatsJSON, error) {
 			Stats:    cgs.MemoryStats.Stats,
 			Failcnt:  mem.Failcnt,
 			Limit:    mem.Limit,
+			Cache:    memoryStat(cgs.MemoryStats.Stats, "cache"),
+			RSS:      memoryStat(cgs.MemoryStats.Stats, "rss"),
+		}
+		if swap := cgs.MemoryStats.SwapUsage; swap != nil && swap.Usage > mem.Usage {
+			s.MemoryStats.Swap = swap.Usage - mem.Usage
+		}
+		if kernel := cgs.MemoryStats.KernelUsage; kernel != nil {
+			s.MemoryStats.Kernel = kernel.Usage
 		}
 		// if the container does not set memory limit, use the machineMemory
 		if mem.Limit > daemon.statsCollector.machineMemory && daemon.statsCollector.machineMemory > 0 {
@@ -1116,6 +1166,11 @@ func (daemon *Daemon) stats(c *container.Container) (*types.StatsJSON, error) {
 		if cgs.PidsStats != nil {
 			s.PidsStats = types.PidsStats{
 				Current: cgs.PidsStats.Current,
+				Limit:   cgs.PidsStats.Limit,
+			}
+			// containerd may not report the limit of the pids cgroup
+			if s.PidsStats.Limit == 0 && c.HostConfig.PidsLimit > 0 {
+				s.PidsStats.Limit = uint64(c.HostConfig.PidsLimit)
 			}
 		}
 	}
@@ -1123,6 +1178,16 @@ func (daemon *Daemon) stats(c *container.Container) (*types.StatsJSON, error) {
 	return s, nil
 }
 
+// memoryStat returns the value of the memory.stat entry name, including the
+// memory of the child cgroups, like the ones of the execs, when the
+// accounting is hierarchical.
+func memoryStat(stats map[string]uint64, name string) uint64 {
+	if v, ok := stats["total_"+name]; ok {
+		return v
+	}
+	return stats[name]
+}
+
 // setDefaultIsolation determines the default isolation mode for the
 // daemon to run in. This is only applicable on Windows
 func (daemon *Daemon) setDefaultIsolation() error {
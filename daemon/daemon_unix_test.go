@@ -9,6 +9,7 @@ import (
 
 	"github.com/docker/docker/container"
 	containertypes "github.com/docker/engine-api/types/container"
+	"github.com/docker/go-units"
 )
 
 // Unix test as uses settings which are not available on Windows
@@ -89,6 +90,41 @@ func TestAdjustCPUSharesNoAdjustment(t *testing.T) {
 	}
 }
 
+func TestMergeUlimits(t *testing.T) {
+	daemon := &Daemon{
+		configStore: &Config{
+			Ulimits: map[string]*units.Ulimit{
+				"nproc":  {Name: "nproc", Soft: 2048, Hard: 4096},
+				"nofile": {Name: "nofile", Soft: 1024, Hard: 2048},
+			},
+		},
+	}
+
+	hostConfig := &containertypes.HostConfig{
+		Resources: containertypes.Resources{
+			Ulimits: []*units.Ulimit{{Name: "nofile", Soft: 4096, Hard: 8192}},
+		},
+	}
+	ulimits := daemon.mergeUlimits(hostConfig)
+	if len(ulimits) != 2 {
+		t.Fatalf("expected 2 ulimits, got %v", ulimits)
+	}
+	if ul := ulimits[0]; ul.Name != "nofile" || ul.Soft != 4096 || ul.Hard != 8192 {
+		t.Fatalf("expected the nofile ulimit of the container to be kept, got %v", ul)
+	}
+	if ul := ulimits[1]; ul.Name != "nproc" || ul.Soft != 2048 || ul.Hard != 4096 {
+		t.Fatalf("expected the default nproc ulimit, got %v", ul)
+	}
+	if len(hostConfig.Ulimits) != 1 {
+		t.Fatalf("expected the defaults not to be recorded in the host config, got %v", hostConfig.Ulimits)
+	}
+
+	ulimits[1].Soft = 1
+	if daemon.configStore.Ulimits["nproc"].Soft != 2048 {
+		t.Fatal("expected the container to get a copy of the default ulimit")
+	}
+}
+
 // Unix test as uses settings which are not available on Windows
 func TestParseSecurityOptWithDeprecatedColon(t *testing.T) {
 	container := &container.Container{}
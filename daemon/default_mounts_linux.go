@@ -0,0 +1,122 @@
+package daemon
+
+import (
+	"bufio"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/Sirupsen/logrus"
+	"github.com/docker/docker/container"
+	"github.com/docker/docker/pkg/archive"
+	"github.com/docker/docker/volume"
+	"github.com/opencontainers/runc/libcontainer/label"
+)
+
+// defaultMount is a line of the default mounts file of the daemon: a
+// directory of the host whose content is made available in every container.
+type defaultMount struct {
+	Source      string
+	Destination string
+}
+
+// readDefaultMounts reads the source:destination lines of the default mounts
+// file. A missing file defines no mounts, and the lines which are not valid
+// are skipped with a warning, so that one bad entry doesn't keep the
+// containers from starting.
+func readDefaultMounts(file string) ([]defaultMount, error) {
+	f, err := os.Open(file)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var mounts []defaultMount
+	scanner := bufio.NewScanner(f)
+	for n := 1; scanner.Scan(); n++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		parts := strings.Split(line, ":")
+		if len(parts) != 2 || !filepath.IsAbs(parts[0]) || !filepath.IsAbs(parts[1]) {
+			logrus.Warnf("Skipping line %d of the default mounts file %s: expected absolute source:destination paths, got %q", n, file, line)
+			continue
+		}
+		mounts = append(mounts, defaultMount{
+			Source:      filepath.Clean(parts[0]),
+			Destination: filepath.Clean(parts[1]),
+		})
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return mounts, nil
+}
+
+// setupDefaultMounts copies the content of the sources of the default mounts
+// into the root of the container, labeled for the container, and returns the
+// read only mounts of the copies. The copies are made again each time the
+// container starts, so that it sees the current content of the host, such as
+// renewed entitlement certificates, without being able to modify it. A mount
+// or tmpfs of the container at the same destination opts it out. The sources
+// which can't be copied are skipped with a warning.
+func (daemon *Daemon) setupDefaultMounts(c *container.Container) ([]container.Mount, error) {
+	if daemon.configStore.DefaultMountsFile == "" {
+		return nil, nil
+	}
+	defaults, err := readDefaultMounts(daemon.configStore.DefaultMountsFile)
+	if err != nil {
+		return nil, err
+	}
+
+	dir, err := c.DefaultMountsResourcePath()
+	if err != nil {
+		return nil, err
+	}
+	if err := os.RemoveAll(dir); err != nil {
+		return nil, err
+	}
+
+	uidMaps, gidMaps := daemon.GetUIDGIDMaps()
+	archiver := &archive.Archiver{
+		Untar:   archive.Untar,
+		UIDMaps: uidMaps,
+		GIDMaps: gidMaps,
+	}
+
+	var mounts []container.Mount
+	for i, m := range defaults {
+		if _, ok := c.HostConfig.Tmpfs[m.Destination]; ok || c.HasMountFor(m.Destination) {
+			continue
+		}
+		if fi, err := os.Stat(m.Source); err != nil || !fi.IsDir() {
+			logrus.Warnf("Skipping the default mount of %s: not a directory", m.Source)
+			continue
+		}
+
+		// A directory which can't be copied is skipped like a missing
+		// one, rather than keeping the container from starting.
+		copyPath := filepath.Join(dir, strconv.Itoa(i))
+		if err := archiver.CopyWithTar(m.Source, copyPath); err != nil {
+			logrus.Warnf("Skipping the default mount of %s: copying it failed: %v", m.Source, err)
+			os.RemoveAll(copyPath)
+			continue
+		}
+		if err := label.Relabel(copyPath, c.GetMountLabel(), false); err != nil {
+			logrus.Warnf("Skipping the default mount of %s: labeling its copy failed: %v", m.Source, err)
+			os.RemoveAll(copyPath)
+			continue
+		}
+		mounts = append(mounts, container.Mount{
+			Source:      copyPath,
+			Destination: m.Destination,
+			Propagation: volume.DefaultPropagationMode,
+		})
+	}
+	return mounts, nil
+}
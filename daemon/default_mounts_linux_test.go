@@ -0,0 +1,98 @@
+package daemon
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/docker/docker/container"
+	"github.com/docker/docker/volume"
+	containertypes "github.com/docker/engine-api/types/container"
+)
+
+func TestReadDefaultMounts(t *testing.T) {
+	dir, err := ioutil.TempDir("", "docker-default-mounts-")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	if mounts, err := readDefaultMounts(filepath.Join(dir, "missing.conf")); err != nil || mounts != nil {
+		t.Fatalf("expected no mounts for a missing file, got %v, %v", mounts, err)
+	}
+
+	file := filepath.Join(dir, "mounts.conf")
+	content := `# entitlements
+/usr/share/rhel/secrets:/run/secrets
+
+/etc/pki/entitlement/:/run/secrets/etc-pki-entitlement
+relative:/run/relative
+/no/destination
+`
+	if err := ioutil.WriteFile(file, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	mounts, err := readDefaultMounts(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := []defaultMount{
+		{Source: "/usr/share/rhel/secrets", Destination: "/run/secrets"},
+		{Source: "/etc/pki/entitlement", Destination: "/run/secrets/etc-pki-entitlement"},
+	}
+	if !reflect.DeepEqual(mounts, expected) {
+		t.Fatalf("expected %v, got %v", expected, mounts)
+	}
+}
+
+func TestSetupDefaultMounts(t *testing.T) {
+	dir, err := ioutil.TempDir("", "docker-default-mounts-")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	secrets := filepath.Join(dir, "secrets")
+	if err := os.Mkdir(secrets, 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := ioutil.WriteFile(filepath.Join(secrets, "cert.pem"), []byte("cert"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	file := filepath.Join(dir, "mounts.conf")
+	content := secrets + ":/run/secrets\n" + secrets + ":/run/overridden\n" + filepath.Join(dir, "missing") + ":/run/missing\n"
+	if err := ioutil.WriteFile(file, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	root := filepath.Join(dir, "container")
+	if err := os.Mkdir(root, 0700); err != nil {
+		t.Fatal(err)
+	}
+	c := container.NewBaseContainer("0123456789", root)
+	c.HostConfig = &containertypes.HostConfig{Tmpfs: map[string]string{"/run/overridden": ""}}
+
+	daemon := &Daemon{configStore: &Config{DefaultMountsFile: file}}
+	mounts, err := daemon.setupDefaultMounts(c)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := []container.Mount{{
+		Source:      filepath.Join(root, "defaultmounts", "0"),
+		Destination: "/run/secrets",
+		Propagation: volume.DefaultPropagationMode,
+	}}
+	if !reflect.DeepEqual(mounts, expected) {
+		t.Fatalf("expected %v, got %v", expected, mounts)
+	}
+	if b, err := ioutil.ReadFile(filepath.Join(mounts[0].Source, "cert.pem")); err != nil || string(b) != "cert" {
+		t.Fatalf("expected a copy of the certificate, got %q, %v", b, err)
+	}
+
+	daemon.configStore.DefaultMountsFile = ""
+	if mounts, err := daemon.setupDefaultMounts(c); err != nil || mounts != nil {
+		t.Fatalf("expected no mounts without a default mounts file, got %v, %v", mounts, err)
+	}
+}
@@ -115,6 +115,8 @@ func (daemon *Daemon) cleanupContainer(container *container.Container, forceRemo
 			selinuxFreeLxcContexts(container.ProcessLabel)
 			daemon.idIndex.Delete(container.ID)
 			daemon.containers.Delete(container.ID)
+			daemon.archiveUploads.removeContainer(container.ID)
+			container.SetRemoved()
 			daemon.LogContainerEvent(container, "destroy")
 		}
 	}()
@@ -0,0 +1,95 @@
+package daemon
+
+import (
+	"github.com/docker/docker/image"
+	"github.com/docker/docker/layer"
+	"github.com/docker/engine-api/types"
+)
+
+// SystemDiskUsage returns the space used by the layers of the images, by
+// the images listed by default, the writable layers of the containers and
+// the local volumes.
+func (daemon *Daemon) SystemDiskUsage() (*types.DiskUsage, error) {
+	du := &types.DiskUsage{}
+
+	layerSizes := daemon.layerSizes(daemon.imageStore.Map())
+	for _, size := range layerSizes {
+		du.LayersSize += size
+	}
+
+	images, err := daemon.Images("", "", false, 0, 0)
+	if err != nil {
+		return nil, err
+	}
+	imageChainIDs := make([][]layer.ChainID, len(images))
+	layerRefs := make(map[layer.ChainID]int)
+	for i, img := range images {
+		imageChainIDs[i] = daemon.imageChainIDs(image.ID(img.ID))
+		for _, chainID := range imageChainIDs[i] {
+			layerRefs[chainID]++
+		}
+	}
+	containers := daemon.imageContainers()
+	du.Images = make([]*types.ImageDiskUsage, len(images))
+	for i, img := range images {
+		idu := &types.ImageDiskUsage{Image: *img, Containers: containers[image.ID(img.ID)]}
+		for _, chainID := range imageChainIDs[i] {
+			if layerRefs[chainID] > 1 {
+				idu.SharedSize += layerSizes[chainID]
+			}
+		}
+		du.Images[i] = idu
+	}
+
+	du.Containers, err = daemon.Containers(&types.ContainerListOptions{All: true, Size: true})
+	if err != nil {
+		return nil, err
+	}
+
+	volumes, _, err := daemon.volumes.List()
+	if err != nil {
+		return nil, err
+	}
+	du.Volumes = make([]*types.VolumeDiskUsage, len(volumes))
+	for i, v := range volumes {
+		ud := daemon.volumeUsageData(v)
+		vdu := &types.VolumeDiskUsage{
+			Volume:   *volumeToAPIType(v),
+			Size:     ud.Size,
+			RefCount: ud.RefCount,
+		}
+		if vv, ok := v.(interface {
+			CachedPath() string
+		}); ok {
+			vdu.Mountpoint = vv.CachedPath()
+		} else {
+			vdu.Mountpoint = v.Path()
+		}
+		du.Volumes[i] = vdu
+	}
+	return du, nil
+}
+
+// imageChainIDs returns the chain IDs of the layers of the image id, from
+// the top one.
+func (daemon *Daemon) imageChainIDs(id image.ID) []layer.ChainID {
+	img, err := daemon.imageStore.Get(id)
+	if err != nil || img.RootFS == nil {
+		return nil
+	}
+	chainID := img.RootFS.ChainID()
+	if chainID == "" {
+		return nil
+	}
+	l, err := daemon.layerStore.Get(chainID)
+	if err != nil {
+		return nil
+	}
+	defer layer.ReleaseAndLog(daemon.layerStore, l)
+
+	var chainIDs []layer.ChainID
+	for p := l; p != nil; p = p.Parent() {
+		chainIDs = append(chainIDs, p.ChainID())
+	}
+	return chainIDs
+}
@@ -0,0 +1,144 @@
+package daemon
+
+import (
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+
+	"github.com/Sirupsen/logrus"
+	"github.com/docker/docker/container"
+	"github.com/docker/docker/errors"
+	"github.com/docker/engine-api/types"
+)
+
+// errDraining is returned when creating or starting a container while the
+// daemon drains.
+var errDraining = errors.NewErrorWithStatusCode(fmt.Errorf("The daemon is draining: it does not create or start containers"), http.StatusServiceUnavailable)
+
+// IsDraining tells whether the daemon drains, in which case it does not
+// create or start containers.
+func (daemon *Daemon) IsDraining() bool {
+	return atomic.LoadInt32(&daemon.draining) != 0
+}
+
+// Drain makes the daemon refuse to create and start containers, and stops
+// the running containers, the containers which others depend on after them.
+// The containers keep their restart policies for the next start of the
+// daemon, as when the daemon shuts down.
+func (daemon *Daemon) Drain() (*types.DrainReport, error) {
+	atomic.StoreInt32(&daemon.draining, 1)
+	daemon.LogDaemonEventWithAttributes("drain", map[string]string{"status": "started"})
+
+	var running []*container.Container
+	for _, c := range daemon.List() {
+		if c.IsRunning() {
+			running = append(running, c)
+		}
+	}
+
+	report := &types.DrainReport{Containers: []types.DrainedContainer{}}
+	for _, wave := range drainWaves(running, daemon.dependencies) {
+		drained := make([]types.DrainedContainer, len(wave))
+		var wg sync.WaitGroup
+		for i, c := range wave {
+			wg.Add(1)
+			go func(i int, c *container.Container) {
+				defer wg.Done()
+				drained[i] = types.DrainedContainer{ID: c.ID, Name: strings.TrimPrefix(c.Name, "/")}
+				if err := daemon.shutdownContainer(c); err != nil {
+					logrus.Errorf("Error stopping container %s to drain: %v", c.ID, err)
+					drained[i].Error = err.Error()
+				}
+			}(i, c)
+		}
+		wg.Wait()
+		report.Containers = append(report.Containers, drained...)
+	}
+
+	daemon.LogDaemonEventWithAttributes("drain", map[string]string{"status": "completed"})
+	return report, nil
+}
+
+// CancelDrain makes a drained daemon create and start containers again. The
+// containers stopped by the drain are not started again.
+func (daemon *Daemon) CancelDrain() {
+	if atomic.CompareAndSwapInt32(&daemon.draining, 1, 0) {
+		daemon.LogDaemonEventWithAttributes("drain", map[string]string{"status": "canceled"})
+	}
+}
+
+// dependencies returns the containers c depends on to run: the containers
+// it links to, and the containers whose network, IPC or PID namespace it
+// joins.
+func (daemon *Daemon) dependencies(c *container.Container) []*container.Container {
+	var deps []*container.Container
+	for _, child := range daemon.children(c) {
+		deps = append(deps, child)
+	}
+
+	var names []string
+	if c.HostConfig.NetworkMode.IsContainer() {
+		names = append(names, c.HostConfig.NetworkMode.ConnectedContainer())
+	}
+	if c.HostConfig.IpcMode.IsContainer() {
+		names = append(names, c.HostConfig.IpcMode.Container())
+	}
+	if c.HostConfig.PidMode.IsContainer() {
+		names = append(names, c.HostConfig.PidMode.Container())
+	}
+	for _, name := range names {
+		if dep, err := daemon.GetContainer(name); err == nil {
+			deps = append(deps, dep)
+		}
+	}
+	return deps
+}
+
+// drainWaves orders containers into the waves to stop them in: no container
+// of a wave depends on the containers of the next waves. The containers of
+// a dependency cycle are stopped in the same wave.
+func drainWaves(containers []*container.Container, dependencies func(*container.Container) []*container.Container) [][]*container.Container {
+	remaining := make(map[string]*container.Container, len(containers))
+	for _, c := range containers {
+		remaining[c.ID] = c
+	}
+
+	var waves [][]*container.Container
+	for len(remaining) > 0 {
+		needed := make(map[string]bool)
+		for _, c := range remaining {
+			for _, dep := range dependencies(c) {
+				if dep.ID != c.ID {
+					needed[dep.ID] = true
+				}
+			}
+		}
+
+		var wave []*container.Container
+		for id, c := range remaining {
+			if !needed[id] {
+				wave = append(wave, c)
+			}
+		}
+		if len(wave) == 0 {
+			for _, c := range remaining {
+				wave = append(wave, c)
+			}
+		}
+		sort.Sort(byContainerName(wave))
+		for _, c := range wave {
+			delete(remaining, c.ID)
+		}
+		waves = append(waves, wave)
+	}
+	return waves
+}
+
+type byContainerName []*container.Container
+
+func (r byContainerName) Len() int           { return len(r) }
+func (r byContainerName) Swap(i, j int)      { r[i], r[j] = r[j], r[i] }
+func (r byContainerName) Less(i, j int) bool { return r[i].Name < r[j].Name }
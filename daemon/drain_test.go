@@ -0,0 +1,65 @@
+package daemon
+
+import (
+	"testing"
+
+	"github.com/docker/docker/container"
+)
+
+func TestDrainWaves(t *testing.T) {
+	containers := make(map[string]*container.Container)
+	for _, name := range []string{"db", "cache", "app", "proxy", "sidecar", "a", "b"} {
+		containers[name] = &container.Container{CommonContainer: container.CommonContainer{ID: name + "-id", Name: "/" + name}}
+	}
+	deps := map[string][]string{
+		"app":     {"db", "cache"},
+		"proxy":   {"app"},
+		"sidecar": {"app", "sidecar"},
+		// a cycle
+		"a": {"b"},
+		"b": {"a"},
+	}
+	dependencies := func(c *container.Container) []*container.Container {
+		var d []*container.Container
+		for _, name := range deps[c.Name[1:]] {
+			d = append(d, containers[name])
+		}
+		return d
+	}
+
+	var all []*container.Container
+	for _, c := range containers {
+		all = append(all, c)
+	}
+	// db is not running: it is not stopped
+	for i, c := range all {
+		if c.Name == "/db" {
+			all = append(all[:i], all[i+1:]...)
+			break
+		}
+	}
+
+	var got [][]string
+	for _, wave := range drainWaves(all, dependencies) {
+		var names []string
+		for _, c := range wave {
+			names = append(names, c.Name[1:])
+		}
+		got = append(got, names)
+	}
+
+	expected := [][]string{{"proxy", "sidecar"}, {"app"}, {"cache"}, {"a", "b"}}
+	if len(got) != len(expected) {
+		t.Fatalf("expected waves %v, got %v", expected, got)
+	}
+	for i := range expected {
+		if len(got[i]) != len(expected[i]) {
+			t.Fatalf("expected waves %v, got %v", expected, got)
+		}
+		for j := range expected[i] {
+			if got[i][j] != expected[i][j] {
+				t.Fatalf("expected waves %v, got %v", expected, got)
+			}
+		}
+	}
+}
@@ -2,33 +2,48 @@ package daemon
 
 import (
 	"fmt"
+	"net/http"
+	"strconv"
 	"strings"
 
 	"github.com/docker/docker/errors"
 	"github.com/docker/docker/reference"
+	"github.com/docker/go-units"
 )
 
 func (d *Daemon) imageNotExistToErrcode(err error) error {
 	if dne, isDNE := err.(ErrImageDoesNotExist); isDNE {
 		if strings.Contains(dne.RefOrID, "@") {
-			e := fmt.Errorf("No such image: %s", dne.RefOrID)
-			return errors.NewRequestNotFoundError(e)
+			return errNoSuchImage(dne.RefOrID)
 		}
 		tag := reference.DefaultTag
 		ref, err := reference.ParseNamed(dne.RefOrID)
 		if err != nil {
-			e := fmt.Errorf("No such image: %s:%s", dne.RefOrID, tag)
-			return errors.NewRequestNotFoundError(e)
+			return errNoSuchImage(dne.RefOrID + ":" + tag)
 		}
 		if tagged, isTagged := ref.(reference.NamedTagged); isTagged {
 			tag = tagged.Tag()
 		}
-		e := fmt.Errorf("No such image: %s:%s", ref.Name(), tag)
-		return errors.NewRequestNotFoundError(e)
+		return errNoSuchImage(ref.Name() + ":" + tag)
 	}
 	return err
 }
 
+func errNoSuchImage(name string) error {
+	err := fmt.Errorf("No such image: %s", name)
+	return errors.NewErrorWithCode(err, http.StatusNotFound, errors.CodeNoSuchImage, map[string]string{"image": name})
+}
+
+func errNoSuchContainer(name string) error {
+	err := fmt.Errorf("No such container: %s", name)
+	return errors.NewErrorWithCode(err, http.StatusNotFound, errors.CodeNoSuchContainer, map[string]string{"container": name})
+}
+
+func errImageBlocked(refOrID string, err error) error {
+	err = fmt.Errorf("Image %s is blocked by the image policy of the daemon: %v", refOrID, err)
+	return errors.NewErrorWithCode(err, http.StatusForbidden, errors.CodeImageBlocked, map[string]string{"image": refOrID})
+}
+
 type errNotRunning struct {
 	containerID string
 }
@@ -48,7 +63,47 @@ func errContainerIsRestarting(containerID string) error {
 
 func errExecNotFound(id string) error {
 	err := fmt.Errorf("No such exec instance '%s' found in daemon", id)
-	return errors.NewRequestNotFoundError(err)
+	return errors.NewErrorWithCode(err, http.StatusNotFound, errors.CodeNoSuchExec, map[string]string{"exec": id})
+}
+
+func errArchiveUploadNotFound(id string) error {
+	err := fmt.Errorf("No such archive upload: %s", id)
+	return errors.NewErrorWithCode(err, http.StatusNotFound, errors.CodeNoSuchUpload, map[string]string{"upload": id})
+}
+
+func errArchiveUploadOffset(id string, offset int64) error {
+	err := fmt.Errorf("Conflict: archive upload %s is at offset %d", id, offset)
+	return errors.NewErrorWithCode(err, http.StatusConflict, errors.CodeUploadOffsetMismatch, map[string]string{"upload": id, "offset": strconv.FormatInt(offset, 10)})
+}
+
+func errArchiveUploadBusy(id string) error {
+	err := fmt.Errorf("Conflict: a chunk of archive upload %s is being written", id)
+	return errors.NewErrorWithCode(err, http.StatusConflict, errors.CodeUploadBusy, map[string]string{"upload": id})
+}
+
+func errArchiveUploadTooLarge(id string, limit int64) error {
+	err := fmt.Errorf("Archive upload %s is larger than the limit of %s", id, units.BytesSize(float64(limit)))
+	return errors.NewErrorWithCode(err, http.StatusRequestEntityTooLarge, errors.CodeRequestTooLarge, map[string]string{"upload": id})
+}
+
+func errArchiveUploadsFull(limit int64) error {
+	err := fmt.Errorf("The archive uploads in progress use the limit of %s", units.BytesSize(float64(limit)))
+	return errors.NewErrorWithStatusCode(err, http.StatusInsufficientStorage)
+}
+
+func errTooManyArchiveUploads(limit int) error {
+	err := fmt.Errorf("Too many archive uploads in progress, the limit is %d", limit)
+	return errors.NewErrorWithStatusCode(err, http.StatusTooManyRequests)
+}
+
+func errExecStatsNotAvailable(id string) error {
+	err := fmt.Errorf("The resource usage of exec instance '%s' is not accounted", id)
+	return errors.NewRequestConflictError(err)
+}
+
+func errExecNoTTY(id string) error {
+	err := fmt.Errorf("Exec instance '%s' has no TTY to resize", id)
+	return errors.NewRequestConflictError(err)
 }
 
 func errExecPaused(id string) error {
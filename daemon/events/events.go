@@ -16,26 +16,62 @@ const (
 // Events is pubsub channel for events generated by the engine.
 type Events struct {
 	mu     sync.Mutex
-	events []eventtypes.Message
+	events *eventRing
 	pub    *pubsub.Publisher
+	log    *eventsLog
 }
 
 // New returns new *Events instance
 func New() *Events {
 	return &Events{
-		events: make([]eventtypes.Message, 0, eventsLimit),
+		events: newEventRing(eventsLimit),
 		pub:    pubsub.NewPublisher(100*time.Millisecond, bufferSize),
 	}
 }
 
+// NewPersistent returns a new *Events instance which keeps the last limit
+// events in the file at path, and loads the events kept there by the
+// previous instance, so that they are replayed after a restart.
+func NewPersistent(path string, limit int) (*Events, error) {
+	l, events, err := openEventsLog(path, limit)
+	if err != nil {
+		return nil, err
+	}
+	size := eventsLimit
+	if limit > size {
+		size = limit
+	}
+	e := &Events{
+		events: newEventRing(size),
+		pub:    pubsub.NewPublisher(100*time.Millisecond, bufferSize),
+		log:    l,
+	}
+	for _, m := range events {
+		e.events.add(m)
+	}
+	return e, nil
+}
+
+// Close writes the pending events to the file the events are kept in, if
+// any, and closes it. The events logged afterwards are only kept in memory.
+func (e *Events) Close() error {
+	e.mu.Lock()
+	l := e.log
+	e.log = nil
+	e.mu.Unlock()
+	if l == nil {
+		return nil
+	}
+	return l.close()
+}
+
 // Subscribe adds new listener to events, returns slice of 64 stored
 // last events, a channel in which you can expect new events (in form
 // of interface{}, so you need type assertion), and a function to call
 // to stop the stream of events.
 func (e *Events) Subscribe() ([]eventtypes.Message, chan interface{}, func()) {
 	e.mu.Lock()
-	current := make([]eventtypes.Message, len(e.events))
-	copy(current, e.events)
+	current := e.events.all()
 	l := e.pub.Subscribe()
 	e.mu.Unlock()
 
@@ -99,12 +135,9 @@ func (e *Events) Log(action, eventType string, actor eventtypes.Actor) {
 	}
 
 	e.mu.Lock()
-	if len(e.events) == cap(e.events) {
-		// discard oldest event
-		copy(e.events, e.events[1:])
-		e.events[len(e.events)-1] = jm
-	} else {
-		e.events = append(e.events, jm)
+	e.events.add(jm)
+	if e.log != nil {
+		e.log.queue(jm)
 	}
 	e.mu.Unlock()
 	e.pub.Publish(jm)
@@ -135,8 +168,8 @@ func (e *Events) loadBufferedEvents(since, until time.Time, topic func(interface
 		untilNanoUnix = until.UnixNano()
 	}
 
-	for i := len(e.events) - 1; i >= 0; i-- {
-		ev := e.events[i]
+	for i := e.events.len() - 1; i >= 0; i-- {
+		ev := e.events.at(i)
 
 		if ev.TimeNano < sinceNanoUnix {
 			break
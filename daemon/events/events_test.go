@@ -2,6 +2,9 @@ package events
 
 import (
 	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
 	"testing"
 	"time"
 
@@ -31,8 +34,8 @@ func TestEventsLog(t *testing.T) {
 		if !ok {
 			t.Fatalf("Unexpected type %T", msg)
 		}
-		if len(e.events) != 1 {
-			t.Fatalf("Must be only one event, got %d", len(e.events))
+		if e.events.len() != 1 {
+			t.Fatalf("Must be only one event, got %d", e.events.len())
 		}
 		if jmsg.Status != "test" {
 			t.Fatalf("Status should be test, got %s", jmsg.Status)
@@ -52,8 +55,8 @@ func TestEventsLog(t *testing.T) {
 		if !ok {
 			t.Fatalf("Unexpected type %T", msg)
 		}
-		if len(e.events) != 1 {
-			t.Fatalf("Must be only one event, got %d", len(e.events))
+		if e.events.len() != 1 {
+			t.Fatalf("Must be only one event, got %d", e.events.len())
 		}
 		if jmsg.Status != "test" {
 			t.Fatalf("Status should be test, got %s", jmsg.Status)
@@ -118,8 +121,8 @@ func TestLogEvents(t *testing.T) {
 		}
 		e.Log(action, events.ContainerEventType, actor)
 	}
-	if len(e.events) != eventsLimit {
-		t.Fatalf("Must be %d events, got %d", eventsLimit, len(e.events))
+	if e.events.len() != eventsLimit {
+		t.Fatalf("Must be %d events, got %d", eventsLimit, e.events.len())
 	}
 
 	var msgs []events.Message
@@ -184,7 +187,7 @@ func TestLoadBufferedEvents(t *testing.T) {
 	}
 
 	events := &Events{
-		events: []events.Message{*m1, *m2, *m3},
+		events: testEventRing(*m1, *m2, *m3),
 	}
 
 	since := time.Unix(s, sNano)
@@ -230,7 +233,7 @@ func TestLoadBufferedEventsOnlyFromPast(t *testing.T) {
 	}
 
 	events := &Events{
-		events: []events.Message{*m1, *m2, *m3},
+		events: testEventRing(*m1, *m2, *m3),
 	}
 
 	since := time.Unix(s, sNano)
@@ -262,7 +265,7 @@ func TestIngoreBufferedWhenNoTimes(t *testing.T) {
 	}
 
 	events := &Events{
-		events: []events.Message{*m1, *m2, *m3},
+		events: testEventRing(*m1, *m2, *m3),
 	}
 
 	since := time.Time{}
@@ -273,3 +276,89 @@ func TestIngoreBufferedWhenNoTimes(t *testing.T) {
 		t.Fatalf("expected 0 buffered events, got %q", out)
 	}
 }
+
+func TestEventsPersistent(t *testing.T) {
+	tmpDir, err := ioutil.TempDir("", "events-log")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(tmpDir)
+	path := filepath.Join(tmpDir, "events.log")
+
+	e, err := NewPersistent(path, 100)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 250; i++ {
+		e.Log(fmt.Sprintf("action_%d", i), events.ContainerEventType, events.Actor{ID: "cont"})
+	}
+	if err := e.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	e, err = NewPersistent(path, 100)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer e.Close()
+	if e.events.len() != 100 {
+		t.Fatalf("Must be 100 events, got %d", e.events.len())
+	}
+	if e.events.at(0).Action != "action_150" || e.events.at(99).Action != "action_249" {
+		t.Fatalf("Must be events 150 to 249, got %s to %s", e.events.at(0).Action, e.events.at(99).Action)
+	}
+	if e.log.count > 200 {
+		t.Fatalf("The events log must be compacted, got %d events", e.log.count)
+	}
+
+	buffered, l := e.SubscribeTopic(time.Unix(0, e.events.at(90).TimeNano), time.Time{}, nil)
+	defer e.Evict(l)
+	if len(buffered) < 10 || buffered[len(buffered)-1].Action != "action_249" {
+		t.Fatalf("The events must be replayed after a restart, got %v", buffered)
+	}
+
+	// A line truncated by a crash is skipped
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_APPEND, 0600)
+	if err != nil {
+		t.Fatal(err)
+	}
+	f.WriteString(`{"Type":"contai`)
+	f.Close()
+	e2, err := NewPersistent(path, 100)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer e2.Close()
+	if e2.events.len() != 100 {
+		t.Fatalf("Must be 100 events, got %d", e2.events.len())
+	}
+}
+
+func TestEventRing(t *testing.T) {
+	r := newEventRing(3)
+	for i := 0; i < 5; i++ {
+		r.add(events.Message{Action: fmt.Sprintf("action_%d", i)})
+		expected := i + 1
+		if expected > 3 {
+			expected = 3
+		}
+		if r.len() != expected {
+			t.Fatalf("Must be %d events, got %d", expected, r.len())
+		}
+	}
+	all := r.all()
+	if len(all) != 3 || all[0].Action != "action_2" || all[2].Action != "action_4" {
+		t.Fatalf("Must be events 2 to 4, got %v", all)
+	}
+	if r.at(1).Action != "action_3" {
+		t.Fatalf("Must be event 3, got %s", r.at(1).Action)
+	}
+}
+
+func testEventRing(messages ...events.Message) *eventRing {
+	r := newEventRing(eventsLimit)
+	for _, m := range messages {
+		r.add(m)
+	}
+	return r
+}
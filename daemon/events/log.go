@@ -0,0 +1,160 @@
+package events
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"os"
+
+	"github.com/Sirupsen/logrus"
+	"github.com/docker/docker/pkg/ioutils"
+	eventtypes "github.com/docker/engine-api/types/events"
+)
+
+// logQueueSize is the number of events waiting to be written to the events
+// log above which new events are not kept on disk.
+const logQueueSize = 1024
+
+// eventsLog is the file the last events are kept in, one JSON message per
+// line, so that they can be replayed after the daemon restarts. Events are
+// appended to it, and it is rewritten with the last limit events once it
+// holds twice as many, which bounds its size. The events are written by a
+// goroutine of their own, so that logging an event doesn't wait for the
+// disk.
+type eventsLog struct {
+	path    string
+	limit   int
+	f       *os.File
+	count   int
+	last    *eventRing
+	pending chan eventtypes.Message
+	done    chan struct{}
+}
+
+// openEventsLog opens the events log at path, and returns it with the last
+// limit events it holds, from the oldest one.
+func openEventsLog(path string, limit int) (*eventsLog, []eventtypes.Message, error) {
+	events, err := readEventsLog(path, limit)
+	if err != nil {
+		return nil, nil, err
+	}
+	l := &eventsLog{
+		path:    path,
+		limit:   limit,
+		last:    newEventRing(limit),
+		pending: make(chan eventtypes.Message, logQueueSize),
+		done:    make(chan struct{}),
+	}
+	if err := l.rewrite(events); err != nil {
+		return nil, nil, err
+	}
+	for _, m := range events {
+		l.last.add(m)
+	}
+	go l.run()
+	return l, events, nil
+}
+
+// readEventsLog returns the last limit events of the events log at path.
+// A truncated last line, written while the daemon was killed, is skipped.
+func readEventsLog(path string, limit int) ([]eventtypes.Message, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	defer f.Close()
+
+	var events []eventtypes.Message
+	s := bufio.NewScanner(f)
+	s.Buffer(nil, 1024*1024)
+	for s.Scan() {
+		var m eventtypes.Message
+		if err := json.Unmarshal(s.Bytes(), &m); err != nil {
+			logrus.Warnf("Skipping invalid event in %s: %v", path, err)
+			continue
+		}
+		events = append(events, m)
+		if len(events) > limit {
+			events = events[1:]
+		}
+	}
+	if err := s.Err(); err != nil {
+		return nil, err
+	}
+	return events, nil
+}
+
+// queue queues the event m to be written to the log. The event is dropped if
+// the disk can't keep up with the events.
+func (l *eventsLog) queue(m eventtypes.Message) {
+	select {
+	case l.pending <- m:
+	default:
+		logrus.Warnf("Too many events waiting to be written to %s, dropping event %s %s", l.path, m.Type, m.Action)
+	}
+}
+
+// run writes the queued events until the log is closed.
+func (l *eventsLog) run() {
+	defer close(l.done)
+	for m := range l.pending {
+		l.last.add(m)
+		l.write(m)
+	}
+}
+
+// write appends the event m to the log, and rewrites the log with the last
+// events if it has grown too much.
+func (l *eventsLog) write(m eventtypes.Message) {
+	if l.count >= 2*l.limit {
+		if err := l.rewrite(l.last.all()); err != nil {
+			logrus.Errorf("Error compacting the events log %s: %v", l.path, err)
+		}
+		return
+	}
+	b, err := json.Marshal(m)
+	if err != nil {
+		logrus.Errorf("Error encoding event: %v", err)
+		return
+	}
+	if _, err := l.f.Write(append(b, '\n')); err != nil {
+		logrus.Errorf("Error writing the events log %s: %v", l.path, err)
+		return
+	}
+	l.count++
+}
+
+// rewrite replaces the content of the log with events atomically.
+func (l *eventsLog) rewrite(events []eventtypes.Message) error {
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	for _, m := range events {
+		if err := enc.Encode(m); err != nil {
+			return err
+		}
+	}
+	if err := ioutils.AtomicWriteFile(l.path, buf.Bytes(), 0600); err != nil {
+		return err
+	}
+	f, err := os.OpenFile(l.path, os.O_WRONLY|os.O_APPEND, 0600)
+	if err != nil {
+		return err
+	}
+	if l.f != nil {
+		l.f.Close()
+	}
+	l.f = f
+	l.count = len(events)
+	return nil
+}
+
+// close writes the queued events and closes the log. No event must be
+// queued afterwards.
+func (l *eventsLog) close() error {
+	close(l.pending)
+	<-l.done
+	return l.f.Close()
+}
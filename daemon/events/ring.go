@@ -0,0 +1,46 @@
+package events
+
+import eventtypes "github.com/docker/engine-api/types/events"
+
+// eventRing keeps the last events in a buffer of fixed size, the newest
+// event overwriting the oldest one once the buffer is full.
+type eventRing struct {
+	buf   []eventtypes.Message
+	start int
+	n     int
+}
+
+func newEventRing(size int) *eventRing {
+	return &eventRing{buf: make([]eventtypes.Message, size)}
+}
+
+// add adds m as the newest event, discarding the oldest one if the ring is
+// full.
+func (r *eventRing) add(m eventtypes.Message) {
+	if r.n < len(r.buf) {
+		r.buf[(r.start+r.n)%len(r.buf)] = m
+		r.n++
+		return
+	}
+	r.buf[r.start] = m
+	r.start = (r.start + 1) % len(r.buf)
+}
+
+// len returns the number of events in the ring.
+func (r *eventRing) len() int {
+	return r.n
+}
+
+// at returns the i-th event of the ring, from the oldest one.
+func (r *eventRing) at(i int) eventtypes.Message {
+	return r.buf[(r.start+i)%len(r.buf)]
+}
+
+// all returns a copy of the events of the ring, from the oldest one.
+func (r *eventRing) all() []eventtypes.Message {
+	events := make([]eventtypes.Message, r.n)
+	for i := range events {
+		events[i] = r.at(i)
+	}
+	return events
+}
@@ -1,6 +1,7 @@
 package daemon
 
 import (
+	"encoding/json"
 	"fmt"
 	"io"
 	"strings"
@@ -9,13 +10,16 @@ import (
 	"golang.org/x/net/context"
 
 	"github.com/Sirupsen/logrus"
+	"github.com/docker/docker/api/types/backend"
 	"github.com/docker/docker/container"
 	"github.com/docker/docker/daemon/exec"
 	"github.com/docker/docker/errors"
 	"github.com/docker/docker/libcontainerd"
+	"github.com/docker/docker/pkg/ioutils"
 	"github.com/docker/docker/pkg/pools"
 	"github.com/docker/docker/pkg/signal"
 	"github.com/docker/docker/pkg/term"
+	"github.com/docker/docker/utils"
 	"github.com/docker/engine-api/types"
 	"github.com/docker/engine-api/types/strslice"
 )
@@ -23,6 +27,9 @@ import (
 // Seconds to wait after sending TERM before trying KILL
 const termProcessTimeout = 10
 
+// execStatsInterval is how often the resource usage of an exec is streamed.
+const execStatsInterval = time.Second
+
 func (d *Daemon) registerExecCommand(container *container.Container, config *exec.Config) {
 	// Storing execs in container in order to kill them gracefully whenever the container is stopped or removed.
 	container.ExecCommands.Add(config.ID, config)
@@ -124,6 +131,15 @@ func (d *Daemon) ContainerExecCreate(name string, config *types.ExecConfig) (str
 	if len(execConfig.User) == 0 {
 		execConfig.User = container.Config.User
 	}
+	if len(config.Env) > 0 {
+		// The variables of the exec are added to, or override, the
+		// environment the process of the container was started with.
+		linkedEnv, err := d.setupLinkedContainers(container)
+		if err != nil {
+			return "", err
+		}
+		execConfig.Env = utils.ReplaceOrAppendEnvValues(container.CreateDaemonEnvironment(linkedEnv), config.Env)
+	}
 
 	d.registerExecCommand(container, execConfig)
 
@@ -199,14 +215,20 @@ func (d *Daemon) ContainerExecStart(ctx context.Context, name string, stdin io.R
 	}
 
 	if err := execSetPlatformOpt(c, ec, &p); err != nil {
-		return nil
+		ec.StartErr = err
+		close(ec.Started)
+		return err
 	}
 
 	attachErr := container.AttachStreams(ctx, ec.StreamConfig, ec.OpenStdin, true, ec.Tty, cStdin, cStdout, cStderr, ec.DetachKeys)
 
 	if err := d.containerd.AddProcess(c.ID, name, p); err != nil {
+		ec.StartErr = err
+		close(ec.Started)
 		return err
 	}
+	close(ec.Started)
+	d.setupExecCgroup(c, ec)
 
 	select {
 	case <-ctx.Done():
@@ -231,6 +253,95 @@ func (d *Daemon) ContainerExecStart(ctx context.Context, name string, stdin io.R
 	return nil
 }
 
+// ContainerExecStats writes the resources used by the processes of an exec
+// to the stream given in the config object, every second while the exec runs
+// if config.Stream is true. The stream ends once the exec exits.
+func (d *Daemon) ContainerExecStats(ctx context.Context, id string, config *backend.ExecStatsConfig) error {
+	ec, err := d.getExecConfig(id)
+	if err != nil {
+		return err
+	}
+	stats, running := execStats(ec)
+	if stats == nil {
+		return errExecStatsNotAvailable(id)
+	}
+
+	outStream := config.OutStream
+	if config.Stream {
+		wf := ioutils.NewWriteFlusher(outStream)
+		defer wf.Close()
+		wf.Flush()
+		outStream = wf
+	}
+	enc := json.NewEncoder(outStream)
+
+	ticker := time.NewTicker(execStatsInterval)
+	defer ticker.Stop()
+	for {
+		if err := enc.Encode(stats); err != nil {
+			return err
+		}
+		if !config.Stream || !running {
+			return nil
+		}
+		select {
+		case <-ticker.C:
+		case <-ctx.Done():
+			return nil
+		}
+		if stats, running = execStats(ec); stats == nil {
+			return nil
+		}
+	}
+}
+
+// execStats returns the resources used by the processes of ec, and whether
+// they are still accounted, or nil if they are not accounted.
+func execStats(ec *exec.Config) (*backend.ExecStats, bool) {
+	ec.Lock()
+	defer ec.Unlock()
+
+	stats, running := ec.ExitStats, false
+	if ec.Cgroup != nil {
+		s, err := ec.Cgroup.Stats()
+		if err != nil {
+			logrus.Debugf("Error reading the resource usage of exec %s: %v", ec.ID, err)
+			return nil, false
+		}
+		stats, running = s, true
+	}
+	if stats == nil {
+		return nil, false
+	}
+	return &backend.ExecStats{
+		Read:           stats.Read,
+		CPUUsage:       stats.CPUUsage,
+		MemoryUsage:    stats.MemoryUsage,
+		MemoryMaxUsage: stats.MemoryMaxUsage,
+		Pids:           stats.Pids,
+	}, running
+}
+
+// releaseExecCgroup records the resources used by the processes of ec when
+// it exited, and removes the cgroup they were accounted in.
+func releaseExecCgroup(ec *exec.Config) {
+	ec.Lock()
+	defer ec.Unlock()
+
+	if ec.Cgroup == nil {
+		return
+	}
+	if stats, err := ec.Cgroup.Stats(); err != nil {
+		logrus.Debugf("Error reading the resource usage of exec %s: %v", ec.ID, err)
+	} else {
+		ec.ExitStats = stats
+	}
+	if err := ec.Cgroup.Remove(); err != nil {
+		logrus.Warnf("Error removing the cgroup of exec %s: %v", ec.ID, err)
+	}
+	ec.Cgroup = nil
+}
+
 // execCommandGC runs a ticker to clean up the daemon references
 // of exec configs that are no longer part of the container.
 func (d *Daemon) execCommandGC() {
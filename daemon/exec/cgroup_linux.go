@@ -0,0 +1,261 @@
+package exec
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Sirupsen/logrus"
+	"github.com/opencontainers/runc/libcontainer/cgroups"
+)
+
+// cgroupSubsystems are the cgroup subsystems the processes of an exec are
+// accounted in. The other subsystems only enforce the limits of the
+// container, so the processes are left in the cgroups of the container.
+var cgroupSubsystems = []string{"cpuacct", "memory"}
+
+// procRoot is where the processes are looked up, replaced by the tests.
+var procRoot = "/proc"
+
+// moveAttempts is how many times the children of the process of an exec are
+// looked up to be moved to its cgroup.
+const moveAttempts = 3
+
+// Cgroup is the cgroup the processes of an exec are moved to, under the
+// cgroup of their container, to account for the resources they use apart
+// from the other processes of the container. The limits of the container
+// still apply to them.
+type Cgroup struct {
+	// dirs are the directories of the cgroup by subsystem.
+	dirs map[string]string
+}
+
+// NewCgroup creates the cgroup of the exec with the given id, under the
+// cgroups of the process with the given pid, and moves that process and the
+// processes it started so far to it. The processes they start afterwards
+// are accounted in the cgroup too.
+func NewCgroup(id string, pid int) (*Cgroup, error) {
+	paths, err := cgroups.ParseCgroupFile(fmt.Sprintf("/proc/%d/cgroup", pid))
+	if err != nil {
+		return nil, err
+	}
+
+	c := &Cgroup{dirs: make(map[string]string)}
+	created := make(map[string]bool)
+	for _, subsystem := range cgroupSubsystems {
+		path, ok := paths[subsystem]
+		if !ok {
+			continue
+		}
+		mountpoint, root, err := cgroups.FindCgroupMountpointAndRoot(subsystem)
+		if err != nil {
+			logrus.Debugf("Not accounting exec %s in the %s cgroup: %v", id, subsystem, err)
+			continue
+		}
+		rel, err := filepath.Rel(root, path)
+		if err != nil {
+			return nil, c.removeAfter(err)
+		}
+		parent := filepath.Join(mountpoint, rel)
+
+		// Without hierarchical accounting, the memory limit of the
+		// container would not apply to the processes of the exec.
+		if subsystem == "memory" {
+			if v, err := readCgroupUint(parent, "memory.use_hierarchy"); err != nil || v != 1 {
+				logrus.Debugf("Not accounting exec %s in the memory cgroup: hierarchical accounting is disabled", id)
+				continue
+			}
+		}
+
+		dir := filepath.Join(parent, "exec-"+id)
+		if !created[dir] {
+			if err := os.Mkdir(dir, 0755); err != nil && !os.IsExist(err) {
+				return nil, c.removeAfter(err)
+			}
+			created[dir] = true
+		}
+		c.dirs[subsystem] = dir
+	}
+	if len(c.dirs) == 0 {
+		return nil, fmt.Errorf("no cgroup subsystem to account exec %s in", id)
+	}
+
+	for dir := range created {
+		if err := ioutil.WriteFile(filepath.Join(dir, "cgroup.procs"), []byte(strconv.Itoa(pid)), 0700); err != nil {
+			return nil, c.removeAfter(err)
+		}
+	}
+	moveDescendants(id, pid, created)
+	return c, nil
+}
+
+// moveDescendants moves the processes descending from pid to the cgroup
+// directories dirs. A process forking while they are moved may leave its
+// child behind, so they are looked up again until all of them are moved,
+// up to moveAttempts times.
+func moveDescendants(id string, pid int, dirs map[string]bool) {
+	for i := 0; i < moveAttempts; i++ {
+		pids, err := descendants(pid)
+		if err != nil {
+			logrus.Debugf("Not accounting the children of exec %s: %v", id, err)
+			return
+		}
+		moved := false
+		for dir := range dirs {
+			in, err := cgroups.GetPids(dir)
+			if err != nil {
+				logrus.Debugf("Not accounting the children of exec %s: %v", id, err)
+				return
+			}
+			inCgroup := make(map[int]bool, len(in))
+			for _, p := range in {
+				inCgroup[p] = true
+			}
+			for _, p := range pids {
+				if inCgroup[p] {
+					continue
+				}
+				// The process may have exited in the meantime.
+				if err := ioutil.WriteFile(filepath.Join(dir, "cgroup.procs"), []byte(strconv.Itoa(p)), 0700); err != nil {
+					logrus.Debugf("Error moving process %d of exec %s to %s: %v", p, id, dir, err)
+				}
+				moved = true
+			}
+		}
+		if !moved {
+			return
+		}
+	}
+}
+
+// descendants returns the pids of the processes descending from pid.
+func descendants(pid int) ([]int, error) {
+	entries, err := ioutil.ReadDir(procRoot)
+	if err != nil {
+		return nil, err
+	}
+	children := make(map[int][]int)
+	for _, e := range entries {
+		p, err := strconv.Atoi(e.Name())
+		if err != nil {
+			continue
+		}
+		stat, err := ioutil.ReadFile(filepath.Join(procRoot, e.Name(), "stat"))
+		if err != nil {
+			// the process exited
+			continue
+		}
+		ppid, err := parseParentPid(string(stat))
+		if err != nil {
+			return nil, err
+		}
+		children[ppid] = append(children[ppid], p)
+	}
+
+	var pids []int
+	for queue := []int{pid}; len(queue) > 0; queue = queue[1:] {
+		for _, child := range children[queue[0]] {
+			pids = append(pids, child)
+			queue = append(queue, child)
+		}
+	}
+	return pids, nil
+}
+
+// parseParentPid returns the pid of the parent in the content of the stat
+// file of a process. The name of the command comes before it, in
+// parentheses, and may hold spaces and parentheses itself.
+func parseParentPid(stat string) (int, error) {
+	i := strings.LastIndex(stat, ")")
+	if i < 0 {
+		return 0, fmt.Errorf("invalid process stat %q", stat)
+	}
+	fields := strings.Fields(stat[i+1:])
+	if len(fields) < 2 {
+		return 0, fmt.Errorf("invalid process stat %q", stat)
+	}
+	return strconv.Atoi(fields[1])
+}
+
+// Stats returns the resources used by the processes of the exec.
+func (c *Cgroup) Stats() (*Stats, error) {
+	s := &Stats{Read: time.Now()}
+	if dir, ok := c.dirs["cpuacct"]; ok {
+		v, err := readCgroupUint(dir, "cpuacct.usage")
+		if err != nil {
+			return nil, err
+		}
+		s.CPUUsage = v
+	}
+	if dir, ok := c.dirs["memory"]; ok {
+		v, err := readCgroupUint(dir, "memory.usage_in_bytes")
+		if err != nil {
+			return nil, err
+		}
+		s.MemoryUsage = v
+		if v, err = readCgroupUint(dir, "memory.max_usage_in_bytes"); err != nil {
+			return nil, err
+		}
+		s.MemoryMaxUsage = v
+	}
+	for _, dir := range c.dirs {
+		pids, err := cgroups.GetPids(dir)
+		if err != nil {
+			return nil, err
+		}
+		s.Pids = len(pids)
+		break
+	}
+	return s, nil
+}
+
+// Remove moves the processes left in the cgroup, like daemons started by the
+// exec, back to the cgroup of their container and removes the cgroup.
+func (c *Cgroup) Remove() error {
+	var errs []string
+	for _, dir := range c.dirs {
+		if _, err := os.Stat(dir); os.IsNotExist(err) {
+			// Co-mounted subsystems share the directory, or the
+			// container removed its cgroups first.
+			continue
+		}
+		pids, err := cgroups.GetPids(dir)
+		if err != nil {
+			errs = append(errs, err.Error())
+			continue
+		}
+		for _, pid := range pids {
+			if err := ioutil.WriteFile(filepath.Join(filepath.Dir(dir), "cgroup.procs"), []byte(strconv.Itoa(pid)), 0700); err != nil {
+				logrus.Debugf("Error moving process %d out of %s: %v", pid, dir, err)
+			}
+		}
+		if err := os.Remove(dir); err != nil && !os.IsNotExist(err) {
+			errs = append(errs, err.Error())
+		}
+	}
+	if len(errs) > 0 {
+		return fmt.Errorf("error removing exec cgroup: %s", strings.Join(errs, ", "))
+	}
+	return nil
+}
+
+// removeAfter removes the directories created so far by NewCgroup after err,
+// and returns err.
+func (c *Cgroup) removeAfter(err error) error {
+	for _, dir := range c.dirs {
+		os.Remove(dir)
+	}
+	return err
+}
+
+func readCgroupUint(dir, file string) (uint64, error) {
+	b, err := ioutil.ReadFile(filepath.Join(dir, file))
+	if err != nil {
+		return 0, err
+	}
+	return strconv.ParseUint(strings.TrimSpace(string(b)), 10, 64)
+}
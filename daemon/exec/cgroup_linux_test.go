@@ -0,0 +1,85 @@
+package exec
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"testing"
+)
+
+func TestCgroupStats(t *testing.T) {
+	dir, err := ioutil.TempDir("", "exec-cgroup")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	for file, content := range map[string]string{
+		"cpuacct.usage":             "123456789\n",
+		"memory.usage_in_bytes":     "4096\n",
+		"memory.max_usage_in_bytes": "8192\n",
+		"cgroup.procs":              "10\n11\n",
+	} {
+		if err := ioutil.WriteFile(filepath.Join(dir, file), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	c := &Cgroup{dirs: map[string]string{"cpuacct": dir, "memory": dir}}
+	s, err := c.Stats()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s.CPUUsage != 123456789 || s.MemoryUsage != 4096 || s.MemoryMaxUsage != 8192 || s.Pids != 2 {
+		t.Fatalf("unexpected stats: %+v", s)
+	}
+
+	// Without the memory subsystem, only the CPU usage is accounted.
+	c = &Cgroup{dirs: map[string]string{"cpuacct": dir}}
+	if s, err = c.Stats(); err != nil {
+		t.Fatal(err)
+	}
+	if s.CPUUsage != 123456789 || s.MemoryUsage != 0 {
+		t.Fatalf("unexpected stats: %+v", s)
+	}
+}
+
+func TestDescendants(t *testing.T) {
+	dir, err := ioutil.TempDir("", "exec-proc")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	defer func(root string) { procRoot = root }(procRoot)
+	procRoot = dir
+
+	for pid, stat := range map[string]string{
+		"1":  "1 (init) S 0 1 1 0",
+		"10": "10 (sh) S 1 10 10 0",
+		"11": "11 (sleep) S 10 10 10 0",
+		"12": "12 (my (odd) cmd) S 11 10 10 0",
+		"13": "13 (other) S 1 13 13 0",
+	} {
+		if err := os.Mkdir(filepath.Join(dir, pid), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := ioutil.WriteFile(filepath.Join(dir, pid, "stat"), []byte(stat), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// not a process
+	if err := os.Mkdir(filepath.Join(dir, "self"), 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	pids, err := descendants(10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sort.Ints(pids)
+	if expected := []int{11, 12}; !reflect.DeepEqual(pids, expected) {
+		t.Fatalf("expected the descendants %v, got %v", expected, pids)
+	}
+}
@@ -0,0 +1,25 @@
+// +build !linux
+
+package exec
+
+import "errors"
+
+// Cgroup is the cgroup the processes of an exec are accounted in, which is
+// only supported on Linux.
+type Cgroup struct{}
+
+// NewCgroup returns an error, since exec cgroups are only supported on
+// Linux.
+func NewCgroup(id string, pid int) (*Cgroup, error) {
+	return nil, errors.New("exec cgroups are not supported on this platform")
+}
+
+// Stats returns the resources used by the processes of the exec.
+func (c *Cgroup) Stats() (*Stats, error) {
+	return &Stats{}, nil
+}
+
+// Remove removes the cgroup.
+func (c *Cgroup) Remove() error {
+	return nil
+}
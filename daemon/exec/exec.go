@@ -2,6 +2,7 @@ package exec
 
 import (
 	"sync"
+	"time"
 
 	"github.com/docker/docker/pkg/stringid"
 	"github.com/docker/docker/runconfig"
@@ -27,6 +28,35 @@ type Config struct {
 	Tty         bool
 	Privileged  bool
 	User        string
+	// Env is the environment of the exec, or nil for the one of the
+	// container.
+	Env []string
+	// Started is closed once the process of the exec is started, or once
+	// it failed to start, StartErr then holding the error.
+	Started  chan struct{}
+	StartErr error
+
+	// Cgroup accounts for the resources used by the processes of the
+	// exec while it runs. It is nil if they are not accounted apart from
+	// the other processes of the container.
+	Cgroup *Cgroup
+	// ExitStats are the resources used by the processes of the exec when
+	// it exited.
+	ExitStats *Stats
+}
+
+// Stats holds the resources used by the processes of an exec.
+type Stats struct {
+	// Read is when the stats were read.
+	Read time.Time
+	// CPUUsage is the CPU time consumed, in nanoseconds.
+	CPUUsage uint64
+	// MemoryUsage and MemoryMaxUsage are the current and maximum memory
+	// usage, in bytes.
+	MemoryUsage    uint64
+	MemoryMaxUsage uint64
+	// Pids is the number of processes running.
+	Pids int
 }
 
 // NewConfig initializes the a new exec configuration
@@ -34,6 +64,7 @@ func NewConfig() *Config {
 	return &Config{
 		ID:           stringid.GenerateNonCryptoID(),
 		StreamConfig: runconfig.NewStreamConfig(),
+		Started:      make(chan struct{}),
 	}
 }
 
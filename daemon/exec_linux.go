@@ -1,6 +1,7 @@
 package daemon
 
 import (
+	"github.com/Sirupsen/logrus"
 	"github.com/docker/docker/container"
 	"github.com/docker/docker/daemon/caps"
 	"github.com/docker/docker/daemon/exec"
@@ -22,5 +23,34 @@ func execSetPlatformOpt(c *container.Container, ec *exec.Config, p *libcontainer
 	if ec.Privileged {
 		p.Capabilities = caps.GetAllCapabilities()
 	}
+	p.Env = ec.Env
 	return nil
 }
+
+// setupExecCgroup moves the processes of ec to a cgroup of their own under
+// the cgroup of c, so that the resources they use can be told apart from the
+// ones of the other processes of the container.
+func (d *Daemon) setupExecCgroup(c *container.Container, ec *exec.Config) {
+	pid, err := d.containerd.GetPidForProcess(c.ID, ec.ID)
+	if err != nil {
+		logrus.Debugf("Not accounting the resource usage of exec %s: %v", ec.ID, err)
+		return
+	}
+
+	// The exec exited already if it is no longer in the store of the
+	// container, and the pid may belong to another process.
+	c.Lock()
+	defer c.Unlock()
+	if c.ExecCommands.Get(ec.ID) == nil {
+		return
+	}
+
+	cg, err := exec.NewCgroup(ec.ID, pid)
+	if err != nil {
+		logrus.Debugf("Not accounting the resource usage of exec %s: %v", ec.ID, err)
+		return
+	}
+	ec.Lock()
+	ec.Cgroup = cg
+	ec.Unlock()
+}
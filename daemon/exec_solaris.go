@@ -9,3 +9,8 @@ import (
 func execSetPlatformOpt(c *container.Container, ec *exec.Config, p *libcontainerd.Process) error {
 	return nil
 }
+
+// setupExecCgroup is a no-op, since the resources used by execs are not
+// accounted apart from the ones of their container on this platform.
+func (d *Daemon) setupExecCgroup(c *container.Container, ec *exec.Config) {
+}
@@ -9,5 +9,11 @@ import (
 func execSetPlatformOpt(c *container.Container, ec *exec.Config, p *libcontainerd.Process) error {
 	// Process arguments need to be escaped before sending to OCI.
 	p.Args = escapeArgs(p.Args)
+	p.Env = ec.Env
 	return nil
 }
+
+// setupExecCgroup is a no-op, since the resources used by execs are not
+// accounted apart from the ones of their container on this platform.
+func (d *Daemon) setupExecCgroup(c *container.Container, ec *exec.Config) {
+}
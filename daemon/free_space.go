@@ -0,0 +1,92 @@
+package daemon
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/Sirupsen/logrus"
+	"github.com/docker/docker/distribution"
+	"github.com/docker/docker/errors"
+	"github.com/docker/docker/layer"
+	"github.com/docker/docker/reference"
+	"github.com/docker/engine-api/types"
+	"github.com/docker/go-units"
+	"golang.org/x/net/context"
+)
+
+// freeSpaceThreshold is the free space to keep on the filesystem of the root
+// directory, either a size or a percentage of the filesystem.
+type freeSpaceThreshold struct {
+	size    int64
+	percent float64
+}
+
+// bytes returns the free space to keep on a filesystem of the given size.
+func (t *freeSpaceThreshold) bytes(total uint64) uint64 {
+	if t.percent != 0 {
+		return uint64(float64(total) * t.percent / 100)
+	}
+	return uint64(t.size)
+}
+
+// CheckFreeSpace returns an error if doing action, which needs about
+// projected bytes, would leave less than the min-free-space of the daemon
+// free on the filesystem of its root directory.
+func (daemon *Daemon) CheckFreeSpace(action string, projected int64) error {
+	threshold, err := daemon.configStore.minFreeSpace()
+	if err != nil || threshold == nil {
+		return err
+	}
+	free, total, err := diskSpace(daemon.configStore.Root)
+	if err != nil {
+		logrus.Debugf("Cannot check the free space to %s: %v", action, err)
+		return nil
+	}
+	return checkFreeSpace(action, daemon.configStore.Root, free, total, uint64(projected), threshold)
+}
+
+func checkFreeSpace(action, root string, free, total, projected uint64, threshold *freeSpaceThreshold) error {
+	keep := threshold.bytes(total)
+	if free >= projected && free-projected >= keep {
+		return nil
+	}
+	msg := fmt.Sprintf("Not enough free space in %s to %s: %s free", root, action, units.BytesSize(float64(free)))
+	if projected > 0 {
+		msg += fmt.Sprintf(", about %s needed", units.BytesSize(float64(projected)))
+	}
+	msg += fmt.Sprintf(" and %s to keep free. Free some space, or use --ignore-free-space to override", units.BytesSize(float64(keep)))
+	return errors.NewErrorWithStatusCode(fmt.Errorf("%s", msg), http.StatusInsufficientStorage)
+}
+
+// projectedPullSize returns about how much space pulling ref takes: twice
+// the compressed size of its layers which are not in the layer store yet,
+// for the downloaded layers and their extracted content. It returns 0 if it
+// is unknown, like when pulling all the tags of a repository.
+func (daemon *Daemon) projectedPullSize(ctx context.Context, ref reference.Named, platform distribution.Platform, metaHeaders map[string][]string, authConfig *types.AuthConfig) int64 {
+	if reference.IsNameOnly(ref) {
+		return 0
+	}
+	img, err := distribution.Inspect(ctx, ref, &distribution.ImageInspectConfig{
+		MetaHeaders:     metaHeaders,
+		AuthConfig:      authConfig,
+		RegistryService: daemon.RegistryService,
+		Platform:        platform,
+	})
+	if err != nil {
+		logrus.Debugf("Cannot project the size of the pull of %s: %v", ref.String(), err)
+		return 0
+	}
+
+	var size int64
+	for i, layerSize := range img.LayerSizes {
+		if img.Image.RootFS != nil && len(img.Image.RootFS.DiffIDs) == len(img.LayerSizes) {
+			chainID := layer.CreateChainID(img.Image.RootFS.DiffIDs[:i+1])
+			if l, err := daemon.layerStore.Get(chainID); err == nil {
+				layer.ReleaseAndLog(daemon.layerStore, l)
+				continue
+			}
+		}
+		size += layerSize
+	}
+	return 2 * size
+}
@@ -0,0 +1,13 @@
+package daemon
+
+import "syscall"
+
+// diskSpace returns the free and total space of the filesystem of path, in
+// bytes. The free space is the one available to unprivileged users.
+func diskSpace(path string) (uint64, uint64, error) {
+	var stat syscall.Statfs_t
+	if err := syscall.Statfs(path, &stat); err != nil {
+		return 0, 0, err
+	}
+	return stat.Bavail * uint64(stat.Bsize), stat.Blocks * uint64(stat.Bsize), nil
+}
@@ -0,0 +1,58 @@
+package daemon
+
+import (
+	"net/http"
+	"testing"
+)
+
+func TestMinFreeSpace(t *testing.T) {
+	for value, expected := range map[string]*freeSpaceThreshold{
+		"":     nil,
+		"2GB":  {size: 2 << 30},
+		"512m": {size: 512 << 20},
+		"10%":  {percent: 10},
+		"2.5%": {percent: 2.5},
+	} {
+		threshold, err := (&Config{CommonConfig: CommonConfig{MinFreeSpace: value}}).minFreeSpace()
+		if err != nil {
+			t.Fatalf("expected no error for %q, got %v", value, err)
+		}
+		if (threshold == nil) != (expected == nil) || threshold != nil && *threshold != *expected {
+			t.Fatalf("expected %v for %q, got %v", expected, value, threshold)
+		}
+	}
+	for _, value := range []string{"0", "-1GB", "abc", "0%", "100%", "x%"} {
+		if err := ValidateConfiguration(&Config{CommonConfig: CommonConfig{MinFreeSpace: value}}); err == nil {
+			t.Fatalf("expected error for %q, got nil", value)
+		}
+	}
+}
+
+func TestCheckFreeSpace(t *testing.T) {
+	const gb = 1 << 30
+	for _, tc := range []struct {
+		free, projected uint64
+		threshold       freeSpaceThreshold
+		ok              bool
+	}{
+		{10 * gb, 1 * gb, freeSpaceThreshold{size: 2 * gb}, true},
+		{10 * gb, 9 * gb, freeSpaceThreshold{size: 2 * gb}, false},
+		{10 * gb, 11 * gb, freeSpaceThreshold{size: 2 * gb}, false},
+		{1 * gb, 0, freeSpaceThreshold{size: 2 * gb}, false},
+		// 10% of 100GB
+		{15 * gb, 4 * gb, freeSpaceThreshold{percent: 10}, true},
+		{15 * gb, 6 * gb, freeSpaceThreshold{percent: 10}, false},
+	} {
+		err := checkFreeSpace("pull busybox", "/var/lib/docker", tc.free, 100*gb, tc.projected, &tc.threshold)
+		if tc.ok != (err == nil) {
+			t.Fatalf("unexpected result for %d free and %d needed with %v: %v", tc.free, tc.projected, tc.threshold, err)
+		}
+		if err != nil {
+			if e, ok := err.(interface {
+				HTTPErrorStatusCode() int
+			}); !ok || e.HTTPErrorStatusCode() != http.StatusInsufficientStorage {
+				t.Fatalf("expected a %d error, got %v", http.StatusInsufficientStorage, err)
+			}
+		}
+	}
+}
@@ -0,0 +1,11 @@
+// +build !linux
+
+package daemon
+
+import "errors"
+
+// diskSpace is not supported on this platform, so the free space is not
+// checked.
+func diskSpace(path string) (uint64, uint64, error) {
+	return 0, 0, errors.New("free space is not supported on this platform")
+}
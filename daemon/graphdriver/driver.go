@@ -105,6 +105,17 @@ type DiffGetterDriver interface {
 	DiffGetter(id string) (FileGetCloser, error)
 }
 
+// SharedLayersDriver is the interface for drivers which can use, besides
+// their own layers, the layers of the home directory of another driver of
+// the same type, shared read-only by several daemons, for instance over NFS.
+type SharedLayersDriver interface {
+	Driver
+	// SetSharedHome sets the read-only home directory of the shared layers.
+	// The layers created by the driver, including the writable layers of
+	// the containers, are always kept in its own home directory.
+	SetSharedHome(home string) error
+}
+
 // FileGetCloser extends the storage.FileGetter interface with a Close method
 // for cleaning up.
 type FileGetCloser interface {
@@ -9,13 +9,16 @@ import (
 	"os"
 	"os/exec"
 	"path"
+	"strings"
 	"syscall"
 
 	"github.com/Sirupsen/logrus"
 
 	"github.com/docker/docker/daemon/graphdriver"
+	"github.com/docker/docker/daemon/graphdriver/quota"
 	"github.com/docker/docker/pkg/archive"
 	"github.com/docker/docker/pkg/idtools"
+	"github.com/docker/go-units"
 
 	"github.com/docker/docker/pkg/mount"
 	"github.com/opencontainers/runc/libcontainer/label"
@@ -90,10 +93,11 @@ func (d *naiveDiffDriverWithApply) ApplyDiff(id, parent string, diff archive.Rea
 
 // Driver contains information about the home directory and the list of active mounts that are created using this driver.
 type Driver struct {
-	home    string
-	uidMaps []idtools.IDMap
-	gidMaps []idtools.IDMap
-	ctr     *graphdriver.RefCounter
+	home     string
+	uidMaps  []idtools.IDMap
+	gidMaps  []idtools.IDMap
+	ctr      *graphdriver.RefCounter
+	quotaCtl *quota.Control
 }
 
 func init() {
@@ -143,6 +147,14 @@ func Init(home string, options []string, uidMaps, gidMaps []idtools.IDMap) (grap
 		ctr:     graphdriver.NewRefCounter(graphdriver.NewFsChecker(graphdriver.FsMagicOverlay)),
 	}
 
+	if fsMagic == graphdriver.FsMagicXfs {
+		// The size of the layers can be limited over XFS mounted with the
+		// pquota option
+		if d.quotaCtl, err = quota.NewControl(home); err != nil {
+			logrus.Debugf("overlay: project quotas are not supported on %s: %v", home, err)
+		}
+	}
+
 	return NaiveDiffDriverWithApply(d, uidMaps, gidMaps), nil
 }
 
@@ -225,8 +237,12 @@ func (d *Driver) CreateReadWrite(id, parent, mountLabel string, storageOpt map[s
 // The parent filesystem is used to configure these directories for the overlay.
 func (d *Driver) Create(id, parent, mountLabel string, storageOpt map[string]string) (retErr error) {
 
-	if len(storageOpt) != 0 {
-		return fmt.Errorf("--storage-opt is not supported for overlay")
+	size, err := parseStorageOpt(storageOpt)
+	if err != nil {
+		return err
+	}
+	if size != 0 && d.quotaCtl == nil {
+		return fmt.Errorf("--storage-opt size is supported only for overlay over xfs with the pquota mount option")
 	}
 
 	dir := d.dir(id)
@@ -245,10 +261,17 @@ func (d *Driver) Create(id, parent, mountLabel string, storageOpt map[string]str
 	defer func() {
 		// Clean up on failure
 		if retErr != nil {
+			d.clearQuota(dir)
 			os.RemoveAll(dir)
 		}
 	}()
 
+	if size != 0 {
+		if err := d.quotaCtl.SetQuota(dir, quota.Quota{Size: size}); err != nil {
+			return err
+		}
+	}
+
 	// Toplevel images are just a "root" dir
 	if parent == "" {
 		if err := idtools.MkdirAs(path.Join(dir, "root"), 0755, rootUID, rootGID); err != nil {
@@ -314,18 +337,49 @@ func (d *Driver) Create(id, parent, mountLabel string, storageOpt map[string]str
 	return copyDir(parentUpperDir, upperDir, 0)
 }
 
+// parseStorageOpt returns the size limit of a layer in the storage options
+// of its container.
+func parseStorageOpt(storageOpt map[string]string) (uint64, error) {
+	var size uint64
+	for key, val := range storageOpt {
+		switch strings.ToLower(key) {
+		case "size":
+			s, err := units.RAMInBytes(val)
+			if err != nil {
+				return 0, err
+			}
+			size = uint64(s)
+		default:
+			return 0, fmt.Errorf("Unknown option %s", key)
+		}
+	}
+	return size, nil
+}
+
 func (d *Driver) dir(id string) string {
 	return path.Join(d.home, id)
 }
 
 // Remove cleans the directories that are created for this id.
 func (d *Driver) Remove(id string) error {
-	if err := os.RemoveAll(d.dir(id)); err != nil && !os.IsNotExist(err) {
+	dir := d.dir(id)
+	d.clearQuota(dir)
+	if err := os.RemoveAll(dir); err != nil && !os.IsNotExist(err) {
 		return err
 	}
 	return nil
 }
 
+// clearQuota removes the size limit of the layer at dir, if any.
+func (d *Driver) clearQuota(dir string) {
+	if d.quotaCtl == nil {
+		return
+	}
+	if err := d.quotaCtl.ClearQuota(dir); err != nil {
+		logrus.Warnf("overlay: failed to clear the quota of %s: %v", dir, err)
+	}
+}
+
 // Get creates and mounts the required file system for the given id and returns the mount path.
 func (d *Driver) Get(id string, mountLabel string) (s string, err error) {
 	dir := d.dir(id)
@@ -17,6 +17,7 @@ import (
 	"github.com/Sirupsen/logrus"
 
 	"github.com/docker/docker/daemon/graphdriver"
+	"github.com/docker/docker/daemon/graphdriver/quota"
 	"github.com/docker/docker/pkg/archive"
 	"github.com/docker/docker/pkg/chrootarchive"
 	"github.com/docker/docker/pkg/directory"
@@ -24,8 +25,10 @@ import (
 	"github.com/docker/docker/pkg/mount"
 	"github.com/docker/docker/pkg/parsers"
 	"github.com/docker/docker/pkg/parsers/kernel"
+	"github.com/docker/go-units"
 
 	"github.com/opencontainers/runc/libcontainer/label"
+	"github.com/vbatts/tar-split/tar/storage"
 )
 
 var (
@@ -76,12 +79,20 @@ const (
 	idLength = 26
 )
 
+// Each layer of a shared home, read-only, can be the parent of the layers
+// of the driver. The links of the shared layers are added to the "l"
+// directory of the driver when it creates their children, pointing to the
+// "diff" directories of the shared layers, so that the "lower" files and
+// the mounts of the children refer to them as to local layers.
+
 // Driver contains information about the home directory and the list of active mounts that are created using this driver.
 type Driver struct {
-	home    string
-	uidMaps []idtools.IDMap
-	gidMaps []idtools.IDMap
-	ctr     *graphdriver.RefCounter
+	home       string
+	sharedHome string
+	uidMaps    []idtools.IDMap
+	gidMaps    []idtools.IDMap
+	ctr        *graphdriver.RefCounter
+	quotaCtl   *quota.Control
 }
 
 var backingFs = "<unknown>"
@@ -150,6 +161,14 @@ func Init(home string, options []string, uidMaps, gidMaps []idtools.IDMap) (grap
 		ctr:     graphdriver.NewRefCounter(graphdriver.NewFsChecker(graphdriver.FsMagicOverlay)),
 	}
 
+	if fsMagic == graphdriver.FsMagicXfs {
+		// The size of the layers can be limited over XFS mounted with the
+		// pquota option
+		if d.quotaCtl, err = quota.NewControl(home); err != nil {
+			logrus.Debugf("overlay2: project quotas are not supported on %s: %v", home, err)
+		}
+	}
+
 	return d, nil
 }
 
@@ -206,9 +225,30 @@ func (d *Driver) String() string {
 // Status returns current driver information in a two dimensional string array.
 // Output contains "Backing Filesystem" used in this implementation.
 func (d *Driver) Status() [][2]string {
-	return [][2]string{
+	status := [][2]string{
 		{"Backing Filesystem", backingFs},
 	}
+	if d.sharedHome != "" {
+		status = append(status, [2]string{"Shared Home", d.sharedHome})
+	}
+	return status
+}
+
+// SetSharedHome sets the read-only home directory of another overlay2
+// driver, whose layers can be used as the parents of the layers of d.
+func (d *Driver) SetSharedHome(home string) error {
+	fi, err := os.Stat(home)
+	if err != nil {
+		return err
+	}
+	if !fi.IsDir() {
+		return fmt.Errorf("overlay2: shared home %s is not a directory", home)
+	}
+	if path.Clean(home) == path.Clean(d.home) {
+		return fmt.Errorf("overlay2: shared home %s is the home of the driver", home)
+	}
+	d.sharedHome = home
+	return nil
 }
 
 // GetMetadata returns meta data about the overlay driver such as
@@ -253,8 +293,12 @@ func (d *Driver) CreateReadWrite(id, parent, mountLabel string, storageOpt map[s
 // The parent filesystem is used to configure these directories for the overlay.
 func (d *Driver) Create(id, parent, mountLabel string, storageOpt map[string]string) (retErr error) {
 
-	if len(storageOpt) != 0 {
-		return fmt.Errorf("--storage-opt is not supported for overlay")
+	size, err := parseStorageOpt(storageOpt)
+	if err != nil {
+		return err
+	}
+	if size != 0 && d.quotaCtl == nil {
+		return fmt.Errorf("--storage-opt size is supported only for overlay2 over xfs with the pquota mount option")
 	}
 
 	dir := d.dir(id)
@@ -273,10 +317,17 @@ func (d *Driver) Create(id, parent, mountLabel string, storageOpt map[string]str
 	defer func() {
 		// Clean up on failure
 		if retErr != nil {
+			d.clearQuota(dir)
 			os.RemoveAll(dir)
 		}
 	}()
 
+	if size != 0 {
+		if err := d.quotaCtl.SetQuota(dir, quota.Quota{Size: size}); err != nil {
+			return err
+		}
+	}
+
 	if err := idtools.MkdirAs(path.Join(dir, "diff"), 0755, rootUID, rootGID); err != nil {
 		return err
 	}
@@ -307,6 +358,9 @@ func (d *Driver) Create(id, parent, mountLabel string, storageOpt map[string]str
 	if err != nil {
 		return err
 	}
+	if err := d.linkSharedLowers(lower); err != nil {
+		return err
+	}
 	if lower != "" {
 		if err := ioutil.WriteFile(path.Join(dir, lowerFile), []byte(lower), 0666); err != nil {
 			return err
@@ -316,6 +370,25 @@ func (d *Driver) Create(id, parent, mountLabel string, storageOpt map[string]str
 	return nil
 }
 
+// parseStorageOpt returns the size limit of a layer in the storage options
+// of its container.
+func parseStorageOpt(storageOpt map[string]string) (uint64, error) {
+	var size uint64
+	for key, val := range storageOpt {
+		switch strings.ToLower(key) {
+		case "size":
+			s, err := units.RAMInBytes(val)
+			if err != nil {
+				return 0, err
+			}
+			size = uint64(s)
+		default:
+			return 0, fmt.Errorf("Unknown option %s", key)
+		}
+	}
+	return size, nil
+}
+
 func (d *Driver) getLower(parent string) (string, error) {
 	parentDir := d.dir(parent)
 
@@ -342,8 +415,48 @@ func (d *Driver) getLower(parent string) (string, error) {
 	return strings.Join(lowers, ":"), nil
 }
 
+// linkSharedLowers adds the links of the lower layers which are missing
+// from the home of the driver, as they are shared layers, pointing to their
+// diff directories in the shared home.
+func (d *Driver) linkSharedLowers(lower string) error {
+	if d.sharedHome == "" || lower == "" {
+		return nil
+	}
+	for _, s := range strings.Split(lower, ":") {
+		if _, err := os.Lstat(path.Join(d.home, s)); !os.IsNotExist(err) {
+			continue
+		}
+		lp, err := os.Readlink(path.Join(d.sharedHome, s))
+		if err != nil {
+			return err
+		}
+		if err := os.Symlink(path.Join(d.sharedHome, linkDir, lp), path.Join(d.home, s)); err != nil && !os.IsExist(err) {
+			return err
+		}
+	}
+	return nil
+}
+
 func (d *Driver) dir(id string) string {
-	return path.Join(d.home, id)
+	dir := path.Join(d.home, id)
+	if d.sharedHome != "" {
+		if _, err := os.Lstat(dir); os.IsNotExist(err) {
+			if sharedDir := path.Join(d.sharedHome, id); exists(sharedDir) {
+				return sharedDir
+			}
+		}
+	}
+	return dir
+}
+
+// isShared tells whether the layer id is a layer of the shared home.
+func (d *Driver) isShared(id string) bool {
+	return d.sharedHome != "" && d.dir(id) != path.Join(d.home, id)
+}
+
+func exists(pth string) bool {
+	_, err := os.Lstat(pth)
+	return err == nil
 }
 
 func (d *Driver) getLowerDirs(id string) ([]string, error) {
@@ -351,11 +464,21 @@ func (d *Driver) getLowerDirs(id string) ([]string, error) {
 	lowers, err := ioutil.ReadFile(path.Join(d.dir(id), lowerFile))
 	if err == nil {
 		for _, s := range strings.Split(string(lowers), ":") {
-			lp, err := os.Readlink(path.Join(d.home, s))
+			home := d.home
+			lp, err := os.Readlink(path.Join(home, s))
+			if os.IsNotExist(err) && d.sharedHome != "" {
+				home = d.sharedHome
+				lp, err = os.Readlink(path.Join(home, s))
+			}
 			if err != nil {
 				return nil, err
 			}
-			lowersArray = append(lowersArray, path.Clean(path.Join(d.home, "link", lp)))
+			// the links of the shared layers are absolute
+			if path.IsAbs(lp) {
+				lowersArray = append(lowersArray, path.Clean(lp))
+				continue
+			}
+			lowersArray = append(lowersArray, path.Clean(path.Join(home, "link", lp)))
 		}
 	} else if !os.IsNotExist(err) {
 		return nil, err
@@ -365,6 +488,9 @@ func (d *Driver) getLowerDirs(id string) ([]string, error) {
 
 // Remove cleans the directories that are created for this id.
 func (d *Driver) Remove(id string) error {
+	if d.isShared(id) {
+		return fmt.Errorf("overlay2: cannot remove layer %s of the read-only shared home %s", id, d.sharedHome)
+	}
 	dir := d.dir(id)
 	lid, err := ioutil.ReadFile(path.Join(dir, "link"))
 	if err == nil {
@@ -373,12 +499,23 @@ func (d *Driver) Remove(id string) error {
 		}
 	}
 
+	d.clearQuota(dir)
 	if err := os.RemoveAll(dir); err != nil && !os.IsNotExist(err) {
 		return err
 	}
 	return nil
 }
 
+// clearQuota removes the size limit of the layer at dir, if any.
+func (d *Driver) clearQuota(dir string) {
+	if d.quotaCtl == nil {
+		return
+	}
+	if err := d.quotaCtl.ClearQuota(dir); err != nil {
+		logrus.Warnf("overlay2: failed to clear the quota of %s: %v", dir, err)
+	}
+}
+
 // Get creates and mounts the required file system for the given id and returns the mount path.
 func (d *Driver) Get(id string, mountLabel string) (s string, err error) {
 	dir := d.dir(id)
@@ -507,3 +644,18 @@ func (d *Driver) Changes(id, parent string) ([]archive.Change, error) {
 
 	return archive.OverlayChanges(layers, diffPath)
 }
+
+type fileGetNilCloser struct {
+	storage.FileGetter
+}
+
+func (f fileGetNilCloser) Close() error {
+	return nil
+}
+
+// DiffGetter returns a FileGetCloser that can read files from the diff
+// directory of the layer, without mounting it. Used for direct access for
+// tar-split, including for the layers of the read-only shared home.
+func (d *Driver) DiffGetter(id string) (graphdriver.FileGetCloser, error) {
+	return fileGetNilCloser{storage.NewPathFileGetter(d.getDiffPath(id))}, nil
+}
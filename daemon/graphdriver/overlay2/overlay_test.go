@@ -3,7 +3,10 @@
 package overlay2
 
 import (
+	"io/ioutil"
 	"os"
+	"path"
+	"reflect"
 	"syscall"
 	"testing"
 
@@ -104,3 +107,79 @@ func BenchmarkDiff20Layers(b *testing.B) {
 func BenchmarkRead20Layers(b *testing.B) {
 	graphtest.DriverBenchDeepLayerRead(b, 20, driverName)
 }
+
+func TestOverlaySharedHome(t *testing.T) {
+	if os.Getuid() != 0 {
+		t.Skip("requires root to create the layers")
+	}
+	sharedHome, err := ioutil.TempDir("", "overlay2-shared-")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(sharedHome)
+	home, err := ioutil.TempDir("", "overlay2-")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(home)
+
+	shared := &Driver{home: sharedHome}
+	d := &Driver{home: home}
+	for _, dir := range []string{sharedHome, home} {
+		if err := os.Mkdir(path.Join(dir, linkDir), 0700); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := shared.Create("base", "", "", nil); err != nil {
+		t.Fatal(err)
+	}
+	if err := shared.Create("top", "base", "", nil); err != nil {
+		t.Fatal(err)
+	}
+	if err := d.SetSharedHome(sharedHome); err != nil {
+		t.Fatal(err)
+	}
+
+	if !d.Exists("top") || !d.isShared("top") {
+		t.Fatal("expected the layer of the shared home to exist")
+	}
+	if err := d.Create("container", "top", "", nil); err != nil {
+		t.Fatal(err)
+	}
+	if d.isShared("container") {
+		t.Fatal("expected the layer to be created in the home of the driver")
+	}
+	lowers, err := d.getLowerDirs("container")
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := []string{path.Join(sharedHome, "top", "diff"), path.Join(sharedHome, "base", "diff")}
+	if !reflect.DeepEqual(lowers, expected) {
+		t.Fatalf("expected the lower directories %v, got %v", expected, lowers)
+	}
+
+	if err := d.Remove("top"); err == nil {
+		t.Fatal("expected removing a layer of the shared home to fail")
+	}
+	if err := d.Remove("container"); err != nil {
+		t.Fatal(err)
+	}
+	if !shared.Exists("top") {
+		t.Fatal("expected the layer of the shared home to be kept")
+	}
+}
+
+func TestParseStorageOpt(t *testing.T) {
+	if size, err := parseStorageOpt(nil); err != nil || size != 0 {
+		t.Fatalf("expected no size limit, got %d, %v", size, err)
+	}
+	if size, err := parseStorageOpt(map[string]string{"Size": "10G"}); err != nil || size != 10*1024*1024*1024 {
+		t.Fatalf("expected a 10G size limit, got %d, %v", size, err)
+	}
+	if _, err := parseStorageOpt(map[string]string{"size": "big"}); err == nil {
+		t.Fatal("expected an error for an invalid size")
+	}
+	if _, err := parseStorageOpt(map[string]string{"inodes": "1000"}); err == nil {
+		t.Fatal("expected an error for an unknown option")
+	}
+}
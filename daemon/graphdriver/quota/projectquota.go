@@ -0,0 +1,277 @@
+// +build linux
+
+// Package quota limits the size of directories with the project quotas of
+// XFS. Each directory gets a project ID of its own, inherited by the files
+// and directories created in it, and the limit is set on the blocks of the
+// project.
+package quota
+
+/*
+#include <stdlib.h>
+#include <linux/fs.h>
+#include <linux/quota.h>
+#include <linux/dqblk_xfs.h>
+
+#ifndef FS_XFLAG_PROJINHERIT
+struct fsxattr {
+	__u32		fsx_xflags;
+	__u32		fsx_extsize;
+	__u32		fsx_nextents;
+	__u32		fsx_projid;
+	unsigned char	fsx_pad[12];
+};
+#define FS_XFLAG_PROJINHERIT	0x00000200
+#endif
+#ifndef FS_IOC_FSGETXATTR
+#define FS_IOC_FSGETXATTR		_IOR ('X', 31, struct fsxattr)
+#endif
+#ifndef FS_IOC_FSSETXATTR
+#define FS_IOC_FSSETXATTR		_IOW ('X', 32, struct fsxattr)
+#endif
+
+#ifndef PRJQUOTA
+#define PRJQUOTA	2
+#endif
+#ifndef XFS_PROJ_QUOTA
+#define XFS_PROJ_QUOTA	2
+#endif
+#ifndef Q_XSETPQLIM
+#define Q_XSETPQLIM QCMD(Q_XSETQLIM, PRJQUOTA)
+#endif
+#ifndef Q_XGETPQUOTA
+#define Q_XGETPQUOTA QCMD(Q_XGETQUOTA, PRJQUOTA)
+#endif
+*/
+import "C"
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sync"
+	"syscall"
+	"unsafe"
+
+	"github.com/Sirupsen/logrus"
+)
+
+// Quota is the limit of a directory.
+type Quota struct {
+	// Size is the maximum number of bytes of the blocks of the directory.
+	Size uint64
+}
+
+// Control sets the quotas of the directories of a home directory on XFS.
+type Control struct {
+	backingFsBlockDev string
+
+	mu            sync.Mutex
+	nextProjectID uint32
+	quotas        map[string]uint32
+}
+
+// NewControl returns the quota control of the directories of home, or an
+// error if the filesystem of home doesn't support project quotas, like XFS
+// mounted without the pquota option.
+//
+// The project ID of home is the last ID not used by the control, so that an
+// administrator can reserve the lower IDs for other projects, by assigning a
+// project ID to home with xfs_quota. The directories of home which already
+// have a project ID, created by a previous control, keep it.
+func NewControl(home string) (*Control, error) {
+	minProjectID, err := getProjectID(home)
+	if err != nil {
+		return nil, err
+	}
+	minProjectID++
+
+	backingFsBlockDev, err := makeBackingFsDev(home)
+	if err != nil {
+		return nil, err
+	}
+
+	// Setting an unlimited quota fails if the project quotas are not
+	// enabled on the filesystem.
+	if err := setProjectQuota(backingFsBlockDev, minProjectID, Quota{}); err != nil {
+		return nil, err
+	}
+
+	q := &Control{
+		backingFsBlockDev: backingFsBlockDev,
+		nextProjectID:     minProjectID + 1,
+		quotas:            make(map[string]uint32),
+	}
+	if err := q.findNextProjectID(home, minProjectID); err != nil {
+		return nil, err
+	}
+	logrus.Debugf("quota: next project ID of %s is %d", home, q.nextProjectID)
+	return q, nil
+}
+
+// SetQuota assigns a project ID to the directory at targetPath, unless it
+// already has one, and limits the project to quota.
+func (q *Control) SetQuota(targetPath string, quota Quota) error {
+	q.mu.Lock()
+	projectID, ok := q.quotas[targetPath]
+	if !ok {
+		projectID = q.nextProjectID
+		if err := setProjectID(targetPath, projectID); err != nil {
+			q.mu.Unlock()
+			return err
+		}
+		q.quotas[targetPath] = projectID
+		q.nextProjectID++
+	}
+	q.mu.Unlock()
+
+	logrus.Debugf("quota: setting the quota of %s, project ID %d, to %d bytes", targetPath, projectID, quota.Size)
+	return setProjectQuota(q.backingFsBlockDev, projectID, quota)
+}
+
+// ClearQuota removes the limit of the project of the directory at
+// targetPath, if any, and forgets its project ID, before the directory is
+// removed. The project IDs are not reused, so that the files left by a
+// directory which failed to be removed are not accounted to another one.
+func (q *Control) ClearQuota(targetPath string) error {
+	q.mu.Lock()
+	projectID, ok := q.quotas[targetPath]
+	delete(q.quotas, targetPath)
+	q.mu.Unlock()
+	if !ok {
+		return nil
+	}
+
+	logrus.Debugf("quota: clearing the quota of %s, project ID %d", targetPath, projectID)
+	return setProjectQuota(q.backingFsBlockDev, projectID, Quota{})
+}
+
+// GetQuota returns the quota set on the directory at targetPath.
+func (q *Control) GetQuota(targetPath string) (Quota, error) {
+	q.mu.Lock()
+	projectID, ok := q.quotas[targetPath]
+	q.mu.Unlock()
+	if !ok {
+		return Quota{}, fmt.Errorf("quota: no quota set on %s", targetPath)
+	}
+
+	var d C.fs_disk_quota_t
+	cs := C.CString(q.backingFsBlockDev)
+	defer C.free(unsafe.Pointer(cs))
+
+	_, _, errno := syscall.Syscall6(syscall.SYS_QUOTACTL, C.Q_XGETPQUOTA,
+		uintptr(unsafe.Pointer(cs)), uintptr(C.__u32(projectID)),
+		uintptr(unsafe.Pointer(&d)), 0, 0)
+	if errno != 0 {
+		return Quota{}, fmt.Errorf("quota: failed to get the quota of project ID %d on %s: %v", projectID, q.backingFsBlockDev, errno)
+	}
+	return Quota{Size: uint64(d.d_blk_hardlimit) * 512}, nil
+}
+
+// setProjectQuota sets the block limit of a project of the XFS filesystem
+// of the device backingFsBlockDev. A zero size removes the limit.
+func setProjectQuota(backingFsBlockDev string, projectID uint32, quota Quota) error {
+	var d C.fs_disk_quota_t
+	d.d_version = C.FS_DQUOT_VERSION
+	d.d_id = C.__u32(projectID)
+	d.d_flags = C.XFS_PROJ_QUOTA
+	d.d_fieldmask = C.FS_DQ_BHARD | C.FS_DQ_BSOFT
+	d.d_blk_hardlimit = C.__u64(quota.Size / 512)
+	d.d_blk_softlimit = d.d_blk_hardlimit
+
+	cs := C.CString(backingFsBlockDev)
+	defer C.free(unsafe.Pointer(cs))
+
+	_, _, errno := syscall.Syscall6(syscall.SYS_QUOTACTL, C.Q_XSETPQLIM,
+		uintptr(unsafe.Pointer(cs)), uintptr(d.d_id),
+		uintptr(unsafe.Pointer(&d)), 0, 0)
+	if errno != 0 {
+		return fmt.Errorf("quota: failed to set the quota of project ID %d on %s: %v", projectID, backingFsBlockDev, errno)
+	}
+	return nil
+}
+
+// getProjectID returns the project ID of the directory at targetPath.
+func getProjectID(targetPath string) (uint32, error) {
+	dir, err := os.Open(targetPath)
+	if err != nil {
+		return 0, err
+	}
+	defer dir.Close()
+
+	var fsx C.struct_fsxattr
+	if _, _, errno := syscall.Syscall(syscall.SYS_IOCTL, dir.Fd(), C.FS_IOC_FSGETXATTR,
+		uintptr(unsafe.Pointer(&fsx))); errno != 0 {
+		return 0, fmt.Errorf("quota: failed to get the project ID of %s: %v", targetPath, errno)
+	}
+	return uint32(fsx.fsx_projid), nil
+}
+
+// setProjectID sets the project ID of the directory at targetPath, inherited
+// by the files and directories created in it.
+func setProjectID(targetPath string, projectID uint32) error {
+	dir, err := os.Open(targetPath)
+	if err != nil {
+		return err
+	}
+	defer dir.Close()
+
+	var fsx C.struct_fsxattr
+	if _, _, errno := syscall.Syscall(syscall.SYS_IOCTL, dir.Fd(), C.FS_IOC_FSGETXATTR,
+		uintptr(unsafe.Pointer(&fsx))); errno != 0 {
+		return fmt.Errorf("quota: failed to get the project ID of %s: %v", targetPath, errno)
+	}
+	fsx.fsx_projid = C.__u32(projectID)
+	fsx.fsx_xflags |= C.FS_XFLAG_PROJINHERIT
+	if _, _, errno := syscall.Syscall(syscall.SYS_IOCTL, dir.Fd(), C.FS_IOC_FSSETXATTR,
+		uintptr(unsafe.Pointer(&fsx))); errno != 0 {
+		return fmt.Errorf("quota: failed to set the project ID of %s: %v", targetPath, errno)
+	}
+	return nil
+}
+
+// findNextProjectID records the project IDs above minProjectID of the
+// directories of home, and moves the next project ID past them. The lower IDs
+// are inherited from home rather than assigned by a control.
+func (q *Control) findNextProjectID(home string, minProjectID uint32) error {
+	files, err := ioutil.ReadDir(home)
+	if err != nil {
+		return err
+	}
+	for _, file := range files {
+		if !file.IsDir() {
+			continue
+		}
+		path := filepath.Join(home, file.Name())
+		projectID, err := getProjectID(path)
+		if err != nil {
+			return err
+		}
+		if projectID <= minProjectID {
+			continue
+		}
+		q.quotas[path] = projectID
+		if q.nextProjectID <= projectID {
+			q.nextProjectID = projectID + 1
+		}
+	}
+	return nil
+}
+
+// makeBackingFsDev creates a device node of the filesystem of home in home,
+// for the quotactl calls.
+func makeBackingFsDev(home string) (string, error) {
+	fi, err := os.Stat(home)
+	if err != nil {
+		return "", err
+	}
+
+	backingFsBlockDev := filepath.Join(home, "backingFsBlockDev")
+	// Create the node again, in case home was moved to another device
+	syscall.Unlink(backingFsBlockDev)
+	stat := fi.Sys().(*syscall.Stat_t)
+	if err := syscall.Mknod(backingFsBlockDev, syscall.S_IFBLK|0600, int(stat.Dev)); err != nil {
+		return "", fmt.Errorf("quota: failed to create the device node %s: %v", backingFsBlockDev, err)
+	}
+	return backingFsBlockDev, nil
+}
@@ -0,0 +1,42 @@
+package daemon
+
+import (
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+
+	"github.com/docker/docker/errors"
+)
+
+// maxAmbiguousCandidates is the maximum number of candidates listed by the
+// error of an ambiguous ID prefix.
+const maxAmbiguousCandidates = 10
+
+// errAmbiguousPrefix is returned when an ID prefix matches several objects
+// of a kind, instead of acting on one of them. It lists the candidates.
+func errAmbiguousPrefix(kind, prefix string, candidates []string) error {
+	sort.Strings(candidates)
+	list := candidates
+	if len(list) > maxAmbiguousCandidates {
+		list = list[:maxAmbiguousCandidates]
+	}
+	msg := fmt.Sprintf("%s ID prefix %q is ambiguous, it matches %d %ss: %s", kind, prefix, len(candidates), kind, strings.Join(list, ", "))
+	if len(candidates) > len(list) {
+		msg += fmt.Sprintf(" and %d more", len(candidates)-len(list))
+	}
+	return errors.NewErrorWithCode(fmt.Errorf("%s", msg), http.StatusBadRequest, errors.CodeAmbiguousID, map[string]string{"kind": kind, "prefix": prefix})
+}
+
+// checkIDPrefix returns an error if prefix, which matches the ID id of an
+// object of a kind, is shorter than the min-id-prefix-length of the daemon.
+// Full IDs are always accepted.
+func (daemon *Daemon) checkIDPrefix(kind, prefix, id string) error {
+	if daemon.configStore == nil || prefix == id {
+		return nil
+	}
+	if min := daemon.configStore.MinIDPrefixLength; len(prefix) < min {
+		return errors.NewBadRequestError(fmt.Errorf("%s ID prefix %q is too short: the daemon requires at least %d characters", kind, prefix, min))
+	}
+	return nil
+}
@@ -2,7 +2,10 @@ package daemon
 
 import (
 	"fmt"
+	"strings"
 
+	"github.com/Sirupsen/logrus"
+	"github.com/docker/distribution/digest"
 	"github.com/docker/docker/builder"
 	"github.com/docker/docker/image"
 	"github.com/docker/docker/reference"
@@ -19,10 +22,30 @@ func (e ErrImageDoesNotExist) Error() string {
 	return fmt.Sprintf("no such id: %s", e.RefOrID)
 }
 
+// resolveShortName returns refOrID with its name replaced by its alias if
+// it is a short name with an alias in the short-name aliases file.
+func (daemon *Daemon) resolveShortName(refOrID string) (string, error) {
+	if daemon.aliasStore == nil {
+		return refOrID, nil
+	}
+	resolved, err := daemon.aliasStore.Resolve(refOrID)
+	if err != nil {
+		return "", err
+	}
+	if resolved != refOrID {
+		logrus.Debugf("Resolved short name %s to %s", refOrID, resolved)
+	}
+	return resolved, nil
+}
+
 // GetImageID returns an image ID corresponding to the image referred to by
-// refOrID.
+// refOrID. Short names with an alias refer to the image of the alias.
 func (daemon *Daemon) GetImageID(refOrID string) (image.ID, error) {
-	id, ref, err := reference.ParseIDOrReference(refOrID)
+	resolved, err := daemon.resolveShortName(refOrID)
+	if err != nil {
+		return "", err
+	}
+	id, ref, err := reference.ParseIDOrReference(resolved)
 	if err != nil {
 		return "", err
 	}
@@ -47,8 +70,20 @@ func (daemon *Daemon) GetImageID(refOrID string) (image.ID, error) {
 	}
 
 	// Search based on ID
-	if id, err := daemon.imageStore.Search(refOrID); err == nil {
-		return id, nil
+	imgID, err := daemon.imageStore.Search(refOrID)
+	if err == nil {
+		prefix := strings.TrimPrefix(refOrID, string(digest.Canonical)+":")
+		if err := daemon.checkIDPrefix("image", prefix, digest.Digest(imgID).Hex()); err != nil {
+			return "", err
+		}
+		return imgID, nil
+	}
+	if ambiguous, ok := err.(image.ErrAmbiguousPrefix); ok {
+		candidates := make([]string, len(ambiguous.Candidates))
+		for i, id := range ambiguous.Candidates {
+			candidates[i] = digest.Digest(id).Hex()
+		}
+		return "", errAmbiguousPrefix("image", refOrID, candidates)
 	}
 
 	return "", ErrImageDoesNotExist{refOrID}
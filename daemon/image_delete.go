@@ -321,6 +321,8 @@ func (daemon *Daemon) imageDeleteHelper(imgID image.ID, records *[]types.ImageDe
 	if err != nil {
 		return err
 	}
+	daemon.verifiedImages.remove(imgID)
+	daemon.imageUsage.remove(imgID)
 
 	daemon.LogImageEvent(imgID.String(), imgID.String(), "delete")
 	*records = append(*records, types.ImageDelete{Deleted: imgID.String()})
@@ -3,9 +3,11 @@ package daemon
 import (
 	"fmt"
 
+	"github.com/docker/docker/distribution"
 	"github.com/docker/docker/layer"
 	"github.com/docker/docker/reference"
 	"github.com/docker/engine-api/types"
+	"golang.org/x/net/context"
 )
 
 // ImageHistory returns a slice of ImageHistory structures for the specified image
@@ -80,3 +82,64 @@ func (daemon *Daemon) ImageHistory(name string) ([]*types.ImageHistory, error) {
 
 	return history, nil
 }
+
+// RemoteImageHistory returns a slice of ImageHistory structures for the
+// image name points to in its registry, without pulling it. The history
+// comes from the configuration of the image, and the sizes of its layers
+// are their compressed sizes as listed in its manifest. Only the most recent
+// entry has the ID and the tag of the image, as the IDs of the images of
+// the other entries are not known.
+func (daemon *Daemon) RemoteImageHistory(ctx context.Context, name string, metaHeaders map[string][]string, authConfig *types.AuthConfig) ([]*types.ImageHistory, error) {
+	resolved, err := daemon.resolveShortName(name)
+	if err != nil {
+		return nil, err
+	}
+	ref, err := reference.ParseNamed(resolved)
+	if err != nil {
+		return nil, err
+	}
+	ref = reference.WithDefaultTag(ref)
+
+	img, err := distribution.Inspect(ctx, ref, &distribution.ImageInspectConfig{
+		MetaHeaders:     metaHeaders,
+		AuthConfig:      authConfig,
+		RegistryService: daemon.RegistryService,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	history := []*types.ImageHistory{}
+
+	layerCounter := 0
+	for _, h := range img.Image.History {
+		var layerSize int64
+
+		if !h.EmptyLayer {
+			if len(img.LayerSizes) <= layerCounter {
+				return nil, fmt.Errorf("too many non-empty layers in History section")
+			}
+			layerSize = img.LayerSizes[layerCounter]
+			layerCounter++
+		}
+
+		history = append([]*types.ImageHistory{{
+			ID:        "<missing>",
+			Created:   h.Created.Unix(),
+			CreatedBy: h.CreatedBy,
+			Comment:   h.Comment,
+			Size:      layerSize,
+		}}, history...)
+	}
+
+	if len(history) > 0 {
+		if img.ID != "" {
+			history[0].ID = img.ID.String()
+		}
+		if tagged, ok := ref.(reference.NamedTagged); ok {
+			history[0].Tags = []string{tagged.String()}
+		}
+	}
+
+	return history, nil
+}
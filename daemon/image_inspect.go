@@ -1,12 +1,16 @@
 package daemon
 
 import (
-	"fmt"
+	"encoding/json"
 	"time"
 
+	"github.com/docker/distribution/digest"
+	"github.com/docker/distribution/manifest"
+	"github.com/docker/docker/distribution"
 	"github.com/docker/docker/layer"
 	"github.com/docker/docker/reference"
 	"github.com/docker/engine-api/types"
+	"golang.org/x/net/context"
 )
 
 // LookupImage looks up an image by name and returns it as an ImageInspect
@@ -14,7 +18,7 @@ import (
 func (daemon *Daemon) LookupImage(name string) (*types.ImageInspect, error) {
 	img, err := daemon.GetImage(name)
 	if err != nil {
-		return nil, fmt.Errorf("No such image: %s", name)
+		return nil, errNoSuchImage(name)
 	}
 
 	refs := daemon.referenceStore.References(img.ID())
@@ -54,6 +58,15 @@ func (daemon *Daemon) LookupImage(name string) (*types.ImageInspect, error) {
 		comment = img.History[len(img.History)-1].Comment
 	}
 
+	var lastUsedAt string
+	lastUsed, err := daemon.imageLastUsed(img.ID())
+	if err != nil {
+		return nil, err
+	}
+	if !lastUsed.IsZero() {
+		lastUsedAt = lastUsed.Format(time.RFC3339Nano)
+	}
+
 	imageInspect := &types.ImageInspect{
 		ID:              img.ID().String(),
 		RepoTags:        repoTags,
@@ -71,6 +84,8 @@ func (daemon *Daemon) LookupImage(name string) (*types.ImageInspect, error) {
 		Size:            size,
 		VirtualSize:     size, // TODO: field unused, deprecate
 		RootFS:          rootFSToAPIType(img.RootFS),
+		LastUsed:        lastUsedAt,
+		Containers:      daemon.imageContainers()[img.ID()],
 	}
 
 	imageInspect.GraphDriver.Name = daemon.GraphDriverName()
@@ -79,3 +94,122 @@ func (daemon *Daemon) LookupImage(name string) (*types.ImageInspect, error) {
 
 	return imageInspect, nil
 }
+
+// LookupRemoteImage looks up an image in its registry, without pulling it,
+// and returns it as an ImageInspect structure. A pinned tag is looked up at
+// the digest it is pinned to, like it is pulled. The results are cached for
+// the remote-inspect-ttl of the daemon, unless refresh is set.
+func (daemon *Daemon) LookupRemoteImage(ctx context.Context, name string, refresh bool, metaHeaders map[string][]string, authConfig *types.AuthConfig) (*types.ImageInspect, error) {
+	resolved, err := daemon.resolveShortName(name)
+	if err != nil {
+		return nil, err
+	}
+	ref, err := reference.ParseNamed(resolved)
+	if err != nil {
+		return nil, err
+	}
+	ref = reference.WithDefaultTag(ref)
+
+	var pinned digest.Digest
+	inspectRef := ref
+	if _, isTagged := ref.(reference.NamedTagged); isTagged {
+		pinned, err = daemon.pinStore.Get(ref)
+		if err == reference.ErrDoesNotExist {
+			pinned = ""
+		} else if err != nil {
+			return nil, err
+		} else if inspectRef, err = reference.WithDigest(ref, pinned); err != nil {
+			return nil, err
+		}
+	}
+
+	key := remoteInspectKey(ref, pinned, authConfig)
+	if !refresh {
+		if imageInspect := daemon.remoteInspectCache.get(key); imageInspect != nil {
+			return imageInspect, nil
+		}
+	}
+
+	img, err := distribution.Inspect(ctx, inspectRef, &distribution.ImageInspectConfig{
+		MetaHeaders:     metaHeaders,
+		AuthConfig:      authConfig,
+		RegistryService: daemon.RegistryService,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	repoTags := []string{}
+	if tagged, ok := ref.(reference.NamedTagged); ok {
+		repoTags = append(repoTags, tagged.String())
+	}
+	repoDigests := []string{}
+	if name, err := reference.WithName(ref.Name()); err == nil {
+		if canonical, err := reference.WithDigest(name, img.Digest); err == nil {
+			repoDigests = append(repoDigests, canonical.String())
+		}
+	}
+
+	comment := img.Image.Comment
+	if len(comment) == 0 && len(img.Image.History) > 0 {
+		comment = img.Image.History[len(img.Image.History)-1].Comment
+	}
+
+	imageInspect := &types.ImageInspect{
+		ID:              img.ID.String(),
+		RepoTags:        repoTags,
+		RepoDigests:     repoDigests,
+		Comment:         comment,
+		Created:         img.Image.Created.Format(time.RFC3339Nano),
+		Container:       img.Image.Container,
+		ContainerConfig: &img.Image.ContainerConfig,
+		DockerVersion:   img.Image.DockerVersion,
+		Author:          img.Image.Author,
+		Config:          img.Image.Config,
+		Architecture:    img.Image.Architecture,
+		Os:              img.Image.OS,
+		Size:            img.Size,
+		VirtualSize:     img.Size, // TODO: field unused, deprecate
+		RootFS:          rootFSToAPIType(img.Image.RootFS),
+	}
+
+	daemon.remoteInspectCache.add(key, imageInspect)
+	return imageInspect, nil
+}
+
+// LookupImageManifest returns the manifest, or manifest list, name points to
+// in its registry, as served by the registry.
+func (daemon *Daemon) LookupImageManifest(ctx context.Context, name string, metaHeaders map[string][]string, authConfig *types.AuthConfig) (*types.ImageManifest, error) {
+	resolved, err := daemon.resolveShortName(name)
+	if err != nil {
+		return nil, err
+	}
+	ref, err := reference.ParseNamed(resolved)
+	if err != nil {
+		return nil, err
+	}
+	ref = reference.WithDefaultTag(ref)
+
+	m, err := distribution.InspectManifest(ctx, ref, &distribution.ImageInspectConfig{
+		MetaHeaders:     metaHeaders,
+		AuthConfig:      authConfig,
+		RegistryService: daemon.RegistryService,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var versioned manifest.Versioned
+	if err := json.Unmarshal(m.Payload, &versioned); err != nil {
+		return nil, err
+	}
+
+	return &types.ImageManifest{
+		Name:          ref.String(),
+		Digest:        m.Digest.String(),
+		MediaType:     m.MediaType,
+		SchemaVersion: versioned.SchemaVersion,
+		Size:          int64(len(m.Payload)),
+		Manifest:      json.RawMessage(m.Payload),
+	}, nil
+}
@@ -0,0 +1,50 @@
+package daemon
+
+import (
+	"fmt"
+
+	"github.com/docker/distribution/digest"
+	"github.com/docker/docker/errors"
+	"github.com/docker/docker/reference"
+	"github.com/docker/engine-api/types"
+)
+
+// ImagePins returns the image tags pinned to a digest.
+func (daemon *Daemon) ImagePins() []types.ImagePin {
+	pins := []types.ImagePin{}
+	for _, pin := range daemon.pinStore.Pins() {
+		pins = append(pins, types.ImagePin{
+			Reference: pin.Ref.String(),
+			Digest:    pin.Digest.String(),
+		})
+	}
+	return pins
+}
+
+// PinImage pins the image tag refOrName to dgst. Pulls of the tag will
+// only accept the manifest with that digest.
+func (daemon *Daemon) PinImage(refOrName, dgst string) error {
+	ref, err := reference.ParseNamed(refOrName)
+	if err != nil {
+		return err
+	}
+	d, err := digest.ParseDigest(dgst)
+	if err != nil {
+		return fmt.Errorf("invalid digest %q: %v", dgst, err)
+	}
+	return daemon.pinStore.Pin(ref, d)
+}
+
+// UnpinImage removes the pin of the image tag refOrName.
+func (daemon *Daemon) UnpinImage(refOrName string) error {
+	ref, err := reference.ParseNamed(refOrName)
+	if err != nil {
+		return err
+	}
+	if _, err := daemon.pinStore.Unpin(ref); err == reference.ErrDoesNotExist {
+		return errors.NewRequestNotFoundError(fmt.Errorf("No pin for %s", reference.WithDefaultTag(ref).String()))
+	} else if err != nil {
+		return err
+	}
+	return nil
+}
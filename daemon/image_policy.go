@@ -0,0 +1,95 @@
+package daemon
+
+import (
+	"fmt"
+	"path"
+	"strings"
+
+	"github.com/docker/docker/image"
+)
+
+// validateImagePattern validates a pattern of the image policy: a shell
+// pattern matching repository names with their registry, like
+// registry.example.com/approved/*.
+func validateImagePattern(val string) (string, error) {
+	if val == "" || strings.Contains(val, "@") || strings.HasSuffix(val, "/") {
+		return "", fmt.Errorf("invalid image pattern %q: must match repository names, like registry.example.com/approved/*", val)
+	}
+	if _, err := path.Match(val, ""); err != nil {
+		return "", fmt.Errorf("invalid image pattern %q: %v", val, err)
+	}
+	return val, nil
+}
+
+// matchImagePattern tells whether the repository name matches the pattern.
+// As in shell patterns, a * does not match slashes, but a trailing /*
+// matches all the repositories under a path.
+func matchImagePattern(pattern, name string) bool {
+	if m, _ := path.Match(pattern, name); m {
+		return true
+	}
+	if !strings.HasSuffix(pattern, "/*") {
+		return false
+	}
+	prefix := strings.TrimSuffix(pattern, "/*")
+	for i := strings.LastIndex(name, "/"); i > 0; i = strings.LastIndex(name[:i], "/") {
+		if m, _ := path.Match(prefix, name[:i]); m {
+			return true
+		}
+	}
+	return false
+}
+
+// checkImagePolicy returns an error if the repository names of an image,
+// like docker.io/library/busybox, are not allowed by the allowed and
+// blocked patterns.
+func checkImagePolicy(names, allowed, blocked []string) error {
+	for _, name := range names {
+		for _, pattern := range blocked {
+			if matchImagePattern(pattern, name) {
+				return fmt.Errorf("%s matches the blocked image pattern %s", name, pattern)
+			}
+		}
+	}
+	if len(allowed) == 0 {
+		return nil
+	}
+	for _, name := range names {
+		for _, pattern := range allowed {
+			if matchImagePattern(pattern, name) {
+				return nil
+			}
+		}
+	}
+	if len(names) == 0 {
+		return fmt.Errorf("the image has no repository name, and only the images matching %s are allowed", strings.Join(allowed, ", "))
+	}
+	return fmt.Errorf("none of %s matches the allowed image patterns %s", strings.Join(names, ", "), strings.Join(allowed, ", "))
+}
+
+// verifyImagePolicy returns a 403 error if containers may not be created or
+// started from the image, referred to as refOrID, as none of its references
+// is allowed or one of them is blocked by the image policy of the daemon.
+// References by digest count with their repository name.
+func (daemon *Daemon) verifyImagePolicy(refOrID string, imgID image.ID) error {
+	if daemon.configStore == nil {
+		return nil
+	}
+	allowed, blocked := daemon.configStore.AllowedImages, daemon.configStore.BlockedImages
+	if len(allowed) == 0 && len(blocked) == 0 {
+		return nil
+	}
+
+	var names []string
+	seen := make(map[string]bool)
+	for _, ref := range daemon.referenceStore.References(imgID) {
+		if name := ref.FullName(); !seen[name] {
+			seen[name] = true
+			names = append(names, name)
+		}
+	}
+	if err := checkImagePolicy(names, allowed, blocked); err != nil {
+		return errImageBlocked(refOrID, err)
+	}
+	return nil
+}
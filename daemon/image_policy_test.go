@@ -0,0 +1,118 @@
+package daemon
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/docker/docker/errors"
+	"github.com/docker/docker/image"
+	"github.com/docker/docker/reference"
+)
+
+func TestMatchImagePattern(t *testing.T) {
+	for _, tc := range []struct {
+		pattern string
+		name    string
+		match   bool
+	}{
+		{"registry.example.com/approved/*", "registry.example.com/approved/app", true},
+		{"registry.example.com/approved/*", "registry.example.com/approved/team/app", true},
+		{"registry.example.com/approved/*", "registry.example.com/approvedx/app", false},
+		{"registry.example.com/approved/*", "registry.example.com/app", false},
+		{"docker.io/library/busybox", "docker.io/library/busybox", true},
+		{"docker.io/library/busybox", "docker.io/library/busybox2", false},
+		{"*.example.com/*", "registry.example.com/approved/app", true},
+		{"docker.io/*/app", "docker.io/team/app", true},
+		{"docker.io/*/app", "docker.io/team/sub/app", false},
+	} {
+		if match := matchImagePattern(tc.pattern, tc.name); match != tc.match {
+			t.Fatalf("expected %s to match %s: %v, got %v", tc.pattern, tc.name, tc.match, match)
+		}
+	}
+}
+
+func TestCheckImagePolicy(t *testing.T) {
+	allowed := []string{"registry.example.com/approved/*"}
+	blocked := []string{"registry.example.com/approved/legacy/*"}
+
+	for _, tc := range []struct {
+		names   []string
+		allowed bool
+	}{
+		{[]string{"registry.example.com/approved/app"}, true},
+		// one allowed name is enough
+		{[]string{"docker.io/library/app", "registry.example.com/approved/app"}, true},
+		{[]string{"docker.io/library/app"}, false},
+		// images without names are not allowed when an allow list is set
+		{nil, false},
+		// blocked names win over allowed ones
+		{[]string{"registry.example.com/approved/legacy/app"}, false},
+	} {
+		err := checkImagePolicy(tc.names, allowed, blocked)
+		if (err == nil) != tc.allowed {
+			t.Fatalf("expected %v to be allowed: %v, got %v", tc.names, tc.allowed, err)
+		}
+	}
+
+	if err := checkImagePolicy(nil, nil, blocked); err != nil {
+		t.Fatalf("expected images without names to be allowed without an allow list, got %v", err)
+	}
+}
+
+func TestValidateImagePattern(t *testing.T) {
+	for _, pattern := range []string{"registry.example.com/approved/*", "docker.io/library/busybox", "*"} {
+		if _, err := validateImagePattern(pattern); err != nil {
+			t.Fatalf("expected %s to be valid: %v", pattern, err)
+		}
+	}
+	for _, pattern := range []string{"", "registry.example.com/approved/", "busybox@sha256:abc", "registry.example.com/[approved"} {
+		if _, err := validateImagePattern(pattern); err == nil {
+			t.Fatalf("expected %s to be invalid", pattern)
+		}
+	}
+}
+
+func TestVerifyImagePolicy(t *testing.T) {
+	tmp, err := ioutil.TempDir("", "docker-image-policy-")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(tmp)
+	store, err := reference.NewReferenceStore(filepath.Join(tmp, "repositories.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	ref, err := reference.ParseNamed("busybox:latest")
+	if err != nil {
+		t.Fatal(err)
+	}
+	imgID := image.ID("sha256:0123456789abcdef0123456789abcdef0123456789abcdef0123456789abcdef")
+	if err := store.AddTag(ref, imgID, false); err != nil {
+		t.Fatal(err)
+	}
+
+	daemon := &Daemon{
+		configStore:    &Config{},
+		referenceStore: store,
+	}
+	daemon.configStore.AllowedImages = []string{"registry.example.com/approved/*"}
+	err = daemon.verifyImagePolicy("busybox", imgID)
+	apiErr, ok := err.(interface {
+		HTTPErrorStatusCode() int
+		ErrorCode() string
+		ErrorDetails() map[string]string
+	})
+	if !ok {
+		t.Fatalf("expected an API error, got %v", err)
+	}
+	if apiErr.HTTPErrorStatusCode() != 403 || apiErr.ErrorCode() != errors.CodeImageBlocked || apiErr.ErrorDetails()["image"] != "busybox" {
+		t.Fatalf("expected a 403 %s error for busybox, got %d %s %v", errors.CodeImageBlocked, apiErr.HTTPErrorStatusCode(), apiErr.ErrorCode(), apiErr.ErrorDetails())
+	}
+
+	daemon.configStore.AllowedImages = []string{"docker.io/library/*"}
+	if err := daemon.verifyImagePolicy("busybox", imgID); err != nil {
+		t.Fatalf("expected busybox to be allowed, got %v", err)
+	}
+}
@@ -0,0 +1,88 @@
+package daemon
+
+import (
+	"fmt"
+	"path"
+
+	"github.com/docker/docker/image"
+	"github.com/docker/engine-api/types/filters"
+)
+
+// provenanceLabels maps the provenance filters of the image list to the
+// well-known labels holding the license, the revision and the build date of
+// images, in order of preference.
+var provenanceLabels = map[string][]string{
+	"license": {
+		"org.opencontainers.image.licenses",
+		"org.label-schema.license",
+		"license",
+		"License",
+	},
+	"vcs-ref": {
+		"org.opencontainers.image.revision",
+		"org.label-schema.vcs-ref",
+		"vcs-ref",
+	},
+	"build-date": {
+		"org.opencontainers.image.created",
+		"org.label-schema.build-date",
+		"build-date",
+	},
+}
+
+// imageProvenance returns the value of the provenance field of the image,
+// from the first of its well-known labels the image has.
+func imageProvenance(img *image.Image, field string) (string, bool) {
+	if img.Config == nil {
+		return "", false
+	}
+	for _, label := range provenanceLabels[field] {
+		if v, ok := img.Config.Labels[label]; ok {
+			return v, true
+		}
+	}
+	return "", false
+}
+
+// validateProvenanceFilters checks the shell patterns of the provenance
+// filters.
+func validateProvenanceFilters(imageFilters filters.Args) error {
+	for field := range provenanceLabels {
+		err := imageFilters.WalkValues(field, func(value string) error {
+			if _, err := path.Match(value, ""); err != nil {
+				return fmt.Errorf("Invalid filter '%s=%s': %v", field, value, err)
+			}
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// matchProvenance tells whether the image matches the provenance filters:
+// for each filtered field, its value matches one of the shell patterns of
+// the filter.
+func matchProvenance(imageFilters filters.Args, img *image.Image) bool {
+	for field := range provenanceLabels {
+		if !imageFilters.Include(field) {
+			continue
+		}
+		v, ok := imageProvenance(img, field)
+		if !ok {
+			return false
+		}
+		matched := false
+		imageFilters.WalkValues(field, func(pattern string) error {
+			if m, _ := path.Match(pattern, v); m {
+				matched = true
+			}
+			return nil
+		})
+		if !matched {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,63 @@
+package daemon
+
+import (
+	"testing"
+
+	"github.com/docker/docker/image"
+	containertypes "github.com/docker/engine-api/types/container"
+	"github.com/docker/engine-api/types/filters"
+)
+
+func provenanceTestImage(labels map[string]string) *image.Image {
+	return &image.Image{V1Image: image.V1Image{Config: &containertypes.Config{Labels: labels}}}
+}
+
+func TestMatchProvenance(t *testing.T) {
+	gpl := provenanceTestImage(map[string]string{"License": "GPLv2", "vcs-ref": "a1b2c3d", "build-date": "2016-09-20T10:02:11Z"})
+	mit := provenanceTestImage(map[string]string{"org.opencontainers.image.licenses": "MIT", "org.label-schema.license": "GPLv3"})
+	unlabeled := provenanceTestImage(nil)
+
+	for _, tc := range []struct {
+		filters []string
+		matches []*image.Image
+	}{
+		{nil, []*image.Image{gpl, mit, unlabeled}},
+		{[]string{"license=GPL*"}, []*image.Image{gpl}},
+		// the preferred label wins
+		{[]string{"license=MIT"}, []*image.Image{mit}},
+		{[]string{"license=GPL*", "license=MIT"}, []*image.Image{gpl, mit}},
+		{[]string{"license=GPL*", "vcs-ref=a1b2*"}, []*image.Image{gpl}},
+		{[]string{"license=GPL*", "vcs-ref=ffff*"}, nil},
+		{[]string{"build-date=2016-09-*"}, []*image.Image{gpl}},
+	} {
+		args := filters.NewArgs()
+		for _, f := range tc.filters {
+			var err error
+			if args, err = filters.ParseFlag(f, args); err != nil {
+				t.Fatal(err)
+			}
+		}
+		if err := validateProvenanceFilters(args); err != nil {
+			t.Fatal(err)
+		}
+		for _, img := range []*image.Image{gpl, mit, unlabeled} {
+			expected := false
+			for _, m := range tc.matches {
+				expected = expected || m == img
+			}
+			if matched := matchProvenance(args, img); matched != expected {
+				t.Fatalf("expected %v to match %v: %v, got %v", img.Config.Labels, tc.filters, expected, matched)
+			}
+		}
+	}
+}
+
+func TestValidateProvenanceFilters(t *testing.T) {
+	args, err := filters.ParseFlag("license=GPL[", filters.NewArgs())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := validateProvenanceFilters(args); err == nil {
+		t.Fatal("expected an invalid pattern to be rejected")
+	}
+}
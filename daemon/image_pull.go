@@ -7,6 +7,7 @@ import (
 	"github.com/docker/distribution/digest"
 	"github.com/docker/docker/builder"
 	"github.com/docker/docker/distribution"
+	"github.com/docker/docker/errors"
 	"github.com/docker/docker/pkg/progress"
 	"github.com/docker/docker/reference"
 	"github.com/docker/docker/registry"
@@ -15,13 +16,21 @@ import (
 )
 
 // PullImage initiates a pull operation. image is the repository name to pull, and
-// tag may be either empty, or indicate a specific tag to pull.
-func (daemon *Daemon) PullImage(ctx context.Context, image, tag string, metaHeaders map[string][]string, authConfig *types.AuthConfig, outStream io.Writer) error {
+// tag may be either empty, or indicate a specific tag to pull. platform selects
+// the image to pull from a manifest list; the daemon's platform is used if it
+// is empty. Short names with an alias pull the repository of the alias. The
+// pull is refused if it would leave less than the min-free-space of the
+// daemon free, unless ignoreFreeSpace is set.
+func (daemon *Daemon) PullImage(ctx context.Context, image, tag, platform string, ignoreFreeSpace bool, metaHeaders map[string][]string, authConfig *types.AuthConfig, outStream io.Writer) error {
 	// Special case: "pull -a" may send an image name with a
 	// trailing :. This is ugly, but let's not break API
 	// compatibility.
 	image = strings.TrimSuffix(image, ":")
 
+	image, err := daemon.resolveShortName(image)
+	if err != nil {
+		return err
+	}
 	ref, err := reference.ParseNamed(image)
 	if err != nil {
 		return err
@@ -41,12 +50,25 @@ func (daemon *Daemon) PullImage(ctx context.Context, image, tag string, metaHead
 		}
 	}
 
-	return daemon.pullImageWithReference(ctx, ref, metaHeaders, authConfig, outStream)
+	var p distribution.Platform
+	if platform != "" {
+		if p, err = distribution.ParsePlatform(platform); err != nil {
+			return errors.NewBadRequestError(err)
+		}
+	}
+
+	return daemon.pullImageWithReference(ctx, ref, p, ignoreFreeSpace, metaHeaders, authConfig, outStream)
 }
 
-// PullOnBuild tells Docker to pull image referenced by `name`.
-func (daemon *Daemon) PullOnBuild(ctx context.Context, name string, authConfigs map[string]types.AuthConfig, output io.Writer) (builder.Image, error) {
-	ref, err := reference.ParseNamed(name)
+// PullOnBuild tells Docker to pull image referenced by `name`. The pull is
+// refused if it would leave less than the min-free-space of the daemon free,
+// unless ignoreFreeSpace is set.
+func (daemon *Daemon) PullOnBuild(ctx context.Context, name string, authConfigs map[string]types.AuthConfig, ignoreFreeSpace bool, output io.Writer) (builder.Image, error) {
+	resolved, err := daemon.resolveShortName(name)
+	if err != nil {
+		return nil, err
+	}
+	ref, err := reference.ParseNamed(resolved)
 	if err != nil {
 		return nil, err
 	}
@@ -67,13 +89,26 @@ func (daemon *Daemon) PullOnBuild(ctx context.Context, name string, authConfigs
 		pullRegistryAuth = &resolvedConfig
 	}
 
-	if err := daemon.pullImageWithReference(ctx, ref, nil, pullRegistryAuth, output); err != nil {
+	if err := daemon.pullImageWithReference(ctx, ref, distribution.Platform{}, ignoreFreeSpace, nil, pullRegistryAuth, output); err != nil {
 		return nil, err
 	}
 	return daemon.GetImage(name)
 }
 
-func (daemon *Daemon) pullImageWithReference(ctx context.Context, ref reference.Named, metaHeaders map[string][]string, authConfig *types.AuthConfig, outStream io.Writer) error {
+// pullImageWithReference pulls ref. It is the pull of the API, the builder,
+// the bootstrap spec and the registry cache, so the pull is refused there if
+// it would leave less than the min-free-space of the daemon free, unless
+// ignoreFreeSpace is set.
+func (daemon *Daemon) pullImageWithReference(ctx context.Context, ref reference.Named, platform distribution.Platform, ignoreFreeSpace bool, metaHeaders map[string][]string, authConfig *types.AuthConfig, outStream io.Writer) error {
+	if !ignoreFreeSpace {
+		if threshold, _ := daemon.configStore.minFreeSpace(); threshold != nil {
+			projected := daemon.projectedPullSize(ctx, ref, platform, metaHeaders, authConfig)
+			if err := daemon.CheckFreeSpace("pull "+ref.String(), projected); err != nil {
+				return err
+			}
+		}
+	}
+
 	// Include a buffer so that slow client connections don't affect
 	// transfer performance.
 	progressChan := make(chan progress.Progress, 100)
@@ -96,7 +131,9 @@ func (daemon *Daemon) pullImageWithReference(ctx context.Context, ref reference.
 		MetadataStore:    daemon.distributionMetadataStore,
 		ImageStore:       daemon.imageStore,
 		ReferenceStore:   daemon.referenceStore,
+		PinStore:         daemon.pinStore,
 		DownloadManager:  daemon.downloadManager,
+		Platform:         platform,
 	}
 
 	err := distribution.Pull(ctx, ref, imagePullConfig)
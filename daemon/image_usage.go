@@ -0,0 +1,110 @@
+package daemon
+
+import (
+	"sync"
+	"time"
+
+	"github.com/Sirupsen/logrus"
+	"github.com/docker/docker/image"
+)
+
+// imageUsageFlushInterval is how often the last uses of images are written
+// to the image store.
+const imageUsageFlushInterval = time.Minute
+
+// imageUsage holds the last uses of images not written to the image store
+// yet, so that creating and starting containers does not write the metadata
+// of their image each time. The uses are written every
+// imageUsageFlushInterval, and when the daemon shuts down.
+type imageUsage struct {
+	mu      sync.Mutex
+	pending map[image.ID]time.Time
+}
+
+func newImageUsage() *imageUsage {
+	return &imageUsage{pending: make(map[image.ID]time.Time)}
+}
+
+func (u *imageUsage) add(id image.ID, t time.Time) {
+	u.mu.Lock()
+	u.pending[id] = t
+	u.mu.Unlock()
+}
+
+// get returns the last use of the image id, if it was not written yet.
+func (u *imageUsage) get(id image.ID) (time.Time, bool) {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	t, ok := u.pending[id]
+	return t, ok
+}
+
+func (u *imageUsage) remove(id image.ID) {
+	u.mu.Lock()
+	delete(u.pending, id)
+	u.mu.Unlock()
+}
+
+// flush writes the pending uses to store. The lock is held meanwhile, so
+// that the uses being written are still found by get.
+func (u *imageUsage) flush(store image.Store) {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	for id, t := range u.pending {
+		if err := store.SetLastUsed(id, t); err != nil {
+			logrus.Warnf("Failed to record the use of image %s: %v", id, err)
+		}
+		delete(u.pending, id)
+	}
+}
+
+// imageUsed records that a container is created or started from the image
+// with the given ID, for the LastUsed of the image and the unused-since
+// filter of images.
+func (daemon *Daemon) imageUsed(id image.ID) {
+	if id == "" {
+		return
+	}
+	daemon.imageUsage.add(id, time.Now())
+}
+
+// imageLastUsed returns the last time a container was created or started
+// from the image with the given ID, or the zero time if it was never used.
+func (daemon *Daemon) imageLastUsed(id image.ID) (time.Time, error) {
+	if t, ok := daemon.imageUsage.get(id); ok {
+		return t, nil
+	}
+	return daemon.imageStore.GetLastUsed(id)
+}
+
+// flushImageUsage writes the last uses of images to the image store every
+// imageUsageFlushInterval.
+func (daemon *Daemon) flushImageUsage() {
+	for range time.Tick(imageUsageFlushInterval) {
+		daemon.imageUsage.flush(daemon.imageStore)
+	}
+}
+
+// imageContainers returns the number of containers, running or not, created
+// from each image.
+func (daemon *Daemon) imageContainers() map[image.ID]int {
+	counts := make(map[image.ID]int)
+	for _, c := range daemon.List() {
+		if c.ImageID != "" {
+			counts[c.ImageID]++
+		}
+	}
+	return counts
+}
+
+// imageUnusedSince tells whether the image has no container and was last
+// used, or created if it was never used, before t.
+func imageUnusedSince(img *image.Image, containers int, lastUsed, t time.Time) bool {
+	if containers > 0 {
+		return false
+	}
+	if lastUsed.IsZero() {
+		lastUsed = img.Created
+	}
+	return lastUsed.Before(t)
+}
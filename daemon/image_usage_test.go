@@ -0,0 +1,77 @@
+package daemon
+
+import (
+	"testing"
+	"time"
+
+	"github.com/docker/docker/image"
+)
+
+func TestImageUnusedSince(t *testing.T) {
+	now := time.Now()
+	img := &image.Image{V1Image: image.V1Image{Created: now.Add(-48 * time.Hour)}}
+	since := now.Add(-24 * time.Hour)
+
+	for _, tc := range []struct {
+		containers int
+		lastUsed   time.Time
+		unused     bool
+	}{
+		// never used: the creation of the image counts
+		{0, time.Time{}, true},
+		{0, now.Add(-36 * time.Hour), true},
+		{0, now.Add(-time.Hour), false},
+		// images with containers are always in use
+		{1, now.Add(-36 * time.Hour), false},
+		{2, time.Time{}, false},
+	} {
+		if unused := imageUnusedSince(img, tc.containers, tc.lastUsed, since); unused != tc.unused {
+			t.Fatalf("expected an image with %d containers last used at %v to be unused: %v, got %v", tc.containers, tc.lastUsed, tc.unused, unused)
+		}
+	}
+}
+
+// lastUsedStore records the last uses written to an image store.
+type lastUsedStore struct {
+	image.Store
+	lastUsed map[image.ID]time.Time
+}
+
+func (s *lastUsedStore) SetLastUsed(id image.ID, t time.Time) error {
+	s.lastUsed[id] = t
+	return nil
+}
+
+func TestImageUsageFlush(t *testing.T) {
+	store := &lastUsedStore{lastUsed: make(map[image.ID]time.Time)}
+	u := newImageUsage()
+	id := image.ID("sha256:abcdef")
+	first := time.Now().Add(-time.Minute)
+	now := time.Now()
+
+	u.add(id, first)
+	u.add(id, now)
+	if len(store.lastUsed) != 0 {
+		t.Fatalf("expected the uses not to be written before a flush, got %v", store.lastUsed)
+	}
+	if last, ok := u.get(id); !ok || !last.Equal(now) {
+		t.Fatalf("expected the pending last use %v, got %v", now, last)
+	}
+
+	u.flush(store)
+	if last := store.lastUsed[id]; !last.Equal(now) {
+		t.Fatalf("expected the last use %v to be written, got %v", now, last)
+	}
+	if _, ok := u.get(id); ok {
+		t.Fatal("expected no pending use once flushed")
+	}
+
+	// the uses of removed images are not written
+	other := image.ID("sha256:012345")
+	u.add(other, now)
+	u.remove(other)
+	u.flush(store)
+	if _, ok := store.lastUsed[other]; ok {
+		t.Fatal("expected the use of a removed image not to be written")
+	}
+}
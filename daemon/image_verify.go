@@ -0,0 +1,215 @@
+package daemon
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"sync"
+	"time"
+
+	"github.com/docker/distribution/digest"
+	"github.com/docker/distribution/manifest"
+	"github.com/docker/distribution/manifest/manifestlist"
+	"github.com/docker/distribution/manifest/schema1"
+	"github.com/docker/distribution/manifest/schema2"
+	"github.com/docker/docker/distribution/metadata"
+	"github.com/docker/docker/errors"
+	"github.com/docker/docker/image"
+	"github.com/docker/docker/layer"
+	"github.com/docker/docker/reference"
+	"github.com/docker/engine-api/types"
+)
+
+// verifiedImageTTL is how long the verification of the local content of an
+// image is trusted before the content is verified again.
+const verifiedImageTTL = time.Hour
+
+// verifiedImage is an image verified against the digest it was referred to
+// by, or only against the diff IDs of its layers if the digest is empty.
+type verifiedImage struct {
+	id   image.ID
+	dgst digest.Digest
+}
+
+// verifiedImages caches when the local content of images was verified, so
+// that creating many containers from an image does not read all of its
+// layers each time. A verification expires after a while, so that content
+// altered since is noticed, and an image is forgotten when it is removed,
+// so that it is verified again once pulled again.
+type verifiedImages struct {
+	mu      sync.Mutex
+	ttl     time.Duration
+	entries map[verifiedImage]time.Time
+}
+
+func newVerifiedImages(ttl time.Duration) *verifiedImages {
+	return &verifiedImages{ttl: ttl, entries: make(map[verifiedImage]time.Time)}
+}
+
+// get returns when the content of the image id was verified against dgst,
+// if it was and the verification has not expired.
+func (v *verifiedImages) get(id image.ID, dgst digest.Digest) (time.Time, bool) {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	key := verifiedImage{id, dgst}
+	t, ok := v.entries[key]
+	if ok && time.Since(t) > v.ttl {
+		delete(v.entries, key)
+		return time.Time{}, false
+	}
+	return t, ok
+}
+
+func (v *verifiedImages) add(id image.ID, dgst digest.Digest, t time.Time) {
+	v.mu.Lock()
+	v.entries[verifiedImage{id, dgst}] = t
+	v.mu.Unlock()
+}
+
+func (v *verifiedImages) remove(id image.ID) {
+	v.mu.Lock()
+	for key := range v.entries {
+		if key.id == id {
+			delete(v.entries, key)
+		}
+	}
+	v.mu.Unlock()
+}
+
+// verifyLocalImage verifies that the local content of the image referred to
+// by digest as refName is the content this digest was pulled as: the
+// manifest kept on pull is hashed again against the digest, the image
+// configuration and the layers it lists must be those of the image, and
+// each layer is assembled again and checked against its diff ID.
+func (daemon *Daemon) verifyLocalImage(refName string) (*types.ImageVerification, error) {
+	ref, err := reference.ParseNamed(refName)
+	if err != nil {
+		return nil, errors.NewBadRequestError(err)
+	}
+	canonical, ok := ref.(reference.Canonical)
+	if !ok {
+		return nil, errors.NewBadRequestError(fmt.Errorf("verifying the local content of image %s requires a reference by digest, like %s@sha256:...", refName, ref.Name()))
+	}
+
+	img, err := daemon.GetImage(refName)
+	if err != nil {
+		return nil, err
+	}
+	verification := &types.ImageVerification{
+		Digest:  canonical.Digest().String(),
+		ImageID: img.ID().String(),
+	}
+	if t, ok := daemon.verifiedImages.get(img.ID(), canonical.Digest()); ok {
+		verification.Verified = t
+		verification.Cached = true
+		return verification, nil
+	}
+
+	if err := daemon.verifyImageManifest(img, canonical.Digest()); err != nil {
+		return nil, fmt.Errorf("The local content of image %s does not match its digest: %v", refName, err)
+	}
+	if err := daemon.verifyImageLayers(img); err != nil {
+		return nil, fmt.Errorf("The local content of image %s does not match its digest: %v", refName, err)
+	}
+	verification.Verified = time.Now().UTC()
+	daemon.verifiedImages.add(img.ID(), canonical.Digest(), verification.Verified)
+	return verification, nil
+}
+
+// verifyImageManifest verifies that the manifest of digest dgst, as kept on
+// pull, describes the image img: a schema2 manifest must list the
+// configuration of the image and its layers, in order, a schema1 manifest
+// must list the layers of the image, and one of the manifests of a manifest
+// list must describe the image.
+func (daemon *Daemon) verifyImageManifest(img *image.Image, dgst digest.Digest) error {
+	content, err := metadata.NewManifestService(daemon.distributionMetadataStore).Get(dgst)
+	if err != nil {
+		return fmt.Errorf("the manifest %s is not available, the image must be pulled again: %v", dgst, err)
+	}
+	var versioned manifest.Versioned
+	if err := json.Unmarshal(content, &versioned); err != nil {
+		return err
+	}
+	v2Metadata := metadata.NewV2MetadataService(daemon.distributionMetadataStore)
+
+	switch {
+	case versioned.MediaType == manifestlist.MediaTypeManifestList:
+		var list manifestlist.ManifestList
+		if err := json.Unmarshal(content, &list); err != nil {
+			return err
+		}
+		for _, m := range list.Manifests {
+			if daemon.verifyImageManifest(img, m.Digest) == nil {
+				return nil
+			}
+		}
+		return fmt.Errorf("none of the manifests of the list %s describes image %s", dgst, img.ID())
+	case versioned.SchemaVersion == 2:
+		var m schema2.Manifest
+		if err := json.Unmarshal(content, &m); err != nil {
+			return err
+		}
+		if image.ID(m.Config.Digest) != img.ID() {
+			return fmt.Errorf("the manifest %s describes image %s, not %s", dgst, m.Config.Digest, img.ID())
+		}
+		if len(m.Layers) != len(img.RootFS.DiffIDs) {
+			return fmt.Errorf("the manifest %s has %d layers, the image %d", dgst, len(m.Layers), len(img.RootFS.DiffIDs))
+		}
+		for i, l := range m.Layers {
+			diffID, err := v2Metadata.GetDiffID(l.Digest)
+			if err != nil || diffID != img.RootFS.DiffIDs[i] {
+				return fmt.Errorf("layer %s of the manifest %s is not layer %s of the image", l.Digest, dgst, img.RootFS.DiffIDs[i])
+			}
+		}
+		return nil
+	case versioned.SchemaVersion == 1:
+		var m schema1.Manifest
+		if err := json.Unmarshal(content, &m); err != nil {
+			return err
+		}
+		pulled := make(map[layer.DiffID]bool)
+		for _, l := range m.FSLayers {
+			if diffID, err := v2Metadata.GetDiffID(l.BlobSum); err == nil {
+				pulled[diffID] = true
+			}
+		}
+		for _, diffID := range img.RootFS.DiffIDs {
+			if !pulled[diffID] {
+				return fmt.Errorf("layer %s of the image is not in the manifest %s", diffID, dgst)
+			}
+		}
+		return nil
+	}
+	return fmt.Errorf("unsupported manifest format for %s", dgst)
+}
+
+// verifyImageLayers reads the content of each layer of the image, which
+// fails if it does not match the diff ID of the layer.
+func (daemon *Daemon) verifyImageLayers(img *image.Image) error {
+	if len(img.RootFS.DiffIDs) == 0 {
+		return nil
+	}
+	l, err := daemon.layerStore.Get(img.RootFS.ChainID())
+	if err != nil {
+		return err
+	}
+	defer layer.ReleaseAndLog(daemon.layerStore, l)
+
+	for ; l != nil; l = l.Parent() {
+		if err := verifyLayer(l); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func verifyLayer(l layer.Layer) error {
+	rc, err := l.TarStream()
+	if err != nil {
+		return err
+	}
+	defer rc.Close()
+	_, err = io.Copy(ioutil.Discard, rc)
+	return err
+}
@@ -0,0 +1,155 @@
+package daemon
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/docker/distribution/digest"
+	"github.com/docker/docker/distribution/metadata"
+	"github.com/docker/docker/image"
+	"github.com/docker/docker/layer"
+)
+
+func TestVerifyLocalImageRequiresDigest(t *testing.T) {
+	daemon := &Daemon{verifiedImages: newVerifiedImages(verifiedImageTTL)}
+	for _, refName := range []string{"busybox", "busybox:latest", "registry.example.com/app:1.0"} {
+		_, err := daemon.verifyLocalImage(refName)
+		if err == nil || !strings.Contains(err.Error(), "requires a reference by digest") {
+			t.Fatalf("expected %s to be refused, got %v", refName, err)
+		}
+	}
+}
+
+func TestVerifiedImages(t *testing.T) {
+	v := newVerifiedImages(time.Hour)
+	id := image.ID("sha256:0123456789012345678901234567890123456789012345678901234567890123")
+	dgst := digest.Digest("sha256:4567456745674567456745674567456745674567456745674567456745674567")
+	if _, ok := v.get(id, dgst); ok {
+		t.Fatal("expected the image not to be verified")
+	}
+
+	now := time.Now()
+	v.add(id, dgst, now)
+	if verified, ok := v.get(id, dgst); !ok || !verified.Equal(now) {
+		t.Fatalf("expected the image to be verified at %v, got %v", now, verified)
+	}
+	if _, ok := v.get(id, digest.FromBytes([]byte("other"))); ok {
+		t.Fatal("expected the image not to be verified against another digest")
+	}
+
+	v.remove(id)
+	if _, ok := v.get(id, dgst); ok {
+		t.Fatal("expected a removed image to be verified again")
+	}
+
+	v.add(id, dgst, now.Add(-2*time.Hour))
+	if _, ok := v.get(id, dgst); ok {
+		t.Fatal("expected an expired verification to be verified again")
+	}
+}
+
+// nopLayerGetReleaser is a layer store which holds any layer.
+type nopLayerGetReleaser struct{}
+
+func (nopLayerGetReleaser) Get(layer.ChainID) (layer.Layer, error) {
+	return nil, nil
+}
+
+func (nopLayerGetReleaser) Release(layer.Layer) ([]layer.Metadata, error) {
+	return nil, nil
+}
+
+func TestVerifyImageManifest(t *testing.T) {
+	tmp, err := ioutil.TempDir("", "docker-image-verify-test-")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(tmp)
+
+	fs, err := image.NewFSStoreBackend(tmp + "/imagedb")
+	if err != nil {
+		t.Fatal(err)
+	}
+	is, err := image.NewImageStore(fs, nopLayerGetReleaser{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	diffID := layer.DiffID("sha256:86e0e091d0da6bde2456dbb48306f3956bbeb2eae1b5b9a43045843f69fe4aaa")
+	id, err := is.Create([]byte(`{"rootfs": {"type": "layers", "diff_ids": ["` + diffID + `"]}}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	img, err := is.Get(id)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	store, err := metadata.NewFSMetadataStore(tmp + "/distribution")
+	if err != nil {
+		t.Fatal(err)
+	}
+	daemon := &Daemon{distributionMetadataStore: store}
+	blobSum := digest.FromBytes([]byte("layer"))
+	if err := metadata.NewV2MetadataService(store).Add(diffID, metadata.V2Metadata{Digest: blobSum}); err != nil {
+		t.Fatal(err)
+	}
+	keep := func(m interface{}) digest.Digest {
+		content, err := json.Marshal(m)
+		if err != nil {
+			t.Fatal(err)
+		}
+		dgst := digest.FromBytes(content)
+		if err := metadata.NewManifestService(store).Set(dgst, content); err != nil {
+			t.Fatal(err)
+		}
+		return dgst
+	}
+	type descriptor struct {
+		Digest digest.Digest `json:"digest"`
+	}
+
+	if err := daemon.verifyImageManifest(img, digest.FromBytes([]byte("unknown"))); err == nil || !strings.Contains(err.Error(), "pulled again") {
+		t.Fatalf("expected a manifest which was not kept to be refused, got %v", err)
+	}
+
+	manifest := keep(map[string]interface{}{
+		"schemaVersion": 2,
+		"mediaType":     "application/vnd.docker.distribution.manifest.v2+json",
+		"config":        descriptor{digest.Digest(id)},
+		"layers":        []descriptor{{blobSum}},
+	})
+	if err := daemon.verifyImageManifest(img, manifest); err != nil {
+		t.Fatal(err)
+	}
+
+	other := keep(map[string]interface{}{
+		"schemaVersion": 2,
+		"mediaType":     "application/vnd.docker.distribution.manifest.v2+json",
+		"config":        descriptor{digest.Digest(id)},
+		"layers":        []descriptor{{digest.FromBytes([]byte("other layer"))}},
+	})
+	if err := daemon.verifyImageManifest(img, other); err == nil {
+		t.Fatal("expected a manifest with other layers to be refused")
+	}
+
+	list := keep(map[string]interface{}{
+		"schemaVersion": 2,
+		"mediaType":     "application/vnd.docker.distribution.manifest.list.v2+json",
+		"manifests":     []descriptor{{other}, {manifest}},
+	})
+	if err := daemon.verifyImageManifest(img, list); err != nil {
+		t.Fatal(err)
+	}
+
+	schema1 := keep(map[string]interface{}{
+		"schemaVersion": 1,
+		"fsLayers":      []map[string]digest.Digest{{"blobSum": blobSum}},
+	})
+	if err := daemon.verifyImageManifest(img, schema1); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"path"
 	"sort"
+	"time"
 
 	"github.com/docker/docker/image"
 	"github.com/docker/docker/layer"
@@ -13,10 +14,15 @@ import (
 )
 
 var acceptedImageFilterTags = map[string]bool{
-	"dangling": true,
-	"label":    true,
-	"before":   true,
-	"since":    true,
+	"dangling":     true,
+	"label":        true,
+	"before":       true,
+	"since":        true,
+	"unused-since": true,
+	"license":      true,
+	"vcs-ref":      true,
+	"build-date":   true,
+	"reference":    true,
 }
 
 // byCreated is a temporary type used to sort a list of images by creation
@@ -36,8 +42,9 @@ func (daemon *Daemon) Map() map[image.ID]*image.Image {
 // of filter arguments which will be interpreted by api/types/filters.
 // filter is a shell glob string applied to repository names. The argument
 // named all controls whether all images in the graph are filtered, or just
-// the heads.
-func (daemon *Daemon) Images(filterArgs, filter string, all bool) ([]*types.Image, error) {
+// the heads. The offset first images, from the most recent, are skipped, and
+// at most limit images are returned if limit is positive.
+func (daemon *Daemon) Images(filterArgs, filter string, all bool, limit, offset int) ([]*types.Image, error) {
 	var (
 		allImages    map[image.ID]*image.Image
 		err          error
@@ -51,6 +58,9 @@ func (daemon *Daemon) Images(filterArgs, filter string, all bool) ([]*types.Imag
 	if err := imageFilters.Validate(acceptedImageFilterTags); err != nil {
 		return nil, err
 	}
+	if err := validateProvenanceFilters(imageFilters); err != nil {
+		return nil, err
+	}
 
 	if imageFilters.Include("dangling") {
 		if imageFilters.ExactMatch("dangling", "true") {
@@ -59,6 +69,17 @@ func (daemon *Daemon) Images(filterArgs, filter string, all bool) ([]*types.Imag
 			return nil, fmt.Errorf("Invalid filter 'dangling=%s'", imageFilters.Get("dangling"))
 		}
 	}
+
+	err = imageFilters.WalkValues("reference", func(value string) error {
+		if _, err := path.Match(value, ""); err != nil {
+			return fmt.Errorf("Invalid filter 'reference=%s': %v", value, err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
 	if danglingOnly {
 		allImages = daemon.imageStore.Heads()
 	} else {
@@ -82,7 +103,28 @@ func (daemon *Daemon) Images(filterArgs, filter string, all bool) ([]*types.Imag
 		return nil, err
 	}
 
+	var (
+		unusedSince time.Time
+		containers  map[image.ID]int
+	)
+	err = imageFilters.WalkValues("unused-since", func(value string) error {
+		d, err := time.ParseDuration(value)
+		if err != nil || d < 0 {
+			return fmt.Errorf("Invalid filter 'unused-since=%s': must be a duration, like 720h", value)
+		}
+		unusedSince = time.Now().Add(-d)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	if !unusedSince.IsZero() {
+		containers = daemon.imageContainers()
+	}
+
 	images := []*types.Image{}
+	// The sizes of the layers are only computed for the returned images
+	layerIDs := make(map[*types.Image]layer.ChainID)
 
 	var filterTagged bool
 	if filter != "" {
@@ -107,6 +149,16 @@ func (daemon *Daemon) Images(filterArgs, filter string, all bool) ([]*types.Imag
 			}
 		}
 
+		if !unusedSince.IsZero() {
+			lastUsed, err := daemon.imageLastUsed(id)
+			if err != nil {
+				return nil, err
+			}
+			if !imageUnusedSince(img, containers[id], lastUsed, unusedSince) {
+				continue
+			}
+		}
+
 		if imageFilters.Include("label") {
 			// Very old image that do not have image.Config (or even labels)
 			if img.Config == nil {
@@ -118,24 +170,16 @@ func (daemon *Daemon) Images(filterArgs, filter string, all bool) ([]*types.Imag
 			}
 		}
 
-		layerID := img.RootFS.ChainID()
-		var size int64
-		if layerID != "" {
-			l, err := daemon.layerStore.Get(layerID)
-			if err != nil {
-				return nil, err
-			}
-
-			size, err = l.Size()
-			layer.ReleaseAndLog(daemon.layerStore, l)
-			if err != nil {
-				return nil, err
-			}
+		if !matchProvenance(imageFilters, img) {
+			continue
 		}
 
-		newImage := newImage(img, size)
+		newImage := newImage(img, 0)
 
 		for _, ref := range daemon.referenceStore.References(id) {
+			if imageFilters.Include("reference") && !matchReference(imageFilters.Get("reference"), ref) {
+				continue
+			}
 			if filter != "" { // filter by tag/repo name
 				if filterTagged { // filter by tag, require full ref match
 					if ref.String() != filter {
@@ -159,7 +203,7 @@ func (daemon *Daemon) Images(filterArgs, filter string, all bool) ([]*types.Imag
 					//dangling=false case, so dangling image is not needed
 					continue
 				}
-				if filter != "" { // skip images with no references if filtering by tag
+				if filter != "" || imageFilters.Include("reference") { // skip images with no references if filtering by tag
 					continue
 				}
 				newImage.RepoDigests = []string{"<none>@<none>"}
@@ -172,13 +216,56 @@ func (daemon *Daemon) Images(filterArgs, filter string, all bool) ([]*types.Imag
 		}
 
 		images = append(images, newImage)
+		layerIDs[newImage] = img.RootFS.ChainID()
 	}
 
 	sort.Sort(sort.Reverse(byCreated(images)))
 
+	if offset > 0 {
+		if offset > len(images) {
+			offset = len(images)
+		}
+		images = images[offset:]
+	}
+	if limit > 0 && limit < len(images) {
+		images = images[:limit]
+	}
+
+	for _, newImage := range images {
+		layerID := layerIDs[newImage]
+		if layerID == "" {
+			continue
+		}
+		l, err := daemon.layerStore.Get(layerID)
+		if err != nil {
+			return nil, err
+		}
+		size, err := l.Size()
+		layer.ReleaseAndLog(daemon.layerStore, l)
+		if err != nil {
+			return nil, err
+		}
+		newImage.Size = size
+		newImage.VirtualSize = size
+	}
+
 	return images, nil
 }
 
+// matchReference returns whether the repository name or the full reference
+// of ref matches one of the shell glob patterns.
+func matchReference(patterns []string, ref reference.Named) bool {
+	for _, pattern := range patterns {
+		if matched, _ := path.Match(pattern, ref.String()); matched {
+			return true
+		}
+		if matched, _ := path.Match(pattern, ref.Name()); matched {
+			return true
+		}
+	}
+	return false
+}
+
 func newImage(image *image.Image, size int64) *types.Image {
 	newImage := new(types.Image)
 	newImage.ParentID = image.Parent.String()
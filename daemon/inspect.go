@@ -162,7 +162,7 @@ func (daemon *Daemon) getInspectData(container *container.Container, size bool)
 	}
 
 	// Now set any platform-specific fields
-	contJSONBase = setPlatformSpecificContainerFields(container, contJSONBase)
+	contJSONBase = daemon.setPlatformSpecificContainerFields(container, contJSONBase)
 
 	contJSONBase.GraphDriver.Name = container.Driver
 
@@ -184,6 +184,7 @@ func (daemon *Daemon) ContainerExecInspect(id string) (*backend.ExecInspect, err
 	}
 
 	pc := inspectExecProcessConfig(e)
+	stats, _ := execStats(e)
 
 	return &backend.ExecInspect{
 		ID:            e.ID,
@@ -196,6 +197,7 @@ func (daemon *Daemon) ContainerExecInspect(id string) (*backend.ExecInspect, err
 		CanRemove:     e.CanRemove,
 		ContainerID:   e.ContainerID,
 		DetachKeys:    e.DetachKeys,
+		Stats:         stats,
 	}, nil
 }
 
@@ -209,6 +211,7 @@ func (daemon *Daemon) VolumeInspect(name string) (*types.Volume, error) {
 	apiV := volumeToAPIType(v)
 	apiV.Mountpoint = v.Path()
 	apiV.Status = v.Status()
+	apiV.UsageData = daemon.volumeUsageData(v)
 	return apiV, nil
 }
 
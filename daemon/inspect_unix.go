@@ -11,7 +11,9 @@ import (
 )
 
 // This sets platform-specific fields
-func setPlatformSpecificContainerFields(container *container.Container, contJSONBase *types.ContainerJSONBase) *types.ContainerJSONBase {
+func (daemon *Daemon) setPlatformSpecificContainerFields(container *container.Container, contJSONBase *types.ContainerJSONBase) *types.ContainerJSONBase {
+	// Report the limits the container runs with, including the defaults
+	contJSONBase.HostConfig.Ulimits = daemon.mergeUlimits(container.HostConfig)
 	contJSONBase.AppArmorProfile = container.AppArmorProfile
 	contJSONBase.ResolvConfPath = container.ResolvConfPath
 	contJSONBase.HostnamePath = container.HostnamePath
@@ -70,7 +70,7 @@ func (daemon *Daemon) killWithSignal(container *container.Container, sig int) er
 
 	container.ExitOnNext()
 
-	if !daemon.IsShuttingDown() {
+	if !daemon.IsShuttingDown() && !daemon.IsDraining() {
 		container.HasBeenManuallyStopped = true
 	}
 
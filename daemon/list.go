@@ -3,6 +3,7 @@ package daemon
 import (
 	"errors"
 	"fmt"
+	"sort"
 	"strconv"
 	"strings"
 
@@ -31,6 +32,7 @@ var acceptedPsFilterTags = map[string]bool{
 	"label":     true,
 	"name":      true,
 	"status":    true,
+	"health":    true,
 	"since":     true,
 	"volume":    true,
 	"network":   true,
@@ -82,6 +84,8 @@ type listContext struct {
 	// sinceFilter is a filter to stop the filtering when the iterator arrive to the given container
 	// this is used for --filter=since= and --since=, the latter is deprecated.
 	sinceFilter *container.Container
+	// skipped is the number of matching containers skipped for the Offset of the options
+	skipped int
 	// ContainerListOptions is the filters set by the user
 	*types.ContainerListOptions
 }
@@ -149,7 +153,11 @@ func (daemon *Daemon) filterByNameIDMatches(ctx *listContext) []*container.Conta
 	for id := range matches {
 		cntrs = append(cntrs, daemon.containers.Get(id))
 	}
-	return cntrs
+	// Sort the matches like the full list, so that the limit
+	// and the offset select the same containers
+	history := container.History(cntrs)
+	sort.Sort(&history)
+	return history
 }
 
 // reduceContainers parses the user's filtering options and generates the list of containers to return based on a reducer.
@@ -196,6 +204,11 @@ func (daemon *Daemon) reducePsContainer(container *container.Container, ctx *lis
 		return nil, errStopIteration
 	}
 
+	if ctx.skipped < ctx.Offset {
+		ctx.skipped++
+		return nil, nil
+	}
+
 	// transform internal container struct into api structs
 	return reducer(container, ctx)
 }
@@ -234,6 +247,16 @@ func (daemon *Daemon) foldFilter(config *types.ContainerListOptions) (*listConte
 		return nil, err
 	}
 
+	err = psFilters.WalkValues("health", func(value string) error {
+		if !container.IsValidHealthString(value) {
+			return fmt.Errorf("Unrecognised filter value for health: %s", value)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
 	var beforeContFilter, sinceContFilter *container.Container
 
 	err = psFilters.WalkValues("before", func(value string) error {
@@ -352,6 +375,11 @@ func includeContainerInList(container *container.Container, ctx *listContext) it
 		return excludeContainer
 	}
 
+	// Do not include container if its health status doesn't match the filter
+	if !ctx.filters.ExactMatch("health", container.State.HealthString()) {
+		return excludeContainer
+	}
+
 	if ctx.filters.Include("volume") {
 		volumesByName := make(map[string]*volume.MountPoint)
 		for _, m := range container.MountPoints {
@@ -515,8 +543,9 @@ func (daemon *Daemon) transformContainer(container *container.Container, ctx *li
 }
 
 // Volumes lists known volumes, using the filter to restrict the range
-// of volumes returned.
-func (daemon *Daemon) Volumes(filter string) ([]*types.Volume, []string, error) {
+// of volumes returned. With size, the space used by the volumes and the
+// number of containers referencing them are returned too.
+func (daemon *Daemon) Volumes(filter string, size bool) ([]*types.Volume, []string, error) {
 	var (
 		volumesOut []*types.Volume
 	)
@@ -547,6 +576,9 @@ func (daemon *Daemon) Volumes(filter string) ([]*types.Volume, []string, error)
 		} else {
 			apiV.Mountpoint = v.Path()
 		}
+		if size {
+			apiV.UsageData = daemon.volumeUsageData(v)
+		}
 		volumesOut = append(volumesOut, apiV)
 	}
 	return volumesOut, warnings, nil
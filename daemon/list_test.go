@@ -0,0 +1,65 @@
+package daemon
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/docker/docker/container"
+	"github.com/docker/docker/pkg/registrar"
+	"github.com/docker/docker/pkg/truncindex"
+	"github.com/docker/engine-api/types"
+	containertypes "github.com/docker/engine-api/types/container"
+	"github.com/docker/engine-api/types/filters"
+)
+
+func TestReduceContainersOffset(t *testing.T) {
+	daemon := &Daemon{
+		containers: container.NewMemoryStore(),
+		idIndex:    truncindex.NewTruncIndex(nil),
+		nameIndex:  registrar.NewRegistrar(),
+	}
+	created := time.Now()
+	for i, id := range []string{"a", "b", "c", "d"} {
+		c := container.NewBaseContainer(id, "")
+		c.Name = "/" + id + id
+		c.Created = created.Add(time.Duration(i) * time.Second)
+		c.Config = &containertypes.Config{}
+		c.HostConfig = &containertypes.HostConfig{}
+		c.State.SetRunning(i+1, false)
+		daemon.containers.Add(c.ID, c)
+		daemon.idIndex.Add(c.ID)
+		if _, err := daemon.reserveName(c.ID, c.Name); err != nil {
+			t.Fatal(err)
+		}
+	}
+	reducer := func(c *container.Container, ctx *listContext) (*types.Container, error) {
+		return &types.Container{ID: c.ID}, nil
+	}
+	ids := func(config *types.ContainerListOptions) []string {
+		containers, err := daemon.reduceContainers(config, reducer)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var ids []string
+		for _, c := range containers {
+			ids = append(ids, c.ID)
+		}
+		return ids
+	}
+
+	if actual, expected := ids(&types.ContainerListOptions{Offset: 1, Limit: 2, Filter: filters.NewArgs()}), []string{"c", "b"}; !reflect.DeepEqual(actual, expected) {
+		t.Fatalf("expected %v, got %v", expected, actual)
+	}
+	if actual, expected := ids(&types.ContainerListOptions{Offset: 3, Filter: filters.NewArgs()}), []string{"a"}; !reflect.DeepEqual(actual, expected) {
+		t.Fatalf("expected %v, got %v", expected, actual)
+	}
+
+	nameFilter := filters.NewArgs()
+	nameFilter.Add("name", "a")
+	nameFilter.Add("name", "c")
+	nameFilter.Add("name", "d")
+	if actual, expected := ids(&types.ContainerListOptions{Offset: 1, Filter: nameFilter}), []string{"c", "a"}; !reflect.DeepEqual(actual, expected) {
+		t.Fatalf("expected the containers matching the names to be sorted, got %v", actual)
+	}
+}
@@ -20,7 +20,36 @@ type Context struct {
 	ContainerEnv        []string
 	ContainerLabels     map[string]string
 	LogPath             string
+	SpoolPath           string
 	DaemonName          string
+	Retention           Retention
+
+	// ContainerImageDigest is the digest of the manifest the image was
+	// pulled with, if known.
+	ContainerImageDigest string
+	// ContainerImageRegistry is the registry the image was pulled from,
+	// if known.
+	ContainerImageRegistry string
+
+	// StateDir is the directory the drivers may keep files in across the
+	// restarts of the container and of the daemon.
+	StateDir string
+
+	// LogEvent, if set, reports an event of the logger, like a reconnection
+	// to its backend, as an event of the container.
+	LogEvent func(action string, attributes map[string]string)
+}
+
+// Retention is the daemon's policy for the logs kept for each container.
+// Drivers storing logs on the host enforce it; other drivers may pass it
+// on to the log store. Zero values mean no limit.
+type Retention struct {
+	// MaxAge is the maximum age of the log entries kept.
+	MaxAge time.Duration
+	// MaxSize is the maximum size, in bytes, of the logs kept.
+	MaxSize int64
+	// MaxFiles is the maximum number of log files kept.
+	MaxFiles int
 }
 
 // ExtraAttributes returns the user-defined extra attributes (labels,
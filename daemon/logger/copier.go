@@ -16,6 +16,7 @@ type Copier struct {
 	// srcs is map of name -> reader pairs, for example "stdout", "stderr"
 	srcs      map[string]io.Reader
 	dst       Logger
+	redactor  *Redactor
 	copyJobs  sync.WaitGroup
 	closeOnce sync.Once
 	closed    chan struct{}
@@ -30,6 +31,12 @@ func NewCopier(srcs map[string]io.Reader, dst Logger) *Copier {
 	}
 }
 
+// SetRedactor sets the redactor applied to every line before it is passed
+// to the logger. It must be called before Run.
+func (c *Copier) SetRedactor(r *Redactor) {
+	c.redactor = r
+}
+
 // Run starts logs copying
 func (c *Copier) Run() {
 	for src, w := range c.srcs {
@@ -53,6 +60,7 @@ func (c *Copier) copySrc(name string, src io.Reader) {
 			// ReadBytes can return full or partial output even when it failed.
 			// e.g. it can return a full entry and EOF.
 			if err == nil || len(line) > 0 {
+				line = c.redactor.Redact(line)
 				if logErr := c.dst.Log(&Message{Line: line, Source: name, Timestamp: time.Now().UTC()}); logErr != nil {
 					logrus.Errorf("Failed to log msg %q for logger %s: %s", line, c.dst.Name(), logErr)
 				}
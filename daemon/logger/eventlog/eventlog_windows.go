@@ -0,0 +1,101 @@
+// Package eventlog provides the log driver for forwarding container logs to
+// the Windows Event Log, where they can be read with the Event Viewer or
+// collected with Windows Event Forwarding.
+//
+// Each log message generates an event of the Application log, from the
+// source set by the "eventlog-source" option. Messages from stdout are
+// informational events and messages from stderr are error events.
+package eventlog
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/Sirupsen/logrus"
+	"github.com/docker/docker/daemon/logger"
+	"github.com/docker/docker/daemon/logger/loggerutils"
+	"golang.org/x/sys/windows/svc/eventlog"
+)
+
+const (
+	name          = "eventlog"
+	sourceKey     = "eventlog-source"
+	defaultSource = "docker"
+	// eventID is the ID of the events. The message file of the sources
+	// created by EventCreate.exe accepts IDs from 1 to 1000.
+	eventID = 1
+	// maxMessageSize is the maximum size of the message of an event.
+	maxMessageSize = 31839
+)
+
+type eventLog struct {
+	log *eventlog.Log
+	tag string
+}
+
+func init() {
+	if err := logger.RegisterLogDriver(name, New); err != nil {
+		logrus.Fatal(err)
+	}
+	if err := logger.RegisterLogOptValidator(name, ValidateLogOpt); err != nil {
+		logrus.Fatal(err)
+	}
+}
+
+// New creates an eventlog logger using the configuration passed in on the
+// context. The event source is registered if it does not exist.
+func New(ctx logger.Context) (logger.Logger, error) {
+	source := ctx.Config[sourceKey]
+	if source == "" {
+		source = defaultSource
+	}
+	if err := eventlog.InstallAsEventCreate(source, eventlog.Info|eventlog.Error); err != nil && !strings.Contains(err.Error(), "already exists") {
+		// The events are still logged, without their message file.
+		logrus.Warnf("eventlog: failed to register event source %s: %v", source, err)
+	}
+
+	tag, err := loggerutils.ParseLogTag(ctx, "{{.Name}}/{{.ID}}")
+	if err != nil {
+		return nil, err
+	}
+	l, err := eventlog.Open(source)
+	if err != nil {
+		return nil, fmt.Errorf("eventlog: failed to open event source %s: %v", source, err)
+	}
+	return &eventLog{log: l, tag: tag}, nil
+}
+
+func (e *eventLog) Log(msg *logger.Message) error {
+	line := e.tag + ": " + string(msg.Line)
+	if len(line) > maxMessageSize {
+		line = line[:maxMessageSize]
+	}
+	if msg.Source == "stderr" {
+		return e.log.Error(eventID, line)
+	}
+	return e.log.Info(eventID, line)
+}
+
+func (e *eventLog) Close() error {
+	return e.log.Close()
+}
+
+func (e *eventLog) Name() string {
+	return name
+}
+
+// ValidateLogOpt looks for eventlog specific log options.
+func ValidateLogOpt(cfg map[string]string) error {
+	for key, value := range cfg {
+		switch key {
+		case "tag":
+		case sourceKey:
+			if value == "" || strings.ContainsAny(value, `\/`) {
+				return fmt.Errorf("invalid event source %q for eventlog log driver", value)
+			}
+		default:
+			return fmt.Errorf("unknown log opt '%s' for eventlog log driver", key)
+		}
+	}
+	return nil
+}
@@ -2,6 +2,7 @@ package logger
 
 import (
 	"fmt"
+	"strings"
 	"sync"
 )
 
@@ -15,6 +16,7 @@ type LogOptValidator func(cfg map[string]string) error
 type logdriverFactory struct {
 	registry     map[string]Creator
 	optValidator map[string]LogOptValidator
+	retention    map[string]bool
 	m            sync.Mutex
 }
 
@@ -47,26 +49,40 @@ func (lf *logdriverFactory) registerLogOptValidator(name string, l LogOptValidat
 	return nil
 }
 
-func (lf *logdriverFactory) get(name string) (Creator, error) {
+func (lf *logdriverFactory) registerRetentionDriver(name string) error {
 	lf.m.Lock()
 	defer lf.m.Unlock()
 
-	c, ok := lf.registry[name]
-	if !ok {
-		return c, fmt.Errorf("logger: no log driver named '%s' is registered", name)
+	if lf.retention[name] {
+		return fmt.Errorf("logger: log driver named '%s' is already registered as enforcing the retention", name)
 	}
-	return c, nil
+	lf.retention[name] = true
+	return nil
 }
 
-func (lf *logdriverFactory) getLogOptValidator(name string) LogOptValidator {
+func (lf *logdriverFactory) enforcesRetention(name string) bool {
 	lf.m.Lock()
 	defer lf.m.Unlock()
+	return lf.retention[name]
+}
 
-	c, _ := lf.optValidator[name]
-	return c
+// driver returns the Creator and the LogOptValidator of the driver name. The
+// drivers which are not registered are looked up in the log driver plugins.
+func (lf *logdriverFactory) driver(name string) (Creator, LogOptValidator, error) {
+	lf.m.Lock()
+	c, ok := lf.registry[name]
+	validator := lf.optValidator[name]
+	lf.m.Unlock()
+	if ok {
+		return c, validator, nil
+	}
+	if c, validator, ok := lookupPlugin(name); ok {
+		return c, validator, nil
+	}
+	return nil, nil, fmt.Errorf("logger: no log driver named '%s' is registered", name)
 }
 
-var factory = &logdriverFactory{registry: make(map[string]Creator), optValidator: make(map[string]LogOptValidator)} // global factory instance
+var factory = &logdriverFactory{registry: make(map[string]Creator), optValidator: make(map[string]LogOptValidator), retention: make(map[string]bool)} // global factory instance
 
 // RegisterLogDriver registers the given logging driver builder with given logging
 // driver name.
@@ -80,9 +96,33 @@ func RegisterLogOptValidator(name string, l LogOptValidator) error {
 	return factory.registerLogOptValidator(name, l)
 }
 
+// RegisterRetentionDriver registers the logging driver name as enforcing the
+// Retention of its Context on the logs it keeps.
+func RegisterRetentionDriver(name string) error {
+	return factory.registerRetentionDriver(name)
+}
+
+// EnforcesRetention returns whether the logging driver name enforces the
+// Retention of its Context. A composite driver enforces it if all of its
+// drivers do.
+func EnforcesRetention(name string) bool {
+	for _, n := range DriverNames(name) {
+		if !factory.enforcesRetention(n) {
+			return false
+		}
+	}
+	return true
+}
+
 // GetLogDriver provides the logging driver builder for a logging driver name.
+// The name can also be a composite driver, like "journald+json-file", which
+// sends the messages to all of its drivers, or a log driver plugin.
 func GetLogDriver(name string) (Creator, error) {
-	return factory.get(name)
+	if strings.Contains(name, TeeSeparator) {
+		return newTee(name)
+	}
+	c, _, err := factory.driver(name)
+	return c, err
 }
 
 // ValidateLogOpts checks the options for the given log driver. The
@@ -91,12 +131,14 @@ func ValidateLogOpts(name string, cfg map[string]string) error {
 	if name == "none" {
 		return nil
 	}
-
-	if !factory.driverRegistered(name) {
-		return fmt.Errorf("logger: no log driver named '%s' is registered", name)
+	if strings.Contains(name, TeeSeparator) {
+		return validateTeeOpts(name, cfg)
 	}
 
-	validator := factory.getLogOptValidator(name)
+	_, validator, err := factory.driver(name)
+	if err != nil {
+		return err
+	}
 	if validator != nil {
 		return validator(cfg)
 	}
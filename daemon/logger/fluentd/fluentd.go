@@ -3,6 +3,7 @@
 package fluentd
 
 import (
+	"crypto/tls"
 	"fmt"
 	"math"
 	"net"
@@ -22,6 +23,8 @@ type fluentd struct {
 	containerID   string
 	containerName string
 	writer        *fluent.Fluent
+	forwarder     *loggerutils.Forwarder
+	stats         *loggerutils.DeliveryStats
 	extra         map[string]string
 }
 
@@ -45,6 +48,7 @@ const (
 	retryWaitKey    = "fluentd-retry-wait"
 	maxRetriesKey   = "fluentd-max-retries"
 	asyncConnectKey = "fluentd-async-connect"
+	spoolSizeKey    = "fluentd-spool-size"
 )
 
 func init() {
@@ -106,6 +110,23 @@ func New(ctx logger.Context) (logger.Logger, error) {
 		}
 	}
 
+	// fluent-logger-golang neither supports TLS nor keeps messages on
+	// disk, use our own forward protocol client when either is requested.
+	if hasTLSOptions(ctx.Config) || ctx.Config[spoolSizeKey] != "" {
+		forwarder, err := newForwarder(ctx, net.JoinHostPort(host, strconv.Itoa(port)), asyncConnect)
+		if err != nil {
+			return nil, err
+		}
+		return &fluentd{
+			tag:           tag,
+			containerID:   ctx.ContainerID,
+			containerName: ctx.ContainerName,
+			forwarder:     forwarder,
+			stats:         loggerutils.GetDeliveryStats(name),
+			extra:         extra,
+		}, nil
+	}
+
 	fluentConfig := fluent.Config{
 		FluentPort:   port,
 		FluentHost:   host,
@@ -127,10 +148,42 @@ func New(ctx logger.Context) (logger.Logger, error) {
 		containerID:   ctx.ContainerID,
 		containerName: ctx.ContainerName,
 		writer:        log,
+		stats:         loggerutils.GetDeliveryStats(name),
 		extra:         extra,
 	}, nil
 }
 
+func newForwarder(ctx logger.Context, address string, asyncConnect bool) (*loggerutils.Forwarder, error) {
+	var tlsConfig *tls.Config
+	if hasTLSOptions(ctx.Config) {
+		var err error
+		if tlsConfig, err = loggerutils.ParseTLSConfig(ctx.Config, name); err != nil {
+			return nil, err
+		}
+	}
+
+	dial := func() (net.Conn, error) {
+		dialer := &net.Dialer{Timeout: defaultTimeout}
+		if tlsConfig != nil {
+			return tls.DialWithDialer(dialer, "tcp", address, tlsConfig)
+		}
+		return dialer.Dial("tcp", address)
+	}
+
+	spool, err := loggerutils.OpenSpool(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+	if spool == nil && !asyncConnect {
+		conn, err := dial()
+		if err != nil {
+			return nil, err
+		}
+		conn.Close()
+	}
+	return loggerutils.NewForwarder(dial, spool, loggerutils.GetDeliveryStats(name)), nil
+}
+
 func (f *fluentd) Log(msg *logger.Message) error {
 	data := map[string]string{
 		"container_id":   f.containerID,
@@ -141,12 +194,27 @@ func (f *fluentd) Log(msg *logger.Message) error {
 	for k, v := range f.extra {
 		data[k] = v
 	}
+	if f.forwarder != nil {
+		record, err := (&fluent.Message{Tag: f.tag, Time: msg.Timestamp.Unix(), Record: data}).MarshalMsg(nil)
+		if err != nil {
+			return err
+		}
+		return f.forwarder.Send(record)
+	}
 	// fluent-logger-golang buffers logs from failures and disconnections,
 	// and these are transferred again automatically.
-	return f.writer.PostWithTime(f.tag, msg.Timestamp, data)
+	if err := f.writer.PostWithTime(f.tag, msg.Timestamp, data); err != nil {
+		f.stats.Failed.Add(1)
+		return err
+	}
+	f.stats.Sent.Add(1)
+	return nil
 }
 
 func (f *fluentd) Close() error {
+	if f.forwarder != nil {
+		return f.forwarder.Close()
+	}
 	return f.writer.Close()
 }
 
@@ -166,7 +234,16 @@ func ValidateLogOpt(cfg map[string]string) error {
 		case retryWaitKey:
 		case maxRetriesKey:
 		case asyncConnectKey:
+		case "fluentd-tls-ca-cert", "fluentd-tls-cert", "fluentd-tls-key":
 			// Accepted
+		case "fluentd-tls-skip-verify":
+			if _, err := loggerutils.ParseTLSSkipVerify(cfg, name); err != nil {
+				return err
+			}
+		case spoolSizeKey:
+			if _, err := loggerutils.ParseSpoolSize(cfg, name); err != nil {
+				return err
+			}
 		default:
 			return fmt.Errorf("unknown log opt '%s' for fluentd log driver", key)
 		}
@@ -179,6 +256,15 @@ func ValidateLogOpt(cfg map[string]string) error {
 	return nil
 }
 
+func hasTLSOptions(cfg map[string]string) bool {
+	for _, key := range loggerutils.TLSOptionKeys(name) {
+		if _, ok := cfg[key]; ok {
+			return true
+		}
+	}
+	return false
+}
+
 func parseAddress(address string) (string, int, error) {
 	if address == "" {
 		return defaultHost, defaultPort, nil
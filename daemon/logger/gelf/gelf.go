@@ -7,6 +7,7 @@ package gelf
 import (
 	"bytes"
 	"compress/flate"
+	"crypto/tls"
 	"encoding/json"
 	"fmt"
 	"net"
@@ -21,10 +22,22 @@ import (
 	"github.com/docker/docker/pkg/urlutil"
 )
 
-const name = "gelf"
+const (
+	name = "gelf"
+
+	dialTimeout = 5 * time.Second
+)
+
+// gelfWriter sends GELF messages to the endpoint. UDP endpoints use the
+// chunking writer from go-gelf, TCP endpoints use a null byte delimited
+// stream.
+type gelfWriter interface {
+	WriteMessage(*gelf.Message) error
+	Close() error
+}
 
 type gelfLogger struct {
-	writer   *gelf.Writer
+	writer   gelfWriter
 	ctx      logger.Context
 	hostname string
 	rawExtra json.RawMessage
@@ -43,7 +56,7 @@ func init() {
 // context. The supported context configuration variable is gelf-address.
 func New(ctx logger.Context) (logger.Logger, error) {
 	// parse gelf address
-	address, err := parseAddress(ctx.Config["gelf-address"])
+	proto, address, err := parseAddress(ctx.Config["gelf-address"])
 	if err != nil {
 		return nil, err
 	}
@@ -88,13 +101,31 @@ func New(ctx logger.Context) (logger.Logger, error) {
 		return nil, err
 	}
 
-	// create new gelfWriter
+	var w gelfWriter
+	if proto == "tcp" {
+		w, err = newTCPWriter(ctx, address)
+	} else {
+		w, err = newUDPWriter(ctx.Config, address)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &gelfLogger{
+		writer:   w,
+		ctx:      ctx,
+		hostname: hostname,
+		rawExtra: rawExtra,
+	}, nil
+}
+
+func newUDPWriter(cfg map[string]string, address string) (gelfWriter, error) {
 	gelfWriter, err := gelf.NewWriter(address)
 	if err != nil {
 		return nil, fmt.Errorf("gelf: cannot connect to GELF endpoint: %s %v", address, err)
 	}
 
-	if v, ok := ctx.Config["gelf-compression-type"]; ok {
+	if v, ok := cfg["gelf-compression-type"]; ok {
 		switch v {
 		case "gzip":
 			gelfWriter.CompressionType = gelf.CompressGzip
@@ -107,22 +138,87 @@ func New(ctx logger.Context) (logger.Logger, error) {
 		}
 	}
 
-	if v, ok := ctx.Config["gelf-compression-level"]; ok {
+	if v, ok := cfg["gelf-compression-level"]; ok {
 		val, err := strconv.Atoi(v)
 		if err != nil {
 			return nil, fmt.Errorf("gelf: invalid compression level %s, err %v", v, err)
 		}
 		gelfWriter.CompressionLevel = val
 	}
+	return &udpWriter{Writer: gelfWriter, stats: loggerutils.GetDeliveryStats(name)}, nil
+}
 
-	return &gelfLogger{
-		writer:   gelfWriter,
-		ctx:      ctx,
-		hostname: hostname,
-		rawExtra: rawExtra,
+// udpWriter wraps the go-gelf writer to account for deliveries.
+type udpWriter struct {
+	*gelf.Writer
+	stats *loggerutils.DeliveryStats
+}
+
+func (w *udpWriter) WriteMessage(m *gelf.Message) error {
+	if err := w.Writer.WriteMessage(m); err != nil {
+		w.stats.Failed.Add(1)
+		return err
+	}
+	w.stats.Sent.Add(1)
+	return nil
+}
+
+// tcpWriter sends uncompressed GELF messages terminated by a null byte,
+// as expected by GELF TCP inputs. The connection is re-established after
+// failures and messages are spooled to disk meanwhile if configured.
+type tcpWriter struct {
+	forwarder *loggerutils.Forwarder
+}
+
+func newTCPWriter(ctx logger.Context, address string) (gelfWriter, error) {
+	var tlsConfig *tls.Config
+	if hasTLSOptions(ctx.Config) {
+		var err error
+		if tlsConfig, err = loggerutils.ParseTLSConfig(ctx.Config, name); err != nil {
+			return nil, fmt.Errorf("gelf: %v", err)
+		}
+	}
+
+	dial := func() (net.Conn, error) {
+		dialer := &net.Dialer{Timeout: dialTimeout}
+		if tlsConfig != nil {
+			return tls.DialWithDialer(dialer, "tcp", address, tlsConfig)
+		}
+		return dialer.Dial("tcp", address)
+	}
+
+	// Fail early if the endpoint cannot be reached and there is no spool
+	// to hold messages until it comes up, like the UDP writer does.
+	spool, err := loggerutils.OpenSpool(ctx, name)
+	if err != nil {
+		return nil, fmt.Errorf("gelf: %v", err)
+	}
+	if spool == nil {
+		conn, err := dial()
+		if err != nil {
+			return nil, fmt.Errorf("gelf: cannot connect to GELF endpoint: %s %v", address, err)
+		}
+		conn.Close()
+	}
+
+	return &tcpWriter{
+		forwarder: loggerutils.NewForwarder(dial, spool, loggerutils.GetDeliveryStats(name)),
 	}, nil
 }
 
+func (w *tcpWriter) WriteMessage(m *gelf.Message) error {
+	var buf bytes.Buffer
+	if err := m.MarshalJSONBuf(&buf); err != nil {
+		return err
+	}
+	buf.WriteByte(0)
+	return w.forwarder.Send(buf.Bytes())
+}
+
+func (w *tcpWriter) Close() error {
+	return w.forwarder.Close()
+}
+
 func (s *gelfLogger) Log(msg *logger.Message) error {
 	level := gelf.LOG_INFO
 	if msg.Source == "stderr" {
@@ -171,39 +267,72 @@ func ValidateLogOpt(cfg map[string]string) error {
 			default:
 				return fmt.Errorf("unknown value %q for log opt %q for gelf log driver", val, key)
 			}
+		case "gelf-tls-ca-cert", "gelf-tls-cert", "gelf-tls-key":
+		case "gelf-tls-skip-verify":
+			if _, err := loggerutils.ParseTLSSkipVerify(cfg, name); err != nil {
+				return err
+			}
+		case "gelf-spool-size":
+			if _, err := loggerutils.ParseSpoolSize(cfg, name); err != nil {
+				return err
+			}
 		default:
 			return fmt.Errorf("unknown log opt %q for gelf log driver", key)
 		}
 	}
 
-	if _, err := parseAddress(cfg["gelf-address"]); err != nil {
+	proto, _, err := parseAddress(cfg["gelf-address"])
+	if err != nil {
 		return err
 	}
+	if proto != "tcp" {
+		if hasTLSOptions(cfg) {
+			return fmt.Errorf("gelf: TLS options require a tcp:// gelf-address")
+		}
+		if _, ok := cfg["gelf-spool-size"]; ok {
+			return fmt.Errorf("gelf: gelf-spool-size requires a tcp:// gelf-address")
+		}
+	} else {
+		_, hasType := cfg["gelf-compression-type"]
+		_, hasLevel := cfg["gelf-compression-level"]
+		if hasType || hasLevel {
+			return fmt.Errorf("gelf: compression is not supported with a tcp:// gelf-address")
+		}
+	}
 
 	return nil
 }
 
-func parseAddress(address string) (string, error) {
+func hasTLSOptions(cfg map[string]string) bool {
+	for _, key := range loggerutils.TLSOptionKeys(name) {
+		if _, ok := cfg[key]; ok {
+			return true
+		}
+	}
+	return false
+}
+
+func parseAddress(address string) (string, string, error) {
 	if address == "" {
-		return "", nil
+		return "", "", nil
 	}
 	if !urlutil.IsTransportURL(address) {
-		return "", fmt.Errorf("gelf-address should be in form proto://address, got %v", address)
+		return "", "", fmt.Errorf("gelf-address should be in form proto://address, got %v", address)
 	}
 	url, err := url.Parse(address)
 	if err != nil {
-		return "", err
+		return "", "", err
 	}
 
-	// we support only udp
-	if url.Scheme != "udp" {
-		return "", fmt.Errorf("gelf: endpoint needs to be UDP")
+	// we support only udp and tcp
+	if url.Scheme != "udp" && url.Scheme != "tcp" {
+		return "", "", fmt.Errorf("gelf: endpoint needs to be TCP or UDP")
 	}
 
 	// get host and port
 	if _, _, err = net.SplitHostPort(url.Host); err != nil {
-		return "", fmt.Errorf("gelf: please provide gelf-address as udp://host:port")
+		return "", "", fmt.Errorf("gelf: please provide gelf-address as udp://host:port or tcp://host:port")
 	}
 
-	return url.Host, nil
+	return url.Scheme, url.Host, nil
 }
@@ -0,0 +1,20 @@
+// +build linux
+
+package journald
+
+import "strings"
+
+// isCursor reports whether s is a journal cursor, as printed by
+// `journalctl --show-cursor`: fields of hexadecimal values separated by
+// semicolons, like "s=7a4f...;i=4ece8;b=5d0b...;m=1ea0d0fd;t=5394be8b2b6c1;x=9b3f...".
+func isCursor(s string) bool {
+	for _, field := range strings.Split(s, ";") {
+		if len(field) < 3 || field[1] != '=' || !strings.ContainsRune("sibmtx", rune(field[0])) {
+			return false
+		}
+		if strings.Trim(field[2:], "0123456789abcdef") != "" {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,28 @@
+// +build linux
+
+package journald
+
+import "testing"
+
+func TestIsCursor(t *testing.T) {
+	for _, s := range []string{
+		"s=739ad463348b4ceca5a9e69c95a84ae8;i=4ece8;b=6c7c6013a8544d7a9dc6b0fe47fa5c27;m=1ea0d0fd;t=5394be8b2b6c1;x=9b3fdeb4a2a2b15b",
+		"s=739ad463348b4ceca5a9e69c95a84ae8;i=4ece8",
+	} {
+		if !isCursor(s) {
+			t.Fatalf("expected %q to be a cursor", s)
+		}
+	}
+	for _, s := range []string{
+		"",
+		"1465919042",
+		"2016-06-14T15:04:05Z",
+		"s=739ad463348b4ceca5a9e69c95a84ae8;",
+		"s=739ad463348b4ceca5a9e69c95a84ae8;q=12",
+		"s=not-hex",
+	} {
+		if isCursor(s) {
+			t.Fatalf("expected %q not to be a cursor", s)
+		}
+	}
+}
@@ -0,0 +1,165 @@
+// +build linux
+
+package journald
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+const (
+	labelFieldsKey = "journald-label-fields"
+	fieldsKey      = "journald-fields"
+)
+
+// labelFieldPrefix prefixes the fields of the labels of the container sent
+// with journald-label-fields.
+const labelFieldPrefix = "CONTAINER_LABEL_"
+
+// maxFieldNameLength is the maximum length of the names of the fields
+// journald accepts.
+const maxFieldNameLength = 64
+
+// fieldName returns key as a journal field name: in upper case, with the
+// characters journald does not accept replaced by underscores, and without
+// the leading underscores and digits, journald ignoring such fields. It is
+// empty if nothing is left of key.
+func fieldName(key string) string {
+	name := strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z':
+			return r - 'a' + 'A'
+		case r >= 'A' && r <= 'Z', r >= '0' && r <= '9', r == '_':
+			return r
+		}
+		return '_'
+	}, key)
+	name = strings.TrimLeft(name, "_0123456789")
+	if len(name) > maxFieldNameLength {
+		name = name[:maxFieldNameLength]
+	}
+	return name
+}
+
+// parseLabelFields returns whether the log opts send each label of the
+// container as its own field.
+func parseLabelFields(cfg map[string]string) (bool, error) {
+	v, ok := cfg[labelFieldsKey]
+	if !ok {
+		return false, nil
+	}
+	labelFields, err := strconv.ParseBool(v)
+	if err != nil {
+		return false, fmt.Errorf("invalid %s %q: must be true or false", labelFieldsKey, v)
+	}
+	return labelFields, nil
+}
+
+// labelVars returns the fields of the labels of a container, named after
+// their keys prefixed with CONTAINER_LABEL_.
+func labelVars(labels map[string]string) map[string]string {
+	vars := make(map[string]string, len(labels))
+	for k, v := range labels {
+		if name := fieldName(k); name != "" {
+			vars[fieldName(labelFieldPrefix+name)] = v
+		}
+	}
+	return vars
+}
+
+// driverFields are the fields the driver sends itself, which the selected
+// fields cannot be named after.
+var driverFields = map[string]bool{
+	"MESSAGE":                  true,
+	"PRIORITY":                 true,
+	"SYSLOG_IDENTIFIER":        true,
+	"CONTAINER_ID":             true,
+	"CONTAINER_ID_FULL":        true,
+	"CONTAINER_NAME":           true,
+	"CONTAINER_TAG":            true,
+	"CONTAINER_IMAGE":          true,
+	"CONTAINER_IMAGE_ID":       true,
+	"CONTAINER_IMAGE_DIGEST":   true,
+	"CONTAINER_IMAGE_REGISTRY": true,
+	streamField:                true,
+	seqField:                   true,
+}
+
+// selectedField is an environment variable or a label of the container
+// selected by journald-fields, and the name of its field.
+type selectedField struct {
+	source string // "env" or "label"
+	key    string
+	name   string
+}
+
+// parseFields parses journald-fields, a comma-separated list of the
+// environment variables, as env:NAME, and of the labels, as label:KEY, sent
+// with the messages, each optionally renamed with =FIELD. By default, the
+// field of an environment variable is named after it, and the field of a
+// label after its key prefixed with CONTAINER_LABEL_. It returns whether the
+// option is set, as an empty list sends no field.
+func parseFields(cfg map[string]string) ([]selectedField, bool, error) {
+	v, ok := cfg[fieldsKey]
+	if !ok {
+		return nil, false, nil
+	}
+	var fields []selectedField
+	names := make(map[string]bool)
+	for _, f := range strings.Split(v, ",") {
+		f = strings.TrimSpace(f)
+		if f == "" {
+			continue
+		}
+		parts := strings.SplitN(f, ":", 2)
+		if len(parts) != 2 || (parts[0] != "env" && parts[0] != "label") {
+			return nil, false, fmt.Errorf("invalid %s entry %q: must be env:NAME or label:KEY, optionally followed by =FIELD", fieldsKey, f)
+		}
+		field := selectedField{source: parts[0], key: parts[1]}
+		if i := strings.Index(parts[1], "="); i >= 0 {
+			field.key, field.name = parts[1][:i], parts[1][i+1:]
+			if field.name == "" || fieldName(field.name) != field.name {
+				return nil, false, fmt.Errorf("invalid %s entry %q: %q is not a journal field name, like %s", fieldsKey, f, field.name, fieldName(field.name))
+			}
+		} else if field.source == "label" {
+			field.name = fieldName(labelFieldPrefix + fieldName(field.key))
+		} else {
+			field.name = fieldName(field.key)
+		}
+		if field.key == "" || field.name == "" {
+			return nil, false, fmt.Errorf("invalid %s entry %q: no name", fieldsKey, f)
+		}
+		if driverFields[field.name] {
+			return nil, false, fmt.Errorf("invalid %s entry %q: the %s field is sent by the driver", fieldsKey, f, field.name)
+		}
+		if names[field.name] {
+			return nil, false, fmt.Errorf("invalid %s entry %q: the %s field is selected twice", fieldsKey, f, field.name)
+		}
+		names[field.name] = true
+		fields = append(fields, field)
+	}
+	return fields, true, nil
+}
+
+// fieldVars returns the selected fields of the environment variables and the
+// labels of a container which are set.
+func fieldVars(fields []selectedField, env []string, labels map[string]string) map[string]string {
+	envMapping := make(map[string]string)
+	for _, e := range env {
+		if kv := strings.SplitN(e, "=", 2); len(kv) == 2 {
+			envMapping[kv[0]] = kv[1]
+		}
+	}
+	vars := make(map[string]string, len(fields))
+	for _, f := range fields {
+		values := labels
+		if f.source == "env" {
+			values = envMapping
+		}
+		if v, ok := values[f.key]; ok {
+			vars[f.name] = v
+		}
+	}
+	return vars
+}
@@ -0,0 +1,117 @@
+// +build linux
+
+package journald
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestFieldName(t *testing.T) {
+	for key, expected := range map[string]string{
+		"com.example.team":      "COM_EXAMPLE_TEAM",
+		"tier":                  "TIER",
+		"Build-Date":            "BUILD_DATE",
+		"_trusted":              "TRUSTED",
+		"2fa":                   "FA",
+		"___":                   "",
+		strings.Repeat("a", 80): strings.Repeat("A", maxFieldNameLength),
+	} {
+		if name := fieldName(key); name != expected {
+			t.Fatalf("expected the field name of %q to be %q, got %q", key, expected, name)
+		}
+	}
+}
+
+func TestLabelVars(t *testing.T) {
+	vars := labelVars(map[string]string{
+		"com.example.team": "payments",
+		"tier":             "web",
+		"...":              "dropped",
+	})
+	expected := map[string]string{
+		"CONTAINER_LABEL_COM_EXAMPLE_TEAM": "payments",
+		"CONTAINER_LABEL_TIER":             "web",
+	}
+	if !reflect.DeepEqual(vars, expected) {
+		t.Fatalf("expected %v, got %v", expected, vars)
+	}
+}
+
+func TestValidateLogOptLabelFields(t *testing.T) {
+	if err := validateLogOpt(map[string]string{labelFieldsKey: "true"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := validateLogOpt(map[string]string{labelFieldsKey: "all"}); err == nil {
+		t.Fatal("expected an invalid journald-label-fields to be rejected")
+	}
+}
+
+func TestParseFields(t *testing.T) {
+	fields, selected, err := parseFields(map[string]string{fieldsKey: "env:APP_VERSION, env:region=DATACENTER,label:com.example.team,label:tier=TIER"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := []selectedField{
+		{source: "env", key: "APP_VERSION", name: "APP_VERSION"},
+		{source: "env", key: "region", name: "DATACENTER"},
+		{source: "label", key: "com.example.team", name: "CONTAINER_LABEL_COM_EXAMPLE_TEAM"},
+		{source: "label", key: "tier", name: "TIER"},
+	}
+	if !selected || !reflect.DeepEqual(fields, expected) {
+		t.Fatalf("expected %v, got %v", expected, fields)
+	}
+
+	if _, selected, err := parseFields(map[string]string{}); err != nil || selected {
+		t.Fatalf("expected no selected fields, got %v, %v", selected, err)
+	}
+	if fields, selected, err := parseFields(map[string]string{fieldsKey: ""}); err != nil || !selected || len(fields) != 0 {
+		t.Fatalf("expected an empty selection, got %v, %v, %v", fields, selected, err)
+	}
+
+	for _, v := range []string{
+		"APP_VERSION",
+		"secret:TOKEN",
+		"env:=VERSION",
+		"env:APP_VERSION=version",
+		"env:APP_VERSION=",
+		"env:NAME=CONTAINER_NAME",
+		"env:message",
+		"env:VERSION,label:version=VERSION",
+	} {
+		if _, _, err := parseFields(map[string]string{fieldsKey: v}); err == nil {
+			t.Fatalf("expected %q to be rejected", v)
+		}
+	}
+}
+
+func TestFieldVars(t *testing.T) {
+	fields, _, err := parseFields(map[string]string{fieldsKey: "env:APP_VERSION,env:region=DATACENTER,label:tier=TIER,label:missing"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	vars := fieldVars(fields,
+		[]string{"APP_VERSION=1.2", "region=eu-west", "DB_PASSWORD=secret"},
+		map[string]string{"tier": "web", "com.example.team": "payments"})
+	expected := map[string]string{
+		"APP_VERSION": "1.2",
+		"DATACENTER":  "eu-west",
+		"TIER":        "web",
+	}
+	if !reflect.DeepEqual(vars, expected) {
+		t.Fatalf("expected %v, got %v", expected, vars)
+	}
+}
+
+func TestValidateLogOptFields(t *testing.T) {
+	if err := validateLogOpt(map[string]string{fieldsKey: "env:APP_VERSION", "env": "DB_PASSWORD"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := validateLogOpt(map[string]string{fieldsKey: "env:APP_VERSION", labelFieldsKey: "true"}); err == nil {
+		t.Fatal("expected journald-fields with journald-label-fields to be rejected")
+	}
+	if err := validateLogOpt(map[string]string{fieldsKey: "APP_VERSION"}); err == nil {
+		t.Fatal("expected an invalid journald-fields to be rejected")
+	}
+}
@@ -6,7 +6,8 @@ package journald
 
 import (
 	"fmt"
-	"strings"
+	"net"
+	"path/filepath"
 	"sync"
 
 	"github.com/Sirupsen/logrus"
@@ -18,8 +19,14 @@ import (
 const name = "journald"
 
 type journald struct {
-	vars    map[string]string // additional variables and values to send to the journal along with the log message
-	readers readerList
+	vars      map[string]string            // additional variables and values to send to the journal along with the log message
+	streams   map[string]map[string]string // vars of the messages of each stream
+	seq       *sequence                    // numbers of the messages of each stream
+	priority  priorityConfig               // how the priorities of the messages are chosen
+	readers   readerList
+	namespace string  // journal namespace the messages are sent to, if any
+	vacuum    bool    // whether the namespace is vacuumed for the retention policy
+	writer    *writer // writer of the messages to the journal
 }
 
 type readerList struct {
@@ -39,7 +46,8 @@ func init() {
 // New creates a journald logger using the configuration passed in on
 // the context.
 func New(ctx logger.Context) (logger.Logger, error) {
-	if !journal.Enabled() {
+	namespace := ctx.Config[namespaceKey]
+	if namespace == "" && !journal.Enabled() {
 		return nil, fmt.Errorf("journald is not enabled on this host")
 	}
 	// Strip a leading slash so that people can search for
@@ -56,42 +64,168 @@ func New(ctx logger.Context) (logger.Logger, error) {
 	}
 
 	vars := map[string]string{
-		"CONTAINER_ID":      ctx.ContainerID[:12],
-		"CONTAINER_ID_FULL": ctx.ContainerID,
-		"CONTAINER_NAME":    name,
-		"CONTAINER_TAG":     tag,
+		"CONTAINER_ID":       ctx.ContainerID[:12],
+		"CONTAINER_ID_FULL":  ctx.ContainerID,
+		"CONTAINER_NAME":     name,
+		"CONTAINER_TAG":      tag,
+		"CONTAINER_IMAGE":    ctx.ContainerImageName,
+		"CONTAINER_IMAGE_ID": ctx.ContainerImageID,
 	}
-	extraAttrs := ctx.ExtraAttributes(strings.ToTitle)
-	for k, v := range extraAttrs {
-		vars[k] = v
+	if ctx.ContainerImageDigest != "" {
+		vars["CONTAINER_IMAGE_DIGEST"] = ctx.ContainerImageDigest
 	}
-	return &journald{vars: vars, readers: readerList{readers: make(map[*logger.LogWatcher]*logger.LogWatcher)}}, nil
+	if ctx.ContainerImageRegistry != "" {
+		vars["CONTAINER_IMAGE_REGISTRY"] = ctx.ContainerImageRegistry
+	}
+	if identifier := ctx.Config[syslogIdentifierKey]; identifier != "" {
+		vars["SYSLOG_IDENTIFIER"] = identifier
+	}
+	fields, selected, err := parseFields(ctx.Config)
+	if err != nil {
+		return nil, err
+	}
+	labelFields, err := parseLabelFields(ctx.Config)
+	if err != nil {
+		return nil, err
+	}
+	if selected {
+		// only the selected environment variables and labels are sent,
+		// whatever the env and labels options
+		for k, v := range fieldVars(fields, ctx.ContainerEnv, ctx.ContainerLabels) {
+			vars[k] = v
+		}
+	} else {
+		if labelFields {
+			for k, v := range labelVars(ctx.ContainerLabels) {
+				vars[k] = v
+			}
+		}
+		extraAttrs := ctx.ExtraAttributes(fieldName)
+		for k, v := range extraAttrs {
+			if k != "" {
+				vars[k] = v
+			}
+		}
+	}
+
+	priority, err := newPriorityConfig(ctx.Config)
+	if err != nil {
+		return nil, err
+	}
+
+	var conn *net.UnixConn
+	path := journalSocket
+	if namespace != "" {
+		path = namespaceSocket(namespace)
+		conn, err = dialNamespace(namespace)
+	} else {
+		conn, err = dialJournal(path)
+	}
+	if err != nil {
+		return nil, err
+	}
+	seqPath := ""
+	if ctx.StateDir != "" {
+		seqPath = filepath.Join(ctx.StateDir, seqFile)
+	}
+	seq, err := newSequence(seqPath, "stdout", "stderr")
+	if err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("failed to load the journald sequence numbers: %v", err)
+	}
+	writer, err := newWriter(conn, path, ctx.Config, name)
+	if err != nil {
+		conn.Close()
+		return nil, err
+	}
+	writer.logEvent = ctx.LogEvent
+	go writer.run()
+
+	// The entries of a single container cannot be removed from a journal,
+	// but the files of a namespace can be vacuumed by age: the maximum age
+	// of the retention policy applies to all the containers logging to it.
+	vacuum := namespace != "" && ctx.Retention.MaxAge > 0
+	if vacuum {
+		startVacuum(namespace, ctx.Retention.MaxAge)
+	}
+
+	return &journald{
+		vars:      vars,
+		streams:   streamVars(vars),
+		seq:       seq,
+		priority:  priority,
+		readers:   readerList{readers: make(map[*logger.LogWatcher]*logger.LogWatcher)},
+		namespace: namespace,
+		vacuum:    vacuum,
+		writer:    writer,
+	}, nil
 }
 
-// We don't actually accept any options, but we have to supply a callback for
-// the factory to pass the (probably empty) configuration map to.
+// validateLogOpt checks the journald log opts.
 func validateLogOpt(cfg map[string]string) error {
 	for key := range cfg {
 		switch key {
 		case "labels":
 		case "env":
 		case "tag":
+		case stdoutPriorityKey:
+		case stderrPriorityKey:
+		case levelPrefixKey:
+		case modeKey:
+		case queueSizeKey:
+		case labelFieldsKey:
+		case fieldsKey:
+		case namespaceKey:
+			if err := validateNamespace(cfg[key]); err != nil {
+				return err
+			}
+		case syslogIdentifierKey:
+			if err := validateSyslogIdentifier(cfg[key]); err != nil {
+				return err
+			}
 		default:
 			return fmt.Errorf("unknown log opt '%s' for journald log driver", key)
 		}
 	}
-	return nil
+	if _, err := newPriorityConfig(cfg); err != nil {
+		return err
+	}
+	labelFields, err := parseLabelFields(cfg)
+	if err != nil {
+		return err
+	}
+	if _, selected, err := parseFields(cfg); err != nil {
+		return err
+	} else if selected && labelFields {
+		return fmt.Errorf("%s cannot be used with %s, which sends all the labels", fieldsKey, labelFieldsKey)
+	}
+	_, _, err = parseQueueConfig(cfg)
+	return err
 }
 
 func (s *journald) Log(msg *logger.Message) error {
-	line := string(msg.Line)
-	source := msg.Source
-	logger.PutMessage(msg)
+	priority, line := s.priority.priority(msg.Source, string(msg.Line))
+	vars, ok := s.streams[msg.Source]
+	if !ok {
+		vars = s.vars
+	}
+	// The messages are numbered before they are queued, so that those
+	// dropped by a non-blocking writer leave a gap.
+	seq := s.seq.next(msg.Source)
+	return s.writer.enqueue(entry{message: line, priority: priority, vars: vars, seq: seq})
+}
 
-	if source == "stderr" {
-		return journal.Send(line, journal.PriErr, vars)
+// closeWriter writes the queued messages, closes the connection to the
+// journal, and saves the sequence numbers of the messages.
+func (s *journald) closeWriter() {
+	s.writer.close()
+	s.writer.conn.Close()
+	if err := s.seq.close(); err != nil {
+		logrus.Warnf("Failed to save the journald sequence numbers: %v", err)
+	}
+	if s.vacuum {
+		stopVacuum(s.namespace)
 	}
-	return journal.Send(string(msg.Line), journal.PriInfo, s.vars)
 }
 
 func (s *journald) Name() string {
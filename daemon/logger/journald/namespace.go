@@ -0,0 +1,136 @@
+// +build linux
+
+package journald
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"syscall"
+
+	"github.com/coreos/go-systemd/journal"
+)
+
+const namespaceKey = "journald-namespace"
+
+// validNamespace matches the journal namespace names accepted by systemd.
+var validNamespace = regexp.MustCompile(`^[a-zA-Z0-9_.:-]{1,64}$`)
+
+func validateNamespace(namespace string) error {
+	if !validNamespace.MatchString(namespace) || namespace == "." || namespace == ".." {
+		return fmt.Errorf("invalid %s %q", namespaceKey, namespace)
+	}
+	return nil
+}
+
+// journalSocket is the socket of the default journal.
+const journalSocket = "/run/systemd/journal/socket"
+
+// namespaceSocket returns the path of the socket journald listens on for
+// the given namespace.
+func namespaceSocket(namespace string) string {
+	return filepath.Join("/run/systemd", "journal."+namespace, "socket")
+}
+
+// namespaceDirectory returns the directory holding the journal files of the
+// given namespace, preferring persistent storage over the runtime one.
+func namespaceDirectory(namespace string) (string, error) {
+	machineID, err := ioutil.ReadFile("/etc/machine-id")
+	if err != nil {
+		return "", err
+	}
+	name := strings.TrimSpace(string(machineID)) + "." + namespace
+	for _, root := range []string{"/var/log/journal", "/run/log/journal"} {
+		dir := filepath.Join(root, name)
+		if _, err := os.Stat(dir); err == nil {
+			return dir, nil
+		}
+	}
+	return "", fmt.Errorf("no journal found for namespace %q", namespace)
+}
+
+// dialNamespace connects to the journald instance serving namespace. The
+// instance is normally started on demand by systemd-journald@.socket.
+func dialNamespace(namespace string) (*net.UnixConn, error) {
+	conn, err := dialJournal(namespaceSocket(namespace))
+	if err != nil {
+		return nil, fmt.Errorf("journal namespace %q is not available: %v", namespace, err)
+	}
+	return conn, nil
+}
+
+// dialJournal connects to the journald socket at path.
+func dialJournal(path string) (*net.UnixConn, error) {
+	return net.DialUnix("unixgram", nil, &net.UnixAddr{Name: path, Net: "unixgram"})
+}
+
+// send writes an entry to conn using the journald native protocol, in the
+// same way journal.Send does for the default journal socket.
+func send(conn *net.UnixConn, message string, priority journal.Priority, vars map[string]string) error {
+	data := new(bytes.Buffer)
+	encodeEntry(data, message, priority, vars)
+	return writeEntry(conn, data.Bytes())
+}
+
+// encodeEntry appends an entry to w in the journald native protocol.
+func encodeEntry(w io.Writer, message string, priority journal.Priority, vars map[string]string) {
+	appendVariable(w, "PRIORITY", strconv.Itoa(int(priority)))
+	appendVariable(w, "MESSAGE", message)
+	for k, v := range vars {
+		appendVariable(w, k, v)
+	}
+}
+
+// writeEntry writes an encoded entry to conn, in a datagram if it fits, and
+// in a memory file passed to journald otherwise.
+func writeEntry(conn *net.UnixConn, data []byte) error {
+	_, err := conn.Write(data)
+	if err == nil || !isSocketSpaceError(err) {
+		return err
+	}
+
+	// The entry does not fit in a datagram: pass it in a file instead.
+	file, err := ioutil.TempFile("/dev/shm/", "journal.XXXXX")
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	if err := syscall.Unlink(file.Name()); err != nil {
+		return err
+	}
+	if _, err := file.Write(data); err != nil {
+		return err
+	}
+	_, _, err = conn.WriteMsgUnix([]byte{}, syscall.UnixRights(int(file.Fd())), nil)
+	return err
+}
+
+func appendVariable(w io.Writer, name, value string) {
+	if strings.ContainsRune(value, '\n') {
+		// Values containing newlines are sent length prefixed.
+		fmt.Fprintln(w, name)
+		binary.Write(w, binary.LittleEndian, uint64(len(value)))
+		fmt.Fprintln(w, value)
+	} else {
+		fmt.Fprintf(w, "%s=%s\n", name, value)
+	}
+}
+
+func isSocketSpaceError(err error) bool {
+	opErr, ok := err.(*net.OpError)
+	if !ok {
+		return false
+	}
+	if sysErr, ok := opErr.Err.(*os.SyscallError); ok {
+		return sysErr.Err == syscall.EMSGSIZE || sysErr.Err == syscall.ENOBUFS
+	}
+	return opErr.Err == syscall.EMSGSIZE || opErr.Err == syscall.ENOBUFS
+}
@@ -0,0 +1,75 @@
+// +build linux
+
+package journald
+
+import (
+	"io/ioutil"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/coreos/go-systemd/journal"
+)
+
+func TestValidateLogOptNamespace(t *testing.T) {
+	for _, ns := range []string{"app", "team-a.logs", "ns_1:2"} {
+		if err := validateLogOpt(map[string]string{namespaceKey: ns}); err != nil {
+			t.Fatalf("expected namespace %q to be valid: %v", ns, err)
+		}
+	}
+	for _, ns := range []string{"", "..", "a/b", "with space", strings.Repeat("x", 65)} {
+		if err := validateLogOpt(map[string]string{namespaceKey: ns}); err == nil {
+			t.Fatalf("expected namespace %q to be rejected", ns)
+		}
+	}
+}
+
+func TestSendNativeProtocol(t *testing.T) {
+	dir, err := ioutil.TempDir("", "journald-namespace")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	addr := &net.UnixAddr{Name: filepath.Join(dir, "socket"), Net: "unixgram"}
+	server, err := net.ListenUnixgram("unixgram", addr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer server.Close()
+
+	conn, err := net.DialUnix("unixgram", nil, addr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+
+	if err := send(conn, "hello", journal.PriErr, map[string]string{"CONTAINER_NAME": "web"}); err != nil {
+		t.Fatal(err)
+	}
+	buf := make([]byte, 1024)
+	n, err := server.Read(buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := string(buf[:n])
+	for _, field := range []string{"PRIORITY=3\n", "MESSAGE=hello\n", "CONTAINER_NAME=web\n"} {
+		if !strings.Contains(got, field) {
+			t.Fatalf("expected %q in %q", field, got)
+		}
+	}
+
+	if err := send(conn, "multi\nline", journal.PriInfo, nil); err != nil {
+		t.Fatal(err)
+	}
+	n, err = server.Read(buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := "MESSAGE\n\x0a\x00\x00\x00\x00\x00\x00\x00multi\nline\n"
+	if got := string(buf[:n]); !strings.Contains(got, expected) {
+		t.Fatalf("expected length prefixed message in %q", got)
+	}
+}
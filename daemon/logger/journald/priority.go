@@ -0,0 +1,112 @@
+// +build linux
+
+package journald
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/coreos/go-systemd/journal"
+)
+
+const (
+	syslogIdentifierKey = "journald-syslog-identifier"
+	stdoutPriorityKey   = "journald-stdout-priority"
+	stderrPriorityKey   = "journald-stderr-priority"
+	levelPrefixKey      = "journald-level-prefix"
+)
+
+// streamField is the field of the entries recording the stream of their
+// message, which their priority does not tell once it is remapped.
+const streamField = "CONTAINER_LOG_STREAM"
+
+// priorities maps the syslog names of the priorities to the priorities.
+var priorities = map[string]journal.Priority{
+	"emerg":   journal.PriEmerg,
+	"alert":   journal.PriAlert,
+	"crit":    journal.PriCrit,
+	"err":     journal.PriErr,
+	"warning": journal.PriWarning,
+	"notice":  journal.PriNotice,
+	"info":    journal.PriInfo,
+	"debug":   journal.PriDebug,
+}
+
+// parsePriority parses a priority given by its syslog name, like "warning",
+// or by its number, from 0 (emerg) to 7 (debug).
+func parsePriority(key, value string) (journal.Priority, error) {
+	if p, ok := priorities[value]; ok {
+		return p, nil
+	}
+	if n, err := strconv.Atoi(value); err == nil && n >= int(journal.PriEmerg) && n <= int(journal.PriDebug) {
+		return journal.Priority(n), nil
+	}
+	return 0, fmt.Errorf("invalid %s %q: must be a syslog priority name or a number from 0 to 7", key, value)
+}
+
+func validateSyslogIdentifier(identifier string) error {
+	if identifier == "" || strings.ContainsAny(identifier, " \t\n") {
+		return fmt.Errorf("invalid %s %q", syslogIdentifierKey, identifier)
+	}
+	return nil
+}
+
+// priorityConfig is how the priorities of the messages are chosen.
+type priorityConfig struct {
+	stdout      journal.Priority
+	stderr      journal.Priority
+	levelPrefix bool // parse the "<N>" priority prefixes of the lines
+}
+
+// newPriorityConfig returns the priority configuration of the log opts,
+// defaulting to info for stdout and err for stderr.
+func newPriorityConfig(cfg map[string]string) (priorityConfig, error) {
+	c := priorityConfig{stdout: journal.PriInfo, stderr: journal.PriErr}
+	var err error
+	if v, ok := cfg[stdoutPriorityKey]; ok {
+		if c.stdout, err = parsePriority(stdoutPriorityKey, v); err != nil {
+			return c, err
+		}
+	}
+	if v, ok := cfg[stderrPriorityKey]; ok {
+		if c.stderr, err = parsePriority(stderrPriorityKey, v); err != nil {
+			return c, err
+		}
+	}
+	if v, ok := cfg[levelPrefixKey]; ok {
+		if c.levelPrefix, err = strconv.ParseBool(v); err != nil {
+			return c, fmt.Errorf("invalid %s %q: %v", levelPrefixKey, v, err)
+		}
+	}
+	return c, nil
+}
+
+// streamVars returns the variables of the entries of each stream: vars and
+// the stream.
+func streamVars(vars map[string]string) map[string]map[string]string {
+	streams := make(map[string]map[string]string)
+	for _, stream := range []string{"stdout", "stderr"} {
+		v := make(map[string]string, len(vars)+1)
+		for k, value := range vars {
+			v[k] = value
+		}
+		v[streamField] = stream
+		streams[stream] = v
+	}
+	return streams
+}
+
+// priority returns the priority of a line of the given source, and the line
+// without its priority prefix. With levelPrefix, a line starting with a
+// priority between angle brackets, like "<4>disk almost full", has this
+// priority, as for the standard output of the services run by systemd.
+func (c priorityConfig) priority(source, line string) (journal.Priority, string) {
+	if c.levelPrefix && len(line) >= 3 && line[0] == '<' && line[2] == '>' && line[1] >= '0' && line[1] <= '7' {
+		return journal.Priority(line[1] - '0'), line[3:]
+	}
+	if source == "stderr" {
+		return c.stderr, line
+	}
+	return c.stdout, line
+}
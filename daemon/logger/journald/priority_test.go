@@ -0,0 +1,79 @@
+// +build linux
+
+package journald
+
+import (
+	"testing"
+
+	"github.com/coreos/go-systemd/journal"
+)
+
+func TestValidateLogOptPriorities(t *testing.T) {
+	for _, cfg := range []map[string]string{
+		{syslogIdentifierKey: "webapp"},
+		{stdoutPriorityKey: "notice", stderrPriorityKey: "warning"},
+		{stderrPriorityKey: "4"},
+		{levelPrefixKey: "true"},
+	} {
+		if err := validateLogOpt(cfg); err != nil {
+			t.Fatalf("expected %v to be valid: %v", cfg, err)
+		}
+	}
+	for _, cfg := range []map[string]string{
+		{syslogIdentifierKey: ""},
+		{syslogIdentifierKey: "web app"},
+		{stdoutPriorityKey: "warn"},
+		{stderrPriorityKey: "8"},
+		{levelPrefixKey: "sometimes"},
+	} {
+		if err := validateLogOpt(cfg); err == nil {
+			t.Fatalf("expected %v to be rejected", cfg)
+		}
+	}
+}
+
+func TestPriority(t *testing.T) {
+	defaults, err := newPriorityConfig(map[string]string{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	remapped, err := newPriorityConfig(map[string]string{stderrPriorityKey: "warning", levelPrefixKey: "true"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, tc := range []struct {
+		config   priorityConfig
+		source   string
+		line     string
+		priority journal.Priority
+		message  string
+	}{
+		{defaults, "stdout", "started", journal.PriInfo, "started"},
+		{defaults, "stderr", "<4>slow query", journal.PriErr, "<4>slow query"},
+		{remapped, "stdout", "started", journal.PriInfo, "started"},
+		{remapped, "stderr", "retrying", journal.PriWarning, "retrying"},
+		{remapped, "stderr", "<2>out of memory", journal.PriCrit, "out of memory"},
+		{remapped, "stdout", "<7>", journal.PriDebug, ""},
+		{remapped, "stdout", "<8>not a priority", journal.PriInfo, "<8>not a priority"},
+		{remapped, "stdout", "<1", journal.PriInfo, "<1"},
+	} {
+		priority, message := tc.config.priority(tc.source, tc.line)
+		if priority != tc.priority || message != tc.message {
+			t.Fatalf("expected %q from %s to be %q with priority %d, got %q with priority %d", tc.line, tc.source, tc.message, tc.priority, message, priority)
+		}
+	}
+}
+
+func TestStreamVars(t *testing.T) {
+	vars := map[string]string{"CONTAINER_NAME": "web"}
+	streams := streamVars(vars)
+	for _, stream := range []string{"stdout", "stderr"} {
+		if streams[stream][streamField] != stream || streams[stream]["CONTAINER_NAME"] != "web" {
+			t.Fatalf("unexpected vars of %s: %v", stream, streams[stream])
+		}
+	}
+	if _, ok := vars[streamField]; ok {
+		t.Fatal("expected the vars not to be modified")
+	}
+}
@@ -0,0 +1,211 @@
+// +build linux
+
+package journald
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/Sirupsen/logrus"
+	"github.com/coreos/go-systemd/journal"
+)
+
+const (
+	modeKey      = "journald-mode"
+	queueSizeKey = "journald-queue-size"
+
+	modeBlocking    = "blocking"
+	modeNonBlocking = "non-blocking"
+
+	defaultQueueSize = 1024
+	// maxBatchSize is the maximum number of queued entries the writer
+	// encodes at once.
+	maxBatchSize = 64
+)
+
+// seqField is the field of the entries numbering the messages of each stream
+// from 1, so that the readers of the journal can put the messages of the
+// container back in order and tell which ones were dropped.
+const seqField = "CONTAINER_SEQ"
+
+// entry is a message queued for the journal.
+type entry struct {
+	message  string
+	priority journal.Priority
+	vars     map[string]string
+	seq      uint64 // number of the message in its stream, if any
+}
+
+// encode appends the entry to w in the journald native protocol.
+func (e entry) encode(w io.Writer) {
+	encodeEntry(w, e.message, e.priority, e.vars)
+	if e.seq > 0 {
+		appendVariable(w, seqField, strconv.FormatUint(e.seq, 10))
+	}
+}
+
+// writer writes the entries queued by Log to the journal from its own
+// goroutine, so that a chatty container does not wait for the journal
+// socket on each message.
+type writer struct {
+	conn     *net.UnixConn
+	path     string // path of the journal socket, to reconnect
+	queue    chan entry
+	blocking bool   // wait for room in the queue instead of dropping entries
+	name     string // name of the container, for the warnings
+	dropped  uint64 // entries dropped since the last warning, updated atomically
+
+	// the connection to the journal is broken, and the entries which
+	// could not be written are kept in pending to be replayed once the
+	// writer reconnects, the oldest ones being lost past replayBufferSize
+	broken   bool
+	pending  [][]byte
+	lost     int
+	lastDial time.Time
+	logEvent func(action string, attributes map[string]string)
+
+	mu     sync.RWMutex // protects closed against the sends to queue
+	closed bool
+	done   chan struct{}
+}
+
+// parseQueueConfig returns whether the writer of the log opts blocks when
+// its queue is full, and the size of its queue.
+func parseQueueConfig(cfg map[string]string) (bool, int, error) {
+	blocking := true
+	switch mode := cfg[modeKey]; mode {
+	case "", modeBlocking:
+	case modeNonBlocking:
+		blocking = false
+	default:
+		return false, 0, fmt.Errorf("invalid %s %q: must be %s or %s", modeKey, mode, modeBlocking, modeNonBlocking)
+	}
+
+	size := defaultQueueSize
+	if v, ok := cfg[queueSizeKey]; ok {
+		var err error
+		if size, err = strconv.Atoi(v); err != nil || size < 1 {
+			return false, 0, fmt.Errorf("invalid %s %q: must be a positive number of messages", queueSizeKey, v)
+		}
+	}
+	return blocking, size, nil
+}
+
+// newWriter returns a writer of the entries to conn, connected to the journal
+// socket at path, configured by the log opts. Its goroutine is started with
+// run.
+func newWriter(conn *net.UnixConn, path string, cfg map[string]string, name string) (*writer, error) {
+	blocking, size, err := parseQueueConfig(cfg)
+	if err != nil {
+		return nil, err
+	}
+	return &writer{
+		conn:     conn,
+		path:     path,
+		queue:    make(chan entry, size),
+		blocking: blocking,
+		name:     name,
+		done:     make(chan struct{}),
+	}, nil
+}
+
+// enqueue queues e. When the queue is full, it waits for room if the writer
+// is blocking, and drops e otherwise.
+func (w *writer) enqueue(e entry) error {
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+	if w.closed {
+		return errors.New("journald logger is closed")
+	}
+	if w.blocking {
+		w.queue <- e
+		return nil
+	}
+	select {
+	case w.queue <- e:
+	default:
+		atomic.AddUint64(&w.dropped, 1)
+	}
+	return nil
+}
+
+// run writes the queued entries until the writer is closed. The entries
+// queued while it writes are encoded together in one buffer, then written
+// one datagram each, as journald reads one entry per datagram. While the
+// connection to the journal is broken, it also tries to reconnect
+// periodically.
+func (w *writer) run() {
+	defer close(w.done)
+
+	var data bytes.Buffer
+	ends := make([]int, 0, maxBatchSize)
+	for {
+		var retry <-chan time.Time
+		if w.broken {
+			retry = time.After(reconnectInterval)
+		}
+		var e entry
+		select {
+		case <-retry:
+			w.reconnect()
+			continue
+		case queued, ok := <-w.queue:
+			if !ok {
+				w.closePending()
+				return
+			}
+			e = queued
+		}
+
+		data.Reset()
+		ends = ends[:0]
+		e.encode(&data)
+		ends = append(ends, data.Len())
+	batch:
+		for len(ends) < maxBatchSize {
+			select {
+			case e, ok := <-w.queue:
+				if !ok {
+					break batch
+				}
+				e.encode(&data)
+				ends = append(ends, data.Len())
+			default:
+				break batch
+			}
+		}
+		w.write(data.Bytes(), ends)
+
+		if dropped := atomic.SwapUint64(&w.dropped, 0); dropped > 0 {
+			logrus.Warnf("Dropped %d messages of container %s: the journald log queue is full", dropped, w.name)
+		}
+	}
+}
+
+// write writes the entries encoded in data, ending at the given offsets.
+func (w *writer) write(data []byte, ends []int) {
+	start := 0
+	for _, end := range ends {
+		w.writeOne(data[start:end])
+		start = end
+	}
+}
+
+// close stops queueing entries, and waits for the queued ones to be
+// written.
+func (w *writer) close() {
+	w.mu.Lock()
+	if !w.closed {
+		w.closed = true
+		close(w.queue)
+	}
+	w.mu.Unlock()
+	<-w.done
+}
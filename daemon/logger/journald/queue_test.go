@@ -0,0 +1,238 @@
+// +build linux
+
+package journald
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/coreos/go-systemd/journal"
+	"github.com/docker/docker/daemon/logger"
+)
+
+func TestValidateLogOptQueue(t *testing.T) {
+	for _, cfg := range []map[string]string{
+		{modeKey: modeBlocking},
+		{modeKey: modeNonBlocking, queueSizeKey: "100"},
+	} {
+		if err := validateLogOpt(cfg); err != nil {
+			t.Fatalf("expected %v to be valid: %v", cfg, err)
+		}
+	}
+	for _, cfg := range []map[string]string{
+		{modeKey: "best-effort"},
+		{queueSizeKey: "0"},
+		{queueSizeKey: "1k"},
+	} {
+		if err := validateLogOpt(cfg); err == nil {
+			t.Fatalf("expected %v to be rejected", cfg)
+		}
+	}
+}
+
+func TestWriter(t *testing.T) {
+	dir, err := ioutil.TempDir("", "journald-writer")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	addr := &net.UnixAddr{Name: filepath.Join(dir, "socket"), Net: "unixgram"}
+	server, err := net.ListenUnixgram("unixgram", addr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer server.Close()
+
+	conn, err := dialJournal(addr.Name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+
+	w, err := newWriter(conn, addr.Name, map[string]string{queueSizeKey: "16"}, "web")
+	if err != nil {
+		t.Fatal(err)
+	}
+	go w.run()
+
+	const count = 100
+	received := make(chan string, count)
+	go func() {
+		buf := make([]byte, 1024)
+		for i := 0; i < count; i++ {
+			n, err := server.Read(buf)
+			if err != nil {
+				close(received)
+				return
+			}
+			received <- string(buf[:n])
+		}
+	}()
+
+	for i := 0; i < count; i++ {
+		if err := w.enqueue(entry{message: fmt.Sprintf("line %d", i), priority: journal.PriInfo}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	w.close()
+	if err := w.enqueue(entry{message: "late"}); err == nil {
+		t.Fatal("expected a closed writer to refuse entries")
+	}
+
+	// each entry is a datagram, in the order of the queue
+	for i := 0; i < count; i++ {
+		got, ok := <-received
+		if !ok {
+			t.Fatalf("expected %d entries, got %d", count, i)
+		}
+		if expected := fmt.Sprintf("MESSAGE=line %d\n", i); !strings.Contains(got, expected) {
+			t.Fatalf("expected %q in %q", expected, got)
+		}
+	}
+}
+
+func TestWriterNonBlocking(t *testing.T) {
+	w, err := newWriter(nil, "", map[string]string{modeKey: modeNonBlocking, queueSizeKey: "2"}, "web")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// the writer is not running, so the queue fills up
+	for i := 0; i < 5; i++ {
+		if err := w.enqueue(entry{message: "line"}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if len(w.queue) != 2 || w.dropped != 3 {
+		t.Fatalf("expected 2 queued and 3 dropped entries, got %d and %d", len(w.queue), w.dropped)
+	}
+}
+
+func TestLogSeq(t *testing.T) {
+	w, err := newWriter(nil, "", map[string]string{modeKey: modeNonBlocking, queueSizeKey: "3"}, "web")
+	if err != nil {
+		t.Fatal(err)
+	}
+	seq, err := newSequence("", "stdout", "stderr")
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := &journald{
+		streams: streamVars(map[string]string{}),
+		seq:     seq,
+		writer:  w,
+	}
+
+	// the last two messages are dropped, but still numbered
+	for _, source := range []string{"stdout", "stderr", "stdout", "stdout", "stdout"} {
+		if err := s.Log(&logger.Message{Line: []byte("line"), Source: source}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	var seqs []string
+	for len(w.queue) > 0 {
+		e := <-w.queue
+		var data bytes.Buffer
+		e.encode(&data)
+		for _, line := range strings.Split(data.String(), "\n") {
+			if strings.HasPrefix(line, seqField+"=") {
+				seqs = append(seqs, e.vars[streamField]+":"+strings.TrimPrefix(line, seqField+"="))
+			}
+		}
+	}
+	if expected := "stdout:1 stderr:1 stdout:2"; strings.Join(seqs, " ") != expected {
+		t.Fatalf("expected the sequence numbers %s, got %v", expected, seqs)
+	}
+}
+
+func TestWriterReconnect(t *testing.T) {
+	dir, err := ioutil.TempDir("", "journald-writer")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	addr := &net.UnixAddr{Name: filepath.Join(dir, "socket"), Net: "unixgram"}
+	server, err := net.ListenUnixgram("unixgram", addr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	conn, err := dialJournal(addr.Name)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	w, err := newWriter(conn, addr.Name, map[string]string{}, "web")
+	if err != nil {
+		t.Fatal(err)
+	}
+	events := make(chan map[string]string, 1)
+	w.logEvent = func(action string, attributes map[string]string) {
+		if action != "log_reconnect" {
+			t.Errorf("unexpected event %s", action)
+		}
+		events <- attributes
+	}
+	defer w.conn.Close()
+
+	// journald restarts: its socket goes away while the container logs
+	server.Close()
+	os.Remove(addr.Name)
+	const count = 3
+	for i := 0; i < count; i++ {
+		var data bytes.Buffer
+		encodeEntry(&data, fmt.Sprintf("line %d", i), journal.PriInfo, nil)
+		w.writeOne(data.Bytes())
+	}
+	if !w.broken || len(w.pending) != count {
+		t.Fatalf("expected %d buffered entries, got %d", count, len(w.pending))
+	}
+
+	server, err = net.ListenUnixgram("unixgram", addr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer server.Close()
+	w.lastDial = time.Time{}
+	if !w.reconnect() {
+		t.Fatal("expected the writer to reconnect")
+	}
+
+	// the buffered entries are replayed in order
+	buf := make([]byte, 1024)
+	for i := 0; i < count; i++ {
+		n, err := server.Read(buf)
+		if err != nil {
+			t.Fatalf("expected %d replayed entries, got %d: %v", count, i, err)
+		}
+		if expected := fmt.Sprintf("MESSAGE=line %d\n", i); !strings.Contains(string(buf[:n]), expected) {
+			t.Fatalf("expected %q in %q", expected, buf[:n])
+		}
+	}
+	attributes := <-events
+	if attributes["driver"] != "journald" || attributes["replayed"] != "3" || attributes["lost"] != "0" {
+		t.Fatalf("unexpected attributes of the reconnection event: %v", attributes)
+	}
+}
+
+func TestWriterBufferLimit(t *testing.T) {
+	w, err := newWriter(nil, "", map[string]string{}, "web")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < replayBufferSize+5; i++ {
+		w.buffer([]byte(strconv.Itoa(i)))
+	}
+	if len(w.pending) != replayBufferSize || w.lost != 5 || string(w.pending[0]) != "5" {
+		t.Fatalf("expected the %d latest entries to be kept and 5 lost, got %d kept from %q and %d lost", replayBufferSize, len(w.pending), w.pending[0], w.lost)
+	}
+}
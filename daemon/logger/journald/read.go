@@ -30,6 +30,24 @@ package journald
 //	}
 //	return rc;
 //}
+//static int get_stream(sd_journal *j, const char **stream, size_t *length)
+//{
+//	int rc;
+//	*stream = NULL;
+//	*length = 0;
+//	rc = sd_journal_get_data(j, "CONTAINER_LOG_STREAM", (const void **) stream, length);
+//	if (rc == 0) {
+//		if (*length > 21) {
+//			(*stream) += 21;
+//			*length -= 21;
+//		} else {
+//			*stream = NULL;
+//			*length = 0;
+//			rc = -ENOENT;
+//		}
+//	}
+//	return rc;
+//}
 //static int get_priority(sd_journal *j, int *priority)
 //{
 //	const void *data;
@@ -70,6 +88,11 @@ package journald
 //		{"CONTAINER_ID", sizeof("CONTAINER_ID") - 1},
 //		{"CONTAINER_ID_FULL", sizeof("CONTAINER_ID_FULL") - 1},
 //		{"CONTAINER_TAG", sizeof("CONTAINER_TAG") - 1},
+//		{"CONTAINER_IMAGE", sizeof("CONTAINER_IMAGE") - 1},
+//		{"CONTAINER_IMAGE_ID", sizeof("CONTAINER_IMAGE_ID") - 1},
+//		{"CONTAINER_IMAGE_DIGEST", sizeof("CONTAINER_IMAGE_DIGEST") - 1},
+//		{"CONTAINER_IMAGE_REGISTRY", sizeof("CONTAINER_IMAGE_REGISTRY") - 1},
+//		{"CONTAINER_LOG_STREAM", sizeof("CONTAINER_LOG_STREAM") - 1},
 //	};
 //	unsigned int i;
 //	void *p;
@@ -160,14 +183,29 @@ func (s *journald) Close() error {
 		reader.Close()
 	}
 	s.readers.mu.Unlock()
+	s.closeWriter()
 	return nil
 }
 
-func (s *journald) drainJournal(logWatcher *logger.LogWatcher, config logger.ReadConfig, j *C.sd_journal, oldCursor string) string {
-	var msg, data, cursor *C.char
+// IsCursor reports whether s is a journal cursor.
+func (s *journald) IsCursor(cursor string) bool {
+	return isCursor(cursor)
+}
+
+// drainJournal sends the entries of the journal from the current one, and
+// returns the cursor of the last one. It reports whether it stopped at an
+// entry after config.Until, in which case there is nothing left to read.
+func (s *journald) drainJournal(logWatcher *logger.LogWatcher, config logger.ReadConfig, j *C.sd_journal, oldCursor string) (string, bool) {
+	var msg, data, cursor, entryCursor, stream *C.char
 	var length C.size_t
 	var stamp C.uint64_t
 	var priority C.int
+	var untilUnixMicro uint64
+	done := false
+
+	if !config.Until.IsZero() {
+		untilUnixMicro = uint64(config.Until.UnixNano() / 1000)
+	}
 
 	// Walk the journal from here forward until we run out of new entries.
 drain:
@@ -182,6 +220,11 @@ drain:
 				}
 			}
 		}
+		// Stop at the first entry after the end of the range.
+		if untilUnixMicro != 0 && C.sd_journal_get_realtime_usec(j, &stamp) == 0 && uint64(stamp) > untilUnixMicro {
+			done = true
+			break
+		}
 		// Read and send the logged message, if there is one to read.
 		i := C.get_message(j, &msg, &length)
 		if i != -C.ENOENT && i != -C.EADDRNOTAVAIL {
@@ -192,12 +235,15 @@ drain:
 			// Set up the time and text of the entry.
 			timestamp := time.Unix(int64(stamp)/1000000, (int64(stamp)%1000000)*1000)
 			line := append(C.GoBytes(unsafe.Pointer(msg), C.int(length)), "\n"...)
-			// Recover the stream name by mapping
-			// from the journal priority back to
-			// the stream that we would have
-			// assigned that value.
+			// Read the stream name, or, for the
+			// entries logged without it, recover
+			// it by mapping from the journal
+			// priority back to the stream that we
+			// would have assigned that value.
 			source := ""
-			if C.get_priority(j, &priority) != 0 {
+			if C.get_stream(j, &stream, &length) == 0 {
+				source = C.GoStringN(stream, C.int(length))
+			} else if C.get_priority(j, &priority) != 0 {
 				source = ""
 			} else if priority == C.int(journal.PriErr) {
 				source = "stderr"
@@ -214,12 +260,20 @@ drain:
 			if len(attrs) == 0 {
 				attrs = nil
 			}
+			// Read the cursor of the entry, for the readers to
+			// resume after it.
+			msgCursor := ""
+			if C.sd_journal_get_cursor(j, &entryCursor) == 0 {
+				msgCursor = C.GoString(entryCursor)
+				C.free(unsafe.Pointer(entryCursor))
+			}
 			// Send the log message.
 			logWatcher.Msg <- &logger.Message{
 				Line:      line,
 				Source:    source,
 				Timestamp: timestamp.In(time.UTC),
 				Attrs:     attrs,
+				Cursor:    msgCursor,
 			}
 		}
 		// If we're at the end of the journal, we're done (for now).
@@ -232,7 +286,7 @@ drain:
 		retCursor = C.GoString(cursor)
 		C.free(unsafe.Pointer(cursor))
 	}
-	return retCursor
+	return retCursor, done
 }
 
 func (s *journald) followJournal(logWatcher *logger.LogWatcher, config logger.ReadConfig, j *C.sd_journal, pfd [2]C.int, cursor string) {
@@ -244,7 +298,11 @@ func (s *journald) followJournal(logWatcher *logger.LogWatcher, config logger.Re
 		// or we hit an error.
 		status := C.wait_for_data_or_close(j, pfd[0])
 		for status == 1 {
-			cursor = s.drainJournal(logWatcher, config, j, cursor)
+			var done bool
+			cursor, done = s.drainJournal(logWatcher, config, j, cursor)
+			if done {
+				break
+			}
 			status = C.wait_for_data_or_close(j, pfd[0])
 		}
 		if status < 0 {
@@ -273,12 +331,26 @@ func (s *journald) readLogs(logWatcher *logger.LogWatcher, config logger.ReadCon
 	var j *C.sd_journal
 	var cmatch *C.char
 	var stamp C.uint64_t
-	var sinceUnixMicro uint64
+	var sinceUnixMicro, untilUnixMicro uint64
+	var csince *C.char
 	var pipes [2]C.int
 	cursor := ""
 
 	// Get a handle to the journal.
-	rc := C.sd_journal_open(&j, C.int(0))
+	var rc C.int
+	if s.namespace != "" {
+		dir, err := namespaceDirectory(s.namespace)
+		if err != nil {
+			logWatcher.Err <- err
+			close(logWatcher.Msg)
+			return
+		}
+		cdir := C.CString(dir)
+		rc = C.sd_journal_open_directory(&j, cdir, C.int(0))
+		C.free(unsafe.Pointer(cdir))
+	} else {
+		rc = C.sd_journal_open(&j, C.int(0))
+	}
 	if rc != 0 {
 		logWatcher.Err <- fmt.Errorf("error opening journal")
 		close(logWatcher.Msg)
@@ -309,15 +381,28 @@ func (s *journald) readLogs(logWatcher *logger.LogWatcher, config logger.ReadCon
 		logWatcher.Err <- fmt.Errorf("error setting journal match")
 		return
 	}
-	// If we have a cutoff time, convert it to Unix time once.
+	// If we have cutoff times, convert them to Unix time once.
 	if !config.Since.IsZero() {
 		nano := config.Since.UnixNano()
 		sinceUnixMicro = uint64(nano / 1000)
 	}
+	if !config.Until.IsZero() {
+		nano := config.Until.UnixNano()
+		untilUnixMicro = uint64(nano / 1000)
+	}
+	if config.SinceCursor != "" {
+		csince = C.CString(config.SinceCursor)
+		defer C.free(unsafe.Pointer(csince))
+	}
 	if config.Tail > 0 {
 		lines := config.Tail
-		// Start at the end of the journal.
-		if C.sd_journal_seek_tail(j) < 0 {
+		// Start at the end of the journal, or of the range.
+		if untilUnixMicro != 0 {
+			if C.sd_journal_seek_realtime_usec(j, C.uint64_t(untilUnixMicro+1)) != 0 {
+				logWatcher.Err <- fmt.Errorf("error seeking to end time in journal")
+				return
+			}
+		} else if C.sd_journal_seek_tail(j) < 0 {
 			logWatcher.Err <- fmt.Errorf("error seeking to end of journal")
 			return
 		}
@@ -327,6 +412,11 @@ func (s *journald) readLogs(logWatcher *logger.LogWatcher, config logger.ReadCon
 		}
 		// Walk backward.
 		for lines > 0 {
+			// Stop at the entry of the cursor, which is skipped
+			// when draining the journal.
+			if csince != nil && C.sd_journal_test_cursor(j, csince) > 0 {
+				break
+			}
 			// Stop if the entry time is before our cutoff.
 			// We'll need the entry time if it isn't, so go
 			// ahead and parse it now.
@@ -334,8 +424,10 @@ func (s *journald) readLogs(logWatcher *logger.LogWatcher, config logger.ReadCon
 				break
 			} else {
 				// Compare the timestamp on the entry
-				// to our threshold value.
+				// to our threshold value, and get back
+				// to the first entry of the range.
 				if sinceUnixMicro != 0 && sinceUnixMicro > uint64(stamp) {
+					C.sd_journal_next(j)
 					break
 				}
 			}
@@ -347,6 +439,17 @@ func (s *journald) readLogs(logWatcher *logger.LogWatcher, config logger.ReadCon
 				break
 			}
 		}
+	} else if csince != nil {
+		// Start at the entry of the cursor, which is skipped when
+		// draining the journal.
+		if C.sd_journal_seek_cursor(j, csince) != 0 {
+			logWatcher.Err <- fmt.Errorf("error seeking to cursor in journal")
+			return
+		}
+		if C.sd_journal_next(j) < 0 {
+			logWatcher.Err <- fmt.Errorf("error skipping to next journal entry")
+			return
+		}
 	} else {
 		// Start at the beginning of the journal.
 		if C.sd_journal_seek_head(j) < 0 {
@@ -363,8 +466,8 @@ func (s *journald) readLogs(logWatcher *logger.LogWatcher, config logger.ReadCon
 			return
 		}
 	}
-	cursor = s.drainJournal(logWatcher, config, j, "")
-	if config.Follow {
+	cursor, done := s.drainJournal(logWatcher, config, j, config.SinceCursor)
+	if config.Follow && !done {
 		// Allocate a descriptor for following the journal, if we'll
 		// need one.  Do it here so that we can report if it fails.
 		if fd := C.sd_journal_get_fd(j); fd < C.int(0) {
@@ -3,5 +3,6 @@
 package journald
 
 func (s *journald) Close() error {
+	s.closeWriter()
 	return nil
 }
@@ -0,0 +1,131 @@
+// +build linux
+
+package journald
+
+import (
+	"net"
+	"os"
+	"strconv"
+	"syscall"
+	"time"
+
+	"github.com/Sirupsen/logrus"
+)
+
+const (
+	// replayBufferSize is the maximum number of entries kept while the
+	// connection to the journal is broken.
+	replayBufferSize = 256
+	// reconnectInterval is the minimum delay between two attempts to
+	// reconnect to the journal.
+	reconnectInterval = time.Second
+)
+
+// writeOne writes an encoded entry to the journal. When the journal is not
+// reachable, as while journald restarts, the entry is kept to be replayed
+// once the writer reconnects.
+func (w *writer) writeOne(data []byte) {
+	if w.broken && !w.reconnect() {
+		w.buffer(data)
+		return
+	}
+	err := writeEntry(w.conn, data)
+	if err == nil {
+		return
+	}
+	if !isConnectionError(err) {
+		logrus.Errorf("Failed to log msg %q for logger journald: %s", data, err)
+		return
+	}
+	logrus.Warnf("Lost the connection to the journal for container %s, buffering its messages: %v", w.name, err)
+	w.broken = true
+	w.buffer(data)
+	w.reconnect()
+}
+
+// buffer keeps a copy of data to replay it, dropping the oldest entry when
+// the buffer is full.
+func (w *writer) buffer(data []byte) {
+	if len(w.pending) == replayBufferSize {
+		w.pending = w.pending[1:]
+		w.lost++
+	}
+	w.pending = append(w.pending, append([]byte(nil), data...))
+}
+
+// reconnect dials the journal again and replays the buffered entries,
+// unless the last attempt is too recent. It returns whether the writer is
+// connected.
+func (w *writer) reconnect() bool {
+	if time.Since(w.lastDial) < reconnectInterval {
+		return false
+	}
+	w.lastDial = time.Now()
+	conn, err := dialJournal(w.path)
+	if err != nil {
+		logrus.Debugf("Failed to reconnect to the journal for container %s: %v", w.name, err)
+		return false
+	}
+	if w.conn != nil {
+		w.conn.Close()
+	}
+	w.conn = conn
+
+	replayed := 0
+	for i, data := range w.pending {
+		if err := writeEntry(w.conn, data); err != nil {
+			if isConnectionError(err) {
+				// journald went away again: keep the rest for the next attempt
+				w.pending = w.pending[i:]
+				return false
+			}
+			logrus.Errorf("Failed to log msg %q for logger journald: %s", data, err)
+		}
+		replayed++
+	}
+
+	logrus.Infof("Reconnected to the journal for container %s: replayed %d messages, lost %d", w.name, replayed, w.lost)
+	if w.logEvent != nil {
+		w.logEvent("log_reconnect", map[string]string{
+			"driver":   name,
+			"replayed": strconv.Itoa(replayed),
+			"lost":     strconv.Itoa(w.lost),
+		})
+	}
+	w.broken = false
+	w.pending = nil
+	w.lost = 0
+	return true
+}
+
+// closePending makes a last attempt to replay the buffered entries when the
+// writer closes, and reports them as lost if the journal is still not
+// reachable.
+func (w *writer) closePending() {
+	if !w.broken {
+		return
+	}
+	w.lastDial = time.Time{}
+	if w.reconnect() {
+		return
+	}
+	if n := len(w.pending) + w.lost; n > 0 {
+		logrus.Warnf("Lost %d messages of container %s: the journal was not reachable", n, w.name)
+	}
+}
+
+// isConnectionError tells whether err means that the journal socket is not
+// reachable, rather than that an entry could not be written.
+func isConnectionError(err error) bool {
+	if opErr, ok := err.(*net.OpError); ok {
+		err = opErr.Err
+	}
+	if sysErr, ok := err.(*os.SyscallError); ok {
+		err = sysErr.Err
+	}
+	switch err {
+	case syscall.ECONNREFUSED, syscall.ENOTCONN, syscall.EPIPE, syscall.ENOENT:
+		return true
+	}
+	return false
+}
@@ -0,0 +1,110 @@
+// +build linux
+
+package journald
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"sync"
+
+	"github.com/Sirupsen/logrus"
+	"github.com/docker/docker/pkg/ioutils"
+)
+
+// seqFile is the file of the directory of the container the sequence numbers
+// of its messages are kept in.
+const seqFile = "journald-seq.json"
+
+// seqReserve is how many sequence numbers of each stream are reserved in the
+// sequence file ahead of the messages sent, so that the file is not written
+// for each message. After a crash of the daemon, the numbering resumes after
+// the reserved numbers, leaving a gap rather than numbering messages again.
+const seqReserve = 1024
+
+// sequence numbers the messages of each stream of a container from 1. The
+// numbers are kept in a file, so that they keep increasing across the
+// restarts of the container and of the daemon.
+type sequence struct {
+	mu       sync.Mutex
+	path     string
+	last     map[string]uint64 // number of the last message of each stream
+	reserved map[string]uint64 // numbers saved in the file while running
+}
+
+// newSequence returns the sequence of the messages of streams, resuming from
+// the numbers saved at path, if any. The numbers are not saved if path is
+// empty.
+func newSequence(path string, streams ...string) (*sequence, error) {
+	s := &sequence{
+		path:     path,
+		last:     make(map[string]uint64),
+		reserved: make(map[string]uint64),
+	}
+	for _, stream := range streams {
+		s.last[stream] = 0
+	}
+	if path == "" {
+		return s, nil
+	}
+
+	b, err := ioutil.ReadFile(path)
+	if err != nil && !os.IsNotExist(err) {
+		return nil, err
+	}
+	if err == nil {
+		var saved map[string]uint64
+		if err := json.Unmarshal(b, &saved); err != nil {
+			return nil, err
+		}
+		for stream := range s.last {
+			s.last[stream] = saved[stream]
+		}
+	}
+	for stream, last := range s.last {
+		s.reserved[stream] = last + seqReserve
+	}
+	if err := s.save(s.reserved); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// next returns the number of the next message of stream, or 0 if the
+// messages of stream are not numbered.
+func (s *sequence) next(stream string) uint64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	last, ok := s.last[stream]
+	if !ok {
+		return 0
+	}
+	last++
+	s.last[stream] = last
+	if s.path != "" && last > s.reserved[stream] {
+		s.reserved[stream] = last + seqReserve
+		if err := s.save(s.reserved); err != nil {
+			logrus.Warnf("Failed to save the journald sequence numbers to %s: %v", s.path, err)
+		}
+	}
+	return last
+}
+
+// close saves the numbers of the last messages, so that the numbering
+// resumes right after them.
+func (s *sequence) close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.path == "" {
+		return nil
+	}
+	return s.save(s.last)
+}
+
+func (s *sequence) save(seqs map[string]uint64) error {
+	b, err := json.Marshal(seqs)
+	if err != nil {
+		return err
+	}
+	return ioutils.AtomicWriteFile(s.path, b, 0600)
+}
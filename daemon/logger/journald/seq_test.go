@@ -0,0 +1,59 @@
+// +build linux
+
+package journald
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestSequenceResumes(t *testing.T) {
+	dir, err := ioutil.TempDir("", "journald-seq")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, seqFile)
+
+	s, err := newSequence(path, "stdout", "stderr")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 3; i++ {
+		s.next("stdout")
+	}
+	s.next("stderr")
+	if seq := s.next("other"); seq != 0 {
+		t.Fatalf("expected the messages of an unknown stream not to be numbered, got %d", seq)
+	}
+	if err := s.close(); err != nil {
+		t.Fatal(err)
+	}
+
+	// the numbering resumes after a restart
+	s, err = newSequence(path, "stdout", "stderr")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if seq := s.next("stdout"); seq != 4 {
+		t.Fatalf("expected stdout to resume at 4, got %d", seq)
+	}
+	if seq := s.next("stderr"); seq != 2 {
+		t.Fatalf("expected stderr to resume at 2, got %d", seq)
+	}
+
+	// without close, as after a crash, it resumes after the reserved
+	// numbers
+	for i := 0; i < seqReserve; i++ {
+		s.next("stdout")
+	}
+	s, err = newSequence(path, "stdout", "stderr")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if seq := s.next("stdout"); seq <= 4+seqReserve {
+		t.Fatalf("expected stdout to resume after %d, got %d", 4+seqReserve, seq)
+	}
+}
@@ -0,0 +1,90 @@
+// +build linux
+
+package journald
+
+import (
+	"bytes"
+	"fmt"
+	"os/exec"
+	"sync"
+	"time"
+
+	"github.com/Sirupsen/logrus"
+)
+
+// vacuumInterval is how often the journal files of a namespace are vacuumed
+// while containers log to it.
+const vacuumInterval = time.Hour
+
+// vacuumNamespace removes the archived journal files of namespace whose
+// entries are all older than maxAge.
+var vacuumNamespace = func(namespace string, maxAge time.Duration) error {
+	out, err := exec.Command("journalctl", "--namespace="+namespace, fmt.Sprintf("--vacuum-time=%ds", int64(maxAge/time.Second))).CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("%v: %s", err, bytes.TrimSpace(out))
+	}
+	return nil
+}
+
+// vacuumer vacuums the journal files of a namespace for the retention policy
+// of the daemon, as long as containers log to the namespace.
+type vacuumer struct {
+	refs   int
+	maxAge time.Duration
+	stop   chan struct{}
+}
+
+var (
+	vacuumersMu sync.Mutex
+	vacuumers   = make(map[string]*vacuumer)
+)
+
+// startVacuum vacuums the journal files of namespace older than maxAge now,
+// then every vacuumInterval, until stopVacuum is called as many times as
+// startVacuum. The latest maxAge is used, so that a reloaded policy applies.
+func startVacuum(namespace string, maxAge time.Duration) {
+	vacuumersMu.Lock()
+	defer vacuumersMu.Unlock()
+	v, ok := vacuumers[namespace]
+	if !ok {
+		v = &vacuumer{stop: make(chan struct{})}
+		vacuumers[namespace] = v
+		go v.run(namespace)
+	}
+	v.refs++
+	v.maxAge = maxAge
+}
+
+// stopVacuum stops vacuuming namespace once no container logs to it.
+func stopVacuum(namespace string) {
+	vacuumersMu.Lock()
+	defer vacuumersMu.Unlock()
+	v, ok := vacuumers[namespace]
+	if !ok {
+		return
+	}
+	v.refs--
+	if v.refs == 0 {
+		close(v.stop)
+		delete(vacuumers, namespace)
+	}
+}
+
+func (v *vacuumer) run(namespace string) {
+	ticker := time.NewTicker(vacuumInterval)
+	defer ticker.Stop()
+	for {
+		vacuumersMu.Lock()
+		maxAge := v.maxAge
+		vacuumersMu.Unlock()
+		if err := vacuumNamespace(namespace, maxAge); err != nil {
+			logrus.Warnf("Failed to vacuum the journal files of namespace %s: %v", namespace, err)
+		}
+
+		select {
+		case <-v.stop:
+			return
+		case <-ticker.C:
+		}
+	}
+}
@@ -0,0 +1,54 @@
+// +build linux
+
+package journald
+
+import (
+	"testing"
+	"time"
+)
+
+func TestVacuum(t *testing.T) {
+	type call struct {
+		namespace string
+		maxAge    time.Duration
+	}
+	calls := make(chan call, 10)
+	defer func(f func(string, time.Duration) error) { vacuumNamespace = f }(vacuumNamespace)
+	vacuumNamespace = func(namespace string, maxAge time.Duration) error {
+		calls <- call{namespace, maxAge}
+		return nil
+	}
+
+	// the namespace is vacuumed once when the first logger starts
+	startVacuum("webapps", time.Hour)
+	startVacuum("webapps", time.Hour)
+	select {
+	case c := <-calls:
+		if c.namespace != "webapps" || c.maxAge != time.Hour {
+			t.Fatalf("expected webapps to be vacuumed for an hour, got %+v", c)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("expected the namespace to be vacuumed")
+	}
+	select {
+	case c := <-calls:
+		t.Fatalf("expected a single vacuum for both loggers, got %+v", c)
+	case <-time.After(100 * time.Millisecond):
+	}
+
+	// it keeps being vacuumed until the last logger stops
+	stopVacuum("webapps")
+	vacuumersMu.Lock()
+	_, ok := vacuumers["webapps"]
+	vacuumersMu.Unlock()
+	if !ok {
+		t.Fatal("expected the namespace to be vacuumed while a logger uses it")
+	}
+	stopVacuum("webapps")
+	vacuumersMu.Lock()
+	_, ok = vacuumers["webapps"]
+	vacuumersMu.Unlock()
+	if ok {
+		t.Fatal("expected the namespace not to be vacuumed once no logger uses it")
+	}
+}
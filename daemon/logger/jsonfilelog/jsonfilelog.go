@@ -20,6 +20,11 @@ import (
 // Name is the name of the file that the jsonlogger logs to.
 const Name = "json-file"
 
+// minFileSize is the minimum size of the log files when their size is set
+// by the retention policy of the daemon, so that the files are not rotated
+// for each entry.
+const minFileSize = 1024
+
 // JSONFileLogger is Logger implementation for default Docker logging.
 type JSONFileLogger struct {
 	buf     *bytes.Buffer
@@ -36,6 +41,9 @@ func init() {
 	if err := logger.RegisterLogOptValidator(Name, ValidateLogOpt); err != nil {
 		logrus.Fatal(err)
 	}
+	if err := logger.RegisterRetentionDriver(Name); err != nil {
+		logrus.Fatal(err)
+	}
 }
 
 // New creates new JSONFileLogger which writes to filename passed in
@@ -61,10 +69,31 @@ func New(ctx logger.Context) (logger.Logger, error) {
 		}
 	}
 
+	// Enforce the daemon's retention policy: the files kept must not
+	// exceed its number of files, nor its maximum size all together.
+	if ctx.Retention.MaxFiles > 0 && maxFiles > ctx.Retention.MaxFiles {
+		maxFiles = ctx.Retention.MaxFiles
+	}
+	if maxSize := ctx.Retention.MaxSize; maxSize > 0 {
+		// fewer files are kept if they would be smaller than minFileSize
+		if maxSize/int64(maxFiles) < minFileSize {
+			maxFiles = int(maxSize / minFileSize)
+			if maxFiles < 1 {
+				maxFiles = 1
+			}
+		}
+		if limit := maxSize / int64(maxFiles); capval == -1 || capval > limit {
+			capval = limit
+		}
+	}
+
 	writer, err := loggerutils.NewRotateFileWriter(ctx.LogPath, capval, maxFiles)
 	if err != nil {
 		return nil, err
 	}
+	if ctx.Retention.MaxAge > 0 {
+		writer.SetMaxAge(ctx.Retention.MaxAge)
+	}
 
 	var extra []byte
 	if attrs := ctx.ExtraAttributes(nil); len(attrs) > 0 {
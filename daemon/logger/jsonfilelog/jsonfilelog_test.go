@@ -152,6 +152,119 @@ func TestJSONFileLoggerWithOpts(t *testing.T) {
 
 }
 
+func TestJSONFileLoggerRetention(t *testing.T) {
+	cid := "a7317399f3f857173c6179d44823594f8294678dea9999662e5c625b5a1c7657"
+	tmp, err := ioutil.TempDir("", "docker-logger-")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(tmp)
+	filename := filepath.Join(tmp, "container.log")
+	// the retention policy caps the 10k files to 1k each
+	config := map[string]string{"max-file": "2", "max-size": "10k"}
+	l, err := New(logger.Context{
+		ContainerID: cid,
+		LogPath:     filename,
+		Config:      config,
+		Retention:   logger.Retention{MaxSize: 2048},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer l.Close()
+	for i := 0; i < 100; i++ {
+		if err := l.Log(&logger.Message{Line: []byte("line" + strconv.Itoa(i)), Source: "src1"}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	var total int64
+	for _, name := range []string{filename, filename + ".1"} {
+		fi, err := os.Stat(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		total += fi.Size()
+	}
+	// a file may exceed its capacity by the last entry written to it
+	if total > 2048+2*128 {
+		t.Fatalf("expected the logs to be capped to about 2k, got %d bytes", total)
+	}
+	if _, err := os.Stat(filename + ".2"); !os.IsNotExist(err) {
+		t.Fatalf("expected max-file to be kept, got %v", err)
+	}
+}
+
+func TestJSONFileLoggerRetentionMaxFiles(t *testing.T) {
+	cid := "a7317399f3f857173c6179d44823594f8294678dea9999662e5c625b5a1c7657"
+	tmp, err := ioutil.TempDir("", "docker-logger-")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(tmp)
+	filename := filepath.Join(tmp, "container.log")
+	// the retention policy caps the 5 files to 2
+	config := map[string]string{"max-file": "5", "max-size": "1k"}
+	l, err := New(logger.Context{
+		ContainerID: cid,
+		LogPath:     filename,
+		Config:      config,
+		Retention:   logger.Retention{MaxFiles: 2},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer l.Close()
+	for i := 0; i < 100; i++ {
+		if err := l.Log(&logger.Message{Line: []byte("line" + strconv.Itoa(i)), Source: "src1"}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if _, err := os.Stat(filename + ".1"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(filename + ".2"); !os.IsNotExist(err) {
+		t.Fatalf("expected 2 files to be kept, got %v", err)
+	}
+}
+
+func TestJSONFileLoggerRetentionMinFileSize(t *testing.T) {
+	cid := "a7317399f3f857173c6179d44823594f8294678dea9999662e5c625b5a1c7657"
+	tmp, err := ioutil.TempDir("", "docker-logger-")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(tmp)
+	filename := filepath.Join(tmp, "container.log")
+	// 10 files of 300 bytes would be rotated for each entry, so only 2
+	// files of 1500 bytes are kept
+	config := map[string]string{"max-file": "10"}
+	l, err := New(logger.Context{
+		ContainerID: cid,
+		LogPath:     filename,
+		Config:      config,
+		Retention:   logger.Retention{MaxSize: 3000},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer l.Close()
+	for i := 0; i < 100; i++ {
+		if err := l.Log(&logger.Message{Line: []byte("line" + strconv.Itoa(i)), Source: "src1"}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	fi, err := os.Stat(filename + ".1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if fi.Size() < 1500 {
+		t.Fatalf("expected the rotated file to hold about 1500 bytes, got %d", fi.Size())
+	}
+	if _, err := os.Stat(filename + ".2"); !os.IsNotExist(err) {
+		t.Fatalf("expected 2 files to be kept, got %v", err)
+	}
+}
+
 func TestJSONFileLoggerWithLabelsEnv(t *testing.T) {
 	cid := "a7317399f3f857173c6179d44823594f8294678dea9999662e5c625b5a1c7657"
 	tmp, err := ioutil.TempDir("", "docker-logger-")
@@ -32,6 +32,9 @@ type Message struct {
 	Source    string
 	Timestamp time.Time
 	Attrs     LogAttributes
+	// Cursor points at the message in the backend of a CursorReader, for
+	// the messages it reads.
+	Cursor string
 }
 
 // LogAttributes is used to hold the extra attributes available in the log message
@@ -67,7 +70,12 @@ type Logger interface {
 
 // ReadConfig is the configuration passed into ReadLogs.
 type ReadConfig struct {
-	Since  time.Time
+	Since time.Time
+	// SinceCursor is a cursor of the backend of a CursorReader, to read
+	// the logs after the entry of the cursor instead of since a time.
+	SinceCursor string
+	// Until is the time logs are read until, if it is set.
+	Until  time.Time
 	Tail   int
 	Follow bool
 }
@@ -78,6 +86,14 @@ type LogReader interface {
 	ReadLogs(ReadConfig) *LogWatcher
 }
 
+// CursorReader is implemented by the log readers whose backend has cursors
+// pointing at its entries, like the journal, which they can read logs after.
+type CursorReader interface {
+	LogReader
+	// IsCursor reports whether s is a cursor of the backend.
+	IsCursor(s string) bool
+}
+
 // LogWatcher is used when consuming logs read from the LogReader interface.
 type LogWatcher struct {
 	// For sending log messages to a reader.
@@ -0,0 +1,223 @@
+package loggerutils
+
+import (
+	"crypto/tls"
+	"fmt"
+	"net"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/Sirupsen/logrus"
+	"github.com/docker/docker/daemon/logger"
+	"github.com/docker/go-connections/tlsconfig"
+	"github.com/docker/go-units"
+)
+
+const (
+	minReconnectDelay = 100 * time.Millisecond
+	maxReconnectDelay = 30 * time.Second
+)
+
+// DialFunc establishes a new connection to a log collector.
+type DialFunc func() (net.Conn, error)
+
+// Forwarder delivers encoded log records to a collector over a stream
+// connection. The connection is re-established with exponential backoff
+// after a failure. When a spool is configured, records that cannot be
+// delivered are stored on disk and replayed, in order, before any new
+// record once the collector is reachable again.
+type Forwarder struct {
+	mu        sync.Mutex
+	dial      DialFunc
+	conn      net.Conn
+	spool     *Spool
+	stats     *DeliveryStats
+	delay     time.Duration
+	nextDial  time.Time
+	connected bool // true once a connection has been established
+}
+
+// NewForwarder creates a Forwarder using dial to connect. spool may be nil,
+// in which case undeliverable records are reported as errors.
+func NewForwarder(dial DialFunc, spool *Spool, stats *DeliveryStats) *Forwarder {
+	return &Forwarder{
+		dial:  dial,
+		spool: spool,
+		stats: stats,
+	}
+}
+
+// Send delivers record, spooling it if the collector is unreachable.
+func (f *Forwarder) Send(record []byte) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.spool != nil && f.spool.Len() > 0 {
+		if err := f.spool.Replay(f.write); err != nil {
+			return f.store(record, err)
+		}
+	}
+	if err := f.write(record); err != nil {
+		return f.store(record, err)
+	}
+	return nil
+}
+
+// store puts a record that failed to be delivered with err in the spool.
+func (f *Forwarder) store(record []byte, err error) error {
+	if f.spool == nil {
+		f.stats.Failed.Add(1)
+		return err
+	}
+	if serr := f.spool.Append(record); serr != nil {
+		if serr == ErrSpoolFull {
+			f.stats.Dropped.Add(1)
+		} else {
+			f.stats.Failed.Add(1)
+		}
+		return fmt.Errorf("%v (message not spooled: %v)", err, serr)
+	}
+	f.stats.Spooled.Add(1)
+	return nil
+}
+
+// write sends a single record over the connection, dialing if needed.
+func (f *Forwarder) write(record []byte) error {
+	if err := f.connect(); err != nil {
+		return err
+	}
+	if _, err := f.conn.Write(record); err != nil {
+		f.disconnect()
+		return err
+	}
+	f.stats.Sent.Add(1)
+	return nil
+}
+
+func (f *Forwarder) connect() error {
+	if f.conn != nil {
+		return nil
+	}
+	now := time.Now()
+	if now.Before(f.nextDial) {
+		return fmt.Errorf("collector unavailable, next connection attempt in %s", units.HumanDuration(f.nextDial.Sub(now)))
+	}
+	conn, err := f.dial()
+	if err != nil {
+		f.backoff()
+		return err
+	}
+	if f.connected {
+		f.stats.Reconnects.Add(1)
+		logrus.Debugf("reconnected to log collector %s", conn.RemoteAddr())
+	}
+	f.conn = conn
+	f.connected = true
+	f.delay = 0
+	return nil
+}
+
+func (f *Forwarder) disconnect() {
+	f.conn.Close()
+	f.conn = nil
+	f.backoff()
+}
+
+func (f *Forwarder) backoff() {
+	if f.delay == 0 {
+		f.delay = minReconnectDelay
+	} else if f.delay *= 2; f.delay > maxReconnectDelay {
+		f.delay = maxReconnectDelay
+	}
+	f.nextDial = time.Now().Add(f.delay)
+}
+
+// Close flushes spooled records if possible and closes the connection.
+// Records that could not be flushed stay in the spool.
+func (f *Forwarder) Close() error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.spool != nil {
+		if f.spool.Len() > 0 {
+			if err := f.spool.Replay(f.write); err != nil {
+				logrus.Warnf("%d log messages left in spool: %v", f.spool.Len(), err)
+			}
+		}
+		f.spool.Close()
+	}
+	if f.conn != nil {
+		return f.conn.Close()
+	}
+	return nil
+}
+
+// TLSOptionKeys returns the log-opt keys used to configure TLS for the
+// driver with the given option prefix, eg. "gelf-tls-ca-cert".
+func TLSOptionKeys(prefix string) []string {
+	return []string{prefix + "-tls-ca-cert", prefix + "-tls-cert", prefix + "-tls-key", prefix + "-tls-skip-verify"}
+}
+
+// ParseTLSSkipVerify parses the "<prefix>-tls-skip-verify" log-opt as a
+// boolean. The verification is skipped only if it is set to true.
+func ParseTLSSkipVerify(cfg map[string]string, prefix string) (bool, error) {
+	v, ok := cfg[prefix+"-tls-skip-verify"]
+	if !ok {
+		return false, nil
+	}
+	skipVerify, err := strconv.ParseBool(v)
+	if err != nil {
+		return false, fmt.Errorf("invalid %s-tls-skip-verify %q: must be a boolean", prefix, v)
+	}
+	return skipVerify, nil
+}
+
+// ParseTLSConfig builds a client TLS configuration from the TLS log-opts
+// of the driver with the given option prefix.
+func ParseTLSConfig(cfg map[string]string, prefix string) (*tls.Config, error) {
+	skipVerify, err := ParseTLSSkipVerify(cfg, prefix)
+	if err != nil {
+		return nil, err
+	}
+
+	opts := tlsconfig.Options{
+		CAFile:             cfg[prefix+"-tls-ca-cert"],
+		CertFile:           cfg[prefix+"-tls-cert"],
+		KeyFile:            cfg[prefix+"-tls-key"],
+		InsecureSkipVerify: skipVerify,
+	}
+
+	return tlsconfig.Client(opts)
+}
+
+// ParseSpoolSize parses the "<prefix>-spool-size" log-opt. A size of 0
+// means spooling is disabled.
+func ParseSpoolSize(cfg map[string]string, prefix string) (int64, error) {
+	v, ok := cfg[prefix+"-spool-size"]
+	if !ok || v == "" {
+		return 0, nil
+	}
+	size, err := units.RAMInBytes(v)
+	if err != nil {
+		return 0, fmt.Errorf("invalid %s-spool-size %q: %v", prefix, v, err)
+	}
+	if size < 0 {
+		return 0, fmt.Errorf("invalid %s-spool-size %q: size must not be negative", prefix, v)
+	}
+	return size, nil
+}
+
+// OpenSpool opens the spool of the container described by ctx if the
+// driver with the given option prefix has spooling enabled. It returns
+// nil if spooling is disabled.
+func OpenSpool(ctx logger.Context, prefix string) (*Spool, error) {
+	size, err := ParseSpoolSize(ctx.Config, prefix)
+	if err != nil || size == 0 {
+		return nil, err
+	}
+	if ctx.SpoolPath == "" {
+		return nil, fmt.Errorf("%s-spool-size is not supported for this container", prefix)
+	}
+	return NewSpool(ctx.SpoolPath, size)
+}
@@ -0,0 +1,137 @@
+package loggerutils
+
+import (
+	"bufio"
+	"io/ioutil"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestForwarderSpoolsWhileUnreachable(t *testing.T) {
+	dir, err := ioutil.TempDir("", "forwarder")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	addr := l.Addr().String()
+	l.Close()
+
+	spool, err := NewSpool(filepath.Join(dir, "test.spool"), 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	stats := &DeliveryStats{}
+	f := NewForwarder(func() (net.Conn, error) {
+		return net.Dial("tcp", addr)
+	}, spool, stats)
+	defer f.Close()
+
+	if err := f.Send([]byte("first\n")); err != nil {
+		t.Fatalf("expected message to be spooled, got %v", err)
+	}
+	if stats.Spooled.Value() != 1 {
+		t.Fatalf("expected 1 spooled message, got %d", stats.Spooled.Value())
+	}
+
+	l, err = net.Listen("tcp", addr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer l.Close()
+	lines := make(chan string, 2)
+	go func() {
+		conn, err := l.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		r := bufio.NewReader(conn)
+		for {
+			line, err := r.ReadString('\n')
+			if err != nil {
+				return
+			}
+			lines <- line
+		}
+	}()
+
+	// wait for the reconnect backoff to expire
+	time.Sleep(2 * minReconnectDelay)
+	if err := f.Send([]byte("second\n")); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, expected := range []string{"first\n", "second\n"} {
+		select {
+		case line := <-lines:
+			if line != expected {
+				t.Fatalf("expected %q, got %q", expected, line)
+			}
+		case <-time.After(5 * time.Second):
+			t.Fatalf("timeout waiting for %q", expected)
+		}
+	}
+	if stats.Sent.Value() != 2 {
+		t.Fatalf("expected 2 sent messages, got %d", stats.Sent.Value())
+	}
+	if spool.Len() != 0 {
+		t.Fatalf("expected spool to be drained, got %d records", spool.Len())
+	}
+}
+
+func TestForwarderWithoutSpool(t *testing.T) {
+	stats := &DeliveryStats{}
+	f := NewForwarder(func() (net.Conn, error) {
+		return nil, &net.OpError{Op: "dial", Err: os.ErrNotExist}
+	}, nil, stats)
+	if err := f.Send([]byte("msg")); err == nil {
+		t.Fatal("expected an error without a spool")
+	}
+	if stats.Failed.Value() != 1 {
+		t.Fatalf("expected 1 failed message, got %d", stats.Failed.Value())
+	}
+}
+
+func TestParseSpoolSize(t *testing.T) {
+	size, err := ParseSpoolSize(map[string]string{"gelf-spool-size": "1k"}, "gelf")
+	if err != nil || size != 1024 {
+		t.Fatalf("unexpected result %d, %v", size, err)
+	}
+	if _, err := ParseSpoolSize(map[string]string{"gelf-spool-size": "lots"}, "gelf"); err == nil {
+		t.Fatal("expected an error for an invalid size")
+	}
+}
+
+func TestParseTLSConfigSkipVerify(t *testing.T) {
+	for _, tc := range []struct {
+		cfg        map[string]string
+		skipVerify bool
+	}{
+		{map[string]string{}, false},
+		{map[string]string{"gelf-tls-skip-verify": "true"}, true},
+		{map[string]string{"gelf-tls-skip-verify": "false"}, false},
+		{map[string]string{"gelf-tls-skip-verify": "0"}, false},
+	} {
+		config, err := ParseTLSConfig(tc.cfg, "gelf")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if config.InsecureSkipVerify != tc.skipVerify {
+			t.Fatalf("expected InsecureSkipVerify %v for %v, got %v", tc.skipVerify, tc.cfg, config.InsecureSkipVerify)
+		}
+	}
+
+	for _, v := range []string{"", "yes"} {
+		if _, err := ParseTLSConfig(map[string]string{"gelf-tls-skip-verify": v}, "gelf"); err == nil {
+			t.Fatalf("expected gelf-tls-skip-verify=%q to be rejected", v)
+		}
+	}
+}
@@ -0,0 +1,50 @@
+package loggerutils
+
+import (
+	"expvar"
+	"sync"
+)
+
+var (
+	deliveryStatsMu sync.Mutex
+	deliveryStats   = make(map[string]*DeliveryStats)
+	deliveryVars    = expvar.NewMap("logdrivers")
+)
+
+// DeliveryStats holds the delivery counters of a log driver. They are
+// shared by all containers using the driver and published through the
+// daemon's /debug/vars endpoint under "logdrivers".
+type DeliveryStats struct {
+	// Sent counts messages accepted by the remote endpoint.
+	Sent expvar.Int
+	// Failed counts messages that could not be delivered nor spooled.
+	Failed expvar.Int
+	// Spooled counts messages written to disk while the endpoint was
+	// unreachable.
+	Spooled expvar.Int
+	// Dropped counts messages discarded because the spool was full.
+	Dropped expvar.Int
+	// Reconnects counts connections re-established after a failure.
+	Reconnects expvar.Int
+}
+
+// GetDeliveryStats returns the delivery counters for the named driver,
+// registering them on first use.
+func GetDeliveryStats(driver string) *DeliveryStats {
+	deliveryStatsMu.Lock()
+	defer deliveryStatsMu.Unlock()
+
+	if s, ok := deliveryStats[driver]; ok {
+		return s
+	}
+	s := &DeliveryStats{}
+	m := new(expvar.Map).Init()
+	m.Set("sent", &s.Sent)
+	m.Set("failed", &s.Failed)
+	m.Set("spooled", &s.Spooled)
+	m.Set("dropped", &s.Dropped)
+	m.Set("reconnects", &s.Reconnects)
+	deliveryVars.Set(driver, m)
+	deliveryStats[driver] = s
+	return s
+}
@@ -4,17 +4,26 @@ import (
 	"os"
 	"strconv"
 	"sync"
+	"time"
 
+	"github.com/Sirupsen/logrus"
 	"github.com/docker/docker/pkg/pubsub"
 )
 
+// minExpiryInterval is the minimum interval between two checks for expired
+// entries when nothing is written.
+const minExpiryInterval = 10 * time.Millisecond
+
 // RotateFileWriter is Logger implementation for default Docker logging.
 type RotateFileWriter struct {
 	f            *os.File // store for closing
 	mu           sync.Mutex
-	capacity     int64 //maximum size of each file
-	currentSize  int64 // current size of the latest file
-	maxFiles     int   //maximum number of files
+	capacity     int64         //maximum size of each file
+	currentSize  int64         // current size of the latest file
+	maxFiles     int           //maximum number of files
+	maxAge       time.Duration // maximum age of the entries kept, 0 for no limit
+	opened       time.Time     // time the first entry of the latest file was written
+	stopExpiry   chan struct{} // closed to stop expiring the entries, nil if there is no maxAge
 	notifyRotate *pubsub.Publisher
 }
 
@@ -35,6 +44,7 @@ func NewRotateFileWriter(logPath string, capacity int64, maxFiles int) (*RotateF
 		capacity:     capacity,
 		currentSize:  size,
 		maxFiles:     maxFiles,
+		opened:       time.Now(),
 		notifyRotate: pubsub.NewPublisher(0, 1),
 	}, nil
 }
@@ -47,6 +57,9 @@ func (w *RotateFileWriter) Write(message []byte) (int, error) {
 		return -1, err
 	}
 
+	if w.currentSize == 0 {
+		w.opened = time.Now()
+	}
 	n, err := w.f.Write(message)
 	if err == nil {
 		w.currentSize += int64(n)
@@ -55,31 +68,93 @@ func (w *RotateFileWriter) Write(message []byte) (int, error) {
 	return n, err
 }
 
-func (w *RotateFileWriter) checkCapacityAndRotate() error {
-	if w.capacity == -1 {
-		return nil
+// SetMaxAge limits the age of the log entries kept by the writer. The latest
+// file is rotated once its first entry is older than maxAge, and rotated
+// files whose entries are all older than maxAge are removed. The entries
+// expire even when nothing is written. With less than two files, there is
+// no rotated file to keep the latest entries in, so the entries only expire
+// when the file is rotated for its size.
+func (w *RotateFileWriter) SetMaxAge(maxAge time.Duration) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.maxAge = maxAge
+	if maxAge > 0 && w.stopExpiry == nil {
+		interval := maxAge / 10
+		if interval < minExpiryInterval {
+			interval = minExpiryInterval
+		}
+		w.stopExpiry = make(chan struct{})
+		go w.expireEntries(w.stopExpiry, interval)
 	}
+}
 
-	if w.currentSize >= w.capacity {
-		name := w.f.Name()
-		if err := w.f.Close(); err != nil {
-			return err
-		}
-		if err := rotate(name, w.maxFiles); err != nil {
-			return err
+// expireEntries rotates the latest file and removes the rotated files once
+// they expire, checking every interval until stop is closed.
+func (w *RotateFileWriter) expireEntries(stop chan struct{}, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-stop:
+			return
+		case <-ticker.C:
 		}
-		file, err := os.OpenFile(name, os.O_WRONLY|os.O_TRUNC|os.O_CREATE, 06400)
-		if err != nil {
-			return err
+		w.mu.Lock()
+		if err := w.checkCapacityAndRotate(); err != nil {
+			logrus.Warnf("Failed to rotate the expired log file %s: %v", w.f.Name(), err)
+		} else if w.maxAge > 0 {
+			removeExpired(w.f.Name(), w.maxFiles, w.maxAge)
 		}
-		w.f = file
-		w.currentSize = 0
-		w.notifyRotate.Publish(struct{}{})
+		w.mu.Unlock()
+	}
+}
+
+func (w *RotateFileWriter) checkCapacityAndRotate() error {
+	full := w.capacity != -1 && w.currentSize >= w.capacity
+	// Rotating on age needs a rotated file to keep the latest entries in,
+	// or they would be truncated with the expired ones.
+	expired := w.maxAge > 0 && w.maxFiles > 1 && w.currentSize > 0 && time.Since(w.opened) >= w.maxAge
+	if !full && !expired {
+		return nil
 	}
 
+	name := w.f.Name()
+	if err := w.f.Close(); err != nil {
+		return err
+	}
+	if err := rotate(name, w.maxFiles); err != nil {
+		return err
+	}
+	file, err := os.OpenFile(name, os.O_WRONLY|os.O_TRUNC|os.O_CREATE, 06400)
+	if err != nil {
+		return err
+	}
+	w.f = file
+	w.currentSize = 0
+	w.opened = time.Now()
+	if w.maxAge > 0 {
+		removeExpired(name, w.maxFiles, w.maxAge)
+	}
+	w.notifyRotate.Publish(struct{}{})
+
 	return nil
 }
 
+// removeExpired removes the rotated files of name that were last written
+// more than maxAge ago.
+func removeExpired(name string, maxFiles int, maxAge time.Duration) {
+	for i := 1; i < maxFiles; i++ {
+		path := name + "." + strconv.Itoa(i)
+		fi, err := os.Stat(path)
+		if err != nil {
+			continue
+		}
+		if time.Since(fi.ModTime()) >= maxAge {
+			os.Remove(path)
+		}
+	}
+}
+
 func rotate(name string, maxFiles int) error {
 	if maxFiles < 2 {
 		return nil
@@ -120,5 +195,11 @@ func (w *RotateFileWriter) NotifyRotateEvict(sub chan interface{}) {
 
 // Close closes underlying file and signals all readers to stop.
 func (w *RotateFileWriter) Close() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.stopExpiry != nil {
+		close(w.stopExpiry)
+		w.stopExpiry = nil
+	}
 	return w.f.Close()
 }
@@ -0,0 +1,127 @@
+package loggerutils
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestRotateFileWriterMaxAge(t *testing.T) {
+	tmp, err := ioutil.TempDir("", "docker-logger-")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(tmp)
+	name := filepath.Join(tmp, "container.log")
+
+	w, err := NewRotateFileWriter(name, -1, 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer w.Close()
+	w.SetMaxAge(time.Hour)
+
+	if _, err := w.Write([]byte("first\n")); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(name + ".1"); !os.IsNotExist(err) {
+		t.Fatalf("expected no rotation before the file expires, got %v", err)
+	}
+
+	// make the current file and an old rotated file expire
+	w.opened = time.Now().Add(-2 * time.Hour)
+	if err := ioutil.WriteFile(name+".1", []byte("old\n"), 0640); err != nil {
+		t.Fatal(err)
+	}
+	old := time.Now().Add(-3 * time.Hour)
+	if err := os.Chtimes(name+".1", old, old); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := w.Write([]byte("second\n")); err != nil {
+		t.Fatal(err)
+	}
+
+	// "old" moved to .2 and was removed as expired; "first" is in .1
+	// since it was written less than an hour ago.
+	if _, err := os.Stat(name + ".2"); !os.IsNotExist(err) {
+		t.Fatalf("expected expired file to be removed, got %v", err)
+	}
+	if b, err := ioutil.ReadFile(name + ".1"); err != nil || string(b) != "first\n" {
+		t.Fatalf("expected rotated file to hold the first entry, got %q (%v)", b, err)
+	}
+	if b, err := ioutil.ReadFile(name); err != nil || string(b) != "second\n" {
+		t.Fatalf("expected current file to hold the second entry, got %q (%v)", b, err)
+	}
+}
+
+func TestRotateFileWriterMaxAgeSingleFile(t *testing.T) {
+	tmp, err := ioutil.TempDir("", "docker-logger-")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(tmp)
+	name := filepath.Join(tmp, "container.log")
+
+	w, err := NewRotateFileWriter(name, -1, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer w.Close()
+	w.SetMaxAge(time.Hour)
+
+	if _, err := w.Write([]byte("first\n")); err != nil {
+		t.Fatal(err)
+	}
+	w.mu.Lock()
+	w.opened = time.Now().Add(-2 * time.Hour)
+	w.mu.Unlock()
+	if _, err := w.Write([]byte("second\n")); err != nil {
+		t.Fatal(err)
+	}
+
+	// without a rotated file, the file is not truncated on age
+	if b, err := ioutil.ReadFile(name); err != nil || string(b) != "first\nsecond\n" {
+		t.Fatalf("expected the file to keep all the entries, got %q (%v)", b, err)
+	}
+}
+
+func TestRotateFileWriterMaxAgeIdle(t *testing.T) {
+	tmp, err := ioutil.TempDir("", "docker-logger-")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(tmp)
+	name := filepath.Join(tmp, "container.log")
+
+	w, err := NewRotateFileWriter(name, -1, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer w.Close()
+	w.SetMaxAge(100 * time.Millisecond)
+
+	if _, err := w.Write([]byte("first\n")); err != nil {
+		t.Fatal(err)
+	}
+
+	// the entry expires without anything else being written: it is
+	// rotated, then removed with its rotated file
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		b, err := ioutil.ReadFile(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		_, statErr := os.Stat(name + ".1")
+		if len(b) == 0 && os.IsNotExist(statErr) {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("expected the entry to expire, got %q in the file and %v for the rotated file", b, statErr)
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+}
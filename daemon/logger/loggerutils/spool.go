@@ -0,0 +1,214 @@
+package loggerutils
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"io"
+	"os"
+	"sync"
+)
+
+// ErrSpoolFull is returned by Spool.Append when storing the record would
+// exceed the configured size of the spool.
+var ErrSpoolFull = errors.New("log spool is full")
+
+// recordHeaderSize is the size of the length prefix written before each
+// spooled record.
+const recordHeaderSize = 4
+
+// Spool is a bounded, file backed FIFO of encoded log records. Network log
+// drivers use it to keep messages while their collector is unreachable and
+// replay them, in order, once the connection is restored. Records left in
+// the spool survive a daemon restart.
+type Spool struct {
+	mu      sync.Mutex
+	path    string
+	f       *os.File
+	maxSize int64
+	size    int64
+	records int
+}
+
+// NewSpool opens (or creates) the spool file at path. Records already
+// present in the file are kept and will be replayed first.
+func NewSpool(path string, maxSize int64) (*Spool, error) {
+	f, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE, 0600)
+	if err != nil {
+		return nil, err
+	}
+	s := &Spool{
+		path:    path,
+		f:       f,
+		maxSize: maxSize,
+	}
+	if err := s.scan(); err != nil {
+		f.Close()
+		return nil, err
+	}
+	return s, nil
+}
+
+// scan counts the complete records in the spool file, dropping a trailing
+// partial record left behind by an interrupted write.
+func (s *Spool) scan() error {
+	if _, err := s.f.Seek(0, os.SEEK_SET); err != nil {
+		return err
+	}
+	var offset int64
+	err := readRecords(s.f, func([]byte) error {
+		s.records++
+		return nil
+	}, &offset)
+	if err != nil && err != io.ErrUnexpectedEOF {
+		return err
+	}
+	if err := s.f.Truncate(offset); err != nil {
+		return err
+	}
+	s.size = offset
+	_, err = s.f.Seek(offset, os.SEEK_SET)
+	return err
+}
+
+// Append adds a record to the end of the spool.
+func (s *Spool) Append(record []byte) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	n := int64(recordHeaderSize + len(record))
+	if s.maxSize > 0 && s.size+n > s.maxSize {
+		return ErrSpoolFull
+	}
+
+	buf := make([]byte, n)
+	binary.BigEndian.PutUint32(buf, uint32(len(record)))
+	copy(buf[recordHeaderSize:], record)
+	if _, err := s.f.Write(buf); err != nil {
+		// leave the file in a consistent state for the next append
+		s.f.Truncate(s.size)
+		s.f.Seek(s.size, os.SEEK_SET)
+		return err
+	}
+	s.size += n
+	s.records++
+	return nil
+}
+
+// Len returns the number of records currently held in the spool.
+func (s *Spool) Len() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.records
+}
+
+// Replay calls send for every spooled record in the order they were
+// appended. Records are removed from the spool once send returns nil. If
+// send fails, replay stops and the remaining records are kept.
+func (s *Spool) Replay(send func([]byte) error) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.records == 0 {
+		return nil
+	}
+	if _, err := s.f.Seek(0, os.SEEK_SET); err != nil {
+		return err
+	}
+
+	var (
+		offset  int64
+		sent    int
+		sendErr error
+	)
+	err := readRecords(s.f, func(record []byte) error {
+		if sendErr = send(record); sendErr != nil {
+			return sendErr
+		}
+		sent++
+		return nil
+	}, &offset)
+	if err != nil && err != sendErr {
+		return err
+	}
+
+	if err := s.compact(offset); err != nil {
+		return err
+	}
+	s.records -= sent
+	return sendErr
+}
+
+// compact drops the first offset bytes of the spool file.
+func (s *Spool) compact(offset int64) error {
+	if offset == 0 {
+		_, err := s.f.Seek(s.size, os.SEEK_SET)
+		return err
+	}
+	if offset == s.size {
+		if err := s.f.Truncate(0); err != nil {
+			return err
+		}
+		s.size = 0
+		_, err := s.f.Seek(0, os.SEEK_SET)
+		return err
+	}
+
+	tmp, err := os.OpenFile(s.path+".tmp", os.O_RDWR|os.O_CREATE|os.O_TRUNC, 0600)
+	if err != nil {
+		return err
+	}
+	if _, err := s.f.Seek(offset, os.SEEK_SET); err != nil {
+		tmp.Close()
+		return err
+	}
+	n, err := io.Copy(tmp, s.f)
+	if err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err := os.Rename(tmp.Name(), s.path); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return err
+	}
+	s.f.Close()
+	s.f = tmp
+	s.size = n
+	return nil
+}
+
+// Close closes the spool file, keeping any records that were not
+// replayed yet.
+func (s *Spool) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.f.Close()
+}
+
+// readRecords calls fn for every complete record read from r, advancing
+// offset past each record fn accepted.
+func readRecords(r io.Reader, fn func([]byte) error, offset *int64) error {
+	br := bufio.NewReader(r)
+	header := make([]byte, recordHeaderSize)
+	for {
+		if _, err := io.ReadFull(br, header); err != nil {
+			if err == io.EOF {
+				return nil
+			}
+			return err
+		}
+		record := make([]byte, binary.BigEndian.Uint32(header))
+		if _, err := io.ReadFull(br, record); err != nil {
+			if err == io.EOF {
+				return io.ErrUnexpectedEOF
+			}
+			return err
+		}
+		if err := fn(record); err != nil {
+			return err
+		}
+		*offset += int64(recordHeaderSize + len(record))
+	}
+}
@@ -0,0 +1,114 @@
+package loggerutils
+
+import (
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestSpoolReplayInOrder(t *testing.T) {
+	dir, err := ioutil.TempDir("", "spool")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	s, err := NewSpool(filepath.Join(dir, "test.spool"), 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, r := range []string{"one", "two", "three"} {
+		if err := s.Append([]byte(r)); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	var got []string
+	errSend := errors.New("send failed")
+	err = s.Replay(func(r []byte) error {
+		if len(got) == 2 {
+			return errSend
+		}
+		got = append(got, string(r))
+		return nil
+	})
+	if err != errSend {
+		t.Fatalf("expected send error, got %v", err)
+	}
+	if len(got) != 2 || got[0] != "one" || got[1] != "two" {
+		t.Fatalf("unexpected records replayed: %v", got)
+	}
+	if s.Len() != 1 {
+		t.Fatalf("expected 1 record left in spool, got %d", s.Len())
+	}
+
+	// records must survive reopening the spool
+	if err := s.Append([]byte("four")); err != nil {
+		t.Fatal(err)
+	}
+	s.Close()
+	s, err = NewSpool(filepath.Join(dir, "test.spool"), 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer s.Close()
+
+	got = nil
+	if err := s.Replay(func(r []byte) error {
+		got = append(got, string(r))
+		return nil
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 2 || got[0] != "three" || got[1] != "four" {
+		t.Fatalf("unexpected records replayed after reopen: %v", got)
+	}
+	if s.Len() != 0 {
+		t.Fatalf("expected empty spool, got %d records", s.Len())
+	}
+}
+
+func TestSpoolFull(t *testing.T) {
+	dir, err := ioutil.TempDir("", "spool")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	s, err := NewSpool(filepath.Join(dir, "test.spool"), 12)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer s.Close()
+
+	if err := s.Append([]byte("12345678")); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Append([]byte("x")); err != ErrSpoolFull {
+		t.Fatalf("expected ErrSpoolFull, got %v", err)
+	}
+}
+
+func TestSpoolDropsPartialRecord(t *testing.T) {
+	dir, err := ioutil.TempDir("", "spool")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "test.spool")
+	// a complete "ok" record followed by a truncated one
+	if err := ioutil.WriteFile(path, []byte{0, 0, 0, 2, 'o', 'k', 0, 0, 0, 9, 'x'}, 0600); err != nil {
+		t.Fatal(err)
+	}
+	s, err := NewSpool(path, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer s.Close()
+	if s.Len() != 1 {
+		t.Fatalf("expected 1 record, got %d", s.Len())
+	}
+}
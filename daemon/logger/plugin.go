@@ -0,0 +1,186 @@
+//go:generate pluginrpc-gen -i $GOFILE -o proxy.go -type logDriver -name LogDriver
+
+package logger
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/Sirupsen/logrus"
+	"github.com/docker/docker/plugin"
+)
+
+const (
+	// pluginCapability is the capability of the plugins providing log drivers.
+	pluginCapability = "LogDriver"
+	// pluginQueueSize is the number of messages of a container queued for
+	// its plugin. Logging blocks once the queue is full.
+	pluginQueueSize = 4096
+	// pluginBatchSize is the maximum number of messages sent to a plugin
+	// in a request.
+	pluginBatchSize = 512
+)
+
+// logDriver defines the functions that log driver plugins must implement.
+// This interface is only defined to generate the proxy objects.
+// It's not intended to be public or reused.
+type logDriver interface {
+	// StartLogging starts logging the messages of the container with the given ID
+	StartLogging(id string, info pluginInfo) (err error)
+	// Log logs messages of the container with the given ID
+	Log(id string, messages []pluginMessage) (err error)
+	// StopLogging stops logging the messages of the container with the given ID
+	StopLogging(id string) (err error)
+	// ValidateLogOpts checks the log opts of a container
+	ValidateLogOpts(config map[string]string) (err error)
+}
+
+// pluginInfo is what a log driver plugin is told about the container it logs
+// the messages of.
+type pluginInfo struct {
+	Config              map[string]string
+	ContainerID         string
+	ContainerName       string
+	ContainerEntrypoint string
+	ContainerArgs       []string
+	ContainerImageID    string
+	ContainerImageName  string
+	ContainerCreated    time.Time
+	ContainerEnv        []string
+	ContainerLabels     map[string]string
+	DaemonName          string
+}
+
+// pluginMessage is a message sent to a log driver plugin. Its line is
+// encoded in base64, as it may not be valid UTF-8.
+type pluginMessage struct {
+	Line      []byte
+	Source    string
+	Timestamp time.Time
+	Attrs     map[string]string `json:",omitempty"`
+}
+
+// pluginLogger is the logger of a container whose log driver is provided by
+// a plugin. The messages are queued, and sent to the plugin in batches by a
+// goroutine, so that the container does not wait for a request to the
+// plugin for each of its lines.
+type pluginLogger struct {
+	name  string
+	id    string
+	proxy *logDriverProxy
+
+	mu       sync.RWMutex
+	closed   bool
+	messages chan pluginMessage
+	done     chan struct{}
+}
+
+func newPluginLogger(name, id string, proxy *logDriverProxy) *pluginLogger {
+	l := &pluginLogger{
+		name:     name,
+		id:       id,
+		proxy:    proxy,
+		messages: make(chan pluginMessage, pluginQueueSize),
+		done:     make(chan struct{}),
+	}
+	go l.run()
+	return l
+}
+
+// Log queues msg to be sent to the plugin, blocking while the queue is
+// full.
+func (l *pluginLogger) Log(msg *Message) error {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+	if l.closed {
+		return fmt.Errorf("the log driver plugin %s is closed", l.name)
+	}
+	l.messages <- pluginMessage{
+		Line:      msg.Line,
+		Source:    msg.Source,
+		Timestamp: msg.Timestamp,
+		Attrs:     msg.Attrs,
+	}
+	return nil
+}
+
+// run sends the queued messages to the plugin until the queue is closed.
+// Each request sends the messages queued while the previous one was in
+// progress, up to pluginBatchSize of them.
+func (l *pluginLogger) run() {
+	defer close(l.done)
+	batch := make([]pluginMessage, 0, pluginBatchSize)
+	for m := range l.messages {
+		batch = append(batch[:0], m)
+	fill:
+		for len(batch) < pluginBatchSize {
+			select {
+			case m, ok := <-l.messages:
+				if !ok {
+					break fill
+				}
+				batch = append(batch, m)
+			default:
+				break fill
+			}
+		}
+		if err := l.proxy.Log(l.id, batch); err != nil {
+			logrus.Errorf("Error sending %d log messages of container %s to log driver plugin %s: %v", len(batch), l.id, l.name, err)
+		}
+	}
+}
+
+// Name returns the name of the plugin.
+func (l *pluginLogger) Name() string {
+	return l.name
+}
+
+// Close sends the queued messages to the plugin, and tells it to stop
+// logging the messages of the container.
+func (l *pluginLogger) Close() error {
+	l.mu.Lock()
+	if !l.closed {
+		l.closed = true
+		close(l.messages)
+	}
+	l.mu.Unlock()
+	<-l.done
+	return l.proxy.StopLogging(l.id)
+}
+
+// lookupPlugin returns the Creator and the LogOptValidator of the log driver
+// provided by the plugin name.
+func lookupPlugin(name string) (Creator, LogOptValidator, bool) {
+	p, err := plugin.LookupWithCapability(name, pluginCapability)
+	if err != nil {
+		logrus.Debugf("No log driver plugin named %s: %v", name, err)
+		return nil, nil, false
+	}
+	proxy := &logDriverProxy{p.Client()}
+	return newPluginCreator(name, proxy), proxy.ValidateLogOpts, true
+}
+
+// newPluginCreator returns the Creator of the loggers of the log driver
+// plugin name.
+func newPluginCreator(name string, proxy *logDriverProxy) Creator {
+	return func(ctx Context) (Logger, error) {
+		info := pluginInfo{
+			Config:              ctx.Config,
+			ContainerID:         ctx.ContainerID,
+			ContainerName:       ctx.Name(),
+			ContainerEntrypoint: ctx.ContainerEntrypoint,
+			ContainerArgs:       ctx.ContainerArgs,
+			ContainerImageID:    ctx.ContainerImageID,
+			ContainerImageName:  ctx.ContainerImageName,
+			ContainerCreated:    ctx.ContainerCreated,
+			ContainerEnv:        ctx.ContainerEnv,
+			ContainerLabels:     ctx.ContainerLabels,
+			DaemonName:          ctx.DaemonName,
+		}
+		if err := proxy.StartLogging(ctx.ContainerID, info); err != nil {
+			return nil, err
+		}
+		return newPluginLogger(name, ctx.ContainerID, proxy), nil
+	}
+}
@@ -0,0 +1,160 @@
+package logger
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/docker/docker/pkg/plugins"
+	"github.com/docker/go-connections/tlsconfig"
+)
+
+func TestPluginLogger(t *testing.T) {
+	mux := http.NewServeMux()
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	var (
+		started logDriverProxyStartLoggingRequest
+		logged  []logDriverProxyLogRequest
+		stopped logDriverProxyStopLoggingRequest
+	)
+	handle := func(method string, decode func(*json.Decoder) error, response string) {
+		mux.HandleFunc("/LogDriver."+method, func(w http.ResponseWriter, r *http.Request) {
+			if err := decode(json.NewDecoder(r.Body)); err != nil {
+				t.Errorf("%s: %v", method, err)
+			}
+			w.Header().Set("Content-Type", "application/vnd.docker.plugins.v1+json")
+			fmt.Fprintln(w, response)
+		})
+	}
+	handle("StartLogging", func(d *json.Decoder) error { return d.Decode(&started) }, "{}")
+	handle("Log", func(d *json.Decoder) error {
+		var req logDriverProxyLogRequest
+		err := d.Decode(&req)
+		logged = append(logged, req)
+		return err
+	}, "{}")
+	handle("StopLogging", func(d *json.Decoder) error { return d.Decode(&stopped) }, "{}")
+	handle("ValidateLogOpts", func(d *json.Decoder) error { return nil }, `{"Err": "unknown log opt 'unknown'"}`)
+
+	u, _ := url.Parse(server.URL)
+	client, err := plugins.NewClient("tcp://"+u.Host, &tlsconfig.Options{InsecureSkipVerify: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	proxy := &logDriverProxy{client}
+
+	if err := proxy.ValidateLogOpts(map[string]string{"unknown": "1"}); err == nil || err.Error() != "unknown log opt 'unknown'" {
+		t.Fatalf("expected the plugin to reject the log opt, got %v", err)
+	}
+
+	l, err := newPluginCreator("test", proxy)(Context{
+		Config:        map[string]string{"tag": "web"},
+		ContainerID:   "abc",
+		ContainerName: "/web",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if started.ID != "abc" || started.Info.ContainerName != "web" || started.Info.Config["tag"] != "web" {
+		t.Fatalf("unexpected StartLogging request %+v", started)
+	}
+	if l.Name() != "test" {
+		t.Fatalf("unexpected name %s", l.Name())
+	}
+
+	now := time.Now().UTC()
+	// the line is not valid UTF-8, and must be sent as is
+	line := []byte{'h', 'i', 0xff}
+	if err := l.Log(&Message{Line: line, Source: "stderr", Timestamp: now}); err != nil {
+		t.Fatal(err)
+	}
+
+	// the queued messages are sent before the plugin stops logging
+	if err := l.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if len(logged) != 1 || len(logged[0].Messages) != 1 {
+		t.Fatalf("expected 1 message to be logged, got %+v", logged)
+	}
+	if m := logged[0]; m.ID != "abc" || string(m.Messages[0].Line) != string(line) || m.Messages[0].Source != "stderr" || !m.Messages[0].Timestamp.Equal(now) {
+		t.Fatalf("unexpected Log request %+v", m)
+	}
+	if stopped.ID != "abc" {
+		t.Fatalf("unexpected StopLogging request %+v", stopped)
+	}
+	if err := l.Log(&Message{Line: line}); err == nil {
+		t.Fatal("expected logging to a closed logger to fail")
+	}
+}
+
+func TestPluginLoggerBatches(t *testing.T) {
+	mux := http.NewServeMux()
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	var (
+		mu      sync.Mutex
+		logged  []logDriverProxyLogRequest
+		release = make(chan struct{})
+	)
+	mux.HandleFunc("/LogDriver.Log", func(w http.ResponseWriter, r *http.Request) {
+		var req logDriverProxyLogRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			t.Error(err)
+		}
+		mu.Lock()
+		logged = append(logged, req)
+		first := len(logged) == 1
+		mu.Unlock()
+		// the messages logged while the first request is in progress
+		// are queued
+		if first {
+			<-release
+		}
+		w.Header().Set("Content-Type", "application/vnd.docker.plugins.v1+json")
+		fmt.Fprintln(w, "{}")
+	})
+	mux.HandleFunc("/LogDriver.StopLogging", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/vnd.docker.plugins.v1+json")
+		fmt.Fprintln(w, "{}")
+	})
+
+	u, _ := url.Parse(server.URL)
+	client, err := plugins.NewClient("tcp://"+u.Host, &tlsconfig.Options{InsecureSkipVerify: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	l := newPluginLogger("test", "abc", &logDriverProxy{client})
+
+	for i := 0; i < 10; i++ {
+		if err := l.Log(&Message{Line: []byte(strconv.Itoa(i)), Source: "stdout"}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	close(release)
+	if err := l.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(logged) > 2 {
+		t.Fatalf("expected the queued messages to be sent in a batch, got %d requests", len(logged))
+	}
+	var lines []string
+	for _, req := range logged {
+		for _, m := range req.Messages {
+			lines = append(lines, string(m.Line))
+		}
+	}
+	if strings.Join(lines, ",") != "0,1,2,3,4,5,6,7,8,9" {
+		t.Fatalf("expected the messages to be sent in order, got %v", lines)
+	}
+}
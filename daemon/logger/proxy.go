@@ -0,0 +1,121 @@
+// generated code - DO NOT EDIT
+
+package logger
+
+import "errors"
+
+type client interface {
+	Call(string, interface{}, interface{}) error
+}
+
+type logDriverProxy struct {
+	client
+}
+
+type logDriverProxyStartLoggingRequest struct {
+	ID   string
+	Info pluginInfo
+}
+
+type logDriverProxyStartLoggingResponse struct {
+	Err string
+}
+
+func (pp *logDriverProxy) StartLogging(id string, info pluginInfo) (err error) {
+	var (
+		req logDriverProxyStartLoggingRequest
+		ret logDriverProxyStartLoggingResponse
+	)
+
+	req.ID = id
+	req.Info = info
+	if err = pp.Call("LogDriver.StartLogging", req, &ret); err != nil {
+		return
+	}
+
+	if ret.Err != "" {
+		err = errors.New(ret.Err)
+	}
+
+	return
+}
+
+type logDriverProxyLogRequest struct {
+	ID       string
+	Messages []pluginMessage
+}
+
+type logDriverProxyLogResponse struct {
+	Err string
+}
+
+func (pp *logDriverProxy) Log(id string, messages []pluginMessage) (err error) {
+	var (
+		req logDriverProxyLogRequest
+		ret logDriverProxyLogResponse
+	)
+
+	req.ID = id
+	req.Messages = messages
+	if err = pp.Call("LogDriver.Log", req, &ret); err != nil {
+		return
+	}
+
+	if ret.Err != "" {
+		err = errors.New(ret.Err)
+	}
+
+	return
+}
+
+type logDriverProxyStopLoggingRequest struct {
+	ID string
+}
+
+type logDriverProxyStopLoggingResponse struct {
+	Err string
+}
+
+func (pp *logDriverProxy) StopLogging(id string) (err error) {
+	var (
+		req logDriverProxyStopLoggingRequest
+		ret logDriverProxyStopLoggingResponse
+	)
+
+	req.ID = id
+	if err = pp.Call("LogDriver.StopLogging", req, &ret); err != nil {
+		return
+	}
+
+	if ret.Err != "" {
+		err = errors.New(ret.Err)
+	}
+
+	return
+}
+
+type logDriverProxyValidateLogOptsRequest struct {
+	Config map[string]string
+}
+
+type logDriverProxyValidateLogOptsResponse struct {
+	Err string
+}
+
+func (pp *logDriverProxy) ValidateLogOpts(config map[string]string) (err error) {
+	var (
+		req logDriverProxyValidateLogOptsRequest
+		ret logDriverProxyValidateLogOptsResponse
+	)
+
+	req.Config = config
+	if err = pp.Call("LogDriver.ValidateLogOpts", req, &ret); err != nil {
+		return
+	}
+
+	if ret.Err != "" {
+		err = errors.New(ret.Err)
+	}
+
+	return
+}
@@ -0,0 +1,131 @@
+package logger
+
+import (
+	"expvar"
+	"fmt"
+	"regexp"
+	"strings"
+	"sync"
+)
+
+// RedactedText replaces the parts of a log line matched by a redaction
+// rule.
+const RedactedText = "[REDACTED]"
+
+var redactedText = []byte(RedactedText)
+
+var (
+	validRuleName = regexp.MustCompile(`^[a-zA-Z0-9][a-zA-Z0-9_.-]*$`)
+
+	redactionVarsMu sync.Mutex
+	// redactionVars publishes the number of redactions made by each rule
+	// through the daemon's /debug/vars endpoint under "logredactions".
+	redactionVars = expvar.NewMap("logredactions")
+)
+
+type redactRule struct {
+	name    string
+	pattern *regexp.Regexp
+	count   *expvar.Int
+}
+
+// Redactor masks sensitive data in log lines before they are handed to a
+// log driver. Its rules can be replaced while it is in use.
+type Redactor struct {
+	mu    sync.RWMutex
+	rules []redactRule
+}
+
+// NewRedactor creates a Redactor from a list of "name=regexp" rules.
+func NewRedactor(rules []string) (*Redactor, error) {
+	r := &Redactor{}
+	if err := r.SetRules(rules); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// ValidateRedactRule validates a "name=regexp" redaction rule.
+func ValidateRedactRule(val string) (string, error) {
+	if _, err := parseRedactRule(val); err != nil {
+		return "", err
+	}
+	return val, nil
+}
+
+func parseRedactRule(val string) (redactRule, error) {
+	parts := strings.SplitN(val, "=", 2)
+	if len(parts) != 2 || !validRuleName.MatchString(parts[0]) || parts[1] == "" {
+		return redactRule{}, fmt.Errorf("invalid log redaction rule %q: expected name=regexp", val)
+	}
+	pattern, err := regexp.Compile(parts[1])
+	if err != nil {
+		return redactRule{}, fmt.Errorf("invalid log redaction rule %q: %v", val, err)
+	}
+	if pattern.MatchString("") {
+		return redactRule{}, fmt.Errorf("invalid log redaction rule %q: pattern matches empty text", val)
+	}
+	return redactRule{name: parts[0], pattern: pattern}, nil
+}
+
+// SetRules replaces the rules of the redactor with a list of
+// "name=regexp" rules. The rules are left untouched if any of them is
+// invalid.
+func (r *Redactor) SetRules(rules []string) error {
+	parsed := make([]redactRule, 0, len(rules))
+	names := make(map[string]struct{}, len(rules))
+	for _, val := range rules {
+		rule, err := parseRedactRule(val)
+		if err != nil {
+			return err
+		}
+		if _, exists := names[rule.name]; exists {
+			return fmt.Errorf("duplicate log redaction rule name %q", rule.name)
+		}
+		names[rule.name] = struct{}{}
+		rule.count = redactionCounter(rule.name)
+		parsed = append(parsed, rule)
+	}
+
+	r.mu.Lock()
+	r.rules = parsed
+	r.mu.Unlock()
+	return nil
+}
+
+// redactionCounter returns the published counter of the named rule,
+// keeping counts across rule reloads.
+func redactionCounter(name string) *expvar.Int {
+	redactionVarsMu.Lock()
+	defer redactionVarsMu.Unlock()
+
+	if v, ok := redactionVars.Get(name).(*expvar.Int); ok {
+		return v
+	}
+	v := new(expvar.Int)
+	redactionVars.Set(name, v)
+	return v
+}
+
+// Redact returns line with every match of the rules replaced with
+// RedactedText. line is returned as is when nothing matches.
+func (r *Redactor) Redact(line []byte) []byte {
+	if r == nil {
+		return line
+	}
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	for _, rule := range r.rules {
+		var n int64
+		redacted := rule.pattern.ReplaceAllFunc(line, func([]byte) []byte {
+			n++
+			return redactedText
+		})
+		if n > 0 {
+			rule.count.Add(n)
+			line = redacted
+		}
+	}
+	return line
+}
@@ -0,0 +1,97 @@
+package logger
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"testing"
+)
+
+func TestRedactorRules(t *testing.T) {
+	invalid := []string{
+		"",
+		"nameonly",
+		"=abc",
+		"name=",
+		"bad name=abc",
+		"name=[",
+		"empty=a*",
+	}
+	for _, rule := range invalid {
+		if _, err := ValidateRedactRule(rule); err == nil {
+			t.Fatalf("expected rule %q to be rejected", rule)
+		}
+	}
+	if _, err := NewRedactor([]string{"token=abc", "token=def"}); err == nil {
+		t.Fatal("expected duplicate rule names to be rejected")
+	}
+	if _, err := ValidateRedactRule(`query=key=\w+`); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestRedactorRedact(t *testing.T) {
+	r, err := NewRedactor([]string{
+		`test-card=\b\d{4}[ -]?\d{4}[ -]?\d{4}[ -]?\d{4}\b`,
+		`test-bearer=(?i)bearer [a-z0-9._-]+`,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	line := []byte("paid with 4111 1111 1111 1111 and 5500-0000-0000-0004 using Bearer abc.def")
+	got := string(r.Redact(line))
+	expected := "paid with [REDACTED] and [REDACTED] using [REDACTED]"
+	if got != expected {
+		t.Fatalf("expected %q, got %q", expected, got)
+	}
+	if n := redactionCounter("test-card").String(); n != "2" {
+		t.Fatalf("expected 2 card redactions, got %s", n)
+	}
+	if n := redactionCounter("test-bearer").String(); n != "1" {
+		t.Fatalf("expected 1 bearer redaction, got %s", n)
+	}
+
+	clean := []byte("nothing to see here")
+	if got := r.Redact(clean); !bytes.Equal(got, clean) {
+		t.Fatalf("expected line to be unchanged, got %q", got)
+	}
+
+	// replacing the rules keeps the counters
+	if err := r.SetRules([]string{`test-card=\d{16}`}); err != nil {
+		t.Fatal(err)
+	}
+	if got := string(r.Redact([]byte("4111111111111111 Bearer abc"))); got != "[REDACTED] Bearer abc" {
+		t.Fatalf("unexpected redacted line %q", got)
+	}
+	if n := redactionCounter("test-card").String(); n != "3" {
+		t.Fatalf("expected 3 card redactions, got %s", n)
+	}
+
+	var nilRedactor *Redactor
+	if got := nilRedactor.Redact(clean); !bytes.Equal(got, clean) {
+		t.Fatalf("expected nil redactor to leave line unchanged, got %q", got)
+	}
+}
+
+func TestCopierRedact(t *testing.T) {
+	r, err := NewRedactor([]string{`test-password=password=\S+`})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	stdout := bytes.NewBufferString("login user=root password=hunter2\n")
+	var jsonBuf bytes.Buffer
+	c := NewCopier(map[string]io.Reader{"stdout": stdout}, &TestLoggerJSON{Encoder: json.NewEncoder(&jsonBuf)})
+	c.SetRedactor(r)
+	c.Run()
+	c.Wait()
+
+	var msg Message
+	if err := json.NewDecoder(&jsonBuf).Decode(&msg); err != nil {
+		t.Fatal(err)
+	}
+	if string(msg.Line) != "login user=root [REDACTED]" {
+		t.Fatalf("expected redacted line, got %q", msg.Line)
+	}
+}
@@ -0,0 +1,256 @@
+package logger
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+)
+
+// TeeSeparator separates the names of the drivers of a composite log driver,
+// like "journald+json-file", which sends the messages of a container to all
+// of its drivers.
+const TeeSeparator = "+"
+
+// TeeOptSeparator separates the name of a driver from the name of an option
+// of a composite log driver which only goes to that driver, like
+// "journald:tag".
+const TeeOptSeparator = ":"
+
+// DriverNames returns the names of the drivers of the log driver name: the
+// drivers of a composite driver, or name itself.
+func DriverNames(name string) []string {
+	return strings.Split(name, TeeSeparator)
+}
+
+// Tee is the logger of a composite log driver. It sends the messages to the
+// loggers of its drivers. If one of them supports reading, the logs are read
+// from the first one which does.
+type Tee struct {
+	name    string
+	loggers []Logger
+}
+
+// readingTee is a Tee reading the logs from one of its loggers.
+type readingTee struct {
+	*Tee
+	reader LogReader
+}
+
+// Loggers returns the loggers of the drivers of the tee, in order.
+func (t *Tee) Loggers() []Logger {
+	return t.loggers
+}
+
+// Log sends msg to all the loggers of the tee, even if some of them fail.
+func (t *Tee) Log(msg *Message) error {
+	var errs []string
+	for _, l := range t.loggers {
+		// each logger gets its own copy, as some keep it to send it later
+		m := *msg
+		if err := l.Log(&m); err != nil {
+			errs = append(errs, fmt.Sprintf("%s: %v", l.Name(), err))
+		}
+	}
+	if len(errs) > 0 {
+		return fmt.Errorf("%s", strings.Join(errs, "; "))
+	}
+	return nil
+}
+
+// Name returns the name of the composite driver.
+func (t *Tee) Name() string {
+	return t.name
+}
+
+// Close closes all the loggers of the tee.
+func (t *Tee) Close() error {
+	var errs []string
+	for _, l := range t.loggers {
+		if err := l.Close(); err != nil {
+			errs = append(errs, fmt.Sprintf("%s: %v", l.Name(), err))
+		}
+	}
+	if len(errs) > 0 {
+		return fmt.Errorf("%s", strings.Join(errs, "; "))
+	}
+	return nil
+}
+
+// ReadLogs reads the logs from the first logger of the tee supporting it.
+func (t *readingTee) ReadLogs(config ReadConfig) *LogWatcher {
+	return t.reader.ReadLogs(config)
+}
+
+// Drivers returns the loggers of the drivers of l: the loggers of a tee, or
+// l itself.
+func Drivers(l Logger) []Logger {
+	switch t := l.(type) {
+	case *Tee:
+		return t.Loggers()
+	case *readingTee:
+		return t.Loggers()
+	}
+	return []Logger{l}
+}
+
+// teeDriver is a driver of a composite driver.
+type teeDriver struct {
+	name      string
+	creator   Creator
+	validator LogOptValidator
+}
+
+// teeDrivers checks the names of the drivers of a composite driver, and
+// returns the drivers.
+func teeDrivers(name string) ([]teeDriver, error) {
+	names := DriverNames(name)
+	drivers := make([]teeDriver, len(names))
+	seen := make(map[string]bool, len(names))
+	for i, n := range names {
+		if n == "" || n == "none" {
+			return nil, fmt.Errorf("logger: invalid log driver '%s': '%s' cannot be combined with other drivers", name, n)
+		}
+		if seen[n] {
+			return nil, fmt.Errorf("logger: invalid log driver '%s': '%s' is repeated", name, n)
+		}
+		seen[n] = true
+		c, validator, err := factory.driver(n)
+		if err != nil {
+			return nil, err
+		}
+		drivers[i] = teeDriver{name: n, creator: c, validator: validator}
+	}
+	return drivers, nil
+}
+
+// teeConfigs shares out the options of a composite driver between its
+// drivers. The options named after one of the drivers, like gelf-address or
+// fluentd-tls-cert, go to that driver only, even if it only accepts them
+// along with its other options. The options scoped to one of the drivers,
+// like journald:tag, go to that driver only, under their name without the
+// scope, and take precedence over the same option without a scope. The
+// others, like tag, go to each driver accepting them, and must be accepted
+// by at least one.
+func teeConfigs(name string, drivers []teeDriver, cfg map[string]string) ([]map[string]string, error) {
+	configs := make([]map[string]string, len(drivers))
+	for i := range drivers {
+		configs[i] = make(map[string]string)
+	}
+	scoped := make(map[string]string)
+	for k, v := range cfg {
+		if strings.Contains(k, TeeOptSeparator) {
+			scoped[k] = v
+			continue
+		}
+		if i := teeOwner(drivers, k); i >= 0 {
+			configs[i][k] = v
+			continue
+		}
+		var errs []string
+		for i, d := range drivers {
+			if d.validator == nil {
+				configs[i][k] = v
+				continue
+			}
+			if err := d.validator(map[string]string{k: v}); err != nil {
+				errs = append(errs, err.Error())
+				continue
+			}
+			configs[i][k] = v
+		}
+		if len(errs) == len(drivers) {
+			return nil, fmt.Errorf("invalid log opt '%s' for log driver %s: %s", k, name, strings.Join(errs, "; "))
+		}
+	}
+	for k, v := range scoped {
+		parts := strings.SplitN(k, TeeOptSeparator, 2)
+		i := teeIndex(drivers, parts[0])
+		if i < 0 {
+			return nil, fmt.Errorf("invalid log opt '%s' for log driver %s: %s is not one of its drivers", k, name, parts[0])
+		}
+		configs[i][parts[1]] = v
+	}
+	return configs, nil
+}
+
+// teeIndex returns the index of the driver name, or -1 if there is none.
+func teeIndex(drivers []teeDriver, name string) int {
+	for i, d := range drivers {
+		if d.name == name {
+			return i
+		}
+	}
+	return -1
+}
+
+// teeOwner returns the index of the driver the option key is named after,
+// or -1 if there is none.
+func teeOwner(drivers []teeDriver, key string) int {
+	for i, d := range drivers {
+		if strings.HasPrefix(key, d.name+"-") {
+			return i
+		}
+	}
+	return -1
+}
+
+// validateTeeOpts checks that each option of a composite driver goes to at
+// least one of its drivers, and that each driver accepts its share of the
+// options.
+func validateTeeOpts(name string, cfg map[string]string) error {
+	drivers, err := teeDrivers(name)
+	if err != nil {
+		return err
+	}
+	configs, err := teeConfigs(name, drivers, cfg)
+	if err != nil {
+		return err
+	}
+	for i, d := range drivers {
+		if d.validator != nil {
+			if err := d.validator(configs[i]); err != nil {
+				return fmt.Errorf("invalid log opts for the %s driver of log driver %s: %v", d.name, name, err)
+			}
+		}
+	}
+	return nil
+}
+
+// newTee returns the Creator of the composite driver name, which creates the
+// loggers of its drivers with their share of the options.
+func newTee(name string) (Creator, error) {
+	drivers, err := teeDrivers(name)
+	if err != nil {
+		return nil, err
+	}
+
+	return func(ctx Context) (Logger, error) {
+		configs, err := teeConfigs(name, drivers, ctx.Config)
+		if err != nil {
+			return nil, err
+		}
+		t := &Tee{name: name}
+		var reader LogReader
+		for i, d := range drivers {
+			driverCtx := ctx
+			driverCtx.Config = configs[i]
+			if ctx.SpoolPath != "" {
+				// the same spool as when the driver is used alone
+				driverCtx.SpoolPath = filepath.Join(filepath.Dir(ctx.SpoolPath), fmt.Sprintf("%s-%s.spool", ctx.ContainerID, d.name))
+			}
+			l, err := d.creator(driverCtx)
+			if err != nil {
+				t.Close()
+				return nil, fmt.Errorf("%s: %v", d.name, err)
+			}
+			t.loggers = append(t.loggers, l)
+			if r, ok := l.(LogReader); ok && reader == nil {
+				reader = r
+			}
+		}
+		if reader != nil {
+			return &readingTee{Tee: t, reader: reader}, nil
+		}
+		return t, nil
+	}, nil
+}
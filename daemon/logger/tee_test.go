@@ -0,0 +1,237 @@
+package logger
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+type teeTestLogger struct {
+	name     string
+	config   map[string]string
+	spool    string
+	messages []string
+	closed   bool
+}
+
+func (l *teeTestLogger) Log(msg *Message) error {
+	l.messages = append(l.messages, string(msg.Line))
+	return nil
+}
+
+func (l *teeTestLogger) Name() string { return l.name }
+
+func (l *teeTestLogger) Close() error {
+	l.closed = true
+	return nil
+}
+
+type teeTestReader struct {
+	*teeTestLogger
+}
+
+func (r *teeTestReader) ReadLogs(ReadConfig) *LogWatcher {
+	return NewLogWatcher()
+}
+
+// registerTeeTestDriver registers a driver accepting the options of the
+// given keys.
+func registerTeeTestDriver(t *testing.T, name string, reader bool, keys ...string) {
+	if factory.driverRegistered(name) {
+		return
+	}
+	err := RegisterLogDriver(name, func(ctx Context) (Logger, error) {
+		l := &teeTestLogger{name: name, config: ctx.Config, spool: ctx.SpoolPath}
+		if reader {
+			return &teeTestReader{l}, nil
+		}
+		return l, nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = RegisterLogOptValidator(name, func(cfg map[string]string) error {
+		for k := range cfg {
+			found := false
+			for _, key := range keys {
+				found = found || k == key
+			}
+			if !found {
+				return fmt.Errorf("unknown log opt '%s' for %s log driver", k, name)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestValidateTeeOpts(t *testing.T) {
+	registerTeeTestDriver(t, "tee-a", false, "tag", "a-opt")
+	registerTeeTestDriver(t, "tee-b", true, "tag", "b-opt")
+
+	if err := ValidateLogOpts("tee-a+tee-b", map[string]string{"tag": "web", "a-opt": "1", "b-opt": "2"}); err != nil {
+		t.Fatal(err)
+	}
+	for _, tc := range []struct {
+		name string
+		cfg  map[string]string
+	}{
+		{"tee-a+tee-b", map[string]string{"c-opt": "3"}},
+		{"tee-a+none", nil},
+		{"tee-a+tee-a", nil},
+		{"tee-a+", nil},
+		{"tee-a+unknown", nil},
+	} {
+		if err := ValidateLogOpts(tc.name, tc.cfg); err == nil {
+			t.Fatalf("expected %s with %v to be rejected", tc.name, tc.cfg)
+		}
+	}
+}
+
+// registerTeeTLSTestDriver registers a driver accepting its TLS option only
+// along with its address, like the gelf driver.
+func registerTeeTLSTestDriver(t *testing.T, name string) {
+	if factory.driverRegistered(name) {
+		return
+	}
+	err := RegisterLogDriver(name, func(ctx Context) (Logger, error) {
+		return &teeTestLogger{name: name, config: ctx.Config, spool: ctx.SpoolPath}, nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = RegisterLogOptValidator(name, func(cfg map[string]string) error {
+		for k := range cfg {
+			switch k {
+			case "tag", name + "-address", name + "-tls-cert":
+			default:
+				return fmt.Errorf("unknown log opt '%s' for %s log driver", k, name)
+			}
+		}
+		if _, ok := cfg[name+"-tls-cert"]; ok && cfg[name+"-address"] == "" {
+			return fmt.Errorf("%s: TLS options require a %s-address", name, name)
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestTeeRoutesOptionsToTheirDriver(t *testing.T) {
+	registerTeeTestDriver(t, "tee-a", false, "tag", "a-opt")
+	registerTeeTLSTestDriver(t, "tee-tls")
+
+	cfg := map[string]string{"tag": "web", "a-opt": "1", "tee-tls-address": "tcp://host:12201", "tee-tls-tls-cert": "/cert.pem"}
+	if err := ValidateLogOpts("tee-a+tee-tls", cfg); err != nil {
+		t.Fatal(err)
+	}
+	creator, err := GetLogDriver("tee-a+tee-tls")
+	if err != nil {
+		t.Fatal(err)
+	}
+	l, err := creator(Context{ContainerID: "abc", Config: cfg})
+	if err != nil {
+		t.Fatal(err)
+	}
+	drivers := Drivers(l)
+	if expected := map[string]string{"tag": "web", "tee-tls-address": "tcp://host:12201", "tee-tls-tls-cert": "/cert.pem"}; !reflect.DeepEqual(drivers[1].(*teeTestLogger).config, expected) {
+		t.Fatalf("expected the options of tee-tls to be %v, got %v", expected, drivers[1].(*teeTestLogger).config)
+	}
+
+	// the options of a driver are validated together
+	err = ValidateLogOpts("tee-a+tee-tls", map[string]string{"tee-tls-tls-cert": "/cert.pem"})
+	if err == nil || !strings.Contains(err.Error(), "TLS options require a tee-tls-address") {
+		t.Fatalf("expected the TLS option without an address to be rejected, got %v", err)
+	}
+}
+
+func TestTeeScopedOptions(t *testing.T) {
+	registerTeeTestDriver(t, "tee-a", false, "tag", "a-opt")
+	registerTeeTestDriver(t, "tee-b", true, "tag", "b-opt")
+
+	cfg := map[string]string{"tag": "web", "tee-a:tag": "a-web", "tee-b:b-opt": "2"}
+	if err := ValidateLogOpts("tee-a+tee-b", cfg); err != nil {
+		t.Fatal(err)
+	}
+	drivers, err := teeDrivers("tee-a+tee-b")
+	if err != nil {
+		t.Fatal(err)
+	}
+	configs, err := teeConfigs("tee-a+tee-b", drivers, cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := []map[string]string{{"tag": "a-web"}, {"tag": "web", "b-opt": "2"}}
+	if !reflect.DeepEqual(configs, expected) {
+		t.Fatalf("expected the options %v, got %v", expected, configs)
+	}
+
+	for _, cfg := range []map[string]string{
+		{"tee-a:b-opt": "2"},
+		{"tee-c:tag": "web"},
+	} {
+		if err := ValidateLogOpts("tee-a+tee-b", cfg); err == nil {
+			t.Fatalf("expected %v to be rejected", cfg)
+		}
+	}
+}
+
+func TestTee(t *testing.T) {
+	registerTeeTestDriver(t, "tee-a", false, "tag", "a-opt")
+	registerTeeTestDriver(t, "tee-b", true, "tag", "b-opt")
+
+	creator, err := GetLogDriver("tee-a+tee-b")
+	if err != nil {
+		t.Fatal(err)
+	}
+	l, err := creator(Context{
+		ContainerID: "abc",
+		Config:      map[string]string{"tag": "web", "a-opt": "1", "b-opt": "2"},
+		SpoolPath:   "/containers/abc/abc-tee-a+tee-b.spool",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if l.Name() != "tee-a+tee-b" {
+		t.Fatalf("unexpected name %s", l.Name())
+	}
+	if _, ok := l.(LogReader); !ok {
+		t.Fatal("expected the tee to read the logs of tee-b")
+	}
+
+	drivers := Drivers(l)
+	if len(drivers) != 2 {
+		t.Fatalf("expected 2 drivers, got %d", len(drivers))
+	}
+	a := drivers[0].(*teeTestLogger)
+	b := drivers[1].(*teeTestReader)
+
+	// each driver gets its share of the options, and its own spool
+	if expected := map[string]string{"tag": "web", "a-opt": "1"}; !reflect.DeepEqual(a.config, expected) {
+		t.Fatalf("expected the options of tee-a to be %v, got %v", expected, a.config)
+	}
+	if expected := map[string]string{"tag": "web", "b-opt": "2"}; !reflect.DeepEqual(b.config, expected) {
+		t.Fatalf("expected the options of tee-b to be %v, got %v", expected, b.config)
+	}
+	if a.spool != "/containers/abc/abc-tee-a.spool" || b.spool != "/containers/abc/abc-tee-b.spool" {
+		t.Fatalf("unexpected spools %s and %s", a.spool, b.spool)
+	}
+
+	if err := l.Log(&Message{Line: []byte("hello"), Source: "stdout"}); err != nil {
+		t.Fatal(err)
+	}
+	if len(a.messages) != 1 || len(b.messages) != 1 || a.messages[0] != "hello" || b.messages[0] != "hello" {
+		t.Fatalf("expected both drivers to log the message, got %v and %v", a.messages, b.messages)
+	}
+
+	if err := l.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if !a.closed || !b.closed {
+		t.Fatal("expected both drivers to be closed")
+	}
+}
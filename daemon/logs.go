@@ -3,18 +3,22 @@ package daemon
 import (
 	"fmt"
 	"io"
+	"sort"
 	"strconv"
 	"time"
 
 	"golang.org/x/net/context"
 
 	"github.com/Sirupsen/logrus"
+	"github.com/docker/docker/api/server/httputils"
 	"github.com/docker/docker/api/types/backend"
 	"github.com/docker/docker/container"
 	"github.com/docker/docker/daemon/logger"
 	"github.com/docker/docker/daemon/logger/jsonfilelog"
+	"github.com/docker/docker/errors"
 	"github.com/docker/docker/pkg/ioutils"
 	"github.com/docker/docker/pkg/stdcopy"
+	"github.com/docker/docker/reference"
 	containertypes "github.com/docker/engine-api/types/container"
 	timetypes "github.com/docker/engine-api/types/time"
 )
@@ -48,18 +52,36 @@ func (daemon *Daemon) ContainerLogs(ctx context.Context, containerName string, c
 
 	logrus.Debug("logs: begin stream")
 
-	var since time.Time
+	var since, until time.Time
+	var sinceCursor string
+	reference := time.Now()
 	if config.Since != "" {
-		s, n, err := timetypes.ParseTimestamps(config.Since, 0)
-		if err != nil {
-			return err
+		t, err := timetypes.ParseTime(config.Since, reference)
+		if err == nil {
+			since = t
+		} else if cursorReader, ok := logReader.(logger.CursorReader); ok && cursorReader.IsCursor(config.Since) {
+			sinceCursor = config.Since
+		} else {
+			return errors.NewBadRequestError(err)
+		}
+	}
+	if config.Cursor != "" {
+		if sinceCursor, err = verifyLogCursor(logReader, config); err != nil {
+			return errors.NewBadRequestError(err)
+		}
+	}
+	config.LastCursor = sinceCursor
+	if config.Until != "" {
+		if until, err = timetypes.ParseTime(config.Until, reference); err != nil {
+			return errors.NewBadRequestError(err)
 		}
-		since = time.Unix(s, n)
 	}
 	readConfig := logger.ReadConfig{
-		Since:  since,
-		Tail:   tailLines,
-		Follow: follow,
+		Since:       since,
+		SinceCursor: sinceCursor,
+		Until:       until,
+		Tail:        tailLines,
+		Follow:      follow,
 	}
 	logs := logReader.ReadLogs(readConfig)
 
@@ -70,6 +92,7 @@ func (daemon *Daemon) ContainerLogs(ctx context.Context, containerName string, c
 
 	var outStream io.Writer = wf
 	errStream := outStream
+	enc := httputils.NewMsgpackEncoder(wf)
 	if !container.Config.Tty {
 		errStream = stdcopy.NewStdWriter(outStream, stdcopy.Stderr)
 		outStream = stdcopy.NewStdWriter(outStream, stdcopy.Stdout)
@@ -96,6 +119,28 @@ func (daemon *Daemon) ContainerLogs(ctx context.Context, containerName string, c
 				}
 				return nil
 			}
+			if !until.IsZero() && msg.Timestamp.After(until) {
+				// Stop the log readers which do not stop at until
+				// themselves, and drop the messages they sent since.
+				logs.Close()
+				continue
+			}
+			if msg.Cursor != "" {
+				config.LastCursor = msg.Cursor
+			}
+			if config.Msgpack {
+				if (msg.Source == "stdout" && config.ShowStdout) || (msg.Source == "stderr" && config.ShowStderr) {
+					m := &backend.LogMessage{Stream: msg.Source, Time: msg.Timestamp, Line: msg.Line, Cursor: msg.Cursor}
+					if config.Details {
+						m.Attrs = msg.Attrs
+					}
+					if err := enc.Encode(m); err != nil {
+						logs.Close()
+						return err
+					}
+				}
+				continue
+			}
 			logLine := msg.Line
 			if config.Details {
 				logLine = append([]byte(msg.Attrs.String()+" "), logLine...)
@@ -113,11 +158,28 @@ func (daemon *Daemon) ContainerLogs(ctx context.Context, containerName string, c
 	}
 }
 
+// verifyLogCursor returns the cursor of the logs config, which the logs are
+// read after, once checked that the log reader has cursors and that it is
+// one of them.
+func verifyLogCursor(logReader logger.LogReader, config *backend.ContainerLogsConfig) (string, error) {
+	cursorReader, ok := logReader.(logger.CursorReader)
+	if !ok {
+		return "", fmt.Errorf("The log driver of the container has no cursors")
+	}
+	if config.Since != "" {
+		return "", fmt.Errorf("Cannot read logs both since %s and after a cursor", config.Since)
+	}
+	if !cursorReader.IsCursor(config.Cursor) {
+		return "", fmt.Errorf("Invalid cursor %q for the log driver of the container", config.Cursor)
+	}
+	return config.Cursor, nil
+}
+
 func (daemon *Daemon) getLogger(container *container.Container) (logger.Logger, error) {
 	if container.LogDriver != nil && container.IsRunning() {
 		return container.LogDriver, nil
 	}
-	return container.StartLogger(container.HostConfig.LogConfig)
+	return container.StartLogger(container.HostConfig.LogConfig, daemon.loggerContext(container))
 }
 
 // StartLogging initializes and starts the container logging stream.
@@ -126,24 +188,141 @@ func (daemon *Daemon) StartLogging(container *container.Container) error {
 		return nil // do not start logging routines
 	}
 
-	l, err := container.StartLogger(container.HostConfig.LogConfig)
+	l, err := container.StartLogger(container.HostConfig.LogConfig, daemon.loggerContext(container))
 	if err != nil {
 		return fmt.Errorf("Failed to initialize logging driver: %v", err)
 	}
 
 	copier := logger.NewCopier(map[string]io.Reader{"stdout": container.StdoutPipe(), "stderr": container.StderrPipe()}, l)
+	copier.SetRedactor(daemon.logRedactor)
 	container.LogCopier = copier
 	copier.Run()
 	container.LogDriver = l
 
 	// set LogPath field only for json-file logdriver
-	if jl, ok := l.(*jsonfilelog.JSONFileLogger); ok {
-		container.LogPath = jl.LogPath()
+	for _, driver := range logger.Drivers(l) {
+		if jl, ok := driver.(*jsonfilelog.JSONFileLogger); ok {
+			container.LogPath = jl.LogPath()
+		}
+	}
+
+	return nil
+}
+
+// loggerContext returns the information the daemon provides to the log
+// driver of a container, on top of what the container itself holds.
+func (daemon *Daemon) loggerContext(container *container.Container) logger.Context {
+	registry, dgst := daemon.imageOrigin(container)
+	return logger.Context{
+		ContainerImageDigest:   dgst,
+		ContainerImageRegistry: registry,
+		Retention:              daemon.logRetention(container.HostConfig.LogConfig),
+		LogEvent: func(action string, attributes map[string]string) {
+			daemon.LogContainerEventWithAttributes(container, action, attributes)
+		},
+	}
+}
+
+// logRetention returns the retention policy of the logs of a container: the
+// policy of the daemon, tightened by the log quota of the container.
+func (daemon *Daemon) logRetention(cfg containertypes.LogConfig) logger.Retention {
+	// The policy is validated when the configuration is loaded.
+	r, _ := daemon.configStore.LogConfig.retention()
+	if cfg.MaxSize > 0 && (r.MaxSize == 0 || cfg.MaxSize < r.MaxSize) {
+		r.MaxSize = cfg.MaxSize
+	}
+	if cfg.MaxFiles > 0 && (r.MaxFiles == 0 || cfg.MaxFiles < r.MaxFiles) {
+		r.MaxFiles = cfg.MaxFiles
+	}
+	return r
+}
+
+// verifyLogQuota returns an error if the log quota of a container is
+// invalid, is set for a log driver which does not enforce it, or exceeds
+// the retention policy of the daemon, so that containers do not start with
+// more logs than the daemon allows.
+func (daemon *Daemon) verifyLogQuota(cfg containertypes.LogConfig) error {
+	if cfg.MaxSize < 0 || cfg.MaxFiles < 0 {
+		return fmt.Errorf("Invalid log quota: the maximum size and number of files of the logs must not be negative")
+	}
+	if cfg.MaxSize > 0 || cfg.MaxFiles > 0 {
+		driver := cfg.Type
+		if driver == "" {
+			driver = daemon.defaultLogConfig.Type
+		}
+		if driver != "none" && !logger.EnforcesRetention(driver) {
+			return fmt.Errorf("Invalid log quota: the log driver %s does not enforce the maximum size and number of files of the logs", driver)
+		}
 	}
+	if daemon.configStore == nil {
+		return nil
+	}
+	r, _ := daemon.configStore.LogConfig.retention()
+	if r.MaxSize > 0 && cfg.MaxSize > r.MaxSize {
+		return fmt.Errorf("The log quota of %d bytes exceeds the log-max-size of the daemon, %s", cfg.MaxSize, daemon.configStore.LogConfig.MaxSize)
+	}
+	if r.MaxFiles > 0 && cfg.MaxFiles > r.MaxFiles {
+		return fmt.Errorf("The log quota of %d files exceeds the log-max-files of the daemon, %d", cfg.MaxFiles, r.MaxFiles)
+	}
+	return nil
+}
 
+// verifyLogDriverOpts returns an error if the default options of the log
+// driver of a container, or of one of the drivers of a composite driver,
+// set with --log-driver-opt, are invalid for that driver.
+func (daemon *Daemon) verifyLogDriverOpts(cfg containertypes.LogConfig) error {
+	driver := cfg.Type
+	if driver == "" {
+		driver = daemon.defaultLogConfig.Type
+	}
+	for _, name := range logger.DriverNames(driver) {
+		opts, ok := daemon.logDriverOpts[name]
+		if !ok || name == "none" {
+			continue
+		}
+		if err := logger.ValidateLogOpts(name, opts); err != nil {
+			return fmt.Errorf("Invalid default log driver options of the %s driver: %v", name, err)
+		}
+	}
 	return nil
 }
 
+// imageOrigin returns the registry the image of a container was pulled
+// from, and the digest of the manifest it was pulled with, as recorded by
+// the references of the image matching the image name of the container.
+// The digest the container was created with is preferred; otherwise the
+// lowest of the digests of the image is returned, so that the result does
+// not depend on the order of the references. Empty values are returned for
+// images that were not pulled.
+func (daemon *Daemon) imageOrigin(container *container.Container) (registry, dgst string) {
+	_, named, err := reference.ParseIDOrReference(container.Config.Image)
+	if err != nil || named == nil {
+		return "", ""
+	}
+	var digests []string
+	for _, ref := range daemon.referenceStore.References(container.ImageID) {
+		if ref.Name() != named.Name() {
+			continue
+		}
+		registry = ref.Hostname()
+		if canonical, ok := ref.(reference.Canonical); ok {
+			digests = append(digests, canonical.Digest().String())
+		}
+	}
+	if len(digests) == 0 {
+		return registry, ""
+	}
+	if canonical, ok := named.(reference.Canonical); ok {
+		for _, d := range digests {
+			if d == canonical.Digest().String() {
+				return registry, d
+			}
+		}
+	}
+	sort.Strings(digests)
+	return registry, digests[0]
+}
+
 // mergeLogConfig merges the daemon log config to the container's log config if the container's log driver is not specified.
 func (daemon *Daemon) mergeAndVerifyLogConfig(cfg *containertypes.LogConfig) error {
 	if cfg.Type == "" {
@@ -154,6 +333,28 @@ func (daemon *Daemon) mergeAndVerifyLogConfig(cfg *containertypes.LogConfig) err
 		cfg.Config = make(map[string]string)
 	}
 
+	// the default options of the driver take precedence over the
+	// --log-opt of the default driver
+	for k, v := range daemon.logDriverOpts[cfg.Type] {
+		if _, ok := cfg.Config[k]; !ok {
+			cfg.Config[k] = v
+		}
+	}
+	// the default options of the drivers of a composite driver are scoped
+	// to each driver, unless the container sets them for all of them
+	if names := logger.DriverNames(cfg.Type); len(names) > 1 {
+		for _, name := range names {
+			for k, v := range daemon.logDriverOpts[name] {
+				scoped := name + logger.TeeOptSeparator + k
+				_, set := cfg.Config[k]
+				_, setScoped := cfg.Config[scoped]
+				if !set && !setScoped {
+					cfg.Config[scoped] = v
+				}
+			}
+		}
+	}
+
 	if cfg.Type == daemon.defaultLogConfig.Type {
 		for k, v := range daemon.defaultLogConfig.Config {
 			if _, ok := cfg.Config[k]; !ok {
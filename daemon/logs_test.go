@@ -1,8 +1,19 @@
 package daemon
 
 import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
 	"testing"
 
+	"github.com/docker/docker/api/types/backend"
+	"github.com/docker/docker/container"
+	"github.com/docker/docker/daemon/logger"
+	"github.com/docker/docker/image"
+	"github.com/docker/docker/reference"
+	"github.com/docker/engine-api/types"
 	containertypes "github.com/docker/engine-api/types/container"
 )
 
@@ -13,3 +24,218 @@ func TestMergeAndVerifyLogConfigNilConfig(t *testing.T) {
 		t.Fatal(err)
 	}
 }
+
+func TestMergeAndVerifyLogConfigDriverOpts(t *testing.T) {
+	d := &Daemon{
+		defaultLogConfig: containertypes.LogConfig{Type: "json-file", Config: map[string]string{"max-file": "1", "max-size": "1m"}},
+		logDriverOpts: map[string]map[string]string{
+			"json-file": {"max-size": "10m", "labels": "team"},
+			"syslog":    {"tag": "{{.Name}}"},
+		},
+	}
+
+	cfg := containertypes.LogConfig{Config: map[string]string{"labels": "tier"}}
+	if err := d.mergeAndVerifyLogConfig(&cfg); err != nil {
+		t.Fatal(err)
+	}
+	expected := map[string]string{"max-file": "1", "max-size": "10m", "labels": "tier"}
+	if !reflect.DeepEqual(cfg.Config, expected) {
+		t.Fatalf("expected %v, got %v", expected, cfg.Config)
+	}
+
+	// the --log-opt of the default driver do not apply to other drivers
+	cfg = containertypes.LogConfig{Type: "syslog"}
+	if err := d.mergeAndVerifyLogConfig(&cfg); err != nil {
+		t.Fatal(err)
+	}
+	expected = map[string]string{"tag": "{{.Name}}"}
+	if !reflect.DeepEqual(cfg.Config, expected) {
+		t.Fatalf("expected %v, got %v", expected, cfg.Config)
+	}
+}
+
+func TestMergeAndVerifyLogConfigTeeDriverOpts(t *testing.T) {
+	d := &Daemon{
+		defaultLogConfig: containertypes.LogConfig{Type: "json-file"},
+		logDriverOpts: map[string]map[string]string{
+			"json-file": {"max-size": "10m", "labels": "team"},
+			"syslog":    {"tag": "{{.Name}}"},
+		},
+	}
+
+	// the container options set for all the drivers take precedence over
+	// the default options of each driver
+	cfg := containertypes.LogConfig{Type: "json-file+syslog", Config: map[string]string{"labels": "tier"}}
+	if err := d.mergeAndVerifyLogConfig(&cfg); err != nil {
+		t.Fatal(err)
+	}
+	expected := map[string]string{"labels": "tier", "json-file:max-size": "10m", "syslog:tag": "{{.Name}}"}
+	if !reflect.DeepEqual(cfg.Config, expected) {
+		t.Fatalf("expected %v, got %v", expected, cfg.Config)
+	}
+}
+
+func TestVerifyLogDriverOpts(t *testing.T) {
+	d := &Daemon{
+		defaultLogConfig: containertypes.LogConfig{Type: "json-file"},
+		logDriverOpts: map[string]map[string]string{
+			"json-file": {"max-size": "10m"},
+			"syslog":    {"unknown": "1"},
+		},
+	}
+	if err := d.verifyLogDriverOpts(containertypes.LogConfig{}); err != nil {
+		t.Fatal(err)
+	}
+	for _, driver := range []string{"syslog", "json-file+syslog"} {
+		err := d.verifyLogDriverOpts(containertypes.LogConfig{Type: driver})
+		if err == nil || !strings.Contains(err.Error(), "default log driver options of the syslog driver") {
+			t.Fatalf("expected the default options of syslog to be rejected for %s, got %v", driver, err)
+		}
+	}
+}
+
+func TestLogRetention(t *testing.T) {
+	d := &Daemon{configStore: &Config{}}
+	d.configStore.LogConfig.MaxSize = "10m"
+	d.configStore.LogConfig.MaxFiles = 5
+
+	for _, tc := range []struct {
+		quota    containertypes.LogConfig
+		maxSize  int64
+		maxFiles int
+	}{
+		{containertypes.LogConfig{}, 10 * 1024 * 1024, 5},
+		{containertypes.LogConfig{MaxSize: 1024, MaxFiles: 2}, 1024, 2},
+	} {
+		r := d.logRetention(tc.quota)
+		if r.MaxSize != tc.maxSize || r.MaxFiles != tc.maxFiles {
+			t.Fatalf("expected %d bytes and %d files for %+v, got %+v", tc.maxSize, tc.maxFiles, tc.quota, r)
+		}
+	}
+}
+
+func TestImageOrigin(t *testing.T) {
+	tmp, err := ioutil.TempDir("", "docker-image-origin")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(tmp)
+	store, err := reference.NewReferenceStore(filepath.Join(tmp, "repositories.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	id := image.ID("sha256:" + strings.Repeat("a", 64))
+	digest1 := "sha256:" + strings.Repeat("1", 64)
+	digest2 := "sha256:" + strings.Repeat("2", 64)
+	for _, s := range []string{
+		"registry.example.com/app@" + digest2,
+		"registry.example.com/app@" + digest1,
+		"registry.example.com/other@" + digest1,
+	} {
+		ref, err := reference.ParseNamed(s)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := store.AddDigest(ref.(reference.Canonical), id, false); err != nil {
+			t.Fatal(err)
+		}
+	}
+	d := &Daemon{referenceStore: store}
+
+	for _, tc := range []struct {
+		image, registry, digest string
+	}{
+		{"registry.example.com/app", "registry.example.com", digest1},
+		{"registry.example.com/app@" + digest2, "registry.example.com", digest2},
+		{"app", "", ""},
+	} {
+		c := &container.Container{CommonContainer: container.CommonContainer{
+			Config:  &containertypes.Config{Image: tc.image},
+			ImageID: id,
+		}}
+		registry, digest := d.imageOrigin(c)
+		if registry != tc.registry || digest != tc.digest {
+			t.Fatalf("expected %q and %q for %s, got %q and %q", tc.registry, tc.digest, tc.image, registry, digest)
+		}
+	}
+}
+
+func TestVerifyLogQuota(t *testing.T) {
+	d := &Daemon{configStore: &Config{}}
+	d.configStore.LogConfig.MaxSize = "10m"
+	d.configStore.LogConfig.MaxFiles = 5
+
+	d.defaultLogConfig.Type = "json-file"
+
+	if err := d.verifyLogQuota(containertypes.LogConfig{MaxSize: 1024 * 1024, MaxFiles: 5}); err != nil {
+		t.Fatal(err)
+	}
+	for _, quota := range []containertypes.LogConfig{
+		{Type: "none", MaxSize: 1024},
+		{Type: "syslog"},
+	} {
+		if err := d.verifyLogQuota(quota); err != nil {
+			t.Fatalf("expected the log quota %+v to be allowed, got %v", quota, err)
+		}
+	}
+	for _, quota := range []containertypes.LogConfig{
+		{MaxSize: -1},
+		{MaxFiles: -1},
+		{MaxSize: 20 * 1024 * 1024},
+		{MaxFiles: 6},
+		// the drivers which do not enforce the quota
+		{Type: "syslog", MaxSize: 1024},
+		{Type: "json-file+syslog", MaxFiles: 2},
+	} {
+		if err := d.verifyLogQuota(quota); err == nil {
+			t.Fatalf("expected the log quota %+v to be refused", quota)
+		}
+	}
+
+	// without a ceiling, any quota is allowed
+	d.configStore.LogConfig = LogConfig{}
+	if err := d.verifyLogQuota(containertypes.LogConfig{Type: "json-file", MaxSize: 1 << 40, MaxFiles: 100}); err != nil {
+		t.Fatal(err)
+	}
+}
+
+type fakeLogReader struct{}
+
+func (fakeLogReader) ReadLogs(logger.ReadConfig) *logger.LogWatcher {
+	return logger.NewLogWatcher()
+}
+
+type fakeCursorReader struct {
+	fakeLogReader
+}
+
+func (fakeCursorReader) IsCursor(s string) bool {
+	return strings.HasPrefix(s, "s=")
+}
+
+func TestVerifyLogCursor(t *testing.T) {
+	config := &backend.ContainerLogsConfig{ContainerLogsOptions: types.ContainerLogsOptions{Cursor: "s=1;i=2"}}
+	cursor, err := verifyLogCursor(fakeCursorReader{}, config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cursor != "s=1;i=2" {
+		t.Fatalf("expected the cursor s=1;i=2, got %s", cursor)
+	}
+
+	for _, tc := range []struct {
+		reader logger.LogReader
+		since  string
+		cursor string
+		err    string
+	}{
+		{fakeLogReader{}, "", "s=1;i=2", "has no cursors"},
+		{fakeCursorReader{}, "", "1h", "Invalid cursor"},
+		{fakeCursorReader{}, "1h", "s=1;i=2", "both since 1h and after a cursor"},
+	} {
+		config := &backend.ContainerLogsConfig{ContainerLogsOptions: types.ContainerLogsOptions{Since: tc.since, Cursor: tc.cursor}}
+		if _, err := verifyLogCursor(tc.reader, config); err == nil || !strings.Contains(err.Error(), tc.err) {
+			t.Fatalf("expected an error containing %q, got %v", tc.err, err)
+		}
+	}
+}
@@ -66,6 +66,7 @@ func (daemon *Daemon) StateChanged(id string, e libcontainerd.StateInfo) error {
 			ec := int(e.ExitCode)
 			execConfig.ExitCode = &ec
 			execConfig.Running = false
+			releaseExecCgroup(execConfig)
 			execConfig.Wait()
 			if err := execConfig.CloseStreams(); err != nil {
 				logrus.Errorf("%s: %s", c.ID, err)
@@ -86,7 +87,11 @@ func (daemon *Daemon) StateChanged(id string, e libcontainerd.StateInfo) error {
 			return err
 		}
 		daemon.initHealthMonitor(c)
-		daemon.LogContainerEvent(c, "start")
+		if c.StartTrace != nil {
+			// the process of the container runs
+			c.StartTrace.End("runtime")
+		}
+		daemon.LogContainerEventWithAttributes(c, "start", startTraceAttributes(c.StartTrace))
 	case libcontainerd.StatePause:
 		// Container is already locked in this case
 		c.Paused = true
@@ -119,10 +124,16 @@ func (daemon *Daemon) AttachStreams(id string, iop libcontainerd.IOPipe) error {
 		s = ec.StreamConfig
 	} else {
 		s = c.StreamConfig
+		if c.StartTrace != nil {
+			c.StartTrace.Begin("logger")
+		}
 		if err := daemon.StartLogging(c); err != nil {
 			c.Reset(false)
 			return err
 		}
+		if c.StartTrace != nil {
+			c.StartTrace.End("logger")
+		}
 	}
 
 	if stdin := s.Stdin(); stdin != nil {
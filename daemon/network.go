@@ -51,7 +51,14 @@ func (daemon *Daemon) GetNetworkByID(partialID string) (libnetwork.Network, erro
 		return nil, libnetwork.ErrNoSuchNetwork(partialID)
 	}
 	if len(list) > 1 {
-		return nil, libnetwork.ErrInvalidID(partialID)
+		candidates := make([]string, len(list))
+		for i, nw := range list {
+			candidates[i] = nw.ID()
+		}
+		return nil, errAmbiguousPrefix("network", partialID, candidates)
+	}
+	if err := daemon.checkIDPrefix("network", partialID, list[0].ID()); err != nil {
+		return nil, err
 	}
 	return list[0], nil
 }
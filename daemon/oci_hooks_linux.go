@@ -0,0 +1,182 @@
+package daemon
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/Sirupsen/logrus"
+	"github.com/docker/docker/container"
+	"github.com/opencontainers/specs/specs-go"
+)
+
+// ociHook is the definition of a hook in a JSON file of the hooks directory
+// of the daemon. The hook runs at its stages for the containers matching any
+// of its criteria, or for all the containers when it has none.
+type ociHook struct {
+	// Hook is the absolute path of the executable of the hook.
+	Hook string `json:"hook"`
+	// Arguments are passed to the hook after its path.
+	Arguments []string `json:"arguments,omitempty"`
+	// Stages are the prestart, poststart and poststop stages at which the
+	// hook runs.
+	Stages []string `json:"stages"`
+	// Cmds are regular expressions matched against the command of the
+	// containers.
+	Cmds []string `json:"cmds,omitempty"`
+	// Annotations are regular expressions matched against the key=value
+	// labels of the containers.
+	Annotations []string `json:"annotations,omitempty"`
+	// HasBindMounts matches the containers with bind mounts.
+	HasBindMounts bool `json:"hasbindmounts,omitempty"`
+
+	cmds        []*regexp.Regexp
+	annotations []*regexp.Regexp
+}
+
+// readOCIHooks reads the hooks defined in the JSON files of dir, sorted by
+// file name. The files which are not valid are skipped with a warning.
+func readOCIHooks(dir string) ([]*ociHook, error) {
+	files, err := ioutil.ReadDir(dir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	var hooks []*ociHook
+	for _, f := range files {
+		if f.IsDir() || filepath.Ext(f.Name()) != ".json" {
+			continue
+		}
+		h, err := readOCIHook(filepath.Join(dir, f.Name()))
+		if err != nil {
+			logrus.Warnf("Skipping the OCI hook %s: %v", filepath.Join(dir, f.Name()), err)
+			continue
+		}
+		hooks = append(hooks, h)
+	}
+	return hooks, nil
+}
+
+func readOCIHook(path string) (*ociHook, error) {
+	b, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	h := &ociHook{}
+	if err := json.Unmarshal(b, h); err != nil {
+		return nil, err
+	}
+	if !filepath.IsAbs(h.Hook) {
+		return nil, fmt.Errorf("the path of the hook %q is not absolute", h.Hook)
+	}
+	if _, err := os.Stat(h.Hook); err != nil {
+		return nil, err
+	}
+	if len(h.Stages) == 0 {
+		return nil, fmt.Errorf("the hook has no stages")
+	}
+	for _, stage := range h.Stages {
+		switch stage {
+		case "prestart", "poststart", "poststop":
+		default:
+			return nil, fmt.Errorf("invalid stage %q", stage)
+		}
+	}
+	if h.cmds, err = compileOCIHookPatterns(h.Cmds); err != nil {
+		return nil, err
+	}
+	if h.annotations, err = compileOCIHookPatterns(h.Annotations); err != nil {
+		return nil, err
+	}
+	return h, nil
+}
+
+func compileOCIHookPatterns(patterns []string) ([]*regexp.Regexp, error) {
+	var res []*regexp.Regexp
+	for _, p := range patterns {
+		re, err := regexp.Compile(p)
+		if err != nil {
+			return nil, fmt.Errorf("invalid pattern %q: %v", p, err)
+		}
+		res = append(res, re)
+	}
+	return res, nil
+}
+
+// matches returns whether the hook runs for the container with the spec s.
+func (h *ociHook) matches(s *specs.Spec, c *container.Container) bool {
+	if len(h.cmds) == 0 && len(h.annotations) == 0 && !h.HasBindMounts {
+		return true
+	}
+	if len(s.Process.Args) > 0 {
+		for _, re := range h.cmds {
+			if re.MatchString(s.Process.Args[0]) {
+				return true
+			}
+		}
+	}
+	if len(h.annotations) > 0 {
+		var labels []string
+		for k, v := range c.Config.Labels {
+			labels = append(labels, k+"="+v)
+		}
+		sort.Strings(labels)
+		for _, re := range h.annotations {
+			for _, l := range labels {
+				if re.MatchString(l) {
+					return true
+				}
+			}
+		}
+	}
+	if h.HasBindMounts {
+		for _, m := range s.Mounts {
+			for _, o := range m.Options {
+				if o == "bind" || o == "rbind" {
+					return true
+				}
+			}
+		}
+	}
+	return false
+}
+
+// setOCIHooks appends the hooks of the hooks directory of the daemon which
+// match the container to the hooks of its spec.
+func (daemon *Daemon) setOCIHooks(s *specs.Spec, c *container.Container) error {
+	if daemon.configStore.HooksDir == "" {
+		return nil
+	}
+	hooks, err := readOCIHooks(daemon.configStore.HooksDir)
+	if err != nil {
+		return err
+	}
+	for _, h := range hooks {
+		if !h.matches(s, c) {
+			continue
+		}
+		hook := specs.Hook{
+			Path: h.Hook,
+			Args: append([]string{h.Hook}, h.Arguments...),
+		}
+		logrus.Debugf("Adding the OCI hook %s at the stages %s of container %s", h.Hook, strings.Join(h.Stages, ", "), c.ID)
+		for _, stage := range h.Stages {
+			switch stage {
+			case "prestart":
+				s.Hooks.Prestart = append(s.Hooks.Prestart, hook)
+			case "poststart":
+				s.Hooks.Poststart = append(s.Hooks.Poststart, hook)
+			case "poststop":
+				s.Hooks.Poststop = append(s.Hooks.Poststop, hook)
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,98 @@
+package daemon
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/docker/docker/container"
+	containertypes "github.com/docker/engine-api/types/container"
+	"github.com/opencontainers/specs/specs-go"
+)
+
+func TestSetOCIHooks(t *testing.T) {
+	dir, err := ioutil.TempDir("", "docker-hooks-")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	hook := filepath.Join(dir, "hook")
+	if err := ioutil.WriteFile(hook, []byte("#!/bin/sh\n"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	for name, def := range map[string]string{
+		"00-all.json":      `{"hook": "` + hook + `", "arguments": ["all"], "stages": ["prestart", "poststop"]}`,
+		"10-cmd.json":      `{"hook": "` + hook + `", "arguments": ["cmd"], "stages": ["poststart"], "cmds": ["^/usr/sbin/init$"]}`,
+		"20-label.json":    `{"hook": "` + hook + `", "arguments": ["label"], "stages": ["prestart"], "annotations": ["^audit=true$"]}`,
+		"30-mounts.json":   `{"hook": "` + hook + `", "arguments": ["mounts"], "stages": ["prestart"], "hasbindmounts": true}`,
+		"40-stage.json":    `{"hook": "` + hook + `", "stages": ["prerun"]}`,
+		"50-relative.json": `{"hook": "hook", "stages": ["prestart"]}`,
+		"60-missing.json":  `{"hook": "` + filepath.Join(dir, "missing") + `", "stages": ["prestart"]}`,
+		"70-pattern.json":  `{"hook": "` + hook + `", "stages": ["prestart"], "cmds": ["("]}`,
+		"80-invalid.json":  `{"hook": `,
+		"README":           `not a hook`,
+	} {
+		if err := ioutil.WriteFile(filepath.Join(dir, name), []byte(def), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	hooks, err := readOCIHooks(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(hooks) != 4 {
+		t.Fatalf("expected 4 valid hooks, got %d", len(hooks))
+	}
+
+	daemon := &Daemon{configStore: &Config{HooksDir: dir}}
+	c := &container.Container{
+		CommonContainer: container.CommonContainer{
+			ID:     "0123456789",
+			Config: &containertypes.Config{Labels: map[string]string{"audit": "true"}},
+		},
+	}
+	s := &specs.Spec{Process: specs.Process{Args: []string{"/bin/sh"}}}
+	if err := daemon.setOCIHooks(s, c); err != nil {
+		t.Fatal(err)
+	}
+	expected := specs.Hooks{
+		Prestart: []specs.Hook{
+			{Path: hook, Args: []string{hook, "all"}},
+			{Path: hook, Args: []string{hook, "label"}},
+		},
+		Poststop: []specs.Hook{
+			{Path: hook, Args: []string{hook, "all"}},
+		},
+	}
+	if !reflect.DeepEqual(s.Hooks, expected) {
+		t.Fatalf("expected the hooks %+v, got %+v", expected, s.Hooks)
+	}
+
+	c.Config.Labels = nil
+	s = &specs.Spec{
+		Process: specs.Process{Args: []string{"/usr/sbin/init"}},
+		Mounts:  []specs.Mount{{Destination: "/data", Options: []string{"rbind", "rprivate"}}},
+	}
+	if err := daemon.setOCIHooks(s, c); err != nil {
+		t.Fatal(err)
+	}
+	expected = specs.Hooks{
+		Prestart: []specs.Hook{
+			{Path: hook, Args: []string{hook, "all"}},
+			{Path: hook, Args: []string{hook, "mounts"}},
+		},
+		Poststart: []specs.Hook{
+			{Path: hook, Args: []string{hook, "cmd"}},
+		},
+		Poststop: []specs.Hook{
+			{Path: hook, Args: []string{hook, "all"}},
+		},
+	}
+	if !reflect.DeepEqual(s.Hooks, expected) {
+		t.Fatalf("expected the hooks %+v, got %+v", expected, s.Hooks)
+	}
+}
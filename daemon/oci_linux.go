@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"io"
 	"os"
+	"os/exec"
 	"path/filepath"
 	"sort"
 	"strconv"
@@ -115,19 +116,7 @@ func setDevices(s *specs.Spec, c *container.Container) error {
 func setRlimits(daemon *Daemon, s *specs.Spec, c *container.Container) error {
 	var rlimits []specs.Rlimit
 
-	ulimits := c.HostConfig.Ulimits
-	// Merge ulimits with daemon defaults
-	ulIdx := make(map[string]struct{})
-	for _, ul := range ulimits {
-		ulIdx[ul.Name] = struct{}{}
-	}
-	for name, ul := range daemon.configStore.Ulimits {
-		if _, exists := ulIdx[name]; !exists {
-			ulimits = append(ulimits, ul)
-		}
-	}
-
-	for _, ul := range ulimits {
+	for _, ul := range daemon.mergeUlimits(c.HostConfig) {
 		rlimits = append(rlimits, specs.Rlimit{
 			Type: "RLIMIT_" + strings.ToUpper(ul.Name),
 			Soft: uint64(ul.Soft),
@@ -663,10 +652,18 @@ func (daemon *Daemon) createSpec(c *container.Container) (*libcontainerd.Spec, e
 	}
 	ms = append(ms, c.IpcMounts()...)
 	ms = append(ms, c.TmpfsMounts()...)
+	defaultMounts, err := daemon.setupDefaultMounts(c)
+	if err != nil {
+		return nil, err
+	}
+	ms = append(ms, defaultMounts...)
 	sort.Sort(mounts(ms))
 	if err := setMounts(daemon, &s, c, ms); err != nil {
 		return nil, fmt.Errorf("linux mounts: %v", err)
 	}
+	if err := daemon.setInit(&s, c); err != nil {
+		return nil, fmt.Errorf("linux init: %v", err)
+	}
 
 	for _, ns := range s.Linux.Namespaces {
 		if ns.Type == "network" && ns.Path == "" && !c.Config.NetworkDisabled {
@@ -684,6 +681,10 @@ func (daemon *Daemon) createSpec(c *container.Container) (*libcontainerd.Spec, e
 		}
 	}
 
+	if err := daemon.setOCIHooks(&s, c); err != nil {
+		return nil, fmt.Errorf("linux spec hooks: %v", err)
+	}
+
 	if apparmor.IsEnabled() {
 		appArmorProfile := "docker-default"
 		if len(c.AppArmorProfile) > 0 {
@@ -700,6 +701,38 @@ func (daemon *Daemon) createSpec(c *container.Container) (*libcontainerd.Spec, e
 	return (*libcontainerd.Spec)(&s), nil
 }
 
+// setInit makes the init binary, bind mounted read only at /dev/init, the
+// first process of the container, so that it forwards the signals to the
+// command of the container and reaps the zombie processes. The HostConfig of
+// the container overrides the --init setting of the daemon.
+func (daemon *Daemon) setInit(s *specs.Spec, c *container.Container) error {
+	init := daemon.configStore.Init
+	if c.HostConfig.Init != nil {
+		init = *c.HostConfig.Init
+	}
+	if !init {
+		return nil
+	}
+
+	name := daemon.configStore.InitPath
+	if name == "" {
+		name = defaultInitBinary
+	}
+	path, err := exec.LookPath(name)
+	if err != nil {
+		return fmt.Errorf("cannot find the init binary %s: %v", name, err)
+	}
+
+	s.Process.Args = append([]string{"/dev/init", "--"}, s.Process.Args...)
+	s.Mounts = append(s.Mounts, specs.Mount{
+		Destination: "/dev/init",
+		Type:        "bind",
+		Source:      path,
+		Options:     []string{"bind", "ro"},
+	})
+	return nil
+}
+
 func clearReadOnly(m *specs.Mount) {
 	var opt []string
 	for _, o := range m.Options {
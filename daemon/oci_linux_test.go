@@ -0,0 +1,70 @@
+package daemon
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/docker/docker/container"
+	containertypes "github.com/docker/engine-api/types/container"
+	"github.com/opencontainers/specs/specs-go"
+)
+
+func TestSetInit(t *testing.T) {
+	dir, err := ioutil.TempDir("", "docker-init-")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	initPath := filepath.Join(dir, "docker-init")
+	if err := ioutil.WriteFile(initPath, []byte("#!/bin/sh\n"), 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	enabled, disabled := true, false
+	for _, tc := range []struct {
+		daemonInit    bool
+		containerInit *bool
+		expected      bool
+	}{
+		{false, nil, false},
+		{true, nil, true},
+		{false, &enabled, true},
+		{true, &disabled, false},
+	} {
+		daemon := &Daemon{configStore: &Config{Init: tc.daemonInit, InitPath: initPath}}
+		c := &container.Container{
+			CommonContainer: container.CommonContainer{
+				HostConfig: &containertypes.HostConfig{Init: tc.containerInit},
+			},
+		}
+		s := &specs.Spec{Process: specs.Process{Args: []string{"/bin/app", "-v"}}}
+		if err := daemon.setInit(s, c); err != nil {
+			t.Fatal(err)
+		}
+
+		if !tc.expected {
+			if !reflect.DeepEqual(s.Process.Args, []string{"/bin/app", "-v"}) || len(s.Mounts) != 0 {
+				t.Fatalf("expected no init for %+v, got %v and %v", tc, s.Process.Args, s.Mounts)
+			}
+			continue
+		}
+		if expected := []string{"/dev/init", "--", "/bin/app", "-v"}; !reflect.DeepEqual(s.Process.Args, expected) {
+			t.Fatalf("expected the arguments %v, got %v", expected, s.Process.Args)
+		}
+		if len(s.Mounts) != 1 || s.Mounts[0].Destination != "/dev/init" || s.Mounts[0].Source != initPath {
+			t.Fatalf("expected the init to be mounted at /dev/init, got %v", s.Mounts)
+		}
+	}
+
+	daemon := &Daemon{configStore: &Config{Init: true, InitPath: filepath.Join(dir, "missing")}}
+	c := &container.Container{
+		CommonContainer: container.CommonContainer{HostConfig: &containertypes.HostConfig{}},
+	}
+	if err := daemon.setInit(&specs.Spec{}, c); err == nil {
+		t.Fatal("expected an error for a missing init binary")
+	}
+}
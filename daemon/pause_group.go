@@ -0,0 +1,203 @@
+package daemon
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/Sirupsen/logrus"
+	"github.com/docker/docker/container"
+	"github.com/docker/docker/errors"
+	"github.com/docker/engine-api/types"
+	"github.com/docker/engine-api/types/filters"
+)
+
+// ContainerPauseGroup pauses the running containers matching filter, all of
+// them or none: if one of them cannot be paused, the ones it paused are
+// unpaused. The containers already paused are left as they are. It returns
+// the IDs of the containers of the group.
+func (daemon *Daemon) ContainerPauseGroup(filter filters.Args) (*types.PauseGroupReport, error) {
+	group, err := daemon.containerGroup(filter)
+	if err != nil {
+		return nil, err
+	}
+	if err := transitionGroup(group, isPaused, daemon.containerPause, daemon.containerUnpause); err != nil {
+		return nil, fmt.Errorf("Cannot pause the group of containers: %v", err)
+	}
+	return groupReport(group), nil
+}
+
+// ContainerUnpauseGroup unpauses the running containers matching filter, all
+// of them or none: if one of them cannot be unpaused, the ones it unpaused
+// are paused again. The containers which are not paused are left as they
+// are. It returns the IDs of the containers of the group.
+func (daemon *Daemon) ContainerUnpauseGroup(filter filters.Args) (*types.PauseGroupReport, error) {
+	group, err := daemon.containerGroup(filter)
+	if err != nil {
+		return nil, err
+	}
+	if err := transitionGroup(group, isUnpaused, daemon.containerUnpause, daemon.containerPause); err != nil {
+		return nil, fmt.Errorf("Cannot unpause the group of containers: %v", err)
+	}
+	return groupReport(group), nil
+}
+
+// ContainerPauseAll pauses the running containers matching filter, or all
+// of them if it is empty, independently of each other: the containers which
+// cannot be paused are reported, and don't keep the others from being
+// paused. The containers already paused are skipped.
+func (daemon *Daemon) ContainerPauseAll(filter filters.Args) (*types.PauseGroupReport, error) {
+	list, err := daemon.runningContainers(filter)
+	if err != nil {
+		return nil, err
+	}
+	var unpaused []*container.Container
+	for _, c := range list {
+		if !c.IsPaused() {
+			unpaused = append(unpaused, c)
+		}
+	}
+	return transitionAll(unpaused, daemon.containerPause), nil
+}
+
+// ContainerUnpauseAll unpauses the paused containers matching filter, or
+// all of them if it is empty, independently of each other: the containers
+// which cannot be unpaused are reported, and don't keep the others from
+// being unpaused.
+func (daemon *Daemon) ContainerUnpauseAll(filter filters.Args) (*types.PauseGroupReport, error) {
+	list, err := daemon.runningContainers(filter)
+	if err != nil {
+		return nil, err
+	}
+	var paused []*container.Container
+	for _, c := range list {
+		if c.IsPaused() {
+			paused = append(paused, c)
+		}
+	}
+	return transitionAll(paused, daemon.containerUnpause), nil
+}
+
+// containerGroup returns the running containers matching filter, which
+// must not be empty so that a group never spans all the containers.
+func (daemon *Daemon) containerGroup(filter filters.Args) ([]*container.Container, error) {
+	if filter.Len() == 0 {
+		return nil, errors.NewBadRequestError(fmt.Errorf("A filter is required to select a group of containers"))
+	}
+	group, err := daemon.runningContainers(filter)
+	if err != nil {
+		return nil, err
+	}
+	if len(group) == 0 {
+		return nil, errors.NewRequestNotFoundError(fmt.Errorf("No running container matches the filter"))
+	}
+	return group, nil
+}
+
+// runningContainers returns the running containers matching filter, paused
+// or not.
+func (daemon *Daemon) runningContainers(filter filters.Args) ([]*container.Container, error) {
+	list, err := daemon.Containers(&types.ContainerListOptions{Filter: filter})
+	if err != nil {
+		return nil, err
+	}
+	var running []*container.Container
+	for _, c := range list {
+		if ctr, err := daemon.GetContainer(c.ID); err == nil {
+			running = append(running, ctr)
+		}
+	}
+	return running, nil
+}
+
+func isPaused(c *container.Container) bool {
+	return c.IsPaused()
+}
+
+func isUnpaused(c *container.Container) bool {
+	return !c.IsPaused()
+}
+
+// transitionGroup applies do to the containers of group concurrently, so
+// that they change state as close together as possible. The containers for
+// which done returns true are already in the target state, and are skipped.
+// If do fails for one of them, undo is applied to those for which it
+// succeeded.
+func transitionGroup(group []*container.Container, done func(*container.Container) bool, do, undo func(*container.Container) error) error {
+	errs := make([]error, len(group))
+	skipped := make([]bool, len(group))
+	var wg sync.WaitGroup
+	for i, c := range group {
+		if done(c) {
+			skipped[i] = true
+			continue
+		}
+		wg.Add(1)
+		go func(i int, c *container.Container) {
+			defer wg.Done()
+			errs[i] = do(c)
+		}(i, c)
+	}
+	wg.Wait()
+
+	var failed []string
+	for _, err := range errs {
+		if err != nil {
+			failed = append(failed, err.Error())
+		}
+	}
+	if len(failed) == 0 {
+		return nil
+	}
+
+	for i, c := range group {
+		if errs[i] != nil || skipped[i] {
+			continue
+		}
+		wg.Add(1)
+		go func(c *container.Container) {
+			defer wg.Done()
+			if err := undo(c); err != nil {
+				logrus.Errorf("Error rolling back container %s of a group: %v", c.ID, err)
+			}
+		}(c)
+	}
+	wg.Wait()
+	return fmt.Errorf("%s", strings.Join(failed, "; "))
+}
+
+// transitionAll applies do to the containers concurrently, and reports
+// those for which it succeeded and the errors of the others.
+func transitionAll(containers []*container.Container, do func(*container.Container) error) *types.PauseGroupReport {
+	errs := make([]error, len(containers))
+	var wg sync.WaitGroup
+	for i, c := range containers {
+		wg.Add(1)
+		go func(i int, c *container.Container) {
+			defer wg.Done()
+			errs[i] = do(c)
+		}(i, c)
+	}
+	wg.Wait()
+
+	report := &types.PauseGroupReport{Containers: []string{}}
+	for i, c := range containers {
+		if errs[i] == nil {
+			report.Containers = append(report.Containers, c.ID)
+			continue
+		}
+		if report.Errors == nil {
+			report.Errors = make(map[string]string)
+		}
+		report.Errors[c.ID] = errs[i].Error()
+	}
+	return report
+}
+
+func groupReport(group []*container.Container) *types.PauseGroupReport {
+	report := &types.PauseGroupReport{Containers: make([]string, len(group))}
+	for i, c := range group {
+		report.Containers[i] = c.ID
+	}
+	return report
+}
@@ -0,0 +1,114 @@
+package daemon
+
+import (
+	"fmt"
+	"reflect"
+	"sync"
+	"testing"
+
+	"github.com/docker/docker/container"
+)
+
+func TestTransitionGroup(t *testing.T) {
+	var group []*container.Container
+	for _, id := range []string{"a", "b", "c"} {
+		group = append(group, &container.Container{CommonContainer: container.CommonContainer{ID: id}})
+	}
+
+	var mu sync.Mutex
+	paused := map[string]bool{}
+	pause := func(c *container.Container) error {
+		mu.Lock()
+		defer mu.Unlock()
+		if c.ID == "b" {
+			return fmt.Errorf("Container %s is already paused", c.ID)
+		}
+		paused[c.ID] = true
+		return nil
+	}
+	unpause := func(c *container.Container) error {
+		mu.Lock()
+		defer mu.Unlock()
+		delete(paused, c.ID)
+		return nil
+	}
+
+	done := func(c *container.Container) bool {
+		mu.Lock()
+		defer mu.Unlock()
+		return paused[c.ID]
+	}
+
+	// b fails, so a and c are unpaused
+	if err := transitionGroup(group, done, pause, unpause); err == nil || err.Error() != "Container b is already paused" {
+		t.Fatalf("expected the pause of b to fail, got %v", err)
+	}
+	if len(paused) != 0 {
+		t.Fatalf("expected the group to be rolled back, got %v paused", paused)
+	}
+
+	if err := transitionGroup(group[:1], done, pause, unpause); err != nil {
+		t.Fatal(err)
+	}
+	if !paused["a"] || len(paused) != 1 {
+		t.Fatalf("expected a to be paused, got %v", paused)
+	}
+
+	// a is already paused, so it is skipped
+	var calls []string
+	pauseOnce := func(c *container.Container) error {
+		mu.Lock()
+		calls = append(calls, c.ID)
+		mu.Unlock()
+		return pause(c)
+	}
+	if err := transitionGroup([]*container.Container{group[0], group[2]}, done, pauseOnce, unpause); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(calls, []string{"c"}) {
+		t.Fatalf("expected only c to be paused, got %v", calls)
+	}
+	// a and c are already paused, so they stay paused when b fails
+	if err := transitionGroup(group, done, pause, unpause); err == nil {
+		t.Fatal("expected the pause of b to fail")
+	}
+	if !paused["a"] || !paused["c"] || len(paused) != 2 {
+		t.Fatalf("expected the containers already paused to stay paused, got %v", paused)
+	}
+}
+
+func TestTransitionAll(t *testing.T) {
+	var containers []*container.Container
+	for _, id := range []string{"a", "b", "c"} {
+		containers = append(containers, &container.Container{CommonContainer: container.CommonContainer{ID: id}})
+	}
+
+	var mu sync.Mutex
+	paused := map[string]bool{}
+	pause := func(c *container.Container) error {
+		mu.Lock()
+		defer mu.Unlock()
+		if c.ID == "b" {
+			return fmt.Errorf("Container %s is already paused", c.ID)
+		}
+		paused[c.ID] = true
+		return nil
+	}
+
+	// b fails, and a and c stay paused
+	report := transitionAll(containers, pause)
+	if !reflect.DeepEqual(report.Containers, []string{"a", "c"}) {
+		t.Fatalf("expected a and c to be reported as paused, got %v", report.Containers)
+	}
+	if !reflect.DeepEqual(report.Errors, map[string]string{"b": "Container b is already paused"}) {
+		t.Fatalf("expected the error of b, got %v", report.Errors)
+	}
+	if !paused["a"] || !paused["c"] || len(paused) != 2 {
+		t.Fatalf("expected a and c to be paused, got %v", paused)
+	}
+
+	report = transitionAll(nil, pause)
+	if report.Containers == nil || len(report.Containers) != 0 || report.Errors != nil {
+		t.Fatalf("expected an empty report, got %+v", report)
+	}
+}
@@ -0,0 +1,259 @@
+package daemon
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/Sirupsen/logrus"
+	"github.com/docker/docker/errors"
+	"github.com/docker/docker/image"
+	"github.com/docker/docker/layer"
+	"github.com/docker/docker/pkg/directory"
+	"github.com/docker/docker/volume"
+	"github.com/docker/engine-api/types"
+	"github.com/docker/engine-api/types/filters"
+	timetypes "github.com/docker/engine-api/types/time"
+)
+
+var (
+	acceptedContainersPruneFilterTags = map[string]bool{
+		"until": true,
+		"label": true,
+	}
+	acceptedImagesPruneFilterTags = map[string]bool{
+		"dangling": true,
+		"until":    true,
+		"label":    true,
+	}
+	acceptedVolumesPruneFilterTags = map[string]bool{
+		"label": true,
+	}
+)
+
+// ContainersPrune removes the stopped containers matching pruneFilters:
+// those created before the until filter, and with the labels of the label
+// filter. It returns the IDs of the containers removed and the size of their
+// writable layers.
+func (daemon *Daemon) ContainersPrune(pruneFilters filters.Args) (*types.ContainersPruneReport, error) {
+	if err := pruneFilters.Validate(acceptedContainersPruneFilterTags); err != nil {
+		return nil, err
+	}
+	until, err := pruneUntil(pruneFilters)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &types.ContainersPruneReport{ContainersDeleted: []string{}}
+	for _, c := range daemon.List() {
+		if c.IsRunning() || c.IsRestarting() {
+			continue
+		}
+		if !until.IsZero() && !c.Created.Before(until) {
+			continue
+		}
+		if !pruneFilters.MatchKVList("label", c.Config.Labels) {
+			continue
+		}
+
+		sizeRw, _ := daemon.getSize(c)
+		if err := daemon.ContainerRm(c.ID, &types.ContainerRmConfig{}); err != nil {
+			logrus.Warnf("Failed to prune container %s: %v", c.ID, err)
+			continue
+		}
+		// ContainerRm returns early if another removal is in progress
+		if daemon.containers.Get(c.ID) != nil {
+			continue
+		}
+		report.ContainersDeleted = append(report.ContainersDeleted, c.ID)
+		if sizeRw > 0 {
+			report.SpaceReclaimed += uint64(sizeRw)
+		}
+	}
+	return report, nil
+}
+
+// ImagesPrune deletes the images without children nor containers matching
+// pruneFilters, and their parents which become dangling. Only the dangling
+// images are deleted unless the dangling filter is false, in which case the
+// tagged images are untagged and deleted as well. It returns the images
+// untagged and deleted, and the size of the layers deleted with them.
+func (daemon *Daemon) ImagesPrune(pruneFilters filters.Args) (*types.ImagesPruneReport, error) {
+	if err := pruneFilters.Validate(acceptedImagesPruneFilterTags); err != nil {
+		return nil, err
+	}
+	until, err := pruneUntil(pruneFilters)
+	if err != nil {
+		return nil, err
+	}
+	danglingOnly := true
+	if pruneFilters.Include("dangling") {
+		if pruneFilters.ExactMatch("dangling", "false") || pruneFilters.ExactMatch("dangling", "0") {
+			danglingOnly = false
+		} else if !pruneFilters.ExactMatch("dangling", "true") && !pruneFilters.ExactMatch("dangling", "1") {
+			return nil, errors.NewBadRequestError(fmt.Errorf("Invalid filter 'dangling=%s'", pruneFilters.Get("dangling")))
+		}
+	}
+
+	layersBefore := daemon.layerSizes(daemon.imageStore.Map())
+	containers := daemon.imageContainers()
+
+	report := &types.ImagesPruneReport{ImagesDeleted: []types.ImageDelete{}}
+	for id, img := range daemon.imageStore.Heads() {
+		if containers[id] > 0 {
+			continue
+		}
+		if !until.IsZero() && !img.Created.Before(until) {
+			continue
+		}
+		var labels map[string]string
+		if img.Config != nil {
+			labels = img.Config.Labels
+		}
+		if !pruneFilters.MatchKVList("label", labels) {
+			continue
+		}
+
+		refs := daemon.referenceStore.References(id)
+		if len(refs) > 0 && danglingOnly {
+			continue
+		}
+		if len(refs) == 0 {
+			records, err := daemon.ImageDelete(id.String(), false, true)
+			if err != nil {
+				logrus.Warnf("Failed to prune image %s: %v", id, err)
+			}
+			report.ImagesDeleted = append(report.ImagesDeleted, records...)
+			continue
+		}
+		// Removing the last reference deletes the image
+		for _, ref := range refs {
+			records, err := daemon.ImageDelete(ref.String(), false, true)
+			report.ImagesDeleted = append(report.ImagesDeleted, records...)
+			if err != nil {
+				logrus.Warnf("Failed to prune image %s: %v", ref.String(), err)
+				break
+			}
+		}
+	}
+
+	// Only the layers which are gone are accounted, there is no need to
+	// compute the sizes of the remaining ones again.
+	layersAfter := daemon.layerChainIDs(daemon.imageStore.Map())
+	for chainID, size := range layersBefore {
+		if !layersAfter[chainID] && size > 0 {
+			report.SpaceReclaimed += uint64(size)
+		}
+	}
+	return report, nil
+}
+
+// VolumesPrune removes the volumes not used by a container and with the
+// labels of the label filter of pruneFilters. It returns the names of the
+// volumes removed and the size of the local ones.
+func (daemon *Daemon) VolumesPrune(pruneFilters filters.Args) (*types.VolumesPruneReport, error) {
+	if err := pruneFilters.Validate(acceptedVolumesPruneFilterTags); err != nil {
+		return nil, err
+	}
+
+	volumes, _, err := daemon.volumes.List()
+	if err != nil {
+		return nil, err
+	}
+
+	report := &types.VolumesPruneReport{VolumesDeleted: []string{}}
+	for _, v := range daemon.volumes.FilterByUsed(volumes, false) {
+		var labels map[string]string
+		if lv, ok := v.(volume.LabeledVolume); ok {
+			labels = lv.Labels()
+		}
+		if !pruneFilters.MatchKVList("label", labels) {
+			continue
+		}
+
+		var size int64
+		if v.DriverName() == volume.DefaultDriverName {
+			if size, err = directory.Size(v.Path()); err != nil {
+				logrus.Debugf("Failed to get the size of volume %s: %v", v.Name(), err)
+			}
+		}
+		// The volume is not removed if a container started using it since
+		// it was listed
+		if err := daemon.volumes.Remove(v); err != nil {
+			logrus.Warnf("Failed to prune volume %s: %v", v.Name(), err)
+			continue
+		}
+		daemon.LogVolumeEvent(v.Name(), "destroy", map[string]string{"driver": v.DriverName()})
+		report.VolumesDeleted = append(report.VolumesDeleted, v.Name())
+		if size > 0 {
+			report.SpaceReclaimed += uint64(size)
+		}
+	}
+	return report, nil
+}
+
+// pruneUntil returns the time of the until filter of pruneFilters, a
+// timestamp, a date or a duration before now, or the zero time if there is
+// none.
+func pruneUntil(pruneFilters filters.Args) (time.Time, error) {
+	var until time.Time
+	err := pruneFilters.WalkValues("until", func(value string) error {
+		t, err := timetypes.ParseTime(value, time.Now())
+		if err != nil {
+			return errors.NewBadRequestError(fmt.Errorf("Invalid filter 'until=%s': %v", value, err))
+		}
+		until = t
+		return nil
+	})
+	return until, err
+}
+
+// layerSizes returns the sizes of the layers of images, and of their
+// parents, by chain ID.
+func (daemon *Daemon) layerSizes(images map[image.ID]*image.Image) map[layer.ChainID]int64 {
+	sizes := make(map[layer.ChainID]int64)
+	daemon.walkImageLayers(images, func(l layer.Layer) {
+		size, err := l.DiffSize()
+		if err != nil {
+			logrus.Debugf("Failed to get the size of layer %s: %v", l.ChainID(), err)
+		}
+		sizes[l.ChainID()] = size
+	})
+	return sizes
+}
+
+// layerChainIDs returns the chain IDs of the layers of images, and of their
+// parents.
+func (daemon *Daemon) layerChainIDs(images map[image.ID]*image.Image) map[layer.ChainID]bool {
+	chainIDs := make(map[layer.ChainID]bool)
+	daemon.walkImageLayers(images, func(l layer.Layer) {
+		chainIDs[l.ChainID()] = true
+	})
+	return chainIDs
+}
+
+// walkImageLayers calls fn once for each layer of images and of their
+// parents.
+func (daemon *Daemon) walkImageLayers(images map[image.ID]*image.Image, fn func(layer.Layer)) {
+	seen := make(map[layer.ChainID]bool)
+	for _, img := range images {
+		if img.RootFS == nil {
+			continue
+		}
+		chainID := img.RootFS.ChainID()
+		if seen[chainID] || chainID == "" {
+			continue
+		}
+		l, err := daemon.layerStore.Get(chainID)
+		if err != nil {
+			continue
+		}
+		for p := l; p != nil; p = p.Parent() {
+			if seen[p.ChainID()] {
+				break
+			}
+			seen[p.ChainID()] = true
+			fn(p)
+		}
+		layer.ReleaseAndLog(daemon.layerStore, l)
+	}
+}
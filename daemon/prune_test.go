@@ -0,0 +1,39 @@
+package daemon
+
+import (
+	"testing"
+	"time"
+
+	"github.com/docker/engine-api/types/filters"
+)
+
+func TestPruneUntil(t *testing.T) {
+	args := filters.NewArgs()
+	if until, err := pruneUntil(args); err != nil || !until.IsZero() {
+		t.Fatalf("expected no time without an until filter, got %v, %v", until, err)
+	}
+
+	args.Add("until", "1h")
+	until, err := pruneUntil(args)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if d := time.Since(until); d < time.Hour || d > time.Hour+time.Minute {
+		t.Fatalf("expected a time an hour ago, got %v", until)
+	}
+
+	args = filters.NewArgs()
+	args.Add("until", "a while ago")
+	if _, err := pruneUntil(args); err == nil {
+		t.Fatal("expected an error for an invalid time")
+	}
+}
+
+func TestVolumesPruneFilters(t *testing.T) {
+	daemon := &Daemon{}
+	args := filters.NewArgs()
+	args.Add("until", "24h")
+	if _, err := daemon.VolumesPrune(args); err == nil {
+		t.Fatal("expected an error for the until filter of volumes")
+	}
+}
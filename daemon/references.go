@@ -0,0 +1,90 @@
+package daemon
+
+import (
+	"github.com/docker/docker/container"
+	"github.com/docker/engine-api/types"
+)
+
+// ImageContainers returns the containers created from the image, which keep
+// it from being deleted.
+func (daemon *Daemon) ImageContainers(name string) ([]*types.Container, error) {
+	id, err := daemon.GetImageID(name)
+	if err != nil {
+		return nil, err
+	}
+	return daemon.referencingContainers(func(c *container.Container) bool {
+		return c.ImageID == id
+	})
+}
+
+// VolumeContainers returns the containers referencing the volume in the
+// volume store, which keep it from being removed.
+func (daemon *Daemon) VolumeContainers(name string) ([]*types.Container, error) {
+	v, err := daemon.volumes.Get(name)
+	if err != nil {
+		return nil, err
+	}
+	refs := make(map[string]bool)
+	for _, ref := range daemon.volumes.Refs(v) {
+		refs[ref] = true
+	}
+	return daemon.referencingContainers(func(c *container.Container) bool {
+		return refs[c.ID]
+	})
+}
+
+// NetworkContainers returns the containers with an endpoint in the network,
+// which keep it from being removed. With includeStopped, it also returns the
+// containers which are connected to the network when they start.
+func (daemon *Daemon) NetworkContainers(name string, includeStopped bool) ([]*types.Container, error) {
+	n, err := daemon.FindNetwork(name)
+	if err != nil {
+		return nil, err
+	}
+	attached := make(map[string]bool)
+	for _, ep := range n.Endpoints() {
+		if sb := ep.Info().Sandbox(); sb != nil {
+			attached[sb.ContainerID()] = true
+		}
+	}
+	return daemon.referencingContainers(func(c *container.Container) bool {
+		if attached[c.ID] {
+			return true
+		}
+		if !includeStopped || c.IsRunning() || c.NetworkSettings == nil {
+			return false
+		}
+		_, ok := c.NetworkSettings.Networks[n.Name()]
+		return ok
+	})
+}
+
+// referencingContainers returns the containers, running or not, for which
+// the function returns true, as listed by `docker ps`.
+func (daemon *Daemon) referencingContainers(references func(*container.Container) bool) ([]*types.Container, error) {
+	ctx := &listContext{
+		names:                daemon.nameIndex.GetAll(),
+		ContainerListOptions: &types.ContainerListOptions{All: true},
+	}
+	containers := []*types.Container{}
+	for _, c := range daemon.List() {
+		t, err := daemon.referencingContainer(c, ctx, references)
+		if err != nil {
+			return nil, err
+		}
+		if t != nil {
+			containers = append(containers, t)
+		}
+	}
+	return containers, nil
+}
+
+func (daemon *Daemon) referencingContainer(c *container.Container, ctx *listContext, references func(*container.Container) bool) (*types.Container, error) {
+	c.Lock()
+	defer c.Unlock()
+
+	if !references(c) {
+		return nil, nil
+	}
+	return daemon.transformContainer(c, ctx)
+}
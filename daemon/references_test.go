@@ -0,0 +1,69 @@
+package daemon
+
+import (
+	"io/ioutil"
+	"os"
+	"testing"
+
+	"github.com/docker/docker/container"
+	"github.com/docker/docker/daemon/network"
+	"github.com/docker/docker/pkg/registrar"
+	containertypes "github.com/docker/engine-api/types/container"
+)
+
+func TestVolumeContainers(t *testing.T) {
+	tmp, err := ioutil.TempDir("", "docker-references-")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(tmp)
+
+	daemon, err := initDaemonWithVolumeStore(tmp)
+	if err != nil {
+		t.Fatal(err)
+	}
+	daemon.containers = container.NewMemoryStore()
+	daemon.nameIndex = registrar.NewRegistrar()
+
+	for _, name := range []string{"web", "db", "backup"} {
+		c := container.NewBaseContainer(name+"-id", tmp)
+		c.Name = "/" + name
+		c.Config = &containertypes.Config{}
+		c.HostConfig = &containertypes.HostConfig{}
+		c.NetworkSettings = &network.Settings{}
+		daemon.containers.Add(c.ID, c)
+		daemon.reserveName(c.ID, c.Name)
+	}
+	for _, ref := range []string{"db-id", "backup-id"} {
+		if _, err := daemon.volumes.CreateWithRef("data", "local", ref, nil, nil); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if _, err := daemon.volumes.Create("unused", "local", nil, nil); err != nil {
+		t.Fatal(err)
+	}
+
+	containers, err := daemon.VolumeContainers("data")
+	if err != nil {
+		t.Fatal(err)
+	}
+	names := make(map[string]bool)
+	for _, c := range containers {
+		names[c.Names[0]] = true
+	}
+	if len(containers) != 2 || !names["/db"] || !names["/backup"] {
+		t.Fatalf("expected the containers /db and /backup, got %v", names)
+	}
+
+	containers, err = daemon.VolumeContainers("unused")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(containers) != 0 {
+		t.Fatalf("expected no containers, got %d", len(containers))
+	}
+
+	if _, err := daemon.VolumeContainers("missing"); err == nil {
+		t.Fatal("expected an error for a missing volume")
+	}
+}
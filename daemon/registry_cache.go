@@ -0,0 +1,63 @@
+package daemon
+
+import (
+	"crypto/tls"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"net/http"
+
+	"github.com/Sirupsen/logrus"
+	"github.com/docker/docker/distribution"
+	"github.com/docker/docker/distribution/mirror"
+	"github.com/docker/docker/reference"
+	"github.com/docker/engine-api/types"
+	"github.com/docker/go-connections/tlsconfig"
+	"golang.org/x/net/context"
+)
+
+// startRegistryCache serves the images of the daemon through a read-only
+// registry API on the address of the configuration. The API is only served
+// over TLS to clients with a certificate signed by the configured CA, as it
+// exposes every image of the daemon. With RegistryCachePull, tags requested
+// but missing are pulled by the daemon first, so that it acts as a
+// pull-through cache for other hosts.
+func (daemon *Daemon) startRegistryCache(config *Config) error {
+	if config.RegistryCacheTLSCACert == "" || config.RegistryCacheTLSCert == "" || config.RegistryCacheTLSKey == "" {
+		return fmt.Errorf("--registry-cache-addr requires --registry-cache-tlscacert, --registry-cache-tlscert and --registry-cache-tlskey")
+	}
+	tlsConfig, err := tlsconfig.Server(tlsconfig.Options{
+		CAFile:     config.RegistryCacheTLSCACert,
+		CertFile:   config.RegistryCacheTLSCert,
+		KeyFile:    config.RegistryCacheTLSKey,
+		ClientAuth: tls.RequireAndVerifyClientCert,
+	})
+	if err != nil {
+		return fmt.Errorf("Error starting registry cache: %v", err)
+	}
+
+	l, err := net.Listen("tcp", config.RegistryCacheAddr)
+	if err != nil {
+		return fmt.Errorf("Error starting registry cache: %v", err)
+	}
+	daemon.registryCache = l
+
+	mirrorConfig := mirror.Config{
+		ImageStore:     daemon.imageStore,
+		LayerStore:     daemon.layerStore,
+		ReferenceStore: daemon.referenceStore,
+	}
+	if config.RegistryCachePull {
+		mirrorConfig.Pull = func(ctx context.Context, ref reference.Named) error {
+			return daemon.pullImageWithReference(ctx, ref, distribution.Platform{}, false, nil, &types.AuthConfig{}, ioutil.Discard)
+		}
+	}
+
+	logrus.Infof("Serving images as a registry mirror on %s", l.Addr())
+	go func() {
+		if err := http.Serve(tls.NewListener(l, tlsConfig), mirror.NewHandler(mirrorConfig)); err != nil && !daemon.shutdown {
+			logrus.Errorf("Registry cache stopped: %v", err)
+		}
+	}()
+	return nil
+}
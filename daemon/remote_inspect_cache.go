@@ -0,0 +1,117 @@
+package daemon
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"sync"
+	"time"
+
+	"github.com/docker/distribution/digest"
+	"github.com/docker/docker/reference"
+	"github.com/docker/engine-api/types"
+)
+
+// defaultRemoteInspectTTL is how long the results of remote image inspects
+// are cached if remote-inspect-ttl is not set.
+const defaultRemoteInspectTTL = time.Minute
+
+// remoteInspectCacheSize is the maximum number of results cached. The
+// results closest to expiring are dropped to make room for new ones.
+const remoteInspectCacheSize = 1024
+
+// remoteInspectCache caches the results of remote image inspects, so that
+// clients polling the metadata of images in registries do not send a
+// request to the registry every time.
+type remoteInspectCache struct {
+	mu      sync.Mutex
+	ttl     time.Duration
+	entries map[string]remoteInspectEntry
+}
+
+type remoteInspectEntry struct {
+	inspect *types.ImageInspect
+	expires time.Time
+}
+
+func newRemoteInspectCache(ttl time.Duration) *remoteInspectCache {
+	return &remoteInspectCache{
+		ttl:     ttl,
+		entries: make(map[string]remoteInspectEntry),
+	}
+}
+
+// remoteInspectKey returns the cache key of the inspect of ref with
+// authConfig, ref being pinned to the digest pinned, if not empty. The pin
+// is part of the key, so that changing it does not serve the image of the
+// previous pin. The credentials are part of the key, so that images only
+// visible to some credentials are not served from the cache to requests
+// with other credentials.
+func remoteInspectKey(ref reference.Named, pinned digest.Digest, authConfig *types.AuthConfig) string {
+	key := ref.FullName()
+	switch v := ref.(type) {
+	case reference.Canonical:
+		key += "@" + v.Digest().String()
+	case reference.NamedTagged:
+		key += ":" + v.Tag()
+	}
+	if pinned != "" {
+		key += " " + pinned.String()
+	}
+
+	h := sha256.New()
+	for _, s := range []string{authConfig.Username, authConfig.Password, authConfig.IdentityToken, authConfig.RegistryToken} {
+		h.Write([]byte(s))
+		h.Write([]byte{0})
+	}
+	return key + " " + hex.EncodeToString(h.Sum(nil))
+}
+
+// get returns the cached inspect of key, or nil if it is not cached or has
+// expired.
+func (c *remoteInspectCache) get(key string) *types.ImageInspect {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	entry, ok := c.entries[key]
+	if !ok || time.Now().After(entry.expires) {
+		return nil
+	}
+	return entry.inspect
+}
+
+// add caches inspect as the inspect of key, and drops the expired entries.
+// If the cache is full, the entry closest to expiring is dropped.
+func (c *remoteInspectCache) add(key string, inspect *types.ImageInspect) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.ttl <= 0 {
+		return
+	}
+	now := time.Now()
+	for k, entry := range c.entries {
+		if now.After(entry.expires) {
+			delete(c.entries, k)
+		}
+	}
+	if _, ok := c.entries[key]; !ok && len(c.entries) >= remoteInspectCacheSize {
+		var oldest string
+		for k, entry := range c.entries {
+			if oldest == "" || entry.expires.Before(c.entries[oldest].expires) {
+				oldest = k
+			}
+		}
+		delete(c.entries, oldest)
+	}
+	c.entries[key] = remoteInspectEntry{inspect: inspect, expires: now.Add(c.ttl)}
+}
+
+// setTTL changes how long the results are cached. The cached results are
+// dropped, so that they do not outlive the new TTL.
+func (c *remoteInspectCache) setTTL(ttl time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.ttl = ttl
+	c.entries = make(map[string]remoteInspectEntry)
+}
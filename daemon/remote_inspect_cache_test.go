@@ -0,0 +1,67 @@
+package daemon
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/docker/distribution/digest"
+	"github.com/docker/docker/reference"
+	"github.com/docker/engine-api/types"
+)
+
+func TestRemoteInspectCache(t *testing.T) {
+	ref, err := reference.ParseNamed("busybox:latest")
+	if err != nil {
+		t.Fatal(err)
+	}
+	anonymous := remoteInspectKey(ref, "", &types.AuthConfig{})
+	authenticated := remoteInspectKey(ref, "", &types.AuthConfig{Username: "user", Password: "pass"})
+	if anonymous == authenticated {
+		t.Fatal("expected the credentials to be part of the key")
+	}
+	pinned := remoteInspectKey(ref, digest.Digest("sha256:"+strings.Repeat("a", 64)), &types.AuthConfig{})
+	if anonymous == pinned {
+		t.Fatal("expected the pin to be part of the key")
+	}
+
+	c := newRemoteInspectCache(time.Hour)
+	imageInspect := &types.ImageInspect{ID: "sha256:abcdef"}
+	c.add(anonymous, imageInspect)
+	if got := c.get(anonymous); got != imageInspect {
+		t.Fatalf("expected the cached inspect, got %v", got)
+	}
+	if got := c.get(authenticated); got != nil {
+		t.Fatalf("expected no inspect for other credentials, got %v", got)
+	}
+
+	// expired entries are not returned
+	c.entries[anonymous] = remoteInspectEntry{inspect: imageInspect, expires: time.Now().Add(-time.Second)}
+	if got := c.get(anonymous); got != nil {
+		t.Fatalf("expected the expired inspect not to be returned, got %v", got)
+	}
+
+	// the entry closest to expiring is dropped when the cache is full
+	for i := 1; i < remoteInspectCacheSize; i++ {
+		c.add(fmt.Sprintf("key%d", i), imageInspect)
+	}
+	c.entries[anonymous] = remoteInspectEntry{inspect: imageInspect, expires: time.Now().Add(time.Minute)}
+	c.add(authenticated, imageInspect)
+	if len(c.entries) != remoteInspectCacheSize {
+		t.Fatalf("expected %d entries, got %d", remoteInspectCacheSize, len(c.entries))
+	}
+	if got := c.get(anonymous); got != nil {
+		t.Fatalf("expected the oldest inspect to be dropped, got %v", got)
+	}
+	if got := c.get(authenticated); got != imageInspect {
+		t.Fatalf("expected the new inspect, got %v", got)
+	}
+
+	// a zero TTL disables the cache
+	c.setTTL(0)
+	c.add(anonymous, imageInspect)
+	if got := c.get(anonymous); got != nil {
+		t.Fatalf("expected nothing to be cached, got %v", got)
+	}
+}
@@ -5,6 +5,8 @@ import (
 	"strings"
 
 	"github.com/Sirupsen/logrus"
+	"github.com/docker/docker/container"
+	runconfigopts "github.com/docker/docker/runconfig/opts"
 	"github.com/docker/libnetwork"
 )
 
@@ -56,6 +58,17 @@ func (daemon *Daemon) ContainerRename(oldName, newName string) error {
 		}
 	}()
 
+	if err = daemon.renameLinks(container, oldName, newName); err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			if e := daemon.renameLinks(container, newName, oldName); e != nil {
+				logrus.Errorf("%s: Failed in restoring the links on rename failure: %v", container.ID, e)
+			}
+		}
+	}()
+
 	daemon.releaseName(oldName)
 	if err = container.ToDisk(); err != nil {
 		return err
@@ -66,6 +79,7 @@ func (daemon *Daemon) ContainerRename(oldName, newName string) error {
 	}
 
 	if !container.Running {
+		daemon.renameParentLinks(container, oldName, newName)
 		daemon.LogContainerEventWithAttributes(container, "rename", attributes)
 		return nil
 	}
@@ -93,6 +107,67 @@ func (daemon *Daemon) ContainerRename(oldName, newName string) error {
 		}
 	}
 
+	daemon.renameParentLinks(container, oldName, newName)
 	daemon.LogContainerEventWithAttributes(container, "rename", attributes)
 	return nil
 }
+
+// renameLinks moves the links of the parent c, named after it, from its old
+// name to its new one in the name and link indexes. The names of the links
+// are all reserved before any of them moves, so that c keeps its links if
+// one of them is taken.
+func (daemon *Daemon) renameLinks(c *container.Container, oldName, newName string) error {
+	links := make(map[string]*container.Container)
+	for name, child := range daemon.children(c) {
+		if !strings.HasPrefix(name, oldName+"/") {
+			return fmt.Errorf("Linked container %s does not match parent %s", name, oldName)
+		}
+		links[name] = child
+	}
+
+	var reserved []string
+	for name, child := range links {
+		newLink := newName + strings.TrimPrefix(name, oldName)
+		if err := daemon.nameIndex.Reserve(newLink, child.ID); err != nil {
+			for _, r := range reserved {
+				daemon.nameIndex.Release(r)
+			}
+			return fmt.Errorf("Error when renaming link %s: %v", name, err)
+		}
+		reserved = append(reserved, newLink)
+	}
+
+	for name, child := range links {
+		daemon.linkIndex.unlink(name, child, c)
+	}
+	for name, child := range links {
+		daemon.linkIndex.link(c, child, newName+strings.TrimPrefix(name, oldName))
+		daemon.nameIndex.Release(name)
+	}
+	return nil
+}
+
+// renameParentLinks updates the links to the child c in the host configs of
+// its parents, so that they still find it by name when the daemon restarts.
+func (daemon *Daemon) renameParentLinks(c *container.Container, oldName, newName string) {
+	updated := make(map[*container.Container]bool)
+	for _, parent := range daemon.parents(c) {
+		if updated[parent] {
+			continue
+		}
+		updated[parent] = true
+
+		parent.Lock()
+		for i, l := range parent.HostConfig.Links {
+			name, alias, err := runconfigopts.ParseLink(l)
+			if err != nil || "/"+strings.TrimPrefix(name, "/") != oldName {
+				continue
+			}
+			parent.HostConfig.Links[i] = strings.TrimPrefix(newName, "/") + ":" + alias
+		}
+		if err := parent.WriteHostConfig(); err != nil {
+			logrus.Errorf("%s: Failed in writing the links to renamed container %s: %v", parent.ID, c.ID, err)
+		}
+		parent.Unlock()
+	}
+}
@@ -0,0 +1,73 @@
+package daemon
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/docker/docker/container"
+	"github.com/docker/docker/daemon/events"
+	"github.com/docker/docker/daemon/network"
+	"github.com/docker/docker/pkg/registrar"
+	containertypes "github.com/docker/engine-api/types/container"
+)
+
+func TestContainerRenameLinks(t *testing.T) {
+	tmp, err := ioutil.TempDir("", "docker-rename-")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(tmp)
+
+	daemon := &Daemon{
+		containers:    container.NewMemoryStore(),
+		nameIndex:     registrar.NewRegistrar(),
+		linkIndex:     newLinkIndex(),
+		EventsService: events.New(),
+	}
+	newContainer := func(id, name string, links []string) *container.Container {
+		c := container.NewBaseContainer(id, filepath.Join(tmp, id))
+		if err := os.Mkdir(c.Root, 0700); err != nil {
+			t.Fatal(err)
+		}
+		c.Name = name
+		c.Config = &containertypes.Config{}
+		c.HostConfig = &containertypes.HostConfig{Links: links}
+		c.NetworkSettings = &network.Settings{}
+		daemon.containers.Add(c.ID, c)
+		if _, err := daemon.reserveName(c.ID, c.Name); err != nil {
+			t.Fatal(err)
+		}
+		return c
+	}
+	db := newContainer("db-id", "/db", nil)
+	web := newContainer("web-id", "/web", []string{"db:database"})
+	if err := daemon.registerLink(web, db, "database"); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := daemon.ContainerRename("web", "shop"); err != nil {
+		t.Fatal(err)
+	}
+	if id, err := daemon.nameIndex.Get("/shop/database"); err != nil || id != db.ID {
+		t.Fatalf("expected the link to be renamed after its parent, got %q, %v", id, err)
+	}
+	if _, err := daemon.nameIndex.Get("/web/database"); err == nil {
+		t.Fatal("expected the link named after the old name of its parent to be released")
+	}
+	if children := daemon.children(web); len(children) != 1 || children["/shop/database"] != db {
+		t.Fatalf("expected the child to be linked as /shop/database, got %v", children)
+	}
+
+	if err := daemon.ContainerRename("db", "store"); err != nil {
+		t.Fatal(err)
+	}
+	if expected := []string{"store:database"}; !reflect.DeepEqual(web.HostConfig.Links, expected) {
+		t.Fatalf("expected the links of the parent to be %v, got %v", expected, web.HostConfig.Links)
+	}
+	if parents := daemon.parents(db); len(parents) != 1 || parents["/shop/database"] != web {
+		t.Fatalf("expected the parent to keep its link, got %v", parents)
+	}
+}
@@ -2,10 +2,15 @@ package daemon
 
 import (
 	"fmt"
+	"time"
 
 	"github.com/docker/docker/libcontainerd"
 )
 
+// execResizeTimeout is how long a resize waits for the process of an exec
+// to start.
+var execResizeTimeout = 10 * time.Second
+
 // ContainerResize changes the size of the TTY of the process running
 // in the container with the given name to the given height and width.
 func (daemon *Daemon) ContainerResize(name string, height, width int) error {
@@ -36,5 +41,20 @@ func (daemon *Daemon) ContainerExecResize(name string, height, width int) error
 	if err != nil {
 		return err
 	}
-	return daemon.containerd.Resize(ec.ContainerID, ec.ID, width, height)
+	if !ec.Tty {
+		return errExecNoTTY(name)
+	}
+
+	// The client sets the initial size of the TTY as soon as it is
+	// attached, which may be before the process of the exec is started
+	// and the TTY exists.
+	select {
+	case <-ec.Started:
+		if ec.StartErr != nil {
+			return fmt.Errorf("Exec instance '%s' failed to start: %v", name, ec.StartErr)
+		}
+		return daemon.containerd.Resize(ec.ContainerID, ec.ID, width, height)
+	case <-time.After(execResizeTimeout):
+		return fmt.Errorf("Timed out waiting for exec instance '%s' to start", name)
+	}
 }
@@ -0,0 +1,52 @@
+package daemon
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/docker/docker/container"
+	"github.com/docker/docker/daemon/exec"
+)
+
+func TestContainerExecResize(t *testing.T) {
+	c := container.NewBaseContainer("0123456789", "")
+	c.State.SetRunning(1, false)
+	daemon := &Daemon{
+		containers:   container.NewMemoryStore(),
+		execCommands: exec.NewStore(),
+	}
+	daemon.containers.Add(c.ID, c)
+
+	noTTY := exec.NewConfig()
+	noTTY.ContainerID = c.ID
+	daemon.execCommands.Add(noTTY.ID, noTTY)
+	if err := daemon.ContainerExecResize(noTTY.ID, 40, 80); err == nil || !strings.Contains(err.Error(), "has no TTY") {
+		t.Fatalf("expected an error for an exec without TTY, got %v", err)
+	}
+
+	defer func(timeout time.Duration) { execResizeTimeout = timeout }(execResizeTimeout)
+	execResizeTimeout = 10 * time.Millisecond
+	notStarted := exec.NewConfig()
+	notStarted.ContainerID = c.ID
+	notStarted.Tty = true
+	daemon.execCommands.Add(notStarted.ID, notStarted)
+	if err := daemon.ContainerExecResize(notStarted.ID, 40, 80); err == nil || !strings.Contains(err.Error(), "Timed out") {
+		t.Fatalf("expected a timeout for an exec which is not started, got %v", err)
+	}
+
+	failed := exec.NewConfig()
+	failed.ContainerID = c.ID
+	failed.Tty = true
+	failed.StartErr = fmt.Errorf("no such file or directory")
+	close(failed.Started)
+	daemon.execCommands.Add(failed.ID, failed)
+	if err := daemon.ContainerExecResize(failed.ID, 40, 80); err == nil || !strings.Contains(err.Error(), "failed to start") {
+		t.Fatalf("expected an error for an exec which failed to start, got %v", err)
+	}
+
+	if err := daemon.ContainerExecResize("missing", 40, 80); err == nil {
+		t.Fatal("expected an error for a missing exec")
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"fmt"
 
 	"github.com/docker/docker/container"
+	"github.com/docker/docker/daemon/trace"
 )
 
 // ContainerRestart stops and starts a container. It attempts to
@@ -39,7 +40,7 @@ func (daemon *Daemon) containerRestart(container *container.Container, seconds i
 		return err
 	}
 
-	if err := daemon.containerStart(container); err != nil {
+	if err := daemon.containerStart(container, "", trace.New()); err != nil {
 		return err
 	}
 
@@ -11,19 +11,38 @@ import (
 
 	"github.com/Sirupsen/logrus"
 	"github.com/docker/docker/container"
+	"github.com/docker/docker/daemon/trace"
 	"github.com/docker/docker/errors"
 	"github.com/docker/docker/libcontainerd"
 	"github.com/docker/docker/runconfig"
 	containertypes "github.com/docker/engine-api/types/container"
 )
 
-// ContainerStart starts a container.
-func (daemon *Daemon) ContainerStart(name string, hostConfig *containertypes.HostConfig, validateHostname bool) error {
+// containerStartMetrics are the durations of the container starts and of
+// their phases, published through the /debug/vars endpoint.
+var containerStartMetrics = trace.NewRecorder("container_start")
+
+// ContainerStart starts a container, restored from the checkpoint if one is
+// given.
+func (daemon *Daemon) ContainerStart(name string, hostConfig *containertypes.HostConfig, validateHostname bool, checkpoint string) error {
 	container, err := daemon.GetContainer(name)
 	if err != nil {
 		return err
 	}
 
+	if daemon.IsDraining() {
+		return errDraining
+	}
+
+	tr := trace.New()
+	if container.ImageID != "" {
+		tr.Begin("image")
+		if err := daemon.verifyImagePolicy(container.Config.Image, container.ImageID); err != nil {
+			return err
+		}
+		tr.End("image")
+	}
+
 	if container.IsPaused() {
 		return fmt.Errorf("Cannot start a paused container, try unpause instead.")
 	}
@@ -33,6 +52,12 @@ func (daemon *Daemon) ContainerStart(name string, hostConfig *containertypes.Hos
 		return errors.NewErrorWithStatusCode(err, http.StatusNotModified)
 	}
 
+	if checkpoint != "" {
+		if err := checkpointExists(container.CheckpointDir(), name, checkpoint); err != nil {
+			return err
+		}
+	}
+
 	// Windows does not have the backwards compatibility issue here.
 	if runtime.GOOS != "windows" {
 		// This is kept for backward compatibility - hostconfig should be passed when
@@ -77,19 +102,20 @@ func (daemon *Daemon) ContainerStart(name string, hostConfig *containertypes.Hos
 		return err
 	}
 
-	return daemon.containerStart(container)
+	return daemon.containerStart(container, checkpoint, tr)
 }
 
 // Start starts a container
 func (daemon *Daemon) Start(container *container.Container) error {
-	return daemon.containerStart(container)
+	return daemon.containerStart(container, "", trace.New())
 }
 
 // containerStart prepares the container to run by setting up everything the
 // container needs, such as storage and networking, as well as links
 // between containers. The container is left waiting for a signal to
-// begin running.
-func (daemon *Daemon) containerStart(container *container.Container) (err error) {
+// begin running. The container is restored from the checkpoint if one is
+// given. The phases of the start are recorded in tr.
+func (daemon *Daemon) containerStart(container *container.Container, checkpoint string, tr *trace.Trace) (err error) {
 	container.Lock()
 	defer container.Unlock()
 
@@ -100,6 +126,14 @@ func (daemon *Daemon) containerStart(container *container.Container) (err error)
 	if container.RemovalInProgress || container.Dead {
 		return fmt.Errorf("Container is marked for removal and cannot be started.")
 	}
+	daemon.imageUsed(container.ImageID)
+
+	// The runtime and logger spans end while containerd starts the
+	// container, see AttachStreams and StateChanged.
+	container.StartTrace = tr
+	defer func() {
+		container.StartTrace = nil
+	}()
 
 	// if we encounter an error during start we need to ensure that any other
 	// setup has been cleaned up properly
@@ -115,22 +149,28 @@ func (daemon *Daemon) containerStart(container *container.Container) (err error)
 		}
 	}()
 
+	tr.Begin("mount")
 	if err := daemon.conditionalMountOnStart(container); err != nil {
 		return err
 	}
+	tr.End("mount")
 
 	// Make sure NetworkMode has an acceptable value. We do this to ensure
 	// backwards API compatibility.
 	container.HostConfig = runconfig.SetDefaultNetModeIfBlank(container.HostConfig)
 
+	tr.Begin("network")
 	if err := daemon.initializeNetworking(container); err != nil {
 		return err
 	}
+	tr.End("network")
 
+	tr.Begin("spec")
 	spec, err := daemon.createSpec(container)
 	if err != nil {
 		return err
 	}
+	tr.End("spec")
 
 	createOptions := []libcontainerd.CreateOption{libcontainerd.WithRestartManager(container.RestartManager(true))}
 	copts, err := daemon.getLibcontainerdCreateOptions(container)
@@ -140,7 +180,11 @@ func (daemon *Daemon) containerStart(container *container.Container) (err error)
 	if copts != nil {
 		createOptions = append(createOptions, *copts...)
 	}
+	if checkpoint != "" {
+		createOptions = append(createOptions, libcontainerd.WithCheckpoint(checkpoint, container.CheckpointDir()))
+	}
 
+	tr.Begin("runtime")
 	if err := daemon.containerd.Create(container.ID, *spec, createOptions...); err != nil {
 		errDesc := grpc.ErrorDesc(err)
 		logrus.Errorf("Create container failed with error: %s", errDesc)
@@ -166,6 +210,25 @@ func (daemon *Daemon) containerStart(container *container.Container) (err error)
 	return nil
 }
 
+// startTraceAttributes returns the attributes of the start event of a
+// container started with trace tr: the duration of the start and of its
+// phases. The durations are recorded in the container_start metrics too.
+// It returns no attribute if the start was not traced, like the starts of
+// the restart manager.
+func startTraceAttributes(tr *trace.Trace) map[string]string {
+	attributes := map[string]string{}
+	if tr == nil {
+		return attributes
+	}
+	containerStartMetrics.Record(tr)
+
+	for _, s := range tr.Spans() {
+		attributes["span."+s.Name] = s.Duration.String()
+	}
+	attributes["duration"] = tr.Duration().String()
+	return attributes
+}
+
 // Cleanup releases any network resources allocated to the container along with any rules
 // around how containers are linked together.  It also unmounts the container's root filesystem.
 func (daemon *Daemon) Cleanup(container *container.Container) {
@@ -182,6 +245,7 @@ func (daemon *Daemon) Cleanup(container *container.Container) {
 	}
 
 	for _, eConfig := range container.ExecCommands.Commands() {
+		releaseExecCgroup(eConfig)
 		daemon.unregisterExecCommand(container, eConfig)
 	}
 
@@ -4,10 +4,12 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"io"
 	"runtime"
 
 	"golang.org/x/net/context"
 
+	"github.com/docker/docker/api/server/httputils"
 	"github.com/docker/docker/api/types/backend"
 	"github.com/docker/docker/container"
 	"github.com/docker/docker/pkg/ioutils"
@@ -32,7 +34,7 @@ func (daemon *Daemon) ContainerStats(ctx context.Context, prefixOrName string, c
 
 	// If the container is not running and requires no stream, return an empty stats.
 	if !container.IsRunning() && !config.Stream {
-		return json.NewEncoder(config.OutStream).Encode(&types.Stats{})
+		return newStatsEncoder(config.OutStream, config.Msgpack).Encode(&types.Stats{})
 	}
 
 	outStream := config.OutStream
@@ -51,7 +53,7 @@ func (daemon *Daemon) ContainerStats(ctx context.Context, prefixOrName string, c
 		return &ss
 	}
 
-	enc := json.NewEncoder(outStream)
+	enc := newStatsEncoder(outStream, config.Msgpack)
 
 	updates := daemon.subscribeToContainerStats(container)
 	defer daemon.unsubscribeToContainerStats(container, updates)
@@ -123,6 +125,18 @@ func (daemon *Daemon) ContainerStats(ctx context.Context, prefixOrName string, c
 	}
 }
 
+// statsEncoder encodes the stats documents sent to the clients.
+type statsEncoder interface {
+	Encode(v interface{}) error
+}
+
+func newStatsEncoder(w io.Writer, useMsgpack bool) statsEncoder {
+	if useMsgpack {
+		return httputils.NewMsgpackEncoder(w)
+	}
+	return json.NewEncoder(w)
+}
+
 func (daemon *Daemon) subscribeToContainerStats(c *container.Container) chan interface{} {
 	return daemon.statsCollector.collect(c)
 }
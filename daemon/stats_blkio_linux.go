@@ -0,0 +1,99 @@
+package daemon
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/docker/engine-api/types"
+	"github.com/opencontainers/runc/libcontainer/cgroups"
+)
+
+// blkioThrottleStats returns the number of bytes and of IOs transferred to
+// and from the block devices by the cgroup of the process with the given
+// pid, as accounted by the throttling policy. Unlike the stats of the CFQ
+// scheduler, they are available whichever IO scheduler the devices use.
+func blkioThrottleStats(pid int) ([]types.BlkioStatEntry, []types.BlkioStatEntry, error) {
+	paths, err := cgroups.ParseCgroupFile(fmt.Sprintf("/proc/%d/cgroup", pid))
+	if err != nil {
+		return nil, nil, err
+	}
+	path, ok := paths["blkio"]
+	if !ok {
+		return nil, nil, nil
+	}
+	mountpoint, root, err := cgroups.FindCgroupMountpointAndRoot("blkio")
+	if err != nil {
+		return nil, nil, err
+	}
+	rel, err := filepath.Rel(root, path)
+	if err != nil {
+		return nil, nil, err
+	}
+	dir := filepath.Join(mountpoint, rel)
+
+	serviceBytes, err := readBlkioStatFile(filepath.Join(dir, "blkio.throttle.io_service_bytes"))
+	if err != nil {
+		return nil, nil, err
+	}
+	serviced, err := readBlkioStatFile(filepath.Join(dir, "blkio.throttle.io_serviced"))
+	if err != nil {
+		return nil, nil, err
+	}
+	return serviceBytes, serviced, nil
+}
+
+func readBlkioStatFile(path string) ([]types.BlkioStatEntry, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	defer f.Close()
+	return parseBlkioStats(f)
+}
+
+// parseBlkioStats parses a blkio stat file, made of "major:minor op value"
+// lines and of a "Total value" line, which is skipped.
+func parseBlkioStats(r io.Reader) ([]types.BlkioStatEntry, error) {
+	var entries []types.BlkioStatEntry
+	s := bufio.NewScanner(r)
+	for s.Scan() {
+		fields := strings.Fields(s.Text())
+		if len(fields) == 0 || fields[0] == "Total" {
+			continue
+		}
+		if len(fields) != 3 {
+			return nil, fmt.Errorf("invalid blkio stat line %q", s.Text())
+		}
+		device := strings.Split(fields[0], ":")
+		if len(device) != 2 {
+			return nil, fmt.Errorf("invalid blkio stat device %q", fields[0])
+		}
+		major, err := strconv.ParseUint(device[0], 10, 64)
+		if err != nil {
+			return nil, err
+		}
+		minor, err := strconv.ParseUint(device[1], 10, 64)
+		if err != nil {
+			return nil, err
+		}
+		value, err := strconv.ParseUint(fields[2], 10, 64)
+		if err != nil {
+			return nil, err
+		}
+		entries = append(entries, types.BlkioStatEntry{
+			Major: major,
+			Minor: minor,
+			Op:    fields[1],
+			Value: value,
+		})
+	}
+	return entries, s.Err()
+}
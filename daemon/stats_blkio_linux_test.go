@@ -0,0 +1,44 @@
+package daemon
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/docker/engine-api/types"
+)
+
+func TestParseBlkioStats(t *testing.T) {
+	stats := `8:0 Read 4096
+8:0 Write 8192
+8:0 Sync 12288
+8:0 Async 0
+8:0 Total 12288
+Total 12288
+`
+	entries, err := parseBlkioStats(strings.NewReader(stats))
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := []types.BlkioStatEntry{
+		{Major: 8, Minor: 0, Op: "Read", Value: 4096},
+		{Major: 8, Minor: 0, Op: "Write", Value: 8192},
+		{Major: 8, Minor: 0, Op: "Sync", Value: 12288},
+		{Major: 8, Minor: 0, Op: "Async", Value: 0},
+		{Major: 8, Minor: 0, Op: "Total", Value: 12288},
+	}
+	if !reflect.DeepEqual(entries, expected) {
+		t.Fatalf("expected %v, got %v", expected, entries)
+	}
+
+	entries, err = parseBlkioStats(strings.NewReader("Total 0\n"))
+	if err != nil || len(entries) != 0 {
+		t.Fatalf("expected no entries, got %v (%v)", entries, err)
+	}
+
+	for _, invalid := range []string{"8:0 Read\n", "8 Read 1\n", "8:x Read 1\n", "8:0 Read -1\n"} {
+		if _, err := parseBlkioStats(strings.NewReader(invalid)); err == nil {
+			t.Fatalf("expected an error parsing %q", invalid)
+		}
+	}
+}
@@ -0,0 +1,11 @@
+// +build !linux
+
+package daemon
+
+import "github.com/docker/engine-api/types"
+
+// blkioThrottleStats returns no stats, since the blkio cgroup is only
+// supported on Linux.
+func blkioThrottleStats(pid int) ([]types.BlkioStatEntry, []types.BlkioStatEntry, error) {
+	return nil, nil, nil
+}
@@ -0,0 +1,85 @@
+package trace
+
+import (
+	"expvar"
+	"sync"
+	"time"
+)
+
+// TotalSpan is the name under which a Recorder accounts the whole
+// duration of the traces.
+const TotalSpan = "total"
+
+// Recorder aggregates the spans of the traces of an operation in counters
+// published through the daemon's /debug/vars endpoint.
+type Recorder struct {
+	mu    sync.Mutex
+	vars  *expvar.Map
+	stats map[string]*SpanStats
+}
+
+// SpanStats holds the counters of a span. The durations are in seconds.
+type SpanStats struct {
+	// Count counts the traces the span was recorded for.
+	Count expvar.Int
+	// Total is the time spent in the span by all traces.
+	Total expvar.Float
+	// Last is the duration of the span in the last trace.
+	Last expvar.Float
+	// Max is the longest duration of the span.
+	Max expvar.Float
+
+	max float64
+}
+
+// NewRecorder returns a recorder publishing its counters under name.
+func NewRecorder(name string) *Recorder {
+	return &Recorder{
+		vars:  expvar.NewMap(name),
+		stats: make(map[string]*SpanStats),
+	}
+}
+
+// Record adds the spans of t, and its duration as TotalSpan, to the
+// counters.
+func (r *Recorder) Record(t *Trace) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, s := range t.Spans() {
+		r.add(s.Name, s.Duration)
+	}
+	r.add(TotalSpan, t.Duration())
+}
+
+// Stats returns the counters of the span with the given name, or nil if it
+// was never recorded.
+func (r *Recorder) Stats(name string) *SpanStats {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return r.stats[name]
+}
+
+func (r *Recorder) add(name string, d time.Duration) {
+	s, ok := r.stats[name]
+	if !ok {
+		s = &SpanStats{}
+		m := new(expvar.Map).Init()
+		m.Set("count", &s.Count)
+		m.Set("total_seconds", &s.Total)
+		m.Set("last_seconds", &s.Last)
+		m.Set("max_seconds", &s.Max)
+		r.vars.Set(name, m)
+		r.stats[name] = s
+	}
+
+	seconds := d.Seconds()
+	s.Count.Add(1)
+	s.Total.Add(seconds)
+	s.Last.Set(seconds)
+	if seconds > s.max {
+		s.max = seconds
+		s.Max.Set(seconds)
+	}
+}
@@ -0,0 +1,79 @@
+// Package trace records how long the phases of an operation of the daemon,
+// like starting a container, take, to diagnose the slow ones.
+package trace
+
+import (
+	"sync"
+	"time"
+)
+
+// Span is a phase of a traced operation.
+type Span struct {
+	Name     string
+	Duration time.Duration
+}
+
+// Trace records the spans of an operation. The spans may be begun and
+// ended from different goroutines.
+type Trace struct {
+	mu      sync.Mutex
+	start   time.Time
+	begun   map[string]time.Time
+	spans   []Span
+	indexes map[string]int
+}
+
+// New returns a trace of an operation starting now.
+func New() *Trace {
+	return &Trace{
+		start:   time.Now(),
+		begun:   make(map[string]time.Time),
+		indexes: make(map[string]int),
+	}
+}
+
+// Begin begins the span with the given name. Beginning a span again
+// restarts it.
+func (t *Trace) Begin(name string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.begun[name] = time.Now()
+	if _, ok := t.indexes[name]; !ok {
+		t.indexes[name] = len(t.spans)
+		t.spans = append(t.spans, Span{Name: name})
+	}
+}
+
+// End ends the span with the given name. Ending a span which was not begun
+// does nothing.
+func (t *Trace) End(name string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	begun, ok := t.begun[name]
+	if !ok {
+		return
+	}
+	delete(t.begun, name)
+	t.spans[t.indexes[name]].Duration = time.Since(begun)
+}
+
+// Spans returns the ended spans, in the order they were begun.
+func (t *Trace) Spans() []Span {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	spans := make([]Span, 0, len(t.spans))
+	for _, s := range t.spans {
+		if _, running := t.begun[s.Name]; !running {
+			spans = append(spans, s)
+		}
+	}
+	return spans
+}
+
+// Duration returns the time elapsed since the operation started.
+func (t *Trace) Duration() time.Duration {
+	return time.Since(t.start)
+}
@@ -0,0 +1,84 @@
+package trace
+
+import (
+	"expvar"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestTraceSpans(t *testing.T) {
+	tr := New()
+	tr.Begin("mount")
+	tr.Begin("network")
+	time.Sleep(10 * time.Millisecond)
+	tr.End("network")
+	tr.End("mount")
+	tr.Begin("runtime")
+	tr.End("unknown")
+
+	spans := tr.Spans()
+	if len(spans) != 2 {
+		t.Fatalf("expected the 2 ended spans, got %v", spans)
+	}
+	if spans[0].Name != "mount" || spans[1].Name != "network" {
+		t.Fatalf("expected the spans in the order they were begun, got %v", spans)
+	}
+	if spans[1].Duration < 10*time.Millisecond || spans[0].Duration < spans[1].Duration {
+		t.Fatalf("unexpected span durations: %v", spans)
+	}
+	if tr.Duration() < spans[0].Duration {
+		t.Fatalf("expected the trace to last longer than its spans, got %s", tr.Duration())
+	}
+
+	tr.End("runtime")
+	if spans := tr.Spans(); len(spans) != 3 || spans[2].Name != "runtime" {
+		t.Fatalf("expected the runtime span to be ended, got %v", spans)
+	}
+}
+
+func TestRecorder(t *testing.T) {
+	r := NewRecorder("trace_test")
+	if r.Stats("mount") != nil {
+		t.Fatal("expected no counters before recording a trace")
+	}
+
+	for _, d := range []time.Duration{30 * time.Millisecond, 10 * time.Millisecond} {
+		tr := New()
+		tr.Begin("mount")
+		time.Sleep(d)
+		tr.End("mount")
+		r.Record(tr)
+	}
+
+	s := r.Stats("mount")
+	if s == nil {
+		t.Fatal("expected counters for the mount span")
+	}
+	if s.Count.String() != "2" {
+		t.Fatalf("expected 2 recorded spans, got %s", s.Count.String())
+	}
+	last, max, total := seconds(t, &s.Last), seconds(t, &s.Max), seconds(t, &s.Total)
+	if max < 0.03 || last >= max || total < 0.04 {
+		t.Fatalf("unexpected durations: last %f, max %f, total %f", last, max, total)
+	}
+	if total := r.Stats(TotalSpan); total == nil || total.Count.String() != "2" {
+		t.Fatal("expected the durations of the traces to be recorded")
+	}
+
+	published := expvar.Get("trace_test").String()
+	for _, key := range []string{`"mount"`, `"total"`, `"count": 2`, `"max_seconds"`} {
+		if !strings.Contains(published, key) {
+			t.Fatalf("expected %s in the published counters, got %s", key, published)
+		}
+	}
+}
+
+func seconds(t *testing.T, f *expvar.Float) float64 {
+	v, err := strconv.ParseFloat(f.String(), 64)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return v
+}
@@ -7,6 +7,7 @@ import (
 	"path/filepath"
 	"strings"
 
+	"github.com/Sirupsen/logrus"
 	"github.com/docker/docker/container"
 	"github.com/docker/docker/volume"
 	"github.com/docker/engine-api/types"
@@ -34,9 +35,32 @@ func volumeToAPIType(v volume.Volume) *types.Volume {
 	if v, ok := v.(volume.ScopedVolume); ok {
 		tv.Scope = v.Scope()
 	}
+
+	if v, ok := v.(volume.ConfiguredVolume); ok {
+		tv.Options = v.Options()
+	}
 	return tv
 }
 
+// volumeUsageData returns the space used by the volume v, when its driver
+// can report it, and the number of containers referencing it.
+func (daemon *Daemon) volumeUsageData(v volume.Volume) *types.VolumeUsageData {
+	ud := &types.VolumeUsageData{
+		Size:     -1,
+		Capacity: -1,
+		RefCount: len(daemon.volumes.Refs(v)),
+	}
+	if v, ok := v.(volume.UsageVolume); ok {
+		size, capacity, err := v.Usage()
+		if err != nil {
+			logrus.Debugf("Failed to get the usage of volume %s: %v", v.Name(), err)
+		} else {
+			ud.Size, ud.Capacity = size, capacity
+		}
+	}
+	return ud
+}
+
 // Len returns the number of mounts. Used in sorting.
 func (m mounts) Len() int {
 	return len(m)
@@ -1,9 +1,14 @@
 package daemon
 
 import (
+	"fmt"
+	"net/http"
 	"time"
 
 	"golang.org/x/net/context"
+
+	"github.com/docker/docker/errors"
+	containertypes "github.com/docker/engine-api/types/container"
 )
 
 // ContainerWait stops processing until the given container is
@@ -20,6 +25,37 @@ func (daemon *Daemon) ContainerWait(name string, timeout time.Duration) (int, er
 	return container.WaitStop(timeout)
 }
 
+// ContainerWaitCondition waits until the given container meets condition,
+// not-running by default, and returns its exit code. The wait stops with an
+// error when ctx is canceled, or after timeout unless it is negative.
+func (daemon *Daemon) ContainerWaitCondition(ctx context.Context, name string, condition containertypes.WaitCondition, timeout time.Duration) (int, error) {
+	switch condition {
+	case "":
+		condition = containertypes.WaitConditionNotRunning
+	case containertypes.WaitConditionNotRunning, containertypes.WaitConditionNextExit, containertypes.WaitConditionRemoved:
+	default:
+		return -1, errors.NewBadRequestError(fmt.Errorf("Invalid wait condition %q: expected %s, %s or %s", condition,
+			containertypes.WaitConditionNotRunning, containertypes.WaitConditionNextExit, containertypes.WaitConditionRemoved))
+	}
+
+	container, err := daemon.GetContainer(name)
+	if err != nil {
+		return -1, err
+	}
+
+	waitCtx := ctx
+	if timeout >= 0 {
+		var cancel context.CancelFunc
+		waitCtx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+	exitCode, err := container.WaitCondition(waitCtx, condition)
+	if err != nil && ctx.Err() == nil {
+		err = errors.NewErrorWithStatusCode(fmt.Errorf("Timed out after %v waiting for container %s to meet condition %s", timeout, name, condition), http.StatusRequestTimeout)
+	}
+	return exitCode, err
+}
+
 // ContainerWaitWithContext returns a channel where exit code is sent
 // when container stops. Channel can be cancelled with a context.
 func (daemon *Daemon) ContainerWaitWithContext(ctx context.Context, name string) error {
@@ -0,0 +1,44 @@
+package daemon
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"golang.org/x/net/context"
+
+	"github.com/docker/docker/container"
+	"github.com/docker/docker/pkg/registrar"
+	"github.com/docker/docker/pkg/truncindex"
+	containertypes "github.com/docker/engine-api/types/container"
+)
+
+func TestContainerWaitCondition(t *testing.T) {
+	c := container.NewBaseContainer("0123456789", "")
+	c.Name = "/web"
+	c.State.SetRunning(1, false)
+	daemon := &Daemon{
+		containers: container.NewMemoryStore(),
+		idIndex:    truncindex.NewTruncIndex(nil),
+		nameIndex:  registrar.NewRegistrar(),
+	}
+	daemon.containers.Add(c.ID, c)
+	daemon.idIndex.Add(c.ID)
+
+	if _, err := daemon.ContainerWaitCondition(context.Background(), c.ID, "stopped", -1); err == nil {
+		t.Fatal("expected an error for an invalid condition")
+	}
+
+	_, err := daemon.ContainerWaitCondition(context.Background(), c.ID, "", 10*time.Millisecond)
+	statusErr, ok := err.(interface {
+		HTTPErrorStatusCode() int
+	})
+	if !ok || statusErr.HTTPErrorStatusCode() != http.StatusRequestTimeout {
+		t.Fatalf("expected a timeout, got %v", err)
+	}
+
+	c.SetStoppedLocking(&container.ExitStatus{ExitCode: 1})
+	if exitCode, err := daemon.ContainerWaitCondition(context.Background(), c.ID, containertypes.WaitConditionNotRunning, 0); err != nil || exitCode != 1 {
+		t.Fatalf("expected the exit code 1 of the stopped container, got %d, %v", exitCode, err)
+	}
+}
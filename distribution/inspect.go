@@ -0,0 +1,337 @@
+package distribution
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/Sirupsen/logrus"
+	"github.com/docker/distribution"
+	"github.com/docker/distribution/digest"
+	"github.com/docker/distribution/manifest/manifestlist"
+	"github.com/docker/distribution/manifest/schema1"
+	"github.com/docker/distribution/manifest/schema2"
+	"github.com/docker/docker/image"
+	"github.com/docker/docker/image/v1"
+	"github.com/docker/docker/reference"
+	"github.com/docker/docker/registry"
+	"github.com/docker/engine-api/types"
+	"golang.org/x/net/context"
+)
+
+// ImageInspectConfig stores configuration for inspecting an image in a
+// registry without pulling it.
+type ImageInspectConfig struct {
+	// MetaHeaders stores HTTP headers with metadata about the image
+	MetaHeaders map[string][]string
+	// AuthConfig holds authentication credentials for authenticating with
+	// the registry.
+	AuthConfig *types.AuthConfig
+	// RegistryService is the registry service to use for TLS configuration
+	// and endpoint lookup.
+	RegistryService registry.Service
+	// Platform selects the image of a manifest list. The platform of the
+	// daemon is used if it is empty.
+	Platform Platform
+}
+
+// RemoteImage is an image inspected in a registry.
+type RemoteImage struct {
+	// ID is the ID the image gets once pulled. It is empty for images
+	// with a schema1 manifest, whose ID depends on their layer contents.
+	ID image.ID
+	// Digest is the digest of the manifest, or manifest list, the
+	// reference points to.
+	Digest digest.Digest
+	// Image is the configuration of the image.
+	Image *image.Image
+	// Size is the total size of the compressed layers of the image.
+	Size int64
+	// LayerSizes are the sizes of the compressed layers of the image, from
+	// the base layer, one for each entry of the history of the image
+	// which is not an empty layer.
+	LayerSizes []int64
+}
+
+// Manifest is the raw manifest, or manifest list, of an image in a
+// registry.
+type Manifest struct {
+	// Digest is the digest of the manifest.
+	Digest digest.Digest
+	// MediaType is the media type of the manifest.
+	MediaType string
+	// Payload is the manifest as served by the registry.
+	Payload []byte
+}
+
+// Inspect fetches the manifest and configuration of the image ref points to
+// in its registry, without pulling the layers of the image. Only v2
+// registries are supported.
+func Inspect(ctx context.Context, ref reference.Named, config *ImageInspectConfig) (*RemoteImage, error) {
+	var img *RemoteImage
+	err := inspectEndpoints(ctx, ref, config, func(repoInfo *registry.RepositoryInfo, endpoint registry.APIEndpoint) (err error) {
+		img, err = inspectV2(ctx, ref, repoInfo, endpoint, config)
+		return err
+	})
+	return img, err
+}
+
+// InspectManifest fetches the manifest, or manifest list, ref points to in
+// its registry, as served by the registry. Only v2 registries are supported.
+func InspectManifest(ctx context.Context, ref reference.Named, config *ImageInspectConfig) (*Manifest, error) {
+	var m *Manifest
+	err := inspectEndpoints(ctx, ref, config, func(repoInfo *registry.RepositoryInfo, endpoint registry.APIEndpoint) error {
+		repo, confirmedV2, err := NewV2Repository(ctx, repoInfo, endpoint, config.MetaHeaders, config.AuthConfig, "pull")
+		if err != nil {
+			return err
+		}
+		manifest, err := getManifest(ctx, repo, ref)
+		if err != nil {
+			if continueOnError(err) {
+				return fallbackError{err: err, confirmedV2: confirmedV2, transportOK: true}
+			}
+			return err
+		}
+		m, err = rawManifest(ref, manifest)
+		return err
+	})
+	return m, err
+}
+
+// inspectEndpoints calls inspect with the v2 endpoints of the registry of
+// ref, until it succeeds or fails with an error other than a fallbackError.
+func inspectEndpoints(ctx context.Context, ref reference.Named, config *ImageInspectConfig, inspect func(*registry.RepositoryInfo, registry.APIEndpoint) error) error {
+	repoInfo, err := config.RegistryService.ResolveRepository(ref)
+	if err != nil {
+		return err
+	}
+	if err := ValidateRepoName(repoInfo.Name()); err != nil {
+		return err
+	}
+
+	endpoints, err := config.RegistryService.LookupPullEndpoints(repoInfo.Hostname())
+	if err != nil {
+		return err
+	}
+
+	var lastErr error
+	for _, endpoint := range endpoints {
+		if endpoint.Version == registry.APIVersion1 {
+			continue
+		}
+
+		logrus.Debugf("Trying to inspect %s in %s %s", repoInfo.Name(), endpoint.URL, endpoint.Version)
+
+		err := inspect(repoInfo, endpoint)
+		if err == nil {
+			return nil
+		}
+		fallbackErr, ok := err.(fallbackError)
+		if !ok {
+			return err
+		}
+		select {
+		case <-ctx.Done():
+			return fallbackErr.err
+		default:
+		}
+		lastErr = fallbackErr.err
+		logrus.Errorf("Attempting next endpoint for inspect after error: %v", lastErr)
+	}
+
+	if lastErr == nil {
+		lastErr = fmt.Errorf("no v2 endpoints found for %s", ref.String())
+	}
+	return lastErr
+}
+
+// rawManifest returns manifest as served by the registry, after checking
+// its digest if ref has one.
+func rawManifest(ref reference.Named, manifest distribution.Manifest) (*Manifest, error) {
+	mediaType, payload, err := manifest.Payload()
+	if err != nil {
+		return nil, err
+	}
+
+	var manifestDigest digest.Digest
+	if v, ok := manifest.(*schema1.SignedManifest); ok {
+		if _, err := verifySchema1Manifest(v, ref); err != nil {
+			return nil, err
+		}
+		manifestDigest = digest.FromBytes(v.Canonical)
+	} else if manifestDigest, err = schema2ManifestDigest(ref, manifest); err != nil {
+		return nil, err
+	}
+
+	return &Manifest{
+		Digest:    manifestDigest,
+		MediaType: mediaType,
+		Payload:   payload,
+	}, nil
+}
+
+func inspectV2(ctx context.Context, ref reference.Named, repoInfo *registry.RepositoryInfo, endpoint registry.APIEndpoint, config *ImageInspectConfig) (*RemoteImage, error) {
+	repo, confirmedV2, err := NewV2Repository(ctx, repoInfo, endpoint, config.MetaHeaders, config.AuthConfig, "pull")
+	if err != nil {
+		return nil, err
+	}
+
+	manifest, err := getManifest(ctx, repo, ref)
+	if err != nil {
+		if continueOnError(err) {
+			return nil, fallbackError{err: err, confirmedV2: confirmedV2, transportOK: true}
+		}
+		return nil, err
+	}
+
+	if mfstList, ok := manifest.(*manifestlist.DeserializedManifestList); ok {
+		manifestListDigest, err := schema2ManifestDigest(ref, mfstList)
+		if err != nil {
+			return nil, err
+		}
+
+		platform := config.Platform
+		if platform == (Platform{}) {
+			platform = DefaultPlatform()
+		}
+		var (
+			manifestDigest digest.Digest
+			available      []string
+		)
+		for _, manifestDescriptor := range mfstList.Manifests {
+			if platform.matches(manifestDescriptor.Platform) {
+				manifestDigest = manifestDescriptor.Digest
+				break
+			}
+			available = append(available, platformOfSpec(manifestDescriptor.Platform).String())
+		}
+		if manifestDigest == "" {
+			return nil, fmt.Errorf("no matching manifest for %s in the manifest list entries (available: %s)", platform, strings.Join(available, ", "))
+		}
+
+		ref, err = reference.WithDigest(ref, manifestDigest)
+		if err != nil {
+			return nil, err
+		}
+		manifest, err = getManifest(ctx, repo, ref)
+		if err != nil {
+			return nil, err
+		}
+		img, err := inspectManifest(ctx, repo, ref, manifest)
+		if err != nil {
+			return nil, err
+		}
+		img.Digest = manifestListDigest
+		return img, nil
+	}
+
+	return inspectManifest(ctx, repo, ref, manifest)
+}
+
+// getManifest fetches the manifest ref points to.
+func getManifest(ctx context.Context, repo distribution.Repository, ref reference.Named) (distribution.Manifest, error) {
+	manSvc, err := repo.Manifests(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var manifest distribution.Manifest
+	if digested, isDigested := ref.(reference.Canonical); isDigested {
+		manifest, err = manSvc.Get(ctx, digested.Digest())
+	} else if tagged, isTagged := ref.(reference.NamedTagged); isTagged {
+		manifest, err = manSvc.Get(ctx, "", distribution.WithTag(tagged.Tag()))
+	} else {
+		return nil, fmt.Errorf("internal error: reference has neither a tag nor a digest: %s", ref.String())
+	}
+	if err != nil {
+		return nil, err
+	}
+	if manifest == nil {
+		return nil, fmt.Errorf("image manifest does not exist for %s", ref.String())
+	}
+	return manifest, nil
+}
+
+// inspectManifest returns the image of a schema1 or schema2 manifest.
+func inspectManifest(ctx context.Context, repo distribution.Repository, ref reference.Named, manifest distribution.Manifest) (*RemoteImage, error) {
+	switch v := manifest.(type) {
+	case *schema1.SignedManifest:
+		verifiedManifest, err := verifySchema1Manifest(v, ref)
+		if err != nil {
+			return nil, err
+		}
+
+		var (
+			v1Image    image.V1Image
+			size       int64
+			history    []image.History
+			layerSizes []int64
+		)
+		for i := len(verifiedManifest.History) - 1; i >= 0; i-- {
+			v1Image = image.V1Image{}
+			v1Compatibility := []byte(verifiedManifest.History[i].V1Compatibility)
+			if err := json.Unmarshal(v1Compatibility, &v1Image); err != nil {
+				return nil, err
+			}
+			var throwAway struct {
+				ThrowAway bool `json:"throwaway,omitempty"`
+			}
+			if err := json.Unmarshal(v1Compatibility, &throwAway); err != nil {
+				return nil, err
+			}
+			h, err := v1.HistoryFromConfig(v1Compatibility, throwAway.ThrowAway)
+			if err != nil {
+				return nil, err
+			}
+			history = append(history, h)
+			if !throwAway.ThrowAway {
+				layerSizes = append(layerSizes, v1Image.Size)
+			}
+			size += v1Image.Size
+		}
+		// The IDs of the v1 history are not the IDs of the images once
+		// pulled.
+		v1Image.ID = ""
+		v1Image.Parent = ""
+		v1Image.Size = 0
+		return &RemoteImage{
+			Digest:     digest.FromBytes(v.Canonical),
+			Image:      &image.Image{V1Image: v1Image, RootFS: image.NewRootFS(), History: history},
+			Size:       size,
+			LayerSizes: layerSizes,
+		}, nil
+	case *schema2.DeserializedManifest:
+		manifestDigest, err := schema2ManifestDigest(ref, v)
+		if err != nil {
+			return nil, err
+		}
+
+		p := &v2Puller{repo: repo}
+		configJSON, err := p.pullSchema2ImageConfig(ctx, v.Target().Digest)
+		if err != nil {
+			return nil, ImageConfigPullError{Err: err}
+		}
+		img, err := image.NewFromJSON(configJSON)
+		if err != nil {
+			return nil, err
+		}
+
+		var (
+			size       int64
+			layerSizes []int64
+		)
+		for _, l := range v.Layers {
+			size += l.Size
+			layerSizes = append(layerSizes, l.Size)
+		}
+		return &RemoteImage{
+			ID:         image.ID(v.Target().Digest),
+			Digest:     manifestDigest,
+			Image:      img,
+			Size:       size,
+			LayerSizes: layerSizes,
+		}, nil
+	}
+	return nil, errors.New("unsupported manifest format")
+}
@@ -0,0 +1,212 @@
+package distribution
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/docker/distribution"
+	"github.com/docker/distribution/digest"
+	"github.com/docker/distribution/manifest/schema2"
+	"github.com/docker/docker/reference"
+	"github.com/docker/docker/registry"
+	"github.com/docker/engine-api/types"
+	"golang.org/x/net/context"
+)
+
+const inspectTestConfig = `{"architecture":"amd64","os":"linux","created":"2016-06-01T00:00:00Z","config":{"Cmd":["/bin/sh"]},"rootfs":{"type":"layers","diff_ids":["sha256:5f70bf18a086007016e948b04aed3b82103a36bea41755b6cddfaf10ace3c6ef"]}}`
+
+func newInspectTestRegistry(t *testing.T) (*httptest.Server, digest.Digest, digest.Digest) {
+	configDigest := digest.FromBytes([]byte(inspectTestConfig))
+	manifest, err := schema2.FromStruct(schema2.Manifest{
+		Versioned: schema2.SchemaVersion,
+		Config: distribution.Descriptor{
+			MediaType: schema2.MediaTypeConfig,
+			Size:      int64(len(inspectTestConfig)),
+			Digest:    configDigest,
+		},
+		Layers: []distribution.Descriptor{
+			{MediaType: schema2.MediaTypeLayer, Size: 100, Digest: digest.FromBytes([]byte("layer1"))},
+			{MediaType: schema2.MediaTypeLayer, Size: 200, Digest: digest.FromBytes([]byte("layer2"))},
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, manifestJSON, err := manifest.Payload()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/v2/", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Docker-Distribution-API-Version", "registry/2.0")
+		if r.URL.Path != "/v2/" {
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusNotFound)
+			w.Write([]byte(`{"errors":[{"code":"MANIFEST_UNKNOWN","message":"manifest unknown"}]}`))
+		}
+	})
+	mux.HandleFunc("/v2/test/manifests/latest", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", schema2.MediaTypeManifest)
+		w.Write(manifestJSON)
+	})
+	mux.HandleFunc(fmt.Sprintf("/v2/test/blobs/%s", configDigest), func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(inspectTestConfig))
+	})
+	return httptest.NewServer(mux), configDigest, digest.FromBytes(manifestJSON)
+}
+
+func TestInspect(t *testing.T) {
+	ts, configDigest, manifestDigest := newInspectTestRegistry(t)
+	defer ts.Close()
+
+	u, err := url.Parse(ts.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ref, err := reference.ParseNamed(u.Host + "/test:latest")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	img, err := Inspect(context.Background(), ref, &ImageInspectConfig{
+		AuthConfig:      &types.AuthConfig{},
+		RegistryService: registry.NewService(registry.ServiceOptions{InsecureRegistries: []string{u.Host}}),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if img.ID.String() != configDigest.String() {
+		t.Fatalf("expected ID %s, got %s", configDigest, img.ID)
+	}
+	if img.Digest != manifestDigest {
+		t.Fatalf("expected digest %s, got %s", manifestDigest, img.Digest)
+	}
+	if img.Size != 300 {
+		t.Fatalf("expected the size of the layers, got %d", img.Size)
+	}
+	if len(img.LayerSizes) != 2 || img.LayerSizes[0] != 100 || img.LayerSizes[1] != 200 {
+		t.Fatalf("expected the sizes of the layers from the base layer, got %v", img.LayerSizes)
+	}
+	if img.Image.Architecture != "amd64" || img.Image.Config == nil || len(img.Image.Config.Cmd) != 1 {
+		config, _ := json.Marshal(img.Image)
+		t.Fatalf("unexpected image configuration: %s", config)
+	}
+
+	missing, err := reference.ParseNamed(u.Host + "/test:missing")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Inspect(context.Background(), missing, &ImageInspectConfig{
+		AuthConfig:      &types.AuthConfig{},
+		RegistryService: registry.NewService(registry.ServiceOptions{InsecureRegistries: []string{u.Host}}),
+	}); err == nil {
+		t.Fatal("expected inspecting a missing tag to fail")
+	}
+}
+
+func TestInspectManifest(t *testing.T) {
+	ts, _, manifestDigest := newInspectTestRegistry(t)
+	defer ts.Close()
+
+	u, err := url.Parse(ts.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ref, err := reference.ParseNamed(u.Host + "/test:latest")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	m, err := InspectManifest(context.Background(), ref, &ImageInspectConfig{
+		AuthConfig:      &types.AuthConfig{},
+		RegistryService: registry.NewService(registry.ServiceOptions{InsecureRegistries: []string{u.Host}}),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if m.Digest != manifestDigest {
+		t.Fatalf("expected digest %s, got %s", manifestDigest, m.Digest)
+	}
+	if m.MediaType != schema2.MediaTypeManifest {
+		t.Fatalf("expected media type %s, got %s", schema2.MediaTypeManifest, m.MediaType)
+	}
+	if digest.FromBytes(m.Payload) != manifestDigest {
+		t.Fatalf("expected the manifest as served by the registry, got %s", m.Payload)
+	}
+}
+
+func TestInspectIdentityToken(t *testing.T) {
+	ts, configDigest, _ := newInspectTestRegistry(t)
+	defer ts.Close()
+
+	tokenServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		r.ParseForm()
+		if r.Method != "POST" || r.PostForm.Get("grant_type") != "refresh_token" || r.PostForm.Get("refresh_token") != "identity-token" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"access_token": "access-token", "expires_in": 300}`))
+	}))
+	defer tokenServer.Close()
+
+	registryServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer access-token" {
+			w.Header().Set("Docker-Distribution-API-Version", "registry/2.0")
+			w.Header().Set("WWW-Authenticate", fmt.Sprintf(`Bearer realm="%s/token",service="inspect-test"`, tokenServer.URL))
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		// proxy to the registry serving the image
+		resp, err := http.Get(ts.URL + r.URL.Path)
+		if err != nil {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		defer resp.Body.Close()
+		for k, v := range resp.Header {
+			w.Header()[k] = v
+		}
+		w.WriteHeader(resp.StatusCode)
+		io.Copy(w, resp.Body)
+	}))
+	defer registryServer.Close()
+
+	u, err := url.Parse(registryServer.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ref, err := reference.ParseNamed(u.Host + "/test:latest")
+	if err != nil {
+		t.Fatal(err)
+	}
+	service := registry.NewService(registry.ServiceOptions{InsecureRegistries: []string{u.Host}})
+
+	img, err := Inspect(context.Background(), ref, &ImageInspectConfig{
+		AuthConfig:      &types.AuthConfig{IdentityToken: "identity-token"},
+		RegistryService: service,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if img.ID.String() != configDigest.String() {
+		t.Fatalf("expected ID %s, got %s", configDigest, img.ID)
+	}
+
+	_, err = Inspect(context.Background(), ref, &ImageInspectConfig{
+		AuthConfig:      &types.AuthConfig{IdentityToken: "revoked-token"},
+		RegistryService: service,
+	})
+	if err == nil || !strings.Contains(err.Error(), "unauthorized") {
+		t.Fatalf("expected the inspect with a wrong identity token to be unauthorized, got %v", err)
+	}
+}
@@ -0,0 +1,55 @@
+package metadata
+
+import (
+	"fmt"
+
+	"github.com/docker/distribution/digest"
+)
+
+// ManifestService keeps the manifests images were pulled with, so that the
+// local content of an image can be verified against the digest it is
+// referred to by.
+type ManifestService struct {
+	store Store
+}
+
+// NewManifestService creates a new digest to manifest mapping service.
+func NewManifestService(store Store) *ManifestService {
+	return &ManifestService{
+		store: store,
+	}
+}
+
+func (serv *ManifestService) namespace() string {
+	return "manifest-by-digest"
+}
+
+func (serv *ManifestService) key(dgst digest.Digest) string {
+	return string(dgst.Algorithm()) + "/" + dgst.Hex()
+}
+
+// Get returns the manifest of digest dgst, after checking that its content
+// still matches the digest.
+func (serv *ManifestService) Get(dgst digest.Digest) ([]byte, error) {
+	verifier, err := digest.NewDigestVerifier(dgst)
+	if err != nil {
+		return nil, err
+	}
+	manifest, err := serv.store.Get(serv.namespace(), serv.key(dgst))
+	if err != nil {
+		return nil, err
+	}
+	verifier.Write(manifest)
+	if !verifier.Verified() {
+		return nil, fmt.Errorf("the manifest kept for %s does not match its digest", dgst)
+	}
+	return manifest, nil
+}
+
+// Set keeps manifest as the manifest of digest dgst.
+func (serv *ManifestService) Set(dgst digest.Digest, manifest []byte) error {
+	if err := dgst.Validate(); err != nil {
+		return err
+	}
+	return serv.store.Set(serv.namespace(), serv.key(dgst), manifest)
+}
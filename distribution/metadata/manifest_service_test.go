@@ -0,0 +1,49 @@
+package metadata
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/docker/distribution/digest"
+)
+
+func TestManifestService(t *testing.T) {
+	tmpDir, err := ioutil.TempDir("", "manifest-service-test")
+	if err != nil {
+		t.Fatalf("could not create temp dir: %v", err)
+	}
+	defer os.RemoveAll(tmpDir)
+
+	metadataStore, err := NewFSMetadataStore(tmpDir)
+	if err != nil {
+		t.Fatalf("could not create metadata store: %v", err)
+	}
+	manifestService := NewManifestService(metadataStore)
+
+	manifest := []byte(`{"schemaVersion": 2}`)
+	dgst := digest.FromBytes(manifest)
+	if _, err := manifestService.Get(dgst); err == nil {
+		t.Fatal("expected an error getting a manifest which was not set")
+	}
+	if err := manifestService.Set(dgst, manifest); err != nil {
+		t.Fatalf("error calling Set: %v", err)
+	}
+	got, err := manifestService.Get(dgst)
+	if err != nil {
+		t.Fatalf("error calling Get: %v", err)
+	}
+	if string(got) != string(manifest) {
+		t.Fatalf("expected %s, got %s", manifest, got)
+	}
+
+	// A manifest altered on disk no longer matches its digest
+	path := filepath.Join(tmpDir, "manifest-by-digest", string(dgst.Algorithm()), dgst.Hex())
+	if err := ioutil.WriteFile(path, []byte(`{"schemaVersion": 1}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := manifestService.Get(dgst); err == nil {
+		t.Fatal("expected an error getting an altered manifest")
+	}
+}
@@ -0,0 +1,452 @@
+// Package mirror serves the images of a daemon through a read-only registry
+// API, so that the daemons of other hosts can use it as a registry mirror.
+package mirror
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"mime"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Sirupsen/logrus"
+	"github.com/docker/distribution"
+	"github.com/docker/distribution/digest"
+	"github.com/docker/distribution/manifest/schema2"
+	"github.com/docker/distribution/registry/api/errcode"
+	"github.com/docker/distribution/registry/api/v2"
+	"github.com/docker/docker/image"
+	"github.com/docker/docker/layer"
+	"github.com/docker/docker/pkg/ioutils"
+	"github.com/docker/docker/reference"
+	"github.com/gorilla/mux"
+	"golang.org/x/net/context"
+)
+
+const (
+	// defaultPullTimeout is how long a tag missing from the stores is
+	// pulled for at most.
+	defaultPullTimeout = 10 * time.Minute
+	// defaultMaxConcurrentPulls is how many tags are pulled at once at
+	// most. Requests for other missing tags are refused meanwhile.
+	defaultMaxConcurrentPulls = 3
+)
+
+// Config holds the stores images are served from.
+type Config struct {
+	ImageStore     image.Store
+	LayerStore     image.LayerGetReleaser
+	ReferenceStore reference.Store
+	// Pull fetches a tag missing from the stores. Only the images already
+	// in the stores are served if it is nil.
+	Pull func(ctx context.Context, ref reference.Named) error
+	// PullTimeout bounds the time a pull takes, and MaxConcurrentPulls the
+	// number of pulls running at once. The defaults are used if they are
+	// not set.
+	PullTimeout        time.Duration
+	MaxConcurrentPulls int
+}
+
+// manifest is a schema2 manifest generated for an image.
+type manifest struct {
+	digest  digest.Digest
+	payload []byte
+}
+
+type server struct {
+	config Config
+
+	// pulls holds a token for each pull running.
+	pulls chan struct{}
+
+	mu sync.Mutex
+	// manifests caches the manifests generated for images.
+	manifests map[image.ID]manifest
+	// layerSizes caches the sizes of the tar streams of the layers.
+	layerSizes map[layer.ChainID]int64
+}
+
+// NewHandler returns an http.Handler serving the images of the stores
+// through the read-only subset of the registry API used for pulls.
+//
+// Manifests are generated from the local images and only use the schema2
+// format, so their digests differ from the digests of the manifests the
+// images were pulled with. Layers are served uncompressed, their digest
+// being their DiffID; pulls detect the compression of layers, so the usual
+// media type is used for them. The size of the tar stream of a layer is not
+// kept by the layer store, so the stream is read once to measure it the
+// first time the layer is served or listed in a manifest.
+func NewHandler(config Config) http.Handler {
+	if config.PullTimeout == 0 {
+		config.PullTimeout = defaultPullTimeout
+	}
+	if config.MaxConcurrentPulls == 0 {
+		config.MaxConcurrentPulls = defaultMaxConcurrentPulls
+	}
+	s := &server{
+		config:     config,
+		pulls:      make(chan struct{}, config.MaxConcurrentPulls),
+		manifests:  make(map[image.ID]manifest),
+		layerSizes: make(map[layer.ChainID]int64),
+	}
+
+	router := v2.Router()
+	router.Get(v2.RouteNameBase).Handler(s.handler(s.getBase))
+	router.Get(v2.RouteNameManifest).Handler(s.handler(s.getManifest))
+	router.Get(v2.RouteNameTags).Handler(s.handler(s.getTags))
+	router.Get(v2.RouteNameBlob).Handler(s.handler(s.getBlob))
+	for _, name := range []string{v2.RouteNameBlobUpload, v2.RouteNameBlobUploadChunk, v2.RouteNameCatalog} {
+		router.Get(name).Handler(s.handler(unsupported))
+	}
+	return router
+}
+
+// handler wraps a read-only handler of the API.
+func (s *server) handler(h func(w http.ResponseWriter, r *http.Request) error) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Docker-Distribution-API-Version", "registry/2.0")
+		if r.Method != "GET" && r.Method != "HEAD" {
+			errcode.ServeJSON(w, errcode.ErrorCodeUnsupported)
+			return
+		}
+		if err := h(w, r); err != nil {
+			logrus.Debugf("registry cache: %s %s: %v", r.Method, r.URL.Path, err)
+			errcode.ServeJSON(w, err)
+		}
+	})
+}
+
+func unsupported(w http.ResponseWriter, r *http.Request) error {
+	return errcode.ErrorCodeUnsupported
+}
+
+func (s *server) getBase(w http.ResponseWriter, r *http.Request) error {
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.Header().Set("Content-Length", "2")
+	if r.Method == "GET" {
+		io.WriteString(w, "{}")
+	}
+	return nil
+}
+
+func repository(r *http.Request) (reference.Named, error) {
+	named, err := reference.ParseNamed(mux.Vars(r)["name"])
+	if err != nil {
+		return nil, v2.ErrorCodeNameInvalid.WithDetail(err.Error())
+	}
+	return named, nil
+}
+
+func (s *server) getManifest(w http.ResponseWriter, r *http.Request) error {
+	named, err := repository(r)
+	if err != nil {
+		return err
+	}
+	if !acceptsSchema2(r) {
+		return v2.ErrorCodeManifestUnknown.WithDetail("only schema2 manifests are served")
+	}
+
+	var m manifest
+	if dgst, err := digest.ParseDigest(mux.Vars(r)["reference"]); err == nil {
+		m, err = s.manifestByDigest(named, dgst)
+		if err != nil {
+			return err
+		}
+	} else {
+		ref, err := reference.WithTag(named, mux.Vars(r)["reference"])
+		if err != nil {
+			return v2.ErrorCodeTagInvalid.WithDetail(err.Error())
+		}
+		id, err := s.resolve(w, ref)
+		if err != nil {
+			return err
+		}
+		if m, err = s.manifest(id); err != nil {
+			return v2.ErrorCodeManifestUnknown.WithDetail(err.Error())
+		}
+	}
+
+	w.Header().Set("Content-Type", schema2.MediaTypeManifest)
+	w.Header().Set("Content-Length", strconv.Itoa(len(m.payload)))
+	w.Header().Set("Docker-Content-Digest", m.digest.String())
+	w.Header().Set("Etag", fmt.Sprintf(`"%s"`, m.digest))
+	if r.Method == "GET" {
+		w.Write(m.payload)
+	}
+	return nil
+}
+
+// acceptsSchema2 returns true if the client accepts schema2 manifests.
+// Older clients are left to fall back to the next endpoint.
+func acceptsSchema2(r *http.Request) bool {
+	for _, accept := range r.Header["Accept"] {
+		for _, mediaType := range strings.Split(accept, ",") {
+			if mediaType, _, err := mime.ParseMediaType(strings.TrimSpace(mediaType)); err == nil && mediaType == schema2.MediaTypeManifest {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// resolve returns the image a tag refers to, pulling the tag if it is
+// missing from the stores. The pull is cancelled if the client goes away.
+func (s *server) resolve(w http.ResponseWriter, ref reference.NamedTagged) (image.ID, error) {
+	id, err := s.config.ReferenceStore.Get(ref)
+	if err == reference.ErrDoesNotExist && s.config.Pull != nil {
+		select {
+		case s.pulls <- struct{}{}:
+		default:
+			return "", errcode.ErrorCodeTooManyRequests.WithDetail("too many pulls in progress")
+		}
+		defer func() { <-s.pulls }()
+
+		ctx, cancel := context.WithTimeout(context.Background(), s.config.PullTimeout)
+		defer cancel()
+		if cn, ok := w.(http.CloseNotifier); ok {
+			closed := cn.CloseNotify()
+			go func() {
+				select {
+				case <-closed:
+					cancel()
+				case <-ctx.Done():
+				}
+			}()
+		}
+
+		logrus.Debugf("registry cache: pulling %s", ref.String())
+		if err := s.config.Pull(ctx, ref); err != nil {
+			return "", v2.ErrorCodeManifestUnknown.WithDetail(fmt.Sprintf("pulling %s failed: %v", ref.String(), err))
+		}
+		id, err = s.config.ReferenceStore.Get(ref)
+	}
+	if err != nil {
+		return "", v2.ErrorCodeManifestUnknown.WithDetail(err.Error())
+	}
+	return id, nil
+}
+
+// manifestByDigest looks for an image of the repository with a generated
+// manifest matching dgst. Digests of other manifests are never pulled as
+// the generated manifest could not match them.
+func (s *server) manifestByDigest(named reference.Named, dgst digest.Digest) (manifest, error) {
+	for _, assoc := range s.config.ReferenceStore.ReferencesByName(named) {
+		m, err := s.manifest(assoc.ImageID)
+		if err != nil {
+			continue
+		}
+		if m.digest == dgst {
+			return m, nil
+		}
+	}
+	return manifest{}, v2.ErrorCodeManifestUnknown.WithDetail(dgst)
+}
+
+// manifest returns the schema2 manifest of an image.
+func (s *server) manifest(id image.ID) (manifest, error) {
+	s.mu.Lock()
+	m, ok := s.manifests[id]
+	s.mu.Unlock()
+	if ok {
+		return m, nil
+	}
+
+	img, err := s.config.ImageStore.Get(id)
+	if err != nil {
+		return manifest{}, err
+	}
+	if img.RootFS == nil || img.RootFS.Type != image.TypeLayers {
+		return manifest{}, fmt.Errorf("image %s has an unsupported root filesystem", id)
+	}
+
+	sm := schema2.Manifest{
+		Versioned: schema2.SchemaVersion,
+		Config: distribution.Descriptor{
+			MediaType: schema2.MediaTypeConfig,
+			Size:      int64(len(img.RawJSON())),
+			Digest:    digest.Digest(id),
+		},
+	}
+	for i, diffID := range img.RootFS.DiffIDs {
+		size, err := s.layerSize(img.RootFS.DiffIDs[:i+1])
+		if err != nil {
+			return manifest{}, err
+		}
+		sm.Layers = append(sm.Layers, distribution.Descriptor{
+			MediaType: schema2.MediaTypeLayer,
+			Size:      size,
+			Digest:    digest.Digest(diffID),
+		})
+	}
+
+	deserialized, err := schema2.FromStruct(sm)
+	if err != nil {
+		return manifest{}, err
+	}
+	_, payload, err := deserialized.Payload()
+	if err != nil {
+		return manifest{}, err
+	}
+	m = manifest{digest: digest.FromBytes(payload), payload: payload}
+
+	s.mu.Lock()
+	s.manifests[id] = m
+	s.mu.Unlock()
+	return m, nil
+}
+
+// openLayer returns the tar stream of the last layer of a chain.
+func (s *server) openLayer(diffIDs []layer.DiffID) (io.ReadCloser, error) {
+	l, err := s.config.LayerStore.Get(layer.CreateChainID(diffIDs))
+	if err != nil {
+		return nil, err
+	}
+	release := func() {
+		if _, err := s.config.LayerStore.Release(l); err != nil {
+			logrus.Errorf("Error releasing layer %s: %v", l.ChainID(), err)
+		}
+	}
+	rc, err := l.TarStream()
+	if err != nil {
+		release()
+		return nil, err
+	}
+	return ioutils.NewReadCloserWrapper(rc, func() error {
+		err := rc.Close()
+		release()
+		return err
+	}), nil
+}
+
+// layerSize returns the size of the tar stream of the last layer of a
+// chain, which is the size of the blob served for it. The layer store only
+// knows the size of the unpacked content, so the stream is read once to
+// measure it.
+func (s *server) layerSize(diffIDs []layer.DiffID) (int64, error) {
+	chainID := layer.CreateChainID(diffIDs)
+	s.mu.Lock()
+	size, ok := s.layerSizes[chainID]
+	s.mu.Unlock()
+	if ok {
+		return size, nil
+	}
+
+	rc, err := s.openLayer(diffIDs)
+	if err != nil {
+		return 0, err
+	}
+	defer rc.Close()
+	size, err = io.Copy(ioutil.Discard, rc)
+	if err != nil {
+		return 0, err
+	}
+
+	s.mu.Lock()
+	s.layerSizes[chainID] = size
+	s.mu.Unlock()
+	return size, nil
+}
+
+func (s *server) getTags(w http.ResponseWriter, r *http.Request) error {
+	named, err := repository(r)
+	if err != nil {
+		return err
+	}
+
+	tags := []string{}
+	for _, assoc := range s.config.ReferenceStore.ReferencesByName(named) {
+		if tagged, ok := assoc.Ref.(reference.NamedTagged); ok {
+			tags = append(tags, tagged.Tag())
+		}
+	}
+	if len(tags) == 0 {
+		return v2.ErrorCodeNameUnknown.WithDetail(mux.Vars(r)["name"])
+	}
+	sort.Strings(tags)
+
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	return json.NewEncoder(w).Encode(struct {
+		Name string   `json:"name"`
+		Tags []string `json:"tags"`
+	}{
+		Name: mux.Vars(r)["name"],
+		Tags: tags,
+	})
+}
+
+// getBlob serves the configuration or a layer of one of the images of the
+// repository.
+func (s *server) getBlob(w http.ResponseWriter, r *http.Request) error {
+	named, err := repository(r)
+	if err != nil {
+		return err
+	}
+	dgst, err := digest.ParseDigest(mux.Vars(r)["digest"])
+	if err != nil {
+		return v2.ErrorCodeDigestInvalid.WithDetail(err.Error())
+	}
+
+	for _, assoc := range s.config.ReferenceStore.ReferencesByName(named) {
+		img, err := s.config.ImageStore.Get(assoc.ImageID)
+		if err != nil {
+			continue
+		}
+		if digest.Digest(img.ID()) == dgst {
+			raw := img.RawJSON()
+			return serveBlob(w, r, dgst, int64(len(raw)), ioutil.NopCloser(bytes.NewReader(raw)))
+		}
+		if img.RootFS == nil || img.RootFS.Type != image.TypeLayers {
+			continue
+		}
+		for i, diffID := range img.RootFS.DiffIDs {
+			if digest.Digest(diffID) != dgst {
+				continue
+			}
+			size, err := s.layerSize(img.RootFS.DiffIDs[:i+1])
+			if err != nil {
+				return errcode.ErrorCodeUnknown.WithDetail(err.Error())
+			}
+			var rc io.ReadCloser
+			if r.Method == "GET" {
+				if rc, err = s.openLayer(img.RootFS.DiffIDs[:i+1]); err != nil {
+					return errcode.ErrorCodeUnknown.WithDetail(err.Error())
+				}
+			}
+			return serveBlob(w, r, dgst, size, rc)
+		}
+	}
+	return v2.ErrorCodeBlobUnknown.WithDetail(dgst)
+}
+
+// serveBlob writes a blob, or only its headers for HEAD requests in which
+// case rc may be nil.
+func serveBlob(w http.ResponseWriter, r *http.Request, dgst digest.Digest, size int64, rc io.ReadCloser) error {
+	w.Header().Set("Content-Type", "application/octet-stream")
+	w.Header().Set("Content-Length", strconv.FormatInt(size, 10))
+	w.Header().Set("Docker-Content-Digest", dgst.String())
+	w.Header().Set("Etag", fmt.Sprintf(`"%s"`, dgst))
+	w.Header().Set("Cache-Control", "max-age=31536000")
+	if rc == nil {
+		w.WriteHeader(http.StatusOK)
+		return nil
+	}
+	defer rc.Close()
+
+	if r.Method != "GET" {
+		w.WriteHeader(http.StatusOK)
+		return nil
+	}
+	if _, err := io.Copy(w, rc); err != nil {
+		// The headers are sent already, the client notices the
+		// truncated blob.
+		logrus.Errorf("registry cache: error serving blob %s: %v", dgst, err)
+	}
+	return nil
+}
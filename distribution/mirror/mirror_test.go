@@ -0,0 +1,364 @@
+package mirror
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/docker/distribution"
+	"github.com/docker/distribution/digest"
+	"github.com/docker/distribution/manifest/schema2"
+	"github.com/docker/docker/image"
+	"github.com/docker/docker/layer"
+	"github.com/docker/docker/reference"
+	"golang.org/x/net/context"
+)
+
+type mockLayer struct {
+	layerData []byte
+	diffID    layer.DiffID
+	chainID   layer.ChainID
+	parent    layer.Layer
+}
+
+func (ml *mockLayer) TarStream() (io.ReadCloser, error) {
+	return ioutil.NopCloser(bytes.NewBuffer(ml.layerData)), nil
+}
+
+func (ml *mockLayer) ChainID() layer.ChainID {
+	return ml.chainID
+}
+
+func (ml *mockLayer) DiffID() layer.DiffID {
+	return ml.diffID
+}
+
+func (ml *mockLayer) Parent() layer.Layer {
+	return ml.parent
+}
+
+func (ml *mockLayer) Size() (size int64, err error) {
+	return 0, nil
+}
+
+// DiffSize is the size of the unpacked content, which differs from the size
+// of the tar stream.
+func (ml *mockLayer) DiffSize() (size int64, err error) {
+	return int64(len(ml.layerData)) * 2, nil
+}
+
+func (ml *mockLayer) Metadata() (map[string]string, error) {
+	return make(map[string]string), nil
+}
+
+type mockLayerStore struct {
+	layers map[layer.ChainID]*mockLayer
+}
+
+func (ls *mockLayerStore) add(data []byte, parent *mockLayer) *mockLayer {
+	l := &mockLayer{layerData: data, diffID: layer.DiffID(digest.FromBytes(data))}
+	if parent == nil {
+		l.chainID = layer.CreateChainID([]layer.DiffID{l.diffID})
+	} else {
+		l.parent = parent
+		l.chainID = layer.CreateChainID([]layer.DiffID{parent.diffID, l.diffID})
+	}
+	ls.layers[l.chainID] = l
+	return l
+}
+
+func (ls *mockLayerStore) Get(chainID layer.ChainID) (layer.Layer, error) {
+	l, ok := ls.layers[chainID]
+	if !ok {
+		return nil, layer.ErrLayerDoesNotExist
+	}
+	return l, nil
+}
+
+func (ls *mockLayerStore) Release(l layer.Layer) ([]layer.Metadata, error) {
+	return nil, nil
+}
+
+type testStores struct {
+	Config
+	layers *mockLayerStore
+	dir    string
+}
+
+func newTestStores(t *testing.T) *testStores {
+	dir, err := ioutil.TempDir("", "registry-mirror")
+	if err != nil {
+		t.Fatal(err)
+	}
+	ls := &mockLayerStore{layers: make(map[layer.ChainID]*mockLayer)}
+	fs, err := image.NewFSStoreBackend(filepath.Join(dir, "images"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	is, err := image.NewImageStore(fs, ls)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rs, err := reference.NewReferenceStore(filepath.Join(dir, "repositories.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &testStores{
+		Config: Config{ImageStore: is, LayerStore: ls, ReferenceStore: rs},
+		layers: ls,
+		dir:    dir,
+	}
+}
+
+// createImage creates an image made of one layer per data and tags it.
+func (s *testStores) createImage(t *testing.T, tag string, data ...string) image.ID {
+	rootFS := image.NewRootFS()
+	var parent *mockLayer
+	for _, d := range data {
+		parent = s.layers.add([]byte(d), parent)
+		rootFS.Append(parent.diffID)
+	}
+	config, err := json.Marshal(&image.Image{
+		V1Image: image.V1Image{Architecture: "amd64", OS: "linux"},
+		RootFS:  rootFS,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	id, err := s.ImageStore.Create(config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ref, err := reference.ParseNamed(tag)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := s.ReferenceStore.AddTag(ref, id, true); err != nil {
+		t.Fatal(err)
+	}
+	return id
+}
+
+func get(t *testing.T, method, url string) *http.Response {
+	req, err := http.NewRequest(method, url, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	req.Header.Set("Accept", fmt.Sprintf("%s, application/json", schema2.MediaTypeManifest))
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return resp
+}
+
+func readManifest(t *testing.T, resp *http.Response) schema2.Manifest {
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("expected manifest, got status %d", resp.StatusCode)
+	}
+	payload, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if dgst := resp.Header.Get("Docker-Content-Digest"); dgst != digest.FromBytes(payload).String() {
+		t.Fatalf("unexpected manifest digest %s", dgst)
+	}
+	var m schema2.Manifest
+	if err := json.Unmarshal(payload, &m); err != nil {
+		t.Fatal(err)
+	}
+	return m
+}
+
+func TestServeImage(t *testing.T) {
+	stores := newTestStores(t)
+	defer os.RemoveAll(stores.dir)
+	id := stores.createImage(t, "busybox:latest", "base layer", "top layer")
+
+	server := httptest.NewServer(NewHandler(stores.Config))
+	defer server.Close()
+
+	resp := get(t, "GET", server.URL+"/v2/")
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK || resp.Header.Get("Docker-Distribution-API-Version") != "registry/2.0" {
+		t.Fatalf("unexpected response to base endpoint: %d", resp.StatusCode)
+	}
+
+	resp = get(t, "GET", server.URL+"/v2/library/busybox/manifests/latest")
+	dgst := resp.Header.Get("Docker-Content-Digest")
+	m := readManifest(t, resp)
+	if m.Config.Digest != digest.Digest(id) {
+		t.Fatalf("expected config %s, got %s", id, m.Config.Digest)
+	}
+	if len(m.Layers) != 2 || m.Layers[1].Size != int64(len("top layer")) {
+		t.Fatalf("unexpected layers %+v", m.Layers)
+	}
+
+	// the generated manifest can be pulled by digest
+	readManifest(t, get(t, "GET", server.URL+"/v2/library/busybox/manifests/"+dgst))
+
+	resp = get(t, "HEAD", server.URL+"/v2/library/busybox/blobs/"+m.Layers[1].Digest.String())
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK || resp.Header.Get("Docker-Content-Digest") != m.Layers[1].Digest.String() || resp.ContentLength != m.Layers[1].Size {
+		t.Fatalf("unexpected blob headers: %d, %d", resp.StatusCode, resp.ContentLength)
+	}
+	for _, blob := range []distribution.Descriptor{m.Config, m.Layers[0]} {
+		resp = get(t, "GET", server.URL+"/v2/library/busybox/blobs/"+blob.Digest.String())
+		data, err := ioutil.ReadAll(resp.Body)
+		resp.Body.Close()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if digest.FromBytes(data) != blob.Digest {
+			t.Fatalf("blob %s does not match its digest", blob.Digest)
+		}
+		if resp.ContentLength != blob.Size || int64(len(data)) != blob.Size {
+			t.Fatalf("expected blob %s of %d bytes, got %d bytes with a Content-Length of %d", blob.Digest, blob.Size, len(data), resp.ContentLength)
+		}
+	}
+
+	resp = get(t, "GET", server.URL+"/v2/library/busybox/tags/list")
+	var tags struct {
+		Name string
+		Tags []string
+	}
+	err := json.NewDecoder(resp.Body).Decode(&tags)
+	resp.Body.Close()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if tags.Name != "library/busybox" || len(tags.Tags) != 1 || tags.Tags[0] != "latest" {
+		t.Fatalf("unexpected tags %+v", tags)
+	}
+}
+
+func TestServeUnknown(t *testing.T) {
+	stores := newTestStores(t)
+	defer os.RemoveAll(stores.dir)
+	stores.createImage(t, "busybox:latest", "layer")
+	other := stores.createImage(t, "alpine:latest", "other layer")
+
+	server := httptest.NewServer(NewHandler(stores.Config))
+	defer server.Close()
+
+	for _, path := range []string{
+		"/v2/library/busybox/manifests/1.0",
+		"/v2/library/busybox/manifests/" + digest.FromBytes([]byte("upstream")).String(),
+		"/v2/library/ubuntu/tags/list",
+		// blobs are only served from the images of the repository
+		"/v2/library/busybox/blobs/" + string(other),
+	} {
+		resp := get(t, "GET", server.URL+path)
+		resp.Body.Close()
+		if resp.StatusCode != http.StatusNotFound {
+			t.Fatalf("expected %s not to be found, got status %d", path, resp.StatusCode)
+		}
+	}
+
+	req, err := http.NewRequest("GET", server.URL+"/v2/library/busybox/manifests/latest", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	req.Header.Set("Accept", "application/json")
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusNotFound {
+		t.Fatalf("expected clients without schema2 support to be refused, got status %d", resp.StatusCode)
+	}
+
+	resp = get(t, "DELETE", server.URL+"/v2/library/busybox/manifests/latest")
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusMethodNotAllowed {
+		t.Fatalf("expected the API to be read-only, got status %d", resp.StatusCode)
+	}
+}
+
+func TestServePullThrough(t *testing.T) {
+	stores := newTestStores(t)
+	defer os.RemoveAll(stores.dir)
+
+	var pulled []string
+	stores.Pull = func(ctx context.Context, ref reference.Named) error {
+		pulled = append(pulled, ref.String())
+		if ref.String() != "busybox:latest" {
+			return fmt.Errorf("%s not found", ref.String())
+		}
+		stores.createImage(t, ref.String(), "pulled layer")
+		return nil
+	}
+
+	server := httptest.NewServer(NewHandler(stores.Config))
+	defer server.Close()
+
+	readManifest(t, get(t, "GET", server.URL+"/v2/library/busybox/manifests/latest"))
+	readManifest(t, get(t, "GET", server.URL+"/v2/library/busybox/manifests/latest"))
+	resp := get(t, "GET", server.URL+"/v2/library/busybox/manifests/missing")
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusNotFound {
+		t.Fatalf("expected failed pull to return not found, got status %d", resp.StatusCode)
+	}
+	if len(pulled) != 2 || pulled[0] != "busybox:latest" || pulled[1] != "busybox:missing" {
+		t.Fatalf("unexpected pulls %v", pulled)
+	}
+}
+
+func TestServePullLimits(t *testing.T) {
+	stores := newTestStores(t)
+	defer os.RemoveAll(stores.dir)
+
+	started := make(chan struct{})
+	stopped := make(chan error)
+	stores.Pull = func(ctx context.Context, ref reference.Named) error {
+		started <- struct{}{}
+		<-ctx.Done()
+		stopped <- ctx.Err()
+		return ctx.Err()
+	}
+	stores.PullTimeout = 100 * time.Millisecond
+	stores.MaxConcurrentPulls = 1
+
+	server := httptest.NewServer(NewHandler(stores.Config))
+	defer server.Close()
+
+	req, err := http.NewRequest("GET", server.URL+"/v2/library/busybox/manifests/latest", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	req.Header.Set("Accept", schema2.MediaTypeManifest)
+	done := make(chan int)
+	go func() {
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			done <- 0
+			return
+		}
+		resp.Body.Close()
+		done <- resp.StatusCode
+	}()
+	<-started
+
+	resp := get(t, "GET", server.URL+"/v2/library/alpine/manifests/latest")
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusTooManyRequests {
+		t.Fatalf("expected pulls over the limit to be refused, got status %d", resp.StatusCode)
+	}
+
+	if err := <-stopped; err != context.DeadlineExceeded {
+		t.Fatalf("expected the pull to time out, got %v", err)
+	}
+	if status := <-done; status != http.StatusNotFound {
+		t.Fatalf("expected timed out pull to return not found, got status %d", status)
+	}
+}
@@ -0,0 +1,95 @@
+package distribution
+
+import (
+	"fmt"
+	"runtime"
+	"strings"
+
+	"github.com/docker/distribution/manifest/manifestlist"
+)
+
+// Platform identifies the operating system and CPU architecture an image
+// is built for.
+type Platform struct {
+	OS           string
+	Architecture string
+	// Variant optionally selects a variant of the CPU, for example "v7"
+	// for arm.
+	Variant string
+}
+
+// archAliases maps common names of CPU architectures to the names used in
+// image manifests.
+var archAliases = map[string]string{
+	"x86_64":  "amd64",
+	"x86-64":  "amd64",
+	"aarch64": "arm64",
+	"i386":    "386",
+	"i686":    "386",
+}
+
+// DefaultPlatform returns the platform of the daemon.
+func DefaultPlatform() Platform {
+	return Platform{OS: runtime.GOOS, Architecture: runtime.GOARCH}
+}
+
+// ParsePlatform parses a platform in the "os/arch[/variant]" format, for
+// example "linux/arm64" or "linux/arm/v7".
+func ParsePlatform(s string) (Platform, error) {
+	parts := strings.Split(strings.ToLower(s), "/")
+	if len(parts) < 2 || len(parts) > 3 {
+		return Platform{}, fmt.Errorf("invalid platform %q: expected os/arch[/variant]", s)
+	}
+	for _, part := range parts {
+		if part == "" {
+			return Platform{}, fmt.Errorf("invalid platform %q: expected os/arch[/variant]", s)
+		}
+	}
+
+	p := Platform{OS: parts[0], Architecture: parts[1]}
+	if alias, ok := archAliases[p.Architecture]; ok {
+		p.Architecture = alias
+	}
+	if len(parts) == 3 {
+		p.Variant = parts[2]
+	}
+	return p, nil
+}
+
+// String returns the platform in the "os/arch[/variant]" format.
+func (p Platform) String() string {
+	s := p.OS + "/" + p.Architecture
+	if p.Variant != "" {
+		s += "/" + p.Variant
+	}
+	return s
+}
+
+// matches returns true if spec describes the platform. The variant is only
+// compared if one was requested.
+func (p Platform) matches(spec manifestlist.PlatformSpec) bool {
+	if spec.OS != p.OS || spec.Architecture != p.Architecture {
+		return false
+	}
+	return p.Variant == "" || spec.Variant == p.Variant
+}
+
+// checkImage verifies that an image built for os and arch runs on the
+// platform. Empty values, which older images may have, are not checked.
+func (p Platform) checkImage(os, arch string) error {
+	if (os != "" && os != p.OS) || (arch != "" && arch != p.Architecture) {
+		image := Platform{OS: os, Architecture: arch}
+		if os == "" {
+			image.OS = "unknown"
+		}
+		if arch == "" {
+			image.Architecture = "unknown"
+		}
+		return fmt.Errorf("image is for platform %s, not %s", image, p)
+	}
+	return nil
+}
+
+func platformOfSpec(spec manifestlist.PlatformSpec) Platform {
+	return Platform{OS: spec.OS, Architecture: spec.Architecture, Variant: spec.Variant}
+}
@@ -0,0 +1,67 @@
+package distribution
+
+import (
+	"testing"
+
+	"github.com/docker/distribution/manifest/manifestlist"
+)
+
+func TestParsePlatform(t *testing.T) {
+	valid := map[string]Platform{
+		"linux/amd64":    {OS: "linux", Architecture: "amd64"},
+		"linux/x86_64":   {OS: "linux", Architecture: "amd64"},
+		"Linux/AArch64":  {OS: "linux", Architecture: "arm64"},
+		"linux/arm/v7":   {OS: "linux", Architecture: "arm", Variant: "v7"},
+		"windows/amd64":  {OS: "windows", Architecture: "amd64"},
+		"linux/ppc64le":  {OS: "linux", Architecture: "ppc64le"},
+		"linux/386":      {OS: "linux", Architecture: "386"},
+		"linux/i686/foo": {OS: "linux", Architecture: "386", Variant: "foo"},
+	}
+	for s, expected := range valid {
+		p, err := ParsePlatform(s)
+		if err != nil {
+			t.Fatalf("unexpected error parsing %q: %v", s, err)
+		}
+		if p != expected {
+			t.Fatalf("expected %q to parse as %+v, got %+v", s, expected, p)
+		}
+	}
+
+	for _, s := range []string{"", "linux", "linux/", "/amd64", "linux/arm/v7/extra", "linux//v7"} {
+		if _, err := ParsePlatform(s); err == nil {
+			t.Fatalf("expected error parsing %q", s)
+		}
+	}
+}
+
+func TestPlatformMatches(t *testing.T) {
+	armv7 := manifestlist.PlatformSpec{OS: "linux", Architecture: "arm", Variant: "v7"}
+	armv6 := manifestlist.PlatformSpec{OS: "linux", Architecture: "arm", Variant: "v6"}
+
+	if !(Platform{OS: "linux", Architecture: "arm"}).matches(armv7) {
+		t.Fatal("expected platform without variant to match any variant")
+	}
+	if !(Platform{OS: "linux", Architecture: "arm", Variant: "v7"}).matches(armv7) {
+		t.Fatal("expected linux/arm/v7 to match")
+	}
+	if (Platform{OS: "linux", Architecture: "arm", Variant: "v7"}).matches(armv6) {
+		t.Fatal("expected linux/arm/v7 not to match linux/arm/v6")
+	}
+	if (Platform{OS: "windows", Architecture: "arm"}).matches(armv7) {
+		t.Fatal("expected windows/arm not to match linux/arm/v7")
+	}
+}
+
+func TestPlatformCheckImage(t *testing.T) {
+	p := Platform{OS: "linux", Architecture: "arm64"}
+	if err := p.checkImage("linux", "arm64"); err != nil {
+		t.Fatal(err)
+	}
+	if err := p.checkImage("", ""); err != nil {
+		t.Fatal(err)
+	}
+	err := p.checkImage("", "amd64")
+	if err == nil || err.Error() != "image is for platform unknown/amd64, not linux/arm64" {
+		t.Fatalf("unexpected error %v", err)
+	}
+}
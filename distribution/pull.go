@@ -16,6 +16,23 @@ import (
 	"golang.org/x/net/context"
 )
 
+// Verifications applied to the images pulled, as reported by
+// PullVerifications.
+const (
+	// VerifyContentDigest is the verification of the manifest, config and
+	// layers pulled against their digests.
+	VerifyContentDigest = "content-digest"
+	// VerifyPinnedReferences is the enforcement of the digests tags are
+	// pinned to.
+	VerifyPinnedReferences = "pinned-references"
+	// VerifyPlatform is the verification that the image pulled is for the
+	// platform requested.
+	VerifyPlatform = "platform"
+	// VerifyBaseLayer is the verification of the base layer of the image,
+	// which is pulled like the other layers.
+	VerifyBaseLayer = "base-layer"
+)
+
 // ImagePullConfig stores pull configuration.
 type ImagePullConfig struct {
 	// MetaHeaders stores HTTP headers with metadata about the image
@@ -38,6 +55,11 @@ type ImagePullConfig struct {
 	ImageStore image.Store
 	// ReferenceStore manages tags.
 	ReferenceStore reference.Store
+	// PinStore holds the digests tags are pinned to. It may be nil.
+	PinStore reference.PinStore
+	// Platform selects the image to pull from a manifest list. The
+	// platform of the daemon is used if it is not set.
+	Platform Platform
 	// DownloadManager manages concurrent pulls.
 	DownloadManager *xfer.LayerDownloadManager
 }
@@ -60,6 +82,7 @@ func newPuller(endpoint registry.APIEndpoint, repoInfo *registry.RepositoryInfo,
 	case registry.APIVersion2:
 		return &v2Puller{
 			V2MetadataService: metadata.NewV2MetadataService(imagePullConfig.MetadataStore),
+			ManifestService:   metadata.NewManifestService(imagePullConfig.MetadataStore),
 			endpoint:          endpoint,
 			config:            imagePullConfig,
 			repoInfo:          repoInfo,
@@ -94,6 +117,8 @@ func Pull(ctx context.Context, ref reference.Named, imagePullConfig *ImagePullCo
 		return err
 	}
 
+	ctx = withRateLimitOutput(ctx, imagePullConfig.ProgressOutput)
+
 	var (
 		lastErr error
 
@@ -182,6 +207,31 @@ func Pull(ctx context.Context, ref reference.Named, imagePullConfig *ImagePullCo
 	return lastErr
 }
 
+// platform returns the platform to pull images for, and whether it was
+// explicitly requested.
+func (config *ImagePullConfig) platform() (Platform, bool) {
+	if config.Platform == (Platform{}) {
+		return DefaultPlatform(), false
+	}
+	return config.Platform, true
+}
+
+// pinnedDigest returns the digest the tag reference ref is pinned to, or an
+// empty digest if it is not pinned.
+func pinnedDigest(config *ImagePullConfig, ref reference.Named) (digest.Digest, error) {
+	if config.PinStore == nil {
+		return "", nil
+	}
+	if _, isTagged := ref.(reference.NamedTagged); !isTagged {
+		return "", nil
+	}
+	dgst, err := config.PinStore.Get(ref)
+	if err == reference.ErrDoesNotExist {
+		return "", nil
+	}
+	return dgst, err
+}
+
 // writeStatus writes a status message to out. If layersDownloaded is true, the
 // status message indicates that a newer image was downloaded. Otherwise, it
 // indicates that the image is up to date. requestedTag is the tag the message
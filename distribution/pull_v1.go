@@ -40,6 +40,11 @@ func (p *v1Puller) Pull(ctx context.Context, ref reference.Named) error {
 		// Allowing fallback, because HTTPS v1 is before HTTP v2
 		return fallbackError{err: ErrNoSupport{Err: errors.New("Cannot pull by digest with v1 registry")}}
 	}
+	if pinned, err := pinnedDigest(p.config, ref); err != nil {
+		return err
+	} else if pinned != "" {
+		return fallbackError{err: ErrNoSupport{Err: fmt.Errorf("Cannot pull pinned reference %s with v1 registry", ref.String())}}
+	}
 
 	tlsConfig, err := p.config.RegistryService.TLSConfig(p.repoInfo.Index.Name)
 	if err != nil {
@@ -143,6 +148,11 @@ func (p *v1Puller) downloadImage(ctx context.Context, repoData *registry.Reposit
 		logrus.Debug(retErr.Error())
 		return retErr
 	}
+	if pinned, err := pinnedDigest(p.config, localNameRef); err != nil {
+		return err
+	} else if pinned != "" {
+		return fmt.Errorf("Cannot pull pinned reference %s with v1 registry", localNameRef.String())
+	}
 
 	if err := v1.ValidateID(img.ID); err != nil {
 		return err
@@ -320,7 +330,7 @@ func (ld *v1LayerDescriptor) Download(ctx context.Context, progressOutput progre
 		return nil, 0, err
 	}
 
-	reader := progress.NewProgressReader(ioutils.NewCancelReadCloser(ctx, layerReader), progressOutput, ld.layerSize, ld.ID(), "Downloading")
+	reader := progress.NewPhaseReader(ioutils.NewCancelReadCloser(ctx, layerReader), progressOutput, ld.layerSize, ld.ID(), progress.PhaseDownloading, "Downloading")
 	defer reader.Close()
 
 	_, err = io.Copy(ld.tmpFile, reader)
@@ -329,7 +339,7 @@ func (ld *v1LayerDescriptor) Download(ctx context.Context, progressOutput progre
 		return nil, 0, err
 	}
 
-	progress.Update(progressOutput, ld.ID(), "Download complete")
+	progress.UpdatePhase(progressOutput, ld.ID(), progress.PhaseDownloaded, "Download complete")
 
 	logrus.Debugf("Downloaded %s to tempfile %s", ld.ID(), ld.tmpFile.Name())
 
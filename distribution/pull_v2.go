@@ -6,9 +6,11 @@ import (
 	"fmt"
 	"io"
 	"io/ioutil"
+	"net/http"
 	"net/url"
 	"os"
 	"runtime"
+	"strings"
 
 	"github.com/Sirupsen/logrus"
 	"github.com/docker/distribution"
@@ -47,6 +49,7 @@ func (e ImageConfigPullError) Error() string {
 
 type v2Puller struct {
 	V2MetadataService *metadata.V2MetadataService
+	ManifestService   *metadata.ManifestService
 	endpoint          registry.APIEndpoint
 	config            *ImagePullConfig
 	repoInfo          *registry.RepositoryInfo
@@ -148,6 +151,63 @@ func (ld *v2LayerDescriptor) DiffID() (layer.DiffID, error) {
 	return ld.V2MetadataService.GetDiffID(ld.digest)
 }
 
+var _ distribution.Describable = &v2LayerDescriptor{}
+
+// Descriptor returns the manifest descriptor of a foreign layer so that
+// it is recorded in the layer store and skipped on push. Layers stored
+// in the registry return an empty descriptor.
+func (ld *v2LayerDescriptor) Descriptor() distribution.Descriptor {
+	if ld.src.MediaType == schema2.MediaTypeForeignLayer && len(ld.src.URLs) > 0 {
+		return ld.src
+	}
+	return distribution.Descriptor{}
+}
+
+func (ld *v2LayerDescriptor) open(ctx context.Context) (distribution.ReadSeekCloser, error) {
+	if len(ld.src.URLs) == 0 {
+		blobs := ld.repo.Blobs(ctx)
+		return blobs.Open(ctx, ld.digest)
+	}
+
+	var (
+		err error
+		rsc distribution.ReadSeekCloser
+	)
+
+	// Find the first URL that results in a 200 result code.
+	for _, u := range ld.src.URLs {
+		if err = validateForeignLayerURL(u); err != nil {
+			logrus.Debugf("skipping foreign layer URL %q: %v", u, err)
+			continue
+		}
+		rsc = transport.NewHTTPReadSeeker(http.DefaultClient, u, nil)
+		_, err = rsc.Seek(0, os.SEEK_SET)
+		if err == nil {
+			break
+		}
+		rsc.Close()
+		rsc = nil
+	}
+	return rsc, err
+}
+
+// validateForeignLayerURL checks that a URL listed for a foreign layer
+// can be fetched over plain HTTP(S). Content is verified against the
+// layer digest after download, so the origin itself is not trusted.
+func validateForeignLayerURL(rawurl string) error {
+	u, err := url.Parse(rawurl)
+	if err != nil {
+		return err
+	}
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return fmt.Errorf("unsupported scheme %q for foreign layer URL", u.Scheme)
+	}
+	if u.Host == "" {
+		return errors.New("foreign layer URL has no host")
+	}
+	return nil
+}
+
 func (ld *v2LayerDescriptor) Download(ctx context.Context, progressOutput progress.Output) (io.ReadCloser, int64, error) {
 	logrus.Debugf("pulling blob %q", ld.digest)
 
@@ -224,7 +284,7 @@ func (ld *v2LayerDescriptor) Download(ctx context.Context, progressOutput progre
 		}
 	}
 
-	reader := progress.NewProgressReader(ioutils.NewCancelReadCloser(ctx, layerDownload), progressOutput, size-offset, ld.ID(), "Downloading")
+	reader := progress.NewPhaseReader(ioutils.NewCancelReadCloser(ctx, layerDownload), progressOutput, size-offset, ld.ID(), progress.PhaseDownloading, "Downloading")
 	defer reader.Close()
 
 	if ld.verifier == nil {
@@ -245,7 +305,7 @@ func (ld *v2LayerDescriptor) Download(ctx context.Context, progressOutput progre
 		return nil, 0, retryOnError(err)
 	}
 
-	progress.Update(progressOutput, ld.ID(), "Verifying Checksum")
+	progress.UpdatePhase(progressOutput, ld.ID(), progress.PhaseVerifying, "Verifying Checksum")
 
 	if !ld.verifier.Verified() {
 		err = fmt.Errorf("filesystem layer verification failed for digest %s", ld.digest)
@@ -263,7 +323,7 @@ func (ld *v2LayerDescriptor) Download(ctx context.Context, progressOutput progre
 		return nil, 0, xfer.DoNotRetry{Err: err}
 	}
 
-	progress.Update(progressOutput, ld.ID(), "Download complete")
+	progress.UpdatePhase(progressOutput, ld.ID(), progress.PhaseDownloaded, "Download complete")
 
 	logrus.Debugf("Downloaded %s to tempfile %s", ld.ID(), tmpFile.Name())
 
@@ -329,11 +389,31 @@ func (p *v2Puller) pullV2Tag(ctx context.Context, ref reference.Named) (tagUpdat
 		return false, err
 	}
 
+	pinned, err := pinnedDigest(p.config, ref)
+	if err != nil {
+		return false, err
+	}
+
 	var (
 		manifest    distribution.Manifest
 		tagOrDigest string // Used for logging/progress only
+		verifyRef   = ref  // Reference the manifest digest is verified against
 	)
-	if tagged, isTagged := ref.(reference.NamedTagged); isTagged {
+	if tagged, isTagged := ref.(reference.NamedTagged); isTagged && pinned != "" {
+		// The tag is pinned: fetch the pinned manifest by digest, so that
+		// whatever the tag currently points to in the registry, only the
+		// pinned content is accepted.
+		logrus.Debugf("%s is pinned to %s", ref.String(), pinned)
+		verifyRef, err = reference.WithDigest(ref, pinned)
+		if err != nil {
+			return false, err
+		}
+		manifest, err = manSvc.Get(ctx, pinned)
+		if err != nil {
+			return false, fmt.Errorf("failed to fetch manifest %s pinned for %s: %v", pinned, ref.String(), err)
+		}
+		tagOrDigest = tagged.Tag()
+	} else if isTagged {
 		// NOTE: not using TagService.Get, since it uses HEAD requests
 		// against the manifests endpoint, which are not supported by
 		// all registry versions.
@@ -370,17 +450,17 @@ func (p *v2Puller) pullV2Tag(ctx context.Context, ref reference.Named) (tagUpdat
 
 	switch v := manifest.(type) {
 	case *schema1.SignedManifest:
-		imageID, manifestDigest, err = p.pullSchema1(ctx, ref, v)
+		imageID, manifestDigest, err = p.pullSchema1(ctx, verifyRef, v)
 		if err != nil {
 			return false, err
 		}
 	case *schema2.DeserializedManifest:
-		imageID, manifestDigest, err = p.pullSchema2(ctx, ref, v)
+		imageID, manifestDigest, err = p.pullSchema2(ctx, verifyRef, v)
 		if err != nil {
 			return false, err
 		}
 	case *manifestlist.DeserializedManifestList:
-		imageID, manifestDigest, err = p.pullManifestList(ctx, ref, v)
+		imageID, manifestDigest, err = p.pullManifestList(ctx, verifyRef, v)
 		if err != nil {
 			return false, err
 		}
@@ -421,6 +501,12 @@ func (p *v2Puller) pullSchema1(ctx context.Context, ref reference.Named, unverif
 		return "", "", err
 	}
 
+	if platform, requested := p.config.platform(); requested {
+		if err := platform.checkImage("", verifiedManifest.Architecture); err != nil {
+			return "", "", err
+		}
+	}
+
 	rootFS := image.NewRootFS()
 
 	if err := detectBaseLayer(p.config.ImageStore, verifiedManifest, rootFS); err != nil {
@@ -487,6 +573,7 @@ func (p *v2Puller) pullSchema1(ctx context.Context, ref reference.Named, unverif
 	}
 
 	manifestDigest = digest.FromBytes(unverifiedManifest.Canonical)
+	p.keepManifest(manifestDigest, unverifiedManifest.Canonical)
 
 	return imageID, manifestDigest, nil
 }
@@ -496,6 +583,9 @@ func (p *v2Puller) pullSchema2(ctx context.Context, ref reference.Named, mfst *s
 	if err != nil {
 		return "", "", err
 	}
+	if _, payload, err := mfst.Payload(); err == nil {
+		p.keepManifest(manifestDigest, payload)
+	}
 
 	target := mfst.Target()
 	imageID = image.ID(target.Digest)
@@ -583,6 +673,12 @@ func (p *v2Puller) pullSchema2(ctx context.Context, ref reference.Named, mfst *s
 		}
 	}
 
+	if platform, requested := p.config.platform(); requested {
+		if err := platform.checkImage(unmarshalledConfig.OS, unmarshalledConfig.Architecture); err != nil {
+			return "", "", err
+		}
+	}
+
 	// The DiffIDs returned in rootFS MUST match those in the config.
 	// Otherwise the image config could be referencing layers that aren't
 	// included in the manifest.
@@ -626,20 +722,29 @@ func (p *v2Puller) pullManifestList(ctx context.Context, ref reference.Named, mf
 	if err != nil {
 		return "", "", err
 	}
+	if _, payload, err := mfstList.Payload(); err == nil {
+		p.keepManifest(manifestListDigest, payload)
+	}
+
+	platform, _ := p.config.platform()
 
-	var manifestDigest digest.Digest
+	var (
+		manifestDigest digest.Digest
+		available      []string
+	)
 	for _, manifestDescriptor := range mfstList.Manifests {
 		// TODO(aaronl): The manifest list spec supports optional
-		// "features" and "variant" fields. These are not yet used.
-		// Once they are, their values should be interpreted here.
-		if manifestDescriptor.Platform.Architecture == runtime.GOARCH && manifestDescriptor.Platform.OS == runtime.GOOS {
+		// "features" field. It is not yet used. Once it is, its values
+		// should be interpreted here.
+		if platform.matches(manifestDescriptor.Platform) {
 			manifestDigest = manifestDescriptor.Digest
 			break
 		}
+		available = append(available, platformOfSpec(manifestDescriptor.Platform).String())
 	}
 
 	if manifestDigest == "" {
-		return "", "", errors.New("no supported platform found in manifest list")
+		return "", "", fmt.Errorf("no matching manifest for %s in the manifest list entries (available: %s)", platform, strings.Join(available, ", "))
 	}
 
 	manSvc, err := p.repo.Manifests(ctx)
@@ -675,6 +780,14 @@ func (p *v2Puller) pullManifestList(ctx context.Context, ref reference.Named, mf
 	return imageID, manifestListDigest, err
 }
 
+// keepManifest keeps the manifest of digest dgst, so that the local content
+// of the image can later be verified against the digest.
+func (p *v2Puller) keepManifest(dgst digest.Digest, manifest []byte) {
+	if err := p.ManifestService.Set(dgst, manifest); err != nil {
+		logrus.Warnf("Error keeping the manifest %s: %v", dgst, err)
+	}
+}
+
 func (p *v2Puller) pullSchema2ImageConfig(ctx context.Context, dgst digest.Digest) (configJSON []byte, err error) {
 	blobs := p.repo.Blobs(ctx)
 	configJSON, err = blobs.Get(ctx, dgst)
@@ -3,14 +3,19 @@ package distribution
 import (
 	"encoding/json"
 	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
 	"reflect"
 	"runtime"
 	"strings"
 	"testing"
 
+	"github.com/docker/distribution"
 	"github.com/docker/distribution/digest"
 	"github.com/docker/distribution/manifest/schema1"
+	"github.com/docker/distribution/manifest/schema2"
 	"github.com/docker/docker/reference"
+	"golang.org/x/net/context"
 )
 
 // TestFixManifestLayers checks that fixManifestLayers removes a duplicate
@@ -181,3 +186,58 @@ func TestValidateManifest(t *testing.T) {
 		t.Fatal("expected validateManifest to fail with digest error")
 	}
 }
+
+// TestForeignLayerOpen checks that layers with URLs are fetched from the
+// first usable URL rather than from the registry.
+func TestForeignLayerOpen(t *testing.T) {
+	content := []byte("foreign layer content")
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/layer" {
+			http.NotFound(w, r)
+			return
+		}
+		w.Write(content)
+	}))
+	defer server.Close()
+
+	ld := &v2LayerDescriptor{
+		digest: digest.FromBytes(content),
+		src: distribution.Descriptor{
+			MediaType: schema2.MediaTypeForeignLayer,
+			Size:      int64(len(content)),
+			Digest:    digest.FromBytes(content),
+			URLs:      []string{"ftp://example.com/layer", server.URL + "/missing", server.URL + "/layer"},
+		},
+	}
+
+	if d := ld.Descriptor(); len(d.URLs) != 3 {
+		t.Fatalf("expected foreign descriptor to be returned, got %#v", d)
+	}
+
+	rsc, err := ld.open(context.Background())
+	if err != nil {
+		t.Fatalf("error opening foreign layer: %v", err)
+	}
+	defer rsc.Close()
+
+	b, err := ioutil.ReadAll(rsc)
+	if err != nil {
+		t.Fatalf("error reading foreign layer: %v", err)
+	}
+	if string(b) != string(content) {
+		t.Fatalf("unexpected layer content %q", b)
+	}
+}
+
+func TestValidateForeignLayerURL(t *testing.T) {
+	for _, u := range []string{"http://example.com/blob", "https://example.com/blob"} {
+		if err := validateForeignLayerURL(u); err != nil {
+			t.Fatalf("expected %q to be valid, got %v", u, err)
+		}
+	}
+	for _, u := range []string{"file:///etc/passwd", "ftp://example.com/blob", "https:///blob", "::"} {
+		if err := validateForeignLayerURL(u); err == nil {
+			t.Fatalf("expected %q to be rejected", u)
+		}
+	}
+}
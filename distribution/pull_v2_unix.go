@@ -3,17 +3,13 @@
 package distribution
 
 import (
-	"github.com/docker/distribution"
-	"github.com/docker/distribution/context"
 	"github.com/docker/distribution/manifest/schema1"
 	"github.com/docker/docker/image"
 )
 
+// PullVerifications lists the verifications applied to the images pulled.
+var PullVerifications = []string{VerifyContentDigest, VerifyPinnedReferences, VerifyPlatform, VerifyBaseLayer}
+
 func detectBaseLayer(is image.Store, m *schema1.Manifest, rootFS *image.RootFS) error {
 	return nil
 }
-
-func (ld *v2LayerDescriptor) open(ctx context.Context) (distribution.ReadSeekCloser, error) {
-	blobs := ld.repo.Blobs(ctx)
-	return blobs.Open(ctx, ld.digest)
-}
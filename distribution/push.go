@@ -105,6 +105,8 @@ func Push(ctx context.Context, ref reference.Named, imagePushConfig *ImagePushCo
 		return err
 	}
 
+	ctx = withRateLimitOutput(ctx, imagePushConfig.ProgressOutput)
+
 	progress.Messagef(imagePushConfig.ProgressOutput, "", "The push refers to a repository [%s]", repoInfo.FullName())
 
 	associations := imagePushConfig.ReferenceStore.ReferencesByName(repoInfo)
@@ -301,9 +301,9 @@ func (p *v1Pusher) lookupImageOnEndpoint(wg *sync.WaitGroup, endpoint string, im
 		if err := p.session.LookupRemoteImage(v1ID, endpoint); err != nil {
 			logrus.Errorf("Error in LookupRemoteImage: %s", err)
 			imagesToPush <- v1ID
-			progress.Update(p.config.ProgressOutput, truncID, "Waiting")
+			progress.UpdatePhase(p.config.ProgressOutput, truncID, progress.PhaseWaiting, "Waiting")
 		} else {
-			progress.Update(p.config.ProgressOutput, truncID, "Already exists")
+			progress.UpdatePhase(p.config.ProgressOutput, truncID, progress.PhaseExists, "Already exists")
 		}
 	}
 }
@@ -431,7 +431,7 @@ func (p *v1Pusher) pushImage(ctx context.Context, v1Image v1Image, ep string) (c
 	// Send the layer
 	logrus.Debugf("rendered layer for %s of [%d] size", v1ID, size)
 
-	reader := progress.NewProgressReader(ioutils.NewCancelReadCloser(ctx, arch), p.config.ProgressOutput, size, truncID, "Pushing")
+	reader := progress.NewPhaseReader(ioutils.NewCancelReadCloser(ctx, arch), p.config.ProgressOutput, size, truncID, progress.PhaseUploading, "Pushing")
 	defer reader.Close()
 
 	checksum, checksumPayload, err := p.session.PushImageLayerRegistry(v1ID, reader, ep, jsonRaw)
@@ -449,6 +449,6 @@ func (p *v1Pusher) pushImage(ctx context.Context, v1Image v1Image, ep string) (c
 		logrus.Warnf("Could not set v1 ID mapping: %v", err)
 	}
 
-	progress.Update(p.config.ProgressOutput, truncID, "Image successfully pushed")
+	progress.UpdatePhase(p.config.ProgressOutput, truncID, progress.PhasePushed, "Image successfully pushed")
 	return imgData.Checksum, nil
 }
@@ -249,7 +249,7 @@ func (pd *v2PushDescriptor) DiffID() layer.DiffID {
 func (pd *v2PushDescriptor) Upload(ctx context.Context, progressOutput progress.Output) (distribution.Descriptor, error) {
 	if fs, ok := pd.layer.(distribution.Describable); ok {
 		if d := fs.Descriptor(); len(d.URLs) > 0 {
-			progress.Update(progressOutput, pd.ID(), "Skipped foreign layer")
+			progress.UpdatePhase(progressOutput, pd.ID(), progress.PhaseSkipped, "Skipped foreign layer")
 			return d, nil
 		}
 	}
@@ -261,7 +261,7 @@ func (pd *v2PushDescriptor) Upload(ctx context.Context, progressOutput progress.
 		// it is already known that the push is not needed and
 		// therefore doing a stat is unnecessary
 		pd.pushState.Unlock()
-		progress.Update(progressOutput, pd.ID(), "Layer already exists")
+		progress.UpdatePhase(progressOutput, pd.ID(), progress.PhaseExists, "Layer already exists")
 		return descriptor, nil
 	}
 	pd.pushState.Unlock()
@@ -271,11 +271,11 @@ func (pd *v2PushDescriptor) Upload(ctx context.Context, progressOutput progress.
 	if err == nil {
 		descriptor, exists, err := layerAlreadyExists(ctx, v2Metadata, pd.repoInfo, pd.repo, pd.pushState)
 		if err != nil {
-			progress.Update(progressOutput, pd.ID(), "Image push failed")
+			progress.UpdatePhase(progressOutput, pd.ID(), progress.PhaseFailed, "Image push failed")
 			return distribution.Descriptor{}, retryOnError(err)
 		}
 		if exists {
-			progress.Update(progressOutput, pd.ID(), "Layer already exists")
+			progress.UpdatePhase(progressOutput, pd.ID(), progress.PhaseExists, "Layer already exists")
 			pd.pushState.Lock()
 			pd.pushState.remoteLayers[diffID] = descriptor
 			pd.pushState.Unlock()
@@ -332,7 +332,7 @@ func (pd *v2PushDescriptor) Upload(ctx context.Context, progressOutput progress.
 		layerUpload, err = bs.Create(ctx, client.WithMountFrom(canonicalRef))
 		switch err := err.(type) {
 		case distribution.ErrBlobMounted:
-			progress.Updatef(progressOutput, pd.ID(), "Mounted from %s", err.From.Name())
+			progress.UpdatePhase(progressOutput, pd.ID(), progress.PhaseMounted, "Mounted from "+err.From.Name())
 
 			err.Descriptor.MediaType = schema2.MediaTypeLayer
 
@@ -373,7 +373,7 @@ func (pd *v2PushDescriptor) Upload(ctx context.Context, progressOutput progress.
 	// don't care if this fails; best effort
 	size, _ := pd.layer.DiffSize()
 
-	reader := progress.NewProgressReader(ioutils.NewCancelReadCloser(ctx, arch), progressOutput, size, pd.ID(), "Pushing")
+	reader := progress.NewPhaseReader(ioutils.NewCancelReadCloser(ctx, arch), progressOutput, size, pd.ID(), progress.PhaseUploading, "Pushing")
 	compressedReader, compressionDone := compress(reader)
 	defer func() {
 		reader.Close()
@@ -395,7 +395,7 @@ func (pd *v2PushDescriptor) Upload(ctx context.Context, progressOutput progress.
 	}
 
 	logrus.Debugf("uploaded layer %s (%s), %d bytes", diffID, pushDigest, nn)
-	progress.Update(progressOutput, pd.ID(), "Pushed")
+	progress.UpdatePhase(progressOutput, pd.ID(), progress.PhasePushed, "Pushed")
 
 	// Cache mapping from this layer's DiffID to the blobsum
 	if err := pd.v2MetadataService.Add(diffID, metadata.V2Metadata{Digest: pushDigest, SourceRepository: pd.repoInfo.FullName()}); err != nil {
@@ -0,0 +1,115 @@
+package distribution
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/Sirupsen/logrus"
+	"github.com/docker/docker/pkg/progress"
+	"github.com/docker/docker/pkg/random"
+	"golang.org/x/net/context"
+)
+
+const (
+	// maxRateLimitRetries is the number of times a request rate limited by
+	// a registry is retried before the response of the registry is
+	// returned.
+	maxRateLimitRetries = 5
+	// maxRateLimitDelay is the longest delay a rate limited request is
+	// retried after. Requests the registry asks to retry later fail
+	// instead of stalling the pull.
+	maxRateLimitDelay = 2 * time.Minute
+)
+
+// rateLimitBackoff is the delay before the first retry of a request rate
+// limited by a registry that does not send a Retry-After header. The delay
+// doubles with every retry.
+var rateLimitBackoff = 2 * time.Second
+
+type rateLimitOutputKey struct{}
+
+// withRateLimitOutput returns a context in which the registry requests
+// report their retries after being rate limited to out.
+func withRateLimitOutput(ctx context.Context, out progress.Output) context.Context {
+	return context.WithValue(ctx, rateLimitOutputKey{}, out)
+}
+
+// rateLimitTransport is an HTTP transport retrying the requests rate
+// limited by a registry, after the delay sent by the registry in the
+// Retry-After header, or with an exponential backoff.
+type rateLimitTransport struct {
+	ctx  context.Context
+	base http.RoundTripper
+	// out receives the retries of the requests. It may be nil.
+	out progress.Output
+}
+
+func newRateLimitTransport(ctx context.Context, base http.RoundTripper) *rateLimitTransport {
+	out, _ := ctx.Value(rateLimitOutputKey{}).(progress.Output)
+	return &rateLimitTransport{ctx: ctx, base: base, out: out}
+}
+
+func (t *rateLimitTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	for retries := 0; ; retries++ {
+		resp, err := t.base.RoundTrip(req)
+		if err != nil || !isRateLimited(resp) {
+			return resp, err
+		}
+		// Requests with a body, like blob uploads, cannot be sent again.
+		if req.Body != nil || retries == maxRateLimitRetries {
+			return resp, nil
+		}
+		delay, ok := rateLimitDelay(resp, retries)
+		if !ok {
+			return resp, nil
+		}
+		resp.Body.Close()
+
+		seconds := int((delay + time.Second - 1) / time.Second)
+		message := fmt.Sprintf("Rate limited by registry %s, retrying in %d second%s", req.URL.Host, seconds, (map[bool]string{true: "s"})[seconds != 1])
+		logrus.Warnf("%s: %s %s", message, req.Method, req.URL)
+		if t.out != nil {
+			progress.Message(t.out, "", message)
+		}
+
+		select {
+		case <-time.After(delay):
+		case <-t.ctx.Done():
+			return nil, t.ctx.Err()
+		}
+	}
+}
+
+// isRateLimited returns whether resp asks to retry the request later.
+func isRateLimited(resp *http.Response) bool {
+	switch resp.StatusCode {
+	case http.StatusTooManyRequests:
+		return true
+	case http.StatusServiceUnavailable:
+		return resp.Header.Get("Retry-After") != ""
+	}
+	return false
+}
+
+// rateLimitDelay returns the delay to retry the request rate limited by
+// resp after, with some jitter so that the concurrent requests of a pull
+// are spread out. It returns false if the delay is too long to wait for.
+func rateLimitDelay(resp *http.Response, retries int) (time.Duration, bool) {
+	delay := rateLimitBackoff << uint(retries)
+	if retryAfter := resp.Header.Get("Retry-After"); retryAfter != "" {
+		if seconds, err := strconv.Atoi(retryAfter); err == nil && seconds >= 0 {
+			delay = time.Duration(seconds) * time.Second
+		} else if date, err := http.ParseTime(retryAfter); err == nil {
+			delay = date.Sub(time.Now())
+			if delay < 0 {
+				delay = 0
+			}
+		}
+	}
+	if delay > maxRateLimitDelay {
+		return 0, false
+	}
+	return delay + time.Duration(random.Rand.Int63n(int64(delay/4+rateLimitBackoff/4)+1)), true
+}
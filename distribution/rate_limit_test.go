@@ -0,0 +1,126 @@
+package distribution
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/docker/docker/pkg/progress"
+	"golang.org/x/net/context"
+)
+
+type rateLimitedServer struct {
+	mu         sync.Mutex
+	requests   int
+	limited    int
+	retryAfter string
+}
+
+func (s *rateLimitedServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.requests++
+	if s.requests <= s.limited {
+		if s.retryAfter != "" {
+			w.Header().Set("Retry-After", s.retryAfter)
+		}
+		w.WriteHeader(http.StatusTooManyRequests)
+		return
+	}
+	w.Write([]byte("ok"))
+}
+
+func TestRateLimitTransport(t *testing.T) {
+	defer func(backoff time.Duration) { rateLimitBackoff = backoff }(rateLimitBackoff)
+	rateLimitBackoff = 10 * time.Millisecond
+
+	for _, s := range []*rateLimitedServer{
+		{limited: 2},
+		{limited: 1, retryAfter: "0"},
+		{limited: 1, retryAfter: time.Now().UTC().Format(http.TimeFormat)},
+	} {
+		ts := httptest.NewServer(s)
+		progressChan := make(chan progress.Progress, 10)
+		ctx := withRateLimitOutput(context.Background(), progress.ChanOutput(progressChan))
+		client := &http.Client{Transport: newRateLimitTransport(ctx, http.DefaultTransport)}
+
+		resp, err := client.Get(ts.URL)
+		ts.Close()
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp.Body.Close()
+		if resp.StatusCode != http.StatusOK {
+			t.Fatalf("expected the request to be retried until it succeeds, got %s", resp.Status)
+		}
+		if s.requests != s.limited+1 {
+			t.Fatalf("expected %d requests, got %d", s.limited+1, s.requests)
+		}
+		if len(progressChan) != s.limited {
+			t.Fatalf("expected %d progress messages, got %d", s.limited, len(progressChan))
+		}
+		if p := <-progressChan; !strings.HasPrefix(p.Message, "Rate limited by registry 127.0.0.1:") {
+			t.Fatalf("unexpected progress message: %q", p.Message)
+		}
+	}
+}
+
+func TestRateLimitTransportGivesUp(t *testing.T) {
+	defer func(backoff time.Duration) { rateLimitBackoff = backoff }(rateLimitBackoff)
+	rateLimitBackoff = time.Millisecond
+
+	for _, tc := range []struct {
+		server   *rateLimitedServer
+		requests int
+	}{
+		// too many retries
+		{&rateLimitedServer{limited: maxRateLimitRetries + 1}, maxRateLimitRetries + 1},
+		// delay too long
+		{&rateLimitedServer{limited: 1, retryAfter: "3600"}, 1},
+	} {
+		ts := httptest.NewServer(tc.server)
+		client := &http.Client{Transport: newRateLimitTransport(context.Background(), http.DefaultTransport)}
+
+		resp, err := client.Get(ts.URL)
+		ts.Close()
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp.Body.Close()
+		if resp.StatusCode != http.StatusTooManyRequests {
+			t.Fatalf("expected the rate limiting response, got %s", resp.Status)
+		}
+		if tc.server.requests != tc.requests {
+			t.Fatalf("expected %d requests, got %d", tc.requests, tc.server.requests)
+		}
+	}
+
+	// requests with a body are not retried
+	s := &rateLimitedServer{limited: 1}
+	ts := httptest.NewServer(s)
+	defer ts.Close()
+	client := &http.Client{Transport: newRateLimitTransport(context.Background(), http.DefaultTransport)}
+	resp, err := client.Post(ts.URL, "application/octet-stream", strings.NewReader("layer"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusTooManyRequests || s.requests != 1 {
+		t.Fatalf("expected the request with a body not to be retried, got %s after %d requests", resp.Status, s.requests)
+	}
+}
+
+func TestRateLimitTransportCancel(t *testing.T) {
+	ts := httptest.NewServer(&rateLimitedServer{limited: 1, retryAfter: "60"})
+	defer ts.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	client := &http.Client{Transport: newRateLimitTransport(ctx, http.DefaultTransport)}
+	time.AfterFunc(10*time.Millisecond, cancel)
+	if _, err := client.Get(ts.URL); err == nil || !strings.Contains(err.Error(), context.Canceled.Error()) {
+		t.Fatalf("expected the retry to be cancelled, got %v", err)
+	}
+}
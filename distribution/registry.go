@@ -65,8 +65,10 @@ func NewV2Repository(ctx context.Context, repoInfo *registry.RepositoryInfo, end
 		base.Dial = proxyDialer.Dial
 	}
 
+	rateLimited := newRateLimitTransport(ctx, base)
+
 	modifiers := registry.DockerHeaders(dockerversion.DockerUserAgent(ctx), metaHeaders)
-	authTransport := transport.NewTransport(base, modifiers...)
+	authTransport := transport.NewTransport(rateLimited, modifiers...)
 
 	challengeManager, foundVersion, err := registry.PingV2Registry(endpoint, authTransport)
 	if err != nil {
@@ -98,11 +100,11 @@ func NewV2Repository(ctx context.Context, repoInfo *registry.RepositoryInfo, end
 			},
 			ClientID: registry.AuthClientID,
 		}
-		tokenHandler := auth.NewTokenHandlerWithOptions(tokenHandlerOptions)
+		tokenHandler := newSharedTokenHandler(tokenHandlerOptions, authConfig)
 		basicHandler := auth.NewBasicHandler(creds)
 		modifiers = append(modifiers, auth.NewAuthorizer(challengeManager, tokenHandler, basicHandler))
 	}
-	tr := transport.NewTransport(base, modifiers...)
+	tr := transport.NewTransport(rateLimited, modifiers...)
 
 	repoNameRef, err := distreference.ParseNamed(repoName)
 	if err != nil {
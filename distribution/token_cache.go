@@ -0,0 +1,163 @@
+package distribution
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/docker/distribution/registry/client/auth"
+	"github.com/docker/engine-api/types"
+)
+
+// tokenHandlerIdleTimeout is how long the token handler of a scope is kept
+// without being used. Tokens usually expire well before.
+const tokenHandlerIdleTimeout = 15 * time.Minute
+
+// tokenHandlers holds the token handlers shared by all the pulls and
+// pushes of the daemon.
+var tokenHandlers = &tokenHandlerCache{handlers: make(map[string]*cachedTokenHandler)}
+
+// tokenHandlerCache shares token handlers, and the bearer tokens they hold
+// until the tokens expire, between the operations against registries. A
+// handler is shared by the requests for the same token server, service,
+// credentials and scopes.
+type tokenHandlerCache struct {
+	mu       sync.Mutex
+	handlers map[string]*cachedTokenHandler
+}
+
+// cachedTokenHandler is a token handler which only keeps its token between
+// operations. Its tokens are fetched with the transport of the operation
+// requesting them, so that it does not keep the context or the progress
+// output of the operation which created it.
+type cachedTokenHandler struct {
+	// mu serializes the requests authorized by the handler while transport
+	// is set to the transport of the operation.
+	mu        sync.Mutex
+	handler   auth.AuthenticationHandler
+	transport *operationTransport
+	lastUsed  time.Time
+}
+
+// authorizeRequest authorizes req, fetching the token with base if it
+// expired.
+func (cached *cachedTokenHandler) authorizeRequest(base http.RoundTripper, req *http.Request, params map[string]string) error {
+	cached.mu.Lock()
+	defer cached.mu.Unlock()
+	cached.transport.base = base
+	defer func() { cached.transport.base = nil }()
+	return cached.handler.AuthorizeRequest(req, params)
+}
+
+// operationTransport sends the token requests of a cached token handler
+// with the transport of the operation being authorized.
+type operationTransport struct {
+	base http.RoundTripper
+}
+
+func (t *operationTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if t.base == nil {
+		return nil, errors.New("token requested outside of an operation")
+	}
+	return t.base.RoundTrip(req)
+}
+
+// CancelRequest cancels a token request, for the timeout of the client of
+// the token handler.
+func (t *operationTransport) CancelRequest(req *http.Request) {
+	type canceler interface {
+		CancelRequest(*http.Request)
+	}
+	if c, ok := t.base.(canceler); ok {
+		c.CancelRequest(req)
+	}
+}
+
+// get returns the token handler of key, creating it from options if it is
+// not cached. The transport of options is not used.
+func (c *tokenHandlerCache) get(key string, options auth.TokenHandlerOptions) *cachedTokenHandler {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	now := time.Now()
+	for k, cached := range c.handlers {
+		if now.Sub(cached.lastUsed) > tokenHandlerIdleTimeout {
+			delete(c.handlers, k)
+		}
+	}
+
+	cached, ok := c.handlers[key]
+	if !ok {
+		cached = &cachedTokenHandler{transport: &operationTransport{}}
+		options.Transport = cached.transport
+		cached.handler = auth.NewTokenHandlerWithOptions(options)
+		c.handlers[key] = cached
+	}
+	cached.lastUsed = now
+	return cached
+}
+
+// sharedTokenHandler is an authentication handler getting bearer tokens
+// from the shared token handlers, so that the tokens fetched for a scope
+// are reused by the following requests and operations until they expire.
+type sharedTokenHandler struct {
+	cache   *tokenHandlerCache
+	options auth.TokenHandlerOptions
+	// credentials identifies the credentials of options, so that tokens
+	// are only shared between operations using the same credentials.
+	credentials string
+}
+
+func newSharedTokenHandler(options auth.TokenHandlerOptions, authConfig *types.AuthConfig) auth.AuthenticationHandler {
+	h := sha256.New()
+	for _, s := range []string{authConfig.Username, authConfig.Password, authConfig.IdentityToken} {
+		h.Write([]byte(s))
+		h.Write([]byte{0})
+	}
+	return &sharedTokenHandler{
+		cache:       tokenHandlers,
+		options:     options,
+		credentials: hex.EncodeToString(h.Sum(nil)),
+	}
+}
+
+func (th *sharedTokenHandler) Scheme() string {
+	return "bearer"
+}
+
+func (th *sharedTokenHandler) AuthorizeRequest(req *http.Request, params map[string]string) error {
+	options := th.options
+	scopes := make([]string, 0, len(options.Scopes)+1)
+	for _, scope := range options.Scopes {
+		scopes = append(scopes, scope.String())
+	}
+
+	// Cross-repository blob mounts need a token for the source repository
+	// as well. Such tokens are cached under their own scopes instead of
+	// being fetched for every mounted blob.
+	authReq := req
+	if from := req.URL.Query().Get("from"); from != "" {
+		fromScope := auth.RepositoryScope{Repository: from, Actions: []string{"pull"}}
+		options.Scopes = append(append([]auth.Scope{}, options.Scopes...), fromScope)
+		scopes = append(scopes, fromScope.String())
+
+		u := *req.URL
+		query := u.Query()
+		query.Del("from")
+		u.RawQuery = query.Encode()
+		authReq = &http.Request{URL: &u, Header: make(http.Header)}
+	}
+
+	key := strings.Join([]string{params["realm"], params["service"], th.credentials, strings.Join(scopes, " ")}, "\n")
+	if err := th.cache.get(key, options).authorizeRequest(th.options.Transport, authReq, params); err != nil {
+		return err
+	}
+	if authReq != req {
+		req.Header.Set("Authorization", authReq.Header.Get("Authorization"))
+	}
+	return nil
+}
@@ -0,0 +1,131 @@
+package distribution
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/docker/distribution/registry/client/auth"
+	"github.com/docker/engine-api/types"
+)
+
+type tokenServer struct {
+	mu     sync.Mutex
+	scopes []string
+}
+
+func (s *tokenServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.scopes = append(s.scopes, fmt.Sprint(r.URL.Query()["scope"]))
+	fmt.Fprintf(w, `{"token": "token-%d", "expires_in": 300}`, len(s.scopes))
+}
+
+func (s *tokenServer) fetches() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return len(s.scopes)
+}
+
+func authorize(t *testing.T, th auth.AuthenticationHandler, url string, params map[string]string) string {
+	req, err := http.NewRequest("GET", url, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := th.AuthorizeRequest(req, params); err != nil {
+		t.Fatal(err)
+	}
+	return req.Header.Get("Authorization")
+}
+
+func TestSharedTokenHandler(t *testing.T) {
+	server := &tokenServer{}
+	ts := httptest.NewServer(server)
+	defer ts.Close()
+
+	cache := &tokenHandlerCache{handlers: make(map[string]*cachedTokenHandler)}
+	newHandler := func(repository string, authConfig *types.AuthConfig) auth.AuthenticationHandler {
+		th := newSharedTokenHandler(auth.TokenHandlerOptions{
+			Transport:   http.DefaultTransport,
+			Credentials: dumbCredentialStore{auth: authConfig},
+			Scopes:      []auth.Scope{auth.RepositoryScope{Repository: repository, Actions: []string{"pull"}}},
+		}, authConfig)
+		th.(*sharedTokenHandler).cache = cache
+		return th
+	}
+	params := map[string]string{"realm": ts.URL, "service": "registry.example.com"}
+	anonymous := &types.AuthConfig{}
+
+	// operations on the same repository share the token
+	first := authorize(t, newHandler("library/busybox", anonymous), "https://registry.example.com/v2/library/busybox/blobs/sha256:1", params)
+	second := authorize(t, newHandler("library/busybox", anonymous), "https://registry.example.com/v2/library/busybox/blobs/sha256:2", params)
+	if first != "Bearer token-1" || second != first {
+		t.Fatalf("expected the token to be shared, got %q and %q", first, second)
+	}
+
+	// other repositories, credentials and services get their own token
+	authorize(t, newHandler("library/alpine", anonymous), "https://registry.example.com/v2/library/alpine/blobs/sha256:1", params)
+	authorize(t, newHandler("library/busybox", &types.AuthConfig{Username: "user", Password: "pass"}), "https://registry.example.com/v2/library/busybox/blobs/sha256:1", params)
+	authorize(t, newHandler("library/busybox", anonymous), "https://other.example.com/v2/library/busybox/blobs/sha256:1", map[string]string{"realm": ts.URL, "service": "other.example.com"})
+	if n := server.fetches(); n != 4 {
+		t.Fatalf("expected 4 token fetches, got %d", n)
+	}
+
+	// tokens for blob mounts are cached under the scopes of the source
+	// repository
+	th := newHandler("library/busybox", anonymous)
+	for i := 0; i < 3; i++ {
+		authorize(t, th, "https://registry.example.com/v2/library/busybox/blobs/uploads/?mount=sha256:1&from=library/alpine", params)
+	}
+	if n := server.fetches(); n != 5 {
+		t.Fatalf("expected one token fetch for mounts, got %d", n-4)
+	}
+	if scopes := server.scopes[4]; scopes != "[repository:library/busybox:pull repository:library/alpine:pull]" {
+		t.Fatalf("unexpected scopes for mount token: %s", scopes)
+	}
+}
+
+type failingTransport struct{}
+
+func (failingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	return nil, errors.New("operation cancelled")
+}
+
+func TestSharedTokenHandlerUsesOperationTransport(t *testing.T) {
+	server := &tokenServer{}
+	ts := httptest.NewServer(server)
+	defer ts.Close()
+
+	cache := &tokenHandlerCache{handlers: make(map[string]*cachedTokenHandler)}
+	newHandler := func(transport http.RoundTripper) auth.AuthenticationHandler {
+		authConfig := &types.AuthConfig{}
+		th := newSharedTokenHandler(auth.TokenHandlerOptions{
+			Transport:   transport,
+			Credentials: dumbCredentialStore{auth: authConfig},
+			Scopes:      []auth.Scope{auth.RepositoryScope{Repository: "library/busybox", Actions: []string{"pull"}}},
+		}, authConfig)
+		th.(*sharedTokenHandler).cache = cache
+		return th
+	}
+	params := map[string]string{"realm": ts.URL, "service": "registry.example.com"}
+
+	// the operation creating the cached handler fails to fetch the token
+	req, err := http.NewRequest("GET", "https://registry.example.com/v2/library/busybox/blobs/sha256:1", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := newHandler(failingTransport{}).AuthorizeRequest(req, params); err == nil {
+		t.Fatal("expected the token fetch to fail")
+	}
+
+	// the next operation fetches it with its own transport
+	if token := authorize(t, newHandler(http.DefaultTransport), "https://registry.example.com/v2/library/busybox/blobs/sha256:2", params); token != "Bearer token-1" {
+		t.Fatalf("unexpected token %q", token)
+	}
+	if n := len(cache.handlers); n != 1 {
+		t.Fatalf("expected the handler to be shared, got %d handlers", n)
+	}
+}
@@ -113,7 +113,7 @@ func (ldm *LayerDownloadManager) Download(ctx context.Context, initialRootFS ima
 				if err == nil {
 					// Layer already exists.
 					logrus.Debugf("Layer already exists: %s", descriptor.ID())
-					progress.Update(progressOutput, descriptor.ID(), "Already exists")
+					progress.UpdatePhase(progressOutput, descriptor.ID(), progress.PhaseExists, "Already exists")
 					if topLayer != nil {
 						layer.ReleaseAndLog(ldm.layerStore, topLayer)
 					}
@@ -137,7 +137,7 @@ func (ldm *LayerDownloadManager) Download(ctx context.Context, initialRootFS ima
 		}
 
 		// Layer is not known to exist - download and register it.
-		progress.Update(progressOutput, descriptor.ID(), "Pulling fs layer")
+		progress.UpdatePhase(progressOutput, descriptor.ID(), progress.PhasePreparing, "Pulling fs layer")
 
 		var xferFunc DoFunc
 		if topDownload != nil {
@@ -213,12 +213,12 @@ func (ldm *LayerDownloadManager) makeDownloadFunc(descriptor DownloadDescriptor,
 				close(progressChan)
 			}()
 
-			progressOutput := progress.ChanOutput(progressChan)
+			progressOutput := &retryOutput{out: progress.ChanOutput(progressChan)}
 
 			select {
 			case <-start:
 			default:
-				progress.Update(progressOutput, descriptor.ID(), "Waiting")
+				progress.UpdatePhase(progressOutput, descriptor.ID(), progress.PhaseWaiting, "Waiting")
 				<-start
 			}
 
@@ -266,6 +266,7 @@ func (ldm *LayerDownloadManager) makeDownloadFunc(descriptor DownloadDescriptor,
 					d.err = err
 					return
 				}
+				progressOutput.retries = retries
 
 				logrus.Errorf("Download failed, retrying: %v", err)
 				delay := retries * 5
@@ -273,7 +274,7 @@ func (ldm *LayerDownloadManager) makeDownloadFunc(descriptor DownloadDescriptor,
 
 			selectLoop:
 				for {
-					progress.Updatef(progressOutput, descriptor.ID(), "Retrying in %d second%s", delay, (map[bool]string{true: "s"})[delay != 1])
+					progress.UpdatePhase(progressOutput, descriptor.ID(), progress.PhaseRetrying, fmt.Sprintf("Retrying in %d second%s", delay, (map[bool]string{true: "s"})[delay != 1]))
 					select {
 					case <-ticker.C:
 						delay--
@@ -310,7 +311,7 @@ func (ldm *LayerDownloadManager) makeDownloadFunc(descriptor DownloadDescriptor,
 				parentLayer = l.ChainID()
 			}
 
-			reader := progress.NewProgressReader(ioutils.NewCancelReadCloser(d.Transfer.Context(), downloadReader), progressOutput, size, descriptor.ID(), "Extracting")
+			reader := progress.NewPhaseReader(ioutils.NewCancelReadCloser(d.Transfer.Context(), downloadReader), progressOutput, size, descriptor.ID(), progress.PhaseExtracting, "Extracting")
 			defer reader.Close()
 
 			inflatedLayerData, err := archive.DecompressStream(reader)
@@ -338,7 +339,7 @@ func (ldm *LayerDownloadManager) makeDownloadFunc(descriptor DownloadDescriptor,
 				return
 			}
 
-			progress.Update(progressOutput, descriptor.ID(), "Pull complete")
+			progress.UpdatePhase(progressOutput, descriptor.ID(), progress.PhaseComplete, "Pull complete")
 			withRegistered, hasRegistered := descriptor.(DownloadDescriptorWithRegistered)
 			if hasRegistered {
 				withRegistered.Registered(d.layer.DiffID())
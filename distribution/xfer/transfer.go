@@ -19,6 +19,20 @@ func (e DoNotRetry) Error() string {
 	return e.Err.Error()
 }
 
+// retryOutput forwards progress updates of a transfer, recording how
+// many times the transfer has been retried on them.
+type retryOutput struct {
+	out     progress.Output
+	retries int
+}
+
+func (o *retryOutput) WriteProgress(p progress.Progress) error {
+	if p.Retries == 0 {
+		p.Retries = o.retries
+	}
+	return o.out.WriteProgress(p)
+}
+
 // Watcher is returned by Watch and can be passed to Release to stop watching.
 type Watcher struct {
 	// signalChan is used to signal to the watcher goroutine that
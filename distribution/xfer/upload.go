@@ -2,6 +2,7 @@ package xfer
 
 import (
 	"errors"
+	"fmt"
 	"time"
 
 	"github.com/Sirupsen/logrus"
@@ -65,7 +66,7 @@ func (lum *LayerUploadManager) Upload(ctx context.Context, layers []UploadDescri
 	)
 
 	for _, descriptor := range layers {
-		progress.Update(progressOutput, descriptor.ID(), "Preparing")
+		progress.UpdatePhase(progressOutput, descriptor.ID(), progress.PhasePreparing, "Preparing")
 
 		key := descriptor.Key()
 		if _, present := dedupDescriptors[key]; present {
@@ -107,12 +108,12 @@ func (lum *LayerUploadManager) makeUploadFunc(descriptor UploadDescriptor) DoFun
 				close(progressChan)
 			}()
 
-			progressOutput := progress.ChanOutput(progressChan)
+			progressOutput := &retryOutput{out: progress.ChanOutput(progressChan)}
 
 			select {
 			case <-start:
 			default:
-				progress.Update(progressOutput, descriptor.ID(), "Waiting")
+				progress.UpdatePhase(progressOutput, descriptor.ID(), progress.PhaseWaiting, "Waiting")
 				<-start
 			}
 
@@ -139,6 +140,7 @@ func (lum *LayerUploadManager) makeUploadFunc(descriptor UploadDescriptor) DoFun
 					u.err = err
 					return
 				}
+				progressOutput.retries = retries
 
 				logrus.Errorf("Upload failed, retrying: %v", err)
 				delay := retries * 5
@@ -146,7 +148,7 @@ func (lum *LayerUploadManager) makeUploadFunc(descriptor UploadDescriptor) DoFun
 
 			selectLoop:
 				for {
-					progress.Updatef(progressOutput, descriptor.ID(), "Retrying in %d second%s", delay, (map[bool]string{true: "s"})[delay != 1])
+					progress.UpdatePhase(progressOutput, descriptor.ID(), progress.PhaseRetrying, fmt.Sprintf("Retrying in %d second%s", delay, (map[bool]string{true: "s"})[delay != 1]))
 					select {
 					case <-ticker.C:
 						delay--
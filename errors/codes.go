@@ -0,0 +1,86 @@
+package errors
+
+import "net/http"
+
+// The codes of the API errors. Unlike its message, the code of an error is
+// stable across the releases, so that the clients can tell the errors apart
+// without matching their messages.
+const (
+	// CodeInvalidParameter is the generic code of the 400 errors.
+	CodeInvalidParameter = "INVALID_PARAMETER"
+	// CodeUnauthorized is the generic code of the 401 errors.
+	CodeUnauthorized = "UNAUTHORIZED"
+	// CodeForbidden is the generic code of the 403 errors.
+	CodeForbidden = "FORBIDDEN"
+	// CodeNotFound is the generic code of the 404 errors.
+	CodeNotFound = "NOT_FOUND"
+	// CodeNotAcceptable is the generic code of the 406 errors.
+	CodeNotAcceptable = "NOT_ACCEPTABLE"
+	// CodeTimeout is the generic code of the 408 errors.
+	CodeTimeout = "TIMEOUT"
+	// CodeConflict is the generic code of the 409 errors.
+	CodeConflict = "CONFLICT"
+	// CodeRequestTooLarge is the generic code of the 413 errors.
+	CodeRequestTooLarge = "REQUEST_TOO_LARGE"
+	// CodeInternal is the generic code of the 500 errors,
+	// and of the errors with an unknown status code.
+	CodeInternal = "INTERNAL"
+	// CodeNotImplemented is the generic code of the 501 errors.
+	CodeNotImplemented = "NOT_IMPLEMENTED"
+	// CodeUnavailable is the generic code of the 503 errors.
+	CodeUnavailable = "UNAVAILABLE"
+
+	// CodeNoSuchContainer is the code of the errors
+	// for a container which doesn't exist.
+	CodeNoSuchContainer = "NO_SUCH_CONTAINER"
+	// CodeNoSuchImage is the code of the errors
+	// for an image which doesn't exist.
+	CodeNoSuchImage = "NO_SUCH_IMAGE"
+	// CodeNoSuchExec is the code of the errors
+	// for an exec instance which doesn't exist.
+	CodeNoSuchExec = "NO_SUCH_EXEC"
+	// CodeNoSuchUpload is the code of the errors
+	// for an archive upload which doesn't exist.
+	CodeNoSuchUpload = "NO_SUCH_UPLOAD"
+	// CodeUploadOffsetMismatch is the code of the errors for a chunk of an
+	// archive upload which doesn't start where the upload stopped.
+	CodeUploadOffsetMismatch = "UPLOAD_OFFSET_MISMATCH"
+	// CodeUploadBusy is the code of the errors for an archive upload
+	// which is receiving another chunk.
+	CodeUploadBusy = "UPLOAD_BUSY"
+	// CodeAmbiguousID is the code of the errors
+	// for an ID prefix which matches several objects.
+	CodeAmbiguousID = "AMBIGUOUS_ID"
+	// CodeImageBlocked is the code of the errors for an image
+	// which the image policy of the daemon doesn't allow.
+	CodeImageBlocked = "IMAGE_BLOCKED"
+)
+
+// CodeForStatus returns the generic code
+// of the errors with an HTTP status code.
+func CodeForStatus(statusCode int) string {
+	switch statusCode {
+	case http.StatusBadRequest:
+		return CodeInvalidParameter
+	case http.StatusUnauthorized:
+		return CodeUnauthorized
+	case http.StatusForbidden:
+		return CodeForbidden
+	case http.StatusNotFound:
+		return CodeNotFound
+	case http.StatusNotAcceptable:
+		return CodeNotAcceptable
+	case http.StatusRequestTimeout:
+		return CodeTimeout
+	case http.StatusConflict:
+		return CodeConflict
+	case http.StatusRequestEntityTooLarge:
+		return CodeRequestTooLarge
+	case http.StatusNotImplemented:
+		return CodeNotImplemented
+	case http.StatusServiceUnavailable:
+		return CodeUnavailable
+	default:
+		return CodeInternal
+	}
+}
@@ -7,6 +7,8 @@ import "net/http"
 type apiError struct {
 	error
 	statusCode int
+	code       string
+	details    map[string]string
 }
 
 // HTTPErrorStatusCode returns a status code.
@@ -14,12 +16,35 @@ func (e apiError) HTTPErrorStatusCode() int {
 	return e.statusCode
 }
 
+// ErrorCode returns the machine-readable code of the error,
+// the generic code of its status code by default.
+func (e apiError) ErrorCode() string {
+	if e.code != "" {
+		return e.code
+	}
+	return CodeForStatus(e.statusCode)
+}
+
+// ErrorDetails returns the details of the error,
+// like the name of the object which is not found.
+func (e apiError) ErrorDetails() map[string]string {
+	return e.details
+}
+
 // NewErrorWithStatusCode allows you to associate
 // a specific HTTP Status Code to an error.
 // The Server will take that code and set
 // it as the response status.
 func NewErrorWithStatusCode(err error, code int) error {
-	return apiError{err, code}
+	return apiError{error: err, statusCode: code}
+}
+
+// NewErrorWithCode associates a specific HTTP Status Code,
+// a machine-readable code and details to an error.
+// The Server returns the code and the details
+// in the body of the response.
+func NewErrorWithCode(err error, statusCode int, code string, details map[string]string) error {
+	return apiError{error: err, statusCode: statusCode, code: code, details: details}
 }
 
 // NewBadRequestError creates a new API error
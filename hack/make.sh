@@ -330,6 +330,16 @@ copy_containerd() {
 					hash_files "$dir/docker-$file"
 				fi
 			done
+			# docker-init is only needed by the containers run with --init,
+			# bundle it when it is installed
+			if [ -x "$(which docker-init)" ]; then
+				cp "$(which docker-init)" "$dir/"
+				if [ "$2" == "hash" ]; then
+					hash_files "$dir/docker-init"
+				fi
+			else
+				echo "docker-init not found, skipping it"
+			fi
 		fi
 	fi
 }
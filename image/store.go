@@ -4,7 +4,11 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"os"
+	"sort"
+	"strings"
 	"sync"
+	"time"
 
 	"github.com/Sirupsen/logrus"
 	"github.com/docker/distribution/digest"
@@ -19,6 +23,8 @@ type Store interface {
 	Search(partialID string) (ID, error)
 	SetParent(id ID, parent ID) error
 	GetParent(id ID) (ID, error)
+	SetLastUsed(id ID, t time.Time) error
+	GetLastUsed(id ID) (time.Time, error)
 	Children(id ID) []ID
 	Map() map[ID]*Image
 	Heads() map[ID]*Image
@@ -30,6 +36,18 @@ type LayerGetReleaser interface {
 	Release(layer.Layer) ([]layer.Metadata, error)
 }
 
+// ErrAmbiguousPrefix is returned by Search when several images match the ID
+// prefix.
+type ErrAmbiguousPrefix struct {
+	Prefix string
+	// Candidates are the IDs of the images matching the prefix, sorted.
+	Candidates []ID
+}
+
+func (e ErrAmbiguousPrefix) Error() string {
+	return fmt.Sprintf("Multiple images found with provided prefix: %s", e.Prefix)
+}
+
 type imageMeta struct {
 	layer    layer.Layer
 	children map[ID]struct{}
@@ -170,14 +188,35 @@ func (is *store) Search(term string) (ID, error) {
 
 	dgst, err := is.digestSet.Lookup(term)
 	if err != nil {
-		if err == digest.ErrDigestNotFound {
+		switch err {
+		case digest.ErrDigestNotFound:
 			err = fmt.Errorf("No such image: %s", term)
+		case digest.ErrDigestAmbiguous:
+			err = ErrAmbiguousPrefix{Prefix: term, Candidates: is.candidates(term)}
 		}
 		return "", err
 	}
 	return ID(dgst), nil
 }
 
+// candidates returns the IDs of the images matching the prefix, sorted.
+func (is *store) candidates(prefix string) []ID {
+	var ids []ID
+	for id := range is.images {
+		if strings.HasPrefix(id.String(), prefix) || strings.HasPrefix(digest.Digest(id).Hex(), prefix) {
+			ids = append(ids, id)
+		}
+	}
+	sort.Sort(byID(ids))
+	return ids
+}
+
+type byID []ID
+
+func (r byID) Len() int           { return len(r) }
+func (r byID) Swap(i, j int)      { r[i], r[j] = r[j], r[i] }
+func (r byID) Less(i, j int) bool { return r[i] < r[j] }
+
 func (is *store) Get(id ID) (*Image, error) {
 	// todo: Check if image is in images
 	// todo: Detect manual insertions and start using them
@@ -249,6 +288,30 @@ func (is *store) GetParent(id ID) (ID, error) {
 	return ID(d), nil // todo: validate?
 }
 
+// SetLastUsed records t as the last time a container was created or started
+// from the image.
+func (is *store) SetLastUsed(id ID, t time.Time) error {
+	is.Lock()
+	defer is.Unlock()
+	if is.images[id] == nil {
+		return fmt.Errorf("unrecognized image ID %s", id.String())
+	}
+	return is.fs.SetMetadata(id, "lastUsed", []byte(t.UTC().Format(time.RFC3339Nano)))
+}
+
+// GetLastUsed returns the last time a container was created or started from
+// the image, or the zero time if it was never used.
+func (is *store) GetLastUsed(id ID) (time.Time, error) {
+	d, err := is.fs.GetMetadata(id, "lastUsed")
+	if err != nil {
+		if os.IsNotExist(err) {
+			return time.Time{}, nil
+		}
+		return time.Time{}, err
+	}
+	return time.Parse(time.RFC3339Nano, string(d))
+}
+
 func (is *store) Children(id ID) []ID {
 	is.Lock()
 	defer is.Unlock()
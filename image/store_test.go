@@ -1,9 +1,13 @@
 package image
 
 import (
+	"fmt"
 	"io/ioutil"
 	"os"
+	"reflect"
+	"sort"
 	"testing"
+	"time"
 
 	"github.com/docker/distribution/digest"
 	"github.com/docker/docker/layer"
@@ -233,6 +237,50 @@ func TestSearchAfterDelete(t *testing.T) {
 	}
 }
 
+func TestSearchAmbiguous(t *testing.T) {
+	tmpdir, err := ioutil.TempDir("", "images-fs-store")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(tmpdir)
+	fs, err := NewFSStoreBackend(tmpdir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	is, err := NewImageStore(fs, &mockLayerGetReleaser{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// with 17 images, at least two IDs start with the same hex digit
+	byDigit := make(map[string][]ID)
+	for i := 0; i < 17; i++ {
+		id, err := is.Create([]byte(fmt.Sprintf(`{"comment": "abc%d", "rootfs": {"type": "layers"}}`, i)))
+		if err != nil {
+			t.Fatal(err)
+		}
+		digit := digest.Digest(id).Hex()[:1]
+		byDigit[digit] = append(byDigit[digit], id)
+	}
+	for digit, ids := range byDigit {
+		if len(ids) < 2 {
+			continue
+		}
+		_, err := is.Search(digit)
+		ambiguous, ok := err.(ErrAmbiguousPrefix)
+		if !ok {
+			t.Fatalf("expected an ambiguous prefix error searching %s, got %v", digit, err)
+		}
+		sort.Sort(byID(ids))
+		if !reflect.DeepEqual(ambiguous.Candidates, ids) {
+			t.Fatalf("expected the candidates %v, got %v", ids, ambiguous.Candidates)
+		}
+		return
+	}
+	t.Fatal("expected two images with the same first hex digit")
+}
+
 func TestParentReset(t *testing.T) {
 	tmpdir, err := ioutil.TempDir("", "images-fs-store")
 	if err != nil {
@@ -289,6 +337,52 @@ func TestParentReset(t *testing.T) {
 
 }
 
+func TestLastUsed(t *testing.T) {
+	tmpdir, err := ioutil.TempDir("", "images-fs-store")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(tmpdir)
+	fs, err := NewFSStoreBackend(tmpdir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	is, err := NewImageStore(fs, &mockLayerGetReleaser{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	id, err := is.Create([]byte(`{"comment": "abc1", "rootfs": {"type": "layers"}}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	lastUsed, err := is.GetLastUsed(id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !lastUsed.IsZero() {
+		t.Fatalf("expected an unused image, got last use %v", lastUsed)
+	}
+
+	now := time.Now()
+	if err := is.SetLastUsed(id, now); err != nil {
+		t.Fatal(err)
+	}
+	lastUsed, err = is.GetLastUsed(id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !lastUsed.Equal(now) {
+		t.Fatalf("expected last use %v, got %v", now, lastUsed)
+	}
+
+	if err := is.SetLastUsed(ID("sha256:0000000000000000000000000000000000000000000000000000000000000000"), now); err == nil {
+		t.Fatal("expected an error recording the use of an unknown image")
+	}
+}
+
 type mockLayerGetReleaser struct{}
 
 func (ls *mockLayerGetReleaser) Get(layer.ChainID) (layer.Layer, error) {
@@ -1001,6 +1001,80 @@ func (s *DockerSuite) TestContainerApiDeleteNotExist(c *check.C) {
 	c.Assert(getErrorMessage(c, body), checker.Matches, "No such container: doesnotexist")
 }
 
+func (s *DockerSuite) TestContainerApiErrorCode(c *check.C) {
+	status, body, err := sockRequest("GET", "/containers/doesnotexist/json", nil)
+	c.Assert(err, checker.IsNil)
+	c.Assert(status, checker.Equals, http.StatusNotFound)
+
+	var resp types.ErrorResponse
+	c.Assert(json.Unmarshal(body, &resp), checker.IsNil)
+	c.Assert(resp.Code, checker.Equals, "NO_SUCH_CONTAINER")
+	c.Assert(resp.Details["container"], checker.Equals, "doesnotexist")
+}
+
+func (s *DockerSuite) TestContainerApiArchiveUpload(c *check.C) {
+	testRequires(c, DaemonIsLinux)
+	dockerCmd(c, "create", "--name", "upload", "busybox", "cat", "/tmp/file")
+
+	var buf bytes.Buffer
+	tw := tar.NewWriter(&buf)
+	content := strings.Repeat("hello world\n", 1024)
+	c.Assert(tw.WriteHeader(&tar.Header{Name: "file", Mode: 0644, Size: int64(len(content))}), checker.IsNil)
+	_, err := tw.Write([]byte(content))
+	c.Assert(err, checker.IsNil)
+	c.Assert(tw.Close(), checker.IsNil)
+	archive := buf.Bytes()
+
+	status, body, err := sockRequest("POST", "/containers/upload/archive/uploads?path=/tmp", nil)
+	c.Assert(err, checker.IsNil)
+	c.Assert(status, checker.Equals, http.StatusCreated, check.Commentf(string(body)))
+	var upload types.ArchiveUpload
+	c.Assert(json.Unmarshal(body, &upload), checker.IsNil)
+	c.Assert(upload.Offset, checker.Equals, int64(0))
+	endpoint := "/containers/upload/archive/uploads/" + upload.ID
+
+	half := len(archive) / 2
+	res, body2, err := sockRequestRaw("PUT", endpoint+"?offset=0", bytes.NewReader(archive[:half]), "application/x-tar")
+	c.Assert(err, checker.IsNil)
+	body2.Close()
+	c.Assert(res.StatusCode, checker.Equals, http.StatusOK)
+
+	// A chunk sent again is refused with the offset to resume from
+	res, body2, err = sockRequestRaw("PUT", endpoint+"?offset=0", bytes.NewReader(archive[:half]), "application/x-tar")
+	c.Assert(err, checker.IsNil)
+	c.Assert(res.StatusCode, checker.Equals, http.StatusConflict)
+	var errResp types.ErrorResponse
+	c.Assert(json.NewDecoder(body2).Decode(&errResp), checker.IsNil)
+	body2.Close()
+	c.Assert(errResp.Code, checker.Equals, "UPLOAD_OFFSET_MISMATCH")
+	c.Assert(errResp.Details["offset"], checker.Equals, strconv.Itoa(half))
+
+	status, body, err = sockRequest("GET", endpoint, nil)
+	c.Assert(err, checker.IsNil)
+	c.Assert(status, checker.Equals, http.StatusOK)
+	c.Assert(json.Unmarshal(body, &upload), checker.IsNil)
+	c.Assert(upload.Offset, checker.Equals, int64(half))
+
+	res, body2, err = sockRequestRaw("PUT", endpoint+"?offset="+strconv.Itoa(half), bytes.NewReader(archive[half:]), "application/x-tar")
+	c.Assert(err, checker.IsNil)
+	body2.Close()
+	c.Assert(res.StatusCode, checker.Equals, http.StatusOK)
+
+	status, body, err = sockRequest("POST", endpoint+"/extract", nil)
+	c.Assert(err, checker.IsNil)
+	c.Assert(status, checker.Equals, http.StatusOK, check.Commentf(string(body)))
+
+	out, _ := dockerCmd(c, "start", "-a", "upload")
+	c.Assert(out, checker.Equals, content)
+
+	// The upload is removed once extracted
+	status, body, err = sockRequest("GET", endpoint, nil)
+	c.Assert(err, checker.IsNil)
+	c.Assert(status, checker.Equals, http.StatusNotFound)
+	c.Assert(json.Unmarshal(body, &errResp), checker.IsNil)
+	c.Assert(errResp.Code, checker.Equals, "NO_SUCH_UPLOAD")
+}
+
 func (s *DockerSuite) TestContainerApiDeleteForce(c *check.C) {
 	out, _ := runSleepingContainer(c)
 
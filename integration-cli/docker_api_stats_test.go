@@ -279,3 +279,25 @@ func (s *DockerSuite) TestApiStatsNoStreamConnectedContainers(c *check.C) {
 		c.Fatalf("Stats did not return after timeout")
 	}
 }
+
+func (s *DockerSuite) TestApiStatsNoStreamMemoryAndBlkio(c *check.C) {
+	testRequires(c, DaemonIsLinux)
+	out, _ := dockerCmd(c, "run", "-d", "busybox", "sh", "-c", "dd if=/dev/zero of=/file bs=1M count=4 conv=fsync && top")
+	id := strings.TrimSpace(out)
+	c.Assert(waitRun(id), checker.IsNil)
+
+	resp, body, err := sockRequestRaw("GET", fmt.Sprintf("/containers/%s/stats?stream=false", id), nil, "")
+	c.Assert(err, checker.IsNil)
+	c.Assert(resp.StatusCode, checker.Equals, http.StatusOK)
+
+	var v *types.Stats
+	err = json.NewDecoder(body).Decode(&v)
+	c.Assert(err, checker.IsNil)
+	body.Close()
+
+	c.Assert(v.MemoryStats.RSS, checker.GreaterThan, uint64(0))
+	c.Assert(v.PidsStats.Current, checker.GreaterThan, uint64(0))
+	for _, entry := range v.BlkioStats.ThrottleIoServiceBytes {
+		c.Assert(entry.Op, checker.Not(checker.Equals), "")
+	}
+}
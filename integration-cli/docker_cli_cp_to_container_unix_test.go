@@ -4,8 +4,11 @@ package main
 
 import (
 	"fmt"
+	"io/ioutil"
 	"os"
+	"os/exec"
 	"path/filepath"
+	"strings"
 
 	"github.com/docker/docker/pkg/integration/checker"
 	"github.com/docker/docker/pkg/system"
@@ -37,3 +40,52 @@ func (s *DockerSuite) TestCpCheckDestOwnership(c *check.C) {
 	c.Assert(stat.UID(), checker.Equals, uint32(uid), check.Commentf("Copied file not owned by container root UID"))
 	c.Assert(stat.GID(), checker.Equals, uint32(gid), check.Commentf("Copied file not owned by container root GID"))
 }
+
+// Check ownership is kept with the archive mode, mapped to the IDs of the
+// host in userns enabled mode
+func (s *DockerSuite) TestCpArchiveKeepsOwnership(c *check.C) {
+	testRequires(c, DaemonIsLinux, SameHostDaemon)
+	tmpVolDir := getTestDir(c, "test-cp-tmpvol")
+	containerID := makeTestContainer(c,
+		testContainerOptions{volumes: []string{fmt.Sprintf("%s:/tmpvol", tmpVolDir)}})
+
+	tmpDir := getTestDir(c, "test-cp-to-keep-ownership")
+	defer os.RemoveAll(tmpDir)
+
+	makeTestContentInDir(c, tmpDir)
+	c.Assert(os.Lchown(filepath.Join(tmpDir, "file1"), 1000, 1001), checker.IsNil)
+
+	srcPath := cpPath(tmpDir, "file1")
+	dstPath := containerCpPath(containerID, "/tmpvol", "file1")
+
+	dockerCmd(c, "cp", "--archive", srcPath, dstPath)
+
+	stat, err := system.Stat(filepath.Join(tmpVolDir, "file1"))
+	c.Assert(err, checker.IsNil)
+	uid, gid, err := getRootUIDGID()
+	c.Assert(err, checker.IsNil)
+	c.Assert(stat.UID(), checker.Equals, uint32(uid+1000), check.Commentf("Copied file did not keep its UID"))
+	c.Assert(stat.GID(), checker.Equals, uint32(gid+1001), check.Commentf("Copied file did not keep its GID"))
+}
+
+func (s *DockerSuite) TestCpToContainerInChunks(c *check.C) {
+	testRequires(c, DaemonIsLinux, SameHostDaemon)
+	tmpVolDir := getTestDir(c, "test-cp-tmpvol")
+	containerID := makeTestContainer(c,
+		testContainerOptions{volumes: []string{fmt.Sprintf("%s:/tmpvol", tmpVolDir)}})
+
+	tmpDir := getTestDir(c, "test-cp-to-container-in-chunks")
+	defer os.RemoveAll(tmpDir)
+
+	content := strings.Repeat("hello world\n", 4096)
+	c.Assert(ioutil.WriteFile(filepath.Join(tmpDir, "file1"), []byte(content), 0644), checker.IsNil)
+
+	srcPath := cpPath(tmpDir, "file1")
+	dstPath := containerCpPath(containerID, "/tmpvol", "file1")
+
+	_, stderr, _, err := runCommandWithStdoutStderr(exec.Command(dockerBinary, "cp", "--chunk-size", "4k", srcPath, dstPath))
+	c.Assert(err, checker.IsNil, check.Commentf(stderr))
+	c.Assert(stderr, checker.Contains, "Copying to container")
+
+	c.Assert(fileContentEquals(c, filepath.Join(tmpVolDir, "file1"), content), checker.IsNil)
+}
@@ -71,6 +71,21 @@ func (s *DockerSuite) TestEventsUntag(c *check.C) {
 	}
 }
 
+func (s *DockerSuite) TestEventsFormat(c *check.C) {
+	since := daemonUnixTime(c)
+	dockerCmd(c, "run", "--rm", "--name", "events-format-test", "busybox", "true")
+	until := daemonUnixTime(c)
+
+	out, _ := dockerCmd(c, "events", "--since", since, "--until", until, "--filter", "container=events-format-test", "--format", "{{.Type}} {{.Action}} {{index .Actor.Attributes \"name\"}}")
+	events := strings.Split(strings.TrimSpace(out), "\n")
+	c.Assert(events, checker.HasLen, 5, check.Commentf("events: %v", events))
+	c.Assert(events[0], checker.Equals, "container create events-format-test")
+	c.Assert(events[4], checker.Equals, "container destroy events-format-test")
+
+	_, _, err := dockerCmdWithError("events", "--until", until, "--format", "{{.Type")
+	c.Assert(err, checker.NotNil, check.Commentf("an invalid template should fail"))
+}
+
 func (s *DockerSuite) TestEventsContainerFailStartDie(c *check.C) {
 	_, _, err := dockerCmdWithError("run", "--name", "testeventdie", "busybox", "blerg")
 	c.Assert(err, checker.NotNil, check.Commentf("Container run with command blerg should have failed, but it did not"))
@@ -0,0 +1,51 @@
+package main
+
+import (
+	"strings"
+
+	"github.com/docker/docker/pkg/integration/checker"
+	"github.com/go-check/check"
+)
+
+func (s *DockerSuite) TestPruneContainers(c *check.C) {
+	testRequires(c, DaemonIsLinux)
+
+	dockerCmd(c, "run", "--name", "prune-stopped", "--label", "prune=1", "busybox", "true")
+	dockerCmd(c, "run", "--name", "prune-kept", "busybox", "true")
+	dockerCmd(c, "run", "-d", "--name", "prune-running", "--label", "prune=1", "busybox", "top")
+	stoppedID := strings.TrimSpace(inspectField(c, "prune-stopped", "Id"))
+
+	out, _ := dockerCmd(c, "prune", "--force", "--filter", "label=prune=1", "containers")
+	c.Assert(out, checker.Contains, "Deleted Containers:")
+	c.Assert(out, checker.Contains, stoppedID)
+	c.Assert(out, checker.Contains, "Total reclaimed space:")
+
+	_, err := inspectFieldWithError("prune-stopped", "Id")
+	c.Assert(err, checker.NotNil, check.Commentf("the stopped container should be removed"))
+	inspectField(c, "prune-kept", "Id")
+	inspectField(c, "prune-running", "Id")
+
+	dockerCmd(c, "prune", "--force", "--filter", "until=1h", "containers")
+	inspectField(c, "prune-kept", "Id")
+}
+
+func (s *DockerSuite) TestPruneVolumes(c *check.C) {
+	testRequires(c, DaemonIsLinux)
+
+	dockerCmd(c, "volume", "create", "--name", "prune-unused")
+	dockerCmd(c, "run", "-d", "-v", "prune-used:/data", "busybox", "top")
+
+	// Volumes are only removed when asked for
+	dockerCmd(c, "prune", "--force")
+	out, _ := dockerCmd(c, "volume", "ls", "-q")
+	c.Assert(out, checker.Contains, "prune-unused")
+
+	out, _ = dockerCmd(c, "prune", "--force", "volumes")
+	c.Assert(out, checker.Contains, "Deleted Volumes:\nprune-unused\n")
+	out, _ = dockerCmd(c, "volume", "ls", "-q")
+	c.Assert(out, checker.Not(checker.Contains), "prune-unused")
+	c.Assert(out, checker.Contains, "prune-used")
+
+	_, _, err := dockerCmdWithError("prune", "--force", "--filter", "until=1h", "volumes")
+	c.Assert(err, checker.NotNil, check.Commentf("the until filter is not supported for volumes"))
+}
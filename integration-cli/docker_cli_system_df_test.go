@@ -0,0 +1,55 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+
+	"github.com/docker/docker/pkg/integration/checker"
+	"github.com/docker/engine-api/types"
+	"github.com/go-check/check"
+)
+
+func (s *DockerSuite) TestSystemDiskUsage(c *check.C) {
+	testRequires(c, DaemonIsLinux)
+
+	dockerCmd(c, "volume", "create", "--name", "df-volume")
+	dockerCmd(c, "run", "--name", "df-container", "-v", "df-volume:/data", "busybox", "sh", "-c", "echo hello > /data/file && echo hello > /file")
+	id := strings.TrimSpace(inspectField(c, "df-container", "Id"))
+
+	status, body, err := sockRequest("GET", "/system/df", nil)
+	c.Assert(err, checker.IsNil)
+	c.Assert(status, checker.Equals, http.StatusOK)
+
+	var du types.DiskUsage
+	c.Assert(json.Unmarshal(body, &du), checker.IsNil)
+	c.Assert(du.LayersSize, checker.GreaterThan, int64(0))
+
+	var foundContainer bool
+	for _, ctr := range du.Containers {
+		if ctr.ID == id {
+			foundContainer = true
+			c.Assert(ctr.SizeRw, checker.GreaterThan, int64(0))
+		}
+	}
+	c.Assert(foundContainer, checker.True, check.Commentf("container %s not in %v", id, du.Containers))
+
+	var foundVolume bool
+	for _, v := range du.Volumes {
+		if v.Name == "df-volume" {
+			foundVolume = true
+			c.Assert(v.RefCount, checker.Equals, 1)
+			c.Assert(v.Size, checker.GreaterThan, int64(0))
+		}
+	}
+	c.Assert(foundVolume, checker.True, check.Commentf("volume df-volume not in %v", du.Volumes))
+
+	out, _ := dockerCmd(c, "system", "df")
+	c.Assert(out, checker.Contains, "RECLAIMABLE")
+	c.Assert(out, checker.Contains, "Local Volumes")
+
+	out, _ = dockerCmd(c, "system", "df", "-v")
+	c.Assert(out, checker.Contains, "Containers space usage:")
+	c.Assert(out, checker.Contains, "df-container")
+	c.Assert(out, checker.Contains, "df-volume")
+}
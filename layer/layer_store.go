@@ -5,6 +5,8 @@ import (
 	"fmt"
 	"io"
 	"io/ioutil"
+	"os"
+	"path/filepath"
 	"sync"
 
 	"github.com/Sirupsen/logrus"
@@ -29,6 +31,10 @@ type layerStore struct {
 	store  MetadataStore
 	driver graphdriver.Driver
 
+	// shared is the read-only metadata store of the layers shared by
+	// several daemons, which are never removed, or nil.
+	shared MetadataStore
+
 	layerMap map[ChainID]*roLayer
 	layerL   sync.Mutex
 
@@ -44,6 +50,13 @@ type StoreOptions struct {
 	GraphDriverOptions        []string
 	UIDMaps                   []idtools.IDMap
 	GIDMaps                   []idtools.IDMap
+
+	// SharedStorePath is the root directory, read-only, of the layers
+	// shared by several daemons, and SharedMetadataStorePathTemplate the
+	// template of the path of their metadata. The layers of the store are
+	// kept apart.
+	SharedStorePath                 string
+	SharedMetadataStorePathTemplate string
 }
 
 // NewStoreFromOptions creates a new Store instance
@@ -64,16 +77,45 @@ func NewStoreFromOptions(options StoreOptions) (Store, error) {
 		return nil, err
 	}
 
-	return NewStoreFromGraphDriver(fms, driver)
+	if options.SharedStorePath == "" {
+		return NewStoreFromGraphDriver(fms, driver)
+	}
+
+	sharedDriver, ok := driver.(graphdriver.SharedLayersDriver)
+	if !ok {
+		return nil, fmt.Errorf("the %s storage driver does not support shared layer stores", driver)
+	}
+	if err := sharedDriver.SetSharedHome(filepath.Join(options.SharedStorePath, driver.String())); err != nil {
+		return nil, fmt.Errorf("error using shared layer store %s: %v", options.SharedStorePath, err)
+	}
+	sharedRoot := fmt.Sprintf(options.SharedMetadataStorePathTemplate, driver)
+	if _, err := os.Stat(sharedRoot); err != nil {
+		return nil, fmt.Errorf("error using shared layer store %s: %v", options.SharedStorePath, err)
+	}
+	shared, err := NewFSMetadataStore(sharedRoot)
+	if err != nil {
+		return nil, err
+	}
+	logrus.Debugf("Using shared layer store %s", options.SharedStorePath)
+
+	return newStoreFromGraphDriver(fms, shared, driver)
 }
 
 // NewStoreFromGraphDriver creates a new Store instance using the provided
 // metadata store and graph driver. The metadata store will be used to restore
 // the Store.
 func NewStoreFromGraphDriver(store MetadataStore, driver graphdriver.Driver) (Store, error) {
+	return newStoreFromGraphDriver(store, nil, driver)
+}
+
+// newStoreFromGraphDriver creates a new Store instance which also uses the
+// layers of the shared metadata store, if not nil, whose content is found
+// by the graph driver too.
+func newStoreFromGraphDriver(store, shared MetadataStore, driver graphdriver.Driver) (Store, error) {
 	ls := &layerStore{
 		store:    store,
 		driver:   driver,
+		shared:   shared,
 		layerMap: map[ChainID]*roLayer{},
 		mounts:   map[string]*mountedLayer{},
 	}
@@ -94,6 +136,28 @@ func NewStoreFromGraphDriver(store MetadataStore, driver graphdriver.Driver) (St
 		}
 	}
 
+	if shared != nil {
+		// the mounts of the shared store belong to other daemons
+		sharedIDs, _, err := shared.List()
+		if err != nil {
+			return nil, err
+		}
+		for _, id := range sharedIDs {
+			if l, ok := ls.layerMap[id]; ok && !l.shared {
+				// the layer is in the store too, and was counted
+				continue
+			}
+			l, err := ls.loadLayer(id)
+			if err != nil {
+				logrus.Debugf("Failed to load shared layer %s: %s", id, err)
+				continue
+			}
+			if l.parent != nil {
+				l.parent.referenceCount++
+			}
+		}
+	}
+
 	for _, mount := range mounts {
 		if err := ls.loadMount(mount); err != nil {
 			logrus.Debugf("Failed to load mount %s: %s", mount, err)
@@ -103,33 +167,42 @@ func NewStoreFromGraphDriver(store MetadataStore, driver graphdriver.Driver) (St
 	return ls, nil
 }
 
+// loadLayer loads the layer from the metadata store, or else from the
+// shared metadata store.
 func (ls *layerStore) loadLayer(layer ChainID) (*roLayer, error) {
 	cl, ok := ls.layerMap[layer]
 	if ok {
 		return cl, nil
 	}
 
-	diff, err := ls.store.GetDiffID(layer)
+	store, shared := ls.store, false
+	diff, err := store.GetDiffID(layer)
+	if err != nil && ls.shared != nil {
+		if sharedDiff, sharedErr := ls.shared.GetDiffID(layer); sharedErr == nil {
+			store, shared = ls.shared, true
+			diff, err = sharedDiff, nil
+		}
+	}
 	if err != nil {
 		return nil, fmt.Errorf("failed to get diff id for %s: %s", layer, err)
 	}
 
-	size, err := ls.store.GetSize(layer)
+	size, err := store.GetSize(layer)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get size for %s: %s", layer, err)
 	}
 
-	cacheID, err := ls.store.GetCacheID(layer)
+	cacheID, err := store.GetCacheID(layer)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get cache id for %s: %s", layer, err)
 	}
 
-	parent, err := ls.store.GetParent(layer)
+	parent, err := store.GetParent(layer)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get parent for %s: %s", layer, err)
 	}
 
-	descriptor, err := ls.store.GetDescriptor(layer)
+	descriptor, err := store.GetDescriptor(layer)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get descriptor for %s: %s", layer, err)
 	}
@@ -142,6 +215,7 @@ func (ls *layerStore) loadLayer(layer ChainID) (*roLayer, error) {
 		layerStore: ls,
 		references: map[Layer]struct{}{},
 		descriptor: descriptor,
+		shared:     shared,
 	}
 
 	if parent != "" {
@@ -157,6 +231,35 @@ func (ls *layerStore) loadLayer(layer ChainID) (*roLayer, error) {
 	return cl, nil
 }
 
+// loadSharedLayer loads a layer of the shared metadata store, with the
+// parents which are not loaded yet, counting the reference of each layer it
+// loads to its parent. The layers can be added to the shared store by
+// another daemon after the store was created.
+func (ls *layerStore) loadSharedLayer(layer ChainID) (*roLayer, error) {
+	if l, ok := ls.layerMap[layer]; ok {
+		return l, nil
+	}
+
+	parent, err := ls.shared.GetParent(layer)
+	if err != nil {
+		return nil, err
+	}
+	if parent != "" {
+		if _, err := ls.loadSharedLayer(parent); err != nil {
+			return nil, err
+		}
+	}
+
+	l, err := ls.loadLayer(layer)
+	if err != nil {
+		return nil, err
+	}
+	if l.parent != nil {
+		l.parent.referenceCount++
+	}
+	return l, nil
+}
+
 func (ls *layerStore) loadMount(mount string) error {
 	if _, ok := ls.mounts[mount]; ok {
 		return nil
@@ -238,6 +341,10 @@ func (ls *layerStore) Register(ts io.Reader, parent ChainID) (Layer, error) {
 	return ls.registerWithDescriptor(ts, parent, distribution.Descriptor{})
 }
 
+func (ls *layerStore) RegisterWithDescriptor(ts io.Reader, parent ChainID, descriptor distribution.Descriptor) (Layer, error) {
+	return ls.registerWithDescriptor(ts, parent, descriptor)
+}
+
 func (ls *layerStore) registerWithDescriptor(ts io.Reader, parent ChainID, descriptor distribution.Descriptor) (Layer, error) {
 	// err is used to hold the error which will always trigger
 	// cleanup of creates sources but may not be an error returned
@@ -331,7 +438,13 @@ func (ls *layerStore) registerWithDescriptor(ts io.Reader, parent ChainID, descr
 func (ls *layerStore) getWithoutLock(layer ChainID) *roLayer {
 	l, ok := ls.layerMap[layer]
 	if !ok {
-		return nil
+		if ls.shared == nil {
+			return nil
+		}
+		var err error
+		if l, err = ls.loadSharedLayer(layer); err != nil {
+			return nil
+		}
 	}
 
 	l.referenceCount++
@@ -396,6 +509,10 @@ func (ls *layerStore) releaseLayer(l *roLayer) ([]Metadata, error) {
 		if l.hasReferences() {
 			panic("cannot delete referenced layer")
 		}
+		if l.shared {
+			// the shared layers are kept, with their parents
+			return removed, nil
+		}
 		var metadata Metadata
 		if err := ls.deleteLayer(l, &metadata); err != nil {
 			return nil, err
@@ -766,3 +766,88 @@ func TestTarStreamVerification(t *testing.T) {
 		t.Fatalf("wrong error returned from tarstream: %q", err)
 	}
 }
+
+func TestSharedStore(t *testing.T) {
+	// TODO Windows: Figure out why this is failing
+	if runtime.GOOS == "windows" {
+		t.Skip("Failing on Windows")
+	}
+	sharedLS, _, cleanup := newTestStore(t)
+	defer cleanup()
+
+	layer1, err := createLayer(sharedLS, "", initWithFiles(newTestFile("layer1.txt", []byte("layer 1 file"), 0644)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	layer2, err := createLayer(sharedLS, layer1.ChainID(), initWithFiles(newTestFile("layer2.txt", []byte("layer 2 file"), 0644)))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	td, err := ioutil.TempDir("", "layerstore-")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(td)
+	fms, err := NewFSMetadataStore(td)
+	if err != nil {
+		t.Fatal(err)
+	}
+	shared := sharedLS.(*layerStore).store
+	driver := sharedLS.(*layerStore).driver
+	ls, err := newStoreFromGraphDriver(fms, shared, driver)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	layer2b, err := ls.Get(layer2.ChainID())
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertLayerEqual(t, layer2b, layer2)
+
+	// the layers created on the shared layers are kept apart
+	layer3, err := createLayer(ls, layer2.ChainID(), initWithFiles(newTestFile("layer3.txt", []byte("layer 3 file"), 0644)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := fms.GetDiffID(layer3.ChainID()); err != nil {
+		t.Fatalf("expected the layer in the store: %v", err)
+	}
+	if _, err := shared.GetDiffID(layer3.ChainID()); err == nil {
+		t.Fatal("expected the layer not to be in the shared store")
+	}
+
+	// the layers added to the shared store are found
+	layer4, err := createLayer(sharedLS, layer2.ChainID(), initWithFiles(newTestFile("layer4.txt", []byte("layer 4 file"), 0644)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	layer4b, err := ls.Get(layer4.ChainID())
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertLayerEqual(t, layer4b, layer4)
+
+	// the shared layers are never removed
+	releaseAndCheckDeleted(t, ls, layer4b)
+	releaseAndCheckDeleted(t, ls, layer2b)
+
+	ls2, err := newStoreFromGraphDriver(fms, shared, driver)
+	if err != nil {
+		t.Fatal(err)
+	}
+	layer3b, err := ls2.Get(layer3.ChainID())
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertLayerEqual(t, layer3b, layer3)
+	releaseAndCheckDeleted(t, ls2, layer3b, layer3)
+
+	if _, err := shared.GetDiffID(layer2.ChainID()); err != nil {
+		t.Fatalf("expected the shared layer to be kept: %v", err)
+	}
+	if !driver.Exists(getCachedLayer(layer2).cacheID) {
+		t.Fatal("expected the content of the shared layer to be kept")
+	}
+}
@@ -19,10 +19,30 @@ type roLayer struct {
 
 	referenceCount int
 	references     map[Layer]struct{}
+
+	// shared tells whether the layer is a layer of the shared metadata
+	// store of the layer store.
+	shared bool
+}
+
+var _ distribution.Describable = &roLayer{}
+
+// Descriptor returns the distribution descriptor the layer was
+// registered with, which is non-empty for foreign layers.
+func (rl *roLayer) Descriptor() distribution.Descriptor {
+	return rl.descriptor
+}
+
+// metadataStore returns the metadata store of the layer.
+func (rl *roLayer) metadataStore() MetadataStore {
+	if rl.shared {
+		return rl.layerStore.shared
+	}
+	return rl.layerStore.store
 }
 
 func (rl *roLayer) TarStream() (io.ReadCloser, error) {
-	r, err := rl.layerStore.store.TarSplitReader(rl.chainID)
+	r, err := rl.metadataStore().TarSplitReader(rl.chainID)
 	if err != nil {
 		return nil, err
 	}
@@ -314,6 +314,20 @@ func (clnt *client) GetPidsForContainer(containerID string) ([]int, error) {
 	return pids, nil
 }
 
+// GetPidForProcess returns the pid of a process of a container on the host.
+func (clnt *client) GetPidForProcess(containerID, processFriendlyName string) (int, error) {
+	cont, err := clnt.getContainerdContainer(containerID)
+	if err != nil {
+		return 0, err
+	}
+	for _, p := range cont.Processes {
+		if p.Pid == processFriendlyName {
+			return int(p.SystemPid), nil
+		}
+	}
+	return 0, fmt.Errorf("no such process %s in container %s", processFriendlyName, containerID)
+}
+
 // Summary returns a summary of the processes running in a container.
 // This is a no-op on Linux.
 func (clnt *client) Summary(containerID string) ([]Summary, error) {
@@ -355,6 +369,37 @@ func (clnt *client) newContainer(dir string, options ...CreateOption) *container
 	return container
 }
 
+func (clnt *client) CreateCheckpoint(containerID string, checkpointID string, checkpointDir string, exit bool) error {
+	clnt.lock(containerID)
+	defer clnt.unlock(containerID)
+	container, err := clnt.getContainer(containerID)
+	if err != nil {
+		return err
+	}
+
+	_, err = clnt.remote.apiClient.CreateCheckpoint(context.Background(), &containerd.CreateCheckpointRequest{
+		Id: containerID,
+		Checkpoint: &containerd.Checkpoint{
+			Name:        checkpointID,
+			Exit:        exit,
+			Tcp:         true,
+			UnixSockets: true,
+			Shell:       false,
+			EmptyNS:     []string{"network"},
+		},
+		CheckpointDir: checkpointDir,
+	})
+	if err != nil {
+		return err
+	}
+	if exit && container.restartManager != nil {
+		// the container exits once checkpointed, its exit is handled once
+		// the lock is released and it must not be restarted
+		container.restartManager.Cancel()
+	}
+	return nil
+}
+
 func (clnt *client) UpdateResources(containerID string, resources Resources) error {
 	clnt.lock(containerID)
 	defer clnt.unlock(containerID)
@@ -1,5 +1,7 @@
 package libcontainerd
 
+import "errors"
+
 type client struct {
 	clientCommon
 
@@ -43,6 +45,11 @@ func (clnt *client) GetPidsForContainer(containerID string) ([]int, error) {
 	return nil, nil
 }
 
+// GetPidForProcess returns the pid of a process of a container on the host.
+func (clnt *client) GetPidForProcess(containerID, processFriendlyName string) (int, error) {
+	return 0, nil
+}
+
 // Summary returns a summary of the processes running in a container.
 func (clnt *client) Summary(containerID string) ([]Summary, error) {
 	return nil, nil
@@ -54,3 +61,7 @@ func (clnt *client) UpdateResources(containerID string, resources Resources) err
 	// but we should return nil for enabling updating container
 	return nil
 }
+
+func (clnt *client) CreateCheckpoint(containerID string, checkpointID string, checkpointDir string, exit bool) error {
+	return errors.New("Solaris: Containers do not support checkpoints")
+}
@@ -405,6 +405,23 @@ func (clnt *client) GetPidsForContainer(containerID string) ([]int, error) {
 	return pids, nil
 }
 
+// GetPidForProcess returns the pid of a process of a container on the host.
+// Although implemented, this is not used in Windows.
+func (clnt *client) GetPidForProcess(containerID, processFriendlyName string) (int, error) {
+	clnt.lock(containerID)
+	defer clnt.unlock(containerID)
+	cont, err := clnt.getContainer(containerID)
+	if err != nil {
+		return 0, err
+	}
+
+	p, ok := cont.processes[processFriendlyName]
+	if !ok {
+		return 0, fmt.Errorf("no such process %s in container %s", processFriendlyName, containerID)
+	}
+	return int(p.processCommon.systemPid), nil
+}
+
 // Summary returns a summary of the processes running in a container.
 // This is present in Windows to support docker top. In linux, the
 // engine shells out to ps to get process information. On Windows, as
@@ -440,3 +457,7 @@ func (clnt *client) UpdateResources(containerID string, resources Resources) err
 	// but we should return nil for enabling updating container
 	return nil
 }
+
+func (clnt *client) CreateCheckpoint(containerID string, checkpointID string, checkpointDir string, exit bool) error {
+	return errors.New("Windows: Containers do not support checkpoints")
+}
@@ -20,6 +20,8 @@ type containerCommon struct {
 	restarting     bool
 	processes      map[string]*process
 	startedAt      time.Time
+	checkpoint     string
+	checkpointDir  string
 }
 
 // WithRestartManager sets the restartmanager to be used with the container.
@@ -38,3 +40,23 @@ func (rm restartManager) Apply(p interface{}) error {
 	}
 	return fmt.Errorf("WithRestartManager option not supported for this client")
 }
+
+// WithCheckpoint sets the checkpoint the container is restored from when it
+// is created.
+func WithCheckpoint(name, dir string) CreateOption {
+	return checkpoint{name, dir}
+}
+
+type checkpoint struct {
+	name string
+	dir  string
+}
+
+func (c checkpoint) Apply(p interface{}) error {
+	if pr, ok := p.(*container); ok {
+		pr.checkpoint = c.name
+		pr.checkpointDir = c.dir
+		return nil
+	}
+	return fmt.Errorf("WithCheckpoint option not supported for this client")
+}
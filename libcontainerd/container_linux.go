@@ -103,9 +103,11 @@ func (ctr *container) start() error {
 		Stdout:     ctr.fifo(syscall.Stdout),
 		Stderr:     ctr.fifo(syscall.Stderr),
 		// check to see if we are running in ramdisk to disable pivot root
-		NoPivotRoot: os.Getenv("DOCKER_RAMDISK") != "",
-		Runtime:     ctr.runtime,
-		RuntimeArgs: ctr.runtimeArgs,
+		NoPivotRoot:   os.Getenv("DOCKER_RAMDISK") != "",
+		Runtime:       ctr.runtime,
+		RuntimeArgs:   ctr.runtimeArgs,
+		Checkpoint:    ctr.checkpoint,
+		CheckpointDir: ctr.checkpointDir,
 	}
 	ctr.client.appendContainer(ctr)
 
@@ -115,6 +117,9 @@ func (ctr *container) start() error {
 		return err
 	}
 	ctr.startedAt = time.Now()
+	// the container is restored from its checkpoint once, its restarts
+	// start it afresh
+	ctr.checkpoint = ""
 
 	if err := ctr.client.backend.AttachStreams(ctr.containerID, *iopipe); err != nil {
 		return err
@@ -226,7 +231,7 @@ func (ctr *container) discardFifos() {
 		c := make(chan struct{})
 		go func() {
 			close(c) // this channel is used to not close the writer too early, before readonly open has been called.
-			io.Copy(ioutil.Discard, openReaderFromFifo(f))
+			io.Copy(ioutil.Discard, openReaderFromFifo(f, false))
 		}()
 		<-c
 		closeReaderFifo(f) // avoid blocking permanently on open if there is no writer side
@@ -3,15 +3,26 @@ package libcontainerd
 import (
 	"fmt"
 	"io"
+	"io/ioutil"
 	"os"
 	"path/filepath"
+	"strconv"
+	"strings"
 	"syscall"
 
+	"github.com/Sirupsen/logrus"
 	containerd "github.com/docker/containerd/api/grpc/types"
 	"github.com/docker/docker/pkg/ioutils"
 	"golang.org/x/net/context"
 )
 
+const (
+	// pipeMaxSizeFile holds the maximum size of the buffer of a pipe.
+	pipeMaxSizeFile = "/proc/sys/fs/pipe-max-size"
+	// fSetPipeSize is F_SETPIPE_SZ, missing from the syscall package.
+	fSetPipeSize = 1031
+)
+
 var fdNames = map[int]string{
 	syscall.Stdin:  "stdin",
 	syscall.Stdout: "stdout",
@@ -45,9 +56,12 @@ func (p *process) openFifos(terminal bool) (*IOPipe, error) {
 		return nil, err
 	}
 
-	io.Stdout = openReaderFromFifo(p.fifo(syscall.Stdout))
+	// with live restore, the processes keep writing to the fifos while the
+	// daemon restarts, until their buffers are full
+	grow := p.client.liveRestore
+	io.Stdout = openReaderFromFifo(p.fifo(syscall.Stdout), grow)
 	if !terminal {
-		io.Stderr = openReaderFromFifo(p.fifo(syscall.Stderr))
+		io.Stderr = openReaderFromFifo(p.fifo(syscall.Stderr), grow)
 	} else {
 		io.Stderr = emptyReader{}
 	}
@@ -77,13 +91,18 @@ func (r emptyReader) Read(b []byte) (int, error) {
 	return 0, io.EOF
 }
 
-func openReaderFromFifo(fn string) io.Reader {
+func openReaderFromFifo(fn string, grow bool) io.Reader {
 	r, w := io.Pipe()
 	go func() {
 		stdoutf, err := os.OpenFile(fn, syscall.O_RDONLY, 0)
 		if err != nil {
 			r.CloseWithError(err)
 		}
+		if err == nil && grow {
+			if err := growPipe(stdoutf); err != nil {
+				logrus.Warnf("Failed to grow the buffer of %s: %v", fn, err)
+			}
+		}
 		if _, err := io.Copy(w, stdoutf); err != nil {
 			r.CloseWithError(err)
 		}
@@ -93,6 +112,24 @@ func openReaderFromFifo(fn string) io.Reader {
 	return r
 }
 
+// growPipe sets the size of the buffer of the pipe f to the maximum set in
+// /proc/sys/fs/pipe-max-size. The buffer is kept as long as the pipe is open
+// on either side, so it outlives the daemon.
+func growPipe(f *os.File) error {
+	data, err := ioutil.ReadFile(pipeMaxSizeFile)
+	if err != nil {
+		return err
+	}
+	size, err := strconv.Atoi(strings.TrimSpace(string(data)))
+	if err != nil {
+		return err
+	}
+	if _, _, errno := syscall.Syscall(syscall.SYS_FCNTL, f.Fd(), fSetPipeSize, uintptr(size)); errno != 0 {
+		return errno
+	}
+	return nil
+}
+
 // closeReaderFifo closes fifo that may be blocked on open by opening the write side.
 func closeReaderFifo(fn string) {
 	f, err := os.OpenFile(fn, syscall.O_WRONLY|syscall.O_NONBLOCK, 0)
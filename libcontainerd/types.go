@@ -42,8 +42,10 @@ type Client interface {
 	Restore(containerID string, options ...CreateOption) error
 	Stats(containerID string) (*Stats, error)
 	GetPidsForContainer(containerID string) ([]int, error)
+	GetPidForProcess(containerID, processFriendlyName string) (int, error)
 	Summary(containerID string) ([]Summary, error)
 	UpdateResources(containerID string, resources Resources) error
+	CreateCheckpoint(containerID string, checkpointID string, checkpointDir string, exit bool) error
 }
 
 // CreateOption allows to configure parameters of container creation.
@@ -59,6 +59,10 @@ type (
 		// For each include when creating an archive, the included name will be
 		// replaced with the matching name from this map.
 		RebaseNames map[string]string
+		// When packing, specifies whether all the extended attributes of the
+		// files are archived, such as their SELinux labels, instead of only
+		// their capabilities.
+		IncludeXattrs bool
 	}
 
 	// Archiver allows the reuse of most utility functions of this package
@@ -261,6 +265,32 @@ type tarAppender struct {
 	// by the AUFS standard are used as the tar whiteout
 	// standard.
 	WhiteoutConverter tarWhiteoutConverter
+
+	// IncludeXattrs archives all the extended attributes of the files
+	IncludeXattrs bool
+}
+
+// addXattrs adds all the extended attributes of the file at path to the
+// header hdr.
+func addXattrs(hdr *tar.Header, path string) error {
+	attrs, err := system.Llistxattr(path)
+	if err != nil {
+		if err == system.ErrNotSupportedPlatform {
+			return nil
+		}
+		return err
+	}
+	for _, attr := range attrs {
+		value, err := system.Lgetxattr(path, attr)
+		if err != nil {
+			return err
+		}
+		if hdr.Xattrs == nil {
+			hdr.Xattrs = make(map[string]string)
+		}
+		hdr.Xattrs[attr] = string(value)
+	}
+	return nil
 }
 
 // canonicalTarName provides a platform-independent and consistent posix-style
@@ -323,10 +353,16 @@ func (ta *tarAppender) addTarFile(path, name string) error {
 		}
 	}
 
-	capability, _ := system.Lgetxattr(path, "security.capability")
-	if capability != nil {
-		hdr.Xattrs = make(map[string]string)
-		hdr.Xattrs["security.capability"] = string(capability)
+	if ta.IncludeXattrs {
+		if err := addXattrs(hdr, path); err != nil {
+			return err
+		}
+	} else {
+		capability, _ := system.Lgetxattr(path, "security.capability")
+		if capability != nil {
+			hdr.Xattrs = make(map[string]string)
+			hdr.Xattrs["security.capability"] = string(capability)
+		}
 	}
 
 	//handle re-mapping container ID mappings back to host ID mappings before
@@ -546,6 +582,7 @@ func TarWithOptions(srcPath string, options *TarOptions) (io.ReadCloser, error)
 			UIDMaps:           options.UIDMaps,
 			GIDMaps:           options.GIDMaps,
 			WhiteoutConverter: getWhiteoutConverter(options.WhiteoutFormat),
+			IncludeXattrs:     options.IncludeXattrs,
 		}
 
 		defer func() {
@@ -3,8 +3,10 @@
 package archive
 
 import (
+	"archive/tar"
 	"bytes"
 	"fmt"
+	"io"
 	"io/ioutil"
 	"os"
 	"path/filepath"
@@ -243,3 +245,46 @@ func TestTarUntarWithXattr(t *testing.T) {
 		}
 	}
 }
+
+func TestTarWithIncludeXattrs(t *testing.T) {
+	origin, err := ioutil.TempDir("", "docker-test-tar-xattrs")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(origin)
+	if err := ioutil.WriteFile(filepath.Join(origin, "1"), []byte("hello world"), 0700); err != nil {
+		t.Fatal(err)
+	}
+	if err := system.Lsetxattr(filepath.Join(origin, "1"), "user.docker-test", []byte("value"), 0); err != nil {
+		t.Skipf("Extended attributes are not supported: %v", err)
+	}
+
+	for _, includeXattrs := range []bool{false, true} {
+		archive, err := TarWithOptions(origin, &TarOptions{IncludeXattrs: includeXattrs})
+		if err != nil {
+			t.Fatal(err)
+		}
+		tr := tar.NewReader(archive)
+		var value string
+		for {
+			hdr, err := tr.Next()
+			if err == io.EOF {
+				break
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if hdr.Name == "1" {
+				value = hdr.Xattrs["user.docker-test"]
+			}
+		}
+		archive.Close()
+
+		if includeXattrs && value != "value" {
+			t.Fatalf("Expected the user.docker-test xattr to be archived, got %q", value)
+		}
+		if !includeXattrs && value != "" {
+			t.Fatalf("Expected only the capabilities to be archived, got %q", value)
+		}
+	}
+}
@@ -95,6 +95,16 @@ func TarResource(sourceInfo CopyInfo) (content Archive, err error) {
 // TarResourceRebase is like TarResource but renames the first path element of
 // items in the resulting tar archive to match the given rebaseName if not "".
 func TarResourceRebase(sourcePath, rebaseName string) (content Archive, err error) {
+	return tarResourceRebase(sourcePath, rebaseName, false)
+}
+
+// TarResourceWithXattrs is like TarResource but archives all the extended
+// attributes of the files, such as their SELinux labels.
+func TarResourceWithXattrs(sourceInfo CopyInfo) (content Archive, err error) {
+	return tarResourceRebase(sourceInfo.Path, sourceInfo.RebaseName, true)
+}
+
+func tarResourceRebase(sourcePath, rebaseName string, includeXattrs bool) (content Archive, err error) {
 	sourcePath = normalizePath(sourcePath)
 	if _, err = os.Lstat(sourcePath); err != nil {
 		// Catches the case where the source does not exist or is not a
@@ -118,6 +128,7 @@ func TarResourceRebase(sourcePath, rebaseName string) (content Archive, err erro
 		RebaseNames: map[string]string{
 			sourceBase: rebaseName,
 		},
+		IncludeXattrs: includeXattrs,
 	})
 }
 
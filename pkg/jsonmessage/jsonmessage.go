@@ -26,12 +26,19 @@ func (e *JSONError) Error() string {
 // JSONProgress describes a Progress. terminalFd is the fd of the current terminal,
 // Start is the initial value for the operation. Current is the current status and
 // value of the progress made towards Total. Total is the end value describing when
-// we made 100% progress for an operation.
+// we made 100% progress for an operation. Phase is a machine-readable
+// stage of an image transfer, Rate the average transfer rate in bytes per
+// second, ETA the estimated number of seconds left and Retries the number
+// of times the transfer was retried.
 type JSONProgress struct {
 	terminalFd uintptr
-	Current    int64 `json:"current,omitempty"`
-	Total      int64 `json:"total,omitempty"`
-	Start      int64 `json:"start,omitempty"`
+	Current    int64  `json:"current,omitempty"`
+	Total      int64  `json:"total,omitempty"`
+	Start      int64  `json:"start,omitempty"`
+	Phase      string `json:"phase,omitempty"`
+	Rate       int64  `json:"rate,omitempty"`
+	ETA        int64  `json:"eta,omitempty"`
+	Retries    int    `json:"retries,omitempty"`
 }
 
 func (p *JSONProgress) String() string {
@@ -39,7 +46,9 @@ func (p *JSONProgress) String() string {
 		width       = 200
 		pbBox       string
 		numbersBox  string
+		rateBox     string
 		timeLeftBox string
+		retriesBox  string
 	)
 
 	ws, err := term.GetWinsize(p.terminalFd)
@@ -50,9 +59,15 @@ func (p *JSONProgress) String() string {
 	if p.Current <= 0 && p.Total <= 0 {
 		return ""
 	}
+	if p.Retries > 0 && width > 80 {
+		retriesBox = fmt.Sprintf(" (retry %d)", p.Retries)
+	}
+	if p.Rate > 0 && width > 80 {
+		rateBox = fmt.Sprintf(" %8v/s", units.HumanSize(float64(p.Rate)))
+	}
 	current := units.HumanSize(float64(p.Current))
 	if p.Total <= 0 {
-		return fmt.Sprintf("%8v", current)
+		return fmt.Sprintf("%8v", current) + rateBox + retriesBox
 	}
 	total := units.HumanSize(float64(p.Total))
 	percentage := int(float64(p.Current)/float64(p.Total)*100) / 2
@@ -75,7 +90,11 @@ func (p *JSONProgress) String() string {
 		numbersBox = fmt.Sprintf("%8v", current)
 	}
 
-	if p.Current > 0 && p.Start > 0 && percentage < 50 {
+	if p.ETA > 0 && percentage < 50 {
+		if width > 50 {
+			timeLeftBox = " " + (time.Duration(p.ETA) * time.Second).String()
+		}
+	} else if p.Current > 0 && p.Start > 0 && percentage < 50 {
 		fromStart := time.Now().UTC().Sub(time.Unix(p.Start, 0))
 		perEntry := fromStart / time.Duration(p.Current)
 		left := time.Duration(p.Total-p.Current) * perEntry
@@ -85,7 +104,7 @@ func (p *JSONProgress) String() string {
 			timeLeftBox = " " + left.String()
 		}
 	}
-	return pbBox + numbersBox + timeLeftBox
+	return pbBox + numbersBox + rateBox + timeLeftBox + retriesBox
 }
 
 // JSONMessage defines a message struct. It describes
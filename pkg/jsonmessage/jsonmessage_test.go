@@ -66,6 +66,20 @@ func TestProgress(t *testing.T) {
 	}
 }
 
+func TestProgressRateAndRetries(t *testing.T) {
+	termsz, err := term.GetWinsize(0)
+	if err == nil && termsz.Width <= 80 {
+		t.Skip("terminal too narrow to display rate and retries")
+	}
+	jp := JSONProgress{Current: 20, Total: 100, Rate: 2000, ETA: 90, Retries: 1}
+	s := jp.String()
+	for _, expected := range []string{"2 kB/s", "1m30s", "(retry 1)"} {
+		if !strings.Contains(s, expected) {
+			t.Fatalf("Expected %q to contain %q", s, expected)
+		}
+	}
+}
+
 func TestJSONMessageDisplay(t *testing.T) {
 	now := time.Now()
 	messages := map[JSONMessage][]string{
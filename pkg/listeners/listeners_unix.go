@@ -7,8 +7,8 @@ import (
 	"fmt"
 	"net"
 	"strconv"
+	"sync"
 
-	"github.com/Sirupsen/logrus"
 	"github.com/coreos/go-systemd/activation"
 	"github.com/docker/go-connections/sockets"
 )
@@ -44,51 +44,76 @@ func Init(proto, addr, socketGroup string, tlsConfig *tls.Config) ([]net.Listene
 	return ls, nil
 }
 
+var (
+	activatedMu   sync.Mutex
+	activatedOnce sync.Once
+	activated     []net.Listener
+	activatedErr  error
+	activatedUsed = make(map[int]bool)
+)
+
+// activatedListeners returns the listeners of the files passed by systemd,
+// starting from fd 3, with nil values for the files which are not sockets.
+// The files are only read once, so that several fd:// hosts can use them:
+// reading them again would create listeners on files which were closed in
+// the meantime.
+func activatedListeners() ([]net.Listener, error) {
+	activatedOnce.Do(func() {
+		activated, activatedErr = activation.Listeners(true)
+	})
+	return activated, activatedErr
+}
+
 // listenFD returns the specified socket activated files as a slice of
-// net.Listeners or all of the activated files if "*" is given.
+// net.Listeners or all of the activated files not used by another host if
+// "*" is given. The TCP sockets are served with TLS when tlsConfig is set.
 func listenFD(addr string, tlsConfig *tls.Config) ([]net.Listener, error) {
-	var (
-		err       error
-		listeners []net.Listener
-	)
-	// socket activation
-	if tlsConfig != nil {
-		listeners, err = activation.TLSListeners(false, tlsConfig)
-	} else {
-		listeners, err = activation.Listeners(false)
-	}
-	if err != nil {
-		return nil, err
-	}
+	activatedMu.Lock()
+	defer activatedMu.Unlock()
 
-	if len(listeners) == 0 {
-		return nil, fmt.Errorf("no sockets found via socket activation: make sure the service was started by systemd")
+	listeners, err := activatedListeners()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read the socket activated files: %v", err)
 	}
-
+	var offsets []int
 	// default to all fds just like unix:// and tcp://
 	if addr == "" || addr == "*" {
-		return listeners, nil
+		for i, l := range listeners {
+			if l != nil && !activatedUsed[i] {
+				offsets = append(offsets, i)
+			}
+		}
+		if len(offsets) == 0 {
+			return nil, fmt.Errorf("no sockets found via socket activation: make sure the service was started by systemd")
+		}
+	} else {
+		fdNum, err := strconv.Atoi(addr)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse systemd fd address: should be a number: %v", addr)
+		}
+		fdOffset := fdNum - 3
+		if fdOffset < 0 || len(listeners) < fdOffset+1 {
+			return nil, fmt.Errorf("too few socket activated files passed in by systemd")
+		}
+		if listeners[fdOffset] == nil {
+			return nil, fmt.Errorf("failed to listen on systemd activated file: fd %d", fdNum)
+		}
+		if activatedUsed[fdOffset] {
+			return nil, fmt.Errorf("systemd activated file fd %d is already used by another host", fdNum)
+		}
+		offsets = []int{fdOffset}
 	}
 
-	fdNum, err := strconv.Atoi(addr)
-	if err != nil {
-		return nil, fmt.Errorf("failed to parse systemd fd address: should be a number: %v", addr)
-	}
-	fdOffset := fdNum - 3
-	if len(listeners) < int(fdOffset)+1 {
-		return nil, fmt.Errorf("too few socket activated files passed in by systemd")
-	}
-	if listeners[fdOffset] == nil {
-		return nil, fmt.Errorf("failed to listen on systemd activated file: fd %d", fdOffset+3)
-	}
-	for i, ls := range listeners {
-		if i == fdOffset || ls == nil {
-			continue
-		}
-		if err := ls.Close(); err != nil {
-			// TODO: We shouldn't log inside a library. Remove this or error out.
-			logrus.Errorf("failed to close systemd activated file: fd %d: %v", fdOffset+3, err)
+	ls := make([]net.Listener, 0, len(offsets))
+	for _, i := range offsets {
+		activatedUsed[i] = true
+		l := listeners[i]
+		// Activate TLS only for TCP sockets
+		if tlsConfig != nil && l.Addr().Network() == "tcp" {
+			tlsConfig.NextProtos = []string{"http/1.1"}
+			l = tls.NewListener(l, tlsConfig)
 		}
+		ls = append(ls, l)
 	}
-	return []net.Listener{listeners[fdOffset]}, nil
+	return ls, nil
 }
@@ -0,0 +1,54 @@
+// +build !windows,!solaris
+
+package listeners
+
+import (
+	"crypto/tls"
+	"net"
+	"testing"
+)
+
+func TestListenFD(t *testing.T) {
+	var tcp []net.Listener
+	for i := 0; i < 3; i++ {
+		l, err := net.Listen("tcp", "127.0.0.1:0")
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer l.Close()
+		tcp = append(tcp, l)
+	}
+	// fd 3 and 5 are TCP sockets, fd 4 is not a socket, fd 6 is another TCP
+	// socket
+	activatedOnce.Do(func() {})
+	activated = []net.Listener{tcp[0], nil, tcp[1], tcp[2]}
+
+	ls, err := listenFD("5", &tls.Config{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(ls) != 1 || ls[0].Addr().String() != tcp[1].Addr().String() {
+		t.Fatalf("expected the listener of fd 5, got %v", ls)
+	}
+	if ls[0] == tcp[1] {
+		t.Fatal("expected the TCP socket to be served with TLS")
+	}
+
+	for _, addr := range []string{"5", "4", "7", "2", "three"} {
+		if _, err := listenFD(addr, nil); err == nil {
+			t.Fatalf("expected an error for fd://%s", addr)
+		}
+	}
+
+	ls, err = listenFD("*", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(ls) != 2 || ls[0] != tcp[0] || ls[1] != tcp[2] {
+		t.Fatalf("expected the listeners of fd 3 and 6, got %v", ls)
+	}
+
+	if _, err := listenFD("", nil); err == nil {
+		t.Fatal("expected an error once all the sockets are used")
+	}
+}
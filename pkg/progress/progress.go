@@ -16,6 +16,15 @@ type Progress struct {
 	Current int64
 	Total   int64
 
+	// Phase is a machine-readable identifier of the stage the transfer
+	// is in, such as PhaseDownloading. It is empty for messages that are
+	// not related to a transfer.
+	Phase string
+	// Rate is the average transfer rate in bytes per second.
+	Rate int64
+	// Retries is the number of times the transfer has been retried.
+	Retries int
+
 	// Aux contains extra information not presented to the user, such as
 	// digests for push signing.
 	Aux interface{}
@@ -23,6 +32,24 @@ type Progress struct {
 	LastUpdate bool
 }
 
+// Phases reported in Progress.Phase for image transfers.
+const (
+	PhasePreparing   = "preparing"
+	PhaseWaiting     = "waiting"
+	PhaseDownloading = "downloading"
+	PhaseVerifying   = "verifying"
+	PhaseDownloaded  = "downloaded"
+	PhaseExtracting  = "extracting"
+	PhaseComplete    = "complete"
+	PhaseExists      = "exists"
+	PhaseRetrying    = "retrying"
+	PhaseUploading   = "uploading"
+	PhasePushed      = "pushed"
+	PhaseMounted     = "mounted"
+	PhaseSkipped     = "skipped"
+	PhaseFailed      = "failed"
+)
+
 // Output is an interface for writing progress information. It's
 // like a writer for progress, but we don't call it Writer because
 // that would be confusing next to ProgressReader (also, because it
@@ -55,6 +82,12 @@ func Updatef(out Output, id, format string, a ...interface{}) {
 	Update(out, id, fmt.Sprintf(format, a...))
 }
 
+// UpdatePhase is a convenience function to write a progress update for a
+// transfer entering the given phase.
+func UpdatePhase(out Output, id, phase, action string) {
+	out.WriteProgress(Progress{ID: id, Action: action, Phase: phase})
+}
+
 // Message is a convenience function to write a progress message to the channel.
 func Message(out Output, id, message string) {
 	out.WriteProgress(Progress{ID: id, Message: message})
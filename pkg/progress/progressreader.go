@@ -2,6 +2,7 @@ package progress
 
 import (
 	"io"
+	"time"
 )
 
 // Reader is a Reader with progress bar.
@@ -13,20 +14,32 @@ type Reader struct {
 	lastUpdate int64
 	id         string
 	action     string
+	phase      string
+	start      time.Time
 }
 
 // NewProgressReader creates a new ProgressReader.
 func NewProgressReader(in io.ReadCloser, out Output, size int64, id, action string) *Reader {
+	return NewPhaseReader(in, out, size, id, "", action)
+}
+
+// NewPhaseReader creates a new ProgressReader which reports the given
+// machine-readable phase along with the progress.
+func NewPhaseReader(in io.ReadCloser, out Output, size int64, id, phase, action string) *Reader {
 	return &Reader{
 		in:     in,
 		out:    out,
 		size:   size,
 		id:     id,
 		action: action,
+		phase:  phase,
 	}
 }
 
 func (p *Reader) Read(buf []byte) (n int, err error) {
+	if p.start.IsZero() {
+		p.start = time.Now()
+	}
 	read, err := p.in.Read(buf)
 	p.current += int64(read)
 	updateEvery := int64(1024 * 512) //512kB
@@ -55,5 +68,18 @@ func (p *Reader) Close() error {
 }
 
 func (p *Reader) updateProgress(last bool) {
-	p.out.WriteProgress(Progress{ID: p.id, Action: p.action, Current: p.current, Total: p.size, LastUpdate: last})
+	p.out.WriteProgress(Progress{ID: p.id, Action: p.action, Phase: p.phase, Current: p.current, Total: p.size, Rate: p.rate(), LastUpdate: last})
+}
+
+// rate returns the average number of bytes read per second since the
+// first read.
+func (p *Reader) rate() int64 {
+	if p.start.IsZero() {
+		return 0
+	}
+	elapsed := time.Since(p.start).Seconds()
+	if elapsed <= 0 {
+		return 0
+	}
+	return int64(float64(p.current) / elapsed)
 }
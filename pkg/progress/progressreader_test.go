@@ -73,3 +73,27 @@ drainLoop:
 	default:
 	}
 }
+
+func TestPhaseReaderReportsPhase(t *testing.T) {
+	content := []byte("TESTING")
+	reader := ioutil.NopCloser(bytes.NewReader(content))
+	progressChan := make(chan Progress, 10)
+
+	pr := NewPhaseReader(reader, ChanOutput(progressChan), int64(len(content)), "Test", PhaseDownloading, "Read")
+	if _, err := ioutil.ReadAll(pr); err != nil {
+		t.Fatal(err)
+	}
+	pr.Close()
+
+	select {
+	case p := <-progressChan:
+		if p.Phase != PhaseDownloading {
+			t.Fatalf("Expected phase %q, got %q", PhaseDownloading, p.Phase)
+		}
+		if p.Rate < 0 {
+			t.Fatalf("Unexpected negative rate %d", p.Rate)
+		}
+	default:
+		t.Fatalf("Expected some progress output")
+	}
+}
@@ -125,7 +125,16 @@ func (out *progressOutput) WriteProgress(prog progress.Progress) error {
 	if prog.Message != "" {
 		formatted = out.sf.FormatStatus(prog.ID, prog.Message)
 	} else {
-		jsonProgress := jsonmessage.JSONProgress{Current: prog.Current, Total: prog.Total}
+		jsonProgress := jsonmessage.JSONProgress{
+			Current: prog.Current,
+			Total:   prog.Total,
+			Phase:   prog.Phase,
+			Rate:    prog.Rate,
+			Retries: prog.Retries,
+		}
+		if prog.Rate > 0 && prog.Total > prog.Current {
+			jsonProgress.ETA = (prog.Total - prog.Current) / prog.Rate
+		}
 		formatted = out.sf.FormatProgress(prog.ID, prog.Action, &jsonProgress, prog.Aux)
 	}
 	_, err := out.out.Write(formatted)
@@ -1,6 +1,7 @@
 package streamformatter
 
 import (
+	"bytes"
 	"encoding/json"
 	"errors"
 	"reflect"
@@ -8,6 +9,7 @@ import (
 	"testing"
 
 	"github.com/docker/docker/pkg/jsonmessage"
+	"github.com/docker/docker/pkg/progress"
 )
 
 func TestFormatStream(t *testing.T) {
@@ -106,3 +108,33 @@ func TestJSONFormatProgress(t *testing.T) {
 		t.Fatal("Original progress not equals progress from FormatProgress")
 	}
 }
+
+func TestJSONProgressOutputDetails(t *testing.T) {
+	var buf bytes.Buffer
+	out := NewJSONStreamFormatter().NewProgressOutput(&buf, false)
+	out.WriteProgress(progress.Progress{
+		ID:      "id",
+		Action:  "Downloading",
+		Phase:   progress.PhaseDownloading,
+		Current: 100,
+		Total:   1100,
+		Rate:    50,
+		Retries: 2,
+	})
+
+	msg := &jsonmessage.JSONMessage{}
+	if err := json.Unmarshal(buf.Bytes(), msg); err != nil {
+		t.Fatal(err)
+	}
+	expected := &jsonmessage.JSONProgress{
+		Current: 100,
+		Total:   1100,
+		Phase:   progress.PhaseDownloading,
+		Rate:    50,
+		ETA:     20,
+		Retries: 2,
+	}
+	if !reflect.DeepEqual(msg.Progress, expected) {
+		t.Fatalf("Expected progress %+v, got %+v", expected, msg.Progress)
+	}
+}
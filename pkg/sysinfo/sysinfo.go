@@ -27,6 +27,10 @@ type SysInfo struct {
 
 	// Whether the cgroup has the mountpoint of "devices" or not
 	CgroupDevicesEnabled bool
+
+	// Whether the cgroup v2 unified hierarchy is mounted on /sys/fs/cgroup,
+	// instead of the per-controller hierarchies of cgroup v1
+	CgroupUnified bool
 }
 
 type cgroupMemInfo struct {
@@ -15,6 +15,12 @@ import (
 const (
 	// SeccompModeFilter refers to the syscall argument SECCOMP_MODE_FILTER.
 	SeccompModeFilter = uintptr(2)
+
+	// cgroup2SuperMagic is the filesystem type of the cgroup v2 unified
+	// hierarchy, CGROUP2_SUPER_MAGIC.
+	cgroup2SuperMagic = 0x63677270
+
+	cgroupRoot = "/sys/fs/cgroup"
 )
 
 func findCgroupMountpoints() (map[string]string, error) {
@@ -36,6 +42,12 @@ func findCgroupMountpoints() (map[string]string, error) {
 // whenever an error occurs or misconfigurations are present.
 func New(quiet bool) *SysInfo {
 	sysInfo := &SysInfo{}
+	if unified, err := isCgroup2Mountpoint(cgroupRoot); err == nil && unified {
+		sysInfo.CgroupUnified = true
+		if !quiet {
+			logrus.Warnf("The cgroup v2 unified hierarchy is mounted on %s, the cgroup v1 controllers are not available", cgroupRoot)
+		}
+	}
 	cgMounts, err := findCgroupMountpoints()
 	if err != nil {
 		logrus.Warnf("Failed to parse cgroup information: %v", err)
@@ -70,6 +82,16 @@ func New(quiet bool) *SysInfo {
 	return sysInfo
 }
 
+// isCgroup2Mountpoint returns whether the cgroup v2 unified hierarchy is
+// mounted on path.
+func isCgroup2Mountpoint(path string) (bool, error) {
+	var st syscall.Statfs_t
+	if err := syscall.Statfs(path, &st); err != nil {
+		return false, err
+	}
+	return int64(st.Type) == cgroup2SuperMagic, nil
+}
+
 // checkCgroupMem reads the memory information from the memory cgroup mount point.
 func checkCgroupMem(cgMounts map[string]string, quiet bool) cgroupMemInfo {
 	mountPoint, ok := cgMounts["memory"]
@@ -56,3 +56,23 @@ func TestCgroupEnabled(t *testing.T) {
 		t.Fatal("cgroupEnabled should be true")
 	}
 }
+
+func TestIsCgroup2Mountpoint(t *testing.T) {
+	tmpDir, err := ioutil.TempDir("", "test-sysinfo-cgroup2")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(tmpDir)
+
+	unified, err := isCgroup2Mountpoint(tmpDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if unified {
+		t.Fatalf("expected %s not to be a cgroup2 mountpoint", tmpDir)
+	}
+
+	if _, err := isCgroup2Mountpoint(filepath.Join(tmpDir, "no-exist")); err == nil {
+		t.Fatal("expected an error for a non-existent path")
+	}
+}
@@ -6,6 +6,7 @@ package truncindex
 import (
 	"errors"
 	"fmt"
+	"sort"
 	"strings"
 	"sync"
 
@@ -27,6 +28,8 @@ var (
 // (multiple ids for the prefix).
 type ErrAmbiguousPrefix struct {
 	prefix string
+	// Candidates are the IDs matching the prefix, sorted.
+	Candidates []string
 }
 
 func (e ErrAmbiguousPrefix) Error() string {
@@ -104,16 +107,9 @@ func (idx *TruncIndex) Get(s string) (string, error) {
 	if s == "" {
 		return "", ErrEmptyPrefix
 	}
-	var (
-		id string
-	)
+	var ids []string
 	subTreeVisitFunc := func(prefix patricia.Prefix, item patricia.Item) error {
-		if id != "" {
-			// we haven't found the ID if there are two or more IDs
-			id = ""
-			return ErrAmbiguousPrefix{prefix: string(prefix)}
-		}
-		id = string(prefix)
+		ids = append(ids, string(prefix))
 		return nil
 	}
 
@@ -122,10 +118,15 @@ func (idx *TruncIndex) Get(s string) (string, error) {
 	if err := idx.trie.VisitSubtree(patricia.Prefix(s), subTreeVisitFunc); err != nil {
 		return "", err
 	}
-	if id != "" {
-		return id, nil
+	switch len(ids) {
+	case 0:
+		return "", ErrNotExist
+	case 1:
+		return ids[0], nil
 	}
-	return "", ErrNotExist
+	// we haven't found the ID if there are two or more IDs
+	sort.Strings(ids)
+	return "", ErrAmbiguousPrefix{prefix: s, Candidates: ids}
 }
 
 // Iterate iterates over all stored IDs, and passes each of them to the given handler.
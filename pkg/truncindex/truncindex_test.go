@@ -2,6 +2,8 @@ package truncindex
 
 import (
 	"math/rand"
+	"reflect"
+	"sort"
 	"testing"
 
 	"github.com/docker/docker/pkg/stringid"
@@ -64,6 +66,18 @@ func TestTruncIndex(t *testing.T) {
 		t.Fatal("An ambiguous id prefix should return an error")
 	}
 
+	// The error should list the candidates, sorted
+	_, err := index.Get(id[:4])
+	ambiguous, ok := err.(ErrAmbiguousPrefix)
+	if !ok {
+		t.Fatalf("Expected an ErrAmbiguousPrefix, got %v", err)
+	}
+	candidates := []string{id, id2}
+	sort.Strings(candidates)
+	if !reflect.DeepEqual(ambiguous.Candidates, candidates) {
+		t.Fatalf("Expected the candidates %v, got %v", candidates, ambiguous.Candidates)
+	}
+
 	// 7 characters should NOT conflict
 	assertIndexGet(t, index, id[:7], id, false)
 	assertIndexGet(t, index, id2[:7], id2, false)
@@ -0,0 +1,140 @@
+package reference
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/BurntSushi/toml"
+)
+
+// AliasStore resolves short image names, which have no registry hostname,
+// using the aliases of a file in the format of the "shortnames.conf" files
+// of the containers tools:
+//
+//	[aliases]
+//	"ubi7" = "registry.access.redhat.com/ubi7/ubi"
+type AliasStore interface {
+	// Resolve returns s with its name replaced by the alias of the name,
+	// keeping the tag or digest of s. s is returned as is if it is not a
+	// short name with an alias.
+	Resolve(s string) (string, error)
+}
+
+type aliasStore struct {
+	mu   sync.Mutex
+	path string
+	// modTime is the modification time of path when it was last read, used
+	// to pick up changes made to the file by an operator.
+	modTime time.Time
+	aliases map[string]string
+}
+
+// NewAliasStore creates an alias store reading the aliases from the file
+// at path. The file is re-read whenever it is modified, and a missing file
+// holds no aliases.
+func NewAliasStore(path string) (AliasStore, error) {
+	abspath, err := filepath.Abs(path)
+	if err != nil {
+		return nil, err
+	}
+	store := &aliasStore{path: abspath}
+	if err := store.refresh(); err != nil {
+		return nil, err
+	}
+	return store, nil
+}
+
+// splitShortName splits s into its name and its tag or digest suffix, and
+// reports whether the name is a short name.
+func splitShortName(s string) (name, suffix string, short bool) {
+	name = s
+	if i := strings.IndexRune(name, '@'); i >= 0 {
+		name, suffix = name[:i], name[i:]
+	}
+	if i := strings.LastIndex(name, ":"); i > strings.LastIndex(name, "/") {
+		name, suffix = name[:i], name[i:]+suffix
+	}
+	if i := strings.IndexRune(name, '/'); i >= 0 {
+		first := name[:i]
+		if strings.ContainsAny(first, ".:") || first == "localhost" {
+			return name, suffix, false
+		}
+	}
+	return name, suffix, name != ""
+}
+
+func (store *aliasStore) Resolve(s string) (string, error) {
+	name, suffix, short := splitShortName(s)
+	if !short {
+		return s, nil
+	}
+
+	store.mu.Lock()
+	defer store.mu.Unlock()
+
+	if err := store.refresh(); err != nil {
+		return "", err
+	}
+	alias, exists := store.aliases[name]
+	if !exists {
+		return s, nil
+	}
+	return alias + suffix, nil
+}
+
+// refresh reloads the file if it was modified since it was last read.
+func (store *aliasStore) refresh() error {
+	fi, err := os.Stat(store.path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			store.aliases = nil
+			store.modTime = time.Time{}
+			return nil
+		}
+		return err
+	}
+	if store.aliases != nil && fi.ModTime().Equal(store.modTime) {
+		return nil
+	}
+
+	var data struct {
+		Aliases map[string]string
+	}
+	if _, err := toml.DecodeFile(store.path, &data); err != nil {
+		return fmt.Errorf("invalid short-name aliases file %s: %v", store.path, err)
+	}
+
+	aliases := make(map[string]string, len(data.Aliases))
+	for name, alias := range data.Aliases {
+		if err := validateAlias(name, alias); err != nil {
+			return fmt.Errorf("invalid short-name aliases file %s: %v", store.path, err)
+		}
+		aliases[name] = alias
+	}
+
+	store.aliases = aliases
+	store.modTime = fi.ModTime()
+	return nil
+}
+
+// validateAlias validates that name is a short name without tag or digest,
+// and that alias is a name with a registry hostname, without tag or digest.
+func validateAlias(name, alias string) error {
+	if n, suffix, short := splitShortName(name); !short || suffix != "" || n != name {
+		return fmt.Errorf("%q is not a short name", name)
+	}
+	if _, err := WithName(name); err != nil {
+		return fmt.Errorf("%q: %v", name, err)
+	}
+	if _, suffix, short := splitShortName(alias); short || suffix != "" || !strings.ContainsRune(alias, '/') {
+		return fmt.Errorf("alias of %q must be a name with a registry hostname, got %q", name, alias)
+	}
+	if _, err := WithName(alias); err != nil {
+		return fmt.Errorf("alias of %q: %v", name, err)
+	}
+	return nil
+}
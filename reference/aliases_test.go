@@ -0,0 +1,100 @@
+package reference
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+const aliasesFile = `[aliases]
+"ubi7" = "registry.access.redhat.com/ubi7/ubi"
+"rhel7/rhel" = "registry.access.redhat.com/rhel7/rhel"
+`
+
+func TestAliasStoreResolve(t *testing.T) {
+	dir, err := ioutil.TempDir("", "aliases")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "shortnames.conf")
+	if err := ioutil.WriteFile(path, []byte(aliasesFile), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	store, err := NewAliasStore(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	resolved := map[string]string{
+		"ubi7":               "registry.access.redhat.com/ubi7/ubi",
+		"ubi7:7.9":           "registry.access.redhat.com/ubi7/ubi:7.9",
+		"rhel7/rhel:latest":  "registry.access.redhat.com/rhel7/rhel:latest",
+		"ubi7@sha256:abcdef": "registry.access.redhat.com/ubi7/ubi@sha256:abcdef",
+		"busybox":            "busybox",
+		"docker.io/ubi7":     "docker.io/ubi7",
+		"localhost/ubi7":     "localhost/ubi7",
+		"localhost:5000/ubi": "localhost:5000/ubi",
+		"ubi7/ubi":           "ubi7/ubi",
+	}
+	for s, expected := range resolved {
+		got, err := store.Resolve(s)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got != expected {
+			t.Fatalf("expected %q to resolve to %q, got %q", s, expected, got)
+		}
+	}
+
+	// changes to the file are picked up
+	if err := ioutil.WriteFile(path, []byte("[aliases]\n\"ubi8\" = \"registry.access.redhat.com/ubi8/ubi\"\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	future := time.Now().Add(time.Minute)
+	if err := os.Chtimes(path, future, future); err != nil {
+		t.Fatal(err)
+	}
+	if got, err := store.Resolve("ubi7"); err != nil || got != "ubi7" {
+		t.Fatalf("expected removed alias not to resolve, got %q, %v", got, err)
+	}
+	if got, err := store.Resolve("ubi8"); err != nil || got != "registry.access.redhat.com/ubi8/ubi" {
+		t.Fatalf("expected new alias to resolve, got %q, %v", got, err)
+	}
+
+	// a removed file holds no aliases
+	if err := os.Remove(path); err != nil {
+		t.Fatal(err)
+	}
+	if got, err := store.Resolve("ubi8"); err != nil || got != "ubi8" {
+		t.Fatalf("expected no aliases without file, got %q, %v", got, err)
+	}
+}
+
+func TestAliasStoreInvalid(t *testing.T) {
+	dir, err := ioutil.TempDir("", "aliases")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "shortnames.conf")
+
+	for _, content := range []string{
+		"[aliases\n",
+		"[aliases]\n\"ubi7:latest\" = \"registry.access.redhat.com/ubi7/ubi\"\n",
+		"[aliases]\n\"quay.io/ubi7\" = \"registry.access.redhat.com/ubi7/ubi\"\n",
+		"[aliases]\n\"ubi7\" = \"ubi7/ubi\"\n",
+		"[aliases]\n\"ubi7\" = \"registry.access.redhat.com/ubi7/ubi:latest\"\n",
+		"[aliases]\n\"UBI7\" = \"registry.access.redhat.com/ubi7/ubi\"\n",
+	} {
+		if err := ioutil.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := NewAliasStore(path); err == nil {
+			t.Fatalf("expected aliases file to be rejected:\n%s", content)
+		}
+	}
+}
@@ -0,0 +1,237 @@
+package reference
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/docker/distribution/digest"
+	"github.com/docker/docker/pkg/ioutils"
+)
+
+// A Pin associates a tag reference with the only manifest digest the
+// daemon accepts when pulling it.
+type Pin struct {
+	Ref    NamedTagged
+	Digest digest.Digest
+}
+
+// PinStore provides the set of methods which can operate on the pinned
+// references file.
+type PinStore interface {
+	Pin(ref Named, dgst digest.Digest) error
+	Unpin(ref Named) (bool, error)
+	Get(ref Named) (digest.Digest, error)
+	Pins() []Pin
+}
+
+type pinStore struct {
+	mu sync.Mutex
+	// jsonPath is the path to the file where the pins are stored.
+	jsonPath string
+	// modTime is the modification time of jsonPath when it was last read,
+	// used to pick up changes made to the file by an operator.
+	modTime time.Time
+	// References maps stringified tag references to their pinned digest.
+	References map[string]digest.Digest
+}
+
+type lexicalPins []Pin
+
+func (a lexicalPins) Len() int           { return len(a) }
+func (a lexicalPins) Swap(i, j int)      { a[i], a[j] = a[j], a[i] }
+func (a lexicalPins) Less(i, j int) bool { return a[i].Ref.String() < a[j].Ref.String() }
+
+// NewPinStore creates a new pin store, tied to a file path where the pinned
+// references are serialized in JSON format. The file is created if it does
+// not exist, and re-read whenever it is modified outside of the daemon.
+func NewPinStore(jsonPath string) (PinStore, error) {
+	abspath, err := filepath.Abs(jsonPath)
+	if err != nil {
+		return nil, err
+	}
+
+	store := &pinStore{
+		jsonPath:   abspath,
+		References: make(map[string]digest.Digest),
+	}
+	if err := store.reload(); os.IsNotExist(err) {
+		if err := store.save(); err != nil {
+			return nil, err
+		}
+	} else if err != nil {
+		return nil, err
+	}
+	return store, nil
+}
+
+// pinnedTag validates that ref can be pinned and returns it with the
+// default tag applied.
+func pinnedTag(ref Named) (NamedTagged, error) {
+	if _, isCanonical := ref.(Canonical); isCanonical {
+		return nil, errors.New("cannot pin a digest reference")
+	}
+	tagged, ok := WithDefaultTag(ref).(NamedTagged)
+	if !ok {
+		return nil, fmt.Errorf("invalid reference %s", ref.String())
+	}
+	return tagged, nil
+}
+
+// Pin pins the tag reference ref to dgst, replacing any existing pin.
+func (store *pinStore) Pin(ref Named, dgst digest.Digest) error {
+	tagged, err := pinnedTag(ref)
+	if err != nil {
+		return err
+	}
+	if err := dgst.Validate(); err != nil {
+		return err
+	}
+
+	store.mu.Lock()
+	defer store.mu.Unlock()
+
+	if err := store.refresh(); err != nil {
+		return err
+	}
+	store.References[tagged.String()] = dgst
+	return store.save()
+}
+
+// Unpin removes the pin of ref. It returns true if a pin was removed.
+func (store *pinStore) Unpin(ref Named) (bool, error) {
+	tagged, err := pinnedTag(ref)
+	if err != nil {
+		return false, err
+	}
+
+	store.mu.Lock()
+	defer store.mu.Unlock()
+
+	if err := store.refresh(); err != nil {
+		return false, err
+	}
+	if _, exists := store.References[tagged.String()]; !exists {
+		return false, ErrDoesNotExist
+	}
+	delete(store.References, tagged.String())
+	return true, store.save()
+}
+
+// Get returns the digest ref is pinned to, or ErrDoesNotExist if the
+// reference is not pinned.
+func (store *pinStore) Get(ref Named) (digest.Digest, error) {
+	tagged, err := pinnedTag(ref)
+	if err != nil {
+		return "", ErrDoesNotExist
+	}
+
+	store.mu.Lock()
+	defer store.mu.Unlock()
+
+	if err := store.refresh(); err != nil {
+		return "", err
+	}
+	dgst, exists := store.References[tagged.String()]
+	if !exists {
+		return "", ErrDoesNotExist
+	}
+	return dgst, nil
+}
+
+// Pins returns all the pinned references, sorted by reference.
+func (store *pinStore) Pins() []Pin {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+
+	if err := store.refresh(); err != nil {
+		return nil
+	}
+
+	pins := make([]Pin, 0, len(store.References))
+	for refStr, dgst := range store.References {
+		ref, err := ParseNamed(refStr)
+		if err != nil {
+			// Entries are validated when loaded
+			continue
+		}
+		pins = append(pins, Pin{Ref: ref.(NamedTagged), Digest: dgst})
+	}
+	sort.Sort(lexicalPins(pins))
+	return pins
+}
+
+// refresh reloads the file if it was modified since it was last read.
+func (store *pinStore) refresh() error {
+	fi, err := os.Stat(store.jsonPath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			store.References = make(map[string]digest.Digest)
+			return store.save()
+		}
+		return err
+	}
+	if fi.ModTime().Equal(store.modTime) {
+		return nil
+	}
+	return store.reload()
+}
+
+func (store *pinStore) save() error {
+	jsonData, err := json.MarshalIndent(store, "", "\t")
+	if err != nil {
+		return err
+	}
+	if err := ioutils.AtomicWriteFile(store.jsonPath, jsonData, 0644); err != nil {
+		return err
+	}
+	if fi, err := os.Stat(store.jsonPath); err == nil {
+		store.modTime = fi.ModTime()
+	}
+	return nil
+}
+
+func (store *pinStore) reload() error {
+	f, err := os.Open(store.jsonPath)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	fi, err := f.Stat()
+	if err != nil {
+		return err
+	}
+
+	var data struct {
+		References map[string]digest.Digest
+	}
+	if err := json.NewDecoder(f).Decode(&data); err != nil {
+		return fmt.Errorf("invalid pinned references file %s: %v", store.jsonPath, err)
+	}
+
+	pins := make(map[string]digest.Digest, len(data.References))
+	for refStr, dgst := range data.References {
+		ref, err := ParseNamed(refStr)
+		if err != nil {
+			return fmt.Errorf("invalid pinned references file %s: %v", store.jsonPath, err)
+		}
+		tagged, err := pinnedTag(ref)
+		if err != nil {
+			return fmt.Errorf("invalid pinned references file %s: %s: %v", store.jsonPath, refStr, err)
+		}
+		if err := dgst.Validate(); err != nil {
+			return fmt.Errorf("invalid pinned references file %s: %s: %v", store.jsonPath, refStr, err)
+		}
+		pins[tagged.String()] = dgst
+	}
+
+	store.References = pins
+	store.modTime = fi.ModTime()
+	return nil
+}
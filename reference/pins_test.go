@@ -0,0 +1,125 @@
+package reference
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/docker/distribution/digest"
+)
+
+const (
+	pinnedDigest1 = digest.Digest("sha256:470022b8af682154f57a2163d030eb369549549cba00edc69e1b99b46bb924d6")
+	pinnedDigest2 = digest.Digest("sha256:ae300ebc4a4f00693702cfb0a5e0b7bc527b353828dc86ad09fb95c8a681b793")
+)
+
+func newTestPinStore(t *testing.T) (PinStore, string, func()) {
+	tmpDir, err := ioutil.TempDir("", "pin-store-test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	jsonPath := filepath.Join(tmpDir, "pins.json")
+	store, err := NewPinStore(jsonPath)
+	if err != nil {
+		os.RemoveAll(tmpDir)
+		t.Fatalf("error creating pin store: %v", err)
+	}
+	return store, jsonPath, func() { os.RemoveAll(tmpDir) }
+}
+
+func TestPinStore(t *testing.T) {
+	store, jsonPath, cleanup := newTestPinStore(t)
+	defer cleanup()
+
+	busybox, _ := ParseNamed("busybox")
+	if _, err := store.Get(busybox); err != ErrDoesNotExist {
+		t.Fatalf("expected ErrDoesNotExist, got %v", err)
+	}
+
+	if err := store.Pin(busybox, pinnedDigest1); err != nil {
+		t.Fatalf("error pinning reference: %v", err)
+	}
+	latest, _ := ParseNamed("docker.io/library/busybox:latest")
+	if dgst, err := store.Get(latest); err != nil || dgst != pinnedDigest1 {
+		t.Fatalf("expected %s, got %s (%v)", pinnedDigest1, dgst, err)
+	}
+
+	// Pinning again replaces the digest
+	if err := store.Pin(latest, pinnedDigest2); err != nil {
+		t.Fatalf("error pinning reference: %v", err)
+	}
+	other, _ := ParseNamed("registry:5000/foobar:HEAD")
+	if err := store.Pin(other, pinnedDigest1); err != nil {
+		t.Fatalf("error pinning reference: %v", err)
+	}
+
+	// The pins are persisted
+	reloaded, err := NewPinStore(jsonPath)
+	if err != nil {
+		t.Fatalf("error reloading pin store: %v", err)
+	}
+	pins := reloaded.Pins()
+	if len(pins) != 2 {
+		t.Fatalf("expected 2 pins, got %d", len(pins))
+	}
+	if pins[0].Ref.String() != "busybox:latest" || pins[0].Digest != pinnedDigest2 {
+		t.Fatalf("unexpected pin %s@%s", pins[0].Ref.String(), pins[0].Digest)
+	}
+	if pins[1].Ref.String() != "registry:5000/foobar:HEAD" || pins[1].Digest != pinnedDigest1 {
+		t.Fatalf("unexpected pin %s@%s", pins[1].Ref.String(), pins[1].Digest)
+	}
+
+	if removed, err := store.Unpin(busybox); err != nil || !removed {
+		t.Fatalf("error unpinning reference: %v", err)
+	}
+	if _, err := store.Unpin(busybox); err != ErrDoesNotExist {
+		t.Fatalf("expected ErrDoesNotExist, got %v", err)
+	}
+	if _, err := store.Get(busybox); err != ErrDoesNotExist {
+		t.Fatalf("expected ErrDoesNotExist, got %v", err)
+	}
+}
+
+func TestPinStoreInvalid(t *testing.T) {
+	store, _, cleanup := newTestPinStore(t)
+	defer cleanup()
+
+	canonical, _ := ParseNamed("busybox@" + string(pinnedDigest1))
+	if err := store.Pin(canonical, pinnedDigest1); err == nil {
+		t.Fatal("expected error pinning a digest reference")
+	}
+	ref, _ := ParseNamed("busybox:latest")
+	if err := store.Pin(ref, digest.Digest("sha256:invalid")); err == nil {
+		t.Fatal("expected error pinning to an invalid digest")
+	}
+}
+
+func TestPinStoreExternalChanges(t *testing.T) {
+	store, jsonPath, cleanup := newTestPinStore(t)
+	defer cleanup()
+
+	data := []byte(`{"References":{"busybox:1.24":"` + string(pinnedDigest1) + `"}}`)
+	if err := ioutil.WriteFile(jsonPath, data, 0644); err != nil {
+		t.Fatal(err)
+	}
+	// make sure the modification is noticed on filesystems with a coarse
+	// timestamp granularity
+	future := time.Now().Add(time.Minute)
+	if err := os.Chtimes(jsonPath, future, future); err != nil {
+		t.Fatal(err)
+	}
+
+	ref, _ := ParseNamed("busybox:1.24")
+	if dgst, err := store.Get(ref); err != nil || dgst != pinnedDigest1 {
+		t.Fatalf("expected %s, got %s (%v)", pinnedDigest1, dgst, err)
+	}
+
+	if err := ioutil.WriteFile(jsonPath, []byte(`{"References":{"busybox@sha256:abc":"sha256:def"}}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := NewPinStore(jsonPath); err == nil {
+		t.Fatal("expected error loading an invalid pins file")
+	}
+}
@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"net"
 	"net/url"
+	"path/filepath"
 	"strings"
 
 	"github.com/docker/docker/opts"
@@ -21,12 +22,21 @@ type ServiceOptions struct {
 	// V2Only controls access to legacy registries.  If it is set to true via the
 	// command line flag the daemon will not attempt to contact v1 legacy registries
 	V2Only bool `json:"disable-legacy-registry,omitempty"`
+
+	// CertsDirs overrides, by registry host, the directory the certificates
+	// of the registry are loaded from instead of CertsDir/<host>.
+	CertsDirs map[string]string `json:"registry-certs-dirs,omitempty"`
+
+	// TrustServers sets, by registry host, the Notary server clients verify
+	// the images of the registry against when content trust is enabled.
+	TrustServers map[string]string `json:"registry-trust-servers,omitempty"`
 }
 
 // serviceConfig holds daemon configuration for the registry service.
 type serviceConfig struct {
 	registrytypes.ServiceConfig
-	V2Only bool
+	V2Only    bool
+	CertsDirs map[string]string
 }
 
 var (
@@ -78,6 +88,12 @@ func (options *ServiceOptions) InstallCliFlags(cmd *flag.FlagSet, usageFn func(s
 	cmd.Var(insecureRegistries, []string{"-insecure-registry"}, usageFn("Enable insecure registry communication"))
 
 	cmd.BoolVar(&options.V2Only, []string{"-disable-legacy-registry"}, false, usageFn("Do not contact legacy registries"))
+
+	options.CertsDirs = make(map[string]string)
+	cmd.Var(opts.NewNamedMapOpts("registry-certs-dirs", options.CertsDirs, ValidateCertsDir), []string{"-registry-certs-dir"}, usageFn("Set the certificates directory of a registry (host=directory)"))
+
+	options.TrustServers = make(map[string]string)
+	cmd.Var(opts.NewNamedMapOpts("registry-trust-servers", options.TrustServers, ValidateTrustServer), []string{"-registry-trust-server"}, usageFn("Set the content trust server of a registry (host=URL)"))
 }
 
 // newServiceConfig returns a new instance of ServiceConfig
@@ -95,9 +111,19 @@ func newServiceConfig(options ServiceOptions) *serviceConfig {
 			IndexConfigs:          make(map[string]*registrytypes.IndexInfo, 0),
 			// Hack: Bypass setting the mirrors to IndexConfigs since they are going away
 			// and Mirrors are only for the official registry anyways.
-			Mirrors: options.Mirrors,
+			Mirrors:      options.Mirrors,
+			TrustServers: make(map[string]string),
 		},
-		V2Only: options.V2Only,
+		V2Only:    options.V2Only,
+		CertsDirs: options.CertsDirs,
+	}
+	// Key the trust servers by index name, as in the configuration file
+	// they are not normalized by the flag validation.
+	for host, server := range options.TrustServers {
+		if val, err := ValidateTrustServer(host + "=" + server); err == nil {
+			parts := strings.SplitN(val, "=", 2)
+			config.TrustServers[parts[0]] = parts[1]
+		}
 	}
 	// Split --insecure-registry into CIDR and registry-specific settings.
 	for _, r := range options.InsecureRegistries {
@@ -195,6 +221,48 @@ func ValidateMirror(val string) (string, error) {
 	return fmt.Sprintf("%s://%s/", uri.Scheme, uri.Host), nil
 }
 
+// ValidateCertsDir validates the certificates directory of a registry, in
+// the host=directory format. The host is the one of the directories of
+// CertsDir, with the port of the registry if it is not the default one, and
+// the directory must be an absolute path.
+func ValidateCertsDir(val string) (string, error) {
+	parts := strings.SplitN(val, "=", 2)
+	if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+		return "", fmt.Errorf("invalid registry certificates directory %s: the format is host=directory", val)
+	}
+	host, dir := parts[0], parts[1]
+	if strings.Contains(host, "/") {
+		return "", fmt.Errorf("invalid registry certificates directory %s: %s is not a host", val, host)
+	}
+	if !filepath.IsAbs(dir) {
+		return "", fmt.Errorf("invalid registry certificates directory %s: %s is not an absolute path", val, dir)
+	}
+	return host + "=" + filepath.Clean(dir), nil
+}
+
+// ValidateTrustServer validates the content trust server of a registry, in
+// the host=URL format. The host is the index name of the registry, and the
+// URL must be an https one.
+func ValidateTrustServer(val string) (string, error) {
+	parts := strings.SplitN(val, "=", 2)
+	if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+		return "", fmt.Errorf("invalid registry trust server %s: the format is host=URL", val)
+	}
+	host, server := parts[0], parts[1]
+	if strings.Contains(host, "/") {
+		return "", fmt.Errorf("invalid registry trust server %s: %s is not a host", val, host)
+	}
+	host, err := ValidateIndexName(host)
+	if err != nil {
+		return "", err
+	}
+	uri, err := url.Parse(server)
+	if err != nil || uri.Scheme != "https" || uri.Host == "" {
+		return "", fmt.Errorf("invalid registry trust server %s: %s is not an https URL", val, server)
+	}
+	return host + "=" + strings.TrimSuffix(server, "/"), nil
+}
+
 // ValidateIndexName validates an index name.
 func ValidateIndexName(val string) (string, error) {
 	if val == reference.LegacyDefaultHostname {
@@ -47,3 +47,59 @@ func TestValidateMirror(t *testing.T) {
 		}
 	}
 }
+
+func TestValidateCertsDir(t *testing.T) {
+	valid := map[string]string{
+		"registry.example.com=/etc/docker/certs.d/shared":       "registry.example.com=/etc/docker/certs.d/shared",
+		"registry.example.com:5000=/etc/docker/certs.d/shared/": "registry.example.com:5000=/etc/docker/certs.d/shared",
+	}
+
+	invalid := []string{
+		"registry.example.com",
+		"registry.example.com=",
+		"=/etc/docker/certs.d/shared",
+		"registry.example.com=certs.d/shared",
+		"https://registry.example.com=/etc/docker/certs.d/shared",
+	}
+
+	for val, expected := range valid {
+		if ret, err := ValidateCertsDir(val); err != nil || ret != expected {
+			t.Errorf("ValidateCertsDir(`"+val+"`) got %s %s", ret, err)
+		}
+	}
+
+	for _, val := range invalid {
+		if ret, err := ValidateCertsDir(val); err == nil || ret != "" {
+			t.Errorf("ValidateCertsDir(`"+val+"`) got %s %s", ret, err)
+		}
+	}
+}
+
+func TestValidateTrustServer(t *testing.T) {
+	valid := map[string]string{
+		"registry.example.com=https://notary.example.com":            "registry.example.com=https://notary.example.com",
+		"registry.example.com:5000=https://notary.example.com:4443/": "registry.example.com:5000=https://notary.example.com:4443",
+		"index.docker.io=https://notary.example.com":                 "docker.io=https://notary.example.com",
+	}
+
+	invalid := []string{
+		"registry.example.com",
+		"registry.example.com=",
+		"=https://notary.example.com",
+		"registry.example.com=http://notary.example.com",
+		"registry.example.com=notary.example.com",
+		"https://registry.example.com=https://notary.example.com",
+	}
+
+	for val, expected := range valid {
+		if ret, err := ValidateTrustServer(val); err != nil || ret != expected {
+			t.Errorf("ValidateTrustServer(`"+val+"`) got %s %s", ret, err)
+		}
+	}
+
+	for _, val := range invalid {
+		if ret, err := ValidateTrustServer(val); err == nil || ret != "" {
+			t.Errorf("ValidateTrustServer(`"+val+"`) got %s %s", ret, err)
+		}
+	}
+}
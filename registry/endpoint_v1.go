@@ -22,8 +22,10 @@ type V1Endpoint struct {
 }
 
 // NewV1Endpoint parses the given address to return a registry endpoint.
-func NewV1Endpoint(index *registrytypes.IndexInfo, userAgent string, metaHeaders http.Header) (*V1Endpoint, error) {
-	tlsConfig, err := newTLSConfig(index.Name, index.Secure)
+// The certificates of the registry are loaded from the directory certsDirs
+// sets for it, if any, or from CertsDir/<hostname>.
+func NewV1Endpoint(index *registrytypes.IndexInfo, userAgent string, metaHeaders http.Header, certsDirs map[string]string) (*V1Endpoint, error) {
+	tlsConfig, err := newTLSConfig(index.Name, index.Secure, certsDirs)
 	if err != nil {
 		return nil, err
 	}
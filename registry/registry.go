@@ -26,14 +26,21 @@ var (
 	ErrAlreadyExists = errors.New("Image already exists")
 )
 
-func newTLSConfig(hostname string, isSecure bool) (*tls.Config, error) {
+// newTLSConfig returns the TLS configuration of the registry hostname. Its
+// certificates are loaded from the directory certsDirs sets for hostname, if
+// any, or from CertsDir/<hostname>. A nil certsDirs loads the certificates
+// of all registries from CertsDir.
+func newTLSConfig(hostname string, isSecure bool, certsDirs map[string]string) (*tls.Config, error) {
 	// PreferredServerCipherSuites should have no effect
 	tlsConfig := tlsconfig.ServerDefault
 
 	tlsConfig.InsecureSkipVerify = !isSecure
 
-	if isSecure && CertsDir != "" {
-		hostDir := filepath.Join(CertsDir, cleanPath(hostname))
+	hostDir := certsDirs[hostname]
+	if hostDir == "" && CertsDir != "" {
+		hostDir = filepath.Join(CertsDir, cleanPath(hostname))
+	}
+	if isSecure && hostDir != "" {
 		logrus.Debugf("hostDir: %s", hostDir)
 		if err := ReadCertsDirectory(&tlsConfig, hostDir); err != nil {
 			return nil, err
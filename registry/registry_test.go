@@ -2,9 +2,12 @@ package registry
 
 import (
 	"fmt"
+	"io/ioutil"
 	"net/http"
 	"net/http/httputil"
 	"net/url"
+	"os"
+	"path/filepath"
 	"strings"
 	"testing"
 
@@ -25,7 +28,7 @@ const (
 
 func spawnTestRegistrySession(t *testing.T) *Session {
 	authConfig := &types.AuthConfig{}
-	endpoint, err := NewV1Endpoint(makeIndex("/v1/"), "", nil)
+	endpoint, err := NewV1Endpoint(makeIndex("/v1/"), "", nil, nil)
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -53,7 +56,7 @@ func spawnTestRegistrySession(t *testing.T) *Session {
 
 func TestPingRegistryEndpoint(t *testing.T) {
 	testPing := func(index *registrytypes.IndexInfo, expectedStandalone bool, assertMessage string) {
-		ep, err := NewV1Endpoint(index, "", nil)
+		ep, err := NewV1Endpoint(index, "", nil, nil)
 		if err != nil {
 			t.Fatal(err)
 		}
@@ -73,7 +76,7 @@ func TestPingRegistryEndpoint(t *testing.T) {
 func TestEndpoint(t *testing.T) {
 	// Simple wrapper to fail test if err != nil
 	expandEndpoint := func(index *registrytypes.IndexInfo) *V1Endpoint {
-		endpoint, err := NewV1Endpoint(index, "", nil)
+		endpoint, err := NewV1Endpoint(index, "", nil, nil)
 		if err != nil {
 			t.Fatal(err)
 		}
@@ -82,7 +85,7 @@ func TestEndpoint(t *testing.T) {
 
 	assertInsecureIndex := func(index *registrytypes.IndexInfo) {
 		index.Secure = true
-		_, err := NewV1Endpoint(index, "", nil)
+		_, err := NewV1Endpoint(index, "", nil, nil)
 		assertNotEqual(t, err, nil, index.Name+": Expected error for insecure index")
 		assertEqual(t, strings.Contains(err.Error(), "insecure-registry"), true, index.Name+": Expected insecure-registry  error for insecure index")
 		index.Secure = false
@@ -90,7 +93,7 @@ func TestEndpoint(t *testing.T) {
 
 	assertSecureIndex := func(index *registrytypes.IndexInfo) {
 		index.Secure = true
-		_, err := NewV1Endpoint(index, "", nil)
+		_, err := NewV1Endpoint(index, "", nil, nil)
 		assertNotEqual(t, err, nil, index.Name+": Expected cert error for secure index")
 		assertEqual(t, strings.Contains(err.Error(), "certificate signed by unknown authority"), true, index.Name+": Expected cert error for secure index")
 		index.Secure = false
@@ -138,7 +141,7 @@ func TestEndpoint(t *testing.T) {
 	}
 	for _, address := range badEndpoints {
 		index.Name = address
-		_, err := NewV1Endpoint(index, "", nil)
+		_, err := NewV1Endpoint(index, "", nil, nil)
 		checkNotEqual(t, err, nil, "Expected error while expanding bad endpoint")
 	}
 }
@@ -871,3 +874,30 @@ func (tr debugTransport) RoundTrip(req *http.Request) (*http.Response, error) {
 	tr.log(string(dump))
 	return resp, err
 }
+
+func TestTLSConfigCertsDirs(t *testing.T) {
+	dir, err := ioutil.TempDir("", "registry-certs-dir")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	// a client certificate without its key fails loading the directory
+	if err := ioutil.WriteFile(filepath.Join(dir, "client.cert"), []byte{}, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	s := NewService(ServiceOptions{CertsDirs: map[string]string{"registry.example.com:5000": dir}})
+	if _, err := s.TLSConfig("registry.example.com:5000"); err == nil || !strings.Contains(err.Error(), "Missing key") {
+		t.Fatalf("expected the certificates to be loaded from %s, got %v", dir, err)
+	}
+	if _, err := s.TLSConfig("registry.example.com"); err != nil {
+		t.Fatalf("expected the directory to only be used for registry.example.com:5000, got %v", err)
+	}
+
+	// without certsDirs, the certificates are loaded from CertsDir
+	defer func(certsDir string) { CertsDir = certsDir }(CertsDir)
+	CertsDir = filepath.Dir(dir)
+	if _, err := newTLSConfig(filepath.Base(dir), true, nil); err == nil || !strings.Contains(err.Error(), "Missing key") {
+		t.Fatalf("expected the certificates to be loaded from %s, got %v", dir, err)
+	}
+}
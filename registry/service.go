@@ -6,6 +6,7 @@ import (
 	"net/http"
 	"net/url"
 	"strings"
+	"sync"
 
 	"golang.org/x/net/context"
 
@@ -30,11 +31,13 @@ type Service interface {
 	Search(ctx context.Context, term string, limit int, authConfig *types.AuthConfig, userAgent string, headers map[string][]string) (*registrytypes.SearchResults, error)
 	ServiceConfig() *registrytypes.ServiceConfig
 	TLSConfig(hostname string) (*tls.Config, error)
+	Reload(options ServiceOptions)
 }
 
 // DefaultService is a registry service. It tracks configuration data such as a list
 // of mirrors.
 type DefaultService struct {
+	mu     sync.Mutex
 	config *serviceConfig
 }
 
@@ -48,7 +51,27 @@ func NewService(options ServiceOptions) *DefaultService {
 
 // ServiceConfig returns the public registry service configuration.
 func (s *DefaultService) ServiceConfig() *registrytypes.ServiceConfig {
-	return &s.config.ServiceConfig
+	return &s.currentConfig().ServiceConfig
+}
+
+// Reload replaces the configuration of the service with the one of options,
+// so that the mirrors and insecure registries can be changed while the
+// daemon runs. The lookups in progress keep the configuration they started
+// with.
+func (s *DefaultService) Reload(options ServiceOptions) {
+	config := newServiceConfig(options)
+
+	s.mu.Lock()
+	s.config = config
+	s.mu.Unlock()
+}
+
+// currentConfig returns the configuration of the service, as last set by
+// NewService or Reload.
+func (s *DefaultService) currentConfig() *serviceConfig {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.config
 }
 
 // Auth contacts the public registry with the provided credentials,
@@ -121,13 +144,14 @@ func (s *DefaultService) Search(ctx context.Context, term string, limit int, aut
 
 	indexName, remoteName := splitReposSearchTerm(term)
 
-	index, err := newIndexInfo(s.config, indexName)
+	config := s.currentConfig()
+	index, err := newIndexInfo(config, indexName)
 	if err != nil {
 		return nil, err
 	}
 
 	// *TODO: Search multiple indexes.
-	endpoint, err := NewV1Endpoint(index, userAgent, http.Header(headers))
+	endpoint, err := NewV1Endpoint(index, userAgent, http.Header(headers), config.CertsDirs)
 	if err != nil {
 		return nil, err
 	}
@@ -152,12 +176,12 @@ func (s *DefaultService) Search(ctx context.Context, term string, limit int, aut
 // ResolveRepository splits a repository name into its components
 // and configuration of the associated registry.
 func (s *DefaultService) ResolveRepository(name reference.Named) (*RepositoryInfo, error) {
-	return newRepositoryInfo(s.config, name)
+	return newRepositoryInfo(s.currentConfig(), name)
 }
 
 // ResolveIndex takes indexName and returns index info
 func (s *DefaultService) ResolveIndex(name string) (*registrytypes.IndexInfo, error) {
-	return newIndexInfo(s.config, name)
+	return newIndexInfo(s.currentConfig(), name)
 }
 
 // APIEndpoint represents a remote API endpoint
@@ -177,7 +201,8 @@ func (e APIEndpoint) ToV1Endpoint(userAgent string, metaHeaders http.Header) (*V
 
 // TLSConfig constructs a client TLS configuration based on server defaults
 func (s *DefaultService) TLSConfig(hostname string) (*tls.Config, error) {
-	return newTLSConfig(hostname, isSecureIndex(s.config, hostname))
+	config := s.currentConfig()
+	return newTLSConfig(hostname, isSecureIndex(config, hostname), config.CertsDirs)
 }
 
 func (s *DefaultService) tlsConfigForMirror(mirrorURL *url.URL) (*tls.Config, error) {
@@ -212,7 +237,7 @@ func (s *DefaultService) lookupEndpoints(hostname string) (endpoints []APIEndpoi
 		return nil, err
 	}
 
-	if s.config.V2Only {
+	if s.currentConfig().V2Only {
 		return endpoints, nil
 	}
 
@@ -4,6 +4,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"io"
+	"strings"
 
 	"github.com/docker/docker/volume"
 	"github.com/docker/engine-api/types/container"
@@ -30,9 +31,7 @@ func (r ContainerDecoder) DecodeHostConfig(src io.Reader) (*container.HostConfig
 // it's your business to do so
 func DecodeContainerConfig(src io.Reader) (*container.Config, *container.HostConfig, *networktypes.NetworkingConfig, error) {
 	var w ContainerConfigWrapper
-
-	decoder := json.NewDecoder(src)
-	if err := decoder.Decode(&w); err != nil {
+	if err := decodeContainerConfigWrapper(src, &w); err != nil {
 		return nil, nil, nil, err
 	}
 
@@ -70,6 +69,102 @@ func DecodeContainerConfig(src io.Reader) (*container.Config, *container.HostCon
 	return w.Config, hc, w.NetworkingConfig, nil
 }
 
+// decodeContainerConfigWrapper decodes a json encoded config into w while
+// it is read. The environment, which may be very large, is decoded one
+// variable at a time instead of being buffered, and fails at the first
+// invalid variable; the other fields are decoded once the config is read.
+func decodeContainerConfigWrapper(src io.Reader, w *ContainerConfigWrapper) error {
+	decoder := json.NewDecoder(src)
+	tok, err := decoder.Token()
+	if err != nil {
+		return err
+	}
+	if tok == nil {
+		return nil
+	}
+	if tok != json.Delim('{') {
+		return fmt.Errorf("invalid container config: expected an object, got %v", tok)
+	}
+
+	var (
+		env    []string
+		hasEnv bool
+		fields = make(map[string]json.RawMessage)
+	)
+	for decoder.More() {
+		tok, err := decoder.Token()
+		if err != nil {
+			return err
+		}
+		key, ok := tok.(string)
+		if !ok {
+			return fmt.Errorf("invalid container config: expected a field name, got %v", tok)
+		}
+		if strings.EqualFold(key, "Env") {
+			if env, err = decodeEnv(decoder); err != nil {
+				return err
+			}
+			hasEnv = true
+			continue
+		}
+		var value json.RawMessage
+		if err := decoder.Decode(&value); err != nil {
+			return err
+		}
+		fields[key] = value
+	}
+	if _, err := decoder.Token(); err != nil {
+		return err
+	}
+
+	b, err := json.Marshal(fields)
+	if err != nil {
+		return err
+	}
+	if err := json.Unmarshal(b, w); err != nil {
+		return err
+	}
+	if hasEnv {
+		if w.Config == nil {
+			w.Config = &container.Config{}
+		}
+		w.Config.Env = env
+	}
+	return nil
+}
+
+// decodeEnv decodes the environment of a config, an array of strings, one
+// variable at a time.
+func decodeEnv(decoder *json.Decoder) ([]string, error) {
+	tok, err := decoder.Token()
+	if err != nil {
+		return nil, err
+	}
+	if tok == nil {
+		return nil, nil
+	}
+	if tok != json.Delim('[') {
+		return nil, fmt.Errorf("invalid environment: expected an array of strings, got %v", tok)
+	}
+
+	env := []string{}
+	for decoder.More() {
+		tok, err := decoder.Token()
+		if err != nil {
+			return nil, err
+		}
+		v, ok := tok.(string)
+		if !ok {
+			return nil, fmt.Errorf("invalid environment variable %d: expected a string, got %v", len(env), tok)
+		}
+		env = append(env, v)
+	}
+	if _, err := decoder.Token(); err != nil {
+		return nil, err
+	}
+	return env, nil
+}
+
 // validateVolumesAndBindSettings validates each of the volumes and bind settings
 // passed by the caller to ensure they are valid.
 func validateVolumesAndBindSettings(c *container.Config, hc *container.HostConfig) error {
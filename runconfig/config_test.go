@@ -3,7 +3,9 @@ package runconfig
 import (
 	"bytes"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
 	"io/ioutil"
 	"runtime"
 	"strings"
@@ -65,6 +67,50 @@ func TestDecodeContainerConfig(t *testing.T) {
 	}
 }
 
+// failAfterReader returns the content of a reader followed by an error, to
+// check that a config is rejected before the rest of it is read.
+type failAfterReader struct {
+	r io.Reader
+}
+
+func (f failAfterReader) Read(p []byte) (int, error) {
+	n, err := f.r.Read(p)
+	if err == io.EOF {
+		return n, errors.New("read past the invalid variable")
+	}
+	return n, err
+}
+
+func TestDecodeContainerConfigEnv(t *testing.T) {
+	c, _, _, err := DecodeContainerConfig(strings.NewReader(`{"Image": "busybox", "env": ["A=1", "B=2"], "Labels": {"a": "b"}}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if c.Image != "busybox" || c.Labels["a"] != "b" {
+		t.Fatalf("Expected the other fields to be decoded, got %+v", c)
+	}
+	if len(c.Env) != 2 || c.Env[0] != "A=1" || c.Env[1] != "B=2" {
+		t.Fatalf("Expected the environment to be decoded, got %v", c.Env)
+	}
+
+	c, _, _, err = DecodeContainerConfig(strings.NewReader(`{"Env": null}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if c == nil || c.Env != nil {
+		t.Fatalf("Expected an empty environment, got %+v", c)
+	}
+
+	src := failAfterReader{strings.NewReader(`{"Env": ["A=1", 2, "B=2"`)}
+	if _, _, _, err := DecodeContainerConfig(src); err == nil || !strings.Contains(err.Error(), "invalid environment variable 1") {
+		t.Fatalf("Expected the invalid variable to be reported, got %v", err)
+	}
+
+	if _, _, _, err := DecodeContainerConfig(strings.NewReader(``)); err != io.EOF {
+		t.Fatalf("Expected an empty config to return io.EOF, got %v", err)
+	}
+}
+
 // TestDecodeContainerConfigIsolation validates isolation passed
 // to the daemon in the hostConfig structure. Note this is platform specific
 // as to what level of container isolation is supported.
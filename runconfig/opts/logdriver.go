@@ -0,0 +1,61 @@
+package opts
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// LogDriverOpts defines a map of the default options of each log driver
+type LogDriverOpts struct {
+	name   string
+	values *map[string]map[string]string
+}
+
+// NewNamedLogDriverOpts creates a new LogDriverOpts
+func NewNamedLogDriverOpts(name string, ref *map[string]map[string]string) *LogDriverOpts {
+	if *ref == nil {
+		*ref = make(map[string]map[string]string)
+	}
+	return &LogDriverOpts{name: name, values: ref}
+}
+
+// Name returns the name of the LogDriverOpts in the configuration.
+func (o *LogDriverOpts) Name() string {
+	return o.name
+}
+
+// Set validates and adds a driver:key=value option. The name of the driver
+// ends at the last colon before the key, as the names of log driver plugins
+// may have a tag.
+func (o *LogDriverOpts) Set(val string) error {
+	kv := strings.SplitN(val, "=", 2)
+	i := strings.LastIndex(kv[0], ":")
+	if len(kv) != 2 || i <= 0 || i == len(kv[0])-1 {
+		return fmt.Errorf("invalid log driver option %s: the format is driver:key=value", val)
+	}
+	driver, key := kv[0][:i], kv[0][i+1:]
+
+	if (*o.values)[driver] == nil {
+		(*o.values)[driver] = make(map[string]string)
+	}
+	(*o.values)[driver][key] = kv[1]
+	return nil
+}
+
+// String returns the options as driver:key=value strings.
+func (o *LogDriverOpts) String() string {
+	var out []string
+	for driver, opts := range *o.values {
+		for k, v := range opts {
+			out = append(out, driver+":"+k+"="+v)
+		}
+	}
+	sort.Strings(out)
+	return fmt.Sprintf("%v", out)
+}
+
+// GetMap returns the options of each driver
+func (o *LogDriverOpts) GetMap() map[string]map[string]string {
+	return *o.values
+}
@@ -0,0 +1,41 @@
+package opts
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestLogDriverOpts(t *testing.T) {
+	var values map[string]map[string]string
+	o := NewNamedLogDriverOpts("log-driver-opts", &values)
+
+	for _, val := range []string{
+		"json-file:max-size=10m",
+		"json-file:max-file=3",
+		"journald:tag={{.Name}}",
+		"vendor/logger:latest:endpoint=tcp://logs:514",
+	} {
+		if err := o.Set(val); err != nil {
+			t.Fatal(err)
+		}
+	}
+	expected := map[string]map[string]string{
+		"json-file":            {"max-size": "10m", "max-file": "3"},
+		"journald":             {"tag": "{{.Name}}"},
+		"vendor/logger:latest": {"endpoint": "tcp://logs:514"},
+	}
+	if !reflect.DeepEqual(values, expected) {
+		t.Fatalf("expected %v, got %v", expected, values)
+	}
+
+	for _, val := range []string{
+		"json-file",
+		"max-size=10m",
+		":max-size=10m",
+		"json-file:=10m",
+	} {
+		if err := o.Set(val); err == nil {
+			t.Fatalf("expected %s to be rejected", val)
+		}
+	}
+}
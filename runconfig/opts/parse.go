@@ -92,6 +92,8 @@ type ContainerOptions struct {
 	flRestartPolicy     string
 	flReadonlyRootfs    bool
 	flLoggingDriver     string
+	flLogMaxSize        string
+	flLogMaxFiles       int
 	flCgroupParent      string
 	flVolumeDriver      string
 	flStopSignal        string
@@ -103,6 +105,7 @@ type ContainerOptions struct {
 	flHealthTimeout     time.Duration
 	flHealthRetries     int
 	flRuntime           string
+	flInit              bool
 
 	Image string
 	Args  []string
@@ -197,6 +200,8 @@ func AddFlags(flags *pflag.FlagSet) *ContainerOptions {
 	flags.StringVar(&copts.flLoggingDriver, "log-driver", "", "Logging driver for container")
 	flags.StringVar(&copts.flVolumeDriver, "volume-driver", "", "Optional volume driver for the container")
 	flags.Var(&copts.flLoggingOpts, "log-opt", "Log driver options")
+	flags.StringVar(&copts.flLogMaxSize, "log-max-size", "", "Maximum size of the logs kept for the container")
+	flags.IntVar(&copts.flLogMaxFiles, "log-max-files", 0, "Maximum number of log files kept for the container")
 	flags.Var(&copts.flStorageOpt, "storage-opt", "Set storage driver options per container")
 	flags.Var(&copts.flTmpfs, "tmpfs", "Mount a tmpfs directory")
 	flags.Var(&copts.flVolumesFrom, "volumes-from", "Mount volumes from the specified container(s)")
@@ -242,6 +247,7 @@ func AddFlags(flags *pflag.FlagSet) *ContainerOptions {
 	flags.StringVar(&copts.flShmSize, "shm-size", "", "Size of /dev/shm, default value is 64MB")
 	flags.StringVar(&copts.flUTSMode, "uts", "", "UTS namespace to use")
 	flags.StringVar(&copts.flRuntime, "runtime", "", "Runtime to use for this container")
+	flags.BoolVar(&copts.flInit, "init", false, "Run an init inside the container that forwards signals and reaps processes")
 	return copts
 }
 
@@ -408,13 +414,13 @@ func Parse(flags *pflag.FlagSet, copts *ContainerOptions) (*container.Config, *c
 	}
 
 	// collect all the environment variables for the container
-	envVariables, err := readKVStrings(copts.flEnvFile.GetAll(), copts.flEnv.GetAll())
+	envVariables, err := ReadKVStrings(copts.flEnvFile.GetAll(), copts.flEnv.GetAll())
 	if err != nil {
 		return nil, nil, nil, err
 	}
 
 	// collect all the labels for the container
-	labels, err := readKVStrings(copts.flLabelsFile.GetAll(), copts.flLabels.GetAll())
+	labels, err := ReadKVStrings(copts.flLabelsFile.GetAll(), copts.flLabels.GetAll())
 	if err != nil {
 		return nil, nil, nil, err
 	}
@@ -449,6 +455,23 @@ func Parse(flags *pflag.FlagSet, copts *ContainerOptions) (*container.Config, *c
 		return nil, nil, nil, err
 	}
 
+	var logMaxSize int64
+	if copts.flLogMaxSize != "" {
+		logMaxSize, err = units.RAMInBytes(copts.flLogMaxSize)
+		if err != nil {
+			return nil, nil, nil, err
+		}
+	}
+	if logMaxSize < 0 || copts.flLogMaxFiles < 0 {
+		return nil, nil, nil, fmt.Errorf("invalid log quota: --log-max-size and --log-max-files must not be negative")
+	}
+	logConfig := container.LogConfig{
+		Type:     copts.flLoggingDriver,
+		Config:   loggingOpts,
+		MaxSize:  logMaxSize,
+		MaxFiles: copts.flLogMaxFiles,
+	}
+
 	securityOpts, err := parseSecurityOpts(copts.flSecurityOpt.GetAll())
 	if err != nil {
 		return nil, nil, nil, err
@@ -576,7 +599,7 @@ func Parse(flags *pflag.FlagSet, copts *ContainerOptions) (*container.Config, *c
 		SecurityOpt:    securityOpts,
 		StorageOpt:     storageOpts,
 		ReadonlyRootfs: copts.flReadonlyRootfs,
-		LogConfig:      container.LogConfig{Type: copts.flLoggingDriver, Config: loggingOpts},
+		LogConfig:      logConfig,
 		VolumeDriver:   copts.flVolumeDriver,
 		Isolation:      container.Isolation(copts.flIsolation),
 		ShmSize:        shmSize,
@@ -586,6 +609,10 @@ func Parse(flags *pflag.FlagSet, copts *ContainerOptions) (*container.Config, *c
 		Runtime:        copts.flRuntime,
 	}
 
+	if flags.Changed("init") {
+		hostConfig.Init = &copts.flInit
+	}
+
 	// When allocating stdin in attached mode, close stdin at client disconnect
 	if config.OpenStdin && config.AttachStdin {
 		config.StdinOnce = true
@@ -633,9 +660,10 @@ func Parse(flags *pflag.FlagSet, copts *ContainerOptions) (*container.Config, *c
 	return config, hostConfig, networkingConfig, nil
 }
 
-// reads a file of line terminated key=value pairs, and overrides any keys
-// present in the file with additional pairs specified in the override parameter
-func readKVStrings(files []string, override []string) ([]string, error) {
+// ReadKVStrings reads a file of line terminated key=value pairs, and overrides
+// any keys present in the file with additional pairs specified in the override
+// parameter
+func ReadKVStrings(files []string, override []string) ([]string, error) {
 	envVariables := []string{}
 	for _, ef := range files {
 		parsedVars, err := ParseEnvFile(ef)
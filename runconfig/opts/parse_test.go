@@ -412,6 +412,18 @@ func TestParseHostname(t *testing.T) {
 	}
 }
 
+func TestParseInit(t *testing.T) {
+	if _, hostConfig := mustParse(t, ""); hostConfig.Init != nil {
+		t.Fatalf("Expected no init setting without --init, got %v", *hostConfig.Init)
+	}
+	if _, hostConfig := mustParse(t, "--init"); hostConfig.Init == nil || !*hostConfig.Init {
+		t.Fatalf("Expected the init to be enabled with --init, got %v", hostConfig.Init)
+	}
+	if _, hostConfig := mustParse(t, "--init=false"); hostConfig.Init == nil || *hostConfig.Init {
+		t.Fatalf("Expected the init to be disabled with --init=false, got %v", hostConfig.Init)
+	}
+}
+
 func TestParseWithExpose(t *testing.T) {
 	invalids := map[string]string{
 		":":                   "invalid port format for --expose: :",
@@ -0,0 +1,13 @@
+package local
+
+import "syscall"
+
+// capacity returns the size of the filesystem of path in bytes, or -1 if
+// it cannot be determined.
+func capacity(path string) int64 {
+	var stat syscall.Statfs_t
+	if err := syscall.Statfs(path, &stat); err != nil {
+		return -1
+	}
+	return int64(stat.Blocks * uint64(stat.Bsize))
+}
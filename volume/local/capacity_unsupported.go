@@ -0,0 +1,9 @@
+// +build !linux
+
+package local
+
+// capacity returns -1, since the size of the filesystem of the volumes is
+// only determined on Linux.
+func capacity(path string) int64 {
+	return -1
+}
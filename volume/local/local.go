@@ -12,6 +12,7 @@ import (
 	"sync"
 
 	"github.com/Sirupsen/logrus"
+	"github.com/docker/docker/pkg/directory"
 	"github.com/docker/docker/pkg/idtools"
 	"github.com/docker/docker/pkg/mount"
 	"github.com/docker/docker/utils"
@@ -336,3 +337,21 @@ func validateOpts(opts map[string]string) error {
 func (v *localVolume) Status() map[string]interface{} {
 	return nil
 }
+
+// Usage returns the number of bytes used by the volume and the capacity of
+// the filesystem it is on. The usage of a volume mounting a device is only
+// known while it is mounted.
+func (v *localVolume) Usage() (int64, int64, error) {
+	v.m.Lock()
+	unmounted := v.opts != nil && !v.active.mounted
+	v.m.Unlock()
+	if unmounted {
+		return -1, -1, nil
+	}
+
+	size, err := directory.Size(v.path)
+	if err != nil {
+		return -1, -1, err
+	}
+	return size, capacity(v.path), nil
+}
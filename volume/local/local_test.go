@@ -3,6 +3,7 @@ package local
 import (
 	"io/ioutil"
 	"os"
+	"path/filepath"
 	"runtime"
 	"strings"
 	"testing"
@@ -247,3 +248,34 @@ func TestCreateWithOpts(t *testing.T) {
 		t.Fatal("expected mount to still be active")
 	}
 }
+
+func TestUsage(t *testing.T) {
+	rootDir, err := ioutil.TempDir("", "local-volume-test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(rootDir)
+
+	r, err := New(rootDir, 0, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	v, err := r.Create("usage", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := ioutil.WriteFile(filepath.Join(v.Path(), "file"), make([]byte, 4096), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	size, capacity, err := v.(*localVolume).Usage()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if size != 4096 {
+		t.Fatalf("Expected a size of 4096 bytes, got %d", size)
+	}
+	if runtime.GOOS == "linux" && capacity < size {
+		t.Fatalf("Expected a capacity of at least %d bytes, got %d", size, capacity)
+	}
+}
@@ -3,6 +3,7 @@ package store
 import (
 	"bytes"
 	"encoding/json"
+	"io"
 	"os"
 	"path/filepath"
 	"sync"
@@ -21,14 +22,16 @@ const (
 )
 
 type volumeMetadata struct {
-	Name   string
-	Labels map[string]string
+	Name    string
+	Labels  map[string]string
+	Options map[string]string
 }
 
 type volumeWrapper struct {
 	volume.Volume
-	labels map[string]string
-	scope  string
+	labels  map[string]string
+	scope   string
+	options map[string]string
 }
 
 func (v volumeWrapper) Labels() map[string]string {
@@ -39,6 +42,17 @@ func (v volumeWrapper) Scope() string {
 	return v.scope
 }
 
+func (v volumeWrapper) Options() map[string]string {
+	return v.options
+}
+
+func (v volumeWrapper) Usage() (int64, int64, error) {
+	if vv, ok := v.Volume.(volume.UsageVolume); ok {
+		return vv.Usage()
+	}
+	return -1, -1, nil
+}
+
 func (v volumeWrapper) CachedPath() string {
 	if vv, ok := v.Volume.(interface {
 		CachedPath() string
@@ -52,10 +66,11 @@ func (v volumeWrapper) CachedPath() string {
 // reference counting of volumes in the system.
 func New(rootPath string) (*VolumeStore, error) {
 	vs := &VolumeStore{
-		locks:  &locker.Locker{},
-		names:  make(map[string]volume.Volume),
-		refs:   make(map[string][]string),
-		labels: make(map[string]map[string]string),
+		locks:   &locker.Locker{},
+		names:   make(map[string]volume.Volume),
+		refs:    make(map[string][]string),
+		labels:  make(map[string]map[string]string),
+		options: make(map[string]map[string]string),
 	}
 
 	if rootPath != "" {
@@ -83,11 +98,33 @@ func New(rootPath string) (*VolumeStore, error) {
 		}); err != nil {
 			return nil, err
 		}
+
+		if err := vs.restore(); err != nil {
+			return nil, err
+		}
 	}
 
 	return vs, nil
 }
 
+// restore loads the labels and the driver options of the volumes kept in the
+// metadata store, so that they are listed with the volumes after a restart
+// of the daemon, before the volumes are used.
+func (s *VolumeStore) restore() error {
+	return s.db.View(func(tx *bolt.Tx) error {
+		return tx.Bucket([]byte(volumeBucketName)).ForEach(func(k, v []byte) error {
+			var meta volumeMetadata
+			if err := json.Unmarshal(v, &meta); err != nil {
+				logrus.Warnf("Error reading the metadata of volume %s: %v", string(k), err)
+				return nil
+			}
+			s.labels[string(k)] = meta.Labels
+			s.options[string(k)] = meta.Options
+			return nil
+		})
+	})
+}
+
 func (s *VolumeStore) getNamed(name string) (volume.Volume, bool) {
 	s.globalLock.Lock()
 	v, exists := s.names[name]
@@ -109,6 +146,7 @@ func (s *VolumeStore) purge(name string) {
 	delete(s.names, name)
 	delete(s.refs, name)
 	delete(s.labels, name)
+	delete(s.options, name)
 	s.globalLock.Unlock()
 }
 
@@ -123,7 +161,22 @@ type VolumeStore struct {
 	refs map[string][]string
 	// labels stores volume labels for each volume
 	labels map[string]map[string]string
-	db     *bolt.DB
+	// options stores the driver options each volume was created with
+	options map[string]map[string]string
+	db      *bolt.DB
+}
+
+// BackupMetadata writes a consistent copy of the metadata database of the
+// volumes to w, while the store keeps running. It writes nothing if the
+// store has no database.
+func (s *VolumeStore) BackupMetadata(w io.Writer) error {
+	if s.db == nil {
+		return nil
+	}
+	return s.db.View(func(tx *bolt.Tx) error {
+		_, err := tx.WriteTo(w)
+		return err
+	})
 }
 
 // List proxies to all registered volume drivers to get the full list of volumes
@@ -182,7 +235,7 @@ func (s *VolumeStore) list() ([]volume.Volume, []string, error) {
 				return
 			}
 			for i, v := range vs {
-				vs[i] = volumeWrapper{v, s.labels[v.Name()], d.Scope()}
+				vs[i] = volumeWrapper{v, s.labels[v.Name()], d.Scope(), s.options[v.Name()]}
 			}
 
 			chVols <- vols{vols: vs}
@@ -279,12 +332,14 @@ func (s *VolumeStore) create(name, driverName string, opts, labels map[string]st
 	}
 	s.globalLock.Lock()
 	s.labels[name] = labels
+	s.options[name] = opts
 	s.globalLock.Unlock()
 
 	if s.db != nil {
 		metadata := &volumeMetadata{
-			Name:   name,
-			Labels: labels,
+			Name:    name,
+			Labels:  labels,
+			Options: opts,
 		}
 
 		volData, err := json.Marshal(metadata)
@@ -301,7 +356,7 @@ func (s *VolumeStore) create(name, driverName string, opts, labels map[string]st
 		}
 	}
 
-	return volumeWrapper{v, labels, vd.Scope()}, nil
+	return volumeWrapper{v, labels, vd.Scope(), opts}, nil
 }
 
 // GetWithRef gets a volume with the given name from the passed in driver and stores the ref
@@ -324,7 +379,7 @@ func (s *VolumeStore) GetWithRef(name, driverName, ref string) (volume.Volume, e
 
 	s.setNamed(v, ref)
 
-	return volumeWrapper{v, s.labels[name], vd.Scope()}, nil
+	return volumeWrapper{v, s.labels[name], vd.Scope(), s.options[name]}, nil
 }
 
 // Get looks if a volume with the given name exists and returns it if so
@@ -346,6 +401,9 @@ func (s *VolumeStore) Get(name string) (volume.Volume, error) {
 // it is expected that callers of this function hold any necessary locks
 func (s *VolumeStore) getVolume(name string) (volume.Volume, error) {
 	labels := map[string]string{}
+	s.globalLock.Lock()
+	options := s.options[name]
+	s.globalLock.Unlock()
 
 	if s.db != nil {
 		// get meta
@@ -364,6 +422,9 @@ func (s *VolumeStore) getVolume(name string) (volume.Volume, error) {
 				return err
 			}
 			labels = meta.Labels
+			if meta.Options != nil {
+				options = meta.Options
+			}
 
 			return nil
 		}); err != nil {
@@ -384,7 +445,7 @@ func (s *VolumeStore) getVolume(name string) (volume.Volume, error) {
 		if err != nil {
 			return nil, err
 		}
-		return volumeWrapper{vol, labels, vd.Scope()}, nil
+		return volumeWrapper{vol, labels, vd.Scope(), options}, nil
 	}
 
 	logrus.Debugf("Probing all drivers for volume with name: %s", name)
@@ -399,7 +460,7 @@ func (s *VolumeStore) getVolume(name string) (volume.Volume, error) {
 			continue
 		}
 
-		return volumeWrapper{v, labels, d.Scope()}, nil
+		return volumeWrapper{v, labels, d.Scope(), options}, nil
 	}
 	return nil, errNoSuchVolume
 }
@@ -474,7 +535,7 @@ func (s *VolumeStore) FilterByDriver(name string) ([]volume.Volume, error) {
 		return nil, &OpErr{Err: err, Name: name, Op: "list"}
 	}
 	for i, v := range ls {
-		ls[i] = volumeWrapper{v, s.labels[v.Name()], vd.Scope()}
+		ls[i] = volumeWrapper{v, s.labels[v.Name()], vd.Scope(), s.options[v.Name()]}
 	}
 	return ls, nil
 }
@@ -2,9 +2,12 @@ package store
 
 import (
 	"errors"
+	"io/ioutil"
+	"os"
 	"strings"
 	"testing"
 
+	"github.com/docker/docker/volume"
 	"github.com/docker/docker/volume/drivers"
 	vt "github.com/docker/docker/volume/testutils"
 )
@@ -110,6 +113,71 @@ func TestList(t *testing.T) {
 	}
 }
 
+func TestCreateKeepsOptions(t *testing.T) {
+	volumedrivers.Register(vt.NewFakeDriver("fake"), "fake")
+	defer volumedrivers.Unregister("fake")
+	s, err := New("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	opts := map[string]string{"size": "10G"}
+	if _, err := s.Create("fake1", "fake", opts, nil); err != nil {
+		t.Fatal(err)
+	}
+
+	v, err := s.Get("fake1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	cv, ok := v.(volume.ConfiguredVolume)
+	if !ok {
+		t.Fatalf("Expected the volume to report its options, got %T", v)
+	}
+	if cv.Options()["size"] != "10G" {
+		t.Fatalf("Expected the size option to be kept, got %v", cv.Options())
+	}
+}
+
+func TestRestoreKeepsOptions(t *testing.T) {
+	volumedrivers.Register(vt.NewFakeDriver("fake"), "fake")
+	defer volumedrivers.Unregister("fake")
+	dir, err := ioutil.TempDir("", "test-restore")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	s, err := New(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	opts := map[string]string{"size": "10G"}
+	labels := map[string]string{"env": "test"}
+	if _, err := s.Create("fake1", "fake", opts, labels); err != nil {
+		t.Fatal(err)
+	}
+	s.db.Close()
+
+	s, err = New(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer s.db.Close()
+	ls, err := s.FilterByDriver("fake")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(ls) != 1 {
+		t.Fatalf("Expected 1 volume, got %d", len(ls))
+	}
+	if cv := ls[0].(volume.ConfiguredVolume); cv.Options()["size"] != "10G" {
+		t.Fatalf("Expected the size option to be restored, got %v", cv.Options())
+	}
+	if lv := ls[0].(volume.LabeledVolume); lv.Labels()["env"] != "test" {
+		t.Fatalf("Expected the labels to be restored, got %v", lv.Labels())
+	}
+}
+
 func TestFilterByDriver(t *testing.T) {
 	volumedrivers.Register(vt.NewFakeDriver("fake"), "fake")
 	volumedrivers.Register(vt.NewFakeDriver("noop"), "noop")
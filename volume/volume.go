@@ -77,6 +77,20 @@ type ScopedVolume interface {
 	Volume
 }
 
+// ConfiguredVolume wraps a volume with the driver options it was created with
+type ConfiguredVolume interface {
+	Options() map[string]string
+	Volume
+}
+
+// UsageVolume wraps a volume which can report the space it uses
+type UsageVolume interface {
+	// Usage returns the number of bytes used by the volume and the
+	// capacity of its storage in bytes, each -1 if it is not known.
+	Usage() (int64, int64, error)
+	Volume
+}
+
 // MountPoint is the intersection point between a volume and a container. It
 // specifies which volume is to be used and where inside a container it should
 // be mounted.